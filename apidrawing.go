@@ -99,7 +99,9 @@ func (p *Paragraph) AddInlineDrawing(pic []byte) (*Run, error) {
 					},
 				},
 			},
+			file: p.file,
 		},
+		file: p.file,
 	}
 	c := make([]interface{}, 1, 64)
 	c[0] = d
@@ -211,7 +213,9 @@ func (p *Paragraph) AddAnchorDrawing(pic []byte) (*Run, error) {
 					},
 				},
 			},
+			file: p.file,
 		},
+		file: p.file,
 	}
 	c := make([]interface{}, 1, 64)
 	c[0] = d
@@ -109,6 +109,12 @@ func (r *Run) Strike(val bool) *Run {
 	return r
 }
 
+// Language sets the language of the run, val for latin text and eastAsia for east asian text
+func (r *Run) Language(val, eastAsia string) *Run {
+	r.RunProperties.Lang = &Lang{Val: val, EastAsia: eastAsia}
+	return r
+}
+
 // AddTab add a tab in front of the run
 func (r *Run) AddTab() *Run {
 	r.Children = append(r.Children, &Tab{})
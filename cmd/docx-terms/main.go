@@ -0,0 +1,77 @@
+// Package main extracts candidate terms from docx files into a CSV
+//
+//	docx-terms -min 3 -o terms.csv a.docx b.docx
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/iEvan-lhr/go-docx-translate"
+)
+
+func main() {
+	minFreq := flag.Int("min", 2, "minimum frequency of a candidate")
+	maxWords := flag.Int("words", 3, "maximum words of a latin candidate")
+	maxRunes := flag.Int("runes", 4, "maximum runes of a CJK candidate")
+	contexts := flag.Int("ctx", 3, "contexts kept per candidate")
+	output := flag.String("o", "", "output csv file (default stdout)")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: docx-terms [flags] file.docx...")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	docs := make([]*docx.Docx, 0, flag.NArg())
+	for _, name := range flag.Args() {
+		doc, err := parseFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, name+":", err)
+			os.Exit(1)
+		}
+		docs = append(docs, doc)
+	}
+	terms := docx.ExtractTerms(docs, docx.TermOptions{
+		MinFrequency: *minFreq,
+		MaxWords:     *maxWords,
+		MaxCJKRunes:  *maxRunes,
+		MaxContexts:  *contexts,
+	})
+
+	err := writeTerms(*output, terms)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func writeTerms(output string, terms []docx.TermCandidate) error {
+	if output == "" {
+		return docx.WriteTermsCSV(os.Stdout, terms)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	err = docx.WriteTermsCSV(f, terms)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func parseFile(name string) (*docx.Docx, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return docx.Parse(f, st.Size())
+}
@@ -0,0 +1,443 @@
+// Package main translates a document with the go-docx-translate library
+//
+//	docx-translate -in a.docx -out a.ja.docx -to ja -provider dashscope
+//
+// Settings come from the -config file, DOCX_TRANSLATE_* environment variables
+// (e.g. DOCX_TRANSLATE_API_KEY) and the flags, in increasing order of precedence.
+// Any format recognized by TranslateFile is accepted; "-" reads stdin or writes stdout.
+// -in and -out also accept s3://, oss:// and gs:// object URIs, see docx.OpenURI.
+// When -in is a directory, every file in it is translated into -out for each of the
+// comma separated -to languages, see Translator.TranslateDir.
+//
+//	docx-translate server -addr :8080 -config docx-translate.toml
+//
+// runs the HTTP service of JobManager.Handler instead, with the admin API under /admin/
+// and Prometheus metrics under /metrics. With -queue memory or -queue sqlite:jobs.db the jobs
+// go through a JobQueue; several servers sharing one SQLite file share its queue. The SQLite
+// queue needs a binary built with -tags sqlite (and cgo). -grpc-addr also serves the gRPC API
+// of proto/docxtranslate/v1 on the same jobs, see package grpcserver. Finally,
+//
+//	docx-translate watch -in inbox -out outbox -to ja
+//
+// translates every .docx dropped into inbox until interrupted, see Translator.WatchFolder.
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/iEvan-lhr/go-docx-translate"
+	"github.com/iEvan-lhr/go-docx-translate/grpcserver"
+	translatev1 "github.com/iEvan-lhr/go-docx-translate/proto/docxtranslate/v1"
+)
+
+// configFlags maps the flags that override the configuration to configuration keys
+var configFlags = map[string]string{
+	"from":        "source_language",
+	"provider":    "provider",
+	"key":         "api_key",
+	"url":         "api_url",
+	"model":       "model",
+	"concurrency": "concurrency",
+	"glossary":    "glossary",
+	"dnt":         "dnt",
+	"cache":       "cache_dir",
+	"mode":        "output_mode",
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "server" {
+		serve(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "watch" {
+		watch(os.Args[2:])
+		return
+	}
+	in := flag.String("in", "", "input file, directory or s3://, oss://, gs:// URI, - for stdin")
+	out := flag.String("out", "", "output file, directory or object URI, - for stdout (default: input name with the language code, e.g. a.ja.docx)")
+	to := flag.String("to", "", "target language, e.g. ja or Japanese; comma separated languages for a directory")
+	format := flag.String("format", "", "input format (default: detected from the content)")
+	config := flag.String("config", "", "configuration file (.yaml, .toml or .json)")
+	addConfigFlags(flag.CommandLine)
+	docs := flag.Int("docs", 2, "documents translated concurrently when -in is a directory")
+	report := flag.String("report", "", "write the summary of a directory translation to this .csv or .json file")
+	quiet := flag.Bool("q", false, "do not print progress")
+	flag.Parse()
+	if *in == "" || *to == "" || flag.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: docx-translate -in file -to language [flags]")
+		fmt.Fprintln(os.Stderr, "       docx-translate server [flags]")
+		fmt.Fprintln(os.Stderr, "       docx-translate watch -in dir -out dir -to language [flags]")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	cfg, err := loadConfig(flag.CommandLine, *config)
+	if err != nil {
+		fail(err)
+	}
+	t, closer, err := cfg.NewTranslator()
+	if err != nil {
+		fail(err)
+	}
+	if cfg.LogLevel == "" {
+		t.WithLogger(docx.NewStdLogger(os.Stderr, docx.LogLevelWarn))
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	if fi, err := os.Stat(*in); err == nil && fi.IsDir() {
+		if *out == "" {
+			fail(errors.New("-out is required when -in is a directory"))
+		}
+		err = runDir(ctx, t.WithDocumentConcurrency(*docs), *in, *out, strings.Split(*to, ","), *report, *quiet)
+		stop()
+		_ = closer()
+		if err != nil {
+			fail(err)
+		}
+		return
+	}
+	if !*quiet {
+		t.WithProgress(func(done, total int, _ docx.SegmentInfo) {
+			fmt.Fprintf(os.Stderr, "\r%d/%d", done, total)
+			if done == total {
+				fmt.Fprintln(os.Stderr)
+			}
+		})
+	}
+
+	err = run(ctx, t, *in, outputName(*in, *out, *to), docx.TranslateFileOptions{TargetLanguage: *to, Format: *format})
+	stop()
+	_ = closer()
+	var segErrs docx.SegmentErrors
+	if errors.As(err, &segErrs) {
+		// the output is still written, with the failed segments left untranslated
+		fmt.Fprintf(os.Stderr, "docx-translate: %d segments kept the source text\n", len(segErrs))
+		os.Exit(1)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+// addConfigFlags defines the flags of configFlags on fs
+func addConfigFlags(fs *flag.FlagSet) {
+	fs.String("from", "", "source language (default Chinese)")
+	fs.String("provider", "", "translation provider: dashscope (default), exec:<command> or unix:<socket>")
+	fs.String("key", "", "API key of the dashscope provider (default $DOCX_TRANSLATE_API_KEY or $DASHSCOPE_API_KEY)")
+	fs.String("url", "", "chat completions endpoint of the dashscope provider (default "+docx.DashscopeAPIURL+")")
+	fs.String("model", "", "model name (default qwen-plus)")
+	fs.Int("concurrency", 4, "segments translated concurrently")
+	fs.String("glossary", "", "glossary csv file with source,target columns")
+	fs.String("dnt", "", "do-not-translate list, one term or re:pattern per line")
+	fs.String("cache", "", "cache directory shared between runs")
+	fs.String("mode", "", "docx output mode: translation (default), interleaved, table, comments or hidden")
+}
+
+// serve runs the server subcommand
+func serve(args []string) {
+	fs := flag.NewFlagSet("server", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "listen address")
+	config := fs.String("config", "", "configuration file (.yaml, .toml or .json)")
+	syncLimit := fs.Int("sync-limit", docx.DefaultSyncLimit, "largest upload in bytes answered synchronously, -1 to always return a job")
+	syncTimeout := fs.Duration("sync-timeout", docx.DefaultSyncTimeout, "longest wait for a synchronous answer")
+	maxUpload := fs.Int64("max-upload", docx.DefaultMaxUploadSize, "largest upload in bytes")
+	retention := fs.Duration("retention", 24*time.Hour, "how long uploads and translations are kept, 0 to keep them")
+	noAdmin := fs.Bool("no-admin", false, "do not serve the admin API under /admin/")
+	noMetrics := fs.Bool("no-metrics", false, "do not serve Prometheus metrics under /metrics")
+	webhook := fs.String("webhook", "", "URL notified with a signed JSON payload when a job finishes")
+	webhookSecret := fs.String("webhook-secret", os.Getenv(docx.ConfigEnvPrefix+"WEBHOOK_SECRET"), "webhook signing secret (default $"+docx.ConfigEnvPrefix+"WEBHOOK_SECRET)")
+	publicURL := fs.String("public-url", "", "external URL of this server, used for the download links of webhooks")
+	queue := fs.String("queue", "", "job queue: memory or sqlite:PATH (default: run jobs in process without a queue)")
+	workers := fs.Int("workers", 2, "jobs of the -queue translated concurrently by this server, 0 to only accept jobs")
+	grpcAddr := fs.String("grpc-addr", "", "listen address of the gRPC API (default: no gRPC API)")
+	encryptionKey := fs.String("encryption-key", os.Getenv(docx.ConfigEnvPrefix+"ENCRYPTION_KEY"), "hex AES key (16, 24 or 32 bytes) encrypting stored uploads and translations (default $"+docx.ConfigEnvPrefix+"ENCRYPTION_KEY)")
+	addConfigFlags(fs)
+	_ = fs.Parse(args)
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: docx-translate server [flags]")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+
+	t, closer := daemonTranslator(fs, *config)
+	defer closer()
+	var metrics *docx.Metrics
+	if !*noMetrics {
+		metrics = docx.NewMetrics()
+		t.WithMetrics(metrics)
+	}
+	var aead cipher.AEAD
+	if *encryptionKey != "" {
+		key, err := hex.DecodeString(*encryptionKey)
+		if err == nil {
+			aead, err = docx.NewArtifactCipher(key)
+		}
+		if err != nil {
+			fail(fmt.Errorf("invalid -encryption-key: %w", err))
+		}
+	}
+	var hook *docx.Webhook
+	if *webhook != "" {
+		hook = &docx.Webhook{URL: *webhook, Secret: []byte(*webhookSecret), BaseURL: *publicURL, Logger: docx.NewStdLogger(os.Stderr, docx.LogLevelWarn)}
+	}
+	opts := docx.ServerOptions{MaxUploadSize: *maxUpload, SyncLimit: *syncLimit, SyncTimeout: *syncTimeout}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	var (
+		jobs           docx.JobService
+		handler, admin http.Handler
+	)
+	if *queue == "" {
+		m := docx.NewJobManager(t).WithRetention(*retention)
+		if aead != nil {
+			m.WithEncryption(aead)
+		}
+		if hook != nil {
+			m.WithWebhook(hook)
+		}
+		jobs, handler, admin = m.Service(), m.Handler(opts), m.AdminHandler()
+	} else {
+		store, closeStore, err := openJobStore(*queue)
+		if err != nil {
+			fail(err)
+		}
+		defer closeStore()
+		q := docx.NewJobQueue(store).WithRetention(*retention)
+		if aead != nil {
+			q.WithEncryption(aead)
+		}
+		if hook != nil {
+			q.WithWebhook(hook)
+		}
+		jobs, handler, admin = q, q.Handler(opts), q.AdminHandler()
+		for i := 0; i < *workers; i++ {
+			go func() { _ = q.Work(ctx, t) }()
+		}
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", handler)
+	if !*noAdmin {
+		mux.Handle("/admin/", http.StripPrefix("/admin", admin))
+	}
+	if metrics != nil {
+		mux.Handle("/metrics", metrics)
+	}
+	srv := &http.Server{Addr: *addr, Handler: mux}
+	if *grpcAddr != "" {
+		lis, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			fail(err)
+		}
+		g := grpc.NewServer()
+		translatev1.RegisterTranslateServiceServer(g, grpcserver.NewServer(jobs).WithMaxUploadSize(*maxUpload))
+		go func() {
+			<-ctx.Done()
+			g.GracefulStop()
+		}()
+		go func() {
+			if err := g.Serve(lis); err != nil {
+				fail(err)
+			}
+		}()
+		fmt.Fprintln(os.Stderr, "docx-translate: gRPC listening on", *grpcAddr)
+	}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdown)
+	}()
+	fmt.Fprintln(os.Stderr, "docx-translate: listening on", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fail(err)
+	}
+}
+
+// openSQLite opens the database of -queue sqlite:PATH; it is set by sqlite.go, which is only built with -tags sqlite
+var openSQLite func(path string) (*sql.DB, error)
+
+// openJobStore opens the job store of the -queue flag, memory or sqlite:PATH
+func openJobStore(queue string) (docx.JobStore, func() error, error) {
+	switch {
+	case queue == "memory":
+		return docx.NewMemoryJobStore(), func() error { return nil }, nil
+	case strings.HasPrefix(queue, "sqlite:"):
+		if openSQLite == nil {
+			return nil, nil, errors.New("built without SQLite support, rebuild with -tags sqlite")
+		}
+		db, err := openSQLite(strings.TrimPrefix(queue, "sqlite:"))
+		if err != nil {
+			return nil, nil, err
+		}
+		store, err := docx.NewSQLiteJobStore(db)
+		if err != nil {
+			_ = db.Close()
+			return nil, nil, err
+		}
+		return store, db.Close, nil
+	}
+	return nil, nil, fmt.Errorf("unknown -queue %q, expected memory or sqlite:PATH", queue)
+}
+
+// watch runs the watch subcommand
+func watch(args []string) {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	in := fs.String("in", "", "directory to watch for .docx files")
+	out := fs.String("out", "", "directory of the translations")
+	errDir := fs.String("errors", "", "directory failed files are moved to (default: errors under -out)")
+	doneDir := fs.String("done", "", "directory translated files are moved to (default: leave them in -in)")
+	to := fs.String("to", "", "target language, e.g. ja or Japanese")
+	interval := fs.Duration("interval", docx.DefaultWatchInterval, "scan interval")
+	config := fs.String("config", "", "configuration file (.yaml, .toml or .json)")
+	addConfigFlags(fs)
+	_ = fs.Parse(args)
+	if *in == "" || *out == "" || *to == "" || fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "usage: docx-translate watch -in dir -out dir -to language [flags]")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+
+	t, closer := daemonTranslator(fs, *config)
+	defer closer()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Fprintln(os.Stderr, "docx-translate: watching", *in)
+	err := t.WatchFolder(ctx, docx.WatchOptions{
+		InputDir:       *in,
+		OutputDir:      *out,
+		ErrorDir:       *errDir,
+		DoneDir:        *doneDir,
+		TargetLanguage: *to,
+		Interval:       *interval,
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		fail(err)
+	}
+}
+
+// daemonTranslator creates the translator of the server and watch subcommands, which log at info level by default
+func daemonTranslator(fs *flag.FlagSet, config string) (*docx.Translator, func() error) {
+	cfg, err := loadConfig(fs, config)
+	if err != nil {
+		fail(err)
+	}
+	t, closer, err := cfg.NewTranslator()
+	if err != nil {
+		fail(err)
+	}
+	if cfg.LogLevel == "" {
+		t.WithLogger(docx.NewStdLogger(os.Stderr, docx.LogLevelInfo))
+	}
+	return t, closer
+}
+
+// loadConfig reads the configuration file and the environment, then applies the flags of fs given on the command line
+func loadConfig(fs *flag.FlagSet, name string) (*docx.Config, error) {
+	cfg := &docx.Config{}
+	var err error
+	if name != "" {
+		cfg, err = docx.LoadConfig(name)
+	} else {
+		err = cfg.LoadEnv()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Concurrency == 0 {
+		cfg.Concurrency = 4
+	}
+	if cfg.ErrorPolicy == "" {
+		cfg.ErrorPolicy = "collect"
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if key, ok := configFlags[f.Name]; ok && err == nil {
+			err = cfg.Set(key, f.Value.String())
+		}
+	})
+	return cfg, err
+}
+
+// outputName defaults to the input name with the language code inserted before the extension
+func outputName(in, out, to string) string {
+	if out != "" || in == "-" {
+		if out == "" {
+			return "-"
+		}
+		return out
+	}
+	return docx.LanguageFileName(in, to)
+}
+
+func run(ctx context.Context, t *docx.Translator, in, out string, opts docx.TranslateFileOptions) error {
+	var r io.Reader = os.Stdin
+	if in != "-" {
+		f, err := docx.OpenURI(ctx, in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	if out == "-" {
+		return t.TranslateFile(ctx, r, os.Stdout, opts)
+	}
+	return t.TranslateToURI(ctx, r, out, opts)
+}
+
+// runDir translates the directory in and prints one line per file and a summary
+func runDir(ctx context.Context, t *docx.Translator, in, out string, targets []string, report string, quiet bool) error {
+	r, err := t.TranslateDir(ctx, in, out, targets)
+	if r == nil {
+		return err
+	}
+	for _, f := range r.Files {
+		switch {
+		case f.Output == "":
+			fmt.Fprintf(os.Stderr, "FAIL %s (%s): %s\n", f.Input, f.TargetLanguage, f.Error)
+		case !quiet:
+			fmt.Fprintf(os.Stderr, "ok   %s -> %s (%d segments, %d kept the source text)\n", f.Input, f.Output, f.Usage.Segments, f.Usage.Failed)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "docx-translate: %d translated, %d failed, %d segments, %d characters in %s\n",
+		r.Succeeded, r.Failed, r.Usage.Segments, r.Usage.Characters, r.Duration.Round(time.Millisecond))
+	if report != "" {
+		var buf bytes.Buffer
+		if strings.EqualFold(filepath.Ext(report), ".json") {
+			enc := json.NewEncoder(&buf)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(r)
+		} else {
+			err = docx.WriteBatchReportCSV(&buf, r)
+		}
+		if err == nil {
+			err = os.WriteFile(report, buf.Bytes(), 0o644)
+		}
+	}
+	if err == nil && r.Failed > 0 {
+		err = fmt.Errorf("%d files failed", r.Failed)
+	}
+	return err
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "docx-translate:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,16 @@
+//go:build sqlite
+
+package main
+
+import (
+	"database/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func init() {
+	openSQLite = func(path string) (*sql.DB, error) {
+		// several servers may share the file: wait for each other's locks instead of failing
+		return sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL")
+	}
+}
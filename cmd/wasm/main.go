@@ -0,0 +1,52 @@
+//go:build js && wasm
+
+// Package main exposes docx translation to JavaScript.
+//
+// Build it with
+//
+//	GOOS=js GOARCH=wasm go build -o docx-translate.wasm ./cmd/wasm
+//
+// and load it together with wasm_exec.js shipped in $(go env GOROOT)/misc/wasm.
+// The module registers a global function
+//
+//	translateDocx(data: Uint8Array, apiKey: string, apiURL: string, target: string): Promise<Uint8Array>
+//
+// HTTP requests go through the browser's fetch, so apiURL must allow CORS.
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/iEvan-lhr/go-docx-translate/mobile"
+)
+
+func main() {
+	js.Global().Set("translateDocx", js.FuncOf(translateDocx))
+	select {} // keep the runtime alive for callbacks
+}
+
+// translateDocx must not block the JS event loop, because fetch only
+// resolves on it, so the work is done in a goroutine behind a Promise.
+func translateDocx(_ js.Value, args []js.Value) interface{} {
+	promise := js.Global().Get("Promise")
+	if len(args) != 4 {
+		return promise.Call("reject", js.Global().Get("Error").New("translateDocx: need 4 arguments"))
+	}
+	data := make([]byte, args[0].Get("length").Int())
+	js.CopyBytesToGo(data, args[0])
+	apiKey, apiURL, target := args[1].String(), args[2].String(), args[3].String()
+	return promise.New(js.FuncOf(func(_ js.Value, cbs []js.Value) interface{} {
+		resolve, reject := cbs[0], cbs[1]
+		go func() {
+			out, err := mobile.TranslateDocx(data, apiKey, apiURL, target)
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			arr := js.Global().Get("Uint8Array").New(len(out))
+			js.CopyBytesToJS(arr, out)
+			resolve.Invoke(arr)
+		}()
+		return nil
+	}))
+}
@@ -40,6 +40,7 @@ type Docx struct {
 
 	media        []Media
 	mediaNameIdx map[string]int
+	mediaMu      sync.RWMutex // mediaMu guards media, mediaNameIdx and image relations
 
 	rID       uintptr
 	imageID   uintptr
@@ -50,6 +51,10 @@ type Docx struct {
 	template string
 	tmplfs   fs.FS
 	tmpfslst []string
+	parts    map[string][]byte // parts override the files in tmplfs, see setPart
+
+	lenient  bool           // lenient skips malformed elements while parsing, see ParseLenient
+	warnings []ParseWarning // warnings are the problems skipped in lenient mode
 
 	io.Reader
 	io.WriterTo
@@ -95,7 +100,7 @@ func Parse(reader io.ReaderAt, size int64) (doc *Docx, err error) {
 	if err != nil {
 		return nil, err
 	}
-	doc, err = unpack(zipReader)
+	doc, err = unpack(zipReader, false)
 	return
 }
 
@@ -113,6 +118,7 @@ func LoadBodyItems(items []interface{}, media []Media) *Docx {
 			XMLWPS: XMLNS_WPS,
 			XMLWPC: XMLNS_WPC,
 			XMLWPG: XMLNS_WPG,
+			XMLM:   XMLNS_M,
 			Body:   Body{Items: items},
 		},
 		docRelation: Relationships{
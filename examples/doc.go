@@ -0,0 +1,8 @@
+// Package examples is a gallery of runnable usages of the translation API.
+//
+// It has no exported identifiers. The Example functions live in
+// example_test.go and are rendered by godoc and pkg.go.dev; go doc does not
+// print examples. go test and go vet compile and check them, but they have no
+// Output comments, so they are never run: they need documents on disk and
+// provider credentials.
+package examples
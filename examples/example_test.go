@@ -0,0 +1,140 @@
+package examples_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/iEvan-lhr/go-docx-translate"
+)
+
+// openDocx parses a docx file from disk
+func openDocx(name string) (*docx.Docx, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return docx.Parse(f, st.Size())
+}
+
+// saveDocx writes a docx to disk
+func saveDocx(doc *docx.Docx, name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = doc.WriteTo(f)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Translate a whole document and save the result.
+func Example_basicTranslation() {
+	doc, err := openDocx("contract.docx")
+	if err != nil {
+		panic(err)
+	}
+	t := docx.NewTranslator(os.Getenv("DASHSCOPE_API_KEY"), docx.DashscopeAPIURL)
+	translated, err := t.TranslateDocx(doc, "English")
+	if err != nil {
+		panic(err)
+	}
+	if err = saveDocx(translated, "contract.en.docx"); err != nil {
+		panic(err)
+	}
+}
+
+// Keep translating when some paragraphs fail and list them afterwards.
+func Example_collectErrors() {
+	doc, err := openDocx("contract.docx")
+	if err != nil {
+		panic(err)
+	}
+	t := docx.NewTranslator(os.Getenv("DASHSCOPE_API_KEY"), docx.DashscopeAPIURL).
+		WithErrorPolicy(docx.ErrorPolicyCollect)
+	translated, err := t.TranslateDocx(doc, "English")
+	var failed docx.SegmentErrors
+	if errors.As(err, &failed) {
+		for _, se := range failed {
+			fmt.Printf("paragraph %d kept in source language: %v\n", se.Index, se.Err)
+		}
+	} else if err != nil {
+		panic(err)
+	}
+	if err = saveDocx(translated, "contract.en.docx"); err != nil {
+		panic(err)
+	}
+}
+
+// Produce a bilingual document: every translated paragraph is followed by its
+// source paragraph in grey italics. OutputModeBilingualTable puts source and
+// translation side by side in a two-column table instead.
+func Example_bilingualOutput() {
+	doc, err := openDocx("contract.docx")
+	if err != nil {
+		panic(err)
+	}
+	t := docx.NewTranslator(os.Getenv("DASHSCOPE_API_KEY"), docx.DashscopeAPIURL).
+		WithOutputMode(docx.OutputModeBilingualInterleaved).
+		WithInterleaveOptions(docx.DefaultInterleaveOptions)
+	translated, err := t.TranslateDocx(doc, "English")
+	if err != nil {
+		panic(err)
+	}
+	if err = saveDocx(translated, "contract.zh-en.docx"); err != nil {
+		panic(err)
+	}
+}
+
+// Pin the translation of product names and legal terms with a glossary,
+// built in code or loaded from a source,target CSV file.
+func Example_glossary() {
+	g := docx.NewGlossary(
+		docx.GlossaryTerm{Source: "甲方", Target: "Party A"},
+		docx.GlossaryTerm{Source: "乙方", Target: "Party B"},
+	)
+	f, err := os.Open("glossary.csv")
+	if err != nil {
+		panic(err)
+	}
+	err = g.Load(f)
+	_ = f.Close()
+	if err != nil {
+		panic(err)
+	}
+	doc, err := openDocx("contract.docx")
+	if err != nil {
+		panic(err)
+	}
+	t := docx.NewTranslator(os.Getenv("DASHSCOPE_API_KEY"), docx.DashscopeAPIURL).
+		WithGlossary(g)
+	translated, err := t.TranslateDocx(doc, "English")
+	if err != nil {
+		panic(err)
+	}
+	if err = saveDocx(translated, "contract.en.docx"); err != nil {
+		panic(err)
+	}
+}
+
+// Serve translation over HTTP: POST a file (or a multipart form with a "file"
+// field) to /translate?to=English and receive the translation, or a job to
+// poll under /jobs/ for large files. The admin API lists and cancels jobs.
+func Example_serverDeployment() {
+	t := docx.NewTranslator(os.Getenv("DASHSCOPE_API_KEY"), docx.DashscopeAPIURL)
+	m := docx.NewJobManager(t).WithRetention(24 * time.Hour)
+	mux := http.NewServeMux()
+	mux.Handle("/", m.Handler(docx.ServerOptions{}))
+	mux.Handle("/admin/", http.StripPrefix("/admin", m.AdminHandler()))
+	panic(http.ListenAndServe(":8080", mux))
+}
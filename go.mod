@@ -2,4 +2,17 @@ module github.com/iEvan-lhr/go-docx-translate
 
 go 1.20
 
-require github.com/fumiama/imgsz v0.0.2
+require (
+	github.com/fumiama/imgsz v0.0.2
+	github.com/mattn/go-sqlite3 v1.14.22
+	google.golang.org/grpc v1.58.3
+	google.golang.org/protobuf v1.31.0
+)
+
+require (
+	github.com/golang/protobuf v1.5.3 // indirect
+	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+	golang.org/x/text v0.13.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230711160842-782d3b101e98 // indirect
+)
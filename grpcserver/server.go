@@ -0,0 +1,217 @@
+// Package grpcserver serves the docxtranslate.v1.TranslateService gRPC API of
+// proto/docxtranslate/v1 over a docx.JobService, either an in-process
+// JobManager (JobManager.Service) or a JobQueue
+//
+//	g := grpc.NewServer()
+//	translatev1.RegisterTranslateServiceServer(g, grpcserver.NewServer(m.Service()))
+//	g.Serve(lis)
+//
+// Like the HTTP handlers, the server does no authentication; serve it on an
+// internal address or add credentials and interceptors to the grpc.Server.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/iEvan-lhr/go-docx-translate"
+	translatev1 "github.com/iEvan-lhr/go-docx-translate/proto/docxtranslate/v1"
+)
+
+// DefaultChunkSize is the content chunk size of DownloadResult when the request leaves it at 0
+const DefaultChunkSize = 1 << 20
+
+// Server implements translatev1.TranslateServiceServer on a docx.JobService
+type Server struct {
+	translatev1.UnimplementedTranslateServiceServer
+	jobs          docx.JobService
+	maxUploadSize int64
+}
+
+// NewServer creates a Server submitting jobs to jobs, accepting uploads of up to docx.DefaultMaxUploadSize bytes
+func NewServer(jobs docx.JobService) *Server {
+	return &Server{jobs: jobs, maxUploadSize: docx.DefaultMaxUploadSize}
+}
+
+// WithMaxUploadSize sets the largest document SubmitJob accepts, in bytes
+func (s *Server) WithMaxUploadSize(n int64) *Server {
+	if n > 0 {
+		s.maxUploadSize = n
+	}
+	return s
+}
+
+// SubmitJob implements translatev1.TranslateServiceServer
+func (s *Server) SubmitJob(stream translatev1.TranslateService_SubmitJobServer) error {
+	var (
+		opts docx.TranslateFileOptions
+		data []byte
+	)
+	for first := true; ; first = false {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if first {
+			opts = docx.TranslateFileOptions{TargetLanguage: req.GetTargetLanguage(), Format: req.GetFormat()}
+		}
+		if int64(len(data))+int64(len(req.GetContent())) > s.maxUploadSize {
+			return status.Errorf(codes.ResourceExhausted, "document larger than %d bytes", s.maxUploadSize)
+		}
+		data = append(data, req.GetContent()...)
+	}
+	switch {
+	case opts.TargetLanguage == "":
+		return status.Error(codes.InvalidArgument, "missing target language")
+	case len(data) == 0:
+		return status.Error(codes.InvalidArgument, "empty document")
+	}
+	id, err := s.jobs.Submit(data, opts)
+	if err != nil {
+		return statusError(err)
+	}
+	info, err := s.jobs.Job(id)
+	if err != nil {
+		return statusError(err)
+	}
+	return stream.SendAndClose(jobMessage(info))
+}
+
+// GetStatus implements translatev1.TranslateServiceServer
+func (s *Server) GetStatus(_ context.Context, req *translatev1.GetStatusRequest) (*translatev1.Job, error) {
+	info, err := s.jobs.Job(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return jobMessage(info), nil
+}
+
+// WatchProgress implements translatev1.TranslateServiceServer
+func (s *Server) WatchProgress(req *translatev1.GetStatusRequest, stream translatev1.TranslateService_WatchProgressServer) error {
+	err := s.jobs.Watch(stream.Context(), req.GetId(), func(info docx.JobInfo) error {
+		return stream.Send(jobMessage(info))
+	})
+	if err != nil {
+		return statusError(err)
+	}
+	return nil
+}
+
+// DownloadResult implements translatev1.TranslateServiceServer
+func (s *Server) DownloadResult(req *translatev1.DownloadResultRequest, stream translatev1.TranslateService_DownloadResultServer) error {
+	info, err := s.jobs.Job(req.GetId())
+	if err != nil {
+		return statusError(err)
+	}
+	switch {
+	case !finished(info.State):
+		return status.Errorf(codes.FailedPrecondition, "job is %s", info.State)
+	case info.State != docx.JobSucceeded:
+		return status.Errorf(codes.FailedPrecondition, "job %s: %s", info.State, info.Error)
+	}
+	data, err := s.jobs.Artifact(info.ID)
+	if err != nil {
+		return statusError(err)
+	}
+	chunk := int(req.GetChunkSize())
+	if chunk <= 0 {
+		chunk = DefaultChunkSize
+	}
+	resp := &translatev1.DownloadResultResponse{Format: docx.SniffFormat(data)}
+	resp.FileExtension, resp.ContentType, _ = docx.FormatFile(resp.Format)
+	for {
+		n := chunk
+		if n > len(data) {
+			n = len(data)
+		}
+		resp.Content, data = data[:n], data[n:]
+		if err = stream.Send(resp); err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return nil
+		}
+		resp = &translatev1.DownloadResultResponse{}
+	}
+}
+
+// CancelJob implements translatev1.TranslateServiceServer
+func (s *Server) CancelJob(_ context.Context, req *translatev1.GetStatusRequest) (*translatev1.Job, error) {
+	if err := s.jobs.Cancel(req.GetId()); err != nil {
+		return nil, statusError(err)
+	}
+	info, err := s.jobs.Job(req.GetId())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return jobMessage(info), nil
+}
+
+func finished(state docx.JobState) bool {
+	return state == docx.JobSucceeded || state == docx.JobFailed || state == docx.JobCanceled
+}
+
+// statusError maps the errors of docx.JobService to gRPC status errors, following the HTTP handlers
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, docx.ErrJobNotFound), errors.Is(err, docx.ErrArtifactExpired):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, docx.ErrJobFinished), errors.Is(err, docx.ErrJobRunning):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// jobStates maps docx.JobState to the Job.State enum
+var jobStates = map[docx.JobState]translatev1.Job_State{
+	docx.JobQueued:    translatev1.Job_STATE_QUEUED,
+	docx.JobRunning:   translatev1.Job_STATE_RUNNING,
+	docx.JobSucceeded: translatev1.Job_STATE_SUCCEEDED,
+	docx.JobFailed:    translatev1.Job_STATE_FAILED,
+	docx.JobCanceled:  translatev1.Job_STATE_CANCELED,
+}
+
+// jobMessage converts a job snapshot to its message
+func jobMessage(info docx.JobInfo) *translatev1.Job {
+	return &translatev1.Job{
+		Id:             info.ID,
+		State:          jobStates[info.State],
+		Format:         info.Format,
+		TargetLanguage: info.TargetLanguage,
+		Size:           int64(info.Size),
+		Done:           int32(info.Done),
+		Total:          int32(info.Total),
+		Usage: &translatev1.Usage{
+			Segments:     int32(info.Usage.Segments),
+			Characters:   int32(info.Usage.Characters),
+			InputTokens:  int32(info.Usage.InputTokens),
+			OutputTokens: int32(info.Usage.OutputTokens),
+			Failed:       int32(info.Usage.Failed),
+		},
+		Created:      timestamp(&info.Created),
+		Started:      timestamp(info.Started),
+		Finished:     timestamp(info.Finished),
+		Error:        info.Error,
+		ArtifactSize: int64(info.ArtifactSize),
+		Expires:      timestamp(info.Expires),
+		Expired:      info.Expired,
+	}
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
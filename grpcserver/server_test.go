@@ -0,0 +1,122 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/iEvan-lhr/go-docx-translate"
+	translatev1 "github.com/iEvan-lhr/go-docx-translate/proto/docxtranslate/v1"
+)
+
+func TestServer(t *testing.T) {
+	tr := docx.NewTranslator("", "").WithProvider(docx.ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	m := docx.NewJobManager(tr)
+	lis := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	translatev1.RegisterTranslateServiceServer(g, NewServer(m.Service()).WithMaxUploadSize(64))
+	go func() { _ = g.Serve(lis) }()
+	defer g.Stop()
+	conn, err := grpc.Dial("bufnet", grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := translatev1.NewTranslateServiceClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// the document is uploaded in two chunks
+	submit, err := client.SubmitJob(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = submit.Send(&translatev1.SubmitJobRequest{TargetLanguage: "English", Format: "txt", Content: []byte("hello ")})
+	_ = submit.Send(&translatev1.SubmitJobRequest{Content: []byte("world")})
+	job, err := submit.CloseAndRecv()
+	if err != nil || job.GetId() == "" || job.GetSize() != 11 || job.GetTargetLanguage() != "English" {
+		t.Fatalf("unexpected job: %v %v", job, err)
+	}
+
+	watch, err := client.WatchProgress(ctx, &translatev1.GetStatusRequest{Id: job.GetId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last *translatev1.Job
+	for {
+		j, err := watch.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		last = j
+	}
+	if last.GetState() != translatev1.Job_STATE_SUCCEEDED || last.GetFinished() == nil || last.GetArtifactSize() != 11 {
+		t.Fatalf("unexpected last snapshot: %v", last)
+	}
+	if j, err := client.GetStatus(ctx, &translatev1.GetStatusRequest{Id: job.GetId()}); err != nil || j.GetUsage().GetSegments() == 0 {
+		t.Fatalf("unexpected status: %v %v", j, err)
+	}
+
+	download, err := client.DownloadResult(ctx, &translatev1.DownloadResultRequest{Id: job.GetId(), ChunkSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		content []byte
+		chunks  int
+	)
+	for {
+		resp, err := download.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chunks == 0 && (resp.GetFormat() != "txt" || resp.GetFileExtension() != ".txt" || !strings.HasPrefix(resp.GetContentType(), "text/plain")) {
+			t.Fatalf("unexpected metadata: %v", resp)
+		}
+		content = append(content, resp.GetContent()...)
+		chunks++
+	}
+	if string(content) != "HELLO WORLD" || chunks != 3 {
+		t.Fatalf("unexpected result in %d chunks: %q", chunks, content)
+	}
+
+	if _, err = client.CancelJob(ctx, &translatev1.GetStatusRequest{Id: job.GetId()}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatal("expected FailedPrecondition, got", err)
+	}
+	if _, err = client.GetStatus(ctx, &translatev1.GetStatusRequest{Id: "missing"}); status.Code(err) != codes.NotFound {
+		t.Fatal("expected NotFound, got", err)
+	}
+	for _, req := range []*translatev1.SubmitJobRequest{
+		{Format: "txt", Content: []byte("hello")},
+		{TargetLanguage: "English", Content: make([]byte, 65)},
+	} {
+		submit, err = client.SubmitJob(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = submit.Send(req)
+		if _, err = submit.CloseAndRecv(); status.Code(err) != codes.InvalidArgument && status.Code(err) != codes.ResourceExhausted {
+			t.Fatal("expected the upload to be rejected, got", err)
+		}
+	}
+}
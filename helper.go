@@ -68,3 +68,13 @@ func GetInt(s string) (int, error) {
 	_, err = fmt.Sscanf(s, "%d", &v)
 	return v, err
 }
+
+// isOnOff reports whether an ST_OnOff value is on, an absent value means on
+func isOnOff(s string) bool {
+	switch s {
+	case "0", "false", "off":
+		return false
+	default:
+		return true
+	}
+}
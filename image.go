@@ -21,13 +21,30 @@
 package docx
 
 import (
+	"bytes"
 	"strconv"
 	"sync/atomic"
+
+	"github.com/fumiama/imgsz"
 )
 
+// AddImage adds an image to the media of docx under a newly generated
+// name and returns its rId, which can be used as the Embed of an ABlip.
+//
+// It is safe to call AddImage concurrently.
+func (f *Docx) AddImage(data []byte) (string, error) {
+	_, format, err := imgsz.DecodeSize(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	return f.addImage(format, data), nil
+}
+
 // addImage add image to docx and return its rId
 func (f *Docx) addImage(format string, data []byte) string {
 	m := Media{Name: "image" + strconv.Itoa(int(atomic.AddUintptr(&f.imageID, 1))) + "." + format, Data: data}
+	f.mediaMu.Lock()
+	defer f.mediaMu.Unlock()
 	f.addMedia(m)
 	return f.addImageRelation(m)
 }
@@ -36,6 +36,8 @@ func (m *Media) String() string {
 
 // Media get media struct pointer (or nil on notfound) by name
 func (f *Docx) Media(name string) *Media {
+	f.mediaMu.RLock()
+	defer f.mediaMu.RUnlock()
 	i, ok := f.mediaNameIdx[name]
 	if !ok {
 		return nil
@@ -43,7 +45,8 @@ func (f *Docx) Media(name string) *Media {
 	return &f.media[i]
 }
 
-// addMedia append the media to docx's media list
+// addMedia append the media to docx's media list,
+// the caller must hold mediaMu
 func (f *Docx) addMedia(m Media) {
 	f.mediaNameIdx[m.Name] = len(f.media)
 	f.media = append(f.media, m)
@@ -0,0 +1,98 @@
+// Package mobile is a gomobile friendly facade of docx translation.
+//
+// Every exported API only uses strings, byte slices, ints and errors, so
+//
+//	gomobile bind -target=android github.com/iEvan-lhr/go-docx-translate/mobile
+//
+// produces bindings usable from Java/Kotlin and Objective-C/Swift.
+package mobile
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/iEvan-lhr/go-docx-translate"
+)
+
+// Error policies accepted by Translator.SetErrorPolicy. docx.ErrorPolicyCollect is
+// not supported: it returns the document together with an error, and gomobile
+// bindings drop the result of a call that fails.
+const (
+	ErrorPolicyKeepOriginal = int(docx.ErrorPolicyKeepOriginal)
+	ErrorPolicyFailFast     = int(docx.ErrorPolicyFailFast)
+)
+
+// Translator translates docx files held in memory
+type Translator struct {
+	t *docx.Translator
+}
+
+// NewTranslator creates a Translator calling apiURL with apiKey
+func NewTranslator(apiKey, apiURL string) *Translator {
+	return &Translator{t: docx.NewTranslator(apiKey, apiURL)}
+}
+
+// SetErrorPolicy sets one of the ErrorPolicy constants, other values return an error
+func (t *Translator) SetErrorPolicy(policy int) error {
+	switch policy {
+	case ErrorPolicyKeepOriginal, ErrorPolicyFailFast:
+		t.t.WithErrorPolicy(docx.ErrorPolicy(policy))
+		return nil
+	}
+	return fmt.Errorf("mobile: unsupported error policy %d", policy)
+}
+
+// TranslateDocx translates a docx file into targetLanguage and returns the new file
+func (t *Translator) TranslateDocx(data []byte, targetLanguage string) ([]byte, error) {
+	doc, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	newDoc, err := t.t.TranslateDocx(doc, targetLanguage)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	_, err = newDoc.WriteTo(&buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// TranslateDocx is a shortcut of NewTranslator(apiKey, apiURL).TranslateDocx(data, targetLanguage)
+func TranslateDocx(data []byte, apiKey, apiURL, targetLanguage string) ([]byte, error) {
+	return NewTranslator(apiKey, apiURL).TranslateDocx(data, targetLanguage)
+}
+
+// ExtractText returns the plain text of a docx file without any network
+// access, one paragraph per line, table cells included in reading order
+func ExtractText(data []byte) (string, error) {
+	doc, err := parse(data)
+	if err != nil {
+		return "", err
+	}
+	sb := strings.Builder{}
+	for _, it := range doc.Document.Body.Items {
+		switch o := it.(type) {
+		case *docx.Paragraph:
+			sb.WriteString(o.String())
+			sb.WriteByte('\n')
+		case *docx.Table:
+			for _, tr := range o.TableRows {
+				for _, tc := range tr.TableCells {
+					for _, p := range tc.Paragraphs {
+						sb.WriteString(p.String())
+						sb.WriteByte('\n')
+					}
+				}
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+func parse(data []byte) (*docx.Docx, error) {
+	return docx.Parse(bytes.NewReader(data), int64(len(data)))
+}
@@ -0,0 +1,72 @@
+package mobile
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/iEvan-lhr/go-docx-translate"
+)
+
+func testDocx(t *testing.T, texts ...string) []byte {
+	t.Helper()
+	doc := docx.New().WithDefaultTheme()
+	for _, text := range texts {
+		doc.AddParagraph().AddText(text)
+	}
+	tbl := doc.AddTable(1, 1, 0, nil)
+	tbl.TableRows[0].TableCells[0].AddParagraph().AddText("cell")
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractText(t *testing.T) {
+	text, err := ExtractText(testDocx(t, "hello", "world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "hello\nworld\n") || !strings.Contains(text, "cell\n") {
+		t.Fatalf("unexpected text: %q", text)
+	}
+	if _, err = ExtractText([]byte("not a docx")); err == nil {
+		t.Fatal("expected an error for invalid data")
+	}
+}
+
+func TestTranslateDocx(t *testing.T) {
+	tr := NewTranslator("", "")
+	tr.t.WithProvider(docx.ProviderFunc(func(text, _ string) (string, error) {
+		if text == "fail" {
+			return "", errors.New("boom")
+		}
+		return strings.ToUpper(text), nil
+	}))
+	out, err := tr.TranslateDocx(testDocx(t, "hello", "fail"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := ExtractText(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ErrorPolicyKeepOriginal keeps the failed paragraph
+	if !strings.Contains(text, "HELLO\nfail\n") || !strings.Contains(text, "CELL\n") {
+		t.Fatalf("unexpected translation: %q", text)
+	}
+
+	if err = tr.SetErrorPolicy(ErrorPolicyFailFast); err != nil {
+		t.Fatal(err)
+	}
+	if out, err = tr.TranslateDocx(testDocx(t, "fail"), "English"); err == nil || out != nil {
+		t.Fatal("expected an error, got", len(out), err)
+	}
+	for _, policy := range []int{int(docx.ErrorPolicyCollect), 99, -1} {
+		if err = tr.SetErrorPolicy(policy); err == nil {
+			t.Error("expected an error for policy", policy)
+		}
+	}
+}
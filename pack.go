@@ -25,6 +25,7 @@ import (
 	"bytes"
 	"encoding/xml"
 	"io"
+	"io/fs"
 	"os"
 )
 
@@ -50,6 +51,10 @@ func (f *Docx) pack(zipWriter *zip.Writer) (err error) {
 		}
 	}
 
+	for name, data := range f.parts {
+		files[name] = bytes.NewReader(data)
+	}
+
 	files["word/_rels/document.xml.rels"] = marshaller{data: &f.docRelation}
 	files["word/document.xml"] = marshaller{data: &f.Document}
 
@@ -72,6 +77,43 @@ func (f *Docx) pack(zipWriter *zip.Writer) (err error) {
 	return
 }
 
+// readPart reads a file of the package other than the document, its relationships and media.
+// Files set by setPart take precedence over the template ones.
+func (f *Docx) readPart(name string) ([]byte, error) {
+	if data, ok := f.parts[name]; ok {
+		return data, nil
+	}
+	found := false
+	for _, n := range f.tmpfslst {
+		if n == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fs.ErrNotExist
+	}
+	if f.template != "" {
+		name = "xml/" + f.template + "/" + name
+	}
+	file, err := f.tmplfs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// setPart adds or replaces a file of the package other than the document, its relationships and media
+//
+//	this func is not thread-safe
+func (f *Docx) setPart(name string, data []byte) {
+	if f.parts == nil {
+		f.parts = make(map[string][]byte, 8)
+	}
+	f.parts[name] = data
+}
+
 type marshaller struct {
 	data interface{}
 	io.Reader
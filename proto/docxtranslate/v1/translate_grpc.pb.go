@@ -0,0 +1,382 @@
+// gRPC API of the docx-translate job service.
+//
+// Each RPC maps onto a docx.JobService method, so the server in the grpcserver
+// package only adapts messages: SubmitJob -> Submit, GetStatus -> Job,
+// DownloadResult -> Artifact, WatchProgress -> Watch and CancelJob -> Cancel.
+// Errors map like the HTTP handlers: ErrJobNotFound and ErrArtifactExpired are
+// NOT_FOUND, while ErrJobFinished, ErrJobRunning and downloads of unfinished
+// jobs are FAILED_PRECONDITION.
+//
+// The Go stubs in this directory are generated with protoc-gen-go v1.31.0 and
+// protoc-gen-go-grpc v1.3.0; after editing this file regenerate them with
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	       --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+//	       proto/docxtranslate/v1/translate.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: proto/docxtranslate/v1/translate.proto
+
+package translatev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	TranslateService_SubmitJob_FullMethodName      = "/docxtranslate.v1.TranslateService/SubmitJob"
+	TranslateService_GetStatus_FullMethodName      = "/docxtranslate.v1.TranslateService/GetStatus"
+	TranslateService_WatchProgress_FullMethodName  = "/docxtranslate.v1.TranslateService/WatchProgress"
+	TranslateService_DownloadResult_FullMethodName = "/docxtranslate.v1.TranslateService/DownloadResult"
+	TranslateService_CancelJob_FullMethodName      = "/docxtranslate.v1.TranslateService/CancelJob"
+)
+
+// TranslateServiceClient is the client API for TranslateService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TranslateServiceClient interface {
+	// SubmitJob uploads a document and starts translating it in the background.
+	// Documents larger than the 4 MiB default message size are streamed as
+	// several requests; only the first one needs the options.
+	SubmitJob(ctx context.Context, opts ...grpc.CallOption) (TranslateService_SubmitJobClient, error)
+	// GetStatus returns a snapshot of a job.
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Job, error)
+	// WatchProgress streams a snapshot whenever the state or progress of a job
+	// changes, and ends once the job has finished. Consecutive changes may be
+	// merged into one message.
+	WatchProgress(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (TranslateService_WatchProgressClient, error)
+	// DownloadResult streams the translated document of a succeeded job in
+	// chunks; the first message carries the file metadata.
+	DownloadResult(ctx context.Context, in *DownloadResultRequest, opts ...grpc.CallOption) (TranslateService_DownloadResultClient, error)
+	// CancelJob stops a running job after its current segment.
+	CancelJob(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Job, error)
+}
+
+type translateServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTranslateServiceClient(cc grpc.ClientConnInterface) TranslateServiceClient {
+	return &translateServiceClient{cc}
+}
+
+func (c *translateServiceClient) SubmitJob(ctx context.Context, opts ...grpc.CallOption) (TranslateService_SubmitJobClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TranslateService_ServiceDesc.Streams[0], TranslateService_SubmitJob_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &translateServiceSubmitJobClient{stream}
+	return x, nil
+}
+
+type TranslateService_SubmitJobClient interface {
+	Send(*SubmitJobRequest) error
+	CloseAndRecv() (*Job, error)
+	grpc.ClientStream
+}
+
+type translateServiceSubmitJobClient struct {
+	grpc.ClientStream
+}
+
+func (x *translateServiceSubmitJobClient) Send(m *SubmitJobRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *translateServiceSubmitJobClient) CloseAndRecv() (*Job, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Job)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *translateServiceClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, TranslateService_GetStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *translateServiceClient) WatchProgress(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (TranslateService_WatchProgressClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TranslateService_ServiceDesc.Streams[1], TranslateService_WatchProgress_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &translateServiceWatchProgressClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TranslateService_WatchProgressClient interface {
+	Recv() (*Job, error)
+	grpc.ClientStream
+}
+
+type translateServiceWatchProgressClient struct {
+	grpc.ClientStream
+}
+
+func (x *translateServiceWatchProgressClient) Recv() (*Job, error) {
+	m := new(Job)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *translateServiceClient) DownloadResult(ctx context.Context, in *DownloadResultRequest, opts ...grpc.CallOption) (TranslateService_DownloadResultClient, error) {
+	stream, err := c.cc.NewStream(ctx, &TranslateService_ServiceDesc.Streams[2], TranslateService_DownloadResult_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &translateServiceDownloadResultClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TranslateService_DownloadResultClient interface {
+	Recv() (*DownloadResultResponse, error)
+	grpc.ClientStream
+}
+
+type translateServiceDownloadResultClient struct {
+	grpc.ClientStream
+}
+
+func (x *translateServiceDownloadResultClient) Recv() (*DownloadResultResponse, error) {
+	m := new(DownloadResultResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *translateServiceClient) CancelJob(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*Job, error) {
+	out := new(Job)
+	err := c.cc.Invoke(ctx, TranslateService_CancelJob_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TranslateServiceServer is the server API for TranslateService service.
+// All implementations must embed UnimplementedTranslateServiceServer
+// for forward compatibility
+type TranslateServiceServer interface {
+	// SubmitJob uploads a document and starts translating it in the background.
+	// Documents larger than the 4 MiB default message size are streamed as
+	// several requests; only the first one needs the options.
+	SubmitJob(TranslateService_SubmitJobServer) error
+	// GetStatus returns a snapshot of a job.
+	GetStatus(context.Context, *GetStatusRequest) (*Job, error)
+	// WatchProgress streams a snapshot whenever the state or progress of a job
+	// changes, and ends once the job has finished. Consecutive changes may be
+	// merged into one message.
+	WatchProgress(*GetStatusRequest, TranslateService_WatchProgressServer) error
+	// DownloadResult streams the translated document of a succeeded job in
+	// chunks; the first message carries the file metadata.
+	DownloadResult(*DownloadResultRequest, TranslateService_DownloadResultServer) error
+	// CancelJob stops a running job after its current segment.
+	CancelJob(context.Context, *GetStatusRequest) (*Job, error)
+	mustEmbedUnimplementedTranslateServiceServer()
+}
+
+// UnimplementedTranslateServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedTranslateServiceServer struct {
+}
+
+func (UnimplementedTranslateServiceServer) SubmitJob(TranslateService_SubmitJobServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubmitJob not implemented")
+}
+func (UnimplementedTranslateServiceServer) GetStatus(context.Context, *GetStatusRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedTranslateServiceServer) WatchProgress(*GetStatusRequest, TranslateService_WatchProgressServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchProgress not implemented")
+}
+func (UnimplementedTranslateServiceServer) DownloadResult(*DownloadResultRequest, TranslateService_DownloadResultServer) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadResult not implemented")
+}
+func (UnimplementedTranslateServiceServer) CancelJob(context.Context, *GetStatusRequest) (*Job, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelJob not implemented")
+}
+func (UnimplementedTranslateServiceServer) mustEmbedUnimplementedTranslateServiceServer() {}
+
+// UnsafeTranslateServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TranslateServiceServer will
+// result in compilation errors.
+type UnsafeTranslateServiceServer interface {
+	mustEmbedUnimplementedTranslateServiceServer()
+}
+
+func RegisterTranslateServiceServer(s grpc.ServiceRegistrar, srv TranslateServiceServer) {
+	s.RegisterService(&TranslateService_ServiceDesc, srv)
+}
+
+func _TranslateService_SubmitJob_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TranslateServiceServer).SubmitJob(&translateServiceSubmitJobServer{stream})
+}
+
+type TranslateService_SubmitJobServer interface {
+	SendAndClose(*Job) error
+	Recv() (*SubmitJobRequest, error)
+	grpc.ServerStream
+}
+
+type translateServiceSubmitJobServer struct {
+	grpc.ServerStream
+}
+
+func (x *translateServiceSubmitJobServer) SendAndClose(m *Job) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *translateServiceSubmitJobServer) Recv() (*SubmitJobRequest, error) {
+	m := new(SubmitJobRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _TranslateService_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranslateServiceServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranslateService_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranslateServiceServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TranslateService_WatchProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GetStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TranslateServiceServer).WatchProgress(m, &translateServiceWatchProgressServer{stream})
+}
+
+type TranslateService_WatchProgressServer interface {
+	Send(*Job) error
+	grpc.ServerStream
+}
+
+type translateServiceWatchProgressServer struct {
+	grpc.ServerStream
+}
+
+func (x *translateServiceWatchProgressServer) Send(m *Job) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TranslateService_DownloadResult_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadResultRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TranslateServiceServer).DownloadResult(m, &translateServiceDownloadResultServer{stream})
+}
+
+type TranslateService_DownloadResultServer interface {
+	Send(*DownloadResultResponse) error
+	grpc.ServerStream
+}
+
+type translateServiceDownloadResultServer struct {
+	grpc.ServerStream
+}
+
+func (x *translateServiceDownloadResultServer) Send(m *DownloadResultResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TranslateService_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TranslateServiceServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TranslateService_CancelJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TranslateServiceServer).CancelJob(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TranslateService_ServiceDesc is the grpc.ServiceDesc for TranslateService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TranslateService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "docxtranslate.v1.TranslateService",
+	HandlerType: (*TranslateServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _TranslateService_GetStatus_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _TranslateService_CancelJob_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubmitJob",
+			Handler:       _TranslateService_SubmitJob_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchProgress",
+			Handler:       _TranslateService_WatchProgress_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "DownloadResult",
+			Handler:       _TranslateService_DownloadResult_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/docxtranslate/v1/translate.proto",
+}
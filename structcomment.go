@@ -0,0 +1,166 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// XMLNS_W14 is the namespace of the Word 2010 extensions, such as the paragraph IDs that link comment replies
+const XMLNS_W14 = `http://schemas.microsoft.com/office/word/2010/wordml`
+
+// CommentRangeStart marks the start of the text a comment is anchored to <w:commentRangeStart>
+type CommentRangeStart struct {
+	XMLName xml.Name `xml:"w:commentRangeStart"`
+	ID      string   `xml:"w:id,attr"`
+}
+
+// CommentRangeEnd marks the end of the text a comment is anchored to <w:commentRangeEnd>
+type CommentRangeEnd struct {
+	XMLName xml.Name `xml:"w:commentRangeEnd"`
+	ID      string   `xml:"w:id,attr"`
+}
+
+// CommentReference is the mark of a comment shown in the text, placed in a run <w:commentReference>
+type CommentReference struct {
+	XMLName xml.Name `xml:"w:commentReference"`
+	ID      string   `xml:"w:id,attr"`
+}
+
+// AnnotationRef is the mark of a comment at the start of its own content, placed in a run <w:annotationRef>
+type AnnotationRef struct {
+	XMLName xml.Name `xml:"w:annotationRef"`
+}
+
+// Comments is the root of the comments part (word/comments.xml) <w:comments>
+type Comments struct {
+	XMLName  xml.Name `xml:"w:comments"`
+	XMLW     string   `xml:"xmlns:w,attr"`
+	XMLR     string   `xml:"xmlns:r,attr,omitempty"`
+	XMLWP    string   `xml:"xmlns:wp,attr,omitempty"`
+	XMLM     string   `xml:"xmlns:m,attr,omitempty"`
+	XMLW14   string   `xml:"xmlns:w14,attr,omitempty"`
+	Comments []*Comment
+
+	file *Docx
+}
+
+// UnmarshalXML ...
+func (c *Comments) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if tt.Name.Local != "comment" {
+			if err = d.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		value := Comment{file: c.file}
+		if err = d.DecodeElement(&value, &tt); err != nil {
+			return err
+		}
+		c.Comments = append(c.Comments, &value)
+	}
+	return nil
+}
+
+// Comment is a comment and its content <w:comment>
+type Comment struct {
+	XMLName    xml.Name `xml:"w:comment"`
+	ID         string   `xml:"w:id,attr"`
+	Author     string   `xml:"w:author,attr,omitempty"`
+	Date       string   `xml:"w:date,attr,omitempty"`
+	Initials   string   `xml:"w:initials,attr,omitempty"`
+	Paragraphs []*Paragraph
+
+	// paraIDs are the w14:paraId of the paragraphs, which commentsExtended.xml uses to link replies to their parent
+	paraIDs []string
+
+	file *Docx
+}
+
+// UnmarshalXML ...
+func (c *Comment) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	c.ID = getAtt(start.Attr, "id")
+	c.Author = getAtt(start.Attr, "author")
+	c.Date = getAtt(start.Attr, "date")
+	c.Initials = getAtt(start.Attr, "initials")
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if tt.Name.Local != "p" {
+			if err = d.Skip(); err != nil {
+				return err
+			}
+			continue
+		}
+		value := Paragraph{file: c.file}
+		if err = d.DecodeElement(&value, &tt); err != nil {
+			return err
+		}
+		c.Paragraphs = append(c.Paragraphs, &value)
+		c.paraIDs = append(c.paraIDs, getAtt(tt.Attr, "paraId"))
+	}
+	return nil
+}
+
+// MarshalXML ...
+func (c *Comment) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Local: "w:comment"}, Attr: []xml.Attr{{Name: xml.Name{Local: "w:id"}, Value: c.ID}}}
+	for _, a := range [...][2]string{{"w:author", c.Author}, {"w:date", c.Date}, {"w:initials", c.Initials}} {
+		if a[1] != "" {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: a[0]}, Value: a[1]})
+		}
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for i, p := range c.Paragraphs {
+		ps := xml.StartElement{Name: xml.Name{Local: "w:p"}}
+		if i < len(c.paraIDs) && c.paraIDs[i] != "" {
+			ps.Attr = []xml.Attr{{Name: xml.Name{Local: "w14:paraId"}, Value: c.paraIDs[i]}}
+		}
+		if err := e.EncodeElement(p, ps); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
@@ -37,6 +37,7 @@ const (
 	XMLNS_WPC = `http://schemas.microsoft.com/office/word/2010/wordprocessingCanvas`
 	XMLNS_WPG = `http://schemas.microsoft.com/office/word/2010/wordprocessingGroup`
 	XMLNS_MC  = `http://schemas.openxmlformats.org/markup-compatibility/2006`
+	XMLNS_M   = `http://schemas.openxmlformats.org/officeDocument/2006/math`
 	// XMLNS_WP14 = `http://schemas.microsoft.com/office/word/2010/wordprocessingDrawing`
 
 	XMLNS_O = `urn:schemas-microsoft-com:office:office`
@@ -77,23 +78,59 @@ func (b *Body) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			case "p":
 				var value Paragraph
 				value.file = b.file
-				err = d.DecodeElement(&value, &tt)
+				err = b.file.decodeElement(d, &value, &tt)
 				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					if b.file.tolerate("word/document.xml", tt.Name.Local, err) {
+						continue
+					}
 					return err
 				}
 				b.Items = append(b.Items, &value)
 			case "tbl":
 				var value Table
 				value.file = b.file
-				err = d.DecodeElement(&value, &tt)
+				err = b.file.decodeElement(d, &value, &tt)
 				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					if b.file.tolerate("word/document.xml", tt.Name.Local, err) {
+						continue
+					}
 					return err
 				}
 				b.Items = append(b.Items, &value)
+			case "bookmarkStart":
+				b.Items = append(b.Items, &BookmarkStart{
+					ID:       getAtt(tt.Attr, "id"),
+					Name:     getAtt(tt.Attr, "name"),
+					ColFirst: getAtt(tt.Attr, "colFirst"),
+					ColLast:  getAtt(tt.Attr, "colLast"),
+				})
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "bookmarkEnd":
+				b.Items = append(b.Items, &BookmarkEnd{ID: getAtt(tt.Attr, "id")})
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "sdt":
+				value := &SDT{file: b.file}
+				err = b.file.decodeElement(d, value, &tt)
+				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					if b.file.tolerate("word/document.xml", tt.Name.Local, err) {
+						continue
+					}
+					return err
+				}
+				b.Items = append(b.Items, value)
 			case "sectPr":
 				var value SectPr
-				err = d.DecodeElement(&value, &tt)
+				err = b.file.decodeElement(d, &value, &tt)
 				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					if b.file.tolerate("word/document.xml", tt.Name.Local, err) {
+						continue
+					}
 					return err
 				}
 				b.Items = append(b.Items, &value)
@@ -110,7 +147,7 @@ func (b *Body) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 
 // KeepElements keep named elems amd removes others
 //
-// names: *docx.Paragraph *docx.Table
+// names: *docx.Paragraph *docx.Table *docx.SDT *docx.BookmarkStart *docx.BookmarkEnd
 func (b *Body) KeepElements(name ...string) {
 	items := make([]interface{}, 0, len(b.Items))
 	namemap := make(map[string]struct{}, len(name)*2)
@@ -153,6 +190,53 @@ func (b *Body) DropDrawingOf(name string) {
 	}
 }
 
+// RangeParagraphs goes through each paragraph in body in reading order,
+// including the ones inside (nested) table cells and content controls
+func (b *Body) RangeParagraphs(iter func(*Paragraph) error) error {
+	for _, item := range b.Items {
+		switch o := item.(type) {
+		case *Paragraph:
+			err := iter(o)
+			if err != nil {
+				return err
+			}
+		case *Table:
+			err := o.RangeParagraphs(iter)
+			if err != nil {
+				return err
+			}
+		case *SDT:
+			err := o.RangeParagraphs(iter)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RangeParagraphs goes through each paragraph in table cells, row by row,
+// including the ones inside nested tables
+func (t *Table) RangeParagraphs(iter func(*Paragraph) error) error {
+	for _, tr := range t.TableRows {
+		for _, tc := range tr.TableCells {
+			for _, item := range tc.Items() {
+				var err error
+				switch o := item.(type) {
+				case *Paragraph:
+					err = iter(o)
+				case *Table:
+					err = o.RangeParagraphs(iter)
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // Document <w:document>
 type Document struct {
 	XMLName xml.Name `xml:"w:document"`
@@ -162,6 +246,7 @@ type Document struct {
 	XMLWPS  string   `xml:"xmlns:wps,attr,omitempty"` // cannot be unmarshalled in
 	XMLWPC  string   `xml:"xmlns:wpc,attr,omitempty"` // cannot be unmarshalled in
 	XMLWPG  string   `xml:"xmlns:wpg,attr,omitempty"` // cannot be unmarshalled in
+	XMLM    string   `xml:"xmlns:m,attr,omitempty"`   // cannot be unmarshalled in
 	// XMLMC   string   `xml:"xmlns:mc,attr,omitempty"`  // cannot be unmarshalled in
 	// XMLWP14 string   `xml:"xmlns:wp14,attr,omitempty"` // cannot be unmarshalled in
 
@@ -227,6 +312,7 @@ newdoclop:
 		ndoc.template = f.template
 		ndoc.tmplfs = f.tmplfs
 		ndoc.tmpfslst = f.tmpfslst
+		ndoc.parts = f.parts
 
 		ndoc.Document.XMLW = XMLNS_W
 		ndoc.Document.XMLR = XMLNS_R
@@ -237,6 +323,7 @@ newdoclop:
 		ndoc.Document.XMLWPS = XMLNS_WPS
 		ndoc.Document.XMLWPC = XMLNS_WPC
 		ndoc.Document.XMLWPG = XMLNS_WPG
+		ndoc.Document.XMLM = XMLNS_M
 		// ndoc.Document.XMLWP14 = XMLNS_WP14
 		ndoc.Document.XMLName.Space = XMLNS_W
 		ndoc.Document.XMLName.Local = "document"
@@ -278,6 +365,8 @@ newdoclop:
 			case *Table:
 				nt := o.copymedia(ndoc)
 				ndoc.Document.Body.Items = append(ndoc.Document.Body.Items, &nt)
+			case *SDT:
+				ndoc.Document.Body.Items = append(ndoc.Document.Body.Items, o.copymedia(ndoc))
 			default:
 				ndoc.Document.Body.Items = append(ndoc.Document.Body.Items, o)
 			}
@@ -296,11 +385,20 @@ func (r *Run) copymedia(to *Docx) *Run {
 	nr.Children = make([]interface{}, 0, len(r.Children))
 	nr.file = to
 	for _, rc := range r.Children {
-		if d, ok := rc.(*Drawing); ok {
-			nr.Children = append(nr.Children, d.copymedia(to))
-			continue
+		switch o := rc.(type) {
+		case *Drawing:
+			nr.Children = append(nr.Children, o.copymedia(to))
+		case *Text:
+			nt := *o
+			nr.Children = append(nr.Children, &nt)
+		case *FldChar:
+			nf := *o
+			nr.Children = append(nr.Children, &nf)
+		case *Object:
+			nr.Children = append(nr.Children, o.copymedia(to))
+		default:
+			nr.Children = append(nr.Children, rc)
 		}
-		nr.Children = append(nr.Children, rc)
 	}
 	return &nr
 }
@@ -315,15 +413,28 @@ func (p *Paragraph) copymedia(to *Docx) (np Paragraph) {
 			continue
 		}
 		if h, ok := pc.(*Hyperlink); ok {
-			tgt, err := p.file.ReferTarget(h.ID)
-			if err != nil {
-				continue
+			nh := *h
+			nh.Run = *h.Run.copymedia(to)
+			if h.ID != "" {
+				tgt, err := p.file.ReferTarget(h.ID)
+				if err != nil {
+					continue
+				}
+				nh.ID = to.addLinkRelation(tgt)
 			}
-			rid := to.addLinkRelation(tgt)
-			np.Children = append(np.Children, &Hyperlink{
-				ID:  rid,
-				Run: *h.Run.copymedia(to),
-			})
+			np.Children = append(np.Children, &nh)
+			continue
+		}
+		if s, ok := pc.(*SDT); ok {
+			np.Children = append(np.Children, s.copymedia(to))
+			continue
+		}
+		switch o := pc.(type) {
+		case *Ins:
+			np.Children = append(np.Children, o.copymedia(to))
+			continue
+		case *Del:
+			np.Children = append(np.Children, o.copymedia(to))
 			continue
 		}
 		np.Children = append(np.Children, pc)
@@ -347,6 +458,11 @@ func (t *Table) copymedia(to *Docx) (nt Table) {
 				np := p.copymedia(to)
 				ntc.Paragraphs = append(ntc.Paragraphs, &np)
 			}
+			ntc.Tables = make([]*Table, 0, len(tc.Tables))
+			for _, tbl := range tc.Tables {
+				nt := tbl.copymedia(to)
+				ntc.Tables = append(ntc.Tables, &nt)
+			}
 			ntr.TableCells = append(ntr.TableCells, &ntc)
 		}
 		nt.TableRows = append(nt.TableRows, &ntr)
@@ -364,6 +480,8 @@ func (f *Docx) AppendFile(af *Docx) {
 		case *Table:
 			nt := o.copymedia(f)
 			f.Document.Body.Items = append(f.Document.Body.Items, &nt)
+		case *SDT:
+			f.Document.Body.Items = append(f.Document.Body.Items, o.copymedia(f))
 		default:
 			f.Document.Body.Items = append(f.Document.Body.Items, o)
 		}
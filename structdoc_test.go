@@ -34,7 +34,7 @@ func TestUnmarshalPlainStructure(t *testing.T) {
 		numParagraphs int
 	}{
 		{decoded_doc_1, 6},
-		{decoded_doc_2, 15},
+		{decoded_doc_2, 16},
 	}
 	for _, tc := range testCases {
 		doc := Document{
@@ -59,10 +59,17 @@ func TestUnmarshalPlainStructure(t *testing.T) {
 					if child == nil {
 						t.Fatalf("There are Paragraph children with all fields nil")
 					}
-					if o, ok := child.(*Hyperlink); ok && o.ID == "" {
-						t.Fatalf("We have a link without ID")
+					if o, ok := child.(*Hyperlink); ok && o.ID == "" && o.Anchor == "" {
+						t.Fatalf("We have a link without ID or anchor")
 					}
 				}
+			case *SDT:
+				if v.Properties == nil || v.Properties.DocPartObj == nil || v.Properties.DocPartObj.DocPartGallery.Val != "Table of Contents" {
+					t.Fatalf("We were not able to parse sdtPr")
+				}
+				if v.Content == nil || len(v.Content.Items) != 1 {
+					t.Fatalf("We were not able to parse sdtContent")
+				}
 			case *SectPr:
 				if v.PgSz.W != 11906 || v.PgSz.H != 16838 {
 					t.Fatalf("We were not able to parse sectPr")
@@ -314,6 +314,9 @@ func (r *WPInline) copymedia(to *Docx) *WPInline { //nolint: dupl
 				ID:   idn,
 				Name: "图片 " + ids,
 			}
+			if r.DocPr != nil {
+				inln.DocPr.Descr, inln.DocPr.Title = r.DocPr.Descr, r.DocPr.Title
+			}
 			pic.NonVisualPicProperties = &PICNonVisualPicProperties{
 				NonVisualDrawingProperties: NonVisualProperties{
 					ID:   id,
@@ -406,6 +409,8 @@ type WPDocPr struct {
 	XMLName xml.Name `xml:"wp:docPr,omitempty"`
 	ID      int      `xml:"id,attr"`
 	Name    string   `xml:"name,attr,omitempty"`
+	Descr   string   `xml:"descr,attr,omitempty"` // Descr is the alternative text
+	Title   string   `xml:"title,attr,omitempty"`
 }
 
 // UnmarshalXML ...
@@ -420,6 +425,10 @@ func (r *WPDocPr) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 			r.ID = id
 		case "name":
 			r.Name = attr.Value
+		case "descr":
+			r.Descr = attr.Value
+		case "title":
+			r.Title = attr.Value
 
 		default:
 			// ignore other attributes
@@ -1404,6 +1413,9 @@ func (r *WPAnchor) copymedia(to *Docx) *WPAnchor { //nolint: dupl
 				ID:   idn,
 				Name: "图片 " + ids,
 			}
+			if r.DocPr != nil {
+				anch.DocPr.Descr, anch.DocPr.Title = r.DocPr.Descr, r.DocPr.Title
+			}
 			pic.NonVisualPicProperties = &PICNonVisualPicProperties{
 				NonVisualDrawingProperties: NonVisualProperties{
 					ID:   id,
@@ -116,6 +116,23 @@ type Strike struct {
 	Val     string   `xml:"w:val,attr"`
 }
 
+// Vanish hides the text of the run, which Word shows only when hidden text is displayed
+type Vanish struct {
+	XMLName xml.Name `xml:"w:vanish,omitempty"`
+}
+
+// RTL show the text of the run is read from right to left
+type RTL struct{}
+
+// Lang specifies the languages used to check spelling and grammar of the run,
+// Val for latin text, EastAsia for east asian text and Bidi for complex script text
+type Lang struct {
+	XMLName  xml.Name `xml:"w:lang,omitempty"`
+	Val      string   `xml:"w:val,attr,omitempty"`
+	EastAsia string   `xml:"w:eastAsia,attr,omitempty"`
+	Bidi     string   `xml:"w:bidi,attr,omitempty"`
+}
+
 // Shade is an element that represents a shading pattern applied to a document element.
 type Shade struct {
 	XMLName       xml.Name `xml:"w:shd,omitempty"`
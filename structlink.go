@@ -29,8 +29,29 @@ import (
 // Hyperlink element contains links
 type Hyperlink struct {
 	XMLName xml.Name `xml:"w:hyperlink,omitempty"`
-	ID      string   `xml:"r:id,attr"`
+	ID      string   `xml:"r:id,attr,omitempty"`
+	Anchor  string   `xml:"w:anchor,attr,omitempty"`  // bookmark in this document, used when ID is empty
+	Tooltip string   `xml:"w:tooltip,attr,omitempty"` // text shown when hovering the link
+	History string   `xml:"w:history,attr,omitempty"`
 	Run     Run
+	// Revision is nil or the tracked insertion (*Ins) or deletion (*Del) that Run is written in,
+	// e.g. <w:hyperlink><w:ins><w:r>...</w:r></w:ins></w:hyperlink>; its own Children are replaced by Run
+	Revision interface{} `xml:"-"`
+}
+
+// BookmarkStart marks the start of a bookmark, the target of internal links and cross-references
+type BookmarkStart struct {
+	XMLName  xml.Name `xml:"w:bookmarkStart,omitempty"`
+	ID       string   `xml:"w:id,attr"`
+	Name     string   `xml:"w:name,attr"`
+	ColFirst string   `xml:"w:colFirst,attr,omitempty"` // first table column covered by the bookmark
+	ColLast  string   `xml:"w:colLast,attr,omitempty"`
+}
+
+// BookmarkEnd marks the end of the bookmark with the same ID
+type BookmarkEnd struct {
+	XMLName xml.Name `xml:"w:bookmarkEnd,omitempty"`
+	ID      string   `xml:"w:id,attr"`
 }
 
 // UnmarshalXML ...
@@ -45,12 +66,29 @@ func (r *Hyperlink) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 		}
 
 		if tt, ok := t.(xml.StartElement); ok {
-			if tt.Name.Local == "r" {
+			switch tt.Name.Local {
+			case "r":
 				err = d.DecodeElement(&r.Run, &tt)
 				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
 					return err
 				}
 				continue
+			case "ins", "moveTo":
+				value := &Ins{}
+				if err = d.DecodeElement(value, &tt); err != nil {
+					return err
+				}
+				r.Revision = value
+				r.addRuns(value.Children)
+				continue
+			case "del", "moveFrom":
+				value := &Del{}
+				if err = d.DecodeElement(value, &tt); err != nil {
+					return err
+				}
+				r.Revision = value
+				r.addRuns(value.Children)
+				continue
 			}
 			err = d.Skip() // skip unsupported tags
 			if err != nil {
@@ -60,3 +98,45 @@ func (r *Hyperlink) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 	}
 	return nil
 }
+
+// addRuns merges the runs of a revision into Run, like the runs directly in the link
+func (r *Hyperlink) addRuns(children []interface{}) {
+	for _, c := range children {
+		if run, ok := c.(*Run); ok {
+			if r.Run.RunProperties == nil {
+				r.Run.RunProperties = run.RunProperties
+			}
+			r.Run.Children = append(r.Run.Children, run.Children...)
+		}
+	}
+}
+
+// MarshalXML ...
+func (r *Hyperlink) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{Name: xml.Name{Local: "w:hyperlink"}}
+	for _, a := range [...][2]string{{"r:id", r.ID}, {"w:anchor", r.Anchor}, {"w:tooltip", r.Tooltip}, {"w:history", r.History}} {
+		if a[1] != "" {
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: a[0]}, Value: a[1]})
+		}
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	var err error
+	switch rev := r.Revision.(type) {
+	case *Ins:
+		ins := *rev
+		ins.Children = []interface{}{&r.Run}
+		err = e.Encode(&ins)
+	case *Del:
+		del := *rev
+		del.Children = []interface{}{&r.Run}
+		err = e.Encode(&del)
+	default:
+		err = e.Encode(&r.Run)
+	}
+	if err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
@@ -0,0 +1,56 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import "encoding/xml"
+
+// Math is an inline OMML equation <m:oMath>, kept verbatim
+type Math struct {
+	XMLName xml.Name `xml:"m:oMath"`
+	RawXML  string   `xml:",innerxml"`
+}
+
+// MathPara is a display OMML equation <m:oMathPara>, kept verbatim
+type MathPara struct {
+	XMLName xml.Name `xml:"m:oMathPara"`
+	RawXML  string   `xml:",innerxml"`
+}
+
+// rawXML decodes the inner XML of the element start
+func rawXML(d *xml.Decoder, start xml.StartElement) (string, error) {
+	var raw struct {
+		RawXML string `xml:",innerxml"`
+	}
+	err := d.DecodeElement(&raw, &start)
+	return raw.RawXML, err
+}
+
+// UnmarshalXML ...
+func (m *Math) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
+	m.RawXML, err = rawXML(d, start)
+	return
+}
+
+// UnmarshalXML ...
+func (m *MathPara) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
+	m.RawXML, err = rawXML(d, start)
+	return
+}
@@ -25,8 +25,8 @@ import (
 // NumProperties show the number properties
 type NumProperties struct {
 	XMLName xml.Name `xml:"w:numPr,omitempty"`
+	Ilvl    *Ilevel  // ilvl precedes numId in the schema
 	NumID   *NumID
-	Ilvl    *Ilevel
 }
 
 // NumID show the number id
@@ -0,0 +1,123 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+//nolint:revive,stylecheck
+const XMLNS_W10 = `urn:schemas-microsoft-com:office:word`
+
+// Object is an embedded or linked OLE object <w:object>, e.g. an Excel sheet or a Visio diagram.
+// Its VML preview and <o:OLEObject> are kept verbatim, the parts they refer to are copied with the object.
+type Object struct {
+	XMLName xml.Name `xml:"w:object"`
+	XMLV    string   `xml:"xmlns:v,attr,omitempty"`
+	XMLO    string   `xml:"xmlns:o,attr,omitempty"`
+	XMLW10  string   `xml:"xmlns:w10,attr,omitempty"`
+	DxaOrig string   `xml:"w:dxaOrig,attr,omitempty"`
+	DyaOrig string   `xml:"w:dyaOrig,attr,omitempty"`
+	RawXML  string   `xml:",innerxml"`
+
+	file *Docx
+}
+
+// UnmarshalXML ...
+func (o *Object) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
+	o.DxaOrig = getAtt(start.Attr, "dxaOrig")
+	o.DyaOrig = getAtt(start.Attr, "dyaOrig")
+	// the prefixes in the verbatim content are declared on the object itself
+	o.XMLV, o.XMLO, o.XMLW10 = XMLNS_V, XMLNS_O, XMLNS_W10
+	o.RawXML, err = rawXML(d, start)
+	return
+}
+
+// objectRelRe matches the relationship references in the content of an object
+var objectRelRe = regexp.MustCompile(`(\br:id=")([^"]*)(")`)
+
+func (o *Object) copymedia(to *Docx) *Object {
+	no := *o
+	no.file = to
+	if o.file == nil || o.file == to {
+		return &no
+	}
+	no.RawXML = objectRelRe.ReplaceAllStringFunc(o.RawXML, func(m string) string {
+		sub := objectRelRe.FindStringSubmatch(m)
+		id, ok := o.file.copyRelation(to, sub[2])
+		if !ok {
+			return m
+		}
+		return sub[1] + id + sub[3]
+	})
+	return &no
+}
+
+// copyRelation copies the relationship id of the document to the document to together with its target,
+// and returns its ID in to. Images are added as new media, other parts such as embeddings keep their names
+// and are shared by all the references to them.
+func (f *Docx) copyRelation(to *Docx, id string) (string, bool) {
+	var rel *Relationship
+	for i := range f.docRelation.Relationship {
+		if f.docRelation.Relationship[i].ID == id {
+			rel = &f.docRelation.Relationship[i]
+			break
+		}
+	}
+	if rel == nil {
+		return "", false
+	}
+	if rel.TargetMode != REL_TARGETMODE && strings.HasPrefix(resolveTarget("word", rel.Target), MEDIA_FOLDER) {
+		m := f.Media(strings.TrimPrefix(resolveTarget("word", rel.Target), MEDIA_FOLDER))
+		if m == nil {
+			return "", false
+		}
+		return to.addImage(strings.TrimPrefix(path.Ext(m.Name), "."), m.Data), true
+	}
+
+	to.mediaMu.Lock()
+	defer to.mediaMu.Unlock()
+	for _, r := range to.docRelation.Relationship {
+		if r.Type == rel.Type && r.Target == rel.Target && r.TargetMode == rel.TargetMode {
+			return r.ID, true
+		}
+	}
+	if rel.TargetMode != REL_TARGETMODE {
+		name := resolveTarget("word", rel.Target)
+		data, err := f.readPart(name)
+		if err != nil {
+			return "", false
+		}
+		if ct := contentTypeOf(f, "/"+name); ct != "" && !addContentType(to, "/"+name, ct) {
+			return "", false
+		}
+		to.setPart(name, data)
+	}
+	nrel := *rel
+	nrel.ID = "rId" + strconv.Itoa(int(atomic.AddUintptr(&to.rID, 1)))
+	to.docRelation.Relationship = append(to.docRelation.Relationship, nrel)
+	return nrel.ID, true
+}
@@ -28,24 +28,27 @@ import (
 	"strings"
 )
 
-// ParagraphProperties <w:pPr>
+// ParagraphProperties <w:pPr>, the style and the numbering come first as the schema requires
 type ParagraphProperties struct {
-	XMLName        xml.Name `xml:"w:pPr,omitempty"`
-	Tabs           *Tabs
-	Spacing        *Spacing
-	NumProperties  *NumProperties
-	Ind            *Ind
-	Justification  *Justification
-	Shade          *Shade
-	Kern           *Kern
-	Style          *Style
-	TextAlignment  *TextAlignment
-	AdjustRightInd *AdjustRightInd
-	SnapToGrid     *SnapToGrid
-	Kinsoku        *Kinsoku
-	OverflowPunct  *OverflowPunct
+	XMLName         xml.Name `xml:"w:pPr,omitempty"`
+	Style           *Style
+	PageBreakBefore *PageBreakBefore `xml:"w:pageBreakBefore,omitempty"` // the paragraph starts on a new page
+	NumProperties   *NumProperties
+	Tabs            *Tabs
+	Bidi            *Bidi `xml:"w:bidi,omitempty"` // the paragraph is laid out from right to left
+	Spacing         *Spacing
+	Ind             *Ind
+	Justification   *Justification
+	Shade           *Shade
+	Kern            *Kern
+	TextAlignment   *TextAlignment
+	AdjustRightInd  *AdjustRightInd
+	SnapToGrid      *SnapToGrid
+	Kinsoku         *Kinsoku
+	OverflowPunct   *OverflowPunct
 
 	RunProperties *RunProperties
+	SectPr        *SectPr // properties of the section ending with this paragraph
 }
 
 // UnmarshalXML ...
@@ -81,6 +84,14 @@ func (p *ParagraphProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 					return err
 				}
 				p.Ind = &value
+			case "bidi":
+				if isOnOff(getAtt(tt.Attr, "val")) {
+					p.Bidi = &Bidi{}
+				}
+			case "pageBreakBefore":
+				if isOnOff(getAtt(tt.Attr, "val")) {
+					p.PageBreakBefore = &PageBreakBefore{}
+				}
 			case "jc":
 				p.Justification = &Justification{Val: getAtt(tt.Attr, "val")}
 			case "shd":
@@ -108,6 +119,13 @@ func (p *ParagraphProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 					return err
 				}
 				p.RunProperties = &value
+			case "sectPr":
+				var value SectPr
+				err = d.DecodeElement(&value, &tt)
+				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					return err
+				}
+				p.SectPr = &value
 			case "pStyle":
 				p.Style = &Style{Val: getAtt(tt.Attr, "val")}
 			case "numPr":
@@ -176,6 +194,9 @@ func (p *ParagraphProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 	return nil
 }
 
+// PageBreakBefore show the paragraph is rendered on the next page
+type PageBreakBefore struct{}
+
 // Paragraph <w:p>
 type Paragraph struct {
 	XMLName xml.Name `xml:"w:p,omitempty"`
@@ -208,9 +229,12 @@ func (p *Paragraph) String() string {
 			sb.WriteString("[")
 			sb.WriteString(text)
 			sb.WriteString("](")
-			if err != nil {
+			switch {
+			case id == "":
+				sb.WriteString("#" + o.Anchor)
+			case err != nil:
 				sb.WriteString(id)
-			} else {
+			default:
 				sb.WriteString(link)
 			}
 			sb.WriteByte(')')
@@ -275,15 +299,75 @@ func (p *Paragraph) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
 					return err
 				}
-				id := getAtt(tt.Attr, "id")
-				anchor := getAtt(tt.Attr, "anchor")
-				if id != "" {
-					value.ID = id
+				value.ID = getAtt(tt.Attr, "id")
+				value.Anchor = getAtt(tt.Attr, "anchor")
+				value.Tooltip = getAtt(tt.Attr, "tooltip")
+				value.History = getAtt(tt.Attr, "history")
+				elem = &value
+			case "bookmarkStart":
+				elem = &BookmarkStart{
+					ID:       getAtt(tt.Attr, "id"),
+					Name:     getAtt(tt.Attr, "name"),
+					ColFirst: getAtt(tt.Attr, "colFirst"),
+					ColLast:  getAtt(tt.Attr, "colLast"),
+				}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "bookmarkEnd":
+				elem = &BookmarkEnd{ID: getAtt(tt.Attr, "id")}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "commentRangeStart":
+				elem = &CommentRangeStart{ID: getAtt(tt.Attr, "id")}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "commentRangeEnd":
+				elem = &CommentRangeEnd{ID: getAtt(tt.Attr, "id")}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "oMath":
+				var value Math
+				err = d.DecodeElement(&value, &tt)
+				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					return err
 				}
-				if anchor != "" {
-					value.ID = anchor
+				elem = &value
+			case "oMathPara":
+				var value MathPara
+				err = d.DecodeElement(&value, &tt)
+				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					return err
 				}
 				elem = &value
+			case "ins", "moveTo":
+				value := &Ins{file: p.file}
+				err = d.DecodeElement(value, &tt)
+				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					return err
+				}
+				elem = value
+			case "del", "moveFrom":
+				value := &Del{file: p.file}
+				err = d.DecodeElement(value, &tt)
+				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					return err
+				}
+				elem = value
+			case "sdt":
+				value := &SDT{file: p.file}
+				err = d.DecodeElement(value, &tt)
+				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					return err
+				}
+				elem = value
 			case "r":
 				var value Run
 				value.file = p.file
@@ -323,7 +407,7 @@ func (p *Paragraph) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 
 // KeepElements keep named elems amd removes others
 //
-// names: *docx.Hyperlink *docx.Run *docx.RunProperties
+// names: *docx.Hyperlink *docx.Run *docx.RunProperties *docx.SDT *docx.Math *docx.MathPara *docx.BookmarkStart *docx.BookmarkEnd *docx.CommentRangeStart *docx.CommentRangeEnd *docx.Ins *docx.Del
 func (p *Paragraph) KeepElements(name ...string) {
 	items := make([]interface{}, 0, len(p.Children))
 	namemap := make(map[string]struct{}, len(name)*2)
@@ -0,0 +1,125 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+// Ins is a tracked insertion <w:ins>, or the destination of a tracked move <w:moveTo>
+// as told by XMLName. It holds the inserted runs.
+type Ins struct {
+	XMLName  xml.Name
+	ID       string `xml:"w:id,attr"`
+	Author   string `xml:"w:author,attr,omitempty"`
+	Date     string `xml:"w:date,attr,omitempty"`
+	Children []interface{}
+
+	file *Docx
+}
+
+// Del is a tracked deletion <w:del>, or the source of a tracked move <w:moveFrom>
+// as told by XMLName. Its runs hold DelText instead of Text.
+type Del struct {
+	XMLName  xml.Name
+	ID       string `xml:"w:id,attr"`
+	Author   string `xml:"w:author,attr,omitempty"`
+	Date     string `xml:"w:date,attr,omitempty"`
+	Children []interface{}
+
+	file *Docx
+}
+
+// UnmarshalXML ...
+func (r *Ins) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
+	r.XMLName = xml.Name{Local: "w:" + start.Name.Local}
+	r.ID, r.Author, r.Date = getAtt(start.Attr, "id"), getAtt(start.Attr, "author"), getAtt(start.Attr, "date")
+	r.Children, err = parseRevision(d, r.file)
+	return
+}
+
+// UnmarshalXML ...
+func (r *Del) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
+	r.XMLName = xml.Name{Local: "w:" + start.Name.Local}
+	r.ID, r.Author, r.Date = getAtt(start.Attr, "id"), getAtt(start.Attr, "author"), getAtt(start.Attr, "date")
+	r.Children, err = parseRevision(d, r.file)
+	return
+}
+
+// parseRevision parses the runs and hyperlinks of a revision
+func parseRevision(d *xml.Decoder, file *Docx) ([]interface{}, error) {
+	var children []interface{}
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := t.(xml.EndElement); ok {
+			return children, nil
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		var elem interface{}
+		switch tt.Name.Local {
+		case "r":
+			value := &Run{file: file}
+			err = d.DecodeElement(value, &tt)
+			elem = value
+		case "hyperlink":
+			value := &Hyperlink{}
+			err = d.DecodeElement(value, &tt)
+			value.ID = getAtt(tt.Attr, "id")
+			value.Anchor = getAtt(tt.Attr, "anchor")
+			value.Tooltip = getAtt(tt.Attr, "tooltip")
+			value.History = getAtt(tt.Attr, "history")
+			elem = value
+		default:
+			err = d.Skip() // skip unsupported tags
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+			return nil, err
+		}
+		children = append(children, elem)
+	}
+}
+
+func (r *Ins) copymedia(to *Docx) *Ins {
+	nr := *r
+	nr.file = to
+	tmp := Paragraph{Children: r.Children, file: r.file}
+	nr.Children = tmp.copymedia(to).Children
+	return &nr
+}
+
+func (r *Del) copymedia(to *Docx) *Del {
+	nr := *r
+	nr.file = to
+	tmp := Paragraph{Children: r.Children, file: r.file}
+	nr.Children = tmp.copymedia(to).Children
+	return &nr
+}
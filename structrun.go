@@ -41,6 +41,10 @@ type Run struct {
 
 	Children []interface{}
 
+	// renderedBreaks are the positions in Children where Word broke the page when it last laid out
+	// the document (w:lastRenderedPageBreak), kept only to estimate page numbers
+	renderedBreaks []int
+
 	file *Docx
 }
 
@@ -97,8 +101,12 @@ func (r *Run) parse(d *xml.Decoder, tt xml.StartElement) (child interface{}, err
 		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
 			return nil, err
 		}
-		r.InstrText = value
-		return nil, nil
+		if r.InstrText == "" && len(r.Children) == 0 {
+			r.InstrText = value
+			return nil, nil
+		}
+		// keep the position of the instruction among the content before it
+		child = &InstrText{XMLSpace: "preserve", Text: value}
 	case "t":
 		var value Text
 		err = d.DecodeElement(&value, &tt)
@@ -106,6 +114,20 @@ func (r *Run) parse(d *xml.Decoder, tt xml.StartElement) (child interface{}, err
 			return nil, err
 		}
 		child = &value
+	case "delText":
+		var value Text
+		err = d.DecodeElement(&value, &tt)
+		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+			return nil, err
+		}
+		child = &DelText{XMLSpace: value.XMLSpace, Text: value.Text}
+	case "delInstrText":
+		var value Text
+		err = d.DecodeElement(&value, &tt)
+		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+			return nil, err
+		}
+		child = &DelInstrText{XMLSpace: value.XMLSpace, Text: value.Text}
 	case "drawing":
 		var value Drawing
 		value.file = r.file
@@ -116,6 +138,13 @@ func (r *Run) parse(d *xml.Decoder, tt xml.StartElement) (child interface{}, err
 		child = &value
 	case "tab":
 		child = &Tab{}
+	case "fldChar":
+		var value FldChar
+		err = d.DecodeElement(&value, &tt)
+		if err != nil {
+			return nil, err
+		}
+		child = &value
 	case "br":
 		var value BarterRabbet
 		err = d.DecodeElement(&value, &tt)
@@ -123,6 +152,26 @@ func (r *Run) parse(d *xml.Decoder, tt xml.StartElement) (child interface{}, err
 			return nil, err
 		}
 		child = &value
+	case "lastRenderedPageBreak":
+		r.renderedBreaks = append(r.renderedBreaks, len(r.Children))
+		err = d.Skip()
+	case "noBreakHyphen":
+		child = &NoBreakHyphen{}
+	case "softHyphen":
+		child = &SoftHyphen{}
+	case "commentReference":
+		child = &CommentReference{ID: getAtt(tt.Attr, "id")}
+		err = d.Skip()
+	case "annotationRef":
+		child = &AnnotationRef{}
+		err = d.Skip()
+	case "object":
+		value := &Object{file: r.file}
+		err = d.DecodeElement(value, &tt)
+		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+			return nil, err
+		}
+		child = value
 	case "AlternateContent":
 		/*var value AlternateContent
 		value.file = r.file
@@ -182,7 +231,7 @@ func (r *Run) parse(d *xml.Decoder, tt xml.StartElement) (child interface{}, err
 
 // KeepElements keep named elems amd removes others
 //
-// names: *docx.Text *docx.Drawing *docx.Tab *docx.BarterRabbet
+// names: *docx.Text *docx.Drawing *docx.Tab *docx.BarterRabbet *docx.FldChar *docx.InstrText *docx.NoBreakHyphen *docx.SoftHyphen *docx.DelText *docx.DelInstrText *docx.Object *docx.CommentReference *docx.AnnotationRef
 func (r *Run) KeepElements(name ...string) {
 	items := make([]interface{}, 0, len(r.Children))
 	namemap := make(map[string]struct{}, len(name)*2)
@@ -217,6 +266,9 @@ type RunProperties struct {
 	Underline *Underline
 	VertAlign *VertAlign
 	Strike    *Strike
+	Vanish    *Vanish
+	RTL       *RTL `xml:"w:rtl,omitempty"`
+	Lang      *Lang
 }
 
 // UnmarshalXML ...
@@ -306,6 +358,20 @@ func (r *RunProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 				var value Strike
 				value.Val = getAtt(tt.Attr, "val")
 				r.Strike = &value
+			case "vanish":
+				if isOnOff(getAtt(tt.Attr, "val")) {
+					r.Vanish = &Vanish{}
+				}
+			case "rtl":
+				if isOnOff(getAtt(tt.Attr, "val")) {
+					r.RTL = &RTL{}
+				}
+			case "lang":
+				var value Lang
+				value.Val = getAtt(tt.Attr, "val")
+				value.EastAsia = getAtt(tt.Attr, "eastAsia")
+				value.Bidi = getAtt(tt.Attr, "bidi")
+				r.Lang = &value
 			default:
 				err = d.Skip() // skip unsupported tags
 				if err != nil {
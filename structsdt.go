@@ -0,0 +1,378 @@
+/*
+   Copyright (c) 2020 gingfrederik
+   Copyright (c) 2021 Gonzalo Fernandez-Victorio
+   Copyright (c) 2021 Basement Crowd Ltd (https://www.basementcrowd.com)
+   Copyright (c) 2023 Fumiama Minamoto (源文雨)
+
+   This program is free software: you can redistribute it and/or modify
+   it under the terms of the GNU Affero General Public License as published
+   by the Free Software Foundation, either version 3 of the License, or
+   (at your option) any later version.
+
+   This program is distributed in the hope that it will be useful,
+   but WITHOUT ANY WARRANTY; without even the implied warranty of
+   MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+   GNU Affero General Public License for more details.
+
+   You should have received a copy of the GNU Affero General Public License
+   along with this program.  If not, see <https://www.gnu.org/licenses/>.
+*/
+
+package docx
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// SDT is a structured document tag (content control). At body level its content
+// holds paragraphs, tables and nested SDTs; inside a paragraph it holds runs and hyperlinks.
+type SDT struct {
+	XMLName    xml.Name       `xml:"w:sdt,omitempty"`
+	Properties *SDTProperties `xml:"w:sdtPr,omitempty"`
+	Content    *SDTContent    `xml:"w:sdtContent,omitempty"`
+
+	file *Docx
+}
+
+// SDTProperties <w:sdtPr> identifies the control and its kind.
+// Extensions outside the main namespace (e.g. check boxes and repeating sections) are not kept.
+type SDTProperties struct {
+	XMLName       xml.Name        `xml:"w:sdtPr,omitempty"`
+	RunProperties *RunProperties  `xml:"w:rPr,omitempty"`
+	Alias         *SDTValue       `xml:"w:alias,omitempty"`
+	Tag           *SDTValue       `xml:"w:tag,omitempty"`
+	ID            *SDTValue       `xml:"w:id,omitempty"`
+	Lock          *SDTValue       `xml:"w:lock,omitempty"`
+	Placeholder   *SDTPlaceholder `xml:"w:placeholder,omitempty"`
+	ShowingPlcHdr *ShowingPlcHdr  `xml:"w:showingPlcHdr,omitempty"` // the content is the placeholder text
+	DataBinding   *SDTDataBinding `xml:"w:dataBinding,omitempty"`
+	ComboBox      *SDTList        `xml:"w:comboBox,omitempty"`
+	Date          *SDTDate        `xml:"w:date,omitempty"`
+	DocPartObj    *SDTDocPart     `xml:"w:docPartObj,omitempty"`
+	DropDownList  *SDTList        `xml:"w:dropDownList,omitempty"`
+	RichText      *RichText       `xml:"w:richText,omitempty"`
+	Text          *SDTText        `xml:"w:text,omitempty"`
+}
+
+// SDTValue is a property with a single w:val
+type SDTValue struct {
+	Val string `xml:"w:val,attr"`
+}
+
+// SDTPlaceholder refers to the building block holding the placeholder text
+type SDTPlaceholder struct {
+	DocPart *SDTValue `xml:"w:docPart,omitempty"`
+}
+
+// ShowingPlcHdr show the control currently displays its placeholder text
+type ShowingPlcHdr struct{}
+
+// SDTDataBinding binds the control to an element of a custom XML part
+type SDTDataBinding struct {
+	PrefixMappings string `xml:"w:prefixMappings,attr,omitempty"`
+	XPath          string `xml:"w:xpath,attr"`
+	StoreItemID    string `xml:"w:storeItemID,attr,omitempty"`
+}
+
+// RichText show the control accepts formatted content
+type RichText struct{}
+
+// SDTText show the control accepts plain text only
+type SDTText struct {
+	MultiLine string `xml:"w:multiLine,attr,omitempty"`
+}
+
+// SDTDate is a date picker
+type SDTDate struct {
+	FullDate   string    `xml:"w:fullDate,attr,omitempty"`
+	DateFormat *SDTValue `xml:"w:dateFormat,omitempty"`
+	Lid        *SDTValue `xml:"w:lid,omitempty"`
+}
+
+// SDTDocPart is a building block gallery, e.g. the table of contents
+type SDTDocPart struct {
+	DocPartGallery  *SDTValue `xml:"w:docPartGallery,omitempty"`
+	DocPartCategory *SDTValue `xml:"w:docPartCategory,omitempty"`
+	DocPartUnique   *SDTValue `xml:"w:docPartUnique,omitempty"`
+}
+
+// SDTList is a drop-down list or a combo box
+type SDTList struct {
+	LastValue string        `xml:"w:lastValue,attr,omitempty"`
+	ListItems []SDTListItem `xml:"w:listItem"`
+}
+
+// SDTListItem is an entry of SDTList
+type SDTListItem struct {
+	DisplayText string `xml:"w:displayText,attr,omitempty"`
+	Value       string `xml:"w:value,attr"`
+}
+
+// SDTContent <w:sdtContent>
+type SDTContent struct {
+	XMLName xml.Name `xml:"w:sdtContent,omitempty"`
+	Items   []interface{}
+}
+
+// UnmarshalXML ...
+func (s *SDT) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if tt, ok := t.(xml.StartElement); ok {
+			switch tt.Name.Local {
+			case "sdtPr":
+				var value SDTProperties
+				err = d.DecodeElement(&value, &tt)
+				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					return err
+				}
+				s.Properties = &value
+			case "sdtContent":
+				content, err := s.parseContent(d)
+				if err != nil {
+					return err
+				}
+				s.Content = content
+			default:
+				err = d.Skip() // skip unsupported tags
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseContent parses the children of <w:sdtContent>
+func (s *SDT) parseContent(d *xml.Decoder) (*SDTContent, error) {
+	content := &SDTContent{}
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := t.(xml.EndElement); ok {
+			return content, nil
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		var elem interface{}
+		switch tt.Name.Local {
+		case "p":
+			value := &Paragraph{file: s.file}
+			err = d.DecodeElement(value, &tt)
+			elem = value
+		case "tbl":
+			value := &Table{file: s.file}
+			err = d.DecodeElement(value, &tt)
+			elem = value
+		case "sdt":
+			value := &SDT{file: s.file}
+			err = d.DecodeElement(value, &tt)
+			elem = value
+		case "r":
+			value := &Run{file: s.file}
+			err = d.DecodeElement(value, &tt)
+			elem = value
+		case "hyperlink":
+			value := &Hyperlink{}
+			err = d.DecodeElement(value, &tt)
+			value.ID = getAtt(tt.Attr, "id")
+			value.Anchor = getAtt(tt.Attr, "anchor")
+			value.Tooltip = getAtt(tt.Attr, "tooltip")
+			value.History = getAtt(tt.Attr, "history")
+			elem = value
+		default:
+			err = d.Skip() // skip unsupported tags
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+			return nil, err
+		}
+		content.Items = append(content.Items, elem)
+	}
+}
+
+// UnmarshalXML ...
+func (p *SDTProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if tt, ok := t.(xml.StartElement); ok {
+			value := func() *SDTValue { return &SDTValue{Val: getAtt(tt.Attr, "val")} }
+			switch tt.Name.Local {
+			case "rPr":
+				var value RunProperties
+				err = d.DecodeElement(&value, &tt)
+				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					return err
+				}
+				p.RunProperties = &value
+				continue
+			case "alias":
+				p.Alias = value()
+			case "tag":
+				p.Tag = value()
+			case "id":
+				p.ID = value()
+			case "lock":
+				p.Lock = value()
+			case "showingPlcHdr":
+				if isOnOff(getAtt(tt.Attr, "val")) {
+					p.ShowingPlcHdr = &ShowingPlcHdr{}
+				}
+			case "dataBinding":
+				p.DataBinding = &SDTDataBinding{
+					PrefixMappings: getAtt(tt.Attr, "prefixMappings"),
+					XPath:          getAtt(tt.Attr, "xpath"),
+					StoreItemID:    getAtt(tt.Attr, "storeItemID"),
+				}
+			case "richText":
+				p.RichText = &RichText{}
+			case "text":
+				p.Text = &SDTText{MultiLine: getAtt(tt.Attr, "multiLine")}
+			case "placeholder", "date", "docPartObj", "comboBox", "dropDownList":
+				err = p.parseChoice(d, tt)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			err = d.Skip() // skip the rest and unsupported tags
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseChoice parses the properties with children
+func (p *SDTProperties) parseChoice(d *xml.Decoder, start xml.StartElement) error {
+	var (
+		placeholder SDTPlaceholder
+		date        = SDTDate{FullDate: getAtt(start.Attr, "fullDate")}
+		docPart     SDTDocPart
+		list        = SDTList{LastValue: getAtt(start.Attr, "lastValue")}
+	)
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		if _, ok := t.(xml.EndElement); ok {
+			break
+		}
+		tt, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		value := &SDTValue{Val: getAtt(tt.Attr, "val")}
+		switch tt.Name.Local {
+		case "docPart":
+			placeholder.DocPart = value
+		case "dateFormat":
+			date.DateFormat = value
+		case "lid":
+			date.Lid = value
+		case "docPartGallery":
+			docPart.DocPartGallery = value
+		case "docPartCategory":
+			docPart.DocPartCategory = value
+		case "docPartUnique":
+			docPart.DocPartUnique = value
+		case "listItem":
+			list.ListItems = append(list.ListItems, SDTListItem{
+				DisplayText: getAtt(tt.Attr, "displayText"),
+				Value:       getAtt(tt.Attr, "value"),
+			})
+		}
+		err = d.Skip()
+		if err != nil {
+			return err
+		}
+	}
+	switch start.Name.Local {
+	case "placeholder":
+		p.Placeholder = &placeholder
+	case "date":
+		p.Date = &date
+	case "docPartObj":
+		p.DocPartObj = &docPart
+	case "comboBox":
+		p.ComboBox = &list
+	case "dropDownList":
+		p.DropDownList = &list
+	}
+	return nil
+}
+
+// RangeParagraphs goes through each paragraph in a block level SDT in reading order
+func (s *SDT) RangeParagraphs(iter func(*Paragraph) error) error {
+	if s.Content == nil {
+		return nil
+	}
+	for _, item := range s.Content.Items {
+		var err error
+		switch o := item.(type) {
+		case *Paragraph:
+			err = iter(o)
+		case *Table:
+			err = o.RangeParagraphs(iter)
+		case *SDT:
+			err = o.RangeParagraphs(iter)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *SDT) copymedia(to *Docx) *SDT {
+	ns := *s
+	ns.file = to
+	if s.Content == nil {
+		return &ns
+	}
+	ns.Content = &SDTContent{Items: make([]interface{}, 0, len(s.Content.Items))}
+	for _, item := range s.Content.Items {
+		switch o := item.(type) {
+		case *Paragraph:
+			np := o.copymedia(to)
+			ns.Content.Items = append(ns.Content.Items, &np)
+		case *Table:
+			nt := o.copymedia(to)
+			ns.Content.Items = append(ns.Content.Items, &nt)
+		case *SDT:
+			ns.Content.Items = append(ns.Content.Items, o.copymedia(to))
+		default:
+			// runs and hyperlinks are copied with a paragraph to resolve their media and relations
+			tmp := Paragraph{Children: []interface{}{o}, file: s.file}
+			np := tmp.copymedia(to)
+			ns.Content.Items = append(ns.Content.Items, np.Children...)
+		}
+	}
+	return &ns
+}
@@ -26,17 +26,31 @@ import (
 
 // SectPr show the properties of the document, like paper size
 type SectPr struct {
-	XMLName xml.Name `xml:"w:sectPr,omitempty"` // properties of the document, including paper size
-	PgSz    *PgSz    `xml:"w:pgSz,omitempty"`
-	PgMar   *PgMar   `xml:"w:pgMar,omitempty"`
-	Cols    *Cols    `xml:"w:cols,omitempty"`
-	DocGrid *DocGrid `xml:"w:docGrid,omitempty"`
+	XMLName   xml.Name            `xml:"w:sectPr,omitempty"` // properties of the document, including paper size
+	Footers   []*FooterReference  `xml:"w:footerReference,omitempty"`
+	Type      *SectType           `xml:"w:type,omitempty"`
+	PgSz      *PgSz               `xml:"w:pgSz,omitempty"`
+	PgMar     *PgMar              `xml:"w:pgMar,omitempty"`
+	PgNumType *PgNumType          `xml:"w:pgNumType,omitempty"`
+	Cols      *Cols               `xml:"w:cols,omitempty"`
+	VAlign    *WVerticalAlignment `xml:"w:vAlign,omitempty"`
+	TitlePg   *TitlePg            `xml:"w:titlePg,omitempty"`
+	Bidi      *Bidi               `xml:"w:bidi,omitempty"`
+	RtlGutter *RtlGutter          `xml:"w:rtlGutter,omitempty"`
+	DocGrid   *DocGrid            `xml:"w:docGrid,omitempty"`
+}
+
+// SectType show where the section starts, like nextPage, continuous, evenPage and oddPage
+type SectType struct {
+	Val string `xml:"w:val,attr"`
 }
 
 // PgSz show the paper size
 type PgSz struct {
-	W int `xml:"w:w,attr"` // width of paper
-	H int `xml:"w:h,attr"` // high of paper
+	W      int    `xml:"w:w,attr"`                // width of paper
+	H      int    `xml:"w:h,attr"`                // high of paper
+	Orient string `xml:"w:orient,attr,omitempty"` // portrait or landscape
+	Code   int    `xml:"w:code,attr,omitempty"`   // printer paper code
 }
 
 // PgMar show the page margin
@@ -50,11 +64,42 @@ type PgMar struct {
 	Gutter int `xml:"w:gutter,attr"`
 }
 
+// PgNumType show the page number format and the starting number
+type PgNumType struct {
+	Fmt   string `xml:"w:fmt,attr,omitempty"`
+	Start int    `xml:"w:start,attr,omitempty"`
+}
+
 // Cols show the number of columns
 type Cols struct {
+	Num   int `xml:"w:num,attr,omitempty"`
 	Space int `xml:"w:space,attr"`
+	Sep   int `xml:"w:sep,attr,omitempty"` // draw a line between columns
+}
+
+// FooterReference refers to a footer part of the section by its relationship ID,
+// Type is default, first or even. It is not parsed from existing documents.
+type FooterReference struct {
+	Type string `xml:"w:type,attr"`
+	ID   string `xml:"r:id,attr"`
+}
+
+// Footer is the root of a footer part <w:ftr>
+type Footer struct {
+	XMLName    xml.Name `xml:"w:ftr"`
+	XMLW       string   `xml:"xmlns:w,attr"`
+	Paragraphs []*Paragraph
 }
 
+// TitlePg show the first page of the section has a different header and footer
+type TitlePg struct{}
+
+// Bidi show the section is laid out from right to left
+type Bidi struct{}
+
+// RtlGutter show the gutter of the section is on the right side of the page
+type RtlGutter struct{}
+
 // DocGrid show the document grid
 type DocGrid struct {
 	Type      string `xml:"w:type,attr"`
@@ -73,6 +118,12 @@ func (sect *SectPr) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 		}
 		if tt, ok := t.(xml.StartElement); ok {
 			switch tt.Name.Local {
+			case "type":
+				sect.Type = &SectType{Val: getAtt(tt.Attr, "val")}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
 			case "pgSz":
 				var value PgSz
 				err = d.DecodeElement(&value, &tt)
@@ -87,6 +138,20 @@ func (sect *SectPr) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 					return err
 				}
 				sect.PgMar = &value
+			case "pgNumType":
+				var value PgNumType
+				value.Fmt = getAtt(tt.Attr, "fmt")
+				if v := getAtt(tt.Attr, "start"); v != "" {
+					value.Start, err = GetInt(v)
+					if err != nil {
+						return err
+					}
+				}
+				sect.PgNumType = &value
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
 			case "cols":
 				var value Cols
 				err = d.DecodeElement(&value, &tt)
@@ -94,6 +159,36 @@ func (sect *SectPr) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 					return err
 				}
 				sect.Cols = &value
+			case "vAlign":
+				sect.VAlign = &WVerticalAlignment{Val: getAtt(tt.Attr, "val")}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "titlePg":
+				if isOnOff(getAtt(tt.Attr, "val")) {
+					sect.TitlePg = &TitlePg{}
+				}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "bidi":
+				if isOnOff(getAtt(tt.Attr, "val")) {
+					sect.Bidi = &Bidi{}
+				}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "rtlGutter":
+				if isOnOff(getAtt(tt.Attr, "val")) {
+					sect.RtlGutter = &RtlGutter{}
+				}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
 			case "docGrid":
 				var value DocGrid
 				err = d.DecodeElement(&value, &tt)
@@ -128,6 +223,13 @@ func (pgsz *PgSz) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 			if err != nil {
 				return err
 			}
+		case "orient":
+			pgsz.Orient = attr.Value
+		case "code":
+			pgsz.Code, err = strconv.Atoi(attr.Value)
+			if err != nil {
+				return err
+			}
 		default:
 			// ignore other attributes now
 		}
@@ -193,11 +295,20 @@ func (cols *Cols) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 
 	for _, attr := range start.Attr {
 		switch attr.Name.Local {
+		case "num":
+			cols.Num, err = strconv.Atoi(attr.Value)
+			if err != nil {
+				return err
+			}
 		case "space":
 			cols.Space, err = strconv.Atoi(attr.Value)
 			if err != nil {
 				return err
 			}
+		case "sep":
+			if isOnOff(attr.Value) {
+				cols.Sep = 1
+			}
 		default:
 			// ignore other attributes now
 		}
@@ -465,7 +465,13 @@ func (w *WTableRow) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 // WTableRowProperties represents the properties of a row within a table.
 type WTableRowProperties struct {
 	XMLName        xml.Name `xml:"w:trPr,omitempty"`
+	GridBefore     *WGridBefore
+	GridAfter      *WGridAfter
+	WidthBefore    *WWidthBefore
+	WidthAfter     *WWidthAfter
+	CantSplit      *WCantSplit
 	TableRowHeight *WTableRowHeight
+	TableHeader    *WTableHeader
 	Justification  *Justification
 }
 
@@ -500,6 +506,50 @@ func (t *WTableRowProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 				if err != nil {
 					return err
 				}
+			case "gridBefore", "gridAfter":
+				v, err := GetInt(getAtt(tt.Attr, "val"))
+				if err != nil {
+					return err
+				}
+				if tt.Name.Local == "gridBefore" {
+					t.GridBefore = &WGridBefore{Val: v}
+				} else {
+					t.GridAfter = &WGridAfter{Val: v}
+				}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "wBefore", "wAfter":
+				w, err := GetInt64(getAtt(tt.Attr, "w"))
+				if err != nil {
+					return err
+				}
+				if tt.Name.Local == "wBefore" {
+					t.WidthBefore = &WWidthBefore{W: w, Type: getAtt(tt.Attr, "type")}
+				} else {
+					t.WidthAfter = &WWidthAfter{W: w, Type: getAtt(tt.Attr, "type")}
+				}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "cantSplit":
+				if isOnOff(getAtt(tt.Attr, "val")) {
+					t.CantSplit = &WCantSplit{}
+				}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
+			case "tblHeader":
+				if isOnOff(getAtt(tt.Attr, "val")) {
+					t.TableHeader = &WTableHeader{}
+				}
+				err = d.Skip()
+				if err != nil {
+					return err
+				}
 			case "jc":
 				th := new(Justification)
 				for _, attr := range tt.Attr {
@@ -524,6 +574,42 @@ func (t *WTableRowProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) e
 	return nil
 }
 
+// WGridBefore is the number of grid columns skipped before the first cell of the row
+type WGridBefore struct {
+	XMLName xml.Name `xml:"w:gridBefore,omitempty"`
+	Val     int      `xml:"w:val,attr"`
+}
+
+// WGridAfter is the number of grid columns left after the last cell of the row
+type WGridAfter struct {
+	XMLName xml.Name `xml:"w:gridAfter,omitempty"`
+	Val     int      `xml:"w:val,attr"`
+}
+
+// WWidthBefore is the width of the grid columns skipped by WGridBefore
+type WWidthBefore struct {
+	XMLName xml.Name `xml:"w:wBefore,omitempty"`
+	W       int64    `xml:"w:w,attr"`
+	Type    string   `xml:"w:type,attr,omitempty"`
+}
+
+// WWidthAfter is the width of the grid columns left by WGridAfter
+type WWidthAfter struct {
+	XMLName xml.Name `xml:"w:wAfter,omitempty"`
+	W       int64    `xml:"w:w,attr"`
+	Type    string   `xml:"w:type,attr,omitempty"`
+}
+
+// WCantSplit show the row must not break across pages
+type WCantSplit struct {
+	XMLName xml.Name `xml:"w:cantSplit,omitempty"`
+}
+
+// WTableHeader show the row is repeated at the top of each page
+type WTableHeader struct {
+	XMLName xml.Name `xml:"w:tblHeader,omitempty"`
+}
+
 // WTableRowHeight represents the height of a row within a table.
 type WTableRowHeight struct {
 	XMLName xml.Name `xml:"w:trHeight,omitempty"`
@@ -538,9 +624,65 @@ type WTableCell struct {
 	Paragraphs          []*Paragraph `xml:"w:p,omitempty"`
 	Tables              []*Table     `xml:"w:tbl,omitempty"`
 
+	// tablePos is the number of paragraphs before each of Tables in the parsed cell,
+	// tables without a position follow all paragraphs
+	tablePos []int
+
 	file *Docx
 }
 
+// Items returns the paragraphs and nested tables of the cell in reading order
+func (c *WTableCell) Items() []interface{} {
+	items := make([]interface{}, 0, len(c.Paragraphs)+len(c.Tables))
+	n := 0
+	for i, tbl := range c.Tables {
+		pos := len(c.Paragraphs)
+		if i < len(c.tablePos) && c.tablePos[i] < pos {
+			pos = c.tablePos[i]
+		}
+		for ; n < pos; n++ {
+			items = append(items, c.Paragraphs[n])
+		}
+		items = append(items, tbl)
+	}
+	for ; n < len(c.Paragraphs); n++ {
+		items = append(items, c.Paragraphs[n])
+	}
+	return items
+}
+
+// MarshalXML writes the paragraphs and nested tables in reading order,
+// and ends the cell with an empty paragraph if it would end with a table
+func (c *WTableCell) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "w:tc"}}
+	err := e.EncodeToken(start)
+	if err != nil {
+		return err
+	}
+	if c.TableCellProperties != nil {
+		err = e.Encode(c.TableCellProperties)
+		if err != nil {
+			return err
+		}
+	}
+	items := c.Items()
+	for _, item := range items {
+		err = e.Encode(item)
+		if err != nil {
+			return err
+		}
+	}
+	if len(items) > 0 {
+		if _, ok := items[len(items)-1].(*Table); ok {
+			err = e.Encode(&Paragraph{})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
 // UnmarshalXML ...
 func (c *WTableCell) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 	for {
@@ -557,25 +699,35 @@ func (c *WTableCell) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 			case "p":
 				var value Paragraph
 				value.file = c.file
-				err = d.DecodeElement(&value, &tt)
+				err = c.file.decodeElement(d, &value, &tt)
 				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					if c.file.tolerate("word/document.xml", tt.Name.Local, err) {
+						continue
+					}
 					return err
 				}
 				c.Paragraphs = append(c.Paragraphs, &value)
 			case "tcPr":
 				var value WTableCellProperties
-				err = d.DecodeElement(&value, &tt)
+				err = c.file.decodeElement(d, &value, &tt)
 				if err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					if c.file.tolerate("word/document.xml", tt.Name.Local, err) {
+						continue
+					}
 					return err
 				}
 				c.TableCellProperties = &value
 			case "tbl":
 				var table Table
 				table.file = c.file
-				if err = d.DecodeElement(&table, &tt); err != nil && !strings.HasPrefix(err.Error(), "expected") {
+				if err = c.file.decodeElement(d, &table, &tt); err != nil && !strings.HasPrefix(err.Error(), "expected") {
+					if c.file.tolerate("word/document.xml", tt.Name.Local, err) {
+						continue
+					}
 					return err
 				}
 				c.Tables = append(c.Tables, &table)
+				c.tablePos = append(c.tablePos, len(c.Paragraphs))
 			default:
 				err = d.Skip() // skip unsupported tags
 				if err != nil {
@@ -592,8 +744,9 @@ func (c *WTableCell) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
 type WTableCellProperties struct {
 	XMLName        xml.Name `xml:"w:tcPr,omitempty"`
 	TableCellWidth *WTableCellWidth
-	VMerge         *WvMerge
 	GridSpan       *WGridSpan
+	HMerge         *WhMerge
+	VMerge         *WvMerge
 	TableBorders   *WTableBorders `xml:"w:tcBorders"`
 	Shade          *Shade
 	VAlign         *WVerticalAlignment
@@ -623,6 +776,8 @@ func (r *WTableCellProperties) UnmarshalXML(d *xml.Decoder, _ xml.StartElement)
 					return err
 				}
 				r.TableCellWidth.Type = getAtt(tt.Attr, "type")
+			case "hMerge":
+				r.HMerge = &WhMerge{Val: getAtt(tt.Attr, "val")}
 			case "vMerge":
 				r.VMerge = &WvMerge{Val: getAtt(tt.Attr, "val")}
 			case "gridSpan":
@@ -701,6 +856,13 @@ type WvMerge struct {
 	Val     string   `xml:"w:val,attr,omitempty"`
 }
 
+// WhMerge is the legacy horizontal merge written by older versions of Word:
+// restart begins a merged group of cells and continue joins the cell to the group
+type WhMerge struct {
+	XMLName xml.Name `xml:"w:hMerge,omitempty"`
+	Val     string   `xml:"w:val,attr,omitempty"`
+}
+
 // WTableBorders is a structure representing the borders of a Word table.
 type WTableBorders struct {
 	Top     *WTableBorder `xml:"w:top,omitempty"`
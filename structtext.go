@@ -107,6 +107,51 @@ func (f *BarterRabbet) UnmarshalXML(d *xml.Decoder, start xml.StartElement) erro
 	return err
 }
 
+// NoBreakHyphen is a hyphen that the line is not broken at
+type NoBreakHyphen struct {
+	XMLName xml.Name `xml:"w:noBreakHyphen,omitempty"`
+}
+
+// SoftHyphen is an optional hyphen shown only when the line is broken at it
+type SoftHyphen struct {
+	XMLName xml.Name `xml:"w:softHyphen,omitempty"`
+}
+
+// FldChar marks the begin, the separator between instruction and result, or the end of a complex field
+type FldChar struct {
+	XMLName     xml.Name `xml:"w:fldChar,omitempty"`
+	FldCharType string   `xml:"w:fldCharType,attr"`       // begin, separate or end
+	Dirty       string   `xml:"w:dirty,attr,omitempty"`   // the result should be updated when the document opens
+	FldLock     string   `xml:"w:fldLock,attr,omitempty"` // the result should not be updated
+}
+
+// UnmarshalXML ...
+func (f *FldChar) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "fldCharType":
+			f.FldCharType = attr.Value
+		case "dirty":
+			f.Dirty = attr.Value
+		case "fldLock":
+			f.FldLock = attr.Value
+		default:
+			// ignore other attributes
+		}
+	}
+	return d.Skip() // skip form field data
+}
+
+// InstrText is the instruction of a complex field that follows other content of the same run,
+// e.g. in the runs merged into a Hyperlink. Instructions alone in their run are kept in Run.InstrText.
+type InstrText struct {
+	XMLName xml.Name `xml:"w:instrText,omitempty"`
+
+	XMLSpace string `xml:"xml:space,attr,omitempty"`
+
+	Text string `xml:",chardata"`
+}
+
 // Text object contains the actual text
 type Text struct {
 	XMLName xml.Name `xml:"w:t,omitempty"`
@@ -143,6 +188,20 @@ func (r *Text) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	return nil
 }
 
+// DelText is the text of a run in a tracked deletion
+type DelText struct {
+	XMLName  xml.Name `xml:"w:delText,omitempty"`
+	XMLSpace string   `xml:"xml:space,attr,omitempty"`
+	Text     string   `xml:",chardata"`
+}
+
+// DelInstrText is the field instruction of a run in a tracked deletion
+type DelInstrText struct {
+	XMLName  xml.Name `xml:"w:delInstrText,omitempty"`
+	XMLSpace string   `xml:"xml:space,attr,omitempty"`
+	Text     string   `xml:",chardata"`
+}
+
 // RunMergeRule compares two runs and decides whether they can be merged
 type RunMergeRule func(r1, r2 *Run) bool
 
@@ -0,0 +1,106 @@
+package docx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AdminHandler 返回管理任务的 HTTP 接口, 供运维人员在不重启服务的情况下管理共享的翻译服务:
+//
+//	GET    /jobs             列出所有任务的进度与用量
+//	GET    /jobs/{id}        查看一个任务
+//	POST   /jobs/{id}/cancel 取消一个任务
+//	DELETE /jobs/{id}        清除一个已结束的任务及其译文
+//	DELETE /jobs?before=...  清除所有 (在 RFC 3339 时间 before 之前) 已结束的任务
+//
+// 响应均为 JSON. 接口不做身份验证, 应挂载在只对内开放的地址上, 挂载在子路径下时使用 http.StripPrefix.
+func (m *JobManager) AdminHandler() http.Handler {
+	return jobAdminHandler(m.Service())
+}
+
+// jobAdminHandler 返回 s 的管理接口
+func jobAdminHandler(s JobService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveAdmin(s, w, r)
+	})
+}
+
+func serveAdmin(s JobService, w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "jobs" || len(parts) > 3 {
+		writeAdminError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		infos, err := s.Jobs()
+		if err != nil {
+			writeAdminError(w, adminStatus(err), err)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, infos)
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		var before time.Time
+		if s := r.URL.Query().Get("before"); s != "" {
+			var err error
+			before, err = time.Parse(time.RFC3339, s)
+			if err != nil {
+				writeAdminError(w, http.StatusBadRequest, err)
+				return
+			}
+		}
+		n, err := s.PurgeFinished(before)
+		if err != nil {
+			writeAdminError(w, adminStatus(err), err)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]int{"purged": n})
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		info, err := s.Job(parts[1])
+		if err != nil {
+			writeAdminError(w, adminStatus(err), err)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, info)
+	case len(parts) == 2 && r.Method == http.MethodDelete:
+		if err := s.Purge(parts[1]); err != nil {
+			writeAdminError(w, adminStatus(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case len(parts) == 3 && parts[2] == "cancel" && r.Method == http.MethodPost:
+		if err := s.Cancel(parts[1]); err != nil {
+			writeAdminError(w, adminStatus(err), err)
+			return
+		}
+		info, _ := s.Job(parts[1])
+		writeAdminJSON(w, http.StatusAccepted, info)
+	default:
+		writeAdminError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+// adminStatus 将任务相关的错误映射为 HTTP 状态码
+func adminStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrJobFinished), errors.Is(err, ErrJobRunning):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, status int, err error) {
+	writeAdminJSON(w, status, map[string]string{"error": err.Error()})
+}
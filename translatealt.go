@@ -0,0 +1,69 @@
+package docx
+
+import "strings"
+
+const (
+	// altHint 是图片替代文字附加的翻译要求
+	altHint = "这段文字是图片的替代文字, 供屏幕阅读器朗读: 译文简洁地描述图片内容, 不要添加说明."
+	// altTitleHint 是图片标题附加的翻译要求
+	altTitleHint = "这段文字是图片的标题: 译文要简洁, 不要添加句末标点."
+)
+
+// altSegments 返回段落 dst 中图片的替代文字 (wp:docPr 的 descr) 与标题对应的翻译单元, 译文写回 dst 中的图片;
+// dst 不是 src 时, dst 中的图片属性先与 src 分离, 翻译不会修改原文档
+func (t *Translator) altSegments(src, dst *Paragraph) []*segment {
+	var segs []*segment
+	add := func(attr *string, hint string) {
+		if strings.TrimSpace(*attr) != "" {
+			segs = append(segs, &segment{src: src, dst: dst, text: *attr, hint: hint, set: func(s string) { *attr = s }})
+		}
+	}
+	for _, d := range paragraphDrawings(dst) {
+		var docPr **WPDocPr
+		switch {
+		case d.Inline != nil:
+			if dst != src {
+				ni := *d.Inline
+				d.Inline = &ni
+			}
+			docPr = &d.Inline.DocPr
+		case d.Anchor != nil:
+			if dst != src {
+				na := *d.Anchor
+				d.Anchor = &na
+			}
+			docPr = &d.Anchor.DocPr
+		}
+		if docPr == nil || *docPr == nil {
+			continue
+		}
+		if dst != src {
+			nd := **docPr
+			*docPr = &nd
+		}
+		add(&(*docPr).Descr, altHint)
+		add(&(*docPr).Title, altTitleHint)
+	}
+	return segs
+}
+
+// paragraphDrawings 返回段落中 Run 与超链接里的图片
+func paragraphDrawings(p *Paragraph) []*Drawing {
+	var drawings []*Drawing
+	collect := func(r *Run) {
+		for _, child := range r.Children {
+			if d, ok := child.(*Drawing); ok {
+				drawings = append(drawings, d)
+			}
+		}
+	}
+	for _, child := range p.Children {
+		switch o := child.(type) {
+		case *Run:
+			collect(o)
+		case *Hyperlink:
+			collect(&o.Run)
+		}
+	}
+	return drawings
+}
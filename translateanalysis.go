@@ -0,0 +1,178 @@
+package docx
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MatchSource 为 AnalyzeDocx 提供翻译记忆的匹配率
+type MatchSource interface {
+	// BestMatch 返回记忆库中与 source 最相近条目的相似度, 取值范围 [0, 1]
+	BestMatch(source string) float64
+}
+
+// MatchBand 是 CAT 工具分析报告中的匹配区间
+type MatchBand uint8
+
+const (
+	// MatchBandRepetition 文档中重复出现的句段 (首次出现之后的部分)
+	MatchBandRepetition MatchBand = iota
+	// MatchBandExact 100% 匹配
+	MatchBandExact
+	// MatchBand95 95%-99% 模糊匹配
+	MatchBand95
+	// MatchBand85 85%-94% 模糊匹配
+	MatchBand85
+	// MatchBand75 75%-84% 模糊匹配
+	MatchBand75
+	// MatchBand50 50%-74% 模糊匹配
+	MatchBand50
+	// MatchBandNew 无匹配 (新字)
+	MatchBandNew
+
+	matchBandCount
+)
+
+func (b MatchBand) String() string {
+	switch b {
+	case MatchBandRepetition:
+		return "Repetitions"
+	case MatchBandExact:
+		return "100%"
+	case MatchBand95:
+		return "95%-99%"
+	case MatchBand85:
+		return "85%-94%"
+	case MatchBand75:
+		return "75%-84%"
+	case MatchBand50:
+		return "50%-74%"
+	case MatchBandNew:
+		return "New"
+	default:
+		return "MatchBand(" + strconv.Itoa(int(b)) + ")"
+	}
+}
+
+// matchBandOf 将相似度归入区间
+func matchBandOf(score float64) MatchBand {
+	switch {
+	case score >= 1:
+		return MatchBandExact
+	case score >= 0.95:
+		return MatchBand95
+	case score >= 0.85:
+		return MatchBand85
+	case score >= 0.75:
+		return MatchBand75
+	case score >= 0.5:
+		return MatchBand50
+	default:
+		return MatchBandNew
+	}
+}
+
+// BandCount 是一个匹配区间内的统计
+type BandCount struct {
+	Band       MatchBand
+	Segments   int
+	Words      int
+	Characters int
+}
+
+// Analysis 是一个文档的字数分析, 对应 CAT 工具的 "分析文件" 报告
+type Analysis struct {
+	Bands []BandCount // Bands 按 MatchBand 的顺序排列, 每个区间都存在
+	Total BandCount   // Total 的 Band 字段无意义
+}
+
+// AnalyzeDocx 按 CAT 工具的惯例统计文档的句段数、字数与字符数,
+// 并按重复、翻译记忆匹配区间与新字分类; tm 为 nil 时所有非重复句段都计为新字
+//
+// 西文以空白分隔计词, 中日韩文字每个字计为一词.
+func (t *Translator) AnalyzeDocx(doc *Docx, tm MatchSource) *Analysis {
+	_, segs := t.prepare(doc, "")
+	a := &Analysis{Bands: make([]BandCount, matchBandCount)}
+	for i := range a.Bands {
+		a.Bands[i].Band = MatchBand(i)
+	}
+	seen := make(map[string]struct{}, len(segs))
+	for _, sg := range segs {
+		band := MatchBandNew
+		if _, ok := seen[sg.text]; ok {
+			band = MatchBandRepetition
+		} else {
+			seen[sg.text] = struct{}{}
+			if tm != nil {
+				band = matchBandOf(tm.BestMatch(sg.text))
+			}
+		}
+		words, chars := CountWords(sg.text), utf8.RuneCountInString(sg.text)
+		bc := &a.Bands[band]
+		bc.Segments++
+		bc.Words += words
+		bc.Characters += chars
+		a.Total.Segments++
+		a.Total.Words += words
+		a.Total.Characters += chars
+	}
+	return a
+}
+
+// WriteCSV 以 Band,Segments,Words,Characters,Percent 的格式写出分析报告,
+// Percent 是该区间字数占总字数的百分比, 最后一行为 Total
+func (a *Analysis) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"Band", "Segments", "Words", "Characters", "Percent"})
+	if err != nil {
+		return err
+	}
+	row := func(name string, bc *BandCount) error {
+		pct := 0.0
+		if a.Total.Words > 0 {
+			pct = float64(bc.Words) * 100 / float64(a.Total.Words)
+		}
+		return cw.Write([]string{
+			name, strconv.Itoa(bc.Segments), strconv.Itoa(bc.Words), strconv.Itoa(bc.Characters),
+			strconv.FormatFloat(pct, 'f', 2, 64),
+		})
+	}
+	for i := range a.Bands {
+		err = row(a.Bands[i].Band.String(), &a.Bands[i])
+		if err != nil {
+			return err
+		}
+	}
+	err = row("Total", &a.Total)
+	if err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// CountWords 按 CAT 工具的惯例计词: 西文以空白与标点分隔, 中日韩文字每个字计为一词
+func CountWords(s string) int {
+	n := 0
+	inWord := false
+	for _, r := range s {
+		switch {
+		case isCJK(r):
+			n++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				n++
+				inWord = true
+			}
+		case r == '\'' || r == '-' || r == '.' || r == ',':
+			// 单词或数字内部的连接符不断开, 如 don't, e-mail, 1,234.5
+		default:
+			inWord = false
+		}
+	}
+	return n
+}
@@ -0,0 +1,39 @@
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type mapMatchSource map[string]float64
+
+func (m mapMatchSource) BestMatch(source string) float64 {
+	return m[source]
+}
+
+func TestAnalyzeDocx(t *testing.T) {
+	doc := newTestDoc("Hello world.", "Hello world.", "合同条款", "It's 1,234.5 e-mail", "fuzzy one")
+	a := NewTranslator("", "").AnalyzeDocx(doc, mapMatchSource{"合同条款": 1, "fuzzy one": 0.9})
+	want := map[MatchBand][2]int{
+		MatchBandRepetition: {1, 2},
+		MatchBandExact:      {1, 4},
+		MatchBand85:         {1, 2},
+		MatchBandNew:        {2, 5},
+	}
+	for _, bc := range a.Bands {
+		if w := want[bc.Band]; bc.Segments != w[0] || bc.Words != w[1] {
+			t.Fatalf("band %v: got %d segments %d words, want %v", bc.Band, bc.Segments, bc.Words, w)
+		}
+	}
+	if a.Total.Segments != 5 || a.Total.Words != 13 {
+		t.Fatalf("unexpected total: %+v", a.Total)
+	}
+	var buf bytes.Buffer
+	if err := a.WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "\nTotal,5,13,") {
+		t.Fatal("unexpected csv:", buf.String())
+	}
+}
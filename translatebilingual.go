@@ -0,0 +1,94 @@
+package docx
+
+import (
+	"strings"
+	"unicode"
+)
+
+// BilingualPolicy 决定如何处理已经是双语 (原文 + 译文) 的段落, 如 "合同 Contract"
+type BilingualPolicy uint8
+
+const (
+	// BilingualPolicyTranslate 不检测双语段落, 整段翻译 (默认)
+	BilingualPolicyTranslate BilingualPolicy = iota
+	// BilingualPolicyKeep 双语段落原样保留, 不请求翻译服务
+	BilingualPolicyKeep
+	// BilingualPolicyStripSource 只保留双语段落中的译文一半, 不请求翻译服务
+	BilingualPolicyStripSource
+	// BilingualPolicyRetranslate 只翻译双语段落中的原文一半, 以新译文替换整个段落
+	BilingualPolicyRetranslate
+)
+
+// WithBilingualPolicy 设置双语段落的处理方式, 见 SplitBilingual
+func (t *Translator) WithBilingualPolicy(p BilingualPolicy) *Translator {
+	t.bilingualPolicy = p
+	return t
+}
+
+// isCJKLanguage 判断语言是否使用中日韩文字书写
+func isCJKLanguage(lang string) bool {
+	switch LanguageCode(lang) {
+	case "zh", "ja", "ko":
+		return true
+	default:
+		return false
+	}
+}
+
+// bilingualTrim 是双语段落两半之间被去除的分隔符
+const bilingualTrim = " \t/|(（[【-–—:：·"
+
+// SplitBilingual 判断 text 是否由原文与译文两半组成, 并返回这两半
+//
+// 只在原文与目标语言中恰有一方使用中日韩文字时检测: 忽略数字、标点与空白后,
+// 文字在两种书写系统间恰好切换一次, 且西文一半的字母数不少于中日韩一半字数的 1.5 倍
+// (译文通常比原文长, 以此排除 "使用 Go" 这样夹带外文的普通句子).
+func SplitBilingual(text, sourceLanguage, targetLanguage string) (source, target string, ok bool) {
+	srcCJK, tgtCJK := isCJKLanguage(sourceLanguage), isCJKLanguage(targetLanguage)
+	if srcCJK == tgtCJK {
+		return "", "", false
+	}
+	var (
+		split    = -1 // split 是第二半第一个字母的位置
+		first    = 0  // first 是第一半的书写系统, 1 为中日韩文字, 2 为其它文字
+		cjk, lat int
+	)
+	for i, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		class := 2
+		if isCJK(r) {
+			class = 1
+			cjk++
+		} else {
+			lat++
+		}
+		switch {
+		case first == 0:
+			first = class
+		case class != first && split < 0:
+			split = i
+		case class == first && split >= 0:
+			return "", "", false // 切换了不止一次
+		}
+	}
+	if split < 0 || cjk < 1 || lat < 2 || float64(lat) < 1.5*float64(cjk) {
+		return "", "", false
+	}
+	a := strings.TrimSpace(strings.TrimRight(text[:split], bilingualTrim))
+	b := strings.TrimSpace(text[split:])
+	// 去掉 "合同 (Contract)" 中包住第二半的括号留下的右括号
+	for _, pair := range [...][2]string{{"(", ")"}, {"（", "）"}, {"[", "]"}, {"【", "】"}} {
+		if strings.HasSuffix(b, pair[1]) && strings.Count(b, pair[0]) < strings.Count(b, pair[1]) {
+			b = strings.TrimSpace(strings.TrimSuffix(b, pair[1]))
+		}
+	}
+	if a == "" || b == "" {
+		return "", "", false
+	}
+	if (first == 1) == srcCJK {
+		return a, b, true
+	}
+	return b, a, true
+}
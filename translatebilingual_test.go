@@ -0,0 +1,60 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitBilingual(t *testing.T) {
+	for _, c := range []struct {
+		text, src, tgt string
+		ok             bool
+	}{
+		{"合同 Contract", "合同", "Contract", true},
+		{"第1条（Article 1）", "第1条", "Article 1", true},
+		{"总结 / Summary (draft)", "总结", "Summary (draft)", true},
+		{"Contract 合同", "合同", "Contract", true},
+		{"使用 Go", "", "", false},
+		{"使用 Go 语言开发", "", "", false},
+		{"合同", "", "", false},
+	} {
+		src, tgt, ok := SplitBilingual(c.text, "中文", "English")
+		if src != c.src || tgt != c.tgt || ok != c.ok {
+			t.Errorf("SplitBilingual(%q) = %q, %q, %v", c.text, src, tgt, ok)
+		}
+	}
+	if _, _, ok := SplitBilingual("合同 Contract", "中文", "Japanese"); ok {
+		t.Fatal("languages sharing a script must not be split")
+	}
+}
+
+func TestTranslateDocxBilingual(t *testing.T) {
+	var seen []string
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		seen = append(seen, text)
+		return strings.ToUpper(text) + "!", nil
+	})
+	for _, c := range []struct {
+		policy BilingualPolicy
+		want   string
+		calls  int
+	}{
+		{BilingualPolicyTranslate, "合同 CONTRACT!", 2},
+		{BilingualPolicyKeep, "合同 Contract", 1},
+		{BilingualPolicyStripSource, "Contract", 1},
+		{BilingualPolicyRetranslate, "合同!", 2},
+	} {
+		seen = nil
+		tr := NewTranslator("", "").WithProvider(p).WithBilingualPolicy(c.policy)
+		newDoc, err := tr.TranslateDocx(newTestDoc("合同 Contract", "正文"), "English")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != c.want || len(seen) != c.calls {
+			t.Errorf("policy %d: got %q with %d calls (%q)", c.policy, s, len(seen), seen)
+		}
+		if est := tr.EstimateDocx(newTestDoc("合同 Contract", "正文"), "English"); est.Segments != c.calls {
+			t.Errorf("policy %d: estimate counted %d segments", c.policy, est.Segments)
+		}
+	}
+}
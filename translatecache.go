@@ -0,0 +1,108 @@
+package docx
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// CacheKey 唯一确定一次翻译
+type CacheKey struct {
+	Text   string // Text 是原文
+	Source string // Source 是原文语言
+	Target string // Target 是目标语言
+	Model  string // Model 是模型名
+	Hint   string // Hint 是附加的翻译要求, 如段落样式对应的语气提示
+}
+
+// Hash 返回键的 SHA-256 十六进制摘要, 供需要定长字符串键的缓存实现使用
+func (k CacheKey) Hash() string {
+	h := sha256.New()
+	for _, s := range [...]string{k.Text, k.Source, k.Target, k.Model} {
+		_, _ = h.Write(StringToBytes(s))
+		_, _ = h.Write([]byte{0})
+	}
+	if k.Hint != "" { // 没有附加要求时与旧版本的摘要保持一致
+		_, _ = h.Write(StringToBytes(k.Hint))
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(make([]byte, 0, sha256.Size)))
+}
+
+// Cache 保存已翻译的文本, 实现必须可以并发调用
+type Cache interface {
+	Get(key CacheKey) (string, bool)
+	Set(key CacheKey, translated string)
+}
+
+// WithCache 设置译文缓存, 再次翻译相同的文档 (或相似的文档) 时复用已有译文
+func (t *Translator) WithCache(c Cache) *Translator {
+	t.cache = c
+	return t
+}
+
+func (t *Translator) cacheKey(text, hint, targetLanguage string) CacheKey {
+	return CacheKey{Text: text, Source: t.sourceLanguageName(), Target: targetLanguage, Model: t.modelName(), Hint: hint}
+}
+
+// LRUCache 是容量有限的内存缓存, 满时淘汰最久未使用的条目
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[CacheKey]*list.Element
+}
+
+type lruEntry struct {
+	key   CacheKey
+	value string
+}
+
+// NewLRUCache 创建一个最多保存 capacity 条译文的 LRUCache
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &LRUCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[CacheKey]*list.Element, capacity),
+	}
+}
+
+// Get 实现 Cache
+func (c *LRUCache) Get(key CacheKey) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+// Set 实现 Cache
+func (c *LRUCache) Set(key CacheKey, translated string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.items[key]; ok {
+		e.Value.(*lruEntry).value = translated
+		c.ll.MoveToFront(e)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: translated})
+	if c.ll.Len() > c.capacity {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.items, e.Value.(*lruEntry).key)
+	}
+}
+
+// Len 返回缓存中的条目数
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
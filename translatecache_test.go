@@ -0,0 +1,185 @@
+package docx
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2)
+	a, b, d := CacheKey{Text: "a"}, CacheKey{Text: "b"}, CacheKey{Text: "d"}
+	c.Set(a, "A")
+	c.Set(b, "B")
+	if v, ok := c.Get(a); !ok || v != "A" {
+		t.Fatal("missing a")
+	}
+	c.Set(d, "D") // evicts b
+	if _, ok := c.Get(b); ok {
+		t.Fatal("b should be evicted")
+	}
+	if c.Len() != 2 {
+		t.Fatal("unexpected len", c.Len())
+	}
+	if a.Hash() == (CacheKey{Text: "a", Model: "x"}).Hash() {
+		t.Fatal("model must be part of the hash")
+	}
+}
+
+func TestTranslatorCache(t *testing.T) {
+	calls := 0
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		calls++
+		return strings.ToUpper(text), nil
+	})
+	c := NewLRUCache(16)
+	tr := NewTranslator("", "").WithProvider(p).WithCache(c)
+	for i := 0; i < 2; i++ {
+		if _, err := tr.TranslateDocx(newTestDoc("one", "two"), "English"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 2 {
+		t.Fatal("expected second run to hit the cache, calls:", calls)
+	}
+	if _, err := tr.WithModel("other").TranslateDocx(newTestDoc("one"), "English"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Fatal("expected a different model to miss the cache, calls:", calls)
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := CacheKey{Text: "你好", Source: "中文", Target: "English", Model: "qwen-plus"}
+	c.Set(k, "Hello")
+	// 模拟进程重启
+	c, err = NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := c.Get(k); !ok || v != "Hello" {
+		t.Fatal("expected persisted entry, got", v, ok)
+	}
+	if err = c.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get(k); ok {
+		t.Fatal("expected entry to be cleared")
+	}
+}
+
+// serveFakeRedis 在 l 上提供一个只支持 AUTH, SELECT, PING, GET, SET 的 Redis,
+// 收到的命令按顺序记录在 cmds 中
+func serveFakeRedis(l net.Listener, mu *sync.Mutex, cmds *[]string) {
+	data := make(map[string]string)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			rd := bufio.NewReader(conn)
+			for {
+				v, err := readRESP(rd)
+				if err != nil {
+					return
+				}
+				args := make([]string, 0, 4)
+				for _, a := range v.([]interface{}) {
+					args = append(args, a.(string))
+				}
+				mu.Lock()
+				*cmds = append(*cmds, strings.Join(args, " "))
+				reply := "+OK\r\n"
+				switch args[0] {
+				case "AUTH":
+					if args[1] != "secret" {
+						reply = "-WRONGPASS invalid password\r\n"
+					}
+				case "PING":
+					reply = "+PONG\r\n"
+				case "GET":
+					if s, ok := data[args[1]]; ok {
+						reply = "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+					} else {
+						reply = "$-1\r\n"
+					}
+				case "SET":
+					data[args[1]] = args[2]
+				}
+				mu.Unlock()
+				if _, err = conn.Write([]byte(reply)); err != nil {
+					return
+				}
+			}
+		}()
+	}
+}
+
+func TestRedisCache(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	var (
+		mu   sync.Mutex
+		cmds []string
+	)
+	go serveFakeRedis(l, &mu, &cmds)
+
+	c := NewRedisCache(l.Addr().String(), &RedisOptions{Password: "secret", DB: 2, Prefix: "t:", TTL: time.Minute})
+	defer c.Close()
+	k := CacheKey{Text: "你好", Source: "中文", Target: "English", Model: "qwen-plus"}
+	if _, ok := c.Get(k); ok {
+		t.Fatal("unexpected hit")
+	}
+	c.Set(k, "Hello")
+	// 断线后自动重连, 另一个 "进程" 也能读到
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	other := NewRedisCache(l.Addr().String(), &RedisOptions{Password: "secret", DB: 2, Prefix: "t:"})
+	defer other.Close()
+	if v, ok := other.Get(k); !ok || v != "Hello" {
+		t.Fatal("expected shared entry, got", v, ok)
+	}
+
+	mu.Lock()
+	got := strings.Join(cmds[:4], "\n")
+	mu.Unlock()
+	want := "AUTH secret\nSELECT 2\nGET t:" + k.Hash() + "\nSET t:" + k.Hash() + " Hello PX 60000"
+	if got != want {
+		t.Fatalf("unexpected commands:\n%s\nwant:\n%s", got, want)
+	}
+
+	bad := NewRedisCache(l.Addr().String(), &RedisOptions{Password: "wrong"})
+	if err = bad.Ping(); err == nil || !strings.Contains(err.Error(), "WRONGPASS") {
+		t.Fatal("expected auth error, got", err)
+	}
+}
+
+func TestReadRESPNestedError(t *testing.T) {
+	// EXEC 的回复中有错误时, 其后的元素与下一条回复仍然完整读取
+	rd := bufio.NewReader(strings.NewReader("*3\r\n+OK\r\n-ERR wrong type\r\n*2\r\n:1\r\n-ERR nested\r\n$5\r\nhello\r\n"))
+	v, err := readRESP(rd)
+	var rerr redisError
+	if !errors.As(err, &rerr) || string(rerr) != "ERR wrong type" || v != nil {
+		t.Fatal("expected the first error reply, got", v, err)
+	}
+	if v, err = readRESP(rd); err != nil || v != "hello" {
+		t.Fatal("connection out of sync:", v, err)
+	}
+}
@@ -0,0 +1,113 @@
+package docx
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// DiskCache 将译文保存在目录下的 256 个 JSON 分片文件中, 进程重启后依然有效,
+// 修订后的文档再次翻译时只需为改动的段落付费
+//
+// 分片以键摘要的前两个十六进制字符命名, 如 3f.json, 内容为 摘要 -> 译文 的对象.
+// 同一目录只应由一个进程写入.
+type DiskCache struct {
+	dir    string
+	mu     sync.Mutex
+	shards map[string]map[string]string
+}
+
+// NewDiskCache 创建 (必要时新建目录) 一个保存在 dir 下的 DiskCache
+func NewDiskCache(dir string) (*DiskCache, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir: dir, shards: make(map[string]map[string]string, 256)}, nil
+}
+
+// WithCacheDir 使用保存在 dir 下的 DiskCache 作为译文缓存,
+// 目录无法创建时记录错误并保持原有缓存设置
+func (t *Translator) WithCacheDir(dir string) *Translator {
+	c, err := NewDiskCache(dir)
+	if err != nil {
+		t.log().Log(LogLevelError, "无法创建缓存目录", "dir", dir, "err", err)
+		return t
+	}
+	return t.WithCache(c)
+}
+
+// shard 返回 (必要时从磁盘加载) 分片, 调用时必须持有锁
+func (c *DiskCache) shard(name string) map[string]string {
+	if m, ok := c.shards[name]; ok {
+		return m
+	}
+	m := make(map[string]string, 64)
+	data, err := os.ReadFile(filepath.Join(c.dir, name+".json"))
+	if err == nil {
+		_ = json.Unmarshal(data, &m) // 损坏的分片视为空分片, 下次写入时覆盖
+	}
+	c.shards[name] = m
+	return m
+}
+
+// Get 实现 Cache
+func (c *DiskCache) Get(key CacheKey) (string, bool) {
+	h := key.Hash()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.shard(h[:2])[h]
+	return v, ok
+}
+
+// Set 实现 Cache, 写入失败时仅保留在内存中
+func (c *DiskCache) Set(key CacheKey, translated string) {
+	h := key.Hash()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m := c.shard(h[:2])
+	m[h] = translated
+	_ = c.flush(h[:2], m)
+}
+
+// flush 原子地写入一个分片
+func (c *DiskCache) flush(name string, m map[string]string) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if err1 := tmp.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(c.dir, name+".json"))
+}
+
+// Clear 删除目录下所有的缓存分片
+func (c *DiskCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.shards = make(map[string]map[string]string, 256)
+	matches, err := filepath.Glob(filepath.Join(c.dir, "??.json"))
+	if err != nil {
+		return err
+	}
+	for _, name := range matches {
+		err = os.Remove(name)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
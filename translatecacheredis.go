@@ -0,0 +1,220 @@
+package docx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RedisOptions 是 RedisCache 的可选配置
+type RedisOptions struct {
+	Password string        // Password 非空时连接后发送 AUTH
+	DB       int           // DB 非零时连接后发送 SELECT
+	Prefix   string        // Prefix 是键的前缀, 默认为 "docx-translate:"
+	TTL      time.Duration // TTL 是译文的过期时间, 零表示永不过期
+	Timeout  time.Duration // Timeout 是连接与单条命令的超时时间, 默认为 3 秒
+}
+
+// RedisCache 将译文保存在 Redis 中, 多台机器上的翻译进程可以共享同一份缓存
+//
+// 键为 Prefix + CacheKey.Hash(). Redis 不可用时 Get 视为未命中, Set 被忽略,
+// 翻译本身不受影响. 连接出错后会在下一次调用时重新建立.
+type RedisCache struct {
+	addr string
+	opts RedisOptions
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// NewRedisCache 创建一个连接到 addr (host:port) 的 RedisCache, opts 可以为 nil
+//
+// 连接在第一次使用时才会建立.
+func NewRedisCache(addr string, opts *RedisOptions) *RedisCache {
+	c := &RedisCache{addr: addr}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.Prefix == "" {
+		c.opts.Prefix = "docx-translate:"
+	}
+	if c.opts.Timeout <= 0 {
+		c.opts.Timeout = 3 * time.Second
+	}
+	return c
+}
+
+// Get 实现 Cache
+func (c *RedisCache) Get(key CacheKey) (string, bool) {
+	v, err := c.Do("GET", c.opts.Prefix+key.Hash())
+	if err != nil {
+		return "", false
+	}
+	s, ok := v.(string)
+	return s, ok
+}
+
+// Set 实现 Cache
+func (c *RedisCache) Set(key CacheKey, translated string) {
+	args := []string{"SET", c.opts.Prefix + key.Hash(), translated}
+	if c.opts.TTL > 0 {
+		args = append(args, "PX", strconv.FormatInt(c.opts.TTL.Milliseconds(), 10))
+	}
+	_, _ = c.Do(args...)
+}
+
+// Ping 检查 Redis 是否可用
+func (c *RedisCache) Ping() error {
+	_, err := c.Do("PING")
+	return err
+}
+
+// Close 关闭当前连接, 之后的调用会重新连接
+func (c *RedisCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.rd = nil, nil
+	return err
+}
+
+// Do 发送一条命令并返回回复, 回复为 string, int64, []interface{} 或 nil (空回复)
+func (c *RedisCache) Do(args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		err := c.dial()
+		if err != nil {
+			return nil, err
+		}
+	}
+	v, err := c.roundTrip(args)
+	var rerr redisError
+	if err != nil && !errors.As(err, &rerr) {
+		// 网络错误后连接状态未知, 丢弃
+		_ = c.conn.Close()
+		c.conn, c.rd = nil, nil
+	}
+	return v, err
+}
+
+// dial 建立连接并完成认证, 调用时必须持有锁
+func (c *RedisCache) dial() error {
+	conn, err := net.DialTimeout("tcp", c.addr, c.opts.Timeout)
+	if err != nil {
+		return err
+	}
+	c.conn, c.rd = conn, bufio.NewReader(conn)
+	if c.opts.Password != "" {
+		_, err = c.roundTrip([]string{"AUTH", c.opts.Password})
+	}
+	if err == nil && c.opts.DB != 0 {
+		_, err = c.roundTrip([]string{"SELECT", strconv.Itoa(c.opts.DB)})
+	}
+	if err != nil {
+		_ = conn.Close()
+		c.conn, c.rd = nil, nil
+	}
+	return err
+}
+
+func (c *RedisCache) roundTrip(args []string) (interface{}, error) {
+	err := c.conn.SetDeadline(time.Now().Add(c.opts.Timeout))
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, a := range args {
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(a)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, a...)
+		buf = append(buf, '\r', '\n')
+	}
+	_, err = c.conn.Write(buf)
+	if err != nil {
+		return nil, err
+	}
+	return readRESP(c.rd)
+}
+
+// redisError 是服务器返回的错误回复 (包括数组回复中的), 不影响连接的可用性
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// readRESP 读取一条 RESP2 回复
+func readRESP(rd *bufio.Reader) (interface{}, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: invalid reply %q", line)
+	}
+	typ, body := line[0], line[1:len(line)-2]
+	switch typ {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		_, err = io.ReadFull(rd, data)
+		if err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		// 元素中的错误回复不中断读取, 读完其余的元素后再返回, 使连接保持同步
+		var first error
+		items := make([]interface{}, n)
+		for i := range items {
+			items[i], err = readRESP(rd)
+			var rerr redisError
+			switch {
+			case errors.As(err, &rerr):
+				if first == nil {
+					first = err
+				}
+			case err != nil:
+				return nil, err
+			}
+		}
+		if first != nil {
+			return nil, first
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("redis: unknown reply type %q", typ)
+}
@@ -0,0 +1,141 @@
+package docx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// HeadingCase 是标题的大小写规则
+type HeadingCase uint8
+
+const (
+	// HeadingCaseKeep 保持译文不变
+	HeadingCaseKeep HeadingCase = iota
+	// HeadingCaseTitle 英文标题式: 除冠词、连词与短介词外每个词首字母大写, 首尾词总是大写
+	HeadingCaseTitle
+	// HeadingCaseSentence 句子式: 只保证首字母大写, 其余保持不变 (德语名词本身需要大写, 不能统一转为小写)
+	HeadingCaseSentence
+	// HeadingCaseUpper 全部大写
+	HeadingCaseUpper
+)
+
+// DefaultHeadingCases 是常见目标语言 (ISO 639-1 代码) 的标题大小写习惯
+var DefaultHeadingCases = map[string]HeadingCase{
+	"en": HeadingCaseTitle,
+	"de": HeadingCaseSentence,
+	"fr": HeadingCaseSentence,
+	"es": HeadingCaseSentence,
+	"it": HeadingCaseSentence,
+	"pt": HeadingCaseSentence,
+	"nl": HeadingCaseSentence,
+	"sv": HeadingCaseSentence,
+	"pl": HeadingCaseSentence,
+	"ru": HeadingCaseSentence,
+}
+
+// WithHeadingCase 对标题样式 (见 DefaultStyleHints) 段落的译文按目标语言应用大小写规则,
+// rules 的键是 ISO 639-1 代码, 可以直接使用 DefaultHeadingCases; 默认不做处理
+//
+// 不翻译列表中的内容保持原样.
+func (t *Translator) WithHeadingCase(rules map[string]HeadingCase) *Translator {
+	t.headingCases = rules
+	return t
+}
+
+// isHeadingStyle 判断样式 ID 是否为标题样式
+func isHeadingStyle(styleID string) bool {
+	id := strings.ToLower(styleID)
+	return id == "title" || id == "subtitle" || strings.HasPrefix(id, "heading") ||
+		len(id) == 1 && id[0] >= '1' && id[0] <= '9'
+}
+
+// applyHeadingCase 对标题段落 p 的译文应用大小写规则
+func (t *Translator) applyHeadingCase(p *Paragraph, translated, targetLanguage string) string {
+	if t.headingCases == nil || p == nil || p.Properties == nil || p.Properties.Style == nil || !isHeadingStyle(p.Properties.Style.Val) {
+		return translated
+	}
+	hc := t.headingCases[LanguageCode(targetLanguage)]
+	if hc == HeadingCaseKeep {
+		return translated
+	}
+	masked, originals := t.mask(translated)
+	masked = ApplyHeadingCase(masked, hc)
+	s, err := unmask(masked, originals)
+	if err != nil { // 不会发生: 大小写转换不改变占位符
+		return translated
+	}
+	return s
+}
+
+// titleCaseMinorWords 是英文标题中除首尾外保持小写的词
+var titleCaseMinorWords = map[string]struct{}{
+	"a": {}, "an": {}, "the": {},
+	"and": {}, "but": {}, "or": {}, "nor": {}, "for": {}, "so": {}, "yet": {},
+	"as": {}, "at": {}, "by": {}, "in": {}, "of": {}, "off": {}, "on": {}, "per": {},
+	"to": {}, "up": {}, "via": {}, "vs": {}, "vs.": {},
+}
+
+// ApplyHeadingCase 按 hc 转换标题 s 的大小写; 标题式中已经含有大写字母的词 (如 iPhone, NASA)
+// 保持不变, 首字母大写的虚词 (如 Of, The) 转为小写
+func ApplyHeadingCase(s string, hc HeadingCase) string {
+	switch hc {
+	case HeadingCaseKeep:
+		return s
+	case HeadingCaseUpper:
+		return strings.ToUpper(s)
+	case HeadingCaseSentence:
+		return upperFirst(s)
+	case HeadingCaseTitle:
+		words := strings.Fields(s)
+		if len(words) == 0 {
+			return s
+		}
+		var sb strings.Builder
+		sb.Grow(len(s))
+		rest := s
+		for i, w := range words {
+			j := strings.Index(rest, w)
+			sb.WriteString(rest[:j])
+			rest = rest[j+len(w):]
+			_, minor := titleCaseMinorWords[strings.ToLower(strings.Trim(w, "\"'“”‘’()[]:,"))]
+			switch {
+			case minor && i > 0 && i < len(words)-1 && !strings.HasSuffix(words[i-1], ":"):
+				if w == upperFirst(strings.ToLower(w)) {
+					w = strings.ToLower(w) // Of -> of, 但保留 OR 这样的缩写
+				}
+			case hasUpper(w):
+			default:
+				// 连字符连接的词每一段都大写, 如 Well-Known
+				parts := strings.Split(w, "-")
+				for k, part := range parts {
+					parts[k] = upperFirst(part)
+				}
+				w = strings.Join(parts, "-")
+			}
+			sb.WriteString(w)
+		}
+		sb.WriteString(rest)
+		return sb.String()
+	}
+	return s
+}
+
+// upperFirst 将 s 中第一个字母转为大写
+func upperFirst(s string) string {
+	for i, r := range s {
+		if unicode.IsLetter(r) {
+			return s[:i] + string(unicode.ToUpper(r)) + s[i+utf8.RuneLen(r):]
+		}
+	}
+	return s
+}
+
+func hasUpper(s string) bool {
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package docx
+
+import "testing"
+
+func TestApplyHeadingCase(t *testing.T) {
+	for _, c := range []struct {
+		in   string
+		hc   HeadingCase
+		want string
+	}{
+		{"terms of the contract", HeadingCaseTitle, "Terms of the Contract"},
+		{"The Terms Of The Contract", HeadingCaseTitle, "The Terms of the Contract"},
+		{"using an iPhone with NASA OR ESA", HeadingCaseTitle, "Using an iPhone With NASA OR ESA"},
+		{"a well-known issue: the fix", HeadingCaseTitle, "A Well-Known Issue: The Fix"},
+		{"where to go", HeadingCaseTitle, "Where to Go"},
+		{"«allgemeine Bedingungen»", HeadingCaseSentence, "«Allgemeine Bedingungen»"},
+		{"überblick", HeadingCaseUpper, "ÜBERBLICK"},
+	} {
+		if got := ApplyHeadingCase(c.in, c.hc); got != c.want {
+			t.Errorf("ApplyHeadingCase(%q, %d) = %q, want %q", c.in, c.hc, got, c.want)
+		}
+	}
+}
+
+func TestTranslateDocxHeadingCase(t *testing.T) {
+	doc := New().WithDefaultTheme()
+	doc.AddParagraph().Style("Heading1").AddText("标题")
+	doc.AddParagraph().AddText("正文")
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		if text == "标题" {
+			return "overview of docx-translate", nil
+		}
+		return "body text of docx-translate", nil
+	})
+	tr := NewTranslator("", "").WithProvider(p).WithDNT(NewDNTList("docx-translate")).WithHeadingCase(DefaultHeadingCases)
+	newDoc, err := tr.TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "Overview of docx-translate" {
+		t.Fatal("unexpected heading:", s)
+	}
+	if s := newDoc.Document.Body.Items[1].(*Paragraph).String(); s != "body text of docx-translate" {
+		t.Fatal("body must not be changed:", s)
+	}
+	newDoc, err = tr.TranslateDocx(doc, "Japanese")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "overview of docx-translate" {
+		t.Fatal("languages without a rule must be kept:", s)
+	}
+}
@@ -0,0 +1,490 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf16"
+)
+
+// 复合文档 (Compound File Binary, MS-CFB) 是加密的 Office 文档的容器, 加密后的 docx 包与加密参数作为其中的流保存
+
+// cfbSignature 是复合文档的文件头
+var cfbSignature = []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}
+
+// errCFBCorrupt 复合文档的结构无效
+var errCFBCorrupt = errors.New("corrupt compound file")
+
+const (
+	cfbFreeSect   = 0xffffffff // cfbFreeSect 是未使用的扇区
+	cfbEndOfChain = 0xfffffffe // cfbEndOfChain 是扇区链的结尾
+	cfbFATSect    = 0xfffffffd // cfbFATSect 是存放 FAT 的扇区
+	cfbDIFATSect  = 0xfffffffc // cfbDIFATSect 是存放 DIFAT 的扇区
+	cfbNoStream   = 0xffffffff // cfbNoStream 表示目录项没有相邻或下级的目录项
+
+	cfbSectorSize   = 512  // cfbSectorSize 是写入时使用的扇区大小 (版本 3)
+	cfbMiniSize     = 64   // cfbMiniSize 是小扇区的大小
+	cfbMiniCutoff   = 4096 // cfbMiniCutoff 以下的流保存在小扇区中
+	cfbDirEntrySize = 128
+	cfbHeaderDIFATs = 109 // cfbHeaderDIFATs 是文件头中 FAT 扇区号的个数
+	cfbTypeStorage  = 1
+	cfbTypeStream   = 2
+	cfbTypeRoot     = 5
+)
+
+// isCFB 判断 data 是否为复合文档
+func isCFB(data []byte) bool {
+	return bytes.HasPrefix(data, cfbSignature)
+}
+
+// cfbEntry 是复合文档的一个目录项
+type cfbEntry struct {
+	name               string
+	typ                byte
+	left, right, child uint32
+	start              uint32
+	size               uint64
+}
+
+// cfbReader 读取复合文档中的流
+type cfbReader struct {
+	data       []byte
+	sectorSize int
+	fat        []uint32
+	miniFAT    []uint32
+	miniStream []byte
+	entries    []cfbEntry
+}
+
+// readCFB 解析复合文档 data 的目录
+func readCFB(data []byte) (*cfbReader, error) {
+	if len(data) < 512 || !isCFB(data) {
+		return nil, errCFBCorrupt
+	}
+	le := binary.LittleEndian
+	shift := le.Uint16(data[30:])
+	if shift != 9 && shift != 12 {
+		return nil, errCFBCorrupt
+	}
+	r := &cfbReader{data: data, sectorSize: 1 << shift}
+	numFAT := int(le.Uint32(data[44:]))
+	if numFAT > r.sectors() {
+		return nil, errCFBCorrupt // 每个 FAT 扇区都在文件中, 不可能多于扇区总数
+	}
+	firstDir := le.Uint32(data[48:])
+	firstMiniFAT := le.Uint32(data[60:])
+	firstDIFAT := le.Uint32(data[68:])
+
+	// FAT 扇区号先取自文件头, 其余的在 DIFAT 扇区链中
+	var fatSectors []uint32
+	for i := 0; i < cfbHeaderDIFATs && len(fatSectors) < numFAT; i++ {
+		fatSectors = append(fatSectors, le.Uint32(data[76+4*i:]))
+	}
+	per := r.sectorSize/4 - 1
+	for sect, steps := firstDIFAT, 0; len(fatSectors) < numFAT && sect < cfbDIFATSect; steps++ {
+		s, ok := r.sector(sect)
+		if !ok || steps > numFAT {
+			return nil, errCFBCorrupt
+		}
+		for i := 0; i < per && len(fatSectors) < numFAT; i++ {
+			fatSectors = append(fatSectors, le.Uint32(s[4*i:]))
+		}
+		sect = le.Uint32(s[4*per:])
+	}
+	if len(fatSectors) < numFAT {
+		return nil, errCFBCorrupt
+	}
+	for _, sect := range fatSectors {
+		s, ok := r.sector(sect)
+		if !ok {
+			return nil, errCFBCorrupt
+		}
+		for i := 0; i < len(s); i += 4 {
+			r.fat = append(r.fat, le.Uint32(s[i:]))
+		}
+	}
+
+	dir, err := r.chain(firstDir, -1)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i+cfbDirEntrySize <= len(dir); i += cfbDirEntrySize {
+		e := dir[i : i+cfbDirEntrySize]
+		n := int(le.Uint16(e[64:]))
+		if n > 64 {
+			n = 64
+		}
+		name := make([]uint16, 0, 32)
+		for j := 0; j+1 < n; j += 2 {
+			if c := le.Uint16(e[j:]); c != 0 {
+				name = append(name, c)
+			}
+		}
+		size := le.Uint64(e[120:])
+		if r.sectorSize == 512 {
+			size &= 0xffffffff // 版本 3 中高 32 位可能是未初始化的内容
+		}
+		r.entries = append(r.entries, cfbEntry{
+			name:  string(utf16.Decode(name)),
+			typ:   e[66],
+			left:  le.Uint32(e[68:]),
+			right: le.Uint32(e[72:]),
+			child: le.Uint32(e[76:]),
+			start: le.Uint32(e[116:]),
+			size:  size,
+		})
+	}
+	if len(r.entries) == 0 || r.entries[0].typ != cfbTypeRoot {
+		return nil, errCFBCorrupt
+	}
+
+	if firstMiniFAT < cfbDIFATSect {
+		mf, err := r.chain(firstMiniFAT, -1)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i+4 <= len(mf); i += 4 {
+			r.miniFAT = append(r.miniFAT, le.Uint32(mf[i:]))
+		}
+	}
+	if root := r.entries[0]; root.start < cfbDIFATSect {
+		if r.miniStream, err = r.chain(root.start, int64(root.size)); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// sectors 返回文件中扇区的个数, 也是任何扇区链长度的上限
+func (r *cfbReader) sectors() int {
+	return len(r.data)/r.sectorSize - 1
+}
+
+// sector 返回第 n 个扇区的内容
+func (r *cfbReader) sector(n uint32) ([]byte, bool) {
+	off := (int64(n) + 1) * int64(r.sectorSize)
+	if n >= cfbDIFATSect || off+int64(r.sectorSize) > int64(len(r.data)) {
+		return nil, false
+	}
+	return r.data[off : off+int64(r.sectorSize)], true
+}
+
+// chain 拼接从扇区 start 开始的扇区链, size >= 0 时截取前 size 个字节
+//
+// 每个扇区最多出现一次, 链的长度因此不超过文件本身; 损坏的文件中 FAT 的环返回 errCFBCorrupt.
+func (r *cfbReader) chain(start uint32, size int64) ([]byte, error) {
+	if size > int64(len(r.data)) {
+		return nil, errCFBCorrupt
+	}
+	var buf []byte
+	visited := make([]bool, len(r.fat))
+	for sect := start; sect != cfbEndOfChain; {
+		s, ok := r.sector(sect)
+		if !ok || int(sect) >= len(r.fat) || visited[sect] {
+			return nil, errCFBCorrupt
+		}
+		visited[sect] = true
+		buf = append(buf, s...)
+		if size >= 0 && int64(len(buf)) >= size {
+			break
+		}
+		sect = r.fat[sect]
+	}
+	if size >= 0 {
+		if int64(len(buf)) < size {
+			return nil, errCFBCorrupt
+		}
+		buf = buf[:size]
+	}
+	return buf, nil
+}
+
+// miniChain 拼接小扇区链, 与 chain 一样每个小扇区最多出现一次
+func (r *cfbReader) miniChain(start uint32, size int64) ([]byte, error) {
+	if size > int64(len(r.miniStream)) {
+		return nil, errCFBCorrupt
+	}
+	buf := make([]byte, 0, size)
+	visited := make([]bool, len(r.miniFAT))
+	for sect := start; int64(len(buf)) < size; {
+		off := int64(sect) * cfbMiniSize
+		if sect >= cfbDIFATSect || int(sect) >= len(r.miniFAT) || off+cfbMiniSize > int64(len(r.miniStream)) || visited[sect] {
+			return nil, errCFBCorrupt
+		}
+		visited[sect] = true
+		buf = append(buf, r.miniStream[off:off+cfbMiniSize]...)
+		sect = r.miniFAT[sect]
+	}
+	return buf[:size], nil
+}
+
+// stream 返回根存储下名为 name 的流 (名称不区分大小写), 不存在时返回 false
+func (r *cfbReader) stream(name string) ([]byte, bool, error) {
+	e, ok := r.find(r.entries[0].child, name, 0)
+	if !ok || e.typ != cfbTypeStream {
+		return nil, false, nil
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if e.size < cfbMiniCutoff {
+		data, err = r.miniChain(e.start, int64(e.size))
+	} else {
+		data, err = r.chain(e.start, int64(e.size))
+	}
+	return data, err == nil, err
+}
+
+// find 在以 id 为根的目录树中查找名为 name 的目录项
+func (r *cfbReader) find(id uint32, name string, depth int) (cfbEntry, bool) {
+	if id == cfbNoStream || int(id) >= len(r.entries) || depth > len(r.entries) {
+		return cfbEntry{}, false
+	}
+	e := r.entries[id]
+	if strings.EqualFold(e.name, name) {
+		return e, true
+	}
+	if found, ok := r.find(e.left, name, depth+1); ok {
+		return found, true
+	}
+	return r.find(e.right, name, depth+1)
+}
+
+// cfbNode 是写入复合文档的一个存储或流, data 为 nil 且 children 非空时是存储
+type cfbNode struct {
+	name     string
+	data     []byte
+	children []*cfbNode
+}
+
+// writeCFB 将 root 下的存储与流写为版本 3 的复合文档
+func writeCFB(root []*cfbNode) []byte {
+	le := binary.LittleEndian
+	type dirEntry struct {
+		node               *cfbNode
+		typ                byte
+		left, right, child uint32
+		start              uint32
+		size               uint64
+	}
+	entries := []*dirEntry{{typ: cfbTypeRoot, left: cfbNoStream, right: cfbNoStream, child: cfbNoStream}}
+	// 同一存储下的目录项按名称排序 (先比较长度, 再不区分大小写比较) 后串成一条右链, 这是一棵有效的二叉搜索树
+	var add func(parent *dirEntry, nodes []*cfbNode)
+	add = func(parent *dirEntry, nodes []*cfbNode) {
+		sorted := append([]*cfbNode(nil), nodes...)
+		sort.Slice(sorted, func(i, j int) bool {
+			a, b := utf16.Encode([]rune(sorted[i].name)), utf16.Encode([]rune(sorted[j].name))
+			if len(a) != len(b) {
+				return len(a) < len(b)
+			}
+			return strings.ToUpper(sorted[i].name) < strings.ToUpper(sorted[j].name)
+		})
+		var prev *dirEntry
+		for _, n := range sorted {
+			e := &dirEntry{node: n, typ: cfbTypeStream, left: cfbNoStream, right: cfbNoStream, child: cfbNoStream}
+			if n.data == nil && len(n.children) > 0 {
+				e.typ = cfbTypeStorage
+			}
+			id := uint32(len(entries))
+			entries = append(entries, e)
+			if prev == nil {
+				parent.child = id
+			} else {
+				prev.right = id
+			}
+			prev = e
+			if e.typ == cfbTypeStorage {
+				add(e, n.children)
+			}
+		}
+	}
+	add(entries[0], root)
+
+	// 小于 cfbMiniCutoff 的流放入小扇区, 其余的流占用普通扇区
+	var mini []byte
+	var miniFAT []uint32
+	var large []*dirEntry
+	for _, e := range entries[1:] {
+		if e.typ != cfbTypeStream {
+			continue
+		}
+		e.size = uint64(len(e.node.data))
+		switch {
+		case len(e.node.data) == 0:
+			e.start = cfbEndOfChain
+		case len(e.node.data) < cfbMiniCutoff:
+			e.start = uint32(len(miniFAT))
+			n := (len(e.node.data) + cfbMiniSize - 1) / cfbMiniSize
+			for i := 0; i < n; i++ {
+				next := uint32(len(miniFAT) + 1)
+				if i == n-1 {
+					next = cfbEndOfChain
+				}
+				miniFAT = append(miniFAT, next)
+			}
+			mini = append(mini, e.node.data...)
+			mini = append(mini, make([]byte, n*cfbMiniSize-len(e.node.data))...)
+		default:
+			large = append(large, e)
+		}
+	}
+
+	sectors := func(n int) int { return (n + cfbSectorSize - 1) / cfbSectorSize }
+	dirSectors := sectors(len(entries) * cfbDirEntrySize)
+	miniFATSectors := sectors(len(miniFAT) * 4)
+	miniSectors := sectors(len(mini))
+	dataSectors := dirSectors + miniFATSectors + miniSectors
+	for _, e := range large {
+		dataSectors += sectors(len(e.node.data))
+	}
+	// FAT 需要覆盖包括自身与 DIFAT 在内的所有扇区
+	fatSectors, difatSectors := 0, 0
+	for {
+		difatSectors = 0
+		if fatSectors > cfbHeaderDIFATs {
+			difatSectors = (fatSectors - cfbHeaderDIFATs + cfbSectorSize/4 - 2) / (cfbSectorSize/4 - 1)
+		}
+		if fatSectors*cfbSectorSize/4 >= dataSectors+fatSectors+difatSectors {
+			break
+		}
+		fatSectors++
+	}
+
+	total := fatSectors + difatSectors + dataSectors
+	fat := make([]uint32, fatSectors*cfbSectorSize/4)
+	for i := range fat {
+		fat[i] = cfbFreeSect
+	}
+	next := 0
+	alloc := func(n int, mark uint32) uint32 {
+		if n == 0 {
+			return cfbEndOfChain
+		}
+		start := next
+		for i := 0; i < n; i++ {
+			switch {
+			case mark != 0:
+				fat[next] = mark
+			case i == n-1:
+				fat[next] = cfbEndOfChain
+			default:
+				fat[next] = uint32(next + 1)
+			}
+			next++
+		}
+		return uint32(start)
+	}
+	fatStart := alloc(fatSectors, cfbFATSect)
+	difatStart := alloc(difatSectors, cfbDIFATSect)
+	dirStart := alloc(dirSectors, 0)
+	miniFATStart := alloc(miniFATSectors, 0)
+	entries[0].start = alloc(miniSectors, 0)
+	entries[0].size = uint64(len(mini))
+	for _, e := range large {
+		e.start = alloc(sectors(len(e.node.data)), 0)
+	}
+
+	out := make([]byte, (total+1)*cfbSectorSize)
+	at := func(sect uint32) []byte {
+		return out[(int(sect)+1)*cfbSectorSize:]
+	}
+
+	// 文件头
+	h := out[:cfbSectorSize]
+	copy(h, cfbSignature)
+	le.PutUint16(h[24:], 0x3e)
+	le.PutUint16(h[26:], 3)
+	le.PutUint16(h[28:], 0xfffe)
+	le.PutUint16(h[30:], 9)
+	le.PutUint16(h[32:], 6)
+	le.PutUint32(h[44:], uint32(fatSectors))
+	le.PutUint32(h[48:], dirStart)
+	le.PutUint32(h[56:], cfbMiniCutoff)
+	le.PutUint32(h[60:], miniFATStart)
+	le.PutUint32(h[64:], uint32(miniFATSectors))
+	le.PutUint32(h[68:], cfbEndOfChain)
+	if difatSectors > 0 {
+		le.PutUint32(h[68:], difatStart)
+	}
+	le.PutUint32(h[72:], uint32(difatSectors))
+	for i := 0; i < cfbHeaderDIFATs; i++ {
+		v := uint32(cfbFreeSect)
+		if i < fatSectors {
+			v = fatStart + uint32(i)
+		}
+		le.PutUint32(h[76+4*i:], v)
+	}
+
+	// DIFAT 扇区: 每个扇区存放 127 个 FAT 扇区号, 最后 4 个字节指向下一个 DIFAT 扇区
+	per := cfbSectorSize/4 - 1
+	for d := 0; d < difatSectors; d++ {
+		s := at(difatStart + uint32(d))
+		for i := 0; i < per; i++ {
+			v := uint32(cfbFreeSect)
+			if k := cfbHeaderDIFATs + d*per + i; k < fatSectors {
+				v = fatStart + uint32(k)
+			}
+			le.PutUint32(s[4*i:], v)
+		}
+		link := uint32(cfbEndOfChain)
+		if d < difatSectors-1 {
+			link = difatStart + uint32(d+1)
+		}
+		le.PutUint32(s[4*per:], link)
+	}
+
+	for i, v := range fat {
+		le.PutUint32(at(fatStart)[4*i:], v)
+	}
+
+	// 目录, 未使用的目录项的相邻与下级目录项也是 cfbNoStream
+	dir := at(dirStart)
+	for i := 0; i < dirSectors*cfbSectorSize/cfbDirEntrySize; i++ {
+		d := dir[i*cfbDirEntrySize : (i+1)*cfbDirEntrySize]
+		le.PutUint32(d[68:], cfbNoStream)
+		le.PutUint32(d[72:], cfbNoStream)
+		le.PutUint32(d[76:], cfbNoStream)
+		if i >= len(entries) {
+			continue
+		}
+		e := entries[i]
+		name := "Root Entry"
+		if e.node != nil {
+			name = e.node.name
+		}
+		u := utf16.Encode([]rune(name))
+		for j, c := range u {
+			le.PutUint16(d[2*j:], c)
+		}
+		le.PutUint16(d[64:], uint16(2*len(u)+2))
+		d[66] = e.typ
+		d[67] = 1 // 黑色
+		le.PutUint32(d[68:], e.left)
+		le.PutUint32(d[72:], e.right)
+		le.PutUint32(d[76:], e.child)
+		if e.typ != cfbTypeStorage {
+			le.PutUint32(d[116:], e.start)
+			le.PutUint64(d[120:], e.size)
+		}
+	}
+
+	if miniFATSectors > 0 {
+		s := at(miniFATStart)
+		for i := range s[:miniFATSectors*cfbSectorSize] {
+			s[i] = 0xff
+		}
+		for i, v := range miniFAT {
+			le.PutUint32(s[4*i:], v)
+		}
+	}
+	if miniSectors > 0 {
+		copy(at(entries[0].start), mini)
+	}
+	for _, e := range large {
+		copy(at(e.start), e.node.data)
+	}
+	return out
+}
@@ -0,0 +1,42 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+func TestReadCFBChainCycle(t *testing.T) {
+	data := writeCFB([]*cfbNode{{name: "a", data: bytes.Repeat([]byte("x"), 5000)}})
+	if _, err := readCFB(data); err != nil {
+		t.Fatal(err)
+	}
+	// 让目录扇区在 FAT 中指向自身
+	le := binary.LittleEndian
+	dir := le.Uint32(data[48:])
+	fat := le.Uint32(data[76:])
+	le.PutUint32(data[(int(fat)+1)*cfbSectorSize+4*int(dir):], dir)
+	if _, err := readCFB(data); !errors.Is(err, errCFBCorrupt) {
+		t.Fatal("expected errCFBCorrupt, got", err)
+	}
+}
+
+func FuzzReadCFB(f *testing.F) {
+	f.Add(writeCFB([]*cfbNode{{name: "a", data: []byte("small")}, {name: "b", data: bytes.Repeat([]byte("y"), 5000)}}))
+	if encrypted, err := Encrypt([]byte("PK\x03\x04"), "pw"); err == nil {
+		f.Add(encrypted)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		IsEncrypted(data)
+		r, err := readCFB(data)
+		if err != nil {
+			return
+		}
+		for _, e := range r.entries {
+			if s, ok, _ := r.stream(e.name); ok && len(s) > len(data) {
+				t.Fatalf("stream %q is larger than the file", e.name)
+			}
+		}
+	})
+}
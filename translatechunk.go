@@ -0,0 +1,144 @@
+package docx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Joiner 返回将 targetLanguage 的译文片段拼接回一个段落时使用的分隔符
+type Joiner func(targetLanguage string) string
+
+// DefaultJoiner 对中文、日文、泰文等词间不加空格的语言返回空串, 其余返回一个空格
+func DefaultJoiner(targetLanguage string) string {
+	if isScriptioContinua(targetLanguage) {
+		return ""
+	}
+	return " "
+}
+
+// WithMaxChunkRunes 设置单次请求的最大字数, 超过的段落会按句切分后分别翻译,
+// 再以 Joiner 拼接; 默认为 0, 即整段翻译
+func (t *Translator) WithMaxChunkRunes(n int) *Translator {
+	t.maxChunkRunes = n
+	return t
+}
+
+// WithJoiner 设置拼接译文片段的方式, 默认为 DefaultJoiner
+func (t *Translator) WithJoiner(j Joiner) *Translator {
+	t.joiner = j
+	return t
+}
+
+// translateSegment 翻译一个段落, 优先使用翻译记忆中完全匹配的译文, hint 是附加的翻译要求
+func (t *Translator) translateSegment(text, hint, targetLanguage string) (string, error) {
+	if t.tm != nil {
+		if translated, ok := t.tm.Lookup(t.sourceLanguageName(), targetLanguage, text); ok {
+			t.log().Log(LogLevelDebug, "命中翻译记忆", "source", text)
+			return translated, nil
+		}
+		if translated, ok := t.translateFuzzy(text, targetLanguage); ok {
+			return translated, nil
+		}
+	}
+	translated, err := t.translateChunks(text, hint, targetLanguage)
+	if err == nil {
+		translated = t.reviewTranslation(text, translated, targetLanguage)
+	}
+	if err == nil && t.tm != nil {
+		t.tm.Add(t.sourceLanguageName(), targetLanguage, text, translated)
+	}
+	return translated, err
+}
+
+// translateChunks 翻译一个段落, 必要时先按句切分
+func (t *Translator) translateChunks(text, hint, targetLanguage string) (string, error) {
+	if t.maxChunkRunes <= 0 || utf8.RuneCountInString(text) <= t.maxChunkRunes {
+		return t.translateText(text, hint, targetLanguage)
+	}
+	chunks := chunkSentences(text, t.maxChunkRunes)
+	translated := make([]string, len(chunks))
+	for i, c := range chunks {
+		s, err := t.translateText(c, hint, targetLanguage)
+		if err != nil {
+			return "", err
+		}
+		translated[i] = strings.TrimSpace(s)
+	}
+	joiner := t.joiner
+	if joiner == nil {
+		joiner = DefaultJoiner
+	}
+	return strings.Join(translated, joiner(targetLanguage)), nil
+}
+
+// isSentenceEnd 判断 r 是否为句末标点
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '.', '!', '?', ';', '。', '！', '？', '；', '…':
+		return true
+	default:
+		return false
+	}
+}
+
+// splitSentences 在句末标点后切分文本, 西文句末标点需后接空白才切分;
+// 切分处的空白被去除
+func splitSentences(text string) []string {
+	sentences := make([]string, 0, 8)
+	runes := []rune(text)
+	start := 0
+	for i, r := range runes {
+		if !isSentenceEnd(r) {
+			continue
+		}
+		if r < utf8.RuneSelf && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			continue // 如 3.14, e.g. 这样的情况不切分
+		}
+		if i+1 < len(runes) && isSentenceEnd(runes[i+1]) {
+			continue // 连续的标点如 ?! 或 …… 视为一体
+		}
+		if s := strings.TrimSpace(string(runes[start : i+1])); s != "" {
+			sentences = append(sentences, s)
+		}
+		start = i + 1
+	}
+	if s := strings.TrimSpace(string(runes[start:])); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
+
+// chunkSentences 将句子贪心地合并为不超过 limit 字的片段, 单个超长的句子独占一个片段
+func chunkSentences(text string, limit int) []string {
+	sentences := splitSentences(text)
+	chunks := make([]string, 0, len(sentences))
+	var cur strings.Builder
+	curLen := 0
+	for _, s := range sentences {
+		n := utf8.RuneCountInString(s)
+		if curLen > 0 && curLen+1+n > limit {
+			chunks = append(chunks, cur.String())
+			cur.Reset()
+			curLen = 0
+		}
+		if curLen > 0 {
+			// 原文片段内部保留原有的分隔习惯
+			if !isCJK(lastRune(cur.String())) {
+				cur.WriteByte(' ')
+				curLen++
+			}
+		}
+		cur.WriteString(s)
+		curLen += n
+	}
+	if curLen > 0 {
+		chunks = append(chunks, cur.String())
+	}
+	return chunks
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
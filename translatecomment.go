@@ -0,0 +1,246 @@
+package docx
+
+import (
+	"encoding/xml"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	relComments           = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments`
+	relCommentsExtended   = `http://schemas.microsoft.com/office/2011/relationships/commentsExtended`
+	relCommentsIDs        = `http://schemas.microsoft.com/office/2016/09/relationships/commentsIds`
+	relCommentsExtensible = `http://schemas.microsoft.com/office/2018/08/relationships/commentsExtensible`
+	relPeople             = `http://schemas.microsoft.com/office/2011/relationships/people`
+
+	contentTypeComments = "application/vnd.openxmlformats-officedocument.wordprocessingml.comments+xml"
+)
+
+// commentParts 是随批注一起原样复制的部件: 回复与解决状态 (commentsExtended.xml)、持久 ID (commentsIds.xml)、
+// 修改时间 (commentsExtensible.xml) 与作者 (people.xml), 它们按段落的 w14:paraId 或持久 ID 引用批注
+var commentParts = []string{relCommentsExtended, relCommentsIDs, relCommentsExtensible, relPeople}
+
+// defaultCommentAuthor 是 OutputModeSourceComments 中批注的默认作者
+const defaultCommentAuthor = "Source"
+
+// WithCommentAuthor 设置 OutputModeSourceComments 中批注的作者, 默认为 "Source"
+func (t *Translator) WithCommentAuthor(author string) *Translator {
+	t.commentAuthor = author
+	return t
+}
+
+// sourceComments 收集 OutputModeSourceComments 中的批注, 全部收集后由 write 写入新文档
+type sourceComments struct {
+	author, date string
+	first        int // first 是第一条批注的 ID, 排在原文档已有的批注之后
+	list         []*Comment
+}
+
+// newSourceComments 返回 OutputModeSourceComments 的批注收集器, 批注的 ID 从 first 开始; 其他输出方式返回 nil
+func (t *Translator) newSourceComments(first int) *sourceComments {
+	if t.outputMode != OutputModeSourceComments {
+		return nil
+	}
+	author := t.commentAuthor
+	if author == "" {
+		author = defaultCommentAuthor
+	}
+	return &sourceComments{author: author, date: time.Now().UTC().Format(time.RFC3339), first: first}
+}
+
+// add 添加一条以段落 p 的原文为内容的批注; 原文中的制表符与换行保留, 域与删除的文字不包括在内
+func (c *sourceComments) add(p *Paragraph) *Comment {
+	cm := &Comment{
+		ID:     strconv.Itoa(c.first + len(c.list)),
+		Author: c.author,
+		Date:   c.date,
+		Paragraphs: []*Paragraph{{
+			Children: []interface{}{&Run{Children: textChildren(plainText(p))}},
+		}},
+	}
+	c.list = append(c.list, cm)
+	return cm
+}
+
+// copyComments 将原文档 src 的批注复制到新文档 dst, 返回复制的批注与其段落的翻译单元, 翻译单元填充后批注部件随之更新.
+// 批注的 ID、作者、日期与段落的 w14:paraId 不变, 正文中的批注范围与批注标记随段落保留, 回复关系等部件 (见 commentParts) 原样复制,
+// 因此译文中的批注仍然锚定在原来的文字上, 回复仍然挂在原来的批注下. TranslateDocxInPlace 保留原文档的批注, 不翻译批注的内容
+func (t *Translator) copyComments(src, dst *Docx) (list []*Comment, segs []*segment) {
+	name, ok := partName(src, relComments)
+	if !ok {
+		return nil, nil
+	}
+	data, err := src.readPart(name)
+	if err != nil {
+		t.log().Log(LogLevelWarn, "无法读取原文档的批注", "part", name, "err", err)
+		return nil, nil
+	}
+	// 批注中的超链接与图片按批注部件自己的关系解析
+	from := &Docx{
+		template:     src.template,
+		tmplfs:       src.tmplfs,
+		tmpfslst:     src.tmpfslst,
+		parts:        src.parts,
+		media:        src.media,
+		mediaNameIdx: src.mediaNameIdx,
+	}
+	if rels, err := src.readPart(path.Join(path.Dir(name), "_rels", path.Base(name)+".rels")); err == nil {
+		if err = xml.Unmarshal(rels, &from.docRelation); err != nil {
+			t.log().Log(LogLevelWarn, "无法读取原文档批注的关系", "part", name, "err", err)
+		}
+	}
+	comments := Comments{file: from}
+	if err = xml.Unmarshal(data, &comments); err != nil {
+		t.log().Log(LogLevelWarn, "无法解析原文档的批注", "part", name, "err", err)
+		return nil, nil
+	}
+	for _, cm := range comments.Comments {
+		ncm := &Comment{ID: cm.ID, Author: cm.Author, Date: cm.Date, Initials: cm.Initials, paraIDs: cm.paraIDs}
+		for _, p := range cm.Paragraphs {
+			np, sg := t.commentSegment(p, dst)
+			ncm.Paragraphs = append(ncm.Paragraphs, np)
+			if sg != nil {
+				segs = append(segs, sg)
+			}
+		}
+		list = append(list, ncm)
+	}
+	t.copyCommentParts(src, dst)
+	writeComments(dst, list)
+	if len(segs) > 0 {
+		segs[len(segs)-1].done = func() { writeComments(dst, list) }
+	}
+	return list, segs
+}
+
+// commentSegment 返回批注段落 p 在新文档 dst 中对应的段落与其翻译单元, 不需要翻译时翻译单元为 nil
+func (t *Translator) commentSegment(p *Paragraph, dst *Docx) (*Paragraph, *segment) {
+	if t.revisionPolicy == RevisionPolicyAccept {
+		p = acceptRevisions(p)
+	}
+	text, inlines, before, after := inlineText(p, t.skipHidden)
+	if strings.TrimSpace(inlineTagRe.ReplaceAllString(text, "")) == "" || t.skipText(text) {
+		np := p.copymedia(dst)
+		return &np, nil
+	}
+	np := &Paragraph{
+		Properties: p.Properties,
+		Children:   make([]interface{}, 0),
+		file:       dst,
+	}
+	sg := &segment{src: p, dst: np, text: text, hint: joinHints(t.styleHint(p), breakHintFor(text)), inlines: inlines, before: before, after: after}
+	if len(inlines) > 0 {
+		sg.hint = joinHints(sg.hint, inlineHint)
+	}
+	return np, sg
+}
+
+// copyCommentParts 将原文档 src 中 commentParts 列出的部件原样复制到新文档 dst, 主文档对它们的关系换用 dst 中的新 ID
+func (t *Translator) copyCommentParts(src, dst *Docx) {
+	for _, typ := range commentParts {
+		for _, r := range src.docRelation.Relationship {
+			if r.Type != typ || r.TargetMode == REL_TARGETMODE {
+				continue
+			}
+			name := resolveTarget("word", r.Target)
+			data, err := src.readPart(name)
+			if err != nil {
+				t.log().Log(LogLevelWarn, "无法读取原文档的批注部件", "part", name, "err", err)
+				continue
+			}
+			if ct := contentTypeOf(src, "/"+name); ct != "" && !addContentType(dst, "/"+name, ct) {
+				continue
+			}
+			dst.setPart(name, data)
+			dst.docRelation.Relationship = append(dst.docRelation.Relationship, Relationship{
+				ID:     "rId" + strconv.Itoa(int(atomic.AddUintptr(&dst.rID, 1))),
+				Type:   typ,
+				Target: r.Target,
+			})
+		}
+	}
+}
+
+// nextCommentID 返回排在批注 list 之后的批注 ID
+func nextCommentID(list []*Comment) int {
+	next := 0
+	for _, cm := range list {
+		if id, err := strconv.Atoi(cm.ID); err == nil && id >= next {
+			next = id + 1
+		}
+	}
+	return next
+}
+
+// isCommentRun 判断 Run 中是否只有批注标记
+func isCommentRun(r *Run) bool {
+	for _, child := range r.Children {
+		if !isCommentMark(child) {
+			return false
+		}
+	}
+	return len(r.Children) > 0
+}
+
+// writeComments 将批注 list 写入文档 doc 的批注部件, 第一次写入时登记其关系与类型, 之后再次调用会覆盖之前的内容;
+// 批注中的超链接与图片在 doc 的主文档关系中登记, 写入时一并复制到批注部件的关系. 没有批注时不写入
+func writeComments(doc *Docx, list []*Comment) {
+	if len(list) == 0 {
+		return
+	}
+	c := &Comments{XMLW: XMLNS_W, XMLR: XMLNS_R, XMLWP: XMLNS_WP, XMLM: XMLNS_M, Comments: list}
+	for _, cm := range list {
+		if len(cm.paraIDs) > 0 {
+			c.XMLW14 = XMLNS_W14
+			break
+		}
+	}
+	data, err := xml.Marshal(c)
+	if err != nil {
+		return
+	}
+	name, ok := partName(doc, relComments)
+	if !ok {
+		name = "word/comments.xml"
+		if !addContentType(doc, "/"+name, contentTypeComments) {
+			return
+		}
+		doc.docRelation.Relationship = append(doc.docRelation.Relationship, Relationship{
+			ID:     "rId" + strconv.Itoa(int(atomic.AddUintptr(&doc.rID, 1))),
+			Type:   relComments,
+			Target: "comments.xml",
+		})
+	}
+	doc.setPart(name, append([]byte(xml.Header), data...))
+
+	ids, err := scanXML(data)
+	if err != nil || len(ids) == 0 {
+		return
+	}
+	used := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		used[id] = struct{}{}
+	}
+	rels := Relationships{Xmlns: XMLNS_REL}
+	for _, r := range doc.docRelation.Relationship {
+		if _, ok := used[r.ID]; ok {
+			rels.Relationship = append(rels.Relationship, r)
+		}
+	}
+	if data, err = xml.Marshal(&rels); err == nil {
+		doc.setPart(path.Join(path.Dir(name), "_rels", path.Base(name)+".rels"), append([]byte(xml.Header), data...))
+	}
+}
+
+// anchorComment 将批注锚定到整个译文段落: 批注范围覆盖段落的全部内容, 批注标记放在段落末尾
+func (sg *segment) anchorComment() {
+	id := sg.comment.ID
+	children := make([]interface{}, 0, len(sg.dst.Children)+3)
+	children = append(children, &CommentRangeStart{ID: id})
+	children = append(children, sg.dst.Children...)
+	children = append(children, &CommentRangeEnd{ID: id}, &Run{Children: []interface{}{&CommentReference{ID: id}}})
+	sg.dst.Children = children
+}
@@ -0,0 +1,88 @@
+package docx
+
+import "context"
+
+// WithConcurrency 设置同时请求翻译服务的翻译单元数, n 不大于 1 时逐个翻译 (默认)
+//
+// n 大于 1 时按文档顺序预先并发翻译各翻译单元, 译文的后处理、回译、进度回调与错误处理仍按文档顺序进行, 结果与逐个翻译相同;
+// ErrorPolicyFailFast 下遇到错误后不再开始新的请求. Provider、Cache 与 TranslationMemory 需要可以并发调用, 本包提供的实现均满足.
+func (t *Translator) WithConcurrency(n int) *Translator {
+	t.concurrency = n
+	return t
+}
+
+// prefetched 是预先翻译的一个翻译单元, done 关闭后 text 与 err 有效
+type prefetched struct {
+	done chan struct{}
+	text string
+	err  error
+}
+
+// segmentKey 返回第 i 个翻译单元的去重键与附加的翻译要求
+func (t *Translator) segmentKey(segs []*segment, i int) (key, hint string) {
+	sg := segs[i]
+	hint = joinHints(sg.hint, t.contextHint(segs, i))
+	return sg.text + "\x00" + hint + "\x00" + sg.lang, hint // 样式或上下文不同的相同原文可能需要不同的译法
+}
+
+// sourceTranslator 返回翻译 sg 使用的 Translator, 按语言标记拆分的片段使用其原文语言
+func (t *Translator) sourceTranslator(sg *segment) *Translator {
+	if sg.lang != "" {
+		return t.withSourceLanguage(sg.lang)
+	}
+	return t
+}
+
+// prefetchSegments 在设置了 WithConcurrency 时以多个 goroutine 按文档顺序翻译 segs, 返回按 segmentKey 索引的结果;
+// 沿用旧译文的翻译单元不翻译. ctx 结束后不再开始新的翻译, 尚未开始的结果不会完成
+func (t *Translator) prefetchSegments(ctx context.Context, segs []*segment, targetLanguage string) map[string]*prefetched {
+	if t.concurrency <= 1 {
+		return nil
+	}
+	type task struct {
+		p    *prefetched
+		sg   *segment
+		hint string
+	}
+	pending := make(map[string]*prefetched, len(segs))
+	var tasks []task
+	for i, sg := range segs {
+		if _, ok := t.previousParagraph(sg); ok {
+			continue
+		}
+		key, hint := t.segmentKey(segs, i)
+		if _, ok := pending[key]; ok {
+			continue
+		}
+		p := &prefetched{done: make(chan struct{})}
+		pending[key] = p
+		tasks = append(tasks, task{p: p, sg: sg, hint: hint})
+	}
+	ch := make(chan task)
+	go func() {
+		defer close(ch)
+		for _, tk := range tasks {
+			select {
+			case ch <- tk:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	workers := t.concurrency
+	if workers > len(tasks) {
+		workers = len(tasks)
+	}
+	for n := 0; n < workers; n++ {
+		go func() {
+			for tk := range ch {
+				if ctx.Err() != nil {
+					continue
+				}
+				tk.p.text, tk.p.err = t.sourceTranslator(tk.sg).translateSegment(tk.sg.text, tk.hint, targetLanguage)
+				close(tk.p.done)
+			}
+		}()
+	}
+	return pending
+}
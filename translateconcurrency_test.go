@@ -0,0 +1,56 @@
+package docx
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithConcurrency(t *testing.T) {
+	var (
+		running, peak int32
+		mu            sync.Mutex
+		calls         = make(map[string]int)
+	)
+	tr := NewTranslator("", "").WithConcurrency(3).WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		mu.Lock()
+		calls[text]++
+		mu.Unlock()
+		return strings.ToUpper(text), nil
+	}))
+	texts := []string{"one", "two", "three", "one", "four", "five", "six"}
+	var order []int
+	tr.WithProgress(func(done, _ int, _ SegmentInfo) { order = append(order, done) })
+	newDoc, err := tr.TranslateDocxContext(context.Background(), newTestDoc(texts...), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, text := range texts {
+		if s := newDoc.Document.Body.Items[i].(*Paragraph).String(); s != strings.ToUpper(text) {
+			t.Errorf("paragraph %d: got %q", i, s)
+		}
+	}
+	if peak < 2 || peak > 3 {
+		t.Error("unexpected peak concurrency:", peak)
+	}
+	if calls["one"] != 1 {
+		t.Error("duplicate source translated", calls["one"], "times")
+	}
+	for i, done := range order {
+		if done != i+1 {
+			t.Fatal("progress out of order:", order)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DashscopeAPIURL 是 Dashscope 兼容 OpenAI 的对话接口, Config 未设置 api_url 时使用
+const DashscopeAPIURL = "https://dashscope.aliyuncs.com/compatible-mode/v1/chat/completions"
+
+// ConfigEnvPrefix 是覆盖配置项的环境变量的前缀, 如 DOCX_TRANSLATE_API_KEY 覆盖 api_key
+const ConfigEnvPrefix = "DOCX_TRANSLATE_"
+
+// Config 是从配置文件与环境变量读取的 Translator 设置, 使密钥与模型等设置不必写在程序中; 用 NewTranslator 创建 Translator
+//
+// 配置文件是只有顶层键值的 YAML、TOML 或 JSON, 键名为字段的 config 标签, 如:
+//
+//	# docx-translate.toml
+//	api_key = "sk-..."
+//	model = "qwen-max"
+//	concurrency = 4
+//	glossary = "terms.csv"
+type Config struct {
+	Provider       string        `config:"provider"`        // Provider 是翻译服务: dashscope (默认)、exec:<命令> 或 unix:<socket 路径>, 见 StartProcessProvider 与 DialProvider
+	APIKey         string        `config:"api_key"`         // APIKey 是 Dashscope 的密钥, 为空时取环境变量 DASHSCOPE_API_KEY
+	APIURL         string        `config:"api_url"`         // APIURL 是对话接口的地址, 为空时为 DashscopeAPIURL
+	Model          string        `config:"model"`           // Model 见 WithModel
+	SourceLanguage string        `config:"source_language"` // SourceLanguage 见 WithSourceLanguage
+	Timeout        time.Duration `config:"timeout"`         // Timeout 是每个 HTTP 请求的超时, 如 "30s", 为 0 时不限
+	Concurrency    int           `config:"concurrency"`     // Concurrency 见 WithConcurrency
+	MaxChunkRunes  int           `config:"max_chunk_runes"` // MaxChunkRunes 见 WithMaxChunkRunes
+	CacheDir       string        `config:"cache_dir"`       // CacheDir 是磁盘缓存的目录, 见 WithCacheDir
+	Glossary       string        `config:"glossary"`        // Glossary 是 CSV 术语表的路径, 见 Glossary.Load
+	DNT            string        `config:"dnt"`             // DNT 是不翻译列表的路径, 见 DNTList.Load
+	TM             string        `config:"tm"`              // TM 是 TMX 翻译记忆的路径, 见 TranslationMemory.LoadTMX
+	Tone           string        `config:"tone"`            // Tone 是语体: formal、informal、marketing 或 legal, 见 WithTone
+	ErrorPolicy    string        `config:"error_policy"`    // ErrorPolicy 是 keep-original (默认)、fail-fast 或 collect, 见 WithErrorPolicy
+	OutputMode     string        `config:"output_mode"`     // OutputMode 是 translation (默认)、interleaved、table、comments 或 hidden, 见 WithOutputMode
+	LogLevel       string        `config:"log_level"`       // LogLevel 非空时以 debug、info、warn 或 error 级别将日志写到标准错误
+}
+
+// configTones 等是配置项中的名称与对应的取值
+var (
+	configTones = map[string]Tone{"": ToneDefault, "default": ToneDefault, "formal": ToneFormal,
+		"informal": ToneInformal, "marketing": ToneMarketing, "legal": ToneLegal}
+	configErrorPolicies = map[string]ErrorPolicy{"": ErrorPolicyKeepOriginal, "keep-original": ErrorPolicyKeepOriginal,
+		"fail-fast": ErrorPolicyFailFast, "collect": ErrorPolicyCollect}
+	configOutputModes = map[string]OutputMode{"": OutputModeTranslation, "translation": OutputModeTranslation,
+		"interleaved": OutputModeBilingualInterleaved, "table": OutputModeBilingualTable,
+		"comments": OutputModeSourceComments, "hidden": OutputModeHiddenSource}
+	configLogLevels = map[string]LogLevel{"debug": LogLevelDebug, "info": LogLevelInfo, "warn": LogLevelWarn, "error": LogLevelError}
+)
+
+// LoadConfig 读取配置文件 name (按扩展名 .yaml、.yml、.toml 或 .json 识别格式), 再用环境变量覆盖, 见 LoadEnv;
+// 文件中 glossary、dnt、tm 与 cache_dir 的相对路径相对于配置文件所在的目录
+func LoadConfig(name string) (*Config, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	c, err := ParseConfig(f, strings.TrimPrefix(filepath.Ext(name), "."))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	dir := filepath.Dir(name)
+	for _, p := range []*string{&c.Glossary, &c.DNT, &c.TM, &c.CacheDir} {
+		if *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, *p)
+		}
+	}
+	return c, c.LoadEnv()
+}
+
+// ParseConfig 读取格式为 format (yaml、yml、toml 或 json) 的配置, 不读取环境变量. 未知的键返回错误
+func ParseConfig(r io.Reader, format string) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var values [][2]string
+	switch strings.ToLower(format) {
+	case "yaml", "yml":
+		values, err = parseConfigLines(string(data), ':', unquoteYAML)
+	case "toml":
+		values, err = parseConfigLines(string(data), '=', unquoteTOML)
+	case "json":
+		// 数字按原文保留, 否则大的整数经 float64 变为 1e+07 这样的形式
+		var m map[string]interface{}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err = dec.Decode(&m); err != nil {
+			return nil, err
+		}
+		if dec.More() {
+			return nil, errors.New("config: unexpected data after the JSON object")
+		}
+		for k, v := range m {
+			switch v.(type) {
+			case map[string]interface{}, []interface{}:
+				return nil, fmt.Errorf("config: %s: nested values are not supported", k)
+			case nil:
+				v = ""
+			}
+			values = append(values, [2]string{k, fmt.Sprint(v)})
+		}
+	default:
+		return nil, fmt.Errorf("config: unknown format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	for _, kv := range values {
+		if err = c.Set(kv[0], kv[1]); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// LoadEnv 用 ConfigEnvPrefix 开头的环境变量覆盖配置项, 如 DOCX_TRANSLATE_API_KEY、DOCX_TRANSLATE_MODEL;
+// 此后 APIKey 仍为空时取 DASHSCOPE_API_KEY
+func (c *Config) LoadEnv() error {
+	for _, key := range configKeys() {
+		if v, ok := os.LookupEnv(ConfigEnvPrefix + strings.ToUpper(key)); ok {
+			if err := c.Set(key, v); err != nil {
+				return err
+			}
+		}
+	}
+	if c.APIKey == "" {
+		c.APIKey = os.Getenv("DASHSCOPE_API_KEY")
+	}
+	return nil
+}
+
+// Set 将键为 key 的配置项设为 value, 供命令行参数等覆盖配置; 整数与时长按文本解析, 时长也可以是秒数
+func (c *Config) Set(key, value string) error {
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("config") != key {
+			continue
+		}
+		f := v.Field(i)
+		switch f.Interface().(type) {
+		case string:
+			f.SetString(value)
+		case int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("config: %s: %w", key, err)
+			}
+			f.SetInt(int64(n))
+		case time.Duration:
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				secs, serr := strconv.ParseFloat(value, 64)
+				if serr != nil {
+					return fmt.Errorf("config: %s: %w", key, err)
+				}
+				d = time.Duration(secs * float64(time.Second))
+			}
+			f.SetInt(int64(d))
+		}
+		return nil
+	}
+	return fmt.Errorf("config: unknown key %q", key)
+}
+
+// configKeys 返回所有配置项的键
+func configKeys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, t.NumField())
+	for i := range keys {
+		keys[i] = t.Field(i).Tag.Get("config")
+	}
+	return keys
+}
+
+// NewTranslator 按配置创建 Translator; closer 关闭 exec: 与 unix: 翻译服务的连接, 使用 Translator 之后调用, 不会为 nil
+func (c *Config) NewTranslator() (t *Translator, closer func() error, err error) {
+	closer = func() error { return nil }
+	tone, ok := configTones[c.Tone]
+	if !ok {
+		return nil, nil, fmt.Errorf("config: unknown tone %q", c.Tone)
+	}
+	policy, ok := configErrorPolicies[c.ErrorPolicy]
+	if !ok {
+		return nil, nil, fmt.Errorf("config: unknown error_policy %q", c.ErrorPolicy)
+	}
+	mode, ok := configOutputModes[c.OutputMode]
+	if !ok {
+		return nil, nil, fmt.Errorf("config: unknown output_mode %q", c.OutputMode)
+	}
+	apiURL := c.APIURL
+	if apiURL == "" {
+		apiURL = DashscopeAPIURL
+	}
+	t = NewTranslator(c.APIKey, apiURL)
+	t.Client = &http.Client{Timeout: c.Timeout}
+	if c.LogLevel != "" {
+		level, ok := configLogLevels[c.LogLevel]
+		if !ok {
+			return nil, nil, fmt.Errorf("config: unknown log_level %q", c.LogLevel)
+		}
+		t.WithLogger(NewStdLogger(os.Stderr, level))
+	}
+	t.WithModel(c.Model).WithSourceLanguage(c.SourceLanguage).WithConcurrency(c.Concurrency).WithMaxChunkRunes(c.MaxChunkRunes).
+		WithTone(tone).WithErrorPolicy(policy).WithOutputMode(mode)
+	if c.Glossary != "" {
+		g := NewGlossary()
+		if err = loadConfigFile(c.Glossary, g.Load); err != nil {
+			return nil, nil, err
+		}
+		t.WithGlossary(g)
+	}
+	if c.DNT != "" {
+		l := NewDNTList()
+		if err = loadConfigFile(c.DNT, l.Load); err != nil {
+			return nil, nil, err
+		}
+		t.WithDNT(l)
+	}
+	if c.TM != "" {
+		tm := NewTranslationMemory()
+		if err = loadConfigFile(c.TM, tm.LoadTMX); err != nil {
+			return nil, nil, err
+		}
+		t.WithTM(tm)
+	}
+	if c.CacheDir != "" {
+		cache, err := NewDiskCache(c.CacheDir)
+		if err != nil {
+			return nil, nil, err
+		}
+		t.WithCache(cache)
+	}
+
+	switch kind, arg, _ := strings.Cut(c.Provider, ":"); kind {
+	case "", "dashscope":
+		if c.APIKey == "" {
+			return nil, nil, errors.New("config: missing api_key")
+		}
+	case "exec":
+		args := strings.Fields(arg)
+		if len(args) == 0 {
+			return nil, nil, errors.New("config: exec provider needs a command, e.g. exec:./translate.py")
+		}
+		p, err := StartProcessProvider(args[0], args[1:]...)
+		if err != nil {
+			return nil, nil, err
+		}
+		t.WithProvider(p)
+		closer = p.Close
+	case "unix":
+		p, err := DialProvider("unix", arg)
+		if err != nil {
+			return nil, nil, err
+		}
+		t.WithProvider(p)
+		closer = p.Close
+	default:
+		return nil, nil, fmt.Errorf("config: unknown provider %q", c.Provider)
+	}
+	return t, closer, nil
+}
+
+func loadConfigFile(name string, load func(io.Reader) error) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err = load(f); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	return nil
+}
+
+// parseConfigLines 读取每行一个 key<sep>value 的配置, 空行与 # 开头的注释被忽略; unquote 解析值
+func parseConfigLines(s string, sep byte, unquote func(string) (string, error)) ([][2]string, error) {
+	var values [][2]string
+	for n, line := range strings.Split(strings.TrimPrefix(s, "\ufeff"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed[0] == '#' || trimmed == "---" {
+			continue
+		}
+		i := strings.IndexByte(trimmed, sep)
+		if i <= 0 || line[0] == ' ' || line[0] == '\t' || trimmed[0] == '[' {
+			return nil, fmt.Errorf("config: line %d: only top-level key%cvalue pairs are supported", n+1, sep)
+		}
+		key := strings.Trim(strings.TrimSpace(trimmed[:i]), `"'`)
+		value, err := unquote(strings.TrimSpace(trimmed[i+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("config: line %d: %w", n+1, err)
+		}
+		values = append(values, [2]string{key, value})
+	}
+	return values, nil
+}
+
+// unquoteTOML 解析 TOML 的值: 基本字符串、字面量字符串或整数等裸值, 其后可以有注释
+func unquoteTOML(v string) (string, error) {
+	switch {
+	case strings.HasPrefix(v, `"`):
+		end := closingQuote(v, '"', true)
+		if end < 0 {
+			return "", errors.New("unterminated string")
+		}
+		s, err := strconv.Unquote(v[:end+1])
+		return s, checkConfigTail(v[end+1:], err)
+	case strings.HasPrefix(v, "'"):
+		end := closingQuote(v, '\'', false)
+		if end < 0 {
+			return "", errors.New("unterminated string")
+		}
+		return v[1:end], checkConfigTail(v[end+1:], nil)
+	}
+	if i := strings.IndexByte(v, '#'); i >= 0 {
+		v = v[:i]
+	}
+	return strings.TrimSpace(v), nil
+}
+
+// unquoteYAML 解析 YAML 的标量: 双引号、单引号 (其中连续两个单引号表示一个单引号) 或普通的值, 普通的值中 " #" 之后是注释
+func unquoteYAML(v string) (string, error) {
+	switch {
+	case strings.HasPrefix(v, `"`):
+		end := closingQuote(v, '"', true)
+		if end < 0 {
+			return "", errors.New("unterminated string")
+		}
+		s, err := strconv.Unquote(v[:end+1])
+		return s, checkConfigTail(v[end+1:], err)
+	case strings.HasPrefix(v, "'"):
+		for i := 1; i < len(v); i++ {
+			if v[i] != '\'' {
+				continue
+			}
+			if i+1 < len(v) && v[i+1] == '\'' {
+				i++
+				continue
+			}
+			return strings.ReplaceAll(v[1:i], "''", "'"), checkConfigTail(v[i+1:], nil)
+		}
+		return "", errors.New("unterminated string")
+	}
+	if i := strings.Index(v, " #"); i >= 0 {
+		v = v[:i]
+	}
+	v = strings.TrimSpace(v)
+	if v == "~" || v == "null" {
+		v = ""
+	}
+	return v, nil
+}
+
+// closingQuote 返回 v 中与开头的引号配对的引号的位置, escapes 为 true 时跳过 \ 转义的字符
+func closingQuote(v string, quote byte, escapes bool) int {
+	for i := 1; i < len(v); i++ {
+		switch {
+		case escapes && v[i] == '\\':
+			i++
+		case v[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// checkConfigTail 检查字符串之后只有空白或注释
+func checkConfigTail(tail string, err error) error {
+	if err != nil {
+		return err
+	}
+	if tail = strings.TrimSpace(tail); tail != "" && tail[0] != '#' {
+		return fmt.Errorf("unexpected %q after string", tail)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package docx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConfig(t *testing.T) {
+	want := Config{APIKey: "sk-1 #2", Model: "qwen-max", Concurrency: 4, Timeout: 30 * time.Second, Tone: "formal", Glossary: "it's.csv"}
+	for format, text := range map[string]string{
+		"toml": "# settings\napi_key = \"sk-1 #2\" # comment\nmodel = 'qwen-max'\nconcurrency = 4\ntimeout = \"30s\"\ntone = formal\nglossary = \"it's.csv\"\n",
+		"yaml": "---\napi_key: \"sk-1 #2\"\nmodel: qwen-max # comment\nconcurrency: 4\ntimeout: 30\ntone: 'formal'\nglossary: 'it''s.csv'\n",
+		"json": `{"api_key": "sk-1 #2", "model": "qwen-max", "concurrency": 4, "timeout": "30s", "tone": "formal", "glossary": "it's.csv"}`,
+	} {
+		c, err := ParseConfig(strings.NewReader(text), format)
+		if err != nil {
+			t.Fatal(format, err)
+		}
+		if *c != want {
+			t.Errorf("%s: got %+v", format, *c)
+		}
+	}
+	for format, text := range map[string]string{
+		"toml": "[translator]\nmodel = \"x\"\n",
+		"yaml": "translator:\n  model: x\n",
+		"json": `{"modle": "x"}`,
+	} {
+		if _, err := ParseConfig(strings.NewReader(text), format); err == nil {
+			t.Errorf("%s: expected an error for %q", format, text)
+		}
+	}
+
+	// JSON 中的数字按原文解析, 大的整数不会变为指数形式
+	c, err := ParseConfig(strings.NewReader(`{"concurrency": 10000000, "timeout": 1.5, "model": 123456789}`), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Concurrency != 10000000 || c.Timeout != 1500*time.Millisecond || c.Model != "123456789" {
+		t.Fatalf("unexpected config: %+v", *c)
+	}
+	if _, err = ParseConfig(strings.NewReader(`{"model": "x"} {}`), "json"); err == nil {
+		t.Error("expected an error for trailing data")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "translate.yaml")
+	if err := os.WriteFile(name, []byte("api_key: from-file\nmodel: qwen-plus\ndnt: dnt.txt\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dnt.txt"), []byte("Go\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCX_TRANSLATE_API_KEY", "from-env")
+	t.Setenv("DOCX_TRANSLATE_ERROR_POLICY", "collect")
+	c, err := LoadConfig(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.APIKey != "from-env" || c.Model != "qwen-plus" || c.DNT != filepath.Join(dir, "dnt.txt") {
+		t.Fatalf("unexpected config: %+v", *c)
+	}
+	tr, closer, err := c.NewTranslator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closer()
+	if tr.APIURL != DashscopeAPIURL || tr.errorPolicy != ErrorPolicyCollect || tr.dnt == nil {
+		t.Fatalf("unexpected translator: %+v", tr)
+	}
+	c.Tone = "casual"
+	if _, _, err = c.NewTranslator(); err == nil {
+		t.Fatal("expected an error for an unknown tone")
+	}
+}
@@ -0,0 +1,82 @@
+package docx
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ContextOptions 设置随每个段落一起发送给翻译服务的上下文, 使代词、术语与语体在段落间保持一致
+type ContextOptions struct {
+	// Before 与 After 是附带的前后翻译单元数
+	Before, After int
+	// MaxRunes 是每个相邻翻译单元保留的最大字符数, 超出部分被截断; 默认为 200
+	MaxRunes int
+	// Summary 是文档的摘要, 非空时附带在每个段落的上下文中
+	Summary string
+}
+
+// defaultContextRunes 是 ContextOptions.MaxRunes 的默认值
+const defaultContextRunes = 200
+
+// WithDocumentContext 设置随每个段落一起发送的上下文, 默认不附带上下文
+//
+// 上下文与样式提示一样附加在系统提示词之后, 或交给实现了 HintedProvider 的 Provider,
+// 并作为缓存键的一部分, 因此相同的原文在不同的上下文中会分别翻译.
+func (t *Translator) WithDocumentContext(opts ContextOptions) *Translator {
+	t.docContext = opts
+	return t
+}
+
+// contextHint 返回第 i 个翻译单元的上下文提示, 没有设置上下文时返回空串
+func (t *Translator) contextHint(segs []*segment, i int) string {
+	opts := t.docContext
+	if opts.Before <= 0 && opts.After <= 0 && opts.Summary == "" {
+		return ""
+	}
+	limit := opts.MaxRunes
+	if limit <= 0 {
+		limit = defaultContextRunes
+	}
+	var sb strings.Builder
+	sb.WriteString("以下是这段文字在文档中的上下文, 仅用于保持代词、术语与语体一致, 不要翻译或输出上下文:")
+	if opts.Summary != "" {
+		sb.WriteString("\n[文档摘要] ")
+		sb.WriteString(opts.Summary)
+	}
+	for k := maxInt(0, i-opts.Before); k < i; k++ {
+		sb.WriteString("\n[上文] ")
+		sb.WriteString(truncateRunes(segs[k].text, limit))
+	}
+	for k := i + 1; k <= i+opts.After && k < len(segs); k++ {
+		sb.WriteString("\n[下文] ")
+		sb.WriteString(truncateRunes(segs[k].text, limit))
+	}
+	return sb.String()
+}
+
+// joinHints 用换行连接非空的翻译要求
+func joinHints(hints ...string) string {
+	var nonEmpty []string
+	for _, h := range hints {
+		if h != "" {
+			nonEmpty = append(nonEmpty, h)
+		}
+	}
+	return strings.Join(nonEmpty, "\n")
+}
+
+// truncateRunes 将 s 截断为最多 n 个字符, 截断时以 … 结尾
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n]) + "…"
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,492 @@
+package docx
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	// ErrPasswordRequired 文档已加密, 需要用 WithPassword 或 Decrypt 提供密码
+	ErrPasswordRequired = errors.New("document is encrypted")
+	// ErrWrongPassword 密码错误
+	ErrWrongPassword = errors.New("wrong password")
+	// ErrUnsupportedEncryption 文档使用了 ECMA-376 agile 加密以外的加密方式 (如 standard 加密或 RC4)
+	ErrUnsupportedEncryption = errors.New("unsupported encryption")
+	// ErrIntegrity 加密文档的完整性校验失败, 文档已损坏或被篡改
+	ErrIntegrity = errors.New("encrypted document failed integrity check")
+	// ErrInvalidEncryption 加密参数中的长度或迭代次数超出范围, 文档已损坏或是恶意构造的
+	ErrInvalidEncryption = errors.New("invalid encryption parameters")
+)
+
+// agile 加密中派生各个密钥使用的块密钥
+var (
+	blockKeyVerifierInput = []byte{0xfe, 0xa7, 0xd2, 0x76, 0x3b, 0x4b, 0x9e, 0x79}
+	blockKeyVerifierValue = []byte{0xd7, 0xaa, 0x0f, 0x6d, 0x30, 0x61, 0x34, 0x4e}
+	blockKeyEncryptedKey  = []byte{0x14, 0x6e, 0x0b, 0xe7, 0xab, 0xac, 0xd0, 0xd6}
+	blockKeyHMACKey       = []byte{0x5f, 0xb2, 0xad, 0x01, 0x0c, 0xb9, 0xe1, 0xf6}
+	blockKeyHMACValue     = []byte{0xa0, 0x67, 0x7f, 0x02, 0xb2, 0x2c, 0x84, 0x33}
+)
+
+const (
+	streamEncryptionInfo   = "EncryptionInfo"
+	streamEncryptedPackage = "EncryptedPackage"
+
+	uriPasswordKeyEncryptor = "http://schemas.microsoft.com/office/2006/keyEncryptor/password"
+
+	// encryptSegmentSize 是加密包分段加密的长度
+	encryptSegmentSize = 4096
+	// encryptSpinCount 是 Encrypt 派生密钥时哈希的迭代次数, 与 Word 相同
+	encryptSpinCount = 100000
+	// maxSpinCount 是 Decrypt 接受的最大迭代次数, 避免一个文档长时间占用 CPU
+	maxSpinCount = 10000000
+)
+
+// IsEncrypted 判断 data 是否为加密的 Office 文档 (含有 EncryptedPackage 的复合文档)
+func IsEncrypted(data []byte) bool {
+	if !isCFB(data) {
+		return false
+	}
+	r, err := readCFB(data)
+	if err != nil {
+		return false
+	}
+	_, ok := r.find(r.entries[0].child, streamEncryptedPackage, 0)
+	return ok
+}
+
+// WithPassword 设置 TranslateFile 打开加密文档 (ECMA-376 agile 加密, 即 Word 2010 及以后的“用密码进行加密”) 使用的密码;
+// 未设置密码时加密文档返回 ErrPasswordRequired, 密码错误时返回 ErrWrongPassword
+func (t *Translator) WithPassword(password string) *Translator {
+	t.password = password
+	return t
+}
+
+// WithOutputPassword 设置 TranslateFile 用密码 password 加密译文 (docx 格式), 为空时不加密 (默认);
+// 原文是否加密不影响译文, 需要保持加密时两个密码都要设置
+func (t *Translator) WithOutputPassword(password string) *Translator {
+	t.outputPassword = password
+	return t
+}
+
+// agileKeyData 是加密参数中的 keyData 与 p:encryptedKey 共有的属性
+type agileKeyData struct {
+	SaltSize        int    `xml:"saltSize,attr"`
+	BlockSize       int    `xml:"blockSize,attr"`
+	KeyBits         int    `xml:"keyBits,attr"`
+	HashSize        int    `xml:"hashSize,attr"`
+	CipherAlgorithm string `xml:"cipherAlgorithm,attr"`
+	CipherChaining  string `xml:"cipherChaining,attr"`
+	HashAlgorithm   string `xml:"hashAlgorithm,attr"`
+	SaltValue       string `xml:"saltValue,attr"`
+}
+
+// agileEncryption 是 EncryptionInfo 流中的 XML 加密参数
+type agileEncryption struct {
+	XMLName       xml.Name     `xml:"encryption"`
+	KeyData       agileKeyData `xml:"keyData"`
+	DataIntegrity *struct {
+		EncryptedHmacKey   string `xml:"encryptedHmacKey,attr"`
+		EncryptedHmacValue string `xml:"encryptedHmacValue,attr"`
+	} `xml:"dataIntegrity"`
+	KeyEncryptors []struct {
+		URI          string `xml:"uri,attr"`
+		EncryptedKey *struct {
+			agileKeyData
+			SpinCount                  int    `xml:"spinCount,attr"`
+			EncryptedVerifierHashInput string `xml:"encryptedVerifierHashInput,attr"`
+			EncryptedVerifierHashValue string `xml:"encryptedVerifierHashValue,attr"`
+			EncryptedKeyValue          string `xml:"encryptedKeyValue,attr"`
+		} `xml:"encryptedKey"`
+	} `xml:"keyEncryptors>keyEncryptor"`
+}
+
+// newAgileHash 返回加密参数中的哈希算法
+func newAgileHash(name string) (func() hash.Hash, error) {
+	switch strings.ToUpper(strings.ReplaceAll(name, "-", "")) {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA384":
+		return sha512.New384, nil
+	case "SHA512":
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("%w: hash %s", ErrUnsupportedEncryption, name)
+}
+
+// checkAgileCipher 检查加密参数中的算法是否为 AES-CBC
+func checkAgileCipher(k *agileKeyData) error {
+	if !strings.EqualFold(k.CipherAlgorithm, "AES") || !strings.EqualFold(k.CipherChaining, "ChainingModeCBC") {
+		return fmt.Errorf("%w: cipher %s %s", ErrUnsupportedEncryption, k.CipherAlgorithm, k.CipherChaining)
+	}
+	if k.BlockSize != aes.BlockSize || k.KeyBits != 128 && k.KeyBits != 192 && k.KeyBits != 256 {
+		return fmt.Errorf("%w: AES block %d key %d", ErrUnsupportedEncryption, k.BlockSize, k.KeyBits)
+	}
+	// 规范中盐最长 65536 字节, 哈希最长为 SHA-512 的 64 字节
+	if k.SaltSize < 1 || k.SaltSize > 65536 || k.HashSize < 1 || k.HashSize > sha512.Size {
+		return fmt.Errorf("%w: salt size %d hash size %d", ErrInvalidEncryption, k.SaltSize, k.HashSize)
+	}
+	return nil
+}
+
+// hashOf 返回 parts 依次拼接后的哈希
+func hashOf(h func() hash.Hash, parts ...[]byte) []byte {
+	d := h()
+	for _, p := range parts {
+		d.Write(p)
+	}
+	return d.Sum(nil)
+}
+
+// fitBytes 将 b 截断或用 0x36 补足为 n 个字节
+func fitBytes(b []byte, n int) []byte {
+	if len(b) >= n {
+		return b[:n]
+	}
+	return append(append(make([]byte, 0, n), b...), bytes.Repeat([]byte{0x36}, n-len(b))...)
+}
+
+// passwordHash 返回密码 password 迭代 spinCount 次后的哈希
+func passwordHash(h func() hash.Hash, password string, salt []byte, spinCount int) []byte {
+	u := utf16.Encode([]rune(password))
+	pw := make([]byte, 2*len(u))
+	for i, c := range u {
+		binary.LittleEndian.PutUint16(pw[2*i:], c)
+	}
+	sum := hashOf(h, salt, pw)
+	var it [4]byte
+	for i := 0; i < spinCount; i++ {
+		binary.LittleEndian.PutUint32(it[:], uint32(i))
+		sum = hashOf(h, it[:], sum)
+	}
+	return sum
+}
+
+// aesCBC 用密钥 key 与初始向量 iv 加密或解密 data, data 的长度需要是块大小的整数倍
+func aesCBC(key, iv, data []byte, encrypt bool) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data)%aes.BlockSize != 0 {
+		return nil, fmt.Errorf("%w: data is not a multiple of the block size", ErrIntegrity)
+	}
+	out := make([]byte, len(data))
+	if encrypt {
+		cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, data)
+	} else {
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(out, data)
+	}
+	return out, nil
+}
+
+// cryptPackage 分段加密或解密包 data, 第 i 段的初始向量由 keyData 的盐与 i 派生
+func cryptPackage(h func() hash.Hash, key, salt, data []byte, encrypt bool) ([]byte, error) {
+	out := make([]byte, 0, len(data)+aes.BlockSize)
+	var idx [4]byte
+	for i := 0; len(data) > 0; i++ {
+		n := encryptSegmentSize
+		if n > len(data) {
+			n = len(data)
+		}
+		seg := data[:n]
+		data = data[n:]
+		if pad := len(seg) % aes.BlockSize; pad != 0 {
+			seg = append(append([]byte(nil), seg...), make([]byte, aes.BlockSize-pad)...)
+		}
+		binary.LittleEndian.PutUint32(idx[:], uint32(i))
+		crypted, err := aesCBC(key, fitBytes(hashOf(h, salt, idx[:]), aes.BlockSize), seg, encrypt)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, crypted...)
+	}
+	return out, nil
+}
+
+// Decrypt 用密码 password 解密 ECMA-376 agile 加密的 Office 文档 data, 返回其中的 zip 包 (如 docx),
+// 解密后可以用 Parse 打开; data 未加密时返回 ErrUnsupportedEncryption
+func Decrypt(data []byte, password string) ([]byte, error) {
+	if !isCFB(data) {
+		return nil, fmt.Errorf("%w: not a compound file", ErrUnsupportedEncryption)
+	}
+	r, err := readCFB(data)
+	if err != nil {
+		return nil, err
+	}
+	info, ok, err := r.stream(streamEncryptionInfo)
+	if err != nil {
+		return nil, err
+	}
+	pkg, ok2, err := r.stream(streamEncryptedPackage)
+	if err != nil {
+		return nil, err
+	}
+	if !ok || !ok2 || len(info) < 8 || len(pkg) < 8 {
+		return nil, fmt.Errorf("%w: missing encryption streams", ErrUnsupportedEncryption)
+	}
+	if major, minor := binary.LittleEndian.Uint16(info), binary.LittleEndian.Uint16(info[2:]); major != 4 || minor != 4 {
+		return nil, fmt.Errorf("%w: version %d.%d", ErrUnsupportedEncryption, major, minor)
+	}
+	var enc agileEncryption
+	if err := xml.Unmarshal(info[8:], &enc); err != nil {
+		return nil, err
+	}
+	if err := checkAgileCipher(&enc.KeyData); err != nil {
+		return nil, err
+	}
+	dataHash, err := newAgileHash(enc.KeyData.HashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	dataSalt, err := base64.StdEncoding.DecodeString(enc.KeyData.SaltValue)
+	if err != nil {
+		return nil, err
+	}
+
+	var key []byte
+	for _, ke := range enc.KeyEncryptors {
+		if ke.URI != uriPasswordKeyEncryptor || ke.EncryptedKey == nil {
+			continue
+		}
+		ek := ke.EncryptedKey
+		if err := checkAgileCipher(&ek.agileKeyData); err != nil {
+			return nil, err
+		}
+		h, err := newAgileHash(ek.HashAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		salt, err := base64.StdEncoding.DecodeString(ek.SaltValue)
+		if err != nil {
+			return nil, err
+		}
+		var fields [3][]byte
+		for i, s := range [...]string{ek.EncryptedVerifierHashInput, ek.EncryptedVerifierHashValue, ek.EncryptedKeyValue} {
+			if fields[i], err = base64.StdEncoding.DecodeString(s); err != nil {
+				return nil, err
+			}
+		}
+		if ek.SpinCount < 0 || ek.SpinCount > maxSpinCount {
+			return nil, fmt.Errorf("%w: spin count %d", ErrInvalidEncryption, ek.SpinCount)
+		}
+		pwHash := passwordHash(h, password, salt, ek.SpinCount)
+		iv := fitBytes(salt, ek.BlockSize)
+		derive := func(blockKey []byte) []byte {
+			return fitBytes(hashOf(h, pwHash, blockKey), ek.KeyBits/8)
+		}
+		input, err := aesCBC(derive(blockKeyVerifierInput), iv, fields[0], false)
+		if err != nil {
+			return nil, err
+		}
+		value, err := aesCBC(derive(blockKeyVerifierValue), iv, fields[1], false)
+		if err != nil {
+			return nil, err
+		}
+		if len(input) < ek.SaltSize || len(value) < ek.HashSize ||
+			!hmac.Equal(hashOf(h, input[:ek.SaltSize]), value[:ek.HashSize]) {
+			return nil, ErrWrongPassword
+		}
+		if key, err = aesCBC(derive(blockKeyEncryptedKey), iv, fields[2], false); err != nil {
+			return nil, err
+		}
+		if len(key) < enc.KeyData.KeyBits/8 {
+			return nil, ErrIntegrity
+		}
+		key = key[:enc.KeyData.KeyBits/8]
+		break
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%w: no password key encryptor", ErrUnsupportedEncryption)
+	}
+
+	if di := enc.DataIntegrity; di != nil {
+		hmacKey, err1 := base64.StdEncoding.DecodeString(di.EncryptedHmacKey)
+		hmacValue, err2 := base64.StdEncoding.DecodeString(di.EncryptedHmacValue)
+		if err1 != nil || err2 != nil {
+			return nil, ErrIntegrity
+		}
+		ivOf := func(blockKey []byte) []byte {
+			return fitBytes(hashOf(dataHash, dataSalt, blockKey), enc.KeyData.BlockSize)
+		}
+		if hmacKey, err = aesCBC(key, ivOf(blockKeyHMACKey), hmacKey, false); err != nil {
+			return nil, err
+		}
+		if hmacValue, err = aesCBC(key, ivOf(blockKeyHMACValue), hmacValue, false); err != nil {
+			return nil, err
+		}
+		size := enc.KeyData.HashSize
+		if len(hmacKey) < size || len(hmacValue) < size {
+			return nil, ErrIntegrity
+		}
+		mac := hmac.New(dataHash, hmacKey[:size])
+		mac.Write(pkg)
+		if !hmac.Equal(mac.Sum(nil), hmacValue[:size]) {
+			return nil, ErrIntegrity
+		}
+	}
+
+	size := binary.LittleEndian.Uint64(pkg)
+	plain, err := cryptPackage(dataHash, key, dataSalt, pkg[8:], false)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(plain)) < size {
+		return nil, ErrIntegrity
+	}
+	return plain[:size], nil
+}
+
+// Encrypt 用密码 password 以 ECMA-376 agile 加密 (AES-256, SHA-512) 加密 zip 包 data (如 docx 文件),
+// 返回可以在 Word 中用密码打开的文件
+func Encrypt(data []byte, password string) ([]byte, error) {
+	const keyBits, hashSize, saltSize = 256, sha512.Size, 16
+	// 随机生成包的密钥、包的盐、密码的盐、校验值与 HMAC 密钥
+	var random [5][]byte
+	for i, n := range [...]int{keyBits / 8, saltSize, saltSize, saltSize, hashSize} {
+		random[i] = make([]byte, n)
+		if _, err := rand.Read(random[i]); err != nil {
+			return nil, err
+		}
+	}
+	key, dataSalt, pwSalt, verifier, hmacKey := random[0], random[1], random[2], random[3], random[4]
+	h := sha512.New
+
+	encrypted, err := cryptPackage(h, key, dataSalt, data, true)
+	if err != nil {
+		return nil, err
+	}
+	pkg := make([]byte, 8, 8+len(encrypted))
+	binary.LittleEndian.PutUint64(pkg, uint64(len(data)))
+	pkg = append(pkg, encrypted...)
+
+	mac := hmac.New(h, hmacKey)
+	mac.Write(pkg)
+	ivOf := func(blockKey []byte) []byte {
+		return fitBytes(hashOf(h, dataSalt, blockKey), aes.BlockSize)
+	}
+	encHMACKey, err := aesCBC(key, ivOf(blockKeyHMACKey), hmacKey, true)
+	if err != nil {
+		return nil, err
+	}
+	encHMACValue, err := aesCBC(key, ivOf(blockKeyHMACValue), mac.Sum(nil), true)
+	if err != nil {
+		return nil, err
+	}
+
+	pwHash := passwordHash(h, password, pwSalt, encryptSpinCount)
+	derive := func(blockKey []byte) []byte {
+		return fitBytes(hashOf(h, pwHash, blockKey), keyBits/8)
+	}
+	var fields [3][]byte
+	for i, f := range [...][2][]byte{
+		{blockKeyVerifierInput, verifier},
+		{blockKeyVerifierValue, hashOf(h, verifier)},
+		{blockKeyEncryptedKey, key},
+	} {
+		if fields[i], err = aesCBC(derive(f[0]), pwSalt, f[1], true); err != nil {
+			return nil, err
+		}
+	}
+
+	b64 := base64.StdEncoding.EncodeToString
+	keyAttrs := func(salt []byte) string {
+		return fmt.Sprintf(`saltSize="%d" blockSize="%d" keyBits="%d" hashSize="%d" cipherAlgorithm="AES" cipherChaining="ChainingModeCBC" hashAlgorithm="SHA512" saltValue="%s"`,
+			saltSize, aes.BlockSize, keyBits, hashSize, b64(salt))
+	}
+	var info bytes.Buffer
+	info.Write([]byte{4, 0, 4, 0, 0x40, 0, 0, 0})
+	info.WriteString(xml.Header[:len(xml.Header)-1] + "\r\n")
+	fmt.Fprintf(&info, `<encryption xmlns="http://schemas.microsoft.com/office/2006/encryption" xmlns:p="%s">`+
+		`<keyData %s/><dataIntegrity encryptedHmacKey="%s" encryptedHmacValue="%s"/>`+
+		`<keyEncryptors><keyEncryptor uri="%s"><p:encryptedKey spinCount="%d" %s encryptedVerifierHashInput="%s" encryptedVerifierHashValue="%s" encryptedKeyValue="%s"/>`+
+		`</keyEncryptor></keyEncryptors></encryption>`,
+		uriPasswordKeyEncryptor, keyAttrs(dataSalt), b64(encHMACKey), b64(encHMACValue),
+		uriPasswordKeyEncryptor, encryptSpinCount, keyAttrs(pwSalt), b64(fields[0]), b64(fields[1]), b64(fields[2]))
+
+	return writeCFB([]*cfbNode{
+		{name: streamEncryptionInfo, data: info.Bytes()},
+		{name: streamEncryptedPackage, data: pkg},
+		{name: "\x06DataSpaces", children: dataSpaces()},
+	}), nil
+}
+
+// dataSpaces 返回加密文档中声明加密方式的 \x06DataSpaces 存储的内容
+func dataSpaces() []*cfbNode {
+	var buf bytes.Buffer
+	u32 := func(v uint32) {
+		_ = binary.Write(&buf, binary.LittleEndian, v)
+	}
+	str := func(s string) { // 以长度开头、补足为 4 字节整数倍的 UTF-16 字符串
+		u := utf16.Encode([]rune(s))
+		u32(uint32(2 * len(u)))
+		_ = binary.Write(&buf, binary.LittleEndian, u)
+		if len(u)%2 != 0 {
+			buf.Write([]byte{0, 0})
+		}
+	}
+	take := func() []byte {
+		b := append([]byte(nil), buf.Bytes()...)
+		buf.Reset()
+		return b
+	}
+	versions := func(n int) {
+		for i := 0; i < n; i++ {
+			u32(1) // 主版本 1, 次版本 0
+		}
+	}
+
+	str("Microsoft.Container.DataSpaces")
+	versions(3)
+	version := take()
+
+	u32(1) // 一个引用: 流 EncryptedPackage
+	u32(0)
+	str(streamEncryptedPackage)
+	str("StrongEncryptionDataSpace")
+	entry := take()
+	u32(8)
+	u32(1)
+	u32(uint32(4 + len(entry)))
+	buf.Write(entry)
+	dataSpaceMap := take()
+
+	u32(8)
+	u32(1)
+	str("StrongEncryptionTransform")
+	dataSpaceInfo := take()
+
+	const transformID = "{FF9A3F03-56EF-4613-BDD5-5A41C1D07246}"
+	u32(uint32(4 + 4 + 4 + 2*len(transformID)))
+	u32(1)
+	str(transformID)
+	str("Microsoft.Container.EncryptionTransform")
+	versions(3)
+	u32(0) // 加密名称为空
+	u32(0)
+	u32(0)
+	u32(4)
+	primary := take()
+
+	return []*cfbNode{
+		{name: "Version", data: version},
+		{name: "DataSpaceMap", data: dataSpaceMap},
+		{name: "DataSpaceInfo", children: []*cfbNode{{name: "StrongEncryptionDataSpace", data: dataSpaceInfo}}},
+		{name: "TransformInfo", children: []*cfbNode{{name: "StrongEncryptionTransform", children: []*cfbNode{{name: "\x06Primary", data: primary}}}}},
+	}
+}
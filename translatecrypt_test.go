@@ -0,0 +1,100 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789abcdef-"), 1000)
+	encrypted, err := Encrypt(data, "密码")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(encrypted) || IsEncrypted(data) {
+		t.Fatal("unexpected IsEncrypted result")
+	}
+	plain, err := Decrypt(encrypted, "密码")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(plain, data) {
+		t.Fatal("decrypted data differs from the original")
+	}
+	if _, err = Decrypt(encrypted, "wrong"); !errors.Is(err, ErrWrongPassword) {
+		t.Fatal("expected ErrWrongPassword, got", err)
+	}
+	if _, err = Decrypt(data, "密码"); !errors.Is(err, ErrUnsupportedEncryption) {
+		t.Fatal("expected ErrUnsupportedEncryption, got", err)
+	}
+}
+
+func TestDecryptInvalidParameters(t *testing.T) {
+	encrypted, err := Encrypt([]byte("PK\x03\x04"), "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := readCFB(encrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, _, _ := r.stream(streamEncryptionInfo)
+	pkg, _, _ := r.stream(streamEncryptedPackage)
+	for _, c := range []struct{ attr, value string }{
+		{"spinCount", "2000000000"},
+		{"spinCount", "-1"},
+		{"saltSize", "-1"},
+		{"saltSize", "99999999"},
+		{"hashSize", "-1"},
+		{"hashSize", "1000"},
+	} {
+		tampered := regexp.MustCompile(c.attr+`="\d+"`).ReplaceAll(info, []byte(c.attr+`="`+c.value+`"`))
+		data := writeCFB([]*cfbNode{
+			{name: streamEncryptionInfo, data: tampered},
+			{name: streamEncryptedPackage, data: pkg},
+		})
+		if _, err := Decrypt(data, "pw"); !errors.Is(err, ErrInvalidEncryption) {
+			t.Errorf("%s=%s: expected ErrInvalidEncryption, got %v", c.attr, c.value, err)
+		}
+	}
+}
+
+func TestTranslateFileEncrypted(t *testing.T) {
+	var in bytes.Buffer
+	if _, err := newTestDoc("hello").WriteTo(&in); err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := Encrypt(in.Bytes(), "in")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	err = tr.TranslateFile(context.Background(), bytes.NewReader(encrypted), io.Discard, TranslateFileOptions{})
+	if !errors.Is(err, ErrPasswordRequired) {
+		t.Fatal("expected ErrPasswordRequired, got", err)
+	}
+
+	var out bytes.Buffer
+	tr.WithPassword("in").WithOutputPassword("out")
+	if err = tr.TranslateFile(context.Background(), bytes.NewReader(encrypted), &out, TranslateFileOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	plain, err := Decrypt(out.Bytes(), "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(plain), int64(len(plain)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := doc.Document.Body.Items[0].(*Paragraph).String(); s != "HELLO" {
+		t.Fatal("unexpected translation:", s)
+	}
+}
@@ -0,0 +1,154 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	relCustomXML = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXml`
+	relSettings  = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/settings`
+
+	contentTypeSettings = "application/vnd.openxmlformats-officedocument.wordprocessingml.settings+xml"
+
+	// customXMLFolder 是自定义 XML 部件所在的目录
+	customXMLFolder = "customXml/"
+)
+
+// copyCustomXML 将原文档 src 的自定义 XML 部件 (customXml/ 下的数据与其属性) 原样复制到新文档 dst,
+// 文件名不变, 主文档对它们的关系换用 dst 中的新 ID, 使绑定到这些数据的内容控件 (见 SDTDataBinding) 在译文中仍然有效;
+// 同时复制文档变量, 见 copyDocVars. TranslateDocxInPlace 本来就保留原文档的所有部件.
+func (t *Translator) copyCustomXML(src, dst *Docx) {
+	names := packageNames(src)
+	copied := make(map[string]bool)
+	for _, name := range names {
+		if !strings.HasPrefix(name, customXMLFolder) || strings.HasSuffix(name, "/") {
+			continue
+		}
+		data, err := src.readPart(name)
+		if err != nil {
+			t.log().Log(LogLevelWarn, "无法读取原文档的自定义 XML 部件", "part", name, "err", err)
+			continue
+		}
+		if path.Ext(name) != ".rels" {
+			if ct := contentTypeOf(src, "/"+name); ct != "" && !addContentType(dst, "/"+name, ct) {
+				continue
+			}
+		}
+		dst.setPart(name, data)
+		copied[name] = true
+	}
+	for _, r := range src.docRelation.Relationship {
+		if r.Type != relCustomXML || r.TargetMode == REL_TARGETMODE || !copied[resolveTarget("word", r.Target)] {
+			continue
+		}
+		dst.docRelation.Relationship = append(dst.docRelation.Relationship, Relationship{
+			ID:     "rId" + strconv.Itoa(int(atomic.AddUintptr(&dst.rID, 1))),
+			Type:   relCustomXML,
+			Target: r.Target,
+		})
+	}
+	t.copyDocVars(src, dst)
+}
+
+// packageNames 返回文档 doc 的包中主文档、其关系与媒体以外的文件名, 按名称排序
+func packageNames(doc *Docx) []string {
+	seen := make(map[string]struct{}, len(doc.tmpfslst)+len(doc.parts))
+	names := make([]string, 0, len(doc.tmpfslst)+len(doc.parts))
+	for _, name := range doc.tmpfslst {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	for name := range doc.parts {
+		if _, ok := seen[name]; !ok {
+			seen[name] = struct{}{}
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DocVariable 是文档变量 (w:docVar), 由 DOCVARIABLE 域引用
+type DocVariable struct {
+	Name  string
+	Value string
+}
+
+// DocVariables 返回文档 doc 的设置 (word/settings.xml) 中的文档变量, 没有时返回 nil
+func DocVariables(doc *Docx) []DocVariable {
+	name, ok := partName(doc, relSettings)
+	if !ok {
+		return nil
+	}
+	data, err := doc.readPart(name)
+	if err != nil {
+		return nil
+	}
+	var vars []DocVariable
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return vars
+		}
+		if se, ok := t.(xml.StartElement); ok && se.Name.Local == "docVar" && se.Name.Space == XMLNS_W {
+			vars = append(vars, DocVariable{Name: getAtt(se.Attr, "name"), Value: getAtt(se.Attr, "val")})
+		}
+	}
+	return vars
+}
+
+// copyDocVars 将原文档 src 的文档变量写入新文档 dst 的设置, dst 没有设置部件时新建一个只含文档变量的设置
+func (t *Translator) copyDocVars(src, dst *Docx) {
+	vars := DocVariables(src)
+	if len(vars) == 0 {
+		return
+	}
+	var buf bytes.Buffer
+	buf.WriteString("<w:docVars>")
+	for _, v := range vars {
+		buf.WriteString(`<w:docVar w:name="`)
+		_ = xml.EscapeText(&buf, []byte(v.Name))
+		buf.WriteString(`" w:val="`)
+		_ = xml.EscapeText(&buf, []byte(v.Value))
+		buf.WriteString(`"/>`)
+	}
+	buf.WriteString("</w:docVars>")
+
+	if name, ok := partName(dst, relSettings); ok {
+		data, err := dst.readPart(name)
+		i := bytes.LastIndex(data, []byte("</w:settings>"))
+		if err != nil || i < 0 || bytes.Contains(data, []byte("<w:docVars")) {
+			t.log().Log(LogLevelWarn, "无法将文档变量写入译文的设置", "part", name)
+			return
+		}
+		patched := make([]byte, 0, len(data)+buf.Len())
+		patched = append(patched, data[:i]...)
+		patched = append(patched, buf.Bytes()...)
+		patched = append(patched, data[i:]...)
+		dst.setPart(name, patched)
+		return
+	}
+	if !addContentType(dst, "/word/settings.xml", contentTypeSettings) {
+		t.log().Log(LogLevelWarn, "无法将文档变量写入译文的设置")
+		return
+	}
+	dst.docRelation.Relationship = append(dst.docRelation.Relationship, Relationship{
+		ID:     "rId" + strconv.Itoa(int(atomic.AddUintptr(&dst.rID, 1))),
+		Type:   relSettings,
+		Target: "settings.xml",
+	})
+	dst.setPart("word/settings.xml", []byte(xml.Header+`<w:settings xmlns:w="`+XMLNS_W+`">`+buf.String()+`</w:settings>`))
+}
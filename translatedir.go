@@ -0,0 +1,211 @@
+package docx
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBatchCacheSize 是 TranslateDir 在没有设置 Cache 时使用的内存缓存的容量
+const DefaultBatchCacheSize = 100000
+
+// WithDocumentConcurrency 设置 TranslateDir 同时翻译的文档数, n 不大于 1 时逐个翻译 (默认);
+// 每个文档内同时翻译的翻译单元数仍由 WithConcurrency 设置, 同时请求翻译服务的总数最多为两者之积
+func (t *Translator) WithDocumentConcurrency(n int) *Translator {
+	t.docConcurrency = n
+	return t
+}
+
+// BatchFile 是 TranslateDir 中一个文件译为一种语言的结果
+type BatchFile struct {
+	Input          string        `json:"input"`            // Input 是相对于输入目录的路径
+	Output         string        `json:"output,omitempty"` // Output 是相对于输出目录的路径, 没有写出译文时为空
+	TargetLanguage string        `json:"target_language"`
+	Format         string        `json:"format"`
+	Usage          JobUsage      `json:"usage"`
+	Duration       time.Duration `json:"duration"`
+	Error          string        `json:"error,omitempty"` // Error 是翻译失败的原因; 部分翻译单元失败时也有译文, 见 Usage.Failed
+}
+
+// BatchReport 是 TranslateDir 的汇总报告
+type BatchReport struct {
+	Files     []BatchFile   `json:"files"` // Files 按输入路径与目标语言的顺序排列
+	Succeeded int           `json:"succeeded"`
+	Failed    int           `json:"failed"` // Failed 是没有写出译文的文件数
+	Usage     JobUsage      `json:"usage"`  // Usage 是所有文件的用量之和
+	Duration  time.Duration `json:"duration"`
+}
+
+// TranslateDir 将目录 in 中 (含子目录) 所有可以翻译的文件分别译为 targets 中的每种语言, 按原有的目录结构写入 out,
+// 返回汇总报告
+//
+// 文件按扩展名确定格式: 扩展名与格式名相同 (.docx, .pptx, .md 等, 包括 RegisterFormat 注册的格式), 以及 .htm 与 .markdown;
+// 其他文件被忽略. 译文的文件名为在扩展名之前插入语言代码, 如 a.docx 译为日语时为 a.ja.docx, .doc 与 .rtf 的译文为 .docx.
+// 同时翻译的文档数由 WithDocumentConcurrency 设置. 所有文件共用 t 的 Cache 与 TranslationMemory, 重复的句子只翻译一次;
+// 没有设置 Cache 时使用容量为 DefaultBatchCacheSize 的内存缓存. 进度回调不会被并发调用.
+//
+// 单个文件的失败记录在报告中, 不影响其他文件; 只有无法读取 in 时返回错误. ctx 结束后不再开始新的文件, 返回已有的报告与 ctx.Err().
+func (t *Translator) TranslateDir(ctx context.Context, in, out string, targets []string) (*BatchReport, error) {
+	start := time.Now()
+	var inputs []string
+	err := filepath.WalkDir(in, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && dirFormat(d.Name()) != "" && !strings.HasPrefix(d.Name(), "~$") {
+			rel, err := filepath.Rel(in, p)
+			if err != nil {
+				return err
+			}
+			inputs = append(inputs, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(inputs)
+
+	bt := *t
+	if bt.cache == nil {
+		bt.cache = NewLRUCache(DefaultBatchCacheSize)
+	}
+	var progressMu sync.Mutex
+	if progress := t.progress; progress != nil {
+		bt.progress = func(done, total int, sg SegmentInfo) {
+			progressMu.Lock()
+			defer progressMu.Unlock()
+			progress(done, total, sg)
+		}
+	}
+
+	report := &BatchReport{Files: make([]BatchFile, 0, len(inputs)*len(targets))}
+	for _, rel := range inputs {
+		for _, lang := range targets {
+			report.Files = append(report.Files, BatchFile{Input: rel, TargetLanguage: lang, Format: dirFormat(rel)})
+		}
+	}
+	n := t.docConcurrency
+	if n < 1 {
+		n = 1
+	}
+	sem := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	for i := range report.Files {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(f *BatchFile) {
+			defer func() { <-sem; wg.Done() }()
+			bt.translateDirFile(ctx, in, out, f)
+		}(&report.Files[i])
+	}
+	wg.Wait()
+
+	for _, f := range report.Files {
+		switch {
+		case f.Output != "":
+			report.Succeeded++
+		case f.Error != "":
+			report.Failed++
+		}
+		report.Usage.Segments += f.Usage.Segments
+		report.Usage.Characters += f.Usage.Characters
+		report.Usage.InputTokens += f.Usage.InputTokens
+		report.Usage.OutputTokens += f.Usage.OutputTokens
+		report.Usage.Failed += f.Usage.Failed
+	}
+	report.Duration = time.Since(start)
+	return report, ctx.Err()
+}
+
+// translateDirFile 翻译 TranslateDir 中的一个文件, 结果记录在 f 中
+func (t *Translator) translateDirFile(ctx context.Context, in, out string, f *BatchFile) {
+	start := time.Now()
+	defer func() { f.Duration = time.Since(start) }()
+
+	var (
+		mu   sync.Mutex
+		info JobInfo
+	)
+	ft := *t
+	ft.progress = func(done, total int, sg SegmentInfo) {
+		mu.Lock()
+		info.record(done, total, sg)
+		mu.Unlock()
+		if t.progress != nil {
+			t.progress(done, total, sg)
+		}
+	}
+	rel := LanguageFileName(f.Input, f.TargetLanguage)
+	if f.Format == "doc" || f.Format == "rtf" {
+		rel = strings.TrimSuffix(rel, filepath.Ext(rel)) + ".docx"
+	}
+	dst := filepath.Join(out, rel)
+	err := os.MkdirAll(filepath.Dir(dst), 0o755)
+	if err == nil {
+		err = ft.translateFileTo(ctx, filepath.Join(in, f.Input), dst, TranslateFileOptions{TargetLanguage: f.TargetLanguage, Format: f.Format})
+	}
+	mu.Lock()
+	f.Usage = info.Usage
+	mu.Unlock()
+	var segErrs SegmentErrors
+	if err == nil || errors.As(err, &segErrs) {
+		f.Output = rel
+	}
+	if err != nil {
+		f.Error = err.Error()
+		t.log().Log(LogLevelWarn, "文件翻译失败", "file", f.Input, "language", f.TargetLanguage, "error", err)
+	}
+}
+
+// dirFormat 按文件名 name 的扩展名返回 TranslateDir 使用的格式名, 不翻译的文件返回空串
+func dirFormat(name string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(name), "."))
+	switch ext {
+	case "htm":
+		ext = "html"
+	case "markdown":
+		ext = "md"
+	}
+	if f := lookupFormat(ext); f != nil && f.Translate != nil {
+		return ext
+	}
+	return ""
+}
+
+// WriteBatchReportCSV 以 input,output,target_language,format,segments,characters,failed_segments,duration_ms,error 的格式写出
+// TranslateDir 的报告, 每个文件的每种语言一行
+func WriteBatchReportCSV(w io.Writer, r *BatchReport) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"input", "output", "target_language", "format", "segments", "characters", "failed_segments", "duration_ms", "error"})
+	if err != nil {
+		return err
+	}
+	for _, f := range r.Files {
+		err = cw.Write([]string{
+			filepath.ToSlash(f.Input), filepath.ToSlash(f.Output), f.TargetLanguage, f.Format,
+			strconv.Itoa(f.Usage.Segments), strconv.Itoa(f.Usage.Characters), strconv.Itoa(f.Usage.Failed),
+			strconv.FormatInt(f.Duration.Milliseconds(), 10), f.Error,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
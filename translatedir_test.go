@@ -0,0 +1,81 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTranslateDir(t *testing.T) {
+	in, out := t.TempDir(), t.TempDir()
+	var doc bytes.Buffer
+	if _, err := newTestDoc("hello world").WriteTo(&doc); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range map[string][]byte{
+		"a.docx":          doc.Bytes(),
+		"sub/b.docx":      doc.Bytes(),
+		"sub/notes.md":    []byte("# Title\n\nhello world\n"),
+		"bad.docx":        []byte("not a document"),
+		"image.png":       {0x89, 'P', 'N', 'G'},
+		"sub/~$lock.docx": doc.Bytes(),
+	} {
+		p := filepath.Join(in, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		mu    sync.Mutex
+		calls = map[string]int{}
+	)
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		mu.Lock()
+		calls[text]++
+		mu.Unlock()
+		return strings.ToUpper(text), nil
+	})).WithDocumentConcurrency(1)
+	r, err := tr.TranslateDir(context.Background(), in, out, []string{"Japanese", "English"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Files) != 8 || r.Succeeded != 6 || r.Failed != 2 {
+		t.Fatalf("unexpected report: %+v", r)
+	}
+	if f := r.Files[0]; f.Input != "a.docx" || f.TargetLanguage != "Japanese" || f.Output != "a.ja.docx" || f.Usage.Segments != 1 {
+		t.Fatalf("unexpected file: %+v", f)
+	}
+	if f := r.Files[2]; f.Input != "bad.docx" || f.Output != "" || f.Error == "" {
+		t.Fatalf("unexpected failed file: %+v", f)
+	}
+	for _, name := range []string{"a.ja.docx", "a.en.docx", "sub/b.ja.docx", "sub/notes.en.md"} {
+		if _, err = os.Stat(filepath.Join(out, filepath.FromSlash(name))); err != nil {
+			t.Error(err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(out, "sub", "notes.ja.md"))
+	if err != nil || !strings.Contains(string(data), "HELLO WORLD") {
+		t.Fatalf("unexpected markdown: %q %v", data, err)
+	}
+	// 相同的段落在各文件之间共用缓存: sub/b.docx 全部命中, 只有 a.docx 与附加要求不同的 notes.md 请求翻译服务
+	if calls["hello world"] != 4 {
+		t.Errorf("expected 4 requests, got %d", calls["hello world"])
+	}
+
+	var csv bytes.Buffer
+	if err = WriteBatchReportCSV(&csv, r); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	if len(lines) != 9 || !strings.HasPrefix(lines[1], "a.docx,a.ja.docx,Japanese,docx,1,11,0,") {
+		t.Fatalf("unexpected csv:\n%s", csv.String())
+	}
+}
@@ -0,0 +1,81 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// WithDocConverter 设置 TranslateFile 将 Word 97-2003 文档 (.doc) 转换为 docx 包的函数, 如 LibreOfficeConverter;
+// 未设置时 .doc 文档返回包装了 ErrUnsupportedFormat 的错误. 转换得到的 docx 包按 docx 文档翻译, 译文以 docx 格式输出
+func (t *Translator) WithDocConverter(convert func(data []byte) ([]byte, error)) *Translator {
+	t.docConverter = convert
+	return t
+}
+
+// LibreOfficeConverter 返回调用 LibreOffice 无界面模式 (soffice --headless --convert-to docx) 转换文档的函数,
+// 可用于 WithDocConverter. soffice 是 LibreOffice 可执行文件的路径, 为空时在 PATH 中查找 soffice
+//
+// 每次转换使用独立的临时目录与用户配置, 因此可以并发调用.
+func LibreOfficeConverter(soffice string) func(data []byte) ([]byte, error) {
+	if soffice == "" {
+		soffice = "soffice"
+	}
+	return func(data []byte) ([]byte, error) {
+		dir, err := os.MkdirTemp("", "docx-convert-")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+		in := filepath.Join(dir, "input.doc")
+		if err = os.WriteFile(in, data, 0o600); err != nil {
+			return nil, err
+		}
+		profile := filepath.ToSlash(filepath.Join(dir, "profile"))
+		if !strings.HasPrefix(profile, "/") {
+			profile = "/" + profile // Windows 盘符路径
+		}
+		var stderr bytes.Buffer
+		cmd := exec.Command(soffice, "-env:UserInstallation=file://"+profile,
+			"--headless", "--convert-to", "docx", "--outdir", dir, in)
+		cmd.Stderr = &stderr
+		if err = cmd.Run(); err != nil {
+			return nil, fmt.Errorf("libreoffice: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		out, err := os.ReadFile(filepath.Join(dir, "input.docx"))
+		if err != nil {
+			return nil, fmt.Errorf("libreoffice: no output: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return out, nil
+	}
+}
+
+func translateDocFile(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error {
+	if t.docConverter == nil {
+		return fmt.Errorf("%w: doc (set a converter with WithDocConverter)", ErrUnsupportedFormat)
+	}
+	converted, err := t.docConverter(data)
+	if err != nil {
+		return err
+	}
+	t.log().Log(LogLevelDebug, "已将 doc 文档转换为 docx", "size", len(converted))
+	return translateDocxFile(ctx, t, converted, w, targetLanguage)
+}
+
+// sniffDoc 判断是否为 Word 97-2003 文档: 含有 WordDocument 流的复合文档
+func sniffDoc(data []byte) bool {
+	if !isCFB(data) {
+		return false
+	}
+	r, err := readCFB(data)
+	if err != nil {
+		return false
+	}
+	_, ok := r.find(r.entries[0].child, "WordDocument", 0)
+	return ok
+}
@@ -0,0 +1,45 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTranslateFileDoc(t *testing.T) {
+	doc := writeCFB([]*cfbNode{{name: "WordDocument", data: []byte("binary word document")}, {name: "1Table", data: []byte{0}}})
+	if got := SniffFormat(doc); got != "doc" {
+		t.Fatalf("expected doc, got %q", got)
+	}
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	if err := tr.TranslateFile(context.Background(), bytes.NewReader(doc), io.Discard, TranslateFileOptions{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatal("expected ErrUnsupportedFormat without a converter, got", err)
+	}
+
+	var converted []byte
+	tr.WithDocConverter(func(data []byte) ([]byte, error) {
+		converted = data
+		var buf bytes.Buffer
+		_, err := newTestDoc("hello").WriteTo(&buf)
+		return buf.Bytes(), err
+	})
+	var out bytes.Buffer
+	if err := tr.TranslateFile(context.Background(), bytes.NewReader(doc), &out, TranslateFileOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(converted, doc) {
+		t.Fatal("the converter did not receive the original document")
+	}
+	parsed, err := Parse(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := parsed.Document.Body.Items[0].(*Paragraph).String(); s != "HELLO" {
+		t.Fatal("unexpected translation:", s)
+	}
+}
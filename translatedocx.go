@@ -0,0 +1,616 @@
+package docx
+
+import (
+	"context"
+	"strings"
+)
+
+// SegmentInfo 描述一个翻译单元 (文档中一个有内容的段落)
+type SegmentInfo struct {
+	Index  int    // Index 是翻译单元在文档遍历顺序中的序号
+	Source string // Source 是原文
+	Target string // Target 是译文, 翻译失败时为原文
+	Err    error  // Err 是翻译失败的原因, 成功时为 nil
+}
+
+// ProgressFunc 在每个翻译单元完成 (无论成功与否) 后被调用,
+// done 为已完成的数量, total 为文档中翻译单元的总数
+type ProgressFunc func(done, total int, segment SegmentInfo)
+
+// WithProgress 设置进度回调
+func (t *Translator) WithProgress(fn ProgressFunc) *Translator {
+	t.progress = fn
+	return t
+}
+
+// segment 是 TranslateDocx 内部使用的翻译单元
+type segment struct {
+	src  *Paragraph // src 是原文档中的段落, 文档属性等段落以外的翻译单元为 nil
+	dst  *Paragraph // dst 是新文档中对应的段落, 翻译完成后填充
+	text string     // text 是 src 拼接后的纯文本
+	hint string     // hint 是段落样式、超链接与域对应的翻译要求
+	lang string     // lang 是按语言标记拆分出的片段的原文语言, 为空表示与文档相同
+
+	inlines       []*inline     // inlines 是段落中的超链接与域, text 中用标记表示, 见 inlineText; TranslateDocxInPlace 中只有超链接, 见 inPlaceText
+	before, after []interface{} // before 与 after 是段落文字之前与之后的书签, 放在译文的两侧
+
+	inPlace bool         // inPlace 表示 dst 就是 src 或其副本, 译文替换其中的文本, 见 TranslateDocxInPlace
+	groups  [][]*Run     // groups 是 inPlace 时各超链接前后的 Run, 见 inPlaceText
+	visible bool         // visible 表示译文不放入隐藏的 Run, 隐藏的文字保持不变, 见 WithSkipHidden
+	texts   []*Text      // texts 非空时译文只替换这些文本节点, 见 tocSegment
+	set     func(string) // set 非 nil 时译文交给 set 写入段落以外的地方 (如图片的替代文字与文档属性), 见 altSegments
+	track   *tracker     // track 非 nil 时译文以修订的形式输出, 见 WithTrackChanges
+	comment *Comment     // comment 非 nil 时译文段落附加以原文为内容的批注, 见 OutputModeSourceComments
+	hidden  bool         // hidden 表示在译文之前保留隐藏的原文, 见 OutputModeHiddenSource
+	langTag string       // langTag 非空时译文的 Run 上的语言标记改为 langTag, 见 retagRun
+	fonts   FontMap      // fonts 是译文的 Run 的东亚字体, 见 WithFontMap
+	done    func()       // done 非 nil 时在 dst 填充译文之后调用, 见 copyComments
+
+	// 以下字段只用于按语言标记拆分出的片段, 见 WithRunLanguageRouting
+	routed      bool   // routed 表示译文填入 dst.Children[slot] 而不是追加到末尾
+	slot        int    // slot 是片段在 dst.Children 中的位置
+	run         *Run   // run 是片段的第一个 Run, 译文继承其格式
+	lead, trail string // lead 与 trail 是译文两侧与相邻片段之间的空格
+}
+
+// paragraphText 拼接段落中所有 Run 的文本
+func paragraphText(p *Paragraph) string {
+	var sb strings.Builder
+	for _, child := range p.Children {
+		if run, ok := child.(*Run); ok {
+			for _, grandChild := range run.Children {
+				if text, ok := grandChild.(*Text); ok {
+					sb.WriteString(text.Text)
+				}
+			}
+		}
+	}
+	return sb.String()
+}
+
+// fill 将译文放入新段落，并尽量保留格式
+func (sg *segment) fill(translated string) {
+	if sg.routed {
+		text := textChildren(sg.lead + strings.TrimSpace(translated) + sg.trail)
+		run := &Run{RunProperties: sg.run.RunProperties, Children: text}
+		retagRun(run, sg.langTag)
+		if sg.langTag != "" {
+			directParagraph(sg.dst, isRightToLeft(sg.langTag))
+			directRun(run, isRightToLeft(sg.langTag))
+		}
+		mapFont(run, sg.fonts)
+		sg.dst.Children[sg.slot] = run
+		return
+	}
+	if sg.set != nil {
+		sg.set(translated)
+		return
+	}
+	if sg.inPlace {
+		sg.replaceText(translated)
+		return
+	}
+	sg.dst.Children = append(sg.dst.Children, sg.before...)
+	from := len(sg.dst.Children)
+	sg.fillRuns(translated)
+	retagRuns(sg.dst.Children[from:], sg.langTag)
+	if sg.langTag != "" {
+		directParagraph(sg.dst, isRightToLeft(sg.langTag))
+		directRuns(sg.dst.Children[from:], isRightToLeft(sg.langTag))
+	}
+	mapFonts(sg.dst.Children[from:], sg.fonts)
+	sg.dst.Children = append(sg.dst.Children, sg.after...)
+	if sg.track != nil {
+		sg.markRevisions(from)
+	}
+	if sg.hidden {
+		sg.hideSource(from)
+	}
+	if sg.comment != nil {
+		sg.anchorComment()
+	}
+	if sg.done != nil {
+		sg.done()
+	}
+}
+
+// fillRuns 将译文放入新段落的 Run 中, 超链接与域等按标记重建, 制表符与换行等按 textChildren 还原
+func (sg *segment) fillRuns(translated string) {
+	if len(sg.src.Children) == 0 {
+		return
+	}
+	// 创建一个新的 Run 来存放完整的翻译文本
+	// 并继承原段落第一个 Run 的格式
+	newRun := &Run{
+		RunProperties: &RunProperties{},
+		Children:      textChildren(translated),
+	}
+	for _, child := range sg.src.Children {
+		// 跳过段落开头的书签与批注标记等
+		if firstRun, ok := child.(*Run); ok && !isCommentRun(firstRun) {
+			newRun.RunProperties = firstRun.RunProperties
+			break
+		}
+	}
+	if len(sg.inlines) > 0 {
+		if sg.fillInlines(translated, newRun.RunProperties) {
+			return
+		}
+		// 标记不完整时只保留译文的文字, 不翻译的域追加在其后
+		newRun.Children = textChildren(inlineTagRe.ReplaceAllString(translated, ""))
+		sg.dst.Children = append(sg.dst.Children, newRun)
+		sg.fillAtomic()
+		return
+	}
+	sg.dst.Children = append(sg.dst.Children, newRun)
+}
+
+// sourceUnit 是原文档中一个段落与目标语言无关的分段结果, 见 segmentPlan
+type sourceUnit struct {
+	src      *Paragraph // src 是要复制或翻译的段落, RevisionPolicyAccept 下为接受修订之后的段落
+	verbatim bool       // verbatim 表示段落原样复制 (不在翻译范围内, 或样式、文字被跳过)
+	toc      bool       // toc 表示目录段落, 见 tocSegment
+	seg      *segment   // seg 是翻译单元的模板, 没有 dst; 为 nil 且不是以上两种时段落没有文字, 只翻译图片的替代文字
+}
+
+// segmentPlan 是 prepare 中与目标语言无关的部分: 原文档各段落的分段结果, 在第一次用到时计算;
+// TranslateDocxMulti 只对原文档分段一次, 各目标语言复制其中的翻译单元
+type segmentPlan struct {
+	t        *Translator
+	doc      *Docx
+	toc      map[*Paragraph]bool
+	selected map[*Paragraph]bool
+	units    map[*Paragraph]*sourceUnit
+}
+
+func (t *Translator) newSegmentPlan(doc *Docx) *segmentPlan {
+	return &segmentPlan{t: t, doc: doc, toc: tocParagraphs(doc), selected: t.rangeParagraphs(doc), units: make(map[*Paragraph]*sourceUnit)}
+}
+
+// unit 返回原文档中段落 p 的分段结果
+func (pl *segmentPlan) unit(p *Paragraph) *sourceUnit {
+	if u, ok := pl.units[p]; ok {
+		return u
+	}
+	t := pl.t
+	u := &sourceUnit{src: p}
+	pl.units[p] = u
+	switch {
+	case t.skipStyle(p) || pl.selected != nil && !pl.selected[p]:
+		u.verbatim = true
+		return u
+	case pl.toc[p]:
+		u.toc = true
+		return u
+	case t.revisionPolicy == RevisionPolicyAccept:
+		u.src = acceptRevisions(p)
+	}
+	text, inlines, before, after := inlineText(u.src, t.skipHidden)
+	switch {
+	case strings.TrimSpace(inlineTagRe.ReplaceAllString(text, "")) == "":
+	case t.skipText(text):
+		u.verbatim = true
+	default:
+		u.seg = &segment{src: u.src, text: text, hint: joinHints(t.styleHint(u.src), breakHintFor(text)), inlines: inlines, before: before, after: after}
+		if len(inlines) > 0 {
+			u.seg.hint = joinHints(u.seg.hint, inlineHint)
+		}
+	}
+	return u
+}
+
+// prepare 搭建新文档的结构并收集所有翻译单元, 此时新文档中的段落尚未填充译文
+func (t *Translator) prepare(doc *Docx, targetLanguage string) (*Docx, []*segment) {
+	return t.preparePlan(t.newSegmentPlan(doc), targetLanguage)
+}
+
+// preparePlan 同 prepare, 翻译单元复制自 plan, 原文档是 plan 的原文档
+//
+// 新文档沿用原文档各节的页面设置 (纸张、页边距、方向与分栏), 原文档没有页面设置时使用 A4;
+// 样式、编号、主题与字体表也从原文档复制, 见 copyParts; 启用宏的文档 (.docm) 连同宏一起复制, 见 copyMacros;
+// 自定义 XML 部件与文档变量原样复制, 见 copyCustomXML; 批注连同锚点与回复关系一起复制并翻译, 见 copyComments; 目录的处理见 WithTOCEntries.
+// 内容控件 (SDT) 的标识与类型原样保留, 其中的段落 (包括显示中的占位文字) 与其他段落一样翻译.
+// 图片的替代文字与标题单独翻译, 见 altSegments; 题注中的 SEQ 编号原样保留. 嵌入的 OLE 对象 (如 Excel 表格) 连同其预览图与嵌入的文件一起复制.
+// 文档属性 (标题、主题、关键词等) 复制到新文档后翻译, 见 propSegments. 修订的处理见 WithRevisionPolicy.
+// 按样式、范围与文字选择翻译的段落见 WithStyleFilter、WithRange 与 WithSkipPatterns.
+// 译文与原文的排列方式见 WithOutputMode. 译文的 Run 与样式中的语言标记 (w:lang) 改为 targetLanguage, 使拼写检查按译文的语言进行;
+// 译文段落、Run 与各节的书写方向随 targetLanguage 设为从右向左 (阿拉伯语、希伯来语等) 或从左向右. 字体的替换见 WithFontMap.
+// 没有任何内容的文档 (包括只有 sectPr 的文档) 得到只含页面设置的合法空文档,
+// 以及零个翻译单元. targetLanguage 为空时不检测双语段落.
+func (t *Translator) preparePlan(plan *segmentPlan, targetLanguage string) (*Docx, []*segment) {
+	doc := plan.doc
+	newDoc := New().WithDefaultTheme()
+	t.copyParts(doc, newDoc)
+	t.copyMacros(doc, newDoc)
+	t.copyCustomXML(doc, newDoc)
+	copied, commentSegs := t.copyComments(doc, newDoc)
+	copyProps(doc, newDoc)
+	tag := languageTag(targetLanguage)
+	retagStyles(newDoc, tag)
+
+	segs := make([]*segment, 0, 64)
+	track := t.newTracker(doc)
+	comments := t.newSourceComments(nextCommentID(copied))
+	var source *Paragraph // source 是 collect 最近翻译的原文段落, 双语输出中与译文段落并列, 见 WithOutputMode
+	collect := func(p *Paragraph) *Paragraph {
+		u := plan.unit(p)
+		if u.toc {
+			// 目录域原样复制, 条目文字按 WithTOCEntries 翻译
+			np := p.copymedia(newDoc)
+			sg, mark := t.tocSegment(p, &np)
+			mark()
+			if sg != nil {
+				segs = append(segs, sg)
+			}
+			return &np
+		}
+		p = u.src
+		if u.seg == nil {
+			// 对于空段落或只有空格的段落 (如只有图片的段落), 连同其引用的媒体一起复制,
+			// 新文档拥有独立的媒体列表与索引, 之后修改任意一方都不会相互影响; 图片的替代文字单独翻译
+			np := p.copymedia(newDoc)
+			if !u.verbatim {
+				segs = append(segs, t.altSegments(p, &np)...)
+			}
+			return &np
+		}
+		text := u.seg.text
+		if t.routeRuns && !(t.skipHidden && hasHidden(p)) {
+			if np, routed := t.route(p, newDoc, targetLanguage); np != nil {
+				segs = append(segs, routed...)
+				source = p
+				return np
+			}
+		}
+		if t.bilingualPolicy != BilingualPolicyTranslate && targetLanguage != "" && len(u.seg.inlines) == 0 {
+			if src, tgt, ok := SplitBilingual(text, t.sourceLanguageName(), targetLanguage); ok {
+				switch t.bilingualPolicy {
+				case BilingualPolicyKeep:
+					np := p.copymedia(newDoc)
+					return &np
+				case BilingualPolicyStripSource:
+					sg := &segment{src: p, dst: &Paragraph{Properties: p.Properties, file: newDoc}, before: u.seg.before, after: u.seg.after}
+					sg.fill(tgt)
+					return sg.dst
+				case BilingualPolicyRetranslate:
+					text = src
+				case BilingualPolicyTranslate:
+				}
+			}
+		}
+		np := &Paragraph{
+			Properties: p.Properties,
+			Children:   make([]interface{}, 0),
+			file:       newDoc,
+		}
+		sg := *u.seg
+		sg.dst, sg.track = np, track
+		if text != sg.text {
+			// 重新翻译双语段落中的原文
+			sg.text, sg.hint = text, joinHints(t.styleHint(p), breakHintFor(text))
+		}
+		if comments != nil {
+			sg.comment = comments.add(p)
+		}
+		sg.hidden = t.outputMode == OutputModeHiddenSource
+		segs = append(segs, &sg)
+		source = p
+		return np
+	}
+	// paragraphs 返回段落 p 在新文档中对应的段落, OutputModeBilingualInterleaved 中译文段落与原文段落并列
+	paragraphs := func(p *Paragraph) []*Paragraph {
+		source = nil
+		np := collect(p)
+		if source == nil || t.outputMode != OutputModeBilingualInterleaved {
+			return []*Paragraph{np}
+		}
+		return t.interleaved(np, sourceParagraph(source, newDoc, t.interleaveOptions()))
+	}
+
+	// copyTable 创建结构相同的新表格, 逐行逐格复制, 各行的单元格数可以不同
+	var copyTable func(o *Table) *Table
+	copyTable = func(o *Table) *Table {
+		newTable := &Table{
+			TableProperties: o.TableProperties,
+			TableGrid:       o.TableGrid,
+			TableRows:       make([]*WTableRow, 0, len(o.TableRows)),
+			file:            newDoc,
+		}
+		for _, row := range o.TableRows {
+			newRow := &WTableRow{
+				TableRowProperties: row.TableRowProperties,
+				TableCells:         make([]*WTableCell, 0, len(row.TableCells)),
+				file:               newDoc,
+			}
+			for _, cell := range row.TableCells {
+				newCell := &WTableCell{
+					TableCellProperties: cell.TableCellProperties,
+					Paragraphs:          make([]*Paragraph, 0, len(cell.Paragraphs)),
+					file:                newDoc,
+				}
+				// 按阅读顺序处理段落与嵌套的表格, 并记录表格的位置
+				for _, item := range cell.Items() {
+					switch c := item.(type) {
+					case *Paragraph:
+						newCell.Paragraphs = append(newCell.Paragraphs, paragraphs(c)...)
+					case *Table:
+						if len(c.TableRows) > 0 {
+							newCell.Tables = append(newCell.Tables, copyTable(c))
+							newCell.tablePos = append(newCell.tablePos, len(newCell.Paragraphs))
+						}
+					}
+				}
+				if len(newCell.Paragraphs) == 0 {
+					// 单元格中至少要有一个段落
+					newCell.Paragraphs = append(newCell.Paragraphs, &Paragraph{file: newDoc})
+				}
+				newRow.TableCells = append(newRow.TableCells, newCell)
+			}
+			newTable.TableRows = append(newTable.TableRows, newRow)
+		}
+		return newTable
+	}
+
+	// copySDT 创建属性相同的新内容控件, 其中的段落与表格同样翻译
+	var copySDT func(o *SDT) *SDT
+	copySDT = func(o *SDT) *SDT {
+		newSDT := &SDT{Properties: o.Properties, file: newDoc}
+		if o.Content == nil {
+			return newSDT
+		}
+		newSDT.Content = &SDTContent{Items: make([]interface{}, 0, len(o.Content.Items))}
+		for _, item := range o.Content.Items {
+			switch c := item.(type) {
+			case *Paragraph:
+				for _, np := range paragraphs(c) {
+					newSDT.Content.Items = append(newSDT.Content.Items, np)
+				}
+			case *Table:
+				if len(c.TableRows) > 0 {
+					newSDT.Content.Items = append(newSDT.Content.Items, copyTable(c))
+				}
+			case *SDT:
+				newSDT.Content.Items = append(newSDT.Content.Items, copySDT(c))
+			}
+		}
+		if len(newSDT.Content.Items) == 0 {
+			// 内容控件中至少要有一个段落
+			newSDT.Content.Items = append(newSDT.Content.Items, &Paragraph{file: newDoc})
+		}
+		return newSDT
+	}
+
+	side := &sideBySide{to: newDoc, width: textWidth(lastSectPr(doc))}
+	for _, item := range doc.Document.Body.Items {
+		switch o := item.(type) {
+		case *Paragraph:
+			if t.outputMode == OutputModeBilingualTable {
+				source = nil
+				np := collect(o)
+				var left *Paragraph
+				if source != nil {
+					left = sourceParagraph(source, newDoc, InterleaveOptions{})
+				}
+				newDoc.Document.Body.Items = side.add(newDoc.Document.Body.Items, left, np)
+				continue
+			}
+			for _, np := range paragraphs(o) {
+				newDoc.Document.Body.Items = append(newDoc.Document.Body.Items, np)
+			}
+
+		case *SDT:
+			side.end()
+			newDoc.Document.Body.Items = append(newDoc.Document.Body.Items, copySDT(o))
+
+		case *BookmarkStart, *BookmarkEnd:
+			// 跨越段落或表格的书签
+			side.end()
+			newDoc.Document.Body.Items = append(newDoc.Document.Body.Items, o)
+
+		case *Table:
+			if len(o.TableRows) == 0 {
+				continue // 没有行的表格在 Word 中无效, 直接丢弃
+			}
+			side.end()
+			newDoc.Document.Body.Items = append(newDoc.Document.Body.Items, copyTable(o))
+		}
+	}
+	// 页面设置必须位于 body 的末尾; 各节中间的页面设置随段落属性一同保留
+	if sect := lastSectPr(doc); sect != nil {
+		newDoc.Document.Body.Items = append(newDoc.Document.Body.Items, sect)
+	} else {
+		newDoc.WithA4Page()
+	}
+	if comments != nil && len(comments.list) > 0 {
+		// 原文的批注排在原文档已有的批注之后, 两者一起写入批注部件
+		list := append(copied, comments.list...)
+		writeComments(newDoc, list)
+		if len(commentSegs) > 0 {
+			commentSegs[len(commentSegs)-1].done = func() { writeComments(newDoc, list) }
+		}
+	}
+	segs = append(segs, commentSegs...)
+	fonts := t.fontMap(targetLanguage)
+	for _, sg := range segs {
+		sg.langTag, sg.fonts = tag, fonts
+	}
+	if tag != "" {
+		directSections(newDoc, isRightToLeft(tag))
+	}
+	return newDoc, append(segs, t.propSegments(newDoc)...)
+}
+
+// lastSectPr 返回文档最后一节的页面设置, 没有时返回 nil
+func lastSectPr(doc *Docx) *SectPr {
+	items := doc.Document.Body.Items
+	for i := len(items) - 1; i >= 0; i-- {
+		if sect, ok := items[i].(*SectPr); ok {
+			return sect
+		}
+	}
+	return nil
+}
+
+// editSections 以 edit 修改文档 doc 中各节的页面设置; 页面设置可能与原文档共用, edit 修改的是其副本
+func editSections(doc *Docx, edit func(sect *SectPr)) {
+	items := doc.Document.Body.Items
+	for i, item := range items {
+		switch o := item.(type) {
+		case *Paragraph:
+			if o.Properties != nil && o.Properties.SectPr != nil {
+				pp, sect := *o.Properties, *o.Properties.SectPr
+				edit(&sect)
+				pp.SectPr = &sect
+				o.Properties = &pp
+			}
+		case *SectPr:
+			sect := *o
+			edit(&sect)
+			items[i] = &sect
+		}
+	}
+}
+
+// TranslateDocx 翻译一个 docx 对象，并返回一个新的翻译后的 docx 对象
+//
+// 段落翻译失败时的行为由 WithErrorPolicy 决定:
+// ErrorPolicyFailFast 下返回 nil 与第一个 *SegmentError;
+// ErrorPolicyCollect 下返回新文档与 SegmentErrors.
+// 超出 WithLimits 设置的限制时返回 nil 与 *LimitError.
+func (t *Translator) TranslateDocx(doc *Docx, targetLanguage string) (*Docx, error) {
+	return t.TranslateDocxContext(context.Background(), doc, targetLanguage)
+}
+
+// TranslateDocxContext 同 TranslateDocx, ctx 被取消时在当前段落完成后停止, 返回 nil 与 ctx.Err()
+func (t *Translator) TranslateDocxContext(ctx context.Context, doc *Docx, targetLanguage string) (*Docx, error) {
+	newDoc, _, err := t.translateDocx(ctx, doc, targetLanguage)
+	return newDoc, err
+}
+
+func (t *Translator) translateDocx(ctx context.Context, doc *Docx, targetLanguage string) (*Docx, *Report, error) {
+	if err := t.checkMediaBytes(doc); err != nil {
+		return nil, nil, err
+	}
+	return t.translatePlan(ctx, t.newSegmentPlan(doc), t.newProvenance(doc), targetLanguage)
+}
+
+// translatePlan 按 plan 翻译原文档, pv 是原文档的来源信息
+func (t *Translator) translatePlan(ctx context.Context, plan *segmentPlan, pv *provenance, targetLanguage string) (*Docx, *Report, error) {
+	// 1. 搭建新文档的结构并收集所有翻译单元, 超出限制时不请求翻译服务
+	newDoc, segs := t.preparePlan(plan, targetLanguage)
+
+	// 2. 逐个翻译并填充
+	report, err := t.translateSegments(ctx, segs, targetLanguage)
+	if report == nil {
+		return nil, nil, err
+	}
+	t.stamp(pv, newDoc)
+	return newDoc, report, err
+}
+
+// translateSegments 逐个翻译翻译单元, 相同的原文只翻译一次, 全部完成后才填充译文
+//
+// 超出限制、ctx 被取消或 ErrorPolicyFailFast 下出错时返回 nil 与错误, 此时不会填充任何译文;
+// ErrorPolicyCollect 下有失败的翻译单元时返回报告与 SegmentErrors.
+func (t *Translator) translateSegments(ctx context.Context, segs []*segment, targetLanguage string) (*Report, error) {
+	if err := t.checkSegments(len(segs)); err != nil {
+		return nil, err
+	}
+	type result struct {
+		text string
+		err  error
+
+		verified bool // verified 表示已经回译, 见 WithBackTranslation
+		back     string
+		score    float64
+		backErr  error
+	}
+	results := make(map[string]result, len(segs))
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pending := t.prefetchSegments(ctx, segs, targetLanguage)
+	report := &Report{Segments: make([]SegmentReport, 0, len(segs))}
+	fills := make([]func(), 0, len(segs))
+	var failed SegmentErrors
+	for i, sg := range segs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if prev, ok := t.reusePrevious(sg); ok {
+			t.log().Log(LogLevelDebug, "沿用旧译文", "index", i)
+			t.metrics.countSegment("reused")
+			report.Segments = append(report.Segments, SegmentReport{Index: i, Source: sg.text, Target: prev})
+			if t.progress != nil {
+				t.progress(i+1, len(segs), SegmentInfo{Index: i, Source: sg.text, Target: prev})
+			}
+			continue
+		}
+		sourceLanguage := t.sourceLanguageName()
+		if sg.lang != "" {
+			sourceLanguage = sg.lang
+		}
+		key, hint := t.segmentKey(segs, i)
+		r, ok := results[key]
+		if !ok {
+			if p := pending[key]; p != nil {
+				select {
+				case <-p.done:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+				r.text, r.err = p.text, p.err
+			} else {
+				r.text, r.err = t.sourceTranslator(sg).translateSegment(sg.text, hint, targetLanguage)
+			}
+			results[key] = r
+		} else {
+			t.log().Log(LogLevelDebug, "复用相同原文的译文", "index", i)
+		}
+		translatedText, err := r.text, r.err
+		sr := SegmentReport{Index: i, Source: sg.text, Err: err}
+		if err != nil {
+			t.metrics.countSegment("failed")
+			se := &SegmentError{Index: i, Source: sg.text, Err: err}
+			switch t.errorPolicy {
+			case ErrorPolicyFailFast:
+				return nil, se
+			case ErrorPolicyCollect:
+				failed = append(failed, se)
+			case ErrorPolicyKeepOriginal:
+			}
+			// 如果翻译出错，则保留原文并记录错误
+			t.log().Log(LogLevelWarn, "翻译段落时出错, 将保留原文", "index", i, "err", err)
+			translatedText = sg.text
+		} else {
+			t.metrics.countSegment("translated")
+			if t.backThreshold > 0 {
+				if !r.verified {
+					r.back, r.score, r.backErr = t.backTranslate(sg.text, r.text, sourceLanguage, targetLanguage)
+					r.verified = true
+					results[key] = r
+				}
+				sr.BackTranslation, sr.Score, sr.BackErr = r.back, r.score, r.backErr
+				sr.Flagged = r.backErr == nil && r.score < t.backThreshold
+			}
+			translatedText = t.applyHeadingCase(sg.src, translatedText, targetLanguage)
+			translatedText = t.applyNumberFormat(sg.text, translatedText, sourceLanguage, targetLanguage)
+			translatedText = t.applyPunctuation(translatedText, targetLanguage)
+			translatedText = t.applyTypography(translatedText, targetLanguage)
+			sr.Warnings = CheckTranslation(sg.text, translatedText, sourceLanguage, targetLanguage)
+		}
+		sr.Target = translatedText
+		report.Segments = append(report.Segments, sr)
+		sg := sg
+		fills = append(fills, func() { sg.fill(translatedText) })
+		if t.progress != nil {
+			t.progress(i+1, len(segs), SegmentInfo{Index: i, Source: sg.text, Target: translatedText, Err: err})
+		}
+	}
+	for _, fill := range fills {
+		fill()
+	}
+	if len(failed) > 0 {
+		return report, failed
+	}
+	return report, nil
+}
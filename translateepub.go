@@ -0,0 +1,135 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+)
+
+var (
+	// epubMetaRe 匹配包文件 (.opf) 中翻译的出版物属性
+	epubMetaRe = regexp.MustCompile(`(?s)<(dc:title|dc:description|dc:subject)(?:\s[^>]*)?>([^<]*)</`)
+	// epubLanguageRe 匹配包文件中的出版物语言, 第 1 组与第 2 组是开始与结束标签
+	epubLanguageRe = regexp.MustCompile(`(<dc:language(?:\s[^>]*)?>)[^<]*(</dc:language>)`)
+	// epubNCXRe 匹配 EPUB 2 目录 (.ncx) 中书名与目录项的文字
+	epubNCXRe = regexp.MustCompile(`(?s)<(text)>([^<]*)</text>`)
+)
+
+// TranslateEpub 将电子书 (.epub) data 翻译为 targetLanguage 并写入 w, ctx 被取消时返回 ctx.Err()
+//
+// 按包文件 (.opf) 的 manifest 翻译其中的 XHTML 文档 (各章与 EPUB 3 的导航文档), 规则同 TranslateHTML;
+// 同时翻译书名、描述与主题, EPUB 2 的目录 (.ncx) 中的目录项, 并将 dc:language 改为目标语言.
+// spine、manifest、样式表、图片与字体等原样复制, mimetype 保持为第一个不压缩的文件.
+// 与 TranslateDocx 一样使用缓存、术语表与 WithErrorPolicy 等设置, ErrorPolicyCollect 下失败的段落保留原文并返回 SegmentErrors.
+func (t *Translator) TranslateEpub(ctx context.Context, data []byte, w io.Writer, targetLanguage string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	read := func(name string) ([]byte, error) {
+		f, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("epub: missing %s", name)
+		}
+		return readZipFile(f)
+	}
+
+	content, err := read("META-INF/container.xml")
+	if err != nil {
+		return err
+	}
+	var container struct {
+		Rootfiles []struct {
+			FullPath  string `xml:"full-path,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err = xml.Unmarshal(content, &container); err != nil {
+		return err
+	}
+	var opfName string
+	for _, rf := range container.Rootfiles {
+		if rf.MediaType == "" || rf.MediaType == "application/oebps-package+xml" {
+			opfName = rf.FullPath
+			break
+		}
+	}
+	opf, err := read(opfName)
+	if err != nil {
+		return err
+	}
+	var pkg struct {
+		Items []struct {
+			Href      string `xml:"href,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"manifest>item"`
+	}
+	if err = xml.Unmarshal(opf, &pkg); err != nil {
+		return err
+	}
+
+	var (
+		segs    []*segment
+		renders = make(map[string]func() string)
+		parts   = make(map[string][]byte)
+	)
+	if tag := xliffLang(targetLanguage); tag != "" {
+		parts[opfName] = epubLanguageRe.ReplaceAll(opf, []byte("${1}"+tag+"${2}"))
+	}
+	segs = append(segs, xmlTextSegments(epubMetaRe, opf, parts, opfName, propHint)...)
+	for _, item := range pkg.Items {
+		href, err := url.PathUnescape(item.Href)
+		if err != nil {
+			href = item.Href
+		}
+		name := path.Join(path.Dir(opfName), href)
+		switch item.MediaType {
+		case "application/xhtml+xml", "text/html":
+		case "application/x-dtbncx+xml":
+			content, err := read(name)
+			if err != nil {
+				return err
+			}
+			segs = append(segs, xmlTextSegments(epubNCXRe, content, parts, name, mdHeadingHint)...)
+			continue
+		default:
+			continue
+		}
+		if _, ok := renders[name]; ok {
+			continue
+		}
+		content, err := read(name)
+		if err != nil {
+			return err
+		}
+		chapter, render := t.htmlSegments(string(content), targetLanguage)
+		segs = append(segs, chapter...)
+		renders[name] = render
+	}
+
+	report, err := t.translateSegments(ctx, segs, targetLanguage)
+	if report == nil {
+		return err
+	}
+	for name, render := range renders {
+		parts[name] = []byte(render())
+	}
+	if werr := writeZipParts(w, zr, parts); werr != nil {
+		return werr
+	}
+	return err // ErrorPolicyCollect 下的 SegmentErrors
+}
+
+func translateEpubFile(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error {
+	return t.TranslateEpub(ctx, data, w, targetLanguage)
+}
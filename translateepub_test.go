@@ -0,0 +1,66 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTranslateEpub(t *testing.T) {
+	const (
+		container = `<?xml version="1.0"?><container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">` +
+			`<rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles></container>`
+		opf = `<?xml version="1.0"?><package xmlns="http://www.idpf.org/2007/opf" version="3.0"><metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+			`<dc:title id="t">A tale &amp; more</dc:title><dc:language>zh-CN</dc:language></metadata>` +
+			`<manifest><item id="c1" href="text/chapter%201.xhtml" media-type="application/xhtml+xml"/>` +
+			`<item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/><item id="css" href="style.css" media-type="text/css"/></manifest>` +
+			`<spine toc="ncx"><itemref idref="c1"/></spine></package>`
+		chapter = `<?xml version="1.0" encoding="utf-8"?><html xmlns="http://www.w3.org/1999/xhtml" xml:lang="zh-CN"><head><title>first</title></head>` +
+			`<body><h1>chapter one</h1><p>it was <em>dark</em>.<a id="x"/></p></body></html>`
+		ncx = `<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/"><navMap><navPoint id="n1"><navLabel><text>chapter one</text></navLabel>` +
+			`<content src="text/chapter%201.xhtml"/></navPoint></navMap></ncx>`
+		css = `p { color: red }`
+	)
+	var in bytes.Buffer
+	zw := zip.NewWriter(&in)
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = mw.Write([]byte("application/epub+zip"))
+	for _, f := range [][2]string{{"META-INF/container.xml", container}, {"OEBPS/content.opf", opf},
+		{"OEBPS/text/chapter 1.xhtml", chapter}, {"OEBPS/toc.ncx", ncx}, {"OEBPS/style.css", css}} {
+		w, err := zw.Create(f[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(f[1]))
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	var out bytes.Buffer
+	if err = tr.TranslateFile(context.Background(), bytes.NewReader(in.Bytes()), &out, TranslateFileOptions{TargetLanguage: "English"}); err != nil {
+		t.Fatal(err)
+	}
+	if SniffFormat(out.Bytes()) != "epub" {
+		t.Fatal("the output is not recognized as epub")
+	}
+	files := readZip(t, out.Bytes())
+	for name, want := range map[string]string{
+		"OEBPS/content.opf":          `<dc:title id="t">A TALE &amp; MORE</dc:title><dc:language>en-US</dc:language>`,
+		"OEBPS/text/chapter 1.xhtml": `<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="en-US"><head><title>FIRST</title></head><body><h1>CHAPTER ONE</h1><p>IT WAS <em>DARK</em>.<a id="x"/></p>`,
+		"OEBPS/toc.ncx":              `<text>CHAPTER ONE</text>`,
+		"OEBPS/style.css":            css,
+	} {
+		if !strings.Contains(files[name], want) {
+			t.Errorf("%s: missing %q in %q", name, want, files[name])
+		}
+	}
+}
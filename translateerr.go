@@ -0,0 +1,55 @@
+package docx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ErrorPolicy 决定 TranslateDocx 遇到翻译失败的段落时如何处理
+type ErrorPolicy uint8
+
+const (
+	// ErrorPolicyKeepOriginal 保留失败段落的原文并继续翻译 (默认)
+	ErrorPolicyKeepOriginal ErrorPolicy = iota
+	// ErrorPolicyFailFast 遇到第一个失败的段落立即返回该错误
+	ErrorPolicyFailFast
+	// ErrorPolicyCollect 保留失败段落的原文并继续翻译,
+	// 最后将所有失败的段落以 SegmentErrors 连同新文档一起返回
+	ErrorPolicyCollect
+)
+
+// SegmentError 记录一个翻译失败的段落
+type SegmentError struct {
+	Index  int    // Index 是段落在文档遍历顺序中的序号
+	Source string // Source 是段落原文
+	Err    error  // Err 是翻译时返回的错误
+}
+
+func (e *SegmentError) Error() string {
+	return "翻译第 " + strconv.Itoa(e.Index) + " 段时出错: " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *SegmentError) Unwrap() error {
+	return e.Err
+}
+
+// SegmentErrors 是 ErrorPolicyCollect 下收集到的所有失败段落
+type SegmentErrors []*SegmentError
+
+func (e SegmentErrors) Error() string {
+	sb := strings.Builder{}
+	sb.WriteString(strconv.Itoa(len(e)))
+	sb.WriteString(" 个段落翻译失败")
+	for _, se := range e {
+		sb.WriteString("\n\t")
+		sb.WriteString(se.Error())
+	}
+	return sb.String()
+}
+
+// WithErrorPolicy 设置翻译失败时的处理策略
+func (t *Translator) WithErrorPolicy(policy ErrorPolicy) *Translator {
+	t.errorPolicy = policy
+	return t
+}
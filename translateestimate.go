@@ -0,0 +1,98 @@
+package docx
+
+import (
+	"math"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ProviderPricing 是一个翻译服务的计价方式
+type ProviderPricing struct {
+	Provider     string  // Provider 是服务与模型名, 例如 "dashscope/qwen-plus"
+	InputPer1K   float64 // InputPer1K 是每千个输入 token 的价格
+	OutputPer1K  float64 // OutputPer1K 是每千个输出 token 的价格
+	Currency     string  // Currency 是价格的货币单位
+	PromptTokens int     // PromptTokens 是每次请求中提示词额外占用的 token 数
+}
+
+// DefaultPricings 是 EstimateDocx 默认使用的价格表, 仅供估算
+var DefaultPricings = []ProviderPricing{
+	{Provider: "dashscope/qwen-plus", InputPer1K: 0.0008, OutputPer1K: 0.002, Currency: "CNY"},
+	{Provider: "openai/gpt-3.5-turbo", InputPer1K: 0.0005, OutputPer1K: 0.0015, Currency: "USD", PromptTokens: 20},
+}
+
+// CostEstimate 是在某个翻译服务上的预估花费
+type CostEstimate struct {
+	Provider     string
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+	Currency     string
+}
+
+// Estimate 是 EstimateDocx 的结果
+type Estimate struct {
+	Segments   int // Segments 是需要调用 API 的翻译单元数
+	Characters int // Characters 是所有翻译单元的字符 (rune) 数
+	Tokens     int // Tokens 是所有翻译单元原文的预估 token 数, 不含提示词
+	Costs      []CostEstimate
+}
+
+// WithPricing 设置 EstimateDocx 使用的价格表, 默认为 DefaultPricings
+func (t *Translator) WithPricing(pricings ...ProviderPricing) *Translator {
+	t.pricings = pricings
+	return t
+}
+
+// EstimateDocx 在不调用任何 API 的前提下遍历文档,
+// 统计翻译单元数、字符数, 并按价格表估算 token 数与花费
+//
+// 译文的 token 数按与原文相同估算.
+func (t *Translator) EstimateDocx(doc *Docx, targetLanguage string) *Estimate {
+	_, segs := t.prepare(doc, targetLanguage)
+	est := &Estimate{}
+	seen := make(map[string]struct{}, len(segs))
+	for _, sg := range segs {
+		if _, ok := seen[sg.text]; ok {
+			continue // 相同的原文只会翻译一次
+		}
+		seen[sg.text] = struct{}{}
+		est.Segments++
+		est.Characters += utf8.RuneCountInString(sg.text)
+		est.Tokens += EstimateTokens(sg.text)
+	}
+	pricings := t.pricings
+	if pricings == nil {
+		pricings = DefaultPricings
+	}
+	prompt := EstimateTokens(dashscopeSystemPrompt(t.sourceLanguageName(), targetLanguage))
+	for _, p := range pricings {
+		promptTokens := p.PromptTokens
+		if promptTokens == 0 {
+			promptTokens = prompt
+		}
+		ce := CostEstimate{
+			Provider:     p.Provider,
+			InputTokens:  est.Tokens + promptTokens*est.Segments,
+			OutputTokens: est.Tokens,
+			Currency:     p.Currency,
+		}
+		ce.Cost = float64(ce.InputTokens)/1000*p.InputPer1K + float64(ce.OutputTokens)/1000*p.OutputPer1K
+		est.Costs = append(est.Costs, ce)
+	}
+	return est
+}
+
+// EstimateTokens 粗略估算文本的 token 数:
+// 每个中日韩字符记为 1 个 token, 其余字符每 4 个记为 1 个 token
+func EstimateTokens(s string) int {
+	cjk, other := 0, 0
+	for _, r := range s {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + int(math.Ceil(float64(other)/4))
+}
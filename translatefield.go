@@ -0,0 +1,184 @@
+package docx
+
+import "strings"
+
+// field 是段落中的一个域 (如 PAGE, REF, DATE), 包括从 begin 到 end 的全部内容
+type field struct {
+	children []interface{} // children 是域的全部内容, 通常都是 Run
+	result   int           // result 是域结果的第一个 Run 在 children 中的位置, 域结果不翻译时为 -1
+	text     string        // text 是域结果的文字
+}
+
+// fieldGroup 是段落中不属于任何域的一项内容, 或一个域
+type fieldGroup struct {
+	child interface{}
+	field *field
+}
+
+// translatableFields 是结果为正文文字的域, 翻译其结果; 其余的域 (如 PAGE, DATE, SEQ) 原样保留
+var translatableFields = map[string]struct{}{
+	"HYPERLINK": {}, "REF": {}, "STYLEREF": {}, "QUOTE": {},
+	"TITLE": {}, "SUBJECT": {}, "KEYWORDS": {}, "COMMENTS": {}, "DOCPROPERTY": {},
+}
+
+// fieldKind 返回域指令的名称, 如 " PAGEREF _Toc1 \h " 返回 PAGEREF
+func fieldKind(instr string) string {
+	fields := strings.Fields(instr)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[0])
+}
+
+// groupFields 将段落的内容按域分组, 域的指令与结构不会被拆开
+//
+// 只有完整位于段落中、没有嵌套、结果只有文字且属于 translatableFields 的域翻译其结果;
+// 跨段落的域 (如目录) 在每个段落中的部分都原样保留.
+func groupFields(p *Paragraph) []fieldGroup {
+	var (
+		groups []fieldGroup
+		cur    *field
+		depth  int
+		broken bool // broken 表示域有嵌套或不完整
+		sep    int  // sep 是 separate 所在的内容在 cur.children 中的位置, -1 表示没有
+		instr  strings.Builder
+	)
+	finish := func() {
+		cur.result = -1
+		if !broken && sep >= 0 && sep+1 < len(cur.children)-1 {
+			if _, ok := translatableFields[fieldKind(instr.String())]; ok {
+				cur.text, ok = resultText(cur.children[sep+1 : len(cur.children)-1])
+				if ok && strings.TrimSpace(cur.text) != "" {
+					cur.result = sep + 1
+				}
+			}
+		}
+		groups = append(groups, fieldGroup{field: cur})
+		cur = nil
+	}
+	for _, child := range p.Children {
+		run, isRun := child.(*Run)
+		if depth == 0 && (!isRun || !hasFldChar(run)) {
+			groups = append(groups, fieldGroup{child: child})
+			continue
+		}
+		if cur == nil {
+			cur, broken, sep = &field{}, false, -1
+			instr.Reset()
+		}
+		cur.children = append(cur.children, child)
+		if !isRun {
+			continue
+		}
+		instrBefore := depth == 1 && sep < 0
+		for _, grandChild := range run.Children {
+			fc, ok := grandChild.(*FldChar)
+			if !ok {
+				continue
+			}
+			switch fc.FldCharType {
+			case "begin":
+				depth++
+				if depth > 1 {
+					broken = true
+				}
+			case "separate":
+				if depth == 1 && sep < 0 {
+					sep = len(cur.children) - 1
+				}
+			case "end":
+				depth--
+				if depth < 0 {
+					// 域开始于之前的段落, 段落开头到这里的内容都属于这个域
+					var prior []interface{}
+					for _, g := range groups {
+						if g.field != nil {
+							prior = append(prior, g.field.children...)
+						} else {
+							prior = append(prior, g.child)
+						}
+					}
+					cur.children = append(prior, cur.children...)
+					groups = groups[:0]
+					depth, broken = 0, true
+				}
+			}
+		}
+		if instrBefore || depth == 1 && sep < 0 {
+			instr.WriteString(runInstr(run))
+		}
+		if depth == 0 {
+			finish()
+		}
+	}
+	if cur != nil {
+		// 域延续到之后的段落
+		broken = true
+		finish()
+	}
+	return groups
+}
+
+// runInstr 拼接 Run 中的域指令
+func runInstr(r *Run) string {
+	s := r.InstrText
+	for _, child := range r.Children {
+		if in, ok := child.(*InstrText); ok {
+			s += in.Text
+		}
+	}
+	return s
+}
+
+// resultText 拼接域结果的文字, 域结果中有文字以外的内容时返回 false
+func resultText(children []interface{}) (string, bool) {
+	var sb strings.Builder
+	for _, child := range children {
+		run, ok := child.(*Run)
+		if !ok {
+			return "", false
+		}
+		for _, grandChild := range run.Children {
+			text, ok := grandChild.(*Text)
+			if !ok {
+				return "", false
+			}
+			sb.WriteString(text.Text)
+		}
+	}
+	return sb.String(), true
+}
+
+// fieldRuns 返回段落中属于域的 Run
+func fieldRuns(p *Paragraph) map[*Run]bool {
+	var runs map[*Run]bool
+	for _, g := range groupFields(p) {
+		if g.field == nil {
+			continue
+		}
+		for _, child := range g.field.children {
+			if run, ok := child.(*Run); ok {
+				if runs == nil {
+					runs = make(map[*Run]bool)
+				}
+				runs[run] = true
+			}
+		}
+	}
+	return runs
+}
+
+// fillField 将域追加到新段落; text 非 nil 且域结果可以翻译时, 以 text (见 textChildren) 替换域的结果
+func (sg *segment) fillField(f *field, text []interface{}) {
+	children := f.children
+	if text != nil && f.result >= 0 {
+		first := f.children[f.result].(*Run)
+		children = make([]interface{}, 0, f.result+2)
+		children = append(children, f.children[:f.result]...)
+		children = append(children, &Run{RunProperties: first.RunProperties, Children: text})
+		children = append(children, f.children[len(f.children)-1])
+	}
+	tmp := Paragraph{Children: children, file: sg.src.file}
+	np := tmp.copymedia(sg.dst.file)
+	sg.dst.Children = append(sg.dst.Children, np.Children...)
+}
@@ -0,0 +1,108 @@
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// addField 在段落中添加一个域
+func addField(p *Paragraph, instr, result string) {
+	p.Children = append(p.Children,
+		&Run{Children: []interface{}{&FldChar{FldCharType: "begin"}}},
+		&Run{InstrText: instr},
+		&Run{Children: []interface{}{&FldChar{FldCharType: "separate"}}},
+		&Run{Children: []interface{}{&Text{Text: result}}},
+		&Run{Children: []interface{}{&FldChar{FldCharType: "end"}}},
+	)
+}
+
+// fieldSummary 返回段落中的域指令、域标记与文字
+func fieldSummary(p *Paragraph) string {
+	var sb strings.Builder
+	for _, child := range p.Children {
+		run, ok := child.(*Run)
+		if !ok {
+			continue
+		}
+		if run.InstrText != "" {
+			sb.WriteString("{" + strings.TrimSpace(run.InstrText) + "}")
+		}
+		for _, grandChild := range run.Children {
+			switch o := grandChild.(type) {
+			case *FldChar:
+				sb.WriteString("<" + o.FldCharType + ">")
+			case *Text:
+				sb.WriteString(o.Text)
+			}
+		}
+	}
+	return sb.String()
+}
+
+func TestTranslateDocxFields(t *testing.T) {
+	doc := New().WithDefaultTheme()
+	p := doc.AddParagraph()
+	p.AddText("第")
+	addField(p, " PAGE ", "3")
+	p.AddText("页，参见")
+	addField(p, ` REF _Ref1 \h `, "总则")
+	p.AddText("。")
+	// 跨段落的域
+	toc := doc.AddParagraph()
+	toc.AddText("目录")
+	toc.Children = append(toc.Children,
+		&Run{Children: []interface{}{&FldChar{FldCharType: "begin"}}},
+		&Run{InstrText: ` INDEX \c "2" `},
+		&Run{Children: []interface{}{&FldChar{FldCharType: "separate"}}},
+	)
+	end := doc.AddParagraph()
+	end.Children = append(end.Children, &Run{Children: []interface{}{&FldChar{FldCharType: "end"}}})
+
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sources []string
+	words := strings.NewReplacer("第", "Page ", "页，参见", ", see ", "总则", "General Provisions", "。", ".", "目录", "Contents")
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		sources = append(sources, text)
+		return words.Replace(text), nil
+	}))
+	newDoc, err := tr.TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 2 || sources[0] != "第⟪1/⟫页，参见⟪2⟫总则⟪/2⟫。" || sources[1] != "目录⟪1/⟫" {
+		t.Fatalf("unexpected sources: %q", sources)
+	}
+	items := newDoc.Document.Body.Items
+	want := []string{
+		"Page <begin>{PAGE}<separate>3<end>, see <begin>{REF _Ref1 \\h}<separate>General Provisions<end>.",
+		"Contents<begin>{INDEX \\c \"2\"}<separate>",
+		"<end>",
+	}
+	for i, w := range want {
+		if s := fieldSummary(items[i].(*Paragraph)); s != w {
+			t.Errorf("paragraph %d: got %q, want %q", i, s, w)
+		}
+	}
+
+	// 原地翻译不修改域
+	doc, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources = nil
+	if err := tr.TranslateDocxInPlace(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	if s := fieldSummary(doc.Document.Body.Items[0].(*Paragraph)); s != "Page , see .<begin>{PAGE}<separate>3<end><begin>{REF _Ref1 \\h}<separate>总则<end>" {
+		t.Fatalf("unexpected in-place result: %q", s)
+	}
+}
@@ -0,0 +1,84 @@
+package docx
+
+import "strings"
+
+// FontMap 将原文 Run 的字体 (w:rFonts 的 ascii, 其次 hAnsi) 映射为译文的东亚字体 (eastAsia),
+// 字体名不区分大小写; 键 "" 是没有对应项或 Run 没有指定字体时使用的字体, 没有 "" 键时这些 Run 保持不变
+type FontMap map[string]string
+
+// DefaultFontMaps 是中文、日文与韩文常用的东亚字体, 可以直接用于 WithFontMap, 或在其副本上增改
+var DefaultFontMaps = map[string]FontMap{
+	"zh": {"": "宋体", "calibri": "等线", "arial": "微软雅黑", "times new roman": "宋体"},
+	"ja": {"": "Yu Mincho", "calibri": "Yu Gothic", "arial": "Yu Gothic", "times new roman": "Yu Mincho"},
+	"ko": {"": "Batang", "calibri": "Malgun Gothic", "arial": "Malgun Gothic", "times new roman": "Batang"},
+}
+
+// WithFontMap 设置译文为 lang (中文、日文或韩文) 时 Run 的东亚字体, 使译文不会因原文的西文字体缺少汉字而显示为方框
+// 或回退到不合适的默认字体; 默认不修改字体. 对其他语言设置的映射不生效.
+func (t *Translator) WithFontMap(lang string, m FontMap) *Translator {
+	if t.fontMaps == nil {
+		t.fontMaps = make(map[string]FontMap)
+	}
+	lower := make(FontMap, len(m))
+	for k, v := range m {
+		lower[strings.ToLower(k)] = v
+	}
+	t.fontMaps[LanguageCode(lang)] = lower
+	return t
+}
+
+// fontMap 返回译文为 targetLanguage 时的字体映射, 没有时返回 nil
+func (t *Translator) fontMap(targetLanguage string) FontMap {
+	switch code := LanguageCode(targetLanguage); code {
+	case "zh", "ja", "ko":
+		return t.fontMaps[code]
+	default:
+		return nil
+	}
+}
+
+// mapFont 按 fonts 设置 Run 的东亚字体; Run 的格式可能与原文档共用, 先复制再修改
+func mapFont(r *Run, fonts FontMap) {
+	if len(fonts) == 0 {
+		return
+	}
+	var source string
+	if r.RunProperties != nil && r.RunProperties.Fonts != nil {
+		source = r.RunProperties.Fonts.ASCII
+		if source == "" {
+			source = r.RunProperties.Fonts.HAnsi
+		}
+	}
+	font, ok := fonts[strings.ToLower(source)]
+	if !ok {
+		font, ok = fonts[""]
+	}
+	if !ok || font == "" {
+		return
+	}
+	var rp RunProperties
+	if r.RunProperties != nil {
+		rp = *r.RunProperties
+	}
+	var rf RunFonts
+	if rp.Fonts != nil {
+		rf = *rp.Fonts
+	}
+	rf.EastAsia = font
+	rp.Fonts = &rf
+	r.RunProperties = &rp
+}
+
+// mapFonts 对 children 中的 Run、超链接与插入修订中的 Run 调用 mapFont
+func mapFonts(children []interface{}, fonts FontMap) {
+	for _, child := range children {
+		switch o := child.(type) {
+		case *Run:
+			mapFont(o, fonts)
+		case *Hyperlink:
+			mapFont(&o.Run, fonts)
+		case *Ins:
+			mapFonts(o.Children, fonts)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedFormat 无法识别文件格式, 或该格式没有可用的翻译流程
+var ErrUnsupportedFormat = errors.New("unsupported file format")
+
+// FileFormat 是 TranslateFile 可以识别的一种文件格式
+type FileFormat struct {
+	// Name 是格式名, 如 "docx", 同时也是 TranslateFileOptions.Format 的取值
+	Name string
+	// Sniff 判断 data 是否为此格式
+	Sniff func(data []byte) bool
+	// Translate 将 data 翻译为 targetLanguage 并写入 w, 为 nil 时只能识别不能翻译
+	Translate func(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error
+}
+
+var (
+	formatsMu sync.RWMutex
+	// formats 按识别的优先级排列, 通用的纯文本格式在最后
+	formats = []*FileFormat{
+		{Name: "docx", Sniff: zipSniffer("word/document.xml"), Translate: translateDocxFile},
+		{Name: "pptx", Sniff: zipSniffer("ppt/presentation.xml"), Translate: translatePptxFile},
+		{Name: "xlsx", Sniff: zipSniffer("xl/workbook.xml"), Translate: translateXlsxFile},
+		{Name: "odt", Sniff: odfSniffer("application/vnd.oasis.opendocument.text"), Translate: translateOdtFile},
+		{Name: "epub", Sniff: odfSniffer("application/epub+zip"), Translate: translateEpubFile},
+		{Name: "doc", Sniff: sniffDoc, Translate: translateDocFile},
+		{Name: "rtf", Sniff: sniffRTF, Translate: translateRTFFile},
+		{Name: "html", Sniff: sniffHTML, Translate: translateHTMLFile},
+		{Name: "vtt", Sniff: sniffVTT, Translate: translateSubtitleFile},
+		{Name: "srt", Sniff: sniffSRT, Translate: translateSubtitleFile},
+		{Name: "md", Sniff: sniffMarkdown, Translate: translateMarkdownFile},
+		{Name: "txt", Sniff: utf8.Valid, Translate: translateTextFile},
+	}
+)
+
+// RegisterFormat 注册一种文件格式, 使 TranslateFile 可以识别并翻译它
+//
+// 与已注册格式同名时替换之 (保留其识别顺序), 否则新格式优先于所有已注册的格式识别.
+func RegisterFormat(f FileFormat) {
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+	for i, old := range formats {
+		if old.Name == f.Name {
+			formats[i] = &f
+			return
+		}
+	}
+	formats = append([]*FileFormat{&f}, formats...)
+}
+
+// SniffFormat 返回 data 的格式名, 无法识别时返回空串
+func SniffFormat(data []byte) string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	for _, f := range formats {
+		if f.Sniff != nil && f.Sniff(data) {
+			return f.Name
+		}
+	}
+	return ""
+}
+
+func lookupFormat(name string) *FileFormat {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+	for _, f := range formats {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// TranslateFileOptions 是 TranslateFile 的参数
+type TranslateFileOptions struct {
+	TargetLanguage string
+	// Format 指定输入的格式名, 为空时自动识别
+	Format string
+}
+
+// TranslateFile 识别 r 中文件的格式并交给对应的翻译流程, 译文写入 w,
+// 使调用方可以用同一个入口处理混杂的文档库
+//
+// 无法识别的格式或没有翻译流程的格式返回包装了 ErrUnsupportedFormat 的错误,
+// 超出 WithLimits 设置的限制时返回 *LimitError. 加密的文档先用 WithPassword 设置的密码解密, 见 Decrypt.
+func (t *Translator) TranslateFile(ctx context.Context, r io.Reader, w io.Writer, opts TranslateFileOptions) error {
+	maxSize := t.limits.MaxFileSize
+	if maxSize > 0 {
+		r = io.LimitReader(r, maxSize+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return &LimitError{Kind: LimitFileSize, Max: maxSize, Actual: int64(len(data))}
+	}
+	if IsEncrypted(data) {
+		if t.password == "" {
+			return ErrPasswordRequired
+		}
+		if data, err = Decrypt(data, t.password); err != nil {
+			return err
+		}
+	}
+	name := opts.Format
+	if name == "" {
+		name = SniffFormat(data)
+		if name == "" {
+			return ErrUnsupportedFormat
+		}
+	}
+	f := lookupFormat(strings.ToLower(name))
+	if f == nil || f.Translate == nil {
+		return fmt.Errorf("%w: %s", ErrUnsupportedFormat, name)
+	}
+	t.log().Log(LogLevelDebug, "识别文件格式", "format", f.Name, "size", len(data))
+	return f.Translate(ctx, t, data, w, opts.TargetLanguage)
+}
+
+func translateDocxFile(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error {
+	if err := t.checkDocxMedia(data); err != nil {
+		return err
+	}
+	var doc *Docx
+	var err error
+	if t.lenient {
+		var warnings []ParseWarning
+		doc, warnings, err = ParseLenient(bytes.NewReader(data), int64(len(data)))
+		for _, w := range warnings {
+			t.log().Log(LogLevelWarn, "解析时跳过了文档中的问题", "part", w.Part, "element", w.Element, "err", w.Err)
+		}
+	} else {
+		doc, err = Parse(bytes.NewReader(data), int64(len(data)))
+	}
+	if err != nil {
+		return err
+	}
+	return t.writeTranslatedDocx(ctx, doc, w, targetLanguage)
+}
+
+// writeTranslatedDocx 翻译 doc 并将译文写入 w, 按设置检查与加密译文
+func (t *Translator) writeTranslatedDocx(ctx context.Context, doc *Docx, w io.Writer, targetLanguage string) error {
+	newDoc, err := t.TranslateDocxContext(ctx, doc, targetLanguage)
+	if newDoc == nil {
+		return err
+	}
+	if t.validate {
+		if verr := newDoc.Validate(); verr != nil {
+			return verr
+		}
+	}
+	if t.outputPassword != "" {
+		var buf bytes.Buffer
+		if _, werr := newDoc.WriteTo(&buf); werr != nil {
+			return werr
+		}
+		encrypted, werr := Encrypt(buf.Bytes(), t.outputPassword)
+		if werr != nil {
+			return werr
+		}
+		if _, werr = w.Write(encrypted); werr != nil {
+			return werr
+		}
+		return err
+	}
+	_, werr := newDoc.WriteTo(w)
+	if werr != nil {
+		return werr
+	}
+	return err // ErrorPolicyCollect 下的 SegmentErrors
+}
+
+// zipSniffer 返回判断 zip 包中是否含有 name 的函数
+func zipSniffer(name string) func(data []byte) bool {
+	return func(data []byte) bool {
+		if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+			return false
+		}
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return false
+		}
+		for _, f := range zr.File {
+			if f.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// readZipFile 读取 zip 包中的文件 f
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// writeZipParts 将 zip 包 zr 写入 w, parts 中的文件换用新的内容, 其他文件连同压缩方式原样复制, 文件顺序不变
+func writeZipParts(w io.Writer, zr *zip.Reader, parts map[string][]byte) error {
+	zw := zip.NewWriter(w)
+	for _, f := range zr.File {
+		content, ok := parts[f.Name]
+		if !ok {
+			if err := zw.Copy(f); err != nil {
+				return err
+			}
+			continue
+		}
+		fw, err := zw.Create(f.Name)
+		if err != nil {
+			return err
+		}
+		if _, err = fw.Write(content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// odfSniffer 返回判断 OpenDocument 包的 mimetype 是否为 mime 的函数,
+// 按规范 mimetype 是包中第一个未压缩的文件, 因此直接比较文件头
+func odfSniffer(mime string) func(data []byte) bool {
+	head := []byte("mimetype" + mime)
+	return func(data []byte) bool {
+		return bytes.HasPrefix(data, []byte("PK\x03\x04")) && len(data) > 30 && bytes.HasPrefix(data[30:], head)
+	}
+}
+
+// sniffSRT 判断是否为 SRT 字幕: 第一个非空行为序号, 第二行为时间轴
+func sniffSRT(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	lines := strings.SplitN(strings.TrimLeft(strings.TrimPrefix(string(data), "\ufeff"), "\r\n"), "\n", 3)
+	if len(lines) < 2 {
+		return false
+	}
+	seq := strings.TrimSpace(lines[0])
+	if seq == "" || strings.Trim(seq, "0123456789") != "" {
+		return false
+	}
+	return strings.Contains(lines[1], "-->")
+}
+
+// sniffMarkdown 判断是否为 Markdown: 含有标题, 代码块或链接等常见标记
+func sniffMarkdown(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "# "), strings.HasPrefix(line, "## "), strings.HasPrefix(line, "```"):
+			return true
+		case strings.Contains(line, "](") && strings.Contains(line, "["):
+			return true
+		}
+	}
+	return false
+}
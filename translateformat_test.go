@@ -0,0 +1,105 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func zipOf(t *testing.T, names ...string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range names {
+		if _, err := zw.Create(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSniffFormat(t *testing.T) {
+	var docx bytes.Buffer
+	if _, err := newTestDoc("hello").WriteTo(&docx); err != nil {
+		t.Fatal(err)
+	}
+	var odt bytes.Buffer
+	zw := zip.NewWriter(&odt)
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.WriteString(w, "application/vnd.oasis.opendocument.text")
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for want, data := range map[string][]byte{
+		"docx": docx.Bytes(),
+		"pptx": zipOf(t, "[Content_Types].xml", "ppt/presentation.xml"),
+		"xlsx": zipOf(t, "xl/workbook.xml"),
+		"odt":  odt.Bytes(),
+		"html": []byte("<!DOCTYPE html>\n<p>你好</p>"),
+		"srt":  []byte("1\n00:00:01,000 --> 00:00:02,000\n你好\n"),
+		"md":   []byte("# 标题\n\n正文"),
+		"txt":  []byte("只是一段文字"),
+		"":     {0xff, 0xfe, 0x00},
+	} {
+		if got := SniffFormat(data); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestTranslateFile(t *testing.T) {
+	var in bytes.Buffer
+	if _, err := newTestDoc("hello").WriteTo(&in); err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	var out bytes.Buffer
+	if err := tr.TranslateFile(context.Background(), bytes.NewReader(in.Bytes()), &out, TranslateFileOptions{TargetLanguage: "English"}); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := doc.Document.Body.Items[0].(*Paragraph).String(); s != "HELLO" {
+		t.Fatal("unexpected translation:", s)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err = tr.TranslateFile(ctx, bytes.NewReader(in.Bytes()), io.Discard, TranslateFileOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatal("expected context.Canceled, got", err)
+	}
+	err = tr.TranslateFile(context.Background(), bytes.NewReader(zipOf(t, "ppt/presentation.xml")), io.Discard, TranslateFileOptions{Format: "key"})
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatal("expected ErrUnsupportedFormat, got", err)
+	}
+
+	RegisterFormat(FileFormat{
+		Name:  "test-upper",
+		Sniff: func(data []byte) bool { return bytes.HasPrefix(data, []byte("UPPER:")) },
+		Translate: func(_ context.Context, t *Translator, data []byte, w io.Writer, target string) error {
+			s, err := t.translateSegment(string(data[6:]), "", target)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(w, s)
+			return err
+		},
+	})
+	out.Reset()
+	if err = tr.TranslateFile(context.Background(), strings.NewReader("UPPER:abc"), &out, TranslateFileOptions{}); err != nil || out.String() != "ABC" {
+		t.Fatal("unexpected custom format result:", out.String(), err)
+	}
+}
@@ -0,0 +1,102 @@
+package docx
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Glossary 是术语表, 原文中出现的术语按指定的译法翻译. 可以并发调用.
+//
+// 翻译一段文字时, 其中出现的术语与译法作为翻译要求附加在 Dashscope 的系统提示词之后, 或交给实现了 HintedProvider 的 Provider;
+// 术语表针对一种目标语言, 翻译为多种语言时为每种语言分别设置.
+type Glossary struct {
+	mu    sync.RWMutex
+	terms []GlossaryTerm
+}
+
+// GlossaryTerm 是术语表中的一个术语
+type GlossaryTerm struct {
+	Source string // Source 是原文中的术语, 拉丁字母不区分大小写
+	Target string // Target 是术语的译法
+}
+
+// NewGlossary 创建一个包含 terms 的术语表
+func NewGlossary(terms ...GlossaryTerm) *Glossary {
+	g := &Glossary{}
+	for _, term := range terms {
+		g.Add(term.Source, term.Target)
+	}
+	return g
+}
+
+// Add 添加一个术语, 原文或译法为空时忽略
+func (g *Glossary) Add(source, target string) {
+	source, target = strings.TrimSpace(source), strings.TrimSpace(target)
+	if source == "" || target == "" {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.terms = append(g.terms, GlossaryTerm{Source: source, Target: target})
+}
+
+// Load 从 r 中读取 CSV 格式的术语表: 每行的前两列是原文与译法, 以 # 开头的行与少于两列的行被忽略;
+// 第一行是 source,target 这样的表头时也被忽略
+func (g *Glossary) Load(r io.Reader) error {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(record) < 2 {
+			continue
+		}
+		source := strings.TrimPrefix(record[0], "\ufeff")
+		if first && strings.EqualFold(strings.TrimSpace(source), "source") {
+			continue
+		}
+		g.Add(source, record[1])
+	}
+}
+
+// Terms 返回术语表中的所有术语
+func (g *Glossary) Terms() []GlossaryTerm {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return append([]GlossaryTerm(nil), g.terms...)
+}
+
+// hint 返回 text 中出现的术语对应的翻译要求, 没有术语时返回空串
+func (g *Glossary) hint(text string) string {
+	if g == nil {
+		return ""
+	}
+	lower := strings.ToLower(text)
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var pairs []string
+	for _, term := range g.terms {
+		if strings.Contains(lower, strings.ToLower(term.Source)) {
+			pairs = append(pairs, term.Source+" → "+term.Target)
+		}
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "以下术语必须按指定的译法翻译: " + strings.Join(pairs, "; ") + "."
+}
+
+// WithGlossary 设置翻译使用的术语表, 见 Glossary; 术语表不同的译文在缓存中互不影响
+func (t *Translator) WithGlossary(g *Glossary) *Translator {
+	t.glossary = g
+	return t
+}
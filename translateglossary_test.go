@@ -0,0 +1,33 @@
+package docx
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGlossary(t *testing.T) {
+	g := NewGlossary()
+	if err := g.Load(strings.NewReader("source,target\n# comment\nApple Pay, Apple Pay\n云服务器,cloud server\nonly one column\n")); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Terms()) != 2 {
+		t.Fatal("unexpected terms:", g.Terms())
+	}
+	hints := make(map[string]string)
+	tr := NewTranslator("", "").WithGlossary(g).WithProvider(hintRecorderFunc(func(text, hint string) (string, error) {
+		hints[text] = hint
+		return strings.ToUpper(text), nil
+	}))
+	if _, err := tr.TranslateDocx(newTestDoc("购买云服务器", "支持 apple pay", "其他"), "English"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(hints["购买云服务器"], "云服务器 → cloud server") || strings.Contains(hints["购买云服务器"], "Apple Pay") {
+		t.Error("unexpected hint:", hints["购买云服务器"])
+	}
+	if !strings.Contains(hints["支持 apple pay"], "Apple Pay → Apple Pay") {
+		t.Error("unexpected hint:", hints["支持 apple pay"])
+	}
+	if strings.Contains(hints["其他"], "术语") {
+		t.Error("unexpected hint:", hints["其他"])
+	}
+}
@@ -0,0 +1,496 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// htmlSpace 是 HTML 中的空白字符
+const htmlSpace = " \t\r\n\f"
+
+const (
+	// htmlInlineHint 是网页段落中含有行内元素时附加的翻译要求
+	htmlInlineHint = "⟪1⟫ 与 ⟪/1⟫ 这样成对的标记包住的是链接、强调等行内元素中的文字, 标记可以嵌套: 译文中原样保留每一对标记, 并用它包住对应的译文; " +
+		"⟪2/⟫ 这样单独的标记代表图片、换行、代码等不翻译的内容, 原样保留在译文中对应的位置."
+	// htmlAttrHint 是网页元素的属性附加的翻译要求
+	htmlAttrHint = "这段文字是网页元素的属性, 如图片的替代文字、提示文字或页面的描述: 译文要简洁, 不要添加说明."
+)
+
+// htmlKind 是 htmlToken 的种类
+type htmlKind int
+
+const (
+	htmlText  htmlKind = iota // htmlText 是文字
+	htmlStart                 // htmlStart 是开始标签
+	htmlEnd                   // htmlEnd 是结束标签
+	htmlOther                 // htmlOther 是注释、文档类型声明与脚本和样式表的内容
+)
+
+var (
+	// htmlBlocks 是分隔翻译单元的块级元素, 其余的元素 (包括自定义元素) 随所在的段落翻译
+	htmlBlocks = map[string]bool{}
+	// htmlAtomic 是不翻译的行内元素, 连同其内容用一个标记表示
+	htmlAtomic = map[string]bool{"code": true, "kbd": true, "samp": true, "var": true, "svg": true, "math": true, "script": true, "style": true, "textarea": true}
+	// htmlVoid 是没有结束标签的元素
+	htmlVoid = map[string]bool{}
+	// htmlRaw 是内容不是 HTML 的元素, title 的内容是文字, 其他元素的内容原样保留
+	htmlRaw = map[string]bool{"script": true, "style": true, "textarea": true, "title": true}
+
+	// htmlAttrRe 匹配需要翻译的属性, 第 1 组是属性名, 第 2 或第 3 组是属性值
+	htmlAttrRe = regexp.MustCompile(`(?i)\s(alt|title|placeholder|aria-label|content|lang|xml:lang)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	// htmlNoTranslateRe 匹配标明内容不翻译的属性
+	htmlNoTranslateRe = regexp.MustCompile(`(?i)\s(?:translate\s*=\s*["']?no\b|class\s*=\s*["'][^"']*\bnotranslate\b)`)
+	// htmlDescriptionRe 匹配页面描述与关键词的 meta 元素
+	htmlDescriptionRe = regexp.MustCompile(`(?i)\sname\s*=\s*["']?(?:description|keywords)\b`)
+	// htmlSpaceRe 匹配连续的空白, 不在 pre 中时按一个空格显示
+	htmlSpaceRe = regexp.MustCompile(`[ \t\r\n\f]+`)
+	// htmlTextEscaper 转义译文中的文字
+	htmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	// htmlAttrEscaper 转义译文中的属性值
+	htmlAttrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;")
+)
+
+func init() {
+	for _, name := range strings.Fields("address article aside blockquote body caption center dd details dialog div dl dt fieldset figcaption figure " +
+		"footer form h1 h2 h3 h4 h5 h6 head header hgroup hr html legend li main menu nav ol optgroup option p pre section summary " +
+		"table tbody td tfoot th thead title tr ul button label noscript template iframe select video audio object canvas") {
+		htmlBlocks[name] = true
+	}
+	for _, name := range strings.Fields("area base br col embed hr img input link meta param source track wbr") {
+		htmlVoid[name] = true
+	}
+}
+
+// TranslateHTML 将网页 r 翻译为 targetLanguage 并写入 w, ctx 被取消时返回 ctx.Err()
+//
+// 按块级元素 (段落、标题、列表项、表格单元格等) 拆分翻译单元, 行内元素 (链接、强调等) 用标记表示, 其标签与属性原样保留;
+// 代码、脚本、样式表、注释与带有 translate="no" 或 class="notranslate" 的元素不翻译. 图片的替代文字、title、placeholder
+// 与 aria-label 属性以及页面的描述单独翻译, html 元素的 lang 与 xml:lang 属性改为目标语言. pre 中的空白保持不变, 其他文字中连续的空白按一个空格翻译.
+// 与 TranslateDocx 一样使用缓存、术语表与 WithErrorPolicy 等设置, ErrorPolicyCollect 下失败的段落保留原文并返回 SegmentErrors.
+func (t *Translator) TranslateHTML(ctx context.Context, r io.Reader, w io.Writer, targetLanguage string) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	segs, render := t.htmlSegments(string(data), targetLanguage)
+	report, err := t.translateSegments(ctx, segs, targetLanguage)
+	if report == nil {
+		return err
+	}
+	if _, werr := io.WriteString(w, render()); werr != nil {
+		return werr
+	}
+	return err // ErrorPolicyCollect 下的 SegmentErrors
+}
+
+// htmlSegments 返回网页 s 的翻译单元, 以及在填入译文后输出网页的函数
+func (t *Translator) htmlSegments(s, targetLanguage string) ([]*segment, func() string) {
+	tokens := scanHTML(s)
+	markNoTranslate(tokens)
+	segs := t.htmlAttrSegments(tokens, targetLanguage)
+	pieces, paras := splitHTML(tokens)
+	for _, p := range paras {
+		if sg := t.htmlSegment(p); sg != nil {
+			segs = append(segs, sg)
+		}
+	}
+	return segs, func() string {
+		var sb strings.Builder
+		for _, p := range pieces {
+			switch p := p.(type) {
+			case *htmlToken:
+				sb.WriteString(p.render())
+			case *htmlPara:
+				sb.WriteString(p.render())
+			}
+		}
+		return sb.String()
+	}
+}
+
+func translateHTMLFile(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error {
+	return t.TranslateHTML(ctx, bytes.NewReader(data), w, targetLanguage)
+}
+
+// sniffHTML 判断是否为网页: 以文档类型声明或 html 元素开头
+func sniffHTML(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	head := bytes.ToLower(bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\ufeff")), " \t\r\n"))
+	return bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html"))
+}
+
+// htmlToken 是网页中的一段文字、一个标签或不翻译的内容
+type htmlToken struct {
+	kind  htmlKind
+	raw   string
+	name  string      // name 是小写的标签名
+	attrs []*htmlAttr // attrs 是需要翻译的属性
+	keep  bool        // keep 表示位于不翻译的块级元素中, 见 markNoTranslate
+}
+
+// htmlAttr 是标签中需要翻译的属性, 译文替换 raw 中 [start, end) 的属性值
+type htmlAttr struct {
+	start, end int
+	translated *string
+}
+
+// render 返回放入属性的译文后的标签
+func (tk *htmlToken) render() string {
+	s := tk.raw
+	for i := len(tk.attrs) - 1; i >= 0; i-- {
+		if a := tk.attrs[i]; a.translated != nil {
+			s = s[:a.start] + htmlAttrEscaper.Replace(*a.translated) + s[a.end:]
+		}
+	}
+	return s
+}
+
+// scanHTML 将网页 s 拆分为 htmlToken, 无法识别的 < 作为文字
+func scanHTML(s string) []*htmlToken {
+	var tokens []*htmlToken
+	text := func(raw string) {
+		if n := len(tokens); n > 0 && tokens[n-1].kind == htmlText {
+			tokens[n-1].raw += raw
+			return
+		}
+		tokens = append(tokens, &htmlToken{kind: htmlText, raw: raw})
+	}
+	for i := 0; i < len(s); {
+		if s[i] != '<' {
+			j := strings.IndexByte(s[i:], '<')
+			if j < 0 {
+				j = len(s) - i
+			}
+			text(s[i : i+j])
+			i += j
+			continue
+		}
+		rest := s[i:]
+		switch {
+		case strings.HasPrefix(rest, "<!--"):
+			j := strings.Index(rest, "-->")
+			if j < 0 {
+				j = len(rest) - 3
+			}
+			tokens = append(tokens, &htmlToken{kind: htmlOther, raw: rest[:j+3]})
+			i += j + 3
+		case strings.HasPrefix(rest, "<!"), strings.HasPrefix(rest, "<?"):
+			j := strings.IndexByte(rest, '>')
+			if j < 0 {
+				j = len(rest) - 1
+			}
+			tokens = append(tokens, &htmlToken{kind: htmlOther, raw: rest[:j+1]})
+			i += j + 1
+		case len(rest) > 2 && rest[1] == '/' && isASCIILetter(rest[2]), len(rest) > 1 && isASCIILetter(rest[1]):
+			j := htmlTagEnd(rest)
+			tk := &htmlToken{kind: htmlStart, raw: rest[:j]}
+			if rest[1] == '/' {
+				tk.kind = htmlEnd
+			}
+			name := strings.TrimPrefix(rest[1:], "/")
+			tk.name = strings.ToLower(name[:htmlNameLen(name)])
+			tokens = append(tokens, tk)
+			i += j
+			if tk.kind == htmlStart && htmlRaw[tk.name] {
+				k := strings.Index(strings.ToLower(s[i:]), "</"+tk.name)
+				if k < 0 {
+					k = len(s) - i
+				}
+				if k > 0 {
+					kind := htmlOther
+					if tk.name == "title" {
+						kind = htmlText
+					}
+					tokens = append(tokens, &htmlToken{kind: kind, raw: s[i : i+k]})
+				}
+				i += k
+			}
+		default:
+			text("<")
+			i++
+		}
+	}
+	return tokens
+}
+
+// htmlTagEnd 返回以 s 开头的标签的长度, 引号中的 > 不结束标签
+func htmlTagEnd(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '>':
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+func htmlNameLen(s string) int {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; !isASCIILetter(c) && (c < '0' || c > '9') && c != '-' && c != ':' {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// markNoTranslate 标记带有 translate="no" 或 class="notranslate" 的块级元素及其中的内容,
+// 行内元素在 htmlSegment 中用单独的标记表示
+func markNoTranslate(tokens []*htmlToken) {
+	for i, tk := range tokens {
+		if tk.kind != htmlStart || tk.keep || !htmlBlocks[tk.name] || !htmlNoTranslateRe.MatchString(tk.raw) {
+			continue
+		}
+		depth := 0
+		for _, inner := range tokens[i:] {
+			inner.keep = true
+			if inner.name != tk.name {
+				continue
+			}
+			if inner.kind == htmlStart {
+				depth++
+			} else if inner.kind == htmlEnd {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+	}
+}
+
+// htmlAttrSegments 返回标签中需要翻译的属性的翻译单元, html 元素的 lang 属性直接改为 targetLanguage 的语言标记
+func (t *Translator) htmlAttrSegments(tokens []*htmlToken, targetLanguage string) []*segment {
+	var segs []*segment
+	for _, tk := range tokens {
+		if tk.kind != htmlStart || tk.keep || htmlNoTranslateRe.MatchString(tk.raw) {
+			continue
+		}
+		for _, m := range htmlAttrRe.FindAllStringSubmatchIndex(tk.raw, -1) {
+			start, end := m[4], m[5]
+			if start < 0 {
+				start, end = m[6], m[7]
+			}
+			a := &htmlAttr{start: start, end: end}
+			switch name := strings.ToLower(tk.raw[m[2]:m[3]]); {
+			case name == "lang" || name == "xml:lang":
+				if tag := languageTag(targetLanguage); tag != "" && tk.name == "html" {
+					a.translated = &tag
+					tk.attrs = append(tk.attrs, a)
+				}
+				continue
+			case name == "content" && (tk.name != "meta" || !htmlDescriptionRe.MatchString(tk.raw)):
+				continue
+			}
+			text := html.UnescapeString(tk.raw[start:end])
+			if !hasLetter(text) || t.skipText(text) {
+				continue
+			}
+			tk.attrs = append(tk.attrs, a)
+			segs = append(segs, &segment{text: text, hint: htmlAttrHint, set: func(s string) { a.translated = &s }})
+		}
+	}
+	return segs
+}
+
+// htmlPara 是网页中两个块级元素之间的一段内容
+type htmlPara struct {
+	tokens     []*htmlToken
+	pre        bool // pre 表示位于 pre 元素中, 空白保持不变
+	inlines    []*htmlInline
+	lead, tail string // lead 与 tail 是译文两侧的空白
+	translated *string
+}
+
+// htmlInline 是段落中的行内元素, 在翻译单元中用标记表示
+type htmlInline struct {
+	tokens     []*htmlToken // tokens 非空时是不翻译的内容, 用单独的标记表示
+	start, end *htmlToken   // start 与 end 是用成对的标记表示的元素的开始与结束标签
+}
+
+// splitHTML 按块级元素拆分 tokens, 返回依次输出的 *htmlToken 与 *htmlPara, 以及其中的段落
+func splitHTML(tokens []*htmlToken) (pieces []interface{}, paras []*htmlPara) {
+	var (
+		run []*htmlToken
+		pre int
+	)
+	flush := func() {
+		if len(run) > 0 {
+			p := &htmlPara{tokens: run, pre: pre > 0}
+			pieces = append(pieces, p)
+			paras = append(paras, p)
+			run = nil
+		}
+	}
+	for _, tk := range tokens {
+		if tk.keep || (tk.kind == htmlStart || tk.kind == htmlEnd) && htmlBlocks[tk.name] {
+			flush()
+			pieces = append(pieces, tk)
+			if tk.name == "pre" && tk.kind == htmlStart {
+				pre++
+			} else if tk.name == "pre" && pre > 0 {
+				pre--
+			}
+			continue
+		}
+		run = append(run, tk)
+	}
+	flush()
+	return pieces, paras
+}
+
+// htmlSegment 返回段落 p 的翻译单元, 没有需要翻译的文字时返回 nil
+func (t *Translator) htmlSegment(p *htmlPara) *segment {
+	// 配对行内元素的开始与结束标签, 没有配对的标签不翻译
+	match := make(map[int]int)
+	var stack []int
+	for i, tk := range p.tokens {
+		switch {
+		case tk.kind == htmlStart && !htmlVoid[tk.name] && !strings.HasSuffix(tk.raw, "/>"):
+			stack = append(stack, i)
+		case tk.kind == htmlEnd:
+			for k := len(stack) - 1; k >= 0; k-- {
+				if p.tokens[stack[k]].name == tk.name {
+					match[stack[k]] = i
+					stack = stack[:k]
+					break
+				}
+			}
+		}
+	}
+	var (
+		sb      strings.Builder
+		inlines []*htmlInline
+		ends    = make(map[int]int) // ends 是结束标签对应的行内元素的序号
+	)
+	for i := 0; i < len(p.tokens); i++ {
+		tk := p.tokens[i]
+		switch end, paired := match[i]; {
+		case tk.kind == htmlText:
+			text := html.UnescapeString(tk.raw)
+			if !p.pre {
+				text = htmlSpaceRe.ReplaceAllString(text, " ")
+			}
+			sb.WriteString(text)
+		case paired && (htmlAtomic[tk.name] || htmlNoTranslateRe.MatchString(tk.raw)):
+			inlines = append(inlines, &htmlInline{tokens: p.tokens[i : end+1]})
+			sb.WriteString(inlineTag(len(inlines)))
+			i = end
+		case paired:
+			inlines = append(inlines, &htmlInline{start: tk, end: p.tokens[end]})
+			ends[end] = len(inlines)
+			open, _ := inlineTags(len(inlines))
+			sb.WriteString(open)
+		case ends[i] > 0:
+			_, close := inlineTags(ends[i])
+			sb.WriteString(close)
+		default:
+			inlines = append(inlines, &htmlInline{tokens: []*htmlToken{tk}})
+			sb.WriteString(inlineTag(len(inlines)))
+		}
+	}
+	trimmed := strings.Trim(sb.String(), htmlSpace)
+	if !hasLetter(inlineTagRe.ReplaceAllString(trimmed, "")) || t.skipText(trimmed) {
+		return nil
+	}
+	p.inlines = inlines
+	// 译文两侧保留原文两侧的空白 (包括换行与缩进)
+	if first := p.tokens[0]; first.kind == htmlText {
+		p.lead = first.raw[:len(first.raw)-len(strings.TrimLeft(first.raw, htmlSpace))]
+	}
+	if last := p.tokens[len(p.tokens)-1]; last.kind == htmlText {
+		p.tail = last.raw[len(strings.TrimRight(last.raw, htmlSpace)):]
+	}
+	sg := &segment{text: trimmed, set: func(s string) { p.translated = &s }}
+	if p.pre {
+		sg.hint = breakHintFor(trimmed)
+	}
+	if len(inlines) > 0 {
+		sg.hint = joinHints(sg.hint, htmlInlineHint)
+	}
+	return sg
+}
+
+func (p *htmlPara) render() string {
+	var sb strings.Builder
+	if p.translated == nil {
+		for _, tk := range p.tokens {
+			sb.WriteString(tk.render())
+		}
+		return sb.String()
+	}
+	sb.WriteString(p.lead)
+	translated := strings.Trim(*p.translated, htmlSpace)
+	if body, ok := p.fillInlines(translated); ok {
+		sb.WriteString(body)
+	} else {
+		sb.WriteString(htmlTextEscaper.Replace(inlineTagRe.ReplaceAllString(translated, "")))
+		for _, in := range p.inlines {
+			for _, tk := range in.tokens {
+				sb.WriteString(tk.render())
+			}
+		}
+	}
+	sb.WriteString(p.tail)
+	return sb.String()
+}
+
+// fillInlines 按译文 translated 中的标记还原行内元素, 规则同 segment.fillInlines, 但成对的标记可以嵌套
+func (p *htmlPara) fillInlines(translated string) (string, bool) {
+	var (
+		sb    strings.Builder
+		seen  = make([]bool, len(p.inlines))
+		stack []int
+		last  int
+	)
+	for _, m := range inlineTagRe.FindAllStringSubmatchIndex(translated, -1) {
+		n, err := strconv.Atoi(translated[m[4]:m[5]])
+		if err != nil || n < 1 || n > len(p.inlines) {
+			return "", false
+		}
+		closing, single := m[3] > m[2], m[7] > m[6]
+		in := p.inlines[n-1]
+		atomic := in.start == nil
+		sb.WriteString(htmlTextEscaper.Replace(translated[last:m[0]]))
+		switch {
+		case single && !closing && atomic && !seen[n-1]:
+			for _, tk := range in.tokens {
+				sb.WriteString(tk.render())
+			}
+			seen[n-1] = true
+		case !single && !closing && !atomic && !seen[n-1]:
+			sb.WriteString(in.start.render())
+			stack = append(stack, n)
+			seen[n-1] = true
+		case !single && closing && len(stack) > 0 && stack[len(stack)-1] == n:
+			sb.WriteString(in.end.render())
+			stack = stack[:len(stack)-1]
+		default:
+			return "", false
+		}
+		last = m[1]
+	}
+	if len(stack) > 0 {
+		return "", false
+	}
+	for _, ok := range seen {
+		if !ok {
+			return "", false
+		}
+	}
+	sb.WriteString(htmlTextEscaper.Replace(translated[last:]))
+	return sb.String(), true
+}
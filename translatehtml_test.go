@@ -0,0 +1,42 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTranslateHTML(t *testing.T) {
+	var sources []string
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		sources = append(sources, text)
+		return strings.ToUpper(text), nil
+	}))
+	const in = "<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n<title>My page</title>\n" +
+		"<meta name=\"description\" content=\"About us\">\n<style>p { color: red; }</style>\n</head>\n<body>\n" +
+		"<h1 class=\"title\">Welcome &amp; hello</h1>\n" +
+		"<p>\n  Read <a href=\"/guide\" title=\"the guide\">the <b>full</b> guide</a>,\n  run <code>make all</code><br>then stop.\n</p>\n" +
+		"<p translate=\"no\">Brand</p><p><span class=\"notranslate\">ACME</span> rocks</p>\n" +
+		"<img src=\"a.png\" alt=\"a cat\">\n<pre>line one\n  line two</pre>\n" +
+		"<ul><li>one</li><li>2024</li></ul>\n<!-- a comment -->\n<script>var s = \"<p>not text</p>\";</script>\n</body>\n</html>\n"
+	var out bytes.Buffer
+	if err := tr.TranslateFile(context.Background(), strings.NewReader(in), &out, TranslateFileOptions{TargetLanguage: "Chinese"}); err != nil {
+		t.Fatal(err)
+	}
+	const want = "<!DOCTYPE html>\n<html lang=\"zh-CN\">\n<head>\n<title>MY PAGE</title>\n" +
+		"<meta name=\"description\" content=\"ABOUT US\">\n<style>p { color: red; }</style>\n</head>\n<body>\n" +
+		"<h1 class=\"title\">WELCOME &amp; HELLO</h1>\n" +
+		"<p>\n  READ <a href=\"/guide\" title=\"THE GUIDE\">THE <b>FULL</b> GUIDE</a>, RUN <code>make all</code><br>THEN STOP.\n</p>\n" +
+		"<p translate=\"no\">Brand</p><p><span class=\"notranslate\">ACME</span> ROCKS</p>\n" +
+		"<img src=\"a.png\" alt=\"A CAT\">\n<pre>LINE ONE\n  LINE TWO</pre>\n" +
+		"<ul><li>ONE</li><li>2024</li></ul>\n<!-- a comment -->\n<script>var s = \"<p>not text</p>\";</script>\n</body>\n</html>\n"
+	if out.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+	for _, s := range sources {
+		if strings.Contains(s, "make all") || strings.Contains(s, "color") || strings.Contains(s, "not text") {
+			t.Error("protected content was sent for translation:", s)
+		}
+	}
+}
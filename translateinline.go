@@ -0,0 +1,376 @@
+package docx
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// inlineHint 是段落中含有超链接、域、内容控件、公式、书签或修订时附加的翻译要求
+const inlineHint = "⟪1⟫ 与 ⟪/1⟫ 这样成对的标记包住的是超链接、域、内容控件或修订中插入的文字: 译文中原样保留每一对标记, 并用它包住对应的译文; " +
+	"⟪2/⟫ 这样单独的标记代表页码、日期、公式、书签、分页符、修订中删除的文字等不翻译的内容, 原样保留在译文中对应的位置."
+
+// breakHint 是段落中含有制表符或换行时附加的翻译要求
+const breakHint = "原文中的制表符与换行符标出了排版位置: 译文中保留同样数量的制表符与换行符, 并放在对应的位置."
+
+// breakHintFor 返回 text 需要的 breakHint
+func breakHintFor(text string) string {
+	if strings.ContainsAny(text, "\t\n") {
+		return breakHint
+	}
+	return ""
+}
+
+// inlineTagRe 匹配超链接与域的标记
+var inlineTagRe = regexp.MustCompile(`⟪(/?)(\d+)(/?)⟫`)
+
+// inlineTags 返回第 i 个 (从 1 开始) 超链接或域的开始与结束标记
+func inlineTags(i int) (string, string) {
+	n := strconv.Itoa(i)
+	return "⟪" + n + "⟫", "⟪/" + n + "⟫"
+}
+
+// inlineTag 返回第 i 个 (从 1 开始) 不翻译的域的标记
+func inlineTag(i int) string {
+	return "⟪" + strconv.Itoa(i) + "/⟫"
+}
+
+// inline 是段落中随译文重建的超链接、域、内容控件或修订
+type inline struct {
+	link  *Hyperlink // link 是超链接
+	sdt   *SDT       // sdt 是只有文字的内容控件
+	ins   *Ins       // ins 是只有文字的插入修订
+	field *field     // field 是域或原样保留的公式、书签等, 以上都为 nil 时使用; field.result 为 -1 时整个域原样保留
+}
+
+// atomic 表示内容原样保留, 译文中用单独的标记表示其位置
+func (in *inline) atomic() bool {
+	return in.link == nil && in.sdt == nil && in.ins == nil && in.field.result < 0
+}
+
+// runText 拼接 Run 中的文本, 制表符、换行与连字符的表示见 specialText
+func runText(r *Run) string {
+	var sb strings.Builder
+	for _, child := range r.Children {
+		if text, ok := child.(*Text); ok {
+			sb.WriteString(text.Text)
+		} else if special, ok := specialText(child); ok {
+			sb.WriteString(special)
+		}
+	}
+	return sb.String()
+}
+
+// 不间断连字符与可选连字符在译文中的表示
+const (
+	noBreakHyphen = "\u2011"
+	softHyphen    = "\u00ad"
+)
+
+// specialText 返回 Run 中的制表符 (\t)、换行 (\n)、不间断连字符与可选连字符在译文中的表示;
+// 分页符与分栏符等返回 false
+func specialText(o interface{}) (string, bool) {
+	switch o := o.(type) {
+	case *Tab:
+		return "\t", true
+	case *BarterRabbet:
+		if o.Type == "" || o.Type == "textWrapping" {
+			return "\n", true
+		}
+	case *NoBreakHyphen:
+		return noBreakHyphen, true
+	case *SoftHyphen:
+		return softHyphen, true
+	}
+	return "", false
+}
+
+// isCommentMark 判断 o 是否为正文中的批注标记或批注内容开头的批注标记
+func isCommentMark(o interface{}) bool {
+	switch o.(type) {
+	case *CommentReference, *AnnotationRef:
+		return true
+	}
+	return false
+}
+
+// isBreak 判断 o 是否为分页符或分栏符
+func isBreak(o interface{}) bool {
+	br, ok := o.(*BarterRabbet)
+	return ok && br.Type != "" && br.Type != "textWrapping"
+}
+
+// textChildren 将译文还原为 Run 的内容, 是 runText 的逆操作
+func textChildren(s string) []interface{} {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	var (
+		children []interface{}
+		last     int
+	)
+	flush := func(end int) {
+		if end > last {
+			text := &Text{Text: s[last:end]}
+			if strings.TrimSpace(text.Text) != text.Text {
+				text.XMLSpace = "preserve"
+			}
+			children = append(children, text)
+		}
+	}
+	for i, r := range s {
+		var child interface{}
+		switch string(r) {
+		case "\t":
+			child = &Tab{}
+		case "\n":
+			child = &BarterRabbet{}
+		case noBreakHyphen:
+			child = &NoBreakHyphen{}
+		case softHyphen:
+			child = &SoftHyphen{}
+		default:
+			continue
+		}
+		flush(i)
+		children = append(children, child)
+		last = i + utf8.RuneLen(r)
+	}
+	flush(len(s))
+	if len(children) == 0 {
+		children = append(children, &Text{})
+	}
+	return children
+}
+
+// runParts 拼接 Run 中的文本, 分页符、分栏符与嵌入的 OLE 对象在文本中用单独的标记表示
+func runParts(r *Run) (parts []inlinePart) {
+	var sb strings.Builder
+	for _, child := range r.Children {
+		if isBreak(child) || isCommentMark(child) {
+			parts = append(parts, inlinePart{text: sb.String()}, inlinePart{in: atomicInline(&Run{RunProperties: r.RunProperties, Children: []interface{}{child}}), edge: true})
+			sb.Reset()
+			continue
+		}
+		if _, ok := child.(*Object); ok {
+			// 嵌入的对象总是用标记表示, 使其连同引用的部件一起复制到新文档, 见 fillField
+			parts = append(parts, inlinePart{text: sb.String()}, inlinePart{in: atomicInline(&Run{RunProperties: r.RunProperties, Children: []interface{}{child}, file: r.file})})
+			sb.Reset()
+			continue
+		}
+		if text, ok := child.(*Text); ok {
+			sb.WriteString(text.Text)
+		} else if special, ok := specialText(child); ok {
+			sb.WriteString(special)
+		}
+	}
+	return append(parts, inlinePart{text: sb.String()})
+}
+
+// hasFldChar 判断 Run 中是否有域的标记
+func hasFldChar(r *Run) bool {
+	for _, child := range r.Children {
+		if _, ok := child.(*FldChar); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inlinePart 是 inlineText 拼接的一段内容
+type inlinePart struct {
+	text string  // text 是普通文本或超链接等的文字
+	in   *inline // in 为 nil 时是普通文本
+	edge bool    // edge 表示 in 是书签或批注范围的开始或结束、分页符、分栏符或批注标记, 位于段落文字之前或之后时不用标记表示
+}
+
+// atomicInline 返回原样保留的内容 o
+func atomicInline(o interface{}) *inline {
+	return &inline{field: &field{children: []interface{}{o}, result: -1}}
+}
+
+// inlineText 同 paragraphText, 但同时拼接超链接、域、内容控件与插入修订的文字, 并用标记包住, 见 inlineHint;
+// 返回段落中的超链接、域、内容控件、公式、书签与修订, 第 i 个对应标记 ⟪i+1⟫ 或 ⟪i+1/⟫.
+// 含有域的超链接 (如目录项)、含有文字以外内容的内容控件与插入修订、公式与删除修订原样保留.
+// 位于段落文字之前与之后的书签、批注范围、批注标记、分页符与分栏符不用标记表示, 分别在 before 与 after 中返回, 填充时放在译文的两侧,
+// 因此译文丢失标记时, 章节开头的分页符也不会移到译文之后. skipHidden 为 true 时隐藏的 Run 与分页符一样原样保留, 见 WithSkipHidden.
+func inlineText(p *Paragraph, skipHidden bool) (text string, inlines []*inline, before, after []interface{}) {
+	var parts []inlinePart
+	for _, g := range groupFields(p) {
+		if g.field != nil {
+			parts = append(parts, inlinePart{text: g.field.text, in: &inline{field: g.field}})
+			continue
+		}
+		switch o := g.child.(type) {
+		case *Run:
+			if skipHidden && isHidden(o) {
+				parts = append(parts, inlinePart{in: atomicInline(o), edge: true})
+				continue
+			}
+			parts = append(parts, runParts(o)...)
+		case *Hyperlink:
+			if hasFldChar(&o.Run) || skipHidden && isHidden(&o.Run) {
+				parts = append(parts, inlinePart{in: atomicInline(o)})
+				continue
+			}
+			text := runText(&o.Run)
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			parts = append(parts, inlinePart{text: text, in: &inline{link: o}})
+		case *BookmarkStart, *BookmarkEnd, *CommentRangeStart, *CommentRangeEnd:
+			parts = append(parts, inlinePart{in: atomicInline(o), edge: true})
+		case *Math, *MathPara, *Del:
+			parts = append(parts, inlinePart{in: atomicInline(o)})
+		case *Ins:
+			text, ok := resultText(o.Children)
+			if !ok || strings.TrimSpace(text) == "" {
+				parts = append(parts, inlinePart{in: atomicInline(o)})
+				continue
+			}
+			parts = append(parts, inlinePart{text: text, in: &inline{ins: o}})
+		case *SDT:
+			var items []interface{}
+			if o.Content != nil {
+				items = o.Content.Items
+			}
+			text, ok := resultText(items)
+			if !ok || strings.TrimSpace(text) == "" {
+				parts = append(parts, inlinePart{in: atomicInline(o)})
+				continue
+			}
+			parts = append(parts, inlinePart{text: text, in: &inline{sdt: o}})
+		}
+	}
+
+	first, last := len(parts), -1
+	for i, pt := range parts {
+		if (pt.in == nil || !pt.in.atomic()) && strings.TrimSpace(pt.text) != "" {
+			if i < first {
+				first = i
+			}
+			last = i
+		}
+	}
+	var sb strings.Builder
+	for i, pt := range parts {
+		switch {
+		case pt.edge && i < first:
+			before = append(before, pt.in.field.children...)
+		case pt.edge && i > last:
+			after = append(after, pt.in.field.children...)
+		case pt.in == nil:
+			sb.WriteString(pt.text)
+		case pt.in.atomic():
+			inlines = append(inlines, pt.in)
+			sb.WriteString(inlineTag(len(inlines)))
+		default:
+			inlines = append(inlines, pt.in)
+			open, end := inlineTags(len(inlines))
+			sb.WriteString(open)
+			sb.WriteString(pt.text)
+			sb.WriteString(end)
+		}
+	}
+	return sb.String(), inlines, before, after
+}
+
+// fillInlines 按译文中的标记重建普通文本、超链接、域与内容控件, 超链接保留原有的目标、提示与格式,
+// 域保留原有的指令, 内容控件与插入修订保留原有的属性; 分页符、分栏符与删除修订同样按标记原样放回;
+// 标记缺失、重复或嵌套时返回 false, 新段落不会被修改. 译文可以调整超链接与域的顺序.
+func (sg *segment) fillInlines(translated string, base *RunProperties) bool {
+	type piece struct {
+		text   string
+		inline int // inline 是超链接或域的序号 (从 1 开始), 0 表示普通文本
+	}
+	var (
+		pieces []piece
+		seen   = make([]bool, len(sg.inlines))
+		open   int // open 是当前未结束的标记, 0 表示没有
+		last   int
+	)
+	for _, m := range inlineTagRe.FindAllStringSubmatchIndex(translated, -1) {
+		n, err := strconv.Atoi(translated[m[4]:m[5]])
+		if err != nil || n < 1 || n > len(sg.inlines) {
+			return false
+		}
+		closing, single := m[3] > m[2], m[7] > m[6]
+		atomic := sg.inlines[n-1].atomic()
+		switch {
+		case single && !closing && open == 0 && atomic && !seen[n-1]:
+			pieces = append(pieces, piece{text: translated[last:m[0]]}, piece{inline: n})
+			seen[n-1] = true
+		case !single && !closing && open == 0 && !atomic && !seen[n-1]:
+			pieces = append(pieces, piece{text: translated[last:m[0]]})
+			open = n
+			seen[n-1] = true
+		case !single && closing && open != 0 && n == open:
+			pieces = append(pieces, piece{text: translated[last:m[0]], inline: n})
+			open = 0
+		default:
+			return false
+		}
+		last = m[1]
+	}
+	if open != 0 {
+		return false
+	}
+	for _, ok := range seen {
+		if !ok {
+			return false
+		}
+	}
+	pieces = append(pieces, piece{text: translated[last:]})
+
+	for _, pc := range pieces {
+		text := textChildren(pc.text)
+		if pc.inline == 0 {
+			if pc.text != "" {
+				sg.dst.Children = append(sg.dst.Children, &Run{RunProperties: base, Children: text})
+			}
+			continue
+		}
+		in := sg.inlines[pc.inline-1]
+		if in.ins != nil {
+			ni := *in.ins
+			ni.file = sg.dst.file
+			first := in.ins.Children[0].(*Run)
+			ni.Children = []interface{}{&Run{RunProperties: first.RunProperties, Children: text}}
+			sg.dst.Children = append(sg.dst.Children, &ni)
+			continue
+		}
+		if in.sdt != nil {
+			ns := *in.sdt
+			ns.file = sg.dst.file
+			first := in.sdt.Content.Items[0].(*Run)
+			ns.Content = &SDTContent{Items: []interface{}{&Run{RunProperties: first.RunProperties, Children: text}}}
+			sg.dst.Children = append(sg.dst.Children, &ns)
+			continue
+		}
+		if in.link == nil {
+			sg.fillField(in.field, text)
+			continue
+		}
+		nh := *in.link
+		nh.Run = Run{RunProperties: in.link.Run.RunProperties, Children: text}
+		if in.link.ID != "" {
+			tgt, err := sg.src.file.ReferTarget(in.link.ID)
+			if err != nil {
+				// 关系已经失效, 只保留文字
+				sg.dst.Children = append(sg.dst.Children, &Run{RunProperties: base, Children: text})
+				continue
+			}
+			nh.ID = sg.dst.file.addLinkRelation(tgt)
+		}
+		sg.dst.Children = append(sg.dst.Children, &nh)
+	}
+	return true
+}
+
+// fillAtomic 将所有原样保留的域按原顺序追加到新段落, 用于译文中的标记不完整时
+func (sg *segment) fillAtomic() {
+	for _, in := range sg.inlines {
+		if in.atomic() {
+			sg.fillField(in.field, nil)
+		}
+	}
+}
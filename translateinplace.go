@@ -0,0 +1,281 @@
+package docx
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// TranslateDocxInPlace 直接将 doc 翻译为 targetLanguage: 只修改段落中的文本节点、图片的替代文字与文档属性, 不重建文档,
+// 因此文档中的其他内容 (超链接、书签、域代码等) 与包中的其他部件 (设置、自定义 XML、内容类型等) 都原样保留
+//
+// 每个段落的译文放入其第一个文本节点 (包括插入修订中的), 其余文本节点被删除, Run 及其格式保持不变; 域中的文字不翻译.
+// 超链接的文字与所在的句子一起翻译, 用 ⟪1⟫ 与 ⟪/1⟫ 这样的标记包住 (见 inlineHint), 译文按标记放回超链接与其前后的文本节点;
+// 标记缺失或顺序改变时译文全部放入超链接之外的文本节点, 超链接的文字被清空.
+// 目录的处理见 WithTOCEntries, 修订的处理见 WithRevisionPolicy.
+// 不支持 WithRunLanguageRouting. 超出限制、ctx 被取消或 ErrorPolicyFailFast 下出错时 doc 不会被修改;
+// ErrorPolicyCollect 下失败的段落保留原文, 并返回 SegmentErrors.
+func (t *Translator) TranslateDocxInPlace(doc *Docx, targetLanguage string) error {
+	return t.TranslateDocxInPlaceContext(context.Background(), doc, targetLanguage)
+}
+
+// TranslateDocxInPlaceContext 同 TranslateDocxInPlace, ctx 被取消时在当前段落完成后停止, 返回 ctx.Err()
+func (t *Translator) TranslateDocxInPlaceContext(ctx context.Context, doc *Docx, targetLanguage string) error {
+	if err := t.checkMediaBytes(doc); err != nil {
+		return err
+	}
+	pv := t.newProvenance(doc)
+	segs, edits := t.collectInPlace(doc, targetLanguage)
+	report, err := t.translateSegments(ctx, segs, targetLanguage)
+	if report == nil {
+		return err
+	}
+	for _, edit := range edits {
+		edit()
+	}
+	t.stamp(pv, doc)
+	return err
+}
+
+// collectInPlace 按 prepare 的顺序收集 doc 中的翻译单元, 翻译单元的 dst 就是 src;
+// edits 是 BilingualPolicyStripSource 下去除双语段落原文、标记目录需要更新等操作, 翻译完成后才执行
+func (t *Translator) collectInPlace(doc *Docx, targetLanguage string) (segs []*segment, edits []func()) {
+	toc := tocParagraphs(doc)
+	selected := t.rangeParagraphs(doc)
+	collect := func(p *Paragraph) error {
+		if t.skipStyle(p) || selected != nil && !selected[p] {
+			return nil
+		}
+		if toc[p] {
+			sg, mark := t.tocSegment(p, p)
+			edits = append(edits, mark)
+			if sg != nil {
+				segs = append(segs, sg)
+			}
+			return nil
+		}
+		if t.revisionPolicy == RevisionPolicyAccept && hasRevisions(p) {
+			// 译文放入插入的 Run 之后再接受修订
+			edits = append(edits, func() { p.Children = acceptedChildren(p) })
+		}
+		text, groups, links := inPlaceText(p, t.skipHidden)
+		plain := inlineTagRe.ReplaceAllString(text, "")
+		if t.skipText(plain) {
+			return nil
+		}
+		segs = append(segs, t.altSegments(p, p)...)
+		if strings.TrimSpace(plain) == "" {
+			return nil
+		}
+		if t.bilingualPolicy != BilingualPolicyTranslate && targetLanguage != "" && len(links) == 0 {
+			if src, tgt, ok := SplitBilingual(text, t.sourceLanguageName(), targetLanguage); ok {
+				switch t.bilingualPolicy {
+				case BilingualPolicyKeep:
+					return nil
+				case BilingualPolicyStripSource:
+					sg := &segment{src: p, dst: p, inPlace: true, visible: t.skipHidden}
+					edits = append(edits, func() { sg.fill(tgt) })
+					return nil
+				case BilingualPolicyRetranslate:
+					text = src
+				case BilingualPolicyTranslate:
+				}
+			}
+		}
+		sg := &segment{src: p, dst: p, text: text, hint: joinHints(t.styleHint(p), breakHintFor(text)), inPlace: true, visible: t.skipHidden, groups: groups}
+		for _, link := range links {
+			sg.inlines = append(sg.inlines, &inline{link: link})
+		}
+		if len(links) > 0 {
+			sg.hint = joinHints(sg.hint, inlineHint)
+		}
+		segs = append(segs, sg)
+		return nil
+	}
+	_ = doc.Document.Body.RangeParagraphs(collect)
+	fonts := t.fontMap(targetLanguage)
+	for _, sg := range segs {
+		sg.fonts = fonts
+	}
+	if tag := languageTag(targetLanguage); tag != "" {
+		for _, sg := range segs {
+			sg.langTag = tag
+		}
+		edits = append(edits, func() {
+			retagStyles(doc, tag)
+			directSections(doc, isRightToLeft(tag))
+		})
+	}
+	return append(segs, t.propSegments(doc)...), edits
+}
+
+// plainText 同 paragraphText, 但不包括域中的文字与删除的文字, 包括插入的文字与超链接的文字, 见 textRuns
+func plainText(p *Paragraph) string {
+	text, _, _ := inPlaceText(p, false)
+	return inlineTagRe.ReplaceAllString(text, "")
+}
+
+// inPlaceText 拼接段落中可以放入译文的文字, 超链接的文字用 ⟪i⟫ 与 ⟪/i⟫ 包住;
+// groups[i] 是第 i 个超链接之前 (最后一个是所有超链接之后) 的 Run, 见 textRuns.
+// 含有域的超链接 (如目录项) 与没有文字的超链接不翻译; skipHidden 为 true 时隐藏的 Run 与超链接不包括在内
+func inPlaceText(p *Paragraph, skipHidden bool) (text string, groups [][]*Run, links []*Hyperlink) {
+	var (
+		sb    strings.Builder
+		group []*Run
+	)
+	for _, run := range textRuns(p) {
+		if link := linkOf(p, run); link != nil {
+			if hasFldChar(run) || skipHidden && isHidden(run) || strings.TrimSpace(runText(run)) == "" {
+				continue
+			}
+			groups, group = append(groups, group), nil
+			links = append(links, link)
+			open, end := inlineTags(len(links))
+			sb.WriteString(open)
+			sb.WriteString(runText(run))
+			sb.WriteString(end)
+			continue
+		}
+		if skipHidden && isHidden(run) {
+			continue
+		}
+		group = append(group, run)
+		sb.WriteString(runText(run))
+	}
+	return sb.String(), append(groups, group), links
+}
+
+// linkOf 返回 run 所在的段落 p 中的超链接, run 不在超链接中时返回 nil
+func linkOf(p *Paragraph, run *Run) *Hyperlink {
+	for _, child := range p.Children {
+		if link, ok := child.(*Hyperlink); ok && &link.Run == run {
+			return link
+		}
+	}
+	return nil
+}
+
+// splitLinks 按 inPlaceText 的标记拆分有 n 个超链接的译文: outside[i] 是第 i 个超链接之前的译文 (最后一个是之后的),
+// inside[i] 是第 i 个超链接的译文; 标记缺失、重复、嵌套或顺序改变时返回 false
+func splitLinks(translated string, n int) (outside, inside []string, ok bool) {
+	last, open := 0, 0
+	for _, m := range inlineTagRe.FindAllStringSubmatchIndex(translated, -1) {
+		i, err := strconv.Atoi(translated[m[4]:m[5]])
+		closing, single := m[3] > m[2], m[7] > m[6]
+		switch {
+		case err != nil || single:
+			return nil, nil, false
+		case !closing && open == 0 && i == len(inside)+1:
+			outside = append(outside, translated[last:m[0]])
+			open = i
+		case closing && open == i:
+			inside = append(inside, translated[last:m[0]])
+			open = 0
+		default:
+			return nil, nil, false
+		}
+		last = m[1]
+	}
+	if open != 0 || len(inside) != n {
+		return nil, nil, false
+	}
+	return append(outside, translated[last:]), inside, true
+}
+
+// replaceText 将译文放入段落的第一个文本节点并删除其余的文本节点, Run 与其他内容 (包括域) 保持不变;
+// 制表符与换行等已包含在译文中, 同样删除后按 textChildren 还原在第一个文本节点处.
+// 段落中有超链接时 (见 inPlaceText), 各超链接与其前后的译文分别放入超链接与其前后的第一个文本节点
+func (sg *segment) replaceText(translated string) {
+	if len(sg.texts) > 0 {
+		// 只替换指定的文本节点, 其余的清空
+		for i, text := range sg.texts {
+			text.Text, text.XMLSpace = "", ""
+			if i == 0 {
+				text.Text = translated
+				if strings.TrimSpace(translated) != translated {
+					text.XMLSpace = "preserve"
+				}
+			}
+		}
+		return
+	}
+	if len(sg.inlines) > 0 {
+		sg.replaceLinks(translated)
+	} else {
+		// 不翻译的超链接 (见 inPlaceText) 保持不变
+		_, groups, _ := inPlaceText(sg.dst, false)
+		var runs []*Run
+		for _, group := range groups {
+			runs = append(runs, group...)
+		}
+		sg.replaceRuns(runs, translated)
+	}
+	if sg.langTag != "" {
+		directParagraph(sg.dst, isRightToLeft(sg.langTag))
+	}
+}
+
+// replaceLinks 按标记将译文放回超链接与其前后的文本节点, 超链接之间没有文本节点时插入沿用前一个 Run 格式的新 Run
+func (sg *segment) replaceLinks(translated string) {
+	outside, inside, ok := splitLinks(translated, len(sg.inlines))
+	if !ok {
+		outside = make([]string, len(sg.inlines)+1)
+		outside[0] = inlineTagRe.ReplaceAllString(translated, "")
+		inside = make([]string, len(sg.inlines))
+	}
+	var base *RunProperties
+	for i, runs := range sg.groups {
+		if len(runs) > 0 {
+			base = runs[len(runs)-1].RunProperties
+		}
+		if sg.replaceRuns(runs, outside[i]) || outside[i] == "" {
+			continue
+		}
+		// 没有可以放入译文的文本节点, 在下一个超链接之前 (或最后一个超链接之后) 插入新的 Run
+		run := &Run{RunProperties: base, Children: textChildren(outside[i]), file: sg.dst.file}
+		sg.replaceRuns([]*Run{run}, outside[i])
+		at := len(sg.dst.Children)
+		for j, child := range sg.dst.Children {
+			if i < len(sg.inlines) && child == sg.inlines[i].link || i > 0 && child == sg.inlines[i-1].link {
+				at = j
+				if i == len(sg.inlines) {
+					at++
+				}
+				break
+			}
+		}
+		sg.dst.Children = append(sg.dst.Children[:at], append([]interface{}{run}, sg.dst.Children[at:]...)...)
+	}
+	for i, in := range sg.inlines {
+		sg.replaceRuns([]*Run{&in.link.Run}, inside[i])
+	}
+}
+
+// replaceRuns 将译文放入 runs 中的第一个文本节点并删除其余的文本节点, 返回译文是否已放入
+func (sg *segment) replaceRuns(runs []*Run, translated string) bool {
+	first := true
+	for _, run := range runs {
+		if sg.visible && isHidden(run) {
+			continue
+		}
+		children := run.Children[:0:0]
+		for _, grandChild := range run.Children {
+			_, special := specialText(grandChild)
+			if _, ok := grandChild.(*Text); !ok && !special {
+				children = append(children, grandChild)
+				continue
+			}
+			if first {
+				children = append(children, textChildren(translated)...)
+				first = false
+			}
+		}
+		run.Children = children
+		retagRun(run, sg.langTag)
+		if sg.langTag != "" {
+			directRun(run, isRightToLeft(sg.langTag))
+		}
+		mapFont(run, sg.fonts)
+	}
+	return !first
+}
@@ -0,0 +1,114 @@
+package docx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTranslateDocxInPlace(t *testing.T) {
+	doc := New().WithDefaultTheme()
+	p := doc.AddParagraph()
+	p.AddText("hello ").Bold()
+	p.AddText("world")
+	p.AddLink("site", "https://example.com")
+	tbl := doc.AddTable(1, 1, 0, nil)
+	tbl.TableRows[0].TableCells[0].AddParagraph().AddText("cell")
+	doc.AddParagraph().AddText("FAIL here")
+	items := len(doc.Document.Body.Items)
+
+	// 出错时不修改文档
+	err := newTestTranslator(t).WithErrorPolicy(ErrorPolicyFailFast).TranslateDocxInPlace(doc, "English")
+	var se *SegmentError
+	if !errors.As(err, &se) {
+		t.Fatal("expected a segment error, got", err)
+	}
+	if s := paragraphText(p); s != "hello world" {
+		t.Fatal("document modified on failure:", s)
+	}
+
+	err = newTestTranslator(t).WithErrorPolicy(ErrorPolicyCollect).TranslateDocxInPlace(doc, "English")
+	var errs SegmentErrors
+	if !errors.As(err, &errs) || len(errs) != 1 {
+		t.Fatal("expected one failed segment, got", err)
+	}
+	if len(doc.Document.Body.Items) != items {
+		t.Fatal("unexpected body items:", len(doc.Document.Body.Items))
+	}
+	if s := paragraphText(p); s != "HELLO WORLD" {
+		t.Fatal("unexpected translation:", s)
+	}
+	first, second := p.Children[0].(*Run), p.Children[1].(*Run)
+	if first.RunProperties == nil || first.RunProperties.Bold == nil || len(second.Children) != 0 {
+		t.Fatal("runs were not kept:", first, second)
+	}
+	if _, ok := p.Children[2].(*Hyperlink); !ok {
+		t.Fatalf("hyperlink was lost: %T", p.Children[2])
+	}
+	if s := paragraphText(tbl.TableRows[0].TableCells[0].Paragraphs[0]); s != "CELL" {
+		t.Fatal("unexpected cell translation:", s)
+	}
+	last := doc.Document.Body.Items[items-1]
+	if s := paragraphText(last.(*Paragraph)); !strings.Contains(s, "FAIL") {
+		t.Fatal("failed paragraph must keep its text:", s)
+	}
+}
+
+func TestTranslateDocxInPlaceHyperlink(t *testing.T) {
+	doc := New().WithDefaultTheme()
+	// 与读取的文档一样, 超链接的文字在文本节点中
+	addLink := func(p *Paragraph, text string) {
+		p.Children = append(p.Children, &Hyperlink{ID: doc.addLinkRelation("https://example.com"), Run: Run{Children: []interface{}{&Text{Text: text}}}})
+	}
+	mid := doc.AddParagraph()
+	mid.AddText("Click ")
+	addLink(mid, "here")
+	mid.AddText(" to continue.")
+	only := doc.AddParagraph()
+	addLink(only, "docs")
+	lost := doc.AddParagraph()
+	lost.AddText("see ")
+	addLink(lost, "site")
+
+	var sent []string
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		sent = append(sent, text)
+		switch text {
+		case "Click ⟪1⟫here⟪/1⟫ to continue.":
+			return "点击⟪1⟫这里⟪/1⟫继续。", nil
+		case "⟪1⟫docs⟪/1⟫":
+			return "参见⟪1⟫文档⟪/1⟫", nil
+		}
+		return "参见网站", nil // 标记丢失
+	})
+	if err := NewTranslator("", "").WithProvider(p).TranslateDocxInPlace(doc, "Chinese"); err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 3 || sent[2] != "see ⟪1⟫site⟪/1⟫" {
+		t.Fatalf("unexpected requests: %q", sent)
+	}
+	texts := func(p *Paragraph) (out []string) {
+		for _, child := range p.Children {
+			switch o := child.(type) {
+			case *Run:
+				out = append(out, runText(o))
+			case *Hyperlink:
+				out = append(out, "["+runText(&o.Run)+"]")
+			}
+		}
+		return out
+	}
+	if got := strings.Join(texts(mid), "|"); got != "点击|[这里]|继续。" {
+		t.Fatal("unexpected mid-sentence link:", got)
+	}
+	// 超链接之前没有文本节点时插入新的 Run
+	if got := strings.Join(texts(only), "|"); got != "参见|[文档]" {
+		t.Fatal("unexpected link-only paragraph:", got)
+	}
+	if got := strings.Join(texts(lost), "|"); got != "参见网站|[]" {
+		t.Fatal("unexpected fallback:", got)
+	}
+	if _, ok := mid.Children[1].(*Hyperlink); !ok {
+		t.Fatalf("hyperlink was lost: %T", mid.Children[1])
+	}
+}
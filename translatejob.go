@@ -0,0 +1,402 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	// ErrJobNotFound 任务不存在或已被清除
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobFinished 任务已经结束, 不能取消
+	ErrJobFinished = errors.New("job already finished")
+	// ErrJobRunning 任务仍在运行, 不能清除
+	ErrJobRunning = errors.New("job still running")
+	// ErrArtifactExpired 任务的译文已超过保留期限被删除, 见 JobManager.WithRetention 与 JobQueue.WithRetention
+	ErrArtifactExpired = errors.New("artifact expired")
+)
+
+// JobState 是任务的状态
+type JobState uint8
+
+const (
+	// JobQueued 任务已提交, 尚未开始
+	JobQueued JobState = iota
+	// JobRunning 任务正在翻译
+	JobRunning
+	// JobSucceeded 任务已完成, 译文可以通过 JobManager.Artifact 取得
+	JobSucceeded
+	// JobFailed 任务失败, 原因见 JobInfo.Error
+	JobFailed
+	// JobCanceled 任务被取消
+	JobCanceled
+)
+
+func (s JobState) String() string {
+	switch s {
+	case JobQueued:
+		return "queued"
+	case JobRunning:
+		return "running"
+	case JobSucceeded:
+		return "succeeded"
+	case JobFailed:
+		return "failed"
+	case JobCanceled:
+		return "canceled"
+	}
+	return "unknown"
+}
+
+// MarshalText 使 JobState 在 JSON 中显示为名称
+func (s JobState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText 解析 MarshalText 写出的名称, 供 HTTP 接口的调用方读取 JobInfo
+func (s *JobState) UnmarshalText(text []byte) error {
+	for st := JobQueued; st <= JobCanceled; st++ {
+		if st.String() == string(text) {
+			*s = st
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown job state %q", text)
+}
+
+// finished 判断任务是否已经结束
+func (s JobState) finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCanceled
+}
+
+// JobUsage 是任务已消耗的用量, token 数按 EstimateTokens 估算
+type JobUsage struct {
+	Segments     int `json:"segments"`      // Segments 是已完成的翻译单元数
+	Characters   int `json:"characters"`    // Characters 是已完成的翻译单元的原文字符 (rune) 数
+	InputTokens  int `json:"input_tokens"`  // InputTokens 是原文的预估 token 数
+	OutputTokens int `json:"output_tokens"` // OutputTokens 是译文的预估 token 数
+	Failed       int `json:"failed"`        // Failed 是翻译失败的翻译单元数
+}
+
+// JobInfo 是任务的快照
+type JobInfo struct {
+	ID             string     `json:"id"`
+	State          JobState   `json:"state"`
+	Format         string     `json:"format,omitempty"`
+	TargetLanguage string     `json:"target_language"`
+	Size           int        `json:"size"`  // Size 是输入文件的字节数
+	Done           int        `json:"done"`  // Done 是已完成的翻译单元数
+	Total          int        `json:"total"` // Total 是翻译单元的总数, 开始翻译前为 0
+	Usage          JobUsage   `json:"usage"`
+	Created        time.Time  `json:"created"`
+	Started        *time.Time `json:"started,omitempty"`
+	Finished       *time.Time `json:"finished,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	ArtifactSize   int        `json:"artifact_size"`     // ArtifactSize 是译文的字节数, 清除后为 0
+	Expires        *time.Time `json:"expires,omitempty"` // Expires 是原文与译文被删除的时间, 没有设置保留期限时为 nil
+	Expired        bool       `json:"expired,omitempty"` // Expired 表示原文与译文已被删除
+}
+
+// job 是 JobManager 中的一个任务
+type job struct {
+	mu       sync.Mutex
+	info     JobInfo
+	source   []byte // source 是原文, 设置了加密时为密文
+	artifact []byte // artifact 是译文, 设置了加密时为密文
+	cancel   context.CancelFunc
+	done     chan struct{}
+	changed  chan struct{} // changed 在任务的状态或进度变化时关闭并替换为新的 channel, 见 Watch
+	expiry   *time.Timer
+}
+
+// notify 通知 Watch 任务有变化, 调用时须持有 j.mu
+func (j *job) notify() {
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+func (j *job) snapshot() JobInfo {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.info
+}
+
+// JobManager 在后台执行 TranslateFile 任务, 并记录每个任务的进度、用量与译文,
+// 供服务端查询、取消任务与清除结果. 可以并发调用.
+type JobManager struct {
+	t *Translator
+
+	retention time.Duration
+	aead      cipher.AEAD
+	webhook   *Webhook
+
+	mu   sync.RWMutex
+	jobs map[string]*job
+}
+
+// NewJobManager 创建使用 t 翻译的 JobManager, t 的设置在所有任务间共享
+func NewJobManager(t *Translator) *JobManager {
+	return &JobManager{t: t, jobs: make(map[string]*job)}
+}
+
+// newJobID 返回一个随机的任务 ID
+func newJobID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Submit 提交一个翻译 data 的任务并立即返回任务 ID, 任务在后台执行,
+// 不受调用方 context 的影响, 只能通过 Cancel 取消
+func (m *JobManager) Submit(data []byte, opts TranslateFileOptions) string {
+	ctx, cancel := context.WithCancel(context.Background())
+	id := newJobID()
+	j := &job{
+		info: JobInfo{
+			ID:             id,
+			State:          JobQueued,
+			Format:         opts.Format,
+			TargetLanguage: opts.TargetLanguage,
+			Size:           len(data),
+			Created:        time.Now(),
+		},
+		source:  m.seal(id, data),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		changed: make(chan struct{}),
+	}
+	m.mu.Lock()
+	m.jobs[id] = j
+	m.mu.Unlock()
+	go m.run(ctx, j, data, opts)
+	return id
+}
+
+// run 执行任务, 用量通过包装后的进度回调统计
+func (m *JobManager) run(ctx context.Context, j *job, data []byte, opts TranslateFileOptions) {
+	defer close(j.done)
+	defer j.cancel()
+
+	now := time.Now()
+	j.mu.Lock()
+	j.info.State = JobRunning
+	j.info.Started = &now
+	if j.info.Format == "" {
+		j.info.Format = SniffFormat(data)
+	}
+	j.notify()
+	j.mu.Unlock()
+
+	t := *m.t
+	userProgress := m.t.progress
+	t.progress = func(done, total int, sg SegmentInfo) {
+		j.mu.Lock()
+		j.info.record(done, total, sg)
+		j.notify()
+		j.mu.Unlock()
+		if userProgress != nil {
+			userProgress(done, total, sg)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := t.TranslateFile(ctx, bytes.NewReader(data), &buf, opts)
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.info.finish(ctx, err, buf.Len()) {
+		j.artifact = m.seal(j.info.ID, buf.Bytes())
+		j.info.ArtifactSize = buf.Len()
+	}
+	m.scheduleExpiry(j)
+	j.notify()
+	m.webhook.notify(j.info)
+	m.t.log().Log(LogLevelInfo, "任务结束", "id", j.info.ID, "state", j.info.State)
+}
+
+// record 在进度回调中记录一个完成的翻译单元
+func (info *JobInfo) record(done, total int, sg SegmentInfo) {
+	info.Done, info.Total = done, total
+	info.Usage.Segments++
+	info.Usage.Characters += utf8.RuneCountInString(sg.Source)
+	info.Usage.InputTokens += EstimateTokens(sg.Source)
+	if sg.Err != nil {
+		info.Usage.Failed++
+	} else {
+		info.Usage.OutputTokens += EstimateTokens(sg.Target)
+	}
+}
+
+// finish 按 TranslateFile 的结果设置任务的结束状态, size 是写出的译文的字节数; 返回任务是否成功, 即是否应保存译文
+func (info *JobInfo) finish(ctx context.Context, err error, size int) bool {
+	now := time.Now()
+	info.Finished = &now
+	switch {
+	case ctx.Err() != nil:
+		info.State = JobCanceled
+		info.Error = ctx.Err().Error()
+	case err != nil && size == 0:
+		info.State = JobFailed
+		info.Error = err.Error()
+	default:
+		// ErrorPolicyCollect 下部分段落失败时仍然得到译文
+		info.State = JobSucceeded
+		if err != nil {
+			info.Error = err.Error()
+		}
+		return true
+	}
+	return false
+}
+
+func (m *JobManager) get(id string) (*job, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return j, nil
+}
+
+// Jobs 返回所有任务的快照, 按创建时间排列
+func (m *JobManager) Jobs() []JobInfo {
+	m.mu.RLock()
+	infos := make([]JobInfo, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		infos = append(infos, j.snapshot())
+	}
+	m.mu.RUnlock()
+	sort.Slice(infos, func(i, k int) bool {
+		return infos[i].Created.Before(infos[k].Created)
+	})
+	return infos
+}
+
+// Job 返回任务的快照
+func (m *JobManager) Job(id string) (JobInfo, error) {
+	j, err := m.get(id)
+	if err != nil {
+		return JobInfo{}, err
+	}
+	return j.snapshot(), nil
+}
+
+// Wait 等待任务结束并返回其快照, ctx 结束时返回 ctx.Err()
+func (m *JobManager) Wait(ctx context.Context, id string) (JobInfo, error) {
+	j, err := m.get(id)
+	if err != nil {
+		return JobInfo{}, err
+	}
+	select {
+	case <-j.done:
+		return j.snapshot(), nil
+	case <-ctx.Done():
+		return JobInfo{}, ctx.Err()
+	}
+}
+
+// Watch 在任务的状态或进度变化时以任务的快照调用 fn, 直到任务结束、ctx 结束或 fn 返回错误, 供流式推送进度;
+// 第一次调用使用当前的快照, 连续的变化可能合并为一次调用. 任务结束时返回 nil, 否则返回 ctx.Err() 或 fn 的错误
+func (m *JobManager) Watch(ctx context.Context, id string, fn func(JobInfo) error) error {
+	j, err := m.get(id)
+	if err != nil {
+		return err
+	}
+	for {
+		j.mu.Lock()
+		info, changed := j.info, j.changed
+		j.mu.Unlock()
+		if err = fn(info); err != nil {
+			return err
+		}
+		if info.State.finished() {
+			return nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Artifact 返回已完成任务的译文
+func (m *JobManager) Artifact(id string) ([]byte, error) {
+	j, err := m.get(id)
+	if err != nil {
+		return nil, err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.info.Expired {
+		return nil, ErrArtifactExpired
+	}
+	if j.artifact == nil {
+		return nil, ErrJobNotFound
+	}
+	return m.open(j.info.ID, j.artifact)
+}
+
+// Cancel 取消任务, 任务在当前段落完成后停止; 已经结束的任务返回 ErrJobFinished
+func (m *JobManager) Cancel(id string) error {
+	j, err := m.get(id)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	finished := j.info.State.finished()
+	j.mu.Unlock()
+	if finished {
+		return ErrJobFinished
+	}
+	j.cancel()
+	return nil
+}
+
+// Purge 清除已结束的任务及其译文; 仍在运行的任务返回 ErrJobRunning
+func (m *JobManager) Purge(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return ErrJobNotFound
+	}
+	j.mu.Lock()
+	finished := j.info.State.finished()
+	j.mu.Unlock()
+	if !finished {
+		return ErrJobRunning
+	}
+	j.discard()
+	delete(m.jobs, id)
+	return nil
+}
+
+// PurgeFinished 清除所有在 before 之前结束的任务, before 为零值时清除所有已结束的任务,
+// 返回清除的数量
+func (m *JobManager) PurgeFinished(before time.Time) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for id, j := range m.jobs {
+		j.mu.Lock()
+		purge := j.info.State.finished() && (before.IsZero() || j.info.Finished.Before(before))
+		j.mu.Unlock()
+		if purge {
+			j.discard()
+			delete(m.jobs, id)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,185 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobManager(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := newTestDoc("hello", "world", "again").WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	started, release := make(chan struct{}, 8), make(chan struct{})
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		started <- struct{}{}
+		<-release
+		return strings.ToUpper(text), nil
+	})
+	m := NewJobManager(NewTranslator("", "").WithProvider(p))
+	srv := httptest.NewServer(http.StripPrefix("/admin", m.AdminHandler()))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 运行中的任务可以被取消, 不能被清除
+	id := m.Submit(buf.Bytes(), TranslateFileOptions{TargetLanguage: "English"})
+	<-started
+	if err := m.Purge(id); !errors.Is(err, ErrJobRunning) {
+		t.Fatal("expected ErrJobRunning, got", err)
+	}
+	resp, err := http.Post(srv.URL+"/admin/jobs/"+id+"/cancel", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatal("unexpected status:", resp.Status)
+	}
+	close(release)
+	info, err := m.Wait(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.State != JobCanceled || info.Done != 1 || info.Usage.Segments != 1 || info.Format != "docx" {
+		t.Fatalf("unexpected job: %+v", info)
+	}
+	if err = m.Cancel(id); !errors.Is(err, ErrJobFinished) {
+		t.Fatal("expected ErrJobFinished, got", err)
+	}
+
+	id2 := m.Submit(buf.Bytes(), TranslateFileOptions{TargetLanguage: "English"})
+	if info, err = m.Wait(ctx, id2); err != nil || info.State != JobSucceeded || info.Total != 3 || info.ArtifactSize == 0 {
+		t.Fatalf("unexpected job: %+v %v", info, err)
+	}
+	if data, err := m.Artifact(id2); err != nil || len(data) != info.ArtifactSize {
+		t.Fatal("unexpected artifact:", len(data), err)
+	}
+
+	resp, err = http.Get(srv.URL + "/admin/jobs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&list)
+	resp.Body.Close()
+	if err != nil || len(list) != 2 || list[0]["id"] != id || list[0]["state"] != "canceled" || list[1]["state"] != "succeeded" {
+		t.Fatalf("unexpected list: %v %v", list, err)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/admin/jobs/"+id, nil)
+	if resp, err = http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatal("unexpected purge response:", resp, err)
+	}
+	resp.Body.Close()
+	resp, err = http.Get(srv.URL + "/admin/jobs/" + id)
+	if err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Fatal("expected a purged job to be gone:", resp, err)
+	}
+	resp.Body.Close()
+	req, _ = http.NewRequest(http.MethodDelete, srv.URL+"/admin/jobs", nil)
+	if resp, err = http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	}
+	var purged map[string]int
+	err = json.NewDecoder(resp.Body).Decode(&purged)
+	resp.Body.Close()
+	if err != nil || purged["purged"] != 1 || len(m.Jobs()) != 0 {
+		t.Fatal("unexpected purge result:", purged, err)
+	}
+}
+
+func TestJobRetention(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := newTestDoc("hello").WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	aead, err := NewArtifactCipher(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewArtifactCipher([]byte("short")); err == nil {
+		t.Fatal("expected an invalid key error")
+	}
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	})
+	m := NewJobManager(NewTranslator("", "").WithProvider(p)).WithEncryption(aead).WithRetention(100 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id := m.Submit(buf.Bytes(), TranslateFileOptions{TargetLanguage: "English"})
+	info, err := m.Wait(ctx, id)
+	if err != nil || info.State != JobSucceeded || info.Expires == nil {
+		t.Fatalf("unexpected job: %+v %v", info, err)
+	}
+	j, _ := m.get(id)
+	j.mu.Lock()
+	plain := bytes.HasPrefix(j.source, []byte("PK")) || bytes.HasPrefix(j.artifact, []byte("PK"))
+	j.mu.Unlock()
+	if plain {
+		t.Fatal("documents must be encrypted at rest")
+	}
+	data, err := m.Artifact(id)
+	if err != nil || !bytes.HasPrefix(data, []byte("PK")) || len(data) != info.ArtifactSize {
+		t.Fatal("unexpected artifact:", len(data), err)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if _, err = m.Artifact(id); !errors.Is(err, ErrArtifactExpired) {
+		t.Fatal("expected ErrArtifactExpired, got", err)
+	}
+	if info, err = m.Job(id); err != nil || !info.Expired || info.ArtifactSize != 0 {
+		t.Fatalf("unexpected expired job: %+v %v", info, err)
+	}
+}
+
+func TestJobWatch(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := newTestDoc("hello", "world").WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		<-release
+		return strings.ToUpper(text), nil
+	})
+	m := NewJobManager(NewTranslator("", "").WithProvider(p))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.Watch(ctx, "nope", nil); !errors.Is(err, ErrJobNotFound) {
+		t.Fatal("expected ErrJobNotFound, got", err)
+	}
+
+	id := m.Submit(buf.Bytes(), TranslateFileOptions{TargetLanguage: "English"})
+	var last JobInfo
+	calls := 0
+	err := m.Watch(ctx, id, func(info JobInfo) error {
+		if calls == 0 {
+			close(release)
+		}
+		calls++
+		if info.Done < last.Done {
+			t.Errorf("progress went back: %+v after %+v", info, last)
+		}
+		last = info
+		return nil
+	})
+	if err != nil || last.State != JobSucceeded || last.Done != 2 || calls < 2 {
+		t.Fatalf("unexpected watch: %d calls, last %+v, %v", calls, last, err)
+	}
+
+	// fn 的错误结束 Watch
+	stop := errors.New("stop")
+	if err = m.Watch(ctx, id, func(JobInfo) error { return stop }); err != stop {
+		t.Fatal("expected the callback error, got", err)
+	}
+}
@@ -0,0 +1,226 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultJobLease 是 JobQueue 默认的租约时长
+	DefaultJobLease = time.Minute
+	// DefaultJobPollInterval 是 JobQueue 默认的轮询间隔
+	DefaultJobPollInterval = time.Second
+)
+
+// JobQueue 是保存在 JobStore 中的任务队列: Submit 提交文档并返回任务 ID, 由一个或多个执行者 (Work) 领取并翻译,
+// 调用方通过 Job 查看进度, 通过 Artifact 取得译文
+//
+// 与在当前进程中立即执行任务的 JobManager 不同, 任务先持久化再执行: 进程崩溃后排队的任务不会丢失,
+// 运行中的任务在租约到期后由其他执行者从头重新翻译. 提交与执行可以在不同的进程中, 只要它们共用同一个存储.
+type JobQueue struct {
+	store     JobStore
+	lease     time.Duration
+	poll      time.Duration
+	retention time.Duration
+	webhook   *Webhook
+}
+
+// NewJobQueue 创建使用 store 的 JobQueue
+func NewJobQueue(store JobStore) *JobQueue {
+	return &JobQueue{store: store, lease: DefaultJobLease, poll: DefaultJobPollInterval}
+}
+
+// WithLease 设置执行者的租约时长, 执行者每隔三分之一租约续约一次; 崩溃的执行者的任务最晚在租约到期后被重新领取
+func (q *JobQueue) WithLease(d time.Duration) *JobQueue {
+	if d > 0 {
+		q.lease = d
+	}
+	return q
+}
+
+// WithPollInterval 设置队列为空时执行者的轮询间隔, 也是 Wait 查询任务状态的间隔
+func (q *JobQueue) WithPollInterval(d time.Duration) *JobQueue {
+	if d > 0 {
+		q.poll = d
+	}
+	return q
+}
+
+// Submit 保存一个翻译 data 的任务并返回任务 ID, 任务由执行者在之后领取
+func (q *JobQueue) Submit(data []byte, opts TranslateFileOptions) (string, error) {
+	info := JobInfo{
+		ID:             newJobID(),
+		State:          JobQueued,
+		Format:         opts.Format,
+		TargetLanguage: opts.TargetLanguage,
+		Size:           len(data),
+		Created:        time.Now(),
+	}
+	if info.Format == "" {
+		info.Format = SniffFormat(data)
+	}
+	if err := q.store.Create(info, opts, data); err != nil {
+		return "", err
+	}
+	return info.ID, nil
+}
+
+// Job 返回任务的快照, 运行中的任务的进度每隔三分之一租约保存一次
+func (q *JobQueue) Job(id string) (JobInfo, error) {
+	return q.store.Get(id)
+}
+
+// Jobs 返回所有任务的快照, 按创建时间排列
+func (q *JobQueue) Jobs() ([]JobInfo, error) {
+	return q.store.List()
+}
+
+// Wait 轮询等待任务结束并返回其快照, ctx 结束时返回 ctx.Err()
+func (q *JobQueue) Wait(ctx context.Context, id string) (JobInfo, error) {
+	for {
+		info, err := q.store.Get(id)
+		if err != nil || info.State.finished() {
+			return info, err
+		}
+		select {
+		case <-time.After(q.poll):
+		case <-ctx.Done():
+			return JobInfo{}, ctx.Err()
+		}
+	}
+}
+
+// Artifact 返回已完成任务的译文
+func (q *JobQueue) Artifact(id string) ([]byte, error) {
+	return q.store.Artifact(id)
+}
+
+// Cancel 取消任务; 运行中的任务在执行者下一次续约时停止, 已经结束的任务返回 ErrJobFinished
+func (q *JobQueue) Cancel(id string) error {
+	if err := q.store.Cancel(id); err != nil {
+		return err
+	}
+	if q.webhook != nil {
+		if info, err := q.store.Get(id); err == nil {
+			q.webhook.notify(info)
+		}
+	}
+	return nil
+}
+
+// Purge 删除已结束的任务及其译文; 排队或运行中的任务返回 ErrJobRunning
+func (q *JobQueue) Purge(id string) error {
+	return q.store.Delete(id)
+}
+
+// Work 作为执行者使用 t 依次领取并翻译队列中的任务, 直到 ctx 结束, 返回 ctx.Err()
+//
+// 同一个进程中可以并发调用多次, 多个进程也可以共同处理同一个存储中的队列. ctx 结束时正在翻译的任务不会被标记为取消,
+// 而是在租约到期后由其他执行者重新领取. 存储出错时记录日志并在轮询间隔后重试.
+// 设置了保留期限时, 每隔一个轮询间隔删除一次已过期的原文与译文.
+func (q *JobQueue) Work(ctx context.Context, t *Translator) error {
+	var lastExpire time.Time
+	for {
+		if q.retention > 0 && time.Since(lastExpire) >= q.poll {
+			lastExpire = time.Now()
+			if n, err := q.store.Expire(lastExpire); err != nil {
+				t.log().Log(LogLevelError, "删除过期的原文与译文失败", "error", err)
+			} else if n > 0 {
+				t.log().Log(LogLevelInfo, "任务的原文与译文已过期删除", "count", n)
+			}
+		}
+		l, err := q.store.Claim(time.Now().Add(q.lease))
+		if err == nil {
+			q.run(ctx, t, l)
+			continue
+		}
+		if !errors.Is(err, ErrQueueEmpty) {
+			t.log().Log(LogLevelError, "领取任务失败", "error", err)
+		}
+		select {
+		case <-time.After(q.poll):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// run 翻译领取的任务, 期间定期保存进度并续约; 租约丢失 (任务被取消或被其他执行者领取) 时停止翻译
+func (q *JobQueue) run(ctx context.Context, t *Translator, l *JobLease) {
+	jctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	info := l.Info
+	tt := *t
+	userProgress := t.progress
+	tt.progress = func(done, total int, sg SegmentInfo) {
+		mu.Lock()
+		info.record(done, total, sg)
+		mu.Unlock()
+		if userProgress != nil {
+			userProgress(done, total, sg)
+		}
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(q.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+			mu.Lock()
+			snapshot := info
+			mu.Unlock()
+			err := q.store.Update(snapshot, l.Token, time.Now().Add(q.lease))
+			if errors.Is(err, ErrJobLeaseLost) || errors.Is(err, ErrJobNotFound) {
+				t.log().Log(LogLevelInfo, "任务已被取消或由其他执行者领取, 停止翻译", "id", info.ID)
+				cancel()
+				return
+			}
+			if err != nil {
+				t.log().Log(LogLevelWarn, "保存任务进度失败", "id", info.ID, "error", err)
+			}
+		}
+	}()
+
+	var buf bytes.Buffer
+	err := tt.TranslateFile(jctx, bytes.NewReader(l.Source), &buf, l.Options)
+	close(stop)
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var artifact []byte
+	if info.finish(jctx, err, buf.Len()) {
+		artifact = buf.Bytes()
+		info.ArtifactSize = len(artifact)
+	}
+	if q.retention > 0 {
+		expires := info.Finished.Add(q.retention)
+		info.Expires = &expires
+	}
+	if err = q.store.Finish(info, l.Token, artifact); err != nil {
+		level := LogLevelError
+		if errors.Is(err, ErrJobLeaseLost) {
+			level = LogLevelInfo
+		}
+		t.log().Log(level, "保存任务结果失败", "id", info.ID, "error", err)
+		return
+	}
+	t.log().Log(LogLevelInfo, "任务结束", "id", info.ID, "state", info.State)
+	q.webhook.notify(info)
+}
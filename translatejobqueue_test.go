@@ -0,0 +1,193 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobQueue(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := newTestDoc("hello", "world").WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	store := NewMemoryJobStore()
+	q := NewJobQueue(store).WithLease(150 * time.Millisecond).WithPollInterval(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 领取后崩溃的执行者的任务在租约到期后被重新领取
+	crashed, err := q.Submit(buf.Bytes(), TranslateFileOptions{TargetLanguage: "English"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := store.Claim(time.Now().Add(50 * time.Millisecond))
+	if err != nil || l.Info.ID != crashed || l.Info.State != JobRunning || l.Info.Format != "docx" {
+		t.Fatalf("unexpected lease: %+v %v", l, err)
+	}
+	if _, err = store.Claim(time.Now().Add(time.Minute)); !errors.Is(err, ErrQueueEmpty) {
+		t.Fatal("expected ErrQueueEmpty, got", err)
+	}
+	canceled, _ := q.Submit([]byte("bye"), TranslateFileOptions{TargetLanguage: "English"})
+	if err = q.Cancel(canceled); err != nil {
+		t.Fatal(err)
+	}
+	txt, _ := q.Submit([]byte("plain text"), TranslateFileOptions{TargetLanguage: "English"})
+
+	workers, stop := context.WithCancel(ctx)
+	done := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() { done <- q.Work(workers, tr) }()
+	}
+	for _, id := range []string{crashed, txt} {
+		info, err := q.Wait(ctx, id)
+		if err != nil || info.State != JobSucceeded || info.Done != info.Total || info.ArtifactSize == 0 {
+			t.Fatalf("unexpected job: %+v %v", info, err)
+		}
+	}
+	if data, err := q.Artifact(txt); err != nil || string(data) != "PLAIN TEXT" {
+		t.Fatalf("unexpected artifact: %q %v", data, err)
+	}
+	// 原来的执行者的租约已经失效
+	if err = store.Update(l.Info, l.Token, time.Now().Add(time.Minute)); !errors.Is(err, ErrJobLeaseLost) {
+		t.Fatal("expected ErrJobLeaseLost, got", err)
+	}
+	stop()
+	for i := 0; i < 2; i++ {
+		if err = <-done; !errors.Is(err, context.Canceled) {
+			t.Fatal("unexpected Work result:", err)
+		}
+	}
+
+	if info, _ := q.Job(canceled); info.State != JobCanceled {
+		t.Fatalf("unexpected canceled job: %+v", info)
+	}
+	if _, err = q.Artifact(canceled); !errors.Is(err, ErrJobNotFound) {
+		t.Fatal("expected ErrJobNotFound, got", err)
+	}
+	if err = q.Cancel(txt); !errors.Is(err, ErrJobFinished) {
+		t.Fatal("expected ErrJobFinished, got", err)
+	}
+	if err = q.Purge(txt); err != nil {
+		t.Fatal(err)
+	}
+	if infos, _ := q.Jobs(); len(infos) != 2 || infos[0].ID != crashed || infos[1].ID != canceled {
+		t.Fatalf("unexpected jobs: %+v", infos)
+	}
+}
+
+func TestJobQueueRetention(t *testing.T) {
+	var buf bytes.Buffer
+	if _, err := newTestDoc("hello").WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	aead, err := NewArtifactCipher(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	store := NewMemoryJobStore()
+	q := NewJobQueue(store).WithEncryption(aead).WithRetention(100 * time.Millisecond).WithPollInterval(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	id, err := q.Submit(buf.Bytes(), TranslateFileOptions{TargetLanguage: "English"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source := store.jobs[id].source; bytes.HasPrefix(source, []byte("PK")) {
+		t.Fatal("the source must be encrypted at rest")
+	}
+	go func() { _ = q.Work(ctx, tr) }()
+	info, err := q.Wait(ctx, id)
+	if err != nil || info.State != JobSucceeded || info.Expires == nil {
+		t.Fatalf("unexpected job: %+v %v", info, err)
+	}
+	if raw, _ := store.Artifact(id); bytes.HasPrefix(raw, []byte("PK")) {
+		t.Fatal("the artifact must be encrypted at rest")
+	}
+	if data, err := q.Artifact(id); err != nil || !bytes.HasPrefix(data, []byte("PK")) || len(data) != info.ArtifactSize {
+		t.Fatal("unexpected artifact:", len(data), err)
+	}
+	other, _ := NewArtifactCipher(bytes.Repeat([]byte{8}, 32))
+	if _, err = EncryptJobStore(store, other).Artifact(id); !errors.Is(err, ErrArtifactCorrupted) {
+		t.Fatal("expected ErrArtifactCorrupted, got", err)
+	}
+
+	// 执行者在保留期限之后删除原文与译文
+	for deadline := time.Now().Add(time.Second); !info.Expired && time.Now().Before(deadline); {
+		time.Sleep(20 * time.Millisecond)
+		info, _ = q.Job(id)
+	}
+	if !info.Expired || info.ArtifactSize != 0 {
+		t.Fatalf("unexpected expired job: %+v", info)
+	}
+	if _, err = q.Artifact(id); !errors.Is(err, ErrArtifactExpired) {
+		t.Fatal("expected ErrArtifactExpired, got", err)
+	}
+}
+
+func TestJobQueueHandler(t *testing.T) {
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	q := NewJobQueue(NewMemoryJobStore()).WithPollInterval(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() { _ = q.Work(ctx, tr) }()
+	mux := http.NewServeMux()
+	mux.Handle("/", q.Handler(ServerOptions{SyncTimeout: 2 * time.Second}))
+	mux.Handle("/admin/", http.StripPrefix("/admin", q.AdminHandler()))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/translate?to=English&format=txt", "text/plain", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(data) != "HELLO" {
+		t.Fatalf("unexpected response: %s %s", resp.Status, data)
+	}
+
+	var updates []JobInfo
+	infos, err := q.Jobs()
+	if err != nil || len(infos) != 1 {
+		t.Fatalf("unexpected jobs: %+v %v", infos, err)
+	}
+	err = q.Watch(ctx, infos[0].ID, func(info JobInfo) error {
+		updates = append(updates, info)
+		return nil
+	})
+	if err != nil || len(updates) != 1 || updates[0].State != JobSucceeded {
+		t.Fatalf("unexpected updates: %+v %v", updates, err)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/admin/jobs", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var purged map[string]int
+	err = json.NewDecoder(resp.Body).Decode(&purged)
+	resp.Body.Close()
+	if err != nil || purged["purged"] != 1 {
+		t.Fatal("unexpected purge result:", purged, err)
+	}
+	if infos, _ = q.Jobs(); len(infos) != 0 {
+		t.Fatalf("unexpected jobs: %+v", infos)
+	}
+}
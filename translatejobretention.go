@@ -0,0 +1,160 @@
+package docx
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"time"
+)
+
+// ErrArtifactCorrupted 保存的原文或译文无法解密, 通常是密钥被更换
+var ErrArtifactCorrupted = errors.New("artifact corrupted")
+
+// NewArtifactCipher 使用 16, 24 或 32 字节的密钥创建 AES-GCM 加密器, 供 JobManager.WithEncryption,
+// JobQueue.WithEncryption 与 EncryptJobStore 使用
+func NewArtifactCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// WithEncryption 使 JobManager 加密保存每个任务的原文与译文, 只在读取译文时解密;
+// 密文与任务 ID 绑定, 不能挪用到其它任务. 默认不加密.
+func (m *JobManager) WithEncryption(aead cipher.AEAD) *JobManager {
+	m.aead = aead
+	return m
+}
+
+// WithRetention 设置任务结束后原文与译文的保留期限, 到期后自动删除, 任务记录保留至被清除;
+// d 不大于 0 时一直保留 (默认)
+func (m *JobManager) WithRetention(d time.Duration) *JobManager {
+	m.retention = d
+	return m
+}
+
+// sealArtifact 以 aead 加密任务 id 的 data, 密文以随机的 nonce 开头; aead 为 nil 或 data 为 nil 时返回 data 本身
+func sealArtifact(aead cipher.AEAD, id string, data []byte) []byte {
+	if aead == nil || data == nil {
+		return data
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+	_, _ = rand.Read(nonce)
+	return aead.Seal(nonce, nonce, data, []byte(id))
+}
+
+// openArtifact 解密 sealArtifact 的结果
+func openArtifact(aead cipher.AEAD, id string, data []byte) ([]byte, error) {
+	if aead == nil || data == nil {
+		return data, nil
+	}
+	n := aead.NonceSize()
+	if len(data) < n {
+		return nil, ErrArtifactCorrupted
+	}
+	plain, err := aead.Open(nil, data[:n], data[n:], []byte(id))
+	if err != nil {
+		return nil, ErrArtifactCorrupted
+	}
+	return plain, nil
+}
+
+func (m *JobManager) seal(id string, data []byte) []byte {
+	return sealArtifact(m.aead, id, data)
+}
+
+func (m *JobManager) open(id string, data []byte) ([]byte, error) {
+	return openArtifact(m.aead, id, data)
+}
+
+// EncryptJobStore 返回加密保存原文与译文的 JobStore: 写入 store 之前以 aead 加密, 读出时解密, 任务的快照与选项不加密;
+// 密文与任务 ID 绑定, 不能挪用到其它任务. 共用同一个存储的所有进程必须使用相同的密钥, 见 JobQueue.WithEncryption
+func EncryptJobStore(store JobStore, aead cipher.AEAD) JobStore {
+	return &encryptedJobStore{JobStore: store, aead: aead}
+}
+
+// encryptedJobStore 在 JobStore 之上加密原文与译文, 其他方法直接使用 JobStore
+type encryptedJobStore struct {
+	JobStore
+	aead cipher.AEAD
+}
+
+// Create 实现 JobStore
+func (s *encryptedJobStore) Create(info JobInfo, opts TranslateFileOptions, source []byte) error {
+	return s.JobStore.Create(info, opts, sealArtifact(s.aead, info.ID, source))
+}
+
+// Claim 实现 JobStore; 无法解密原文的任务 (通常是密钥被更换) 直接设为失败, 然后领取下一个
+func (s *encryptedJobStore) Claim(until time.Time) (*JobLease, error) {
+	for {
+		l, err := s.JobStore.Claim(until)
+		if err != nil {
+			return nil, err
+		}
+		source, err := openArtifact(s.aead, l.Info.ID, l.Source)
+		if err == nil {
+			l.Source = source
+			return l, nil
+		}
+		now := time.Now()
+		l.Info.State, l.Info.Finished, l.Info.Error = JobFailed, &now, err.Error()
+		if err = s.JobStore.Finish(l.Info, l.Token, nil); err != nil && !errors.Is(err, ErrJobLeaseLost) {
+			return nil, err
+		}
+	}
+}
+
+// Finish 实现 JobStore
+func (s *encryptedJobStore) Finish(info JobInfo, token string, artifact []byte) error {
+	return s.JobStore.Finish(info, token, sealArtifact(s.aead, info.ID, artifact))
+}
+
+// Artifact 实现 JobStore
+func (s *encryptedJobStore) Artifact(id string) ([]byte, error) {
+	data, err := s.JobStore.Artifact(id)
+	if err != nil {
+		return nil, err
+	}
+	return openArtifact(s.aead, id, data)
+}
+
+// WithEncryption 以 EncryptJobStore 加密保存在存储中的原文与译文, 提交任务与执行任务的所有进程都要设置相同的密钥. 默认不加密.
+func (q *JobQueue) WithEncryption(aead cipher.AEAD) *JobQueue {
+	q.store = EncryptJobStore(q.store, aead)
+	return q
+}
+
+// WithRetention 设置任务结束后原文与译文的保留期限, 到期后由执行者 (Work) 以 JobStore.Expire 删除, 任务记录保留至被清除;
+// d 不大于 0 时一直保留 (默认). 只提交任务、不执行任务的进程不需要设置
+func (q *JobQueue) WithRetention(d time.Duration) *JobQueue {
+	q.retention = d
+	return q
+}
+
+// scheduleExpiry 在任务结束后按保留期限安排删除, 调用方必须持有 j.mu
+func (m *JobManager) scheduleExpiry(j *job) {
+	if m.retention <= 0 {
+		return
+	}
+	expires := j.info.Finished.Add(m.retention)
+	j.info.Expires = &expires
+	j.expiry = time.AfterFunc(m.retention, func() {
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		j.source, j.artifact = nil, nil
+		expired(&j.info)
+		m.t.log().Log(LogLevelInfo, "任务的原文与译文已过期删除", "id", j.info.ID)
+	})
+}
+
+// discard 在任务被清除时删除原文与译文并停止到期删除
+func (j *job) discard() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.expiry != nil {
+		j.expiry.Stop()
+	}
+	j.source, j.artifact = nil, nil
+}
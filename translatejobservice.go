@@ -0,0 +1,114 @@
+package docx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// JobService 是提交与管理翻译任务的接口, 由 JobQueue 与 JobManager.Service 实现,
+// Handler, AdminHandler 等对外接口基于它, 因此同样适用于进程内的任务与持久化的队列
+type JobService interface {
+	// Submit 提交一个翻译 data 的任务并返回任务 ID
+	Submit(data []byte, opts TranslateFileOptions) (string, error)
+	// Job 返回任务的快照
+	Job(id string) (JobInfo, error)
+	// Jobs 返回所有任务的快照, 按创建时间排列
+	Jobs() ([]JobInfo, error)
+	// Wait 等待任务结束并返回其快照, ctx 结束时返回 ctx.Err()
+	Wait(ctx context.Context, id string) (JobInfo, error)
+	// Watch 在任务的状态或进度变化时以任务的快照调用 fn, 直到任务结束、ctx 结束或 fn 返回错误, 见 JobManager.Watch
+	Watch(ctx context.Context, id string, fn func(JobInfo) error) error
+	// Artifact 返回已完成任务的译文
+	Artifact(id string) ([]byte, error)
+	// Cancel 取消任务, 已经结束的任务返回 ErrJobFinished
+	Cancel(id string) error
+	// Purge 清除已结束的任务及其译文, 仍在运行的任务返回 ErrJobRunning
+	Purge(id string) error
+	// PurgeFinished 清除所有在 before 之前结束的任务, before 为零值时清除所有已结束的任务, 返回清除的数量
+	PurgeFinished(before time.Time) (int, error)
+}
+
+// Service 返回 m 的 JobService
+func (m *JobManager) Service() JobService {
+	return managerService{m}
+}
+
+// managerService 将 JobManager 适配为 JobService
+type managerService struct {
+	*JobManager
+}
+
+func (s managerService) Submit(data []byte, opts TranslateFileOptions) (string, error) {
+	return s.JobManager.Submit(data, opts), nil
+}
+
+func (s managerService) Jobs() ([]JobInfo, error) {
+	return s.JobManager.Jobs(), nil
+}
+
+func (s managerService) PurgeFinished(before time.Time) (int, error) {
+	return s.JobManager.PurgeFinished(before), nil
+}
+
+// Watch 每隔轮询间隔查询一次任务, 在任务的状态或进度变化时以任务的快照调用 fn, 直到任务结束、ctx 结束或 fn 返回错误;
+// 第一次调用使用当前的快照. 任务结束时返回 nil, 否则返回 ctx.Err() 或 fn 的错误
+func (q *JobQueue) Watch(ctx context.Context, id string, fn func(JobInfo) error) error {
+	var last *JobInfo
+	for {
+		info, err := q.store.Get(id)
+		if err != nil {
+			return err
+		}
+		if last == nil || !reflect.DeepEqual(info, *last) {
+			if err = fn(info); err != nil {
+				return err
+			}
+			last = &info
+		}
+		if info.State.finished() {
+			return nil
+		}
+		select {
+		case <-time.After(q.poll):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// PurgeFinished 删除所有在 before 之前结束的任务, before 为零值时删除所有已结束的任务, 返回删除的数量
+func (q *JobQueue) PurgeFinished(before time.Time) (int, error) {
+	infos, err := q.store.List()
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, info := range infos {
+		if !info.State.finished() || !before.IsZero() && !info.Finished.Before(before) {
+			continue
+		}
+		err = q.store.Delete(info.ID)
+		if errors.Is(err, ErrJobNotFound) {
+			// 已被其他进程删除
+			continue
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// Handler 返回翻译文件的 HTTP 接口, 见 JobManager.Handler; 任务保存在队列中, 由执行者 (Work) 翻译
+func (q *JobQueue) Handler(opts ServerOptions) http.Handler {
+	return jobHandler(q, opts)
+}
+
+// AdminHandler 返回管理任务的 HTTP 接口, 见 JobManager.AdminHandler
+func (q *JobQueue) AdminHandler() http.Handler {
+	return jobAdminHandler(q)
+}
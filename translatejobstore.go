@@ -0,0 +1,516 @@
+package docx
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueEmpty 没有可领取的任务, 见 JobStore.Claim
+	ErrQueueEmpty = errors.New("no queued jobs")
+	// ErrJobLeaseLost 任务已不再由持有该租约的执行者运行: 租约过期后被其他执行者领取, 或任务已被取消
+	ErrJobLeaseLost = errors.New("job lease lost")
+)
+
+// JobLease 是执行者领取的任务, 在租约到期前由该执行者独占
+type JobLease struct {
+	Info    JobInfo
+	Options TranslateFileOptions
+	Source  []byte
+	// Token 标识这次领取, 更新与结束任务时须提供
+	Token string
+}
+
+// JobStore 保存 JobQueue 的任务、原文与译文, 使进程崩溃后排队的任务不会丢失, 并且多个执行者可以共同处理同一个队列
+//
+// 运行中的任务带有租约, 执行者在翻译期间定期续约; 执行者崩溃后租约过期, 任务可以被其他执行者重新领取.
+// 实现必须可以并发调用, 共享同一存储的多个进程之间 Claim 必须是原子的. 不存在的任务返回 ErrJobNotFound.
+type JobStore interface {
+	// Create 保存新提交的任务与原文
+	Create(info JobInfo, opts TranslateFileOptions, source []byte) error
+	// Claim 领取最早提交的排队任务或租约已过期的运行中任务, 将其设为 JobRunning 并持有租约至 until;
+	// 没有可领取的任务时返回 ErrQueueEmpty
+	Claim(until time.Time) (*JobLease, error)
+	// Update 保存运行中的任务的进度并将租约延长至 until; token 不再有效时返回 ErrJobLeaseLost
+	Update(info JobInfo, token string, until time.Time) error
+	// Finish 保存结束的任务与译文 (失败或取消时为 nil) 并删除原文; token 不再有效时返回 ErrJobLeaseLost
+	Finish(info JobInfo, token string, artifact []byte) error
+	// Cancel 取消排队或运行中的任务, 已经结束的任务返回 ErrJobFinished
+	Cancel(id string) error
+	// Get 返回任务的快照
+	Get(id string) (JobInfo, error)
+	// Artifact 返回成功的任务的译文, 译文已经过期删除时返回 ErrArtifactExpired
+	Artifact(id string) ([]byte, error)
+	// List 返回所有任务的快照, 按创建时间排列
+	List() ([]JobInfo, error)
+	// Delete 删除已结束的任务及其译文, 排队或运行中的任务返回 ErrJobRunning
+	Delete(id string) error
+	// Expire 删除 Expires 早于 before 的已结束任务的原文与译文, 并将任务标记为 Expired, 返回删除的任务数
+	Expire(before time.Time) (int, error)
+}
+
+// claimed 将领取的任务设为重新开始运行, 清除上一个执行者留下的进度
+func claimed(info *JobInfo, now time.Time) {
+	info.State = JobRunning
+	info.Started = &now
+	info.Done, info.Total = 0, 0
+	info.Usage = JobUsage{}
+	info.Error = ""
+}
+
+// canceled 将任务设为已取消
+func canceled(info *JobInfo, now time.Time) {
+	info.State = JobCanceled
+	info.Finished = &now
+	info.Error = "canceled"
+}
+
+// expired 将任务标记为原文与译文已被删除
+func expired(info *JobInfo) {
+	info.ArtifactSize = 0
+	info.Expired = true
+}
+
+// MemoryJobStore 是保存在内存中的 JobStore, 进程退出后任务随之丢失, 适用于测试与单进程的队列
+type MemoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*memoryJob
+}
+
+type memoryJob struct {
+	info     JobInfo
+	opts     TranslateFileOptions
+	source   []byte
+	artifact []byte
+	token    string
+	until    time.Time
+}
+
+// NewMemoryJobStore 创建一个空的 MemoryJobStore
+func NewMemoryJobStore() *MemoryJobStore {
+	return &MemoryJobStore{jobs: make(map[string]*memoryJob)}
+}
+
+// Create 实现 JobStore
+func (s *MemoryJobStore) Create(info JobInfo, opts TranslateFileOptions, source []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[info.ID] = &memoryJob{info: info, opts: opts, source: source}
+	return nil
+}
+
+// Claim 实现 JobStore
+func (s *MemoryJobStore) Claim(until time.Time) (*JobLease, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	var next *memoryJob
+	for _, j := range s.jobs {
+		if (j.info.State == JobQueued || j.info.State == JobRunning && j.until.Before(now)) &&
+			(next == nil || j.info.Created.Before(next.info.Created)) {
+			next = j
+		}
+	}
+	if next == nil {
+		return nil, ErrQueueEmpty
+	}
+	claimed(&next.info, now)
+	next.token, next.until = newJobID(), until
+	return &JobLease{Info: next.info, Options: next.opts, Source: next.source, Token: next.token}, nil
+}
+
+// leased 返回由 token 持有的运行中的任务
+func (s *MemoryJobStore) leased(id, token string) (*memoryJob, error) {
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	if j.info.State != JobRunning || j.token != token {
+		return nil, ErrJobLeaseLost
+	}
+	return j, nil
+}
+
+// Update 实现 JobStore
+func (s *MemoryJobStore) Update(info JobInfo, token string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.leased(info.ID, token)
+	if err != nil {
+		return err
+	}
+	j.info, j.until = info, until
+	return nil
+}
+
+// Finish 实现 JobStore
+func (s *MemoryJobStore) Finish(info JobInfo, token string, artifact []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.leased(info.ID, token)
+	if err != nil {
+		return err
+	}
+	j.info, j.artifact, j.source, j.token = info, artifact, nil, ""
+	return nil
+}
+
+// Cancel 实现 JobStore
+func (s *MemoryJobStore) Cancel(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return ErrJobNotFound
+	}
+	if j.info.State.finished() {
+		return ErrJobFinished
+	}
+	canceled(&j.info, time.Now())
+	j.source, j.token = nil, ""
+	return nil
+}
+
+// Get 实现 JobStore
+func (s *MemoryJobStore) Get(id string) (JobInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return JobInfo{}, ErrJobNotFound
+	}
+	return j.info, nil
+}
+
+// Artifact 实现 JobStore
+func (s *MemoryJobStore) Artifact(id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	switch {
+	case ok && j.info.Expired:
+		return nil, ErrArtifactExpired
+	case !ok || j.artifact == nil:
+		return nil, ErrJobNotFound
+	}
+	return j.artifact, nil
+}
+
+// List 实现 JobStore
+func (s *MemoryJobStore) List() ([]JobInfo, error) {
+	s.mu.Lock()
+	infos := make([]JobInfo, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		infos = append(infos, j.info)
+	}
+	s.mu.Unlock()
+	sort.Slice(infos, func(i, k int) bool {
+		return infos[i].Created.Before(infos[k].Created)
+	})
+	return infos, nil
+}
+
+// Delete 实现 JobStore
+func (s *MemoryJobStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return ErrJobNotFound
+	}
+	if !j.info.State.finished() {
+		return ErrJobRunning
+	}
+	delete(s.jobs, id)
+	return nil
+}
+
+// Expire 实现 JobStore
+func (s *MemoryJobStore) Expire(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, j := range s.jobs {
+		if j.info.State.finished() && !j.info.Expired && j.info.Expires != nil && j.info.Expires.Before(before) {
+			expired(&j.info)
+			j.source, j.artifact = nil, nil
+			n++
+		}
+	}
+	return n, nil
+}
+
+// SQLiteJobStore 将任务保存在 SQLite 数据库的 docx_translate_jobs 表中, 同一个数据库文件上的多个进程可以共同处理队列
+//
+// 数据库由调用方以任意 SQLite 驱动打开 (如 modernc.org/sqlite 或 github.com/mattn/go-sqlite3), 本包不依赖具体的驱动.
+// 多个进程共用时建议打开 WAL 模式并设置 busy_timeout. 任务的快照与选项以 JSON 保存, 原文与译文以 BLOB 保存,
+// 需要加密时使用 EncryptJobStore.
+type SQLiteJobStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteJobStore 创建使用 db 的 SQLiteJobStore, 表不存在时创建
+func NewSQLiteJobStore(db *sql.DB) (*SQLiteJobStore, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS docx_translate_jobs (
+	id TEXT PRIMARY KEY,
+	state TEXT NOT NULL,
+	created INTEGER NOT NULL,
+	lease_token TEXT NOT NULL DEFAULT '',
+	lease_until INTEGER NOT NULL DEFAULT 0,
+	info TEXT NOT NULL,
+	options TEXT NOT NULL,
+	source BLOB,
+	artifact BLOB,
+	expires INTEGER NOT NULL DEFAULT 0
+)`)
+	if err == nil {
+		_, err = db.Exec(`CREATE INDEX IF NOT EXISTS docx_translate_jobs_queue ON docx_translate_jobs (state, created)`)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteJobStore{db: db}, nil
+}
+
+// Create 实现 JobStore
+func (s *SQLiteJobStore) Create(info JobInfo, opts TranslateFileOptions, source []byte) error {
+	infoJSON, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	optsJSON, err := json.Marshal(opts)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO docx_translate_jobs (id, state, created, info, options, source) VALUES (?, ?, ?, ?, ?, ?)`,
+		info.ID, info.State.String(), info.Created.UnixNano(), string(infoJSON), string(optsJSON), source)
+	return err
+}
+
+// Claim 实现 JobStore; 先查询候选任务, 再以带条件的 UPDATE 领取, 被其他进程抢先时换下一个
+func (s *SQLiteJobStore) Claim(until time.Time) (*JobLease, error) {
+	for {
+		now := time.Now()
+		var (
+			id, token, infoJSON, optsJSON string
+			source                        []byte
+		)
+		err := s.db.QueryRow(`SELECT id, lease_token, info, options, source FROM docx_translate_jobs
+WHERE state = 'queued' OR state = 'running' AND lease_until < ? ORDER BY created LIMIT 1`, now.UnixNano()).
+			Scan(&id, &token, &infoJSON, &optsJSON, &source)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrQueueEmpty
+		}
+		if err != nil {
+			return nil, err
+		}
+		l := &JobLease{Source: source, Token: newJobID()}
+		if err = json.Unmarshal([]byte(infoJSON), &l.Info); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(optsJSON), &l.Options); err != nil {
+			return nil, err
+		}
+		claimed(&l.Info, now)
+		b, err := json.Marshal(l.Info)
+		if err != nil {
+			return nil, err
+		}
+		res, err := s.db.Exec(`UPDATE docx_translate_jobs SET state = 'running', lease_token = ?, lease_until = ?, info = ?
+WHERE id = ? AND lease_token = ? AND (state = 'queued' OR state = 'running' AND lease_until < ?)`,
+			l.Token, until.UnixNano(), string(b), id, token, now.UnixNano())
+		if err != nil {
+			return nil, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if n == 1 {
+			return l, nil
+		}
+	}
+}
+
+// Update 实现 JobStore
+func (s *SQLiteJobStore) Update(info JobInfo, token string, until time.Time) error {
+	b, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`UPDATE docx_translate_jobs SET info = ?, lease_until = ? WHERE id = ? AND lease_token = ? AND state = 'running'`,
+		string(b), until.UnixNano(), info.ID, token)
+	return s.leased(res, err, info.ID)
+}
+
+// Finish 实现 JobStore
+func (s *SQLiteJobStore) Finish(info JobInfo, token string, artifact []byte) error {
+	b, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	var expires int64
+	if info.Expires != nil {
+		expires = info.Expires.UnixNano()
+	}
+	res, err := s.db.Exec(`UPDATE docx_translate_jobs SET state = ?, info = ?, artifact = ?, source = NULL, lease_token = '', lease_until = 0, expires = ?
+WHERE id = ? AND lease_token = ? AND state = 'running'`, info.State.String(), string(b), artifact, expires, info.ID, token)
+	return s.leased(res, err, info.ID)
+}
+
+// leased 将没有更新任何行的 UPDATE 转为 ErrJobNotFound 或 ErrJobLeaseLost
+func (s *SQLiteJobStore) leased(res sql.Result, err error, id string) error {
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	if _, err = s.Get(id); err != nil {
+		return err
+	}
+	return ErrJobLeaseLost
+}
+
+// Cancel 实现 JobStore
+func (s *SQLiteJobStore) Cancel(id string) error {
+	info, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+	if info.State.finished() {
+		return ErrJobFinished
+	}
+	canceled(&info, time.Now())
+	b, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	res, err := s.db.Exec(`UPDATE docx_translate_jobs SET state = 'canceled', info = ?, source = NULL, lease_token = '', lease_until = 0
+WHERE id = ? AND state IN ('queued', 'running')`, string(b), id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	// 查询之后任务已经结束
+	return ErrJobFinished
+}
+
+// Get 实现 JobStore
+func (s *SQLiteJobStore) Get(id string) (JobInfo, error) {
+	var (
+		info JobInfo
+		b    string
+	)
+	err := s.db.QueryRow(`SELECT info FROM docx_translate_jobs WHERE id = ?`, id).Scan(&b)
+	if errors.Is(err, sql.ErrNoRows) {
+		return info, ErrJobNotFound
+	}
+	if err != nil {
+		return info, err
+	}
+	err = json.Unmarshal([]byte(b), &info)
+	return info, err
+}
+
+// Artifact 实现 JobStore
+func (s *SQLiteJobStore) Artifact(id string) ([]byte, error) {
+	var artifact []byte
+	err := s.db.QueryRow(`SELECT artifact FROM docx_translate_jobs WHERE id = ? AND artifact IS NOT NULL`, id).Scan(&artifact)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return artifact, err
+	}
+	if info, err := s.Get(id); err == nil && info.Expired {
+		return nil, ErrArtifactExpired
+	}
+	return nil, ErrJobNotFound
+}
+
+// List 实现 JobStore
+func (s *SQLiteJobStore) List() ([]JobInfo, error) {
+	rows, err := s.db.Query(`SELECT info FROM docx_translate_jobs ORDER BY created`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	infos := []JobInfo{}
+	for rows.Next() {
+		var (
+			info JobInfo
+			b    string
+		)
+		if err = rows.Scan(&b); err != nil {
+			return nil, err
+		}
+		if err = json.Unmarshal([]byte(b), &info); err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, rows.Err()
+}
+
+// Delete 实现 JobStore
+func (s *SQLiteJobStore) Delete(id string) error {
+	res, err := s.db.Exec(`DELETE FROM docx_translate_jobs WHERE id = ? AND state NOT IN ('queued', 'running')`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	if _, err = s.Get(id); err != nil {
+		return err
+	}
+	return ErrJobRunning
+}
+
+// Expire 实现 JobStore
+func (s *SQLiteJobStore) Expire(before time.Time) (int, error) {
+	rows, err := s.db.Query(`SELECT info FROM docx_translate_jobs WHERE expires > 0 AND expires < ?`, before.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	var infos []JobInfo
+	for rows.Next() {
+		var (
+			info JobInfo
+			b    string
+		)
+		if err = rows.Scan(&b); err == nil {
+			err = json.Unmarshal([]byte(b), &info)
+		}
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		infos = append(infos, info)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, info := range infos {
+		expired(&info)
+		b, err := json.Marshal(info)
+		if err != nil {
+			return n, err
+		}
+		res, err := s.db.Exec(`UPDATE docx_translate_jobs SET info = ?, source = NULL, artifact = NULL, expires = 0 WHERE id = ? AND expires > 0`,
+			string(b), info.ID)
+		if err != nil {
+			return n, err
+		}
+		if k, err := res.RowsAffected(); err == nil {
+			n += int(k)
+		}
+	}
+	return n, nil
+}
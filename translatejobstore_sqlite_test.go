@@ -0,0 +1,31 @@
+//go:build sqlite
+
+// 需要 cgo: go test -tags sqlite
+
+package docx
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSQLiteJobStore(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "jobs.db")+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	store, err := NewSQLiteJobStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testJobStore(t, store)
+
+	// 表已存在时可以再次创建
+	if _, err = NewSQLiteJobStore(db); err != nil {
+		t.Fatal(err)
+	}
+}
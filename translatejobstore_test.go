@@ -0,0 +1,143 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryJobStore(t *testing.T) {
+	testJobStore(t, NewMemoryJobStore())
+}
+
+func TestEncryptedJobStore(t *testing.T) {
+	aead, err := NewArtifactCipher(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testJobStore(t, EncryptJobStore(NewMemoryJobStore(), aead))
+}
+
+// testJobStore 检查 store 是否符合 JobStore 的约定, store 必须是空的
+func testJobStore(t *testing.T, store JobStore) {
+	t.Helper()
+	created := time.Now().Add(-time.Hour)
+	ids := []string{"b", "a", "c"}
+	for i, id := range ids {
+		info := JobInfo{ID: id, State: JobQueued, TargetLanguage: "English", Size: 3, Created: created.Add(time.Duration(i) * time.Second)}
+		if err := store.Create(info, TranslateFileOptions{TargetLanguage: "English", Format: "txt"}, []byte("src"+id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if infos, err := store.List(); err != nil || len(infos) != 3 || infos[0].ID != "b" || infos[1].ID != "a" || infos[2].ID != "c" {
+		t.Fatalf("unexpected jobs: %+v %v", infos, err)
+	}
+	if _, err := store.Get("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatal("expected ErrJobNotFound, got", err)
+	}
+
+	// 按提交的先后领取
+	b, err := store.Claim(time.Now().Add(-time.Millisecond))
+	if err != nil || b.Info.ID != "b" || b.Info.State != JobRunning || b.Info.Started == nil ||
+		string(b.Source) != "srcb" || b.Options.Format != "txt" || b.Token == "" {
+		t.Fatalf("unexpected lease: %+v %v", b, err)
+	}
+	// b 的租约已经过期, 再次被领取
+	b2, err := store.Claim(time.Now().Add(time.Minute))
+	if err != nil || b2.Info.ID != "b" || b2.Token == b.Token || string(b2.Source) != "srcb" {
+		t.Fatalf("unexpected lease: %+v %v", b2, err)
+	}
+	if err = store.Update(b.Info, b.Token, time.Now().Add(time.Minute)); !errors.Is(err, ErrJobLeaseLost) {
+		t.Fatal("expected ErrJobLeaseLost, got", err)
+	}
+	if err = store.Update(JobInfo{ID: "missing"}, b.Token, time.Now()); !errors.Is(err, ErrJobNotFound) {
+		t.Fatal("expected ErrJobNotFound, got", err)
+	}
+	b2.Info.Done, b2.Info.Total = 1, 2
+	if err = store.Update(b2.Info, b2.Token, time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := store.Get("b"); err != nil || info.State != JobRunning || info.Done != 1 || info.Total != 2 {
+		t.Fatalf("unexpected job: %+v %v", info, err)
+	}
+	a, err := store.Claim(time.Now().Add(time.Minute))
+	if err != nil || a.Info.ID != "a" {
+		t.Fatalf("unexpected lease: %+v %v", a, err)
+	}
+
+	// 取消排队的任务, 然后队列为空
+	if err = store.Cancel("c"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.Claim(time.Now().Add(time.Minute)); !errors.Is(err, ErrQueueEmpty) {
+		t.Fatal("expected ErrQueueEmpty, got", err)
+	}
+	if info, err := store.Get("c"); err != nil || info.State != JobCanceled || info.Finished == nil {
+		t.Fatalf("unexpected job: %+v %v", info, err)
+	}
+	if _, err = store.Artifact("c"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatal("expected ErrJobNotFound, got", err)
+	}
+	if err = store.Cancel("c"); !errors.Is(err, ErrJobFinished) {
+		t.Fatal("expected ErrJobFinished, got", err)
+	}
+	if err = store.Cancel("missing"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatal("expected ErrJobNotFound, got", err)
+	}
+
+	// 结束任务
+	if err = store.Delete("b"); !errors.Is(err, ErrJobRunning) {
+		t.Fatal("expected ErrJobRunning, got", err)
+	}
+	now := time.Now()
+	expires := now.Add(time.Millisecond)
+	b2.Info.State, b2.Info.Finished, b2.Info.ArtifactSize, b2.Info.Expires = JobSucceeded, &now, 3, &expires
+	if err = store.Finish(b2.Info, b2.Token, []byte("dst")); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Finish(b2.Info, b2.Token, []byte("dst")); !errors.Is(err, ErrJobLeaseLost) {
+		t.Fatal("expected ErrJobLeaseLost, got", err)
+	}
+	a.Info.State, a.Info.Finished, a.Info.Error = JobFailed, &now, "failed"
+	if err = store.Finish(a.Info, a.Token, nil); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := store.Artifact("b"); err != nil || string(data) != "dst" {
+		t.Fatalf("unexpected artifact: %q %v", data, err)
+	}
+	if _, err = store.Artifact("a"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatal("expected ErrJobNotFound, got", err)
+	}
+	if err = store.Cancel("b"); !errors.Is(err, ErrJobFinished) {
+		t.Fatal("expected ErrJobFinished, got", err)
+	}
+
+	// 过期删除原文与译文
+	if n, err := store.Expire(now); err != nil || n != 0 {
+		t.Fatal("unexpected Expire result:", n, err)
+	}
+	if n, err := store.Expire(now.Add(time.Second)); err != nil || n != 1 {
+		t.Fatal("unexpected Expire result:", n, err)
+	}
+	if n, err := store.Expire(now.Add(time.Second)); err != nil || n != 0 {
+		t.Fatal("unexpected Expire result:", n, err)
+	}
+	if info, err := store.Get("b"); err != nil || info.State != JobSucceeded || !info.Expired || info.ArtifactSize != 0 {
+		t.Fatalf("unexpected job: %+v %v", info, err)
+	}
+	if _, err = store.Artifact("b"); !errors.Is(err, ErrArtifactExpired) {
+		t.Fatal("expected ErrArtifactExpired, got", err)
+	}
+
+	// 删除任务
+	if err = store.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Delete("a"); !errors.Is(err, ErrJobNotFound) {
+		t.Fatal("expected ErrJobNotFound, got", err)
+	}
+	if infos, err := store.List(); err != nil || len(infos) != 2 || infos[0].ID != "b" || infos[1].ID != "c" {
+		t.Fatalf("unexpected jobs: %+v %v", infos, err)
+	}
+}
@@ -0,0 +1,276 @@
+package docx
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// languageNames 将常见的语言名称映射为 ISO 639-1 代码
+var languageNames = map[string]string{
+	"chinese": "zh", "中文": "zh", "汉语": "zh", "简体中文": "zh", "繁体中文": "zh", "繁體中文": "zh",
+	"simplified chinese": "zh", "traditional chinese": "zh",
+	"english": "en", "英语": "en", "英文": "en",
+	"japanese": "ja", "日语": "ja", "日文": "ja", "日本語": "ja",
+	"korean": "ko", "韩语": "ko", "韩文": "ko", "한국어": "ko",
+	"french": "fr", "法语": "fr", "français": "fr",
+	"german": "de", "德语": "de", "deutsch": "de",
+	"spanish": "es", "西班牙语": "es", "español": "es",
+	"italian": "it", "意大利语": "it",
+	"portuguese": "pt", "葡萄牙语": "pt",
+	"russian": "ru", "俄语": "ru",
+	"arabic": "ar", "阿拉伯语": "ar",
+	"hebrew": "he", "希伯来语": "he",
+	"persian": "fa", "波斯语": "fa",
+	"urdu": "ur", "乌尔都语": "ur",
+	"thai": "th", "泰语": "th",
+	"vietnamese": "vi", "越南语": "vi",
+	"dutch": "nl", "荷兰语": "nl",
+}
+
+// LanguageCode 将 "English", "中文", "ja-JP", "zh_CN" 等写法归一化为 ISO 639-1 代码,
+// 无法识别时返回小写后的原值
+func LanguageCode(lang string) string {
+	l := strings.ToLower(strings.TrimSpace(lang))
+	if code, ok := languageNames[l]; ok {
+		return code
+	}
+	if i := strings.IndexAny(l, "-_"); i > 0 {
+		l = l[:i]
+	}
+	if code, ok := languageNames[l]; ok {
+		return code
+	}
+	return l
+}
+
+// LanguageFileName 返回在文件名 name 的扩展名之前插入语言 lang 的代码后的文件名, 如 "a.docx" 与 "Japanese" 为 "a.ja.docx";
+// 无法识别的语言名转为只含字母、数字与连字符的代码, 如 "Klingon (pIqaD)" 为 "klingon-piqad", 没有字母与数字时为 "translated"
+func LanguageFileName(name, lang string) string {
+	code := languageFileCode(lang)
+	if code == "" {
+		code = "translated"
+	}
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + code + ext
+}
+
+// languageFileCode 返回语言 lang 可以用在文件名中的代码, 字母与数字以外的字符连续出现时替换为一个连字符
+func languageFileCode(lang string) string {
+	return strings.Join(strings.FieldsFunc(LanguageCode(lang), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+}
+
+// isScriptioContinua 判断语言书写时词与词之间是否不加空格
+func isScriptioContinua(lang string) bool {
+	switch LanguageCode(lang) {
+	case "zh", "ja", "th", "lo", "km", "my":
+		return true
+	default:
+		return false
+	}
+}
+
+// languageTags 是各语言在 Word 中默认使用的语言标记 (w:lang)
+var languageTags = map[string]string{
+	"zh": "zh-CN", "en": "en-US", "ja": "ja-JP", "ko": "ko-KR", "fr": "fr-FR", "de": "de-DE",
+	"es": "es-ES", "it": "it-IT", "pt": "pt-BR", "ru": "ru-RU", "ar": "ar-SA", "he": "he-IL",
+	"fa": "fa-IR", "ur": "ur-PK", "th": "th-TH", "vi": "vi-VN", "nl": "nl-NL",
+}
+
+// languageTag 返回语言 lang 在 Word 中的语言标记, 如 "English" 为 "en-US", 繁体中文为 "zh-TW";
+// 已带文字或地区的写法 (如 "en-GB", "zh_TW", "sr-latn-rs") 按 BCP 47 的大小写惯例使用, 无法识别时返回空字符串
+func languageTag(lang string) string {
+	l := strings.TrimSpace(lang)
+	switch strings.ToLower(l) {
+	case "traditional chinese", "繁体中文", "繁體中文":
+		return "zh-TW"
+	}
+	if i := strings.IndexAny(l, "-_"); i > 0 && i <= 3 && i < len(l)-1 {
+		return canonicalTag(l)
+	}
+	return languageTags[LanguageCode(l)]
+}
+
+// canonicalTag 按 BCP 47 的惯例规范语言标记的大小写与分隔符: 语言小写, 四个字母的文字首字母大写 (Hans, Latn),
+// 两个字母的地区大写 (CN, RS), 其余子标记与单字母扩展 (如 -u-, -x-) 之后的子标记小写
+func canonicalTag(tag string) string {
+	subtags := strings.FieldsFunc(strings.ToLower(tag), func(r rune) bool { return r == '-' || r == '_' })
+	for i := 1; i < len(subtags); i++ {
+		s := subtags[i]
+		if len(s) == 1 {
+			break
+		}
+		switch {
+		case len(s) == 2 && isLetters(s):
+			subtags[i] = strings.ToUpper(s)
+		case len(s) == 4 && isLetters(s):
+			subtags[i] = strings.ToUpper(s[:1]) + s[1:]
+		}
+	}
+	return strings.Join(subtags, "-")
+}
+
+func isLetters(s string) bool {
+	for _, r := range s {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// withLanguageTag 返回将 l 中对应语言 tag 的文字的标记改为 tag 的副本:
+// 中日韩语言为 eastAsia, 从右向左书写的语言为 bidi, 其他为 val
+func withLanguageTag(l Lang, tag string) *Lang {
+	switch LanguageCode(tag) {
+	case "zh", "ja", "ko":
+		l.EastAsia = tag
+	default:
+		if isRightToLeft(tag) {
+			l.Bidi = tag
+		} else {
+			l.Val = tag
+		}
+	}
+	return &l
+}
+
+// isRightToLeft 判断语言是否从右向左书写
+func isRightToLeft(lang string) bool {
+	switch LanguageCode(lang) {
+	case "ar", "he", "fa", "ur", "yi", "ps", "dv":
+		return true
+	default:
+		return false
+	}
+}
+
+// directParagraph 将段落的书写方向 (w:bidi) 设为从右向左或从左向右; 段落属性可能与原文档共用, 先复制再修改
+func directParagraph(p *Paragraph, rtl bool) {
+	if rtl == (p.Properties != nil && p.Properties.Bidi != nil) {
+		return
+	}
+	var pp ParagraphProperties
+	if p.Properties != nil {
+		pp = *p.Properties
+	}
+	pp.Bidi = nil
+	if rtl {
+		pp.Bidi = &Bidi{}
+	}
+	p.Properties = &pp
+}
+
+// directRun 将 Run 的书写方向 (w:rtl) 设为从右向左或从左向右, 与 directParagraph 一样先复制再修改
+func directRun(r *Run, rtl bool) {
+	if rtl == (r.RunProperties != nil && r.RunProperties.RTL != nil) {
+		return
+	}
+	var rp RunProperties
+	if r.RunProperties != nil {
+		rp = *r.RunProperties
+	}
+	rp.RTL = nil
+	if rtl {
+		rp.RTL = &RTL{}
+	}
+	r.RunProperties = &rp
+}
+
+// directRuns 对 children 中的 Run、超链接与插入修订中的 Run 调用 directRun
+func directRuns(children []interface{}, rtl bool) {
+	for _, child := range children {
+		switch o := child.(type) {
+		case *Run:
+			directRun(o, rtl)
+		case *Hyperlink:
+			directRun(&o.Run, rtl)
+		case *Ins:
+			directRuns(o.Children, rtl)
+		}
+	}
+}
+
+// directSections 将文档 doc 中各节的版式方向 (w:bidi) 与装订线位置 (w:rtlGutter) 设为从右向左或从左向右
+func directSections(doc *Docx, rtl bool) {
+	editSections(doc, func(sect *SectPr) {
+		sect.Bidi, sect.RtlGutter = nil, nil
+		if rtl {
+			sect.Bidi, sect.RtlGutter = &Bidi{}, &RtlGutter{}
+		}
+	})
+}
+
+// retagRun 将 Run 上的语言标记改为 tag, 没有语言标记的 Run 沿用样式中的标记 (见 retagStyles);
+// Run 的格式可能与原文档共用, 先复制再修改
+func retagRun(r *Run, tag string) {
+	if tag == "" || r.RunProperties == nil || r.RunProperties.Lang == nil {
+		return
+	}
+	rp := *r.RunProperties
+	rp.Lang = withLanguageTag(*rp.Lang, tag)
+	r.RunProperties = &rp
+}
+
+// retagRuns 将 children 中的 Run、超链接与插入修订中的 Run 的语言标记改为 tag
+func retagRuns(children []interface{}, tag string) {
+	for _, child := range children {
+		switch o := child.(type) {
+		case *Run:
+			retagRun(o, tag)
+		case *Hyperlink:
+			retagRun(&o.Run, tag)
+		case *Ins:
+			retagRuns(o.Children, tag)
+		}
+	}
+}
+
+var (
+	// langElemRe 匹配样式中的语言标记
+	langElemRe = regexp.MustCompile(`<w:lang\b[^>]*>`)
+	// langAttrRe 匹配语言标记中的属性, 第 1 组是属性名
+	langAttrRe = regexp.MustCompile(`\sw:(val|eastAsia|bidi)="[^"]*"`)
+)
+
+// retagStyles 将文档 doc 的样式 (包括默认格式) 中的语言标记改为 tag, 使拼写检查按译文的语言进行
+func retagStyles(doc *Docx, tag string) {
+	name, ok := partName(doc, relStyles)
+	if tag == "" || !ok {
+		return
+	}
+	data, err := doc.readPart(name)
+	if err != nil {
+		return
+	}
+	attr := "val"
+	switch l := withLanguageTag(Lang{}, tag); {
+	case l.EastAsia != "":
+		attr = "eastAsia"
+	case l.Bidi != "":
+		attr = "bidi"
+	}
+	data = langElemRe.ReplaceAllFunc(data, func(elem []byte) []byte {
+		set := ` w:` + attr + `="` + tag + `"`
+		replaced := false
+		elem = langAttrRe.ReplaceAllFunc(elem, func(a []byte) []byte {
+			if string(langAttrRe.FindSubmatch(a)[1]) != attr {
+				return a
+			}
+			replaced = true
+			return []byte(set)
+		})
+		if replaced {
+			return elem
+		}
+		end := len(elem) - 1
+		if elem[end-1] == '/' {
+			end--
+		}
+		return append(append(append([]byte(nil), elem[:end]...), set...), elem[end:]...)
+	})
+	doc.setPart(name, data)
+}
@@ -0,0 +1,110 @@
+package docx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ParseWarning 是宽松模式下解析时跳过的问题, 见 ParseLenient
+type ParseWarning struct {
+	Part    string // Part 是出问题的部件, 如 word/document.xml
+	Element string // Element 是被跳过的元素, 如 p、tbl; 整个部件出问题时为空
+	Err     error  // Err 是跳过的原因
+}
+
+func (w ParseWarning) String() string {
+	if w.Element == "" {
+		return w.Part + ": " + w.Err.Error()
+	}
+	return w.Part + " <" + w.Element + ">: " + w.Err.Error()
+}
+
+// ParseLenient 同 Parse, 但容忍其他工具生成的文档中常见的瑕疵:
+// 正文中无法解析的段落、表格等元素被跳过, 截断或标签不匹配的 XML 保留已经解析的部分,
+// 未知的实体与不带引号的属性按非严格模式解析, 无法读取的媒体文件与格式不规范的关系 ID 被忽略.
+// 跳过的问题作为 ParseWarning 返回, 只有 zip 包本身无法打开时才返回错误
+func ParseLenient(reader io.ReaderAt, size int64) (*Docx, []ParseWarning, error) {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err := unpack(zipReader, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, doc.warnings, nil
+}
+
+// WithLenientParsing 设置 TranslateFile 用 ParseLenient 打开 docx, 跳过的问题以 LogLevelWarn 写入日志;
+// 默认严格解析, 任何解析错误都会中止翻译
+func (t *Translator) WithLenientParsing(enabled bool) *Translator {
+	t.lenient = enabled
+	return t
+}
+
+// tolerate 在宽松模式下记录解码 part 中的元素 elem 时出现的错误 err 并返回 true, 表示跳过该元素继续解析;
+// XML 语法错误之后解码器无法继续, 总是返回 false
+func (f *Docx) tolerate(part, elem string, err error) bool {
+	if f == nil || !f.lenient {
+		return false
+	}
+	var syntax *xml.SyntaxError
+	if errors.As(err, &syntax) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return false
+	}
+	f.warn(part, elem, err)
+	return true
+}
+
+// decodeElement 同 d.DecodeElement(v, start); 宽松模式下先读出整个元素再解码,
+// 使解码 v 出错时 d 仍然停在元素的结尾, 可以继续解析后面的元素
+func (f *Docx) decodeElement(d *xml.Decoder, v interface{}, start *xml.StartElement) error {
+	if f == nil || !f.lenient {
+		return d.DecodeElement(v, start)
+	}
+	tokens := tokenSlice{start.Copy()}
+	for depth := 1; depth > 0; {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		tokens = append(tokens, xml.CopyToken(t))
+	}
+	return xml.NewTokenDecoder(&tokens).Decode(v)
+}
+
+// tokenSlice 依次返回保存的 XML 标记
+type tokenSlice []xml.Token
+
+// Token 实现 xml.TokenReader
+func (s *tokenSlice) Token() (xml.Token, error) {
+	if len(*s) == 0 {
+		return nil, io.EOF
+	}
+	t := (*s)[0]
+	*s = (*s)[1:]
+	return t, nil
+}
+
+// warn 记录一个 ParseWarning
+func (f *Docx) warn(part, elem string, err error) {
+	f.warnings = append(f.warnings, ParseWarning{Part: part, Element: elem, Err: err})
+}
+
+// lenientDocumentError 在宽松模式下将解码 word/document.xml 的错误 err 记为警告, 保留已经解析的正文
+func (f *Docx) lenientDocumentError(err error) error {
+	if err == nil || !f.lenient {
+		return err
+	}
+	f.warn("word/document.xml", "", fmt.Errorf("kept %d body items: %w", len(f.Document.Body.Items), err))
+	return nil
+}
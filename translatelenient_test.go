@@ -0,0 +1,97 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+// docxWithBody 返回正文为 body 的 docx 文件
+func docxWithBody(t *testing.T, body string) []byte {
+	var base bytes.Buffer
+	if _, err := newTestDoc().WriteTo(&base); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(base.Bytes()), int64(base.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	zw := zip.NewWriter(&out)
+	for _, f := range zr.File {
+		w, err := zw.Create(f.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f.Name == "word/document.xml" {
+			_, _ = io.WriteString(w, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`+
+				`<w:document xmlns:w="`+XMLNS_W+`"><w:body>`+body)
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.Copy(w, rc)
+		rc.Close()
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+func TestParseLenient(t *testing.T) {
+	const badSect = `<w:sectPr><w:pgMar w:top="1440.5"/></w:sectPr>`
+	data := docxWithBody(t, `<w:p><w:r><w:t>one</w:t></w:r></w:p>`+
+		`<w:p><w:pPr>`+badSect+`</w:pPr><w:r><w:t>lost</w:t></w:r></w:p>`+
+		`<w:tbl><w:tr><w:tc><w:p><w:pPr>`+badSect+`</w:pPr></w:p><w:p><w:r><w:t>two</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`+
+		`<w:p><w:r><w:t>three &nbsp;</w:t></w:r></w:p>`+badSect+
+		`<w:p><w:r><w:t>trunc`)
+	if _, err := Parse(bytes.NewReader(data), int64(len(data))); err == nil {
+		t.Fatal("expected strict parsing to fail")
+	}
+	doc, warnings, err := ParseLenient(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 4 {
+		t.Fatal("expected 4 warnings, got", warnings)
+	}
+	items := doc.Document.Body.Items
+	if len(items) != 3 {
+		t.Fatal("expected 3 body items, got", len(items))
+	}
+	if s := items[0].(*Paragraph).String(); s != "one" {
+		t.Fatal("unexpected first paragraph:", s)
+	}
+	cell := items[1].(*Table).TableRows[0].TableCells[0]
+	if len(cell.Paragraphs) != 1 || cell.Paragraphs[0].String() != "two" {
+		t.Fatal("expected the malformed cell paragraph to be skipped")
+	}
+	if s := items[2].(*Paragraph).String(); !strings.HasPrefix(s, "three") {
+		t.Fatal("unexpected third paragraph:", s)
+	}
+
+	var logbuf, out bytes.Buffer
+	tr := NewTranslator("", "").WithLenientParsing(true).WithLogger(NewStdLogger(&logbuf, LogLevelWarn)).
+		WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+			return strings.ToUpper(text), nil
+		}))
+	if err = tr.TranslateFile(context.Background(), bytes.NewReader(data), &out, TranslateFileOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(logbuf.String(), "WARN"); n != 4 {
+		t.Fatal("expected 4 logged warnings, got", n)
+	}
+	newDoc, err := Parse(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "ONE" {
+		t.Fatal("unexpected translation:", s)
+	}
+}
@@ -0,0 +1,115 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrLimitExceeded 任务超出了 WithLimits 设置的限制, 具体原因见 *LimitError
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// LimitKind 是超出的限制种类
+type LimitKind uint8
+
+const (
+	// LimitSegments 翻译单元的数量
+	LimitSegments LimitKind = iota
+	// LimitFileSize 输入文件的字节数
+	LimitFileSize
+	// LimitMediaBytes 文档中媒体文件 (图片等) 解压后的总字节数
+	LimitMediaBytes
+)
+
+func (k LimitKind) String() string {
+	switch k {
+	case LimitSegments:
+		return "segments"
+	case LimitFileSize:
+		return "file size"
+	case LimitMediaBytes:
+		return "media bytes"
+	}
+	return "unknown"
+}
+
+// Limits 限制单个任务可以消耗的资源, 供服务端拒绝恶意或过大的上传; 零值字段表示不限制
+type Limits struct {
+	// MaxSegments 是一个文档中翻译单元的最大数量, 在请求翻译服务之前检查
+	MaxSegments int
+	// MaxFileSize 是 TranslateFile 读取的输入文件的最大字节数, 超出时不再继续读取
+	MaxFileSize int64
+	// MaxMediaBytes 是文档中媒体文件解压后的总字节数上限, TranslateFile 在解析 docx 之前
+	// 按 zip 目录中记录的大小检查, 以免解压炸弹耗尽内存
+	MaxMediaBytes int64
+}
+
+// LimitError 记录超出的限制
+type LimitError struct {
+	Kind   LimitKind
+	Max    int64 // Max 是设置的上限
+	Actual int64 // Actual 是实际的值, 文件过大时为已读取的字节数
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s %d exceeds %d", ErrLimitExceeded, e.Kind, e.Actual, e.Max)
+}
+
+// Is 使 errors.Is(err, ErrLimitExceeded) 成立
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// WithLimits 设置单个任务的资源限制, 超出时返回 *LimitError; 默认不限制
+func (t *Translator) WithLimits(l Limits) *Translator {
+	t.limits = l
+	return t
+}
+
+// checkSegments 检查翻译单元的数量
+func (t *Translator) checkSegments(n int) error {
+	if t.limits.MaxSegments > 0 && n > t.limits.MaxSegments {
+		return &LimitError{Kind: LimitSegments, Max: int64(t.limits.MaxSegments), Actual: int64(n)}
+	}
+	return nil
+}
+
+// checkMediaBytes 检查已解析的文档中媒体文件的总字节数
+func (t *Translator) checkMediaBytes(doc *Docx) error {
+	if t.limits.MaxMediaBytes <= 0 {
+		return nil
+	}
+	doc.mediaMu.RLock()
+	var total int64
+	for _, m := range doc.media {
+		total += int64(len(m.Data))
+	}
+	doc.mediaMu.RUnlock()
+	if total > t.limits.MaxMediaBytes {
+		return &LimitError{Kind: LimitMediaBytes, Max: t.limits.MaxMediaBytes, Actual: total}
+	}
+	return nil
+}
+
+// checkDocxMedia 在解析之前按 zip 目录检查 docx 中媒体文件解压后的总字节数
+func (t *Translator) checkDocxMedia(data []byte) error {
+	if t.limits.MaxMediaBytes <= 0 {
+		return nil
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	var total uint64
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, MEDIA_FOLDER) {
+			total += f.UncompressedSize64
+		}
+	}
+	if total > uint64(t.limits.MaxMediaBytes) {
+		return &LimitError{Kind: LimitMediaBytes, Max: t.limits.MaxMediaBytes, Actual: int64(total)}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLimits(t *testing.T) {
+	calls := 0
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		calls++
+		return strings.ToUpper(text), nil
+	})
+	doc := newTestDoc("a", "b", "c")
+	if _, err := doc.AddParagraph().AddInlineDrawingFrom("testdata/fumiamayoko.png"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	var le *LimitError
+	_, err := NewTranslator("", "").WithProvider(p).WithLimits(Limits{MaxSegments: 2}).TranslateDocx(doc, "English")
+	if !errors.As(err, &le) || le.Kind != LimitSegments || le.Actual != 3 || !errors.Is(err, ErrLimitExceeded) {
+		t.Fatal("expected a segment limit error, got", err)
+	}
+	if calls != 0 {
+		t.Fatal("provider must not be called when a limit is exceeded, calls:", calls)
+	}
+
+	tr := NewTranslator("", "").WithProvider(p).WithLimits(Limits{MaxFileSize: int64(buf.Len()) - 1})
+	err = tr.TranslateFile(context.Background(), bytes.NewReader(buf.Bytes()), &bytes.Buffer{}, TranslateFileOptions{TargetLanguage: "English"})
+	if !errors.As(err, &le) || le.Kind != LimitFileSize || le.Actual != le.Max+1 {
+		t.Fatal("expected a file size limit error, got", err)
+	}
+
+	tr = NewTranslator("", "").WithProvider(p).WithLimits(Limits{MaxMediaBytes: 16})
+	err = tr.TranslateFile(context.Background(), bytes.NewReader(buf.Bytes()), &bytes.Buffer{}, TranslateFileOptions{TargetLanguage: "English"})
+	if !errors.As(err, &le) || le.Kind != LimitMediaBytes {
+		t.Fatal("expected a media limit error, got", err)
+	}
+	if _, err = tr.TranslateDocx(doc, "English"); !errors.As(err, &le) || le.Kind != LimitMediaBytes {
+		t.Fatal("expected a media limit error, got", err)
+	}
+	if calls != 0 {
+		t.Fatal("provider must not be called when a limit is exceeded, calls:", calls)
+	}
+
+	tr = NewTranslator("", "").WithProvider(p).WithLimits(Limits{MaxSegments: 3, MaxFileSize: int64(buf.Len()), MaxMediaBytes: 1 << 20})
+	if err = tr.TranslateFile(context.Background(), bytes.NewReader(buf.Bytes()), &bytes.Buffer{}, TranslateFileOptions{TargetLanguage: "English"}); err != nil {
+		t.Fatal(err)
+	}
+}
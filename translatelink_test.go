@@ -0,0 +1,71 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTranslateDocxHyperlinks(t *testing.T) {
+	doc := New().WithDefaultTheme()
+	p := doc.AddParagraph()
+	p.AddText("请参阅")
+	h := p.AddLink("", "https://example.com/docs")
+	h.Tooltip = "打开文档"
+	h.Run.Children = []interface{}{&Text{Text: "文档"}}
+	p.AddText("了解详情")
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	words := strings.NewReplacer("请参阅", "See ", "文档", "the docs", "了解详情", " for details")
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return words.Replace(text), nil
+	}))
+	newDoc, report, err := tr.TranslateDocxWithReport(context.Background(), doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src := report.Segments[0].Source; src != "请参阅⟪1⟫文档⟪/1⟫了解详情" {
+		t.Fatal("unexpected source:", src)
+	}
+	np := newDoc.Document.Body.Items[0].(*Paragraph)
+	if len(np.Children) != 3 {
+		t.Fatalf("unexpected children: %#v", np.Children)
+	}
+	link, ok := np.Children[1].(*Hyperlink)
+	if !ok {
+		t.Fatalf("expected a hyperlink, got %T", np.Children[1])
+	}
+	if target, err := newDoc.ReferTarget(link.ID); err != nil || target != "https://example.com/docs" {
+		t.Fatal("unexpected link target:", target, err)
+	}
+	if link.Tooltip != "打开文档" || runText(&link.Run) != "the docs" {
+		t.Fatalf("unexpected hyperlink: %+v", link)
+	}
+	if s := paragraphText(np); s != "See  for details" {
+		t.Fatal("unexpected text:", s)
+	}
+
+	// 标记丢失时只保留译文的文字, 并在报告中警告
+	tr = NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return inlineTagRe.ReplaceAllString(words.Replace(text), ""), nil
+	}))
+	newDoc, report, err = tr.TranslateDocxWithReport(context.Background(), doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	np = newDoc.Document.Body.Items[0].(*Paragraph)
+	if len(np.Children) != 1 || paragraphText(np) != "See the docs for details" {
+		t.Fatalf("unexpected children: %#v", np.Children)
+	}
+	if w := report.Segments[0].Warnings; len(w) == 0 || w[0].Kind != QAPlaceholders {
+		t.Fatal("expected a placeholder warning, got", w)
+	}
+}
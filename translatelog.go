@@ -0,0 +1,101 @@
+package docx
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLevel 日志级别
+type LogLevel uint8
+
+const (
+	// LogLevelDebug 调试信息, 例如每一段的翻译结果
+	LogLevelDebug LogLevel = iota
+	// LogLevelInfo 一般信息
+	LogLevelInfo
+	// LogLevelWarn 不影响结果的异常, 例如保留原文的失败段落
+	LogLevelWarn
+	// LogLevelError 导致翻译中止的错误
+	LogLevelError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "DEBUG"
+	case LogLevelInfo:
+		return "INFO"
+	case LogLevelWarn:
+		return "WARN"
+	case LogLevelError:
+		return "ERROR"
+	default:
+		return "LEVEL(" + fmt.Sprint(uint8(l)) + ")"
+	}
+}
+
+// Logger 接收翻译过程中的诊断信息
+//
+// keyvals 为交替出现的键值对, 例如 "index", 3, "err", err
+type Logger interface {
+	Log(level LogLevel, msg string, keyvals ...interface{})
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Log(LogLevel, string, ...interface{}) {}
+
+// StdLogger 将不低于 Level 的日志以 "时间 级别 消息 k=v ..." 的格式逐行写入 Writer
+type StdLogger struct {
+	Level  LogLevel
+	Writer io.Writer
+
+	mu sync.Mutex
+}
+
+// NewStdLogger 创建一个写入 w 的 StdLogger
+func NewStdLogger(w io.Writer, level LogLevel) *StdLogger {
+	return &StdLogger{Level: level, Writer: w}
+}
+
+// Log 实现 Logger
+func (l *StdLogger) Log(level LogLevel, msg string, keyvals ...interface{}) {
+	if level < l.Level {
+		return
+	}
+	sb := strings.Builder{}
+	sb.WriteString(time.Now().Format(time.RFC3339))
+	sb.WriteByte(' ')
+	sb.WriteString(level.String())
+	sb.WriteByte(' ')
+	sb.WriteString(msg)
+	for i := 0; i < len(keyvals); i += 2 {
+		sb.WriteByte(' ')
+		sb.WriteString(fmt.Sprint(keyvals[i]))
+		sb.WriteByte('=')
+		if i+1 < len(keyvals) {
+			fmt.Fprintf(&sb, "%q", fmt.Sprint(keyvals[i+1]))
+		}
+	}
+	sb.WriteByte('\n')
+	l.mu.Lock()
+	_, _ = io.WriteString(l.Writer, sb.String())
+	l.mu.Unlock()
+}
+
+// WithLogger 设置诊断信息的输出位置, 默认不输出任何日志
+func (t *Translator) WithLogger(l Logger) *Translator {
+	t.logger = l
+	return t
+}
+
+// log 返回可用的 Logger
+func (t *Translator) log() Logger {
+	if t.logger == nil {
+		return nopLogger{}
+	}
+	return t.logger
+}
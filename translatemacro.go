@@ -0,0 +1,102 @@
+package docx
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"sync/atomic"
+)
+
+const (
+	relVBAProject  = `http://schemas.microsoft.com/office/2006/relationships/vbaProject`
+	relKeyMapCusts = `http://schemas.microsoft.com/office/2006/relationships/keyMapCustomizations`
+
+	contentTypeMacroDocument = "application/vnd.ms-word.document.macroEnabled.main+xml"
+	contentTypeVBAProject    = "application/vnd.ms-office.vbaProject"
+	contentTypeKeyMapCusts   = "application/vnd.ms-word.keyMapCustomizations+xml"
+)
+
+// macroParts 是启用宏的文档 (.docm) 中随宏一起复制到新文档的部件, 依次为关系类型、新文档中的文件名与默认的类型
+var macroParts = [...][3]string{
+	{relVBAProject, "vbaProject.bin", contentTypeVBAProject},
+	{relKeyMapCusts, "customizations.xml", contentTypeKeyMapCusts},
+}
+
+// IsMacroEnabled 判断 doc 是否为启用宏的文档 (.docm), 翻译后的文档应同样以 .docm 为扩展名保存
+func IsMacroEnabled(doc *Docx) bool {
+	if contentTypeOf(doc, "/word/document.xml") == contentTypeMacroDocument {
+		return true
+	}
+	_, ok := partName(doc, relVBAProject)
+	return ok
+}
+
+// copyMacros 将启用宏的原文档 src 中的宏 (vbaProject.bin 及其数据) 与快捷键设置原样复制到新文档 dst,
+// 并将 dst 的主文档类型改为启用宏的文档; src 不是启用宏的文档时不做处理.
+// TranslateDocxInPlace 本来就保留原文档的所有部件.
+func (t *Translator) copyMacros(src, dst *Docx) {
+	if !IsMacroEnabled(src) {
+		return
+	}
+	for _, mp := range macroParts {
+		typ, target, contentType := mp[0], mp[1], mp[2]
+		name, ok := partName(src, typ)
+		if !ok {
+			continue
+		}
+		data, err := src.readPart(name)
+		if err != nil {
+			t.log().Log(LogLevelWarn, "无法读取原文档的宏, 译文中将没有宏", "part", name, "err", err)
+			continue
+		}
+		newName := "word/" + target
+		related, err := partRelations(src, name, newName)
+		if err != nil {
+			t.log().Log(LogLevelWarn, "无法复制宏的关系, 译文中将没有宏", "part", name, "err", err)
+			continue
+		}
+		if ct := contentTypeOf(src, "/"+name); ct != "" {
+			contentType = ct
+		}
+		if !addContentType(dst, "/"+newName, contentType) {
+			continue
+		}
+		dst.docRelation.Relationship = append(dst.docRelation.Relationship, Relationship{
+			ID:     "rId" + strconv.Itoa(int(atomic.AddUintptr(&dst.rID, 1))),
+			Type:   typ,
+			Target: target,
+		})
+		dst.setPart(newName, data)
+		for _, f := range related {
+			if f.contentType != "" {
+				addContentType(dst, "/"+f.name, f.contentType)
+			}
+			dst.setPart(f.name, f.data)
+		}
+	}
+	if !setContentType(dst, "/word/document.xml", contentTypeMacroDocument) {
+		t.log().Log(LogLevelWarn, "无法将译文标记为启用宏的文档")
+	}
+}
+
+// setContentType 将 [Content_Types].xml 中部件 partName 的类型改为 contentType, 部件未登记时登记之; 返回是否成功
+func setContentType(doc *Docx, partName, contentType string) bool {
+	const name = "[Content_Types].xml"
+	data, err := doc.readPart(name)
+	if err != nil {
+		return false
+	}
+	re := regexp.MustCompile(`<Override\s[^>]*PartName="` + regexp.QuoteMeta(partName) + `"[^>]*/>`)
+	loc := re.FindIndex(data)
+	if loc == nil {
+		return addContentType(doc, partName, contentType)
+	}
+	override := `<Override PartName="` + partName + `" ContentType="` + contentType + `"/>`
+	var patched bytes.Buffer
+	patched.Grow(len(data) + len(override))
+	patched.Write(data[:loc[0]])
+	patched.WriteString(override)
+	patched.Write(data[loc[1]:])
+	doc.setPart(name, patched.Bytes())
+	return true
+}
@@ -0,0 +1,273 @@
+package docx
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ErrPlaceholderMissing 译文中缺少原文中的占位符, 被保护的内容无法还原
+var ErrPlaceholderMissing = errors.New("placeholder missing in translation")
+
+// DNTList 是不翻译 (do-not-translate) 列表, 如产品名、代码标识符,
+// 以及工单号这类用正则表达式描述的内容. 可以并发调用.
+//
+// 命中的内容在请求翻译服务前被替换为 ⟦1⟧ 这样的占位符, 翻译后原样还原.
+type DNTList struct {
+	mu       sync.RWMutex
+	terms    []string
+	patterns []*regexp.Regexp
+}
+
+// NewDNTList 创建一个包含 terms 的不翻译列表
+func NewDNTList(terms ...string) *DNTList {
+	l := &DNTList{}
+	for _, term := range terms {
+		l.AddTerm(term)
+	}
+	return l
+}
+
+// AddTerm 添加一个按原样 (区分大小写) 匹配的词; 以字母或数字开头结尾的词只匹配完整的词,
+// 如 Go 不会匹配 Google 中的 Go
+func (l *DNTList) AddTerm(term string) {
+	term = strings.TrimSpace(term)
+	if term == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.terms = append(l.terms, term)
+}
+
+// AddPattern 添加一个正则表达式, 如 `[A-Z]+-\d+` 匹配 JIRA-1234 这样的工单号
+func (l *DNTList) AddPattern(expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.patterns = append(l.patterns, re)
+	return nil
+}
+
+// Load 从 r 中逐行读取规则: 空行与以 # 开头的行被忽略, 以 re: 开头的行是正则表达式,
+// 其余每行是一个词
+func (l *DNTList) Load(r io.Reader) error {
+	sc := bufio.NewScanner(r)
+	n := 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "re:"):
+			err := l.AddPattern(strings.TrimSpace(line[3:]))
+			if err != nil {
+				return fmt.Errorf("line %d: %w", n, err)
+			}
+		default:
+			l.AddTerm(line)
+		}
+	}
+	return sc.Err()
+}
+
+// spans 返回 text 中所有命中规则的区间 (可能相互重叠)
+func (l *DNTList) spans(text string) [][2]int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	var spans [][2]int
+	for _, term := range l.terms {
+		for off := 0; ; {
+			i := strings.Index(text[off:], term)
+			if i < 0 {
+				break
+			}
+			start, end := off+i, off+i+len(term)
+			if isWordBoundary(text, start, end) {
+				spans = append(spans, [2]int{start, end})
+			}
+			off = start + 1
+		}
+	}
+	for _, re := range l.patterns {
+		for _, m := range re.FindAllStringIndex(text, -1) {
+			if m[1] > m[0] {
+				spans = append(spans, [2]int{m[0], m[1]})
+			}
+		}
+	}
+	return spans
+}
+
+// isWordBoundary 判断 text[start:end] 两端是否没有与之相连的 ASCII 字母或数字
+func isWordBoundary(text string, start, end int) bool {
+	if isWordByte(text[start]) && start > 0 && isWordByte(text[start-1]) {
+		return false
+	}
+	if isWordByte(text[end-1]) && end < len(text) && isWordByte(text[end]) {
+		return false
+	}
+	return true
+}
+
+func isWordByte(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b == '_'
+}
+
+// WithDNT 设置不翻译列表
+func (t *Translator) WithDNT(l *DNTList) *Translator {
+	t.dnt = l
+	return t
+}
+
+// ProtectKind 是自动识别并保护的内容种类, 可以按位组合
+type ProtectKind uint8
+
+const (
+	// ProtectURLs 保护 http(s)/ftp 链接与 www. 开头的网址
+	ProtectURLs ProtectKind = 1 << iota
+	// ProtectEmails 保护电子邮件地址
+	ProtectEmails
+	// ProtectNumbers 保护数字, 如 42, 3.14, 1,234.5 与 15%
+	ProtectNumbers
+	// ProtectPaths 保护文件路径, 如 C:\Windows, \\server\share 与 /usr/local/bin
+	ProtectPaths
+
+	// ProtectAll 保护以上所有内容
+	ProtectAll = ProtectURLs | ProtectEmails | ProtectNumbers | ProtectPaths
+)
+
+var (
+	protectURLRe    = regexp.MustCompile(`(?i)(?:\b(?:https?|ftp)://|\bwww\.)[^\s<>"'“”‘’，。；、（）]+`)
+	protectEmailRe  = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	protectNumberRe = regexp.MustCompile(`\d+(?:[.,]\d+)*%?`)
+	protectPathRe   = regexp.MustCompile(`(?:[A-Za-z]:\\|\\\\)[^\s"'<>|，。；]+|(?:~|\.{1,2})?(?:/[\w.\-]+){2,}/?`)
+)
+
+// WithProtection 设置自动保护的内容, 命中的内容与不翻译列表一样被替换为占位符,
+// 翻译后原样还原, 任何一个没有出现在译文中都视为翻译失败; 默认不保护
+func (t *Translator) WithProtection(kinds ProtectKind) *Translator {
+	t.protect = kinds
+	return t
+}
+
+// protectSpans 返回 text 中需要自动保护的区间
+func (t *Translator) protectSpans(text string) [][2]int {
+	var spans [][2]int
+	add := func(re *regexp.Regexp, check func(start, end int) (int, bool)) {
+		for _, m := range re.FindAllStringIndex(text, -1) {
+			end, ok := check(m[0], m[1])
+			if ok && end > m[0] {
+				spans = append(spans, [2]int{m[0], end})
+			}
+		}
+	}
+	if t.protect&ProtectURLs != 0 {
+		add(protectURLRe, func(start, end int) (int, bool) {
+			// 句末的标点不属于链接
+			return start + len(strings.TrimRight(text[start:end], ".,;:!?)]}")), true
+		})
+	}
+	if t.protect&ProtectEmails != 0 {
+		add(protectEmailRe, func(start, end int) (int, bool) { return end, true })
+	}
+	if t.protect&ProtectPaths != 0 {
+		add(protectPathRe, func(start, end int) (int, bool) {
+			// 2024/01/02 这样的日期与 a/b/c 中间的部分不是路径
+			if start > 0 && isWordByte(text[start-1]) {
+				return 0, false
+			}
+			return start + len(strings.TrimRight(text[start:end], ".,;:!?")), true
+		})
+	}
+	if t.protect&ProtectNumbers != 0 {
+		add(protectNumberRe, func(start, end int) (int, bool) {
+			// Win10, v2 这样与字母相连的数字是名称的一部分, 由翻译服务原样保留
+			if start > 0 && isWordByte(text[start-1]) || end < len(text) && isWordByte(text[end]) {
+				return 0, false
+			}
+			return end, true
+		})
+	}
+	return spans
+}
+
+// placeholder 返回第 i 个 (从 1 开始) 占位符
+func placeholder(i int) string {
+	return "⟦" + strconv.Itoa(i) + "⟧"
+}
+
+// mask 将 text 中需要保护的内容替换为占位符, 返回替换后的文本与被替换的原文;
+// 重叠的区间取最靠前、其次最长的一个
+func (t *Translator) mask(text string) (string, []string) {
+	var spans [][2]int
+	if t.dnt != nil {
+		spans = append(spans, t.dnt.spans(text)...)
+	}
+	if t.protect != 0 {
+		spans = append(spans, t.protectSpans(text)...)
+	}
+	if len(spans) == 0 {
+		return text, nil
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i][0] != spans[j][0] {
+			return spans[i][0] < spans[j][0]
+		}
+		return spans[i][1] > spans[j][1]
+	})
+	var (
+		sb        strings.Builder
+		originals []string
+		last      int
+	)
+	sb.Grow(len(text))
+	for _, sp := range spans {
+		if sp[0] < last {
+			continue
+		}
+		sb.WriteString(text[last:sp[0]])
+		originals = append(originals, text[sp[0]:sp[1]])
+		sb.WriteString(placeholder(len(originals)))
+		last = sp[1]
+	}
+	sb.WriteString(text[last:])
+	return sb.String(), originals
+}
+
+// unmask 将译文中的占位符还原为原文, 任何一个占位符缺失都会返回 ErrPlaceholderMissing
+func unmask(text string, originals []string) (string, error) {
+	for i, orig := range originals {
+		ph := placeholder(i + 1)
+		if !strings.Contains(text, ph) {
+			return "", fmt.Errorf("%w: %s (%s)", ErrPlaceholderMissing, ph, orig)
+		}
+		text = strings.ReplaceAll(text, ph, orig)
+	}
+	return text, nil
+}
+
+// onlyPlaceholders 判断去掉占位符与空白、标点之后是否没有需要翻译的内容
+func onlyPlaceholders(masked string, n int) bool {
+	for i := 1; i <= n; i++ {
+		masked = strings.ReplaceAll(masked, placeholder(i), "")
+	}
+	for len(masked) > 0 {
+		r, size := utf8.DecodeRuneInString(masked)
+		if !strings.ContainsRune(" \t\r\n.,;:!?()[]{}-–—/\\|'\"，。；：！？（）、", r) {
+			return false
+		}
+		masked = masked[size:]
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package docx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDNTList(t *testing.T) {
+	l := NewDNTList("Go")
+	err := l.Load(strings.NewReader("# 产品名\nDocxTranslate\nre: [A-Z]+-\\d+\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = l.Load(strings.NewReader("re: (")); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatal("expected a line-numbered error, got", err)
+	}
+	tr := NewTranslator("", "").WithDNT(l)
+	masked, originals := tr.mask("用 Go 和 DocxTranslate 修复 JIRA-12, 见 Google")
+	if masked != "用 ⟦1⟧ 和 ⟦2⟧ 修复 ⟦3⟧, 见 Google" || len(originals) != 3 {
+		t.Fatalf("unexpected mask: %q %q", masked, originals)
+	}
+	if s, err := unmask("Fix ⟦3⟧ with ⟦1⟧ and ⟦2⟧", originals); err != nil || s != "Fix JIRA-12 with Go and DocxTranslate" {
+		t.Fatal("unexpected unmask:", s, err)
+	}
+	if _, err := unmask("Fix with ⟦1⟧ and ⟦2⟧", originals); !errors.Is(err, ErrPlaceholderMissing) {
+		t.Fatal("expected ErrPlaceholderMissing, got", err)
+	}
+}
+
+func TestTranslateDocxDNT(t *testing.T) {
+	var seen []string
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		seen = append(seen, text)
+		if strings.Contains(text, "丢失") {
+			return "lost", nil
+		}
+		return strings.ToUpper(text), nil
+	})
+	tr := NewTranslator("", "").WithProvider(p).WithDNT(NewDNTList("DocxTranslate")).WithErrorPolicy(ErrorPolicyCollect)
+	newDoc, err := tr.TranslateDocx(newTestDoc("欢迎使用 DocxTranslate", "DocxTranslate", "丢失 DocxTranslate"), "English")
+	var ses SegmentErrors
+	if !errors.As(err, &ses) || len(ses) != 1 || !errors.Is(ses[0], ErrPlaceholderMissing) {
+		t.Fatal("expected one ErrPlaceholderMissing, got", err)
+	}
+	if len(seen) != 2 || seen[0] != "欢迎使用 ⟦1⟧" {
+		t.Fatalf("unexpected provider input: %q", seen)
+	}
+	// 中文原样返回, 占位符被还原; 只有不翻译内容的段落不请求翻译服务; 占位符丢失时保留原文
+	for i, want := range []string{"欢迎使用 DocxTranslate", "DocxTranslate", "丢失 DocxTranslate"} {
+		if s := newDoc.Document.Body.Items[i].(*Paragraph).String(); s != want {
+			t.Fatalf("paragraph %d: got %q, want %q", i, s, want)
+		}
+	}
+}
+
+func TestProtection(t *testing.T) {
+	tr := NewTranslator("", "").WithProtection(ProtectAll)
+	text := "详见 https://example.com/a?b=1. 或发邮件至 a.b@example.cn, 日志在 C:\\logs\\app.log 与 /var/log/app 中, 2024/01/02 增长 12.5%, Win10 除外"
+	masked, originals := tr.mask(text)
+	want := []string{"https://example.com/a?b=1", "a.b@example.cn", "C:\\logs\\app.log", "/var/log/app", "2024", "01", "02", "12.5%"}
+	if strings.Join(originals, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected originals: %q\nmasked: %s", originals, masked)
+	}
+	if !strings.Contains(masked, "Win10") {
+		t.Fatal("numbers attached to words must not be masked:", masked)
+	}
+	if s, err := unmask(masked, originals); err != nil || s != text {
+		t.Fatal("round trip failed:", s, err)
+	}
+
+	calls := 0
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		calls++
+		return strings.Replace(text, "⟦2⟧", "⟦3⟧", 1), nil // 模拟模型改动了占位符
+	})
+	newDoc, err := NewTranslator("", "").WithProvider(p).WithProtection(ProtectNumbers).WithErrorPolicy(ErrorPolicyCollect).
+		TranslateDocx(newTestDoc("2024", "第 1 章 第 2 节"), "English")
+	var ses SegmentErrors
+	if !errors.As(err, &ses) || len(ses) != 1 || !errors.Is(ses[0], ErrPlaceholderMissing) {
+		t.Fatal("expected a placeholder error, got", err)
+	}
+	if calls != 1 {
+		t.Fatal("a paragraph made of numbers only must not be sent, calls:", calls)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "2024" {
+		t.Fatal("unexpected number paragraph:", s)
+	}
+}
@@ -0,0 +1,258 @@
+package docx
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets 是翻译服务请求耗时直方图的上界, 单位为秒
+var DefaultLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Metrics 收集翻译的运行指标并以 Prometheus 的文本格式导出, 供运维监控基于本库的翻译服务,
+// 见 WithMetrics; 可以被多个 Translator 共用, 方法可以并发调用
+//
+// 导出的指标:
+//
+//	docx_translate_segments_total{status}                       翻译单元数, status 为 translated, failed 或 reused (沿用旧译文)
+//	docx_translate_provider_requests_total{provider,result}     请求翻译服务的次数, result 为 success 或 error
+//	docx_translate_provider_request_duration_seconds{provider}  请求翻译服务的耗时 (直方图)
+//	docx_translate_provider_retries_total{provider}             回复异常后以更严格的提示词重试的次数
+//	docx_translate_tokens_total{provider,direction}             token 数, direction 为 input 或 output
+//	docx_translate_cache_requests_total{result}                 查询译文缓存的次数, result 为 hit 或 miss
+//
+// Dashscope 的 token 数取自响应中的 usage, 其他翻译服务按 EstimateTokens 估算. 缓存命中率为 hit / (hit + miss),
+// 各翻译服务的失败率为 result="error" 的请求数占比.
+type Metrics struct {
+	mu       sync.Mutex
+	families []*metricFamily
+
+	segments *metricFamily
+	requests *metricFamily
+	latency  *metricFamily
+	retries  *metricFamily
+	tokens   *metricFamily
+	cache    *metricFamily
+}
+
+// metricFamily 是同名的一组指标, 每组标签值对应一个 metricSeries
+type metricFamily struct {
+	name, help string
+	histogram  bool
+	labels     []string
+	buckets    []float64
+	series     map[string]*metricSeries // series 的键是以 \xff 连接的标签值
+}
+
+type metricSeries struct {
+	values []string
+	value  float64  // value 是计数器的值或直方图的样本数
+	sum    float64  // sum 是直方图样本的和
+	counts []uint64 // counts 是直方图落在各个区间 (不累计) 的样本数
+}
+
+// NewMetrics 创建空的 Metrics
+func NewMetrics() *Metrics {
+	m := &Metrics{}
+	m.segments = m.counter("docx_translate_segments_total", "Translation units processed, by outcome.", "status")
+	m.requests = m.counter("docx_translate_provider_requests_total", "Requests sent to the translation provider.", "provider", "result")
+	m.latency = m.histogram("docx_translate_provider_request_duration_seconds", "Latency of translation provider requests.", DefaultLatencyBuckets, "provider")
+	m.retries = m.counter("docx_translate_provider_retries_total", "Requests retried after a malformed provider response.", "provider")
+	m.tokens = m.counter("docx_translate_tokens_total", "Tokens sent to and received from the translation provider.", "provider", "direction")
+	m.cache = m.counter("docx_translate_cache_requests_total", "Translation cache lookups.", "result")
+	return m
+}
+
+func (m *Metrics) counter(name, help string, labels ...string) *metricFamily {
+	f := &metricFamily{name: name, help: help, labels: labels, series: map[string]*metricSeries{}}
+	m.families = append(m.families, f)
+	return f
+}
+
+func (m *Metrics) histogram(name, help string, buckets []float64, labels ...string) *metricFamily {
+	f := m.counter(name, help, labels...)
+	f.histogram, f.buckets = true, buckets
+	return f
+}
+
+// with 返回标签值 values 对应的 metricSeries, 不存在时创建; 调用方需持有 m.mu
+func (f *metricFamily) with(values ...string) *metricSeries {
+	key := strings.Join(values, "\xff")
+	s := f.series[key]
+	if s == nil {
+		s = &metricSeries{values: values}
+		if f.histogram {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// add 将计数器 f 中标签值为 values 的指标增加 v
+func (m *Metrics) add(f *metricFamily, v float64, values ...string) {
+	m.mu.Lock()
+	f.with(values...).value += v
+	m.mu.Unlock()
+}
+
+// observe 在直方图 f 中记录样本 v
+func (m *Metrics) observe(f *metricFamily, v float64, values ...string) {
+	m.mu.Lock()
+	s := f.with(values...)
+	s.value++
+	s.sum += v
+	if i := sort.SearchFloat64s(f.buckets, v); i < len(f.buckets) {
+		s.counts[i]++
+	}
+	m.mu.Unlock()
+}
+
+// countSegment 记录一个结果为 status 的翻译单元
+func (m *Metrics) countSegment(status string) {
+	if m != nil {
+		m.add(m.segments, 1, status)
+	}
+}
+
+// countRequest 记录一次耗时 d 的翻译服务请求
+func (m *Metrics) countRequest(provider string, d time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.add(m.requests, 1, provider, result)
+	m.observe(m.latency, d.Seconds(), provider)
+}
+
+// countRetry 记录一次重试
+func (m *Metrics) countRetry(provider string) {
+	if m != nil {
+		m.add(m.retries, 1, provider)
+	}
+}
+
+// countTokens 记录一次请求的输入与输出 token 数
+func (m *Metrics) countTokens(provider string, input, output int) {
+	if m == nil {
+		return
+	}
+	m.add(m.tokens, float64(input), provider, "input")
+	m.add(m.tokens, float64(output), provider, "output")
+}
+
+// countCache 记录一次缓存查询
+func (m *Metrics) countCache(hit bool) {
+	if m == nil {
+		return
+	}
+	if hit {
+		m.add(m.cache, 1, "hit")
+	} else {
+		m.add(m.cache, 1, "miss")
+	}
+}
+
+// WriteTo 以 Prometheus 的文本格式 (0.0.4) 写出所有指标
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	m.mu.Lock()
+	for _, f := range m.families {
+		f.write(&sb)
+	}
+	m.mu.Unlock()
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+func (f *metricFamily) write(sb *strings.Builder) {
+	kind := "counter"
+	if f.histogram {
+		kind = "histogram"
+	}
+	fmt.Fprintf(sb, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, kind)
+	keys := make([]string, 0, len(f.series))
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := f.series[key]
+		labels := f.labelPairs(s.values)
+		if !f.histogram {
+			sb.WriteString(f.name + braces(labels) + " " + formatMetricValue(s.value) + "\n")
+			continue
+		}
+		var cumulative uint64
+		for i, le := range f.buckets {
+			cumulative += s.counts[i]
+			sb.WriteString(f.name + "_bucket" + braces(append(labels, `le="`+formatMetricValue(le)+`"`)) + " " + strconv.FormatUint(cumulative, 10) + "\n")
+		}
+		sb.WriteString(f.name + "_bucket" + braces(append(labels, `le="+Inf"`)) + " " + formatMetricValue(s.value) + "\n")
+		sb.WriteString(f.name + "_sum" + braces(labels) + " " + formatMetricValue(s.sum) + "\n")
+		sb.WriteString(f.name + "_count" + braces(labels) + " " + formatMetricValue(s.value) + "\n")
+	}
+}
+
+// labelPairs 返回 name="value" 形式的标签, 值按文本格式的要求转义
+func (f *metricFamily) labelPairs(values []string) []string {
+	pairs := make([]string, len(values), len(values)+1)
+	for i, v := range values {
+		pairs[i] = f.labels[i] + `="` + labelEscaper.Replace(v) + `"`
+	}
+	return pairs
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func braces(pairs []string) string {
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatMetricValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// ServeHTTP 实现 http.Handler, 供 Prometheus 抓取, 通常挂载在 /metrics
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// WithMetrics 记录翻译的运行指标到 m, 见 Metrics
+//
+// 指标中 provider 标签的值: Dashscope 为 "dashscope/<模型名>", 实现了 Name() string 的 Provider 为其返回值,
+// ExternalProvider 为 "external", 其他 Provider 为其类型名.
+func (t *Translator) WithMetrics(m *Metrics) *Translator {
+	t.metrics = m
+	return t
+}
+
+// providerName 返回指标中已配置的翻译服务的 provider 标签
+func (t *Translator) providerName() string {
+	switch p := t.provider.(type) {
+	case nil:
+		return "dashscope/" + t.modelName()
+	case interface{ Name() string }:
+		return p.Name()
+	case *ExternalProvider:
+		return "external"
+	default:
+		return strings.TrimPrefix(fmt.Sprintf("%T", p), "*")
+	}
+}
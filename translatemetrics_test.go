@@ -0,0 +1,96 @@
+package docx
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type namedProvider struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (p *namedProvider) Name() string { return "test" }
+
+func (p *namedProvider) Translate(text, targetLanguage string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls[text]++
+	switch {
+	case strings.HasPrefix(text, "FAIL"):
+		return "", errors.New("quota exceeded")
+	case text == "retry" && p.calls[text] == 1:
+		return "BAD", nil
+	}
+	return "[" + text + "]", nil
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	tr := NewTranslator("", "").
+		WithProvider(&namedProvider{calls: map[string]int{}}).
+		WithCache(NewLRUCache(16)).
+		WithSanitizer(func(source, response string) (string, bool) { return response, response != "BAD" }).
+		WithMetrics(m)
+	for i := 0; i < 2; i++ {
+		if _, err := tr.TranslateDocx(newTestDoc("hello", "FAIL here", "retry"), "ja"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	out := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE docx_translate_segments_total counter\n",
+		`docx_translate_segments_total{status="translated"} 4` + "\n",
+		`docx_translate_segments_total{status="failed"} 2` + "\n",
+		// 第一轮: hello 与 retry 各一次, retry 重试一次, FAIL 一次; 第二轮 hello 与 retry 命中缓存, FAIL 再失败一次
+		`docx_translate_provider_requests_total{provider="test",result="success"} 3` + "\n",
+		`docx_translate_provider_requests_total{provider="test",result="error"} 2` + "\n",
+		`docx_translate_provider_retries_total{provider="test"} 1` + "\n",
+		`docx_translate_cache_requests_total{result="hit"} 2` + "\n",
+		`docx_translate_cache_requests_total{result="miss"} 4` + "\n",
+		"# TYPE docx_translate_provider_request_duration_seconds histogram\n",
+		`docx_translate_provider_request_duration_seconds_bucket{provider="test",le="+Inf"} 5` + "\n",
+		`docx_translate_provider_request_duration_seconds_count{provider="test"} 5` + "\n",
+		`docx_translate_tokens_total{provider="test",direction="output"} `,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in\n%s", want, out)
+		}
+	}
+}
+
+func TestMetricsDashscopeUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"content":"こんにちは"}}],"usage":{"prompt_tokens":42,"completion_tokens":7}}`))
+	}))
+	defer srv.Close()
+
+	m := NewMetrics()
+	tr := NewTranslator("key", srv.URL).WithModel("qwen-max").WithMetrics(m)
+	if _, err := tr.TranslateWithDashscope("hello", "Japanese"); err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if _, err := m.WriteTo(&sb); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`docx_translate_provider_requests_total{provider="dashscope/qwen-max",result="success"} 1`,
+		`docx_translate_tokens_total{provider="dashscope/qwen-max",direction="input"} 42`,
+		`docx_translate_tokens_total{provider="dashscope/qwen-max",direction="output"} 7`,
+	} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("missing %q in\n%s", want, sb.String())
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package docx
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// TargetErrors 是 TranslateDocxMulti 中各目标语言的错误, 键为目标语言
+type TargetErrors map[string]error
+
+func (e TargetErrors) Error() string {
+	targets := make([]string, 0, len(e))
+	for target := range e {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	sb := strings.Builder{}
+	for i, target := range targets {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(target)
+		sb.WriteString(": ")
+		sb.WriteString(e[target].Error())
+	}
+	return sb.String()
+}
+
+// TranslateDocxMulti 将同一个文档翻译为多种目标语言, 返回目标语言到译文的映射
+func (t *Translator) TranslateDocxMulti(doc *Docx, targetLanguages []string) (map[string]*Docx, error) {
+	return t.TranslateDocxMultiContext(context.Background(), doc, targetLanguages)
+}
+
+// TranslateDocxMultiContext 同 TranslateDocxMulti, ctx 被取消时立即停止, 返回 nil 与 ctx.Err()
+//
+// 文档只分段一次, 各目标语言复制其中的翻译单元后依次翻译, 共享缓存、翻译记忆等设置, 相同的原文在每种语言中只翻译一次.
+// 某种语言失败 (或在 ErrorPolicyCollect 下有失败的段落) 时继续翻译其余语言,
+// 最后返回已得到的译文与 TargetErrors; 重复的目标语言只翻译一次. 媒体超出 WithLimits 的限制时返回 nil 与 *LimitError.
+func (t *Translator) TranslateDocxMultiContext(ctx context.Context, doc *Docx, targetLanguages []string) (map[string]*Docx, error) {
+	if err := t.checkMediaBytes(doc); err != nil {
+		return nil, err
+	}
+	plan, pv := t.newSegmentPlan(doc), t.newProvenance(doc)
+	docs := make(map[string]*Docx, len(targetLanguages))
+	var failed TargetErrors
+	for _, target := range targetLanguages {
+		if _, ok := docs[target]; ok {
+			continue
+		}
+		if _, ok := failed[target]; ok {
+			continue
+		}
+		newDoc, _, err := t.translatePlan(ctx, plan, pv, target)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if newDoc != nil {
+			docs[target] = newDoc
+		}
+		if err != nil {
+			if failed == nil {
+				failed = make(TargetErrors)
+			}
+			failed[target] = err
+			t.log().Log(LogLevelWarn, "翻译为目标语言时出错", "target", target, "err", err)
+		}
+	}
+	if len(failed) > 0 {
+		return docs, failed
+	}
+	return docs, nil
+}
@@ -0,0 +1,188 @@
+package docx
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// NumberFormat 是一种语言书写数字与日期的习惯, 见 WithNumberFormats
+type NumberFormat struct {
+	Decimal rune // Decimal 是小数点
+	Group   rune // Group 是千位分隔符
+	// Date 是日期的写法, 使用 time.Format 的布局, 只支持 2006、1、01、2、02、January 与 Jan; 为空时不转换日期
+	Date string
+}
+
+// DefaultNumberFormats 是常见语言 (ISO 639-1 代码) 书写数字与日期的习惯
+var DefaultNumberFormats = map[string]NumberFormat{
+	"zh": {Decimal: '.', Group: ',', Date: "2006年1月2日"},
+	"ja": {Decimal: '.', Group: ',', Date: "2006年1月2日"},
+	"ko": {Decimal: '.', Group: ',', Date: "2006년 1월 2일"},
+	"en": {Decimal: '.', Group: ',', Date: "January 2, 2006"},
+	"fr": {Decimal: ',', Group: '\u202f', Date: "02/01/2006"},
+	"de": {Decimal: ',', Group: '.', Date: "2.1.2006"},
+	"es": {Decimal: ',', Group: '.', Date: "2/1/2006"},
+	"it": {Decimal: ',', Group: '.', Date: "2/1/2006"},
+	"ru": {Decimal: ',', Group: '\u00a0', Date: "02.01.2006"},
+}
+
+// WithNumberFormats 在翻译完成后将译文中仍按原文语言书写的数字与日期改为目标语言的写法,
+// 如英文译为德文时 1,234.56 改为 1.234,56, 中文译为英文时 2024年3月5日 改为 March 5, 2024;
+// formats 的键是 ISO 639-1 代码, 可以直接使用 DefaultNumberFormats, 原文语言与目标语言都有格式时才转换; 默认不转换
+//
+// 只转换带千位分隔符或小数点的数字, 不添加原文没有的千位分隔符; 数值 (或日期) 必须在段落的原文中出现过,
+// 且转换后按目标语言的格式解析得到相同的数值, 否则保持原样, 因此译文中已经改写过的数字不会被再次转换.
+// 不翻译列表中的内容保持原样.
+func (t *Translator) WithNumberFormats(formats map[string]NumberFormat) *Translator {
+	t.numberFormats = formats
+	return t
+}
+
+// numberTokenRe 匹配可能是数字的文本, 以及需要跳过的占位符与超链接等的标记
+var numberTokenRe = regexp.MustCompile(`⟦\d+⟧|⟪/?\d+/?⟫|\d[\d.,'\x{a0}\x{202f}]*\d`)
+
+// applyNumberFormat 将译文 translated 中按原文语言书写的数字与日期改为目标语言的写法, source 是段落的原文
+func (t *Translator) applyNumberFormat(source, translated, sourceLanguage, targetLanguage string) string {
+	from, ok := t.numberFormats[LanguageCode(sourceLanguage)]
+	if !ok {
+		return translated
+	}
+	to, ok := t.numberFormats[LanguageCode(targetLanguage)]
+	if !ok {
+		return translated
+	}
+	masked, originals := t.mask(translated)
+	masked = ConvertNumbers(source, masked, from, to)
+	s, err := unmask(masked, originals)
+	if err != nil { // 不会发生: 转换不会改动占位符
+		return translated
+	}
+	return s
+}
+
+// ConvertNumbers 将 text 中按格式 from 书写的数字与日期改为格式 to 的写法,
+// 只转换数值 (或日期) 在 source 中按格式 from 出现过的数字与日期
+func ConvertNumbers(source, text string, from, to NumberFormat) string {
+	if from.Decimal != to.Decimal || from.Group != to.Group {
+		values := make(map[string]bool)
+		for _, tok := range numberTokenRe.FindAllString(source, -1) {
+			if v, ok := parseNumber(tok, from); ok {
+				values[v] = true
+			}
+		}
+		text = numberTokenRe.ReplaceAllStringFunc(text, func(tok string) string {
+			v, ok := parseNumber(tok, from)
+			if !ok || !values[v] {
+				return tok
+			}
+			converted := formatNumber(tok, from, to)
+			if back, ok := parseNumber(converted, to); !ok || back != v {
+				return tok
+			}
+			return converted
+		})
+	}
+	if from.Date == "" || to.Date == "" || from.Date == to.Date {
+		return text
+	}
+	re := layoutRegexp(from.Date)
+	dates := make(map[string]bool)
+	for _, m := range re.FindAllString(source, -1) {
+		if d, err := time.Parse(from.Date, m); err == nil {
+			dates[d.Format("2006-01-02")] = true
+		}
+	}
+	return re.ReplaceAllStringFunc(text, func(m string) string {
+		d, err := time.Parse(from.Date, m)
+		if err != nil || !dates[d.Format("2006-01-02")] {
+			return m
+		}
+		converted := d.Format(to.Date)
+		if back, err := time.Parse(to.Date, converted); err != nil || !back.Equal(d) {
+			return m
+		}
+		return converted
+	})
+}
+
+// parseNumber 按格式 f 解析带千位分隔符或小数点的数字 s, 返回以 "." 为小数点、不带千位分隔符的数值;
+// s 不是这样的数字 (如分组不是三位, 或没有分隔符) 时返回 false
+func parseNumber(s string, f NumberFormat) (string, bool) {
+	whole, frac, decimal := strings.Cut(s, string(f.Decimal))
+	if decimal && !isDigits(frac) {
+		return "", false
+	}
+	groups := strings.Split(whole, string(f.Group))
+	if len(groups) == 1 && !decimal {
+		return "", false
+	}
+	for i, g := range groups {
+		if !isDigits(g) || (i == 0 && len(groups) > 1 && len(g) > 3) || (i > 0 && len(g) != 3) {
+			return "", false
+		}
+	}
+	v := strings.Join(groups, "")
+	if decimal {
+		v += "." + frac
+	}
+	return v, true
+}
+
+// formatNumber 将按格式 from 书写的数字 s 中的千位分隔符与小数点换为格式 to 的写法
+func formatNumber(s string, from, to NumberFormat) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case from.Decimal:
+			return to.Decimal
+		case from.Group:
+			return to.Group
+		}
+		return r
+	}, s)
+}
+
+// isDigits 判断 s 是否为非空的十进制数字串
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// layoutRegexp 返回匹配按布局 layout 书写的日期的正则表达式
+func layoutRegexp(layout string) *regexp.Regexp {
+	const months = `January|February|March|April|May|June|July|August|September|October|November|December`
+	var sb strings.Builder
+	if r, _ := utf8.DecodeRuneInString(layout); r < utf8.RuneSelf && isWordByte(byte(r)) {
+		sb.WriteString(`\b`)
+	}
+	for rest := layout; rest != ""; {
+		var token, pattern string
+		for _, tp := range [...][2]string{
+			{"2006", `\d{4}`}, {"January", "(?:" + months + ")"}, {"Jan", `(?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec)`},
+			{"01", `\d{2}`}, {"02", `\d{2}`}, {"1", `\d{1,2}`}, {"2", `\d{1,2}`},
+		} {
+			if strings.HasPrefix(rest, tp[0]) {
+				token, pattern = tp[0], tp[1]
+				break
+			}
+		}
+		if token == "" {
+			_, size := utf8.DecodeRuneInString(rest)
+			token, pattern = rest[:size], regexp.QuoteMeta(rest[:size])
+		}
+		sb.WriteString(pattern)
+		rest = rest[len(token):]
+	}
+	if r, _ := utf8.DecodeLastRuneInString(layout); r < utf8.RuneSelf && isWordByte(byte(r)) {
+		sb.WriteString(`\b`)
+	}
+	return regexp.MustCompile(sb.String())
+}
@@ -0,0 +1,49 @@
+package docx
+
+import "testing"
+
+func TestConvertNumbers(t *testing.T) {
+	en, de, zh := DefaultNumberFormats["en"], DefaultNumberFormats["de"], DefaultNumberFormats["zh"]
+	for _, c := range []struct {
+		source, text string
+		from, to     NumberFormat
+		want         string
+	}{
+		{"Total 1,234.56 and 0.5 in 2024", "Summe 1,234.56 und 0.5 im 2024", en, de, "Summe 1.234,56 und 0,5 im 2024"},
+		{"Total 1,234.56", "Summe 1.234,56", en, de, "Summe 1.234,56"},
+		{"Total 1,234", "Summe 12,345 und 1,2345", en, de, "Summe 12,345 und 1,2345"},
+		{"Summe 1.234,56", "Total 1.234,56", de, en, "Total 1,234.56"},
+		{"⟪1⟫1,000⟪/1⟫ 元", "⟪1⟫1,000⟪/1⟫ yuan", zh, de, "⟪1⟫1.000⟪/1⟫ yuan"},
+		{"于2024年3月5日签订", "Signed on 2024年3月5日", zh, en, "Signed on March 5, 2024"},
+		{"于2024年3月5日签订", "Signed on 2024年3月6日", zh, en, "Signed on 2024年3月6日"},
+		{"Signed on March 5, 2024", "签订于 March 5, 2024", en, zh, "签订于 2024年3月5日"},
+		{"am 15.3.2024", "on 15.3.2024 and 5.3.2024", de, en, "on March 15, 2024 and 5.3.2024"},
+	} {
+		if got := ConvertNumbers(c.source, c.text, c.from, c.to); got != c.want {
+			t.Errorf("ConvertNumbers(%q, %q) = %q, want %q", c.source, c.text, got, c.want)
+		}
+	}
+}
+
+func TestTranslateDocxNumberFormats(t *testing.T) {
+	doc := New().WithDefaultTheme()
+	doc.AddParagraph().AddText("合计 1,234.5 元, 版本 2.5")
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		return "Summe 1,234.5 Yuan, Version 2.5", nil
+	})
+	tr := NewTranslator("", "").WithProvider(p).WithDNT(NewDNTList("Version 2.5")).WithNumberFormats(DefaultNumberFormats)
+	newDoc, err := tr.TranslateDocx(doc, "German")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "Summe 1.234,5 Yuan, Version 2.5" {
+		t.Fatal("unexpected numbers:", s)
+	}
+	newDoc, err = tr.TranslateDocx(doc, "Korean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "Summe 1,234.5 Yuan, Version 2.5" {
+		t.Fatal("formats with the same separators must be kept:", s)
+	}
+}
@@ -0,0 +1,469 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// XMLNS_ODF_TEXT 是 OpenDocument 文字内容的命名空间
+const XMLNS_ODF_TEXT = `urn:oasis:names:tc:opendocument:xmlns:text:1.0`
+
+// odtMetaRe 匹配 meta.xml 中翻译的文档属性
+var odtMetaRe = regexp.MustCompile(`(?s)<(dc:title|dc:subject|dc:description|meta:keyword)>([^<]*)</`)
+
+// odtWhitespaceRe 匹配文字中连续的空白, 按 OpenDocument 的规则视为一个空格
+var odtWhitespaceRe = regexp.MustCompile(`[ \t\r\n]+`)
+
+// TranslateOdt 将 OpenDocument 文本 (.odt) data 翻译为 targetLanguage 并写入 w, ctx 被取消时返回 ctx.Err()
+//
+// 翻译正文 (content.xml) 与页眉页脚 (styles.xml) 中的段落与标题, 以及文档的标题、主题、描述与关键词 (meta.xml).
+// 段落中带格式的文字 (text:span) 与超链接 (text:a) 用标记表示, 译文中按标记重建, 见 inlineHint;
+// 脚注、文本框、书签与域等原样保留, 其中的段落单独翻译. 其他文件原样复制, mimetype 保持不压缩.
+// 与 TranslateDocx 一样使用缓存、术语表与 WithErrorPolicy 等设置, ErrorPolicyCollect 下失败的段落保留原文并返回 SegmentErrors.
+func (t *Translator) TranslateOdt(ctx context.Context, data []byte, w io.Writer, targetLanguage string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	var (
+		segs    []*segment
+		renders = make(map[string]func() []byte)
+		parts   = make(map[string][]byte)
+	)
+	for _, f := range zr.File {
+		switch f.Name {
+		case "content.xml", "styles.xml", "meta.xml":
+		default:
+			continue
+		}
+		content, err := readZipFile(f)
+		if err != nil {
+			return err
+		}
+		if f.Name == "meta.xml" {
+			segs = append(segs, xmlTextSegments(odtMetaRe, content, parts, f.Name, propHint)...)
+			continue
+		}
+		s := &odtScan{d: xml.NewDecoder(bytes.NewReader(content)), data: content}
+		top, err := s.children()
+		if err != nil {
+			return err
+		}
+		for _, p := range s.paras {
+			if sg := t.odtSegment(p); sg != nil {
+				segs = append(segs, sg)
+			}
+		}
+		renders[f.Name] = func() []byte { return renderODT(content, 0, len(content), top) }
+	}
+
+	report, err := t.translateSegments(ctx, segs, targetLanguage)
+	if report == nil {
+		return err
+	}
+	for name, render := range renders {
+		parts[name] = render()
+	}
+	if werr := writeZipParts(w, zr, parts); werr != nil {
+		return werr
+	}
+	return err // ErrorPolicyCollect 下的 SegmentErrors
+}
+
+func translateOdtFile(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error {
+	return t.TranslateOdt(ctx, data, w, targetLanguage)
+}
+
+// xmlTextSegments 返回部件 name 的内容 content 中 re 匹配的元素的文字 (re 的第 1 组是元素名, 第 2 组是文字) 的翻译单元,
+// 译文写入 parts[name]; 用于 meta.xml 中的文档属性等只含文字的元素, meta:keyword 的 hint 换为 keywordsHint
+func xmlTextSegments(re *regexp.Regexp, content []byte, parts map[string][]byte, name, hint string) []*segment {
+	var segs []*segment
+	for k, m := range re.FindAllSubmatchIndex(content, -1) {
+		text, ok := unescapeXML(content[m[4]:m[5]])
+		if !ok || strings.TrimSpace(text) == "" {
+			continue
+		}
+		hint := hint
+		if string(content[m[2]:m[3]]) == "meta:keyword" {
+			hint = keywordsHint
+		}
+		k := k
+		segs = append(segs, &segment{text: text, hint: hint, set: func(s string) {
+			// 之前的译文可能已经修改了部件, 但不会增减其中的元素, 按序号重新定位
+			cur, ok := parts[name]
+			if !ok {
+				cur = content
+			}
+			ms := re.FindAllSubmatchIndex(cur, -1)
+			patched := make([]byte, 0, len(cur)+len(s))
+			patched = append(patched, cur[:ms[k][4]]...)
+			patched = append(patched, escapeXMLText(s)...)
+			patched = append(patched, cur[ms[k][5]:]...)
+			parts[name] = patched
+		}})
+	}
+	return segs
+}
+
+// odtPara 是 OpenDocument 中的一个段落或标题 (text:p、text:h)
+type odtPara struct {
+	start, end int          // start 与 end 是段落内容 (开始标签与结束标签之间) 在部件中的位置
+	parts      []odtPart    // parts 是段落的内容
+	nested     []*odtPara   // nested 是原样保留的元素 (如脚注、文本框) 中的段落
+	translated *string      // translated 是段落的译文, 含有 odtPart 的标记; nil 表示保留原文
+	inlines    []*odtInline // inlines 是 translated 中的标记对应的元素, 第 i 个对应标记 ⟪i+1⟫ 或 ⟪i+1/⟫
+	before     []*odtInline // before 与 after 是段落文字之前与之后原样保留的元素, 不用标记表示
+	after      []*odtInline
+}
+
+// odtPart 是段落中的一段文字, 或一个元素
+type odtPart struct {
+	text string
+	in   *odtInline // in 为 nil 时是普通文字
+}
+
+// odtInline 是段落中的元素
+type odtInline struct {
+	start, end  int    // start 与 end 是元素在部件中的位置
+	open, close string // open 与 close 是包住文字的元素 (text:span、text:a) 的开始与结束标签, 为空时元素原样保留
+}
+
+// odtScan 按顺序读取部件中的段落
+type odtScan struct {
+	d     *xml.Decoder
+	data  []byte
+	paras []*odtPara // paras 是读到的所有段落, 包括嵌套在其他段落中的, 按开始的位置排列
+}
+
+// children 读取当前元素的内容直到其结束, 返回其中不在其他段落中的段落
+func (s *odtScan) children() ([]*odtPara, error) {
+	var list []*odtPara
+	for {
+		tok, err := s.d.Token()
+		if err == io.EOF {
+			return list, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tt := tok.(type) {
+		case xml.StartElement:
+			if tt.Name.Space == XMLNS_ODF_TEXT && (tt.Name.Local == "p" || tt.Name.Local == "h") {
+				p, err := s.para()
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, p)
+				continue
+			}
+			sub, err := s.children()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, sub...)
+		case xml.EndElement:
+			return list, nil
+		}
+	}
+}
+
+// para 读取刚刚开始的段落
+func (s *odtScan) para() (*odtPara, error) {
+	p := &odtPara{start: int(s.d.InputOffset())}
+	s.paras = append(s.paras, p)
+	for {
+		off := int(s.d.InputOffset())
+		tok, err := s.d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch tt := tok.(type) {
+		case xml.CharData:
+			p.parts = append(p.parts, odtPart{text: odtWhitespaceRe.ReplaceAllString(string(tt), " ")})
+		case xml.StartElement:
+			if text, ok := odtSpecial(tt); ok {
+				p.parts = append(p.parts, odtPart{text: text})
+				if err = s.d.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			in := &odtInline{start: off}
+			openEnd := int(s.d.InputOffset())
+			wraps := tt.Name.Space == XMLNS_ODF_TEXT && (tt.Name.Local == "span" || tt.Name.Local == "a")
+			text, simple, nested, closeStart, err := s.inline()
+			if err != nil {
+				return nil, err
+			}
+			in.end = int(s.d.InputOffset())
+			p.nested = append(p.nested, nested...)
+			if wraps && simple {
+				in.open, in.close = string(s.data[off:openEnd]), string(s.data[closeStart:in.end])
+				p.parts = append(p.parts, odtPart{text: text, in: in})
+				continue
+			}
+			p.parts = append(p.parts, odtPart{in: in})
+		case xml.EndElement:
+			p.end = off
+			return p, nil
+		}
+	}
+}
+
+// inline 读取段落中刚刚开始的元素直到其结束, 返回其中的文字与段落, 以及结束标签的位置;
+// 元素中只有文字与带格式的文字时 simple 为 true
+func (s *odtScan) inline() (text string, simple bool, nested []*odtPara, closeStart int, err error) {
+	var sb strings.Builder
+	simple = true
+	for {
+		off := int(s.d.InputOffset())
+		tok, err := s.d.Token()
+		if err != nil {
+			return "", false, nil, 0, err
+		}
+		switch tt := tok.(type) {
+		case xml.CharData:
+			sb.WriteString(odtWhitespaceRe.ReplaceAllString(string(tt), " "))
+		case xml.StartElement:
+			if special, ok := odtSpecial(tt); ok {
+				sb.WriteString(special)
+				if err = s.d.Skip(); err != nil {
+					return "", false, nil, 0, err
+				}
+				continue
+			}
+			if tt.Name.Space == XMLNS_ODF_TEXT && (tt.Name.Local == "p" || tt.Name.Local == "h") {
+				p, err := s.para()
+				if err != nil {
+					return "", false, nil, 0, err
+				}
+				simple = false
+				nested = append(nested, p)
+				continue
+			}
+			// 嵌套的带格式的文字只保留文字, 其他元素使整个元素原样保留
+			inner, ok, sub, _, err := s.inline()
+			if err != nil {
+				return "", false, nil, 0, err
+			}
+			nested = append(nested, sub...)
+			simple = simple && ok && tt.Name.Space == XMLNS_ODF_TEXT && tt.Name.Local == "span"
+			sb.WriteString(inner)
+		case xml.EndElement:
+			return sb.String(), simple, nested, off, nil
+		}
+	}
+}
+
+// odtSpecial 返回空格 (text:s)、制表符 (text:tab) 与换行 (text:line-break) 在译文中的表示
+func odtSpecial(se xml.StartElement) (string, bool) {
+	if se.Name.Space != XMLNS_ODF_TEXT {
+		return "", false
+	}
+	switch se.Name.Local {
+	case "s":
+		n, err := strconv.Atoi(getAtt(se.Attr, "c"))
+		if err != nil || n < 1 {
+			n = 1
+		}
+		return strings.Repeat(" ", n), true
+	case "tab":
+		return "\t", true
+	case "line-break":
+		return "\n", true
+	}
+	return "", false
+}
+
+// odtSegment 返回段落 p 的翻译单元, 段落中没有需要翻译的文字时返回 nil;
+// 段落文字之前与之后原样保留的元素 (如书签) 不用标记表示
+func (t *Translator) odtSegment(p *odtPara) *segment {
+	first, last := len(p.parts), -1
+	for i, pt := range p.parts {
+		if (pt.in == nil || pt.in.open != "") && strings.TrimSpace(pt.text) != "" {
+			if i < first {
+				first = i
+			}
+			last = i
+		}
+	}
+	var sb strings.Builder
+	var inlines, before, after []*odtInline
+	for i, pt := range p.parts {
+		switch {
+		case pt.in != nil && pt.in.open == "" && i < first:
+			before = append(before, pt.in)
+		case pt.in != nil && pt.in.open == "" && i > last:
+			after = append(after, pt.in)
+		case pt.in == nil:
+			sb.WriteString(pt.text)
+		case pt.in.open == "":
+			inlines = append(inlines, pt.in)
+			sb.WriteString(inlineTag(len(inlines)))
+		default:
+			inlines = append(inlines, pt.in)
+			open, end := inlineTags(len(inlines))
+			sb.WriteString(open)
+			sb.WriteString(pt.text)
+			sb.WriteString(end)
+		}
+	}
+	text := strings.TrimSpace(sb.String())
+	if !hasLetter(inlineTagRe.ReplaceAllString(text, "")) || t.skipText(text) {
+		return nil
+	}
+	p.inlines, p.before, p.after = inlines, before, after
+	sg := &segment{text: text, hint: breakHintFor(text), set: func(s string) { p.translated = &s }}
+	if len(inlines) > 0 {
+		sg.hint = joinHints(sg.hint, inlineHint)
+	}
+	return sg
+}
+
+// renderODT 返回部件 data 中 [start, end) 的内容, 其中的段落 paras 换用译文
+func renderODT(data []byte, start, end int, paras []*odtPara) []byte {
+	out := make([]byte, 0, end-start)
+	last := start
+	for _, p := range paras {
+		if p.start < start || p.end > end {
+			continue
+		}
+		out = append(out, data[last:p.start]...)
+		out = append(out, p.render(data)...)
+		last = p.end
+	}
+	return append(out, data[last:end]...)
+}
+
+// render 返回段落的新内容; 译文中的标记缺失、重复或嵌套时只保留译文的文字, 原样保留的元素追加在其后
+func (p *odtPara) render(data []byte) []byte {
+	if p.translated == nil {
+		return renderODT(data, p.start, p.end, p.nested)
+	}
+	keep := func(in *odtInline) []byte {
+		return renderODT(data, in.start, in.end, p.nested)
+	}
+	var out []byte
+	for _, in := range p.before {
+		out = append(out, keep(in)...)
+	}
+	body, ok := p.fillInlines(*p.translated, keep)
+	if !ok {
+		body = odtText(inlineTagRe.ReplaceAllString(*p.translated, ""), len(out) == 0)
+		for _, in := range p.inlines {
+			if in.open == "" {
+				body = append(body, keep(in)...)
+			}
+		}
+	}
+	out = append(out, body...)
+	for _, in := range p.after {
+		out = append(out, keep(in)...)
+	}
+	return out
+}
+
+// fillInlines 按译文 translated 中的标记重建段落的内容, 规则同 segment.fillInlines
+func (p *odtPara) fillInlines(translated string, keep func(*odtInline) []byte) ([]byte, bool) {
+	var (
+		out  []byte
+		seen = make([]bool, len(p.inlines))
+		open int
+		last int
+	)
+	for _, m := range inlineTagRe.FindAllStringSubmatchIndex(translated, -1) {
+		n, err := strconv.Atoi(translated[m[4]:m[5]])
+		if err != nil || n < 1 || n > len(p.inlines) {
+			return nil, false
+		}
+		closing, single := m[3] > m[2], m[7] > m[6]
+		in := p.inlines[n-1]
+		atomic := in.open == ""
+		switch {
+		case single && !closing && open == 0 && atomic && !seen[n-1]:
+			out = append(out, odtText(translated[last:m[0]], len(out) == 0 && len(p.before) == 0)...)
+			out = append(out, keep(in)...)
+			seen[n-1] = true
+		case !single && !closing && open == 0 && !atomic && !seen[n-1]:
+			out = append(out, odtText(translated[last:m[0]], len(out) == 0 && len(p.before) == 0)...)
+			open = n
+			seen[n-1] = true
+		case !single && closing && open != 0 && n == open:
+			out = append(out, in.open...)
+			out = append(out, odtText(translated[last:m[0]], false)...)
+			out = append(out, in.close...)
+			open = 0
+		default:
+			return nil, false
+		}
+		last = m[1]
+	}
+	if open != 0 {
+		return nil, false
+	}
+	for _, ok := range seen {
+		if !ok {
+			return nil, false
+		}
+	}
+	return append(out, odtText(translated[last:], len(out) == 0 && len(p.before) == 0)...), true
+}
+
+// odtText 将译文中的文字转为 OpenDocument 的段落内容: 制表符与换行转为 text:tab 与 text:line-break,
+// 连续的空格与段落开头 (atStart) 的空格转为 text:s, 以免被合并或忽略
+func odtText(s string, atStart bool) []byte {
+	var (
+		out    []byte
+		plain  strings.Builder
+		spaces int
+	)
+	flush := func() {
+		if plain.Len() > 0 {
+			out = append(out, escapeXMLText(plain.String())...)
+			plain.Reset()
+		}
+		if spaces == 0 {
+			return
+		}
+		if !atStart {
+			out = append(out, ' ')
+			spaces--
+		}
+		if spaces > 0 {
+			out = append(out, `<text:s text:c="`+strconv.Itoa(spaces)+`"/>`...)
+		}
+		spaces = 0
+	}
+	for _, r := range s {
+		switch r {
+		case ' ':
+			if plain.Len() > 0 {
+				out = append(out, escapeXMLText(plain.String())...)
+				plain.Reset()
+				atStart = false
+			}
+			spaces++
+			continue
+		case '\t':
+			flush()
+			out = append(out, "<text:tab/>"...)
+		case '\n':
+			flush()
+			out = append(out, "<text:line-break/>"...)
+		default:
+			if spaces > 0 {
+				flush()
+			}
+			plain.WriteRune(r)
+		}
+		atStart = false
+	}
+	flush()
+	return out
+}
@@ -0,0 +1,73 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTranslateOdt(t *testing.T) {
+	const (
+		ns      = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="` + XMLNS_ODF_TEXT + `" xmlns:xlink="http://www.w3.org/1999/xlink"`
+		content = `<?xml version="1.0" encoding="UTF-8"?><office:document-content ` + ns + `><office:body><office:text>` +
+			`<text:h text:outline-level="1">Introduction</text:h>` +
+			`<text:p text:style-name="P1"><text:bookmark text:name="start"/>Read <text:span text:style-name="T1">the guide</text:span> at ` +
+			`<text:a xlink:href="https://example.com">our site</text:a>.<text:note text:id="n1"><text:note-citation>1</text:note-citation>` +
+			`<text:note-body><text:p>a note</text:p></text:note-body></text:note></text:p>` +
+			`<text:p>one<text:tab/>two<text:s text:c="2"/>three</text:p>` +
+			`<text:p>2024</text:p><text:p/>` +
+			`</office:text></office:body></office:document-content>`
+		styles = `<office:document-styles ` + ns + `><office:master-styles><style:master-page xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0">` +
+			`<style:footer><text:p>page footer</text:p></style:footer></style:master-page></office:master-styles></office:document-styles>`
+		meta = `<office:document-meta ` + ns + ` xmlns:dc="http://purl.org/dc/elements/1.1/"><office:meta><dc:title>My report</dc:title></office:meta></office:document-meta>`
+	)
+	var in bytes.Buffer
+	zw := zip.NewWriter(&in)
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = mw.Write([]byte("application/vnd.oasis.opendocument.text"))
+	for _, f := range [][2]string{{"content.xml", content}, {"styles.xml", styles}, {"meta.xml", meta}} {
+		w, err := zw.Create(f[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(f[1]))
+	}
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	var out bytes.Buffer
+	if err = tr.TranslateFile(context.Background(), bytes.NewReader(in.Bytes()), &out, TranslateFileOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if SniffFormat(out.Bytes()) != "odt" {
+		t.Fatal("the output is not recognized as odt")
+	}
+	files := readZip(t, out.Bytes())
+	for _, want := range []string{
+		`<text:h text:outline-level="1">INTRODUCTION</text:h>`,
+		`<text:p text:style-name="P1"><text:bookmark text:name="start"/>READ <text:span text:style-name="T1">THE GUIDE</text:span> AT ` +
+			`<text:a xlink:href="https://example.com">OUR SITE</text:a>.<text:note text:id="n1"><text:note-citation>1</text:note-citation>` +
+			`<text:note-body><text:p>A NOTE</text:p></text:note-body></text:note></text:p>`,
+		`<text:p>ONE<text:tab/>TWO <text:s text:c="1"/>THREE</text:p>`,
+		`<text:p>2024</text:p><text:p/>`,
+	} {
+		if !strings.Contains(files["content.xml"], want) {
+			t.Errorf("expected %s in %s", want, files["content.xml"])
+		}
+	}
+	if !strings.Contains(files["styles.xml"], "<text:p>PAGE FOOTER</text:p>") {
+		t.Error("the footer was not translated:", files["styles.xml"])
+	}
+	if !strings.Contains(files["meta.xml"], "<dc:title>MY REPORT</dc:title>") {
+		t.Error("the title was not translated:", files["meta.xml"])
+	}
+}
@@ -0,0 +1,269 @@
+package docx
+
+// OutputMode 决定译文在新文档中的排列方式
+type OutputMode uint8
+
+const (
+	// OutputModeTranslation 只输出译文 (默认)
+	OutputModeTranslation OutputMode = iota
+	// OutputModeBilingualInterleaved 每个译文段落之后 (或之前) 跟随对应的原文段落, 见 WithInterleaveOptions
+	OutputModeBilingualInterleaved
+	// OutputModeBilingualTable 将正文的段落排成左栏原文、右栏译文的双栏表格, 每个段落一行, 便于对照审阅;
+	// 正文中的表格、内容控件与分节处结束当前的双栏表格, 表格与内容控件只输出译文
+	OutputModeBilingualTable
+	// OutputModeSourceComments 只输出译文, 每个译文段落附加一条批注, 内容为段落的原文, 见 WithCommentAuthor;
+	// 目录与按语言标记拆分的段落 (WithRunLanguageRouting) 不附加批注
+	OutputModeSourceComments
+	// OutputModeHiddenSource 在每个译文段落中译文之前保留原文, 原文设为隐藏文字 (w:vanish),
+	// 在 Word 中切换 "显示隐藏文字" 即可对照原文; 目录与按语言标记拆分的段落不保留原文
+	OutputModeHiddenSource
+)
+
+// InterleaveOptions 是 OutputModeBilingualInterleaved 中原文段落的位置与样式
+type InterleaveOptions struct {
+	SourceFirst bool   // SourceFirst 表示原文段落放在译文段落之前, 默认在之后
+	Color       string // Color 是原文文字的颜色 (十六进制 RGB, 如 "808080"), 为空时不改变
+	Italic      bool   // Italic 表示原文文字使用斜体
+	StyleID     string // StyleID 是原文段落的段落样式, 为空时沿用原段落的样式
+}
+
+// DefaultInterleaveOptions 将原文段落放在译文段落之后, 使用灰色斜体
+var DefaultInterleaveOptions = InterleaveOptions{Color: "808080", Italic: true}
+
+// WithOutputMode 设置译文的排列方式, 默认为 OutputModeTranslation; TranslateDocxInPlace 只支持默认方式
+func (t *Translator) WithOutputMode(m OutputMode) *Translator {
+	t.outputMode = m
+	return t
+}
+
+// WithInterleaveOptions 设置 OutputModeBilingualInterleaved 中原文段落的位置与样式, 默认为 DefaultInterleaveOptions
+func (t *Translator) WithInterleaveOptions(o InterleaveOptions) *Translator {
+	t.interleave = &o
+	return t
+}
+
+// interleaveOptions 返回 OutputModeBilingualInterleaved 中原文段落的位置与样式
+func (t *Translator) interleaveOptions() InterleaveOptions {
+	if t.interleave != nil {
+		return *t.interleave
+	}
+	return DefaultInterleaveOptions
+}
+
+// sourceParagraph 返回双语输出中与译文段落并列的原文段落:
+// 原文按 opts 设置样式, 书签、编号、分页符与分栏符不重复, 避免书签重名、列表编号错位与多余的分页
+func sourceParagraph(p *Paragraph, to *Docx, opts InterleaveOptions) *Paragraph {
+	np := p.copymedia(to)
+	if (np.Properties != nil && (np.Properties.NumProperties != nil || np.Properties.PageBreakBefore != nil)) || opts.StyleID != "" {
+		var pp ParagraphProperties
+		if np.Properties != nil {
+			pp = *np.Properties
+		}
+		pp.NumProperties = nil
+		pp.PageBreakBefore = nil
+		if opts.StyleID != "" {
+			pp.Style = &Style{Val: opts.StyleID}
+		}
+		np.Properties = &pp
+	}
+	restyle := func(r *Run) {
+		if opts.Color == "" && !opts.Italic {
+			return
+		}
+		var rp RunProperties
+		if r.RunProperties != nil {
+			rp = *r.RunProperties
+		}
+		if opts.Color != "" {
+			rp.Color = &Color{Val: opts.Color}
+		}
+		if opts.Italic {
+			rp.Italic = &Italic{}
+		}
+		r.RunProperties = &rp
+	}
+	children := np.Children[:0]
+	for _, child := range np.Children {
+		switch o := child.(type) {
+		case *BookmarkStart, *BookmarkEnd, *CommentRangeStart, *CommentRangeEnd:
+			continue
+		case *Run:
+			if o = withoutBreaks(o); o == nil {
+				continue
+			}
+			restyle(o)
+			child = o
+		case *Hyperlink:
+			restyle(&o.Run)
+		case *Ins:
+			for _, c := range o.Children {
+				if r, ok := c.(*Run); ok {
+					restyle(r)
+				}
+			}
+		}
+		children = append(children, child)
+	}
+	np.Children = children
+	return &np
+}
+
+// withoutBreaks 返回去掉分页符、分栏符与批注标记的 Run, 只有这些内容时返回 nil;
+// 批注只锚定在译文段落中, 原文段落再次引用同一批注会使 Word 报告文档损坏
+func withoutBreaks(r *Run) *Run {
+	children := make([]interface{}, 0, len(r.Children))
+	for _, child := range r.Children {
+		if _, ok := child.(*CommentReference); !ok && !isBreak(child) {
+			children = append(children, child)
+		}
+	}
+	switch {
+	case len(children) == len(r.Children):
+		return r
+	case len(children) == 0:
+		return nil
+	}
+	nr := *r
+	nr.Children = children
+	return &nr
+}
+
+// interleaved 按 InterleaveOptions 排列译文段落 np 与原文段落 source;
+// 结束一节的段落属性 (sectPr) 只留在后一个段落中, 段前分页 (pageBreakBefore) 只留在前一个段落中.
+// 段落中的分页符与分栏符只留在译文段落中
+func (t *Translator) interleaved(np, source *Paragraph) []*Paragraph {
+	pair := []*Paragraph{np, source}
+	if t.interleaveOptions().SourceFirst {
+		pair[0], pair[1] = source, np
+		if np.Properties != nil && np.Properties.PageBreakBefore != nil {
+			pp, sp := *np.Properties, ParagraphProperties{}
+			if source.Properties != nil {
+				sp = *source.Properties
+			}
+			pp.PageBreakBefore, sp.PageBreakBefore = nil, &PageBreakBefore{}
+			np.Properties, source.Properties = &pp, &sp
+		}
+	}
+	if first := pair[0]; first.Properties != nil && first.Properties.SectPr != nil {
+		pp := *first.Properties
+		pp.SectPr = nil
+		first.Properties = &pp
+	}
+	return pair
+}
+
+// hideSource 在 dst.Children[at] 处放入隐藏的原文, 之后跟一个隐藏的空格与译文隔开;
+// 原文中的 Run、超链接与插入修订中的文字保留格式, 域、图片与删除的文字不重复
+func (sg *segment) hideSource(at int) {
+	fields := fieldRuns(sg.src)
+	var hidden []interface{}
+	add := func(r *Run) {
+		if fields[r] {
+			return
+		}
+		text := runText(r)
+		if text == "" {
+			return
+		}
+		var rp RunProperties
+		if r.RunProperties != nil {
+			rp = *r.RunProperties
+		}
+		rp.Vanish = &Vanish{}
+		hidden = append(hidden, &Run{RunProperties: &rp, Children: textChildren(text)})
+	}
+	for _, child := range sg.src.Children {
+		switch o := child.(type) {
+		case *Run:
+			add(o)
+		case *Hyperlink:
+			add(&o.Run)
+		case *Ins:
+			for _, c := range o.Children {
+				if r, ok := c.(*Run); ok {
+					add(r)
+				}
+			}
+		}
+	}
+	if len(hidden) == 0 {
+		return
+	}
+	hidden = append(hidden, &Run{RunProperties: &RunProperties{Vanish: &Vanish{}}, Children: textChildren(" ")})
+	children := make([]interface{}, 0, len(sg.dst.Children)+len(hidden))
+	children = append(children, sg.dst.Children[:at]...)
+	children = append(children, hidden...)
+	sg.dst.Children = append(children, sg.dst.Children[at:]...)
+}
+
+// sideBySide 是 OutputModeBilingualTable 中正在填充的双栏表格
+type sideBySide struct {
+	to    *Docx
+	width int64 // width 是表格的宽度
+	table *Table
+}
+
+// textWidth 返回节 sect 中版心的宽度, 没有页面设置时按 A4 纸与 1 英寸页边距计算
+func textWidth(sect *SectPr) int64 {
+	if sect == nil || sect.PgSz == nil || sect.PgMar == nil {
+		return 11906 - 2*1440
+	}
+	return int64(sect.PgSz.W - sect.PgMar.Left - sect.PgMar.Right - sect.PgMar.Gutter)
+}
+
+// add 将原文段落 source 与译文段落 translated 作为一行加入双栏表格, 返回追加了内容的 items;
+// source 为 nil (没有翻译的段落, 如空段落与只有图片的段落) 时左栏为空段落.
+// 段落中的分节 (sectPr) 不能放入表格, 移到表格之后的空段落中, 并结束当前的表格
+func (s *sideBySide) add(items []interface{}, source, translated *Paragraph) []interface{} {
+	if source == nil {
+		source = &Paragraph{file: s.to}
+	}
+	var sect *SectPr
+	for _, p := range []*Paragraph{source, translated} {
+		if p.Properties != nil && p.Properties.SectPr != nil {
+			sect = p.Properties.SectPr
+			pp := *p.Properties
+			pp.SectPr = nil
+			p.Properties = &pp
+		}
+	}
+	if s.table == nil {
+		s.table = s.newTable()
+		items = append(items, s.table)
+	}
+	row := &WTableRow{TableRowProperties: &WTableRowProperties{}, file: s.to}
+	for _, p := range []*Paragraph{source, translated} {
+		row.TableCells = append(row.TableCells, &WTableCell{
+			TableCellProperties: &WTableCellProperties{TableCellWidth: &WTableCellWidth{W: s.width / 2, Type: "dxa"}},
+			Paragraphs:          []*Paragraph{p},
+			file:                s.to,
+		})
+	}
+	s.table.TableRows = append(s.table.TableRows, row)
+	if sect != nil {
+		s.end()
+		items = append(items, &Paragraph{Properties: &ParagraphProperties{SectPr: sect}, file: s.to})
+	}
+	return items
+}
+
+// end 结束当前的双栏表格, 之后的段落放入新的表格
+func (s *sideBySide) end() {
+	s.table = nil
+}
+
+// newTable 返回没有行的双栏表格, 两栏等宽, 带单线边框
+func (s *sideBySide) newTable() *Table {
+	border := func() *WTableBorder { return &WTableBorder{Val: "single", Size: 4, Color: "auto"} }
+	return &Table{
+		TableProperties: &WTableProperties{
+			Width: &WTableWidth{W: s.width, Type: "dxa"},
+			TableBorders: &WTableBorders{
+				Top: border(), Left: border(), Bottom: border(), Right: border(), InsideH: border(), InsideV: border(),
+			},
+			Look: &WTableLook{Val: "0000"},
+		},
+		TableGrid: &WTableGrid{GridCols: []*WGridCol{{W: s.width / 2}, {W: s.width / 2}}},
+		file:      s.to,
+	}
+}
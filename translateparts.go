@@ -0,0 +1,205 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	relStyles    = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles`
+	relNumbering = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering`
+	relTheme     = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/theme`
+	relFontTable = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/fontTable`
+
+	contentTypeNumbering = "application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"
+)
+
+// carriedParts 是从原文档复制到新文档的部件, 键为关系类型, 值为新文档中的文件名
+var carriedParts = [...][2]string{
+	{relStyles, "styles.xml"},
+	{relNumbering, "numbering.xml"},
+	{relTheme, "theme/theme1.xml"},
+	{relFontTable, "fontTable.xml"},
+}
+
+// copyParts 将原文档 src 的样式、编号、主题与字体表复制到新文档 dst,
+// 使段落与 Run 属性中引用的样式 ID、编号 ID 与主题字体在新文档中仍然有效
+//
+// 原文档没有的部件沿用默认模板; 部件自身的关系 (如编号中的图片项目符号、字体表中嵌入的字体) 连同其目标一起复制,
+// 目标无法读取时该部件沿用默认模板.
+func (t *Translator) copyParts(src, dst *Docx) {
+	for _, cp := range carriedParts {
+		typ, target := cp[0], cp[1]
+		name, ok := partName(src, typ)
+		if !ok {
+			continue
+		}
+		data, err := src.readPart(name)
+		if err != nil {
+			t.log().Log(LogLevelWarn, "无法读取原文档的部件, 将使用默认模板", "part", name, "err", err)
+			continue
+		}
+		newName, ok := partName(dst, typ)
+		if !ok {
+			newName = "word/" + target
+		}
+		related, err := partRelations(src, name, newName)
+		if err != nil {
+			t.log().Log(LogLevelWarn, "无法复制部件的关系, 将使用默认模板", "part", name, "err", err)
+			continue
+		}
+		if !ok {
+			if typ != relNumbering || !addContentType(dst, "/word/"+target, contentTypeNumbering) {
+				continue
+			}
+			dst.docRelation.Relationship = append(dst.docRelation.Relationship, Relationship{
+				ID:     "rId" + strconv.Itoa(int(atomic.AddUintptr(&dst.rID, 1))),
+				Type:   typ,
+				Target: target,
+			})
+		}
+		dst.setPart(newName, data)
+		for _, f := range related {
+			if f.contentType != "" {
+				addContentType(dst, "/"+f.name, f.contentType)
+			}
+			dst.setPart(f.name, f.data)
+		}
+	}
+}
+
+// relatedFile 是随部件一起复制的文件
+type relatedFile struct {
+	name        string // name 是新文档中的文件名
+	contentType string // contentType 为空表示不需要登记, 如关系文件
+	data        []byte
+}
+
+// partRelations 读取原文档 src 中部件 name 的关系与其指向的文件, 返回复制到新文档部件 newName 时需要写入的文件;
+// 目标文件加上部件名作为前缀, 以免与新文档中的图片等重名. 部件没有关系时返回 nil
+func partRelations(src *Docx, name, newName string) ([]relatedFile, error) {
+	relsName := func(name string) string {
+		return path.Join(path.Dir(name), "_rels", path.Base(name)+".rels")
+	}
+	data, err := src.readPart(relsName(name))
+	if err != nil {
+		return nil, nil
+	}
+	var rels Relationships
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		return nil, err
+	}
+	prefix := strings.TrimSuffix(path.Base(newName), path.Ext(newName)) + "-"
+	files := make([]relatedFile, 0, len(rels.Relationship)+1)
+	for i, r := range rels.Relationship {
+		if r.TargetMode == REL_TARGETMODE {
+			continue
+		}
+		target := path.Join(path.Dir(name), r.Target)
+		if strings.HasPrefix(r.Target, "/") {
+			target = strings.TrimPrefix(path.Clean(r.Target), "/")
+		}
+		var content []byte
+		if strings.HasPrefix(target, MEDIA_FOLDER) {
+			if m := src.Media(strings.TrimPrefix(target, MEDIA_FOLDER)); m != nil {
+				content = m.Data
+			}
+		}
+		if content == nil {
+			content, err = src.readPart(target)
+			if err != nil {
+				return nil, err
+			}
+		}
+		dir, base := path.Split(r.Target)
+		rels.Relationship[i].Target = dir + prefix + base
+		newTarget := path.Join(path.Dir(newName), rels.Relationship[i].Target)
+		if strings.HasPrefix(r.Target, "/") {
+			newTarget = strings.TrimPrefix(path.Clean(rels.Relationship[i].Target), "/")
+		}
+		files = append(files, relatedFile{name: newTarget, contentType: contentTypeOf(src, "/"+target), data: content})
+	}
+	data, err = xml.Marshal(&rels)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, relatedFile{name: relsName(newName), data: append([]byte(xml.Header), data...)})
+	return files, nil
+}
+
+// contentTypes 是 [Content_Types].xml 的内容
+type contentTypes struct {
+	Defaults []struct {
+		Extension   string `xml:"Extension,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Default"`
+	Overrides []struct {
+		PartName    string `xml:"PartName,attr"`
+		ContentType string `xml:"ContentType,attr"`
+	} `xml:"Override"`
+}
+
+// contentTypeOf 返回文档中部件 partName 的类型, 未登记时返回空字符串
+func contentTypeOf(doc *Docx, partName string) string {
+	data, err := doc.readPart("[Content_Types].xml")
+	if err != nil {
+		return ""
+	}
+	var types contentTypes
+	if xml.Unmarshal(data, &types) != nil {
+		return ""
+	}
+	for _, o := range types.Overrides {
+		if strings.EqualFold(o.PartName, partName) {
+			return o.ContentType
+		}
+	}
+	ext := strings.TrimPrefix(path.Ext(partName), ".")
+	for _, d := range types.Defaults {
+		if strings.EqualFold(d.Extension, ext) {
+			return d.ContentType
+		}
+	}
+	return ""
+}
+
+// partName 返回文档中关系类型为 typ 的部件在包中的文件名
+func partName(doc *Docx, typ string) (string, bool) {
+	for _, r := range doc.docRelation.Relationship {
+		if r.Type != typ || r.TargetMode == REL_TARGETMODE {
+			continue
+		}
+		if strings.HasPrefix(r.Target, "/") {
+			return strings.TrimPrefix(path.Clean(r.Target), "/"), true
+		}
+		return path.Join("word", r.Target), true
+	}
+	return "", false
+}
+
+// addContentType 在 [Content_Types].xml 中登记部件 partName 的类型, 返回是否成功
+func addContentType(doc *Docx, partName, contentType string) bool {
+	const name = "[Content_Types].xml"
+	data, err := doc.readPart(name)
+	if err != nil {
+		return false
+	}
+	if bytes.Contains(data, []byte(`PartName="`+partName+`"`)) {
+		return true
+	}
+	i := bytes.LastIndex(data, []byte("</Types>"))
+	if i < 0 {
+		return false
+	}
+	override := `<Override PartName="` + partName + `" ContentType="` + contentType + `"/>`
+	patched := make([]byte, 0, len(data)+len(override))
+	patched = append(patched, data[:i]...)
+	patched = append(patched, override...)
+	patched = append(patched, data[i:]...)
+	doc.setPart(name, patched)
+	return true
+}
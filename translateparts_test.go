@@ -0,0 +1,517 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// rewriteZip 替换或添加 docx 包中的文件, edit 返回 nil 时保留原内容
+func rewriteZip(t *testing.T, data []byte, edit func(name string, content []byte) []byte, extra map[string]string) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	write := func(name string, content []byte) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if edited := edit(f.Name, content); edited != nil {
+			content = edited
+		}
+		write(f.Name, content)
+	}
+	for name, content := range extra {
+		write(name, []byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// readZip 返回 docx 包中的所有文件
+func readZip(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(content)
+	}
+	return files
+}
+
+func TestTranslateDocxCopiesParts(t *testing.T) {
+	const (
+		styles    = `<?xml version="1.0" encoding="UTF-8"?><w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:style w:type="paragraph" w:styleId="ContractClause"/></w:styles>`
+		numbering = `<?xml version="1.0" encoding="UTF-8"?><w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:num w:numId="7"/></w:numbering>`
+	)
+	doc := newTestDoc("第一条")
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		switch name {
+		case "word/styles.xml":
+			return []byte(styles)
+		case "word/_rels/document.xml.rels":
+			return bytes.Replace(content, []byte("</Relationships>"),
+				[]byte(`<Relationship Id="rId9" Type="`+relNumbering+`" Target="numbering.xml"></Relationship></Relationships>`), 1)
+		}
+		return nil
+	}, map[string]string{"word/numbering.xml": numbering})
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newDoc, err := newTestTranslator(t).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+	if files["word/styles.xml"] != styles {
+		t.Fatal("styles.xml was not copied:", files["word/styles.xml"])
+	}
+	if files["word/numbering.xml"] != numbering {
+		t.Fatal("numbering.xml was not copied:", files["word/numbering.xml"])
+	}
+	if !strings.Contains(files["word/_rels/document.xml.rels"], `Type="`+relNumbering+`" Target="numbering.xml"`) {
+		t.Fatal("missing numbering relationship:", files["word/_rels/document.xml.rels"])
+	}
+	if !strings.Contains(files["[Content_Types].xml"], `PartName="/word/numbering.xml"`) {
+		t.Fatal("missing numbering content type:", files["[Content_Types].xml"])
+	}
+	if _, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有编号的原文档不会得到 numbering.xml
+	newDoc, err = newTestTranslator(t).TranslateDocx(newTestDoc("第一条"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := readZip(t, buf.Bytes())["word/numbering.xml"]; ok {
+		t.Fatal("unexpected numbering.xml")
+	}
+}
+
+func TestTranslateDocxKeepsLists(t *testing.T) {
+	const (
+		numbering = `<?xml version="1.0" encoding="UTF-8"?><w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+			`<w:numPicBullet w:numPicBulletId="0"><w:drawing/></w:numPicBullet><w:num w:numId="3"/></w:numbering>`
+		rels = `<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="` + REL_IMAGE + `" Target="media/image1.png"/></Relationships>`
+		body = `<w:p><w:pPr><w:spacing w:line="240"/><w:numPr><w:ilvl w:val="1"/><w:numId w:val="3"/></w:numPr><w:pStyle w:val="ListParagraph"/></w:pPr>` +
+			`<w:r><w:t>item</w:t></w:r></w:p>`
+	)
+	doc := newTestDoc()
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		switch name {
+		case "word/document.xml":
+			return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+		case "word/_rels/document.xml.rels":
+			return bytes.Replace(content, []byte("</Relationships>"),
+				[]byte(`<Relationship Id="rId9" Type="`+relNumbering+`" Target="numbering.xml"></Relationship></Relationships>`), 1)
+		}
+		return nil
+	}, map[string]string{
+		"word/numbering.xml":            numbering,
+		"word/_rels/numbering.xml.rels": rels,
+		"word/media/image1.png":         "png",
+	})
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newDoc, err := newTestTranslator(t).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+	if files["word/numbering.xml"] != numbering {
+		t.Fatal("numbering.xml was not copied:", files["word/numbering.xml"])
+	}
+	if !strings.Contains(files["word/_rels/numbering.xml.rels"], `Target="media/numbering-image1.png"`) {
+		t.Fatal("numbering relationships were not copied:", files["word/_rels/numbering.xml.rels"])
+	}
+	if files["word/media/numbering-image1.png"] != "png" {
+		t.Fatal("picture bullet was not copied")
+	}
+	want := `<w:pPr><w:pStyle w:val="ListParagraph"></w:pStyle><w:numPr><w:ilvl w:val="1"></w:ilvl><w:numId w:val="3"></w:numId></w:numPr>`
+	if !strings.Contains(files["word/document.xml"], want) {
+		t.Fatalf("missing %s in %s", want, files["word/document.xml"])
+	}
+	if _, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTranslateDocxKeepsMacros(t *testing.T) {
+	const (
+		vba     = "\xd0\xcf\x11\xe0 macro project"
+		vbaData = `<?xml version="1.0" encoding="UTF-8"?><wne:vbaSuppData xmlns:wne="http://schemas.microsoft.com/office/word/2006/wordml"/>`
+	)
+	var buf bytes.Buffer
+	if _, err := newTestDoc("第一条").WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		switch name {
+		case "[Content_Types].xml":
+			content = bytes.Replace(content, []byte("application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"), []byte(contentTypeMacroDocument), 1)
+			return bytes.Replace(content, []byte("</Types>"), []byte(`<Default Extension="bin" ContentType="`+contentTypeVBAProject+`"/>`+
+				`<Override PartName="/word/vbaData.xml" ContentType="application/vnd.ms-word.vbaData+xml"/></Types>`), 1)
+		case "word/_rels/document.xml.rels":
+			return bytes.Replace(content, []byte("</Relationships>"),
+				[]byte(`<Relationship Id="rId9" Type="`+relVBAProject+`" Target="vbaProject.bin"></Relationship></Relationships>`), 1)
+		}
+		return nil
+	}, map[string]string{
+		"word/vbaProject.bin": vba,
+		"word/_rels/vbaProject.bin.rels": `<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.microsoft.com/office/2006/relationships/wordVbaData" Target="vbaData.xml"/></Relationships>`,
+		"word/vbaData.xml": vbaData,
+	})
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsMacroEnabled(doc) {
+		t.Fatal("expected a macro-enabled document")
+	}
+
+	newDoc, err := newTestTranslator(t).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsMacroEnabled(newDoc) {
+		t.Fatal("expected the translation to be macro-enabled")
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+	if files["word/vbaProject.bin"] != vba {
+		t.Fatal("vbaProject.bin was not copied")
+	}
+	if files["word/vbaProject-vbaData.xml"] != vbaData {
+		t.Fatalf("vbaData.xml was not copied: %v", files["word/_rels/vbaProject.bin.rels"])
+	}
+	types := files["[Content_Types].xml"]
+	for _, want := range []string{
+		`<Override PartName="/word/document.xml" ContentType="` + contentTypeMacroDocument + `"/>`,
+		`<Override PartName="/word/vbaProject.bin" ContentType="` + contentTypeVBAProject + `"/>`,
+		`<Override PartName="/word/vbaProject-vbaData.xml" ContentType="application/vnd.ms-word.vbaData+xml"/>`,
+	} {
+		if !strings.Contains(types, want) {
+			t.Fatalf("missing %s in %s", want, types)
+		}
+	}
+	if strings.Contains(types, "application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml") {
+		t.Fatal("the main document is still typed as .docx:", types)
+	}
+
+	// 普通文档的译文不启用宏
+	newDoc, err = newTestTranslator(t).TranslateDocx(newTestDoc("第一条"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsMacroEnabled(newDoc) {
+		t.Fatal("unexpected macro-enabled translation")
+	}
+}
+
+func TestTranslateDocxKeepsCustomXML(t *testing.T) {
+	const (
+		item      = `<?xml version="1.0" encoding="UTF-8"?><root><name>合同</name></root>`
+		itemProps = `<?xml version="1.0" encoding="UTF-8"?><ds:datastoreItem ds:itemID="{1D0A4A8B-0000-4000-8000-000000000001}" xmlns:ds="http://schemas.openxmlformats.org/officeDocument/2006/customXml"/>`
+		sdt       = `<w:sdt><w:sdtPr><w:dataBinding w:xpath="/root/name" w:storeItemID="{1D0A4A8B-0000-4000-8000-000000000001}"/></w:sdtPr>` +
+			`<w:sdtContent><w:p><w:r><w:t>合同</w:t></w:r></w:p></w:sdtContent></w:sdt>`
+	)
+	var buf bytes.Buffer
+	if _, err := newTestDoc("第一条").WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		switch name {
+		case "[Content_Types].xml":
+			return bytes.Replace(content, []byte("</Types>"), []byte(
+				`<Override PartName="/customXml/itemProps1.xml" ContentType="application/vnd.openxmlformats-officedocument.customXmlProperties+xml"/>`+
+					`<Override PartName="/word/settings.xml" ContentType="`+contentTypeSettings+`"/></Types>`), 1)
+		case "word/_rels/document.xml.rels":
+			return bytes.Replace(content, []byte("</Relationships>"), []byte(
+				`<Relationship Id="rId8" Type="`+relCustomXML+`" Target="../customXml/item1.xml"></Relationship>`+
+					`<Relationship Id="rId9" Type="`+relSettings+`" Target="settings.xml"></Relationship></Relationships>`), 1)
+		case "word/document.xml":
+			return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+sdt), 1)
+		}
+		return nil
+	}, map[string]string{
+		"customXml/item1.xml":      item,
+		"customXml/itemProps1.xml": itemProps,
+		"customXml/_rels/item1.xml.rels": `<?xml version="1.0" encoding="UTF-8"?><Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/customXmlProps" Target="itemProps1.xml"/></Relationships>`,
+		"word/settings.xml": `<?xml version="1.0" encoding="UTF-8"?><w:settings xmlns:w="` + XMLNS_W + `"><w:zoom w:percent="100"/>` +
+			`<w:docVars><w:docVar w:name="Client" w:val="甲方 &amp; Co"/></w:docVars></w:settings>`,
+	})
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newDoc, err := newTestTranslator(t).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = newDoc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if vars := DocVariables(newDoc); len(vars) != 1 || vars[0] != (DocVariable{Name: "Client", Value: "甲方 & Co"}) {
+		t.Fatal("unexpected document variables:", vars)
+	}
+	sdtCopy, ok := newDoc.Document.Body.Items[0].(*SDT)
+	if !ok || sdtCopy.Properties == nil || sdtCopy.Properties.DataBinding == nil || sdtCopy.Properties.DataBinding.XPath != "/root/name" {
+		t.Fatal("the data binding was not kept")
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+	if files["customXml/item1.xml"] != item || files["customXml/itemProps1.xml"] != itemProps || files["customXml/_rels/item1.xml.rels"] == "" {
+		t.Fatal("custom XML parts were not copied")
+	}
+	if !strings.Contains(files["word/_rels/document.xml.rels"], `Target="../customXml/item1.xml"`) {
+		t.Fatal("missing custom XML relationship:", files["word/_rels/document.xml.rels"])
+	}
+	if !strings.Contains(files["word/document.xml"], `storeItemID="{1D0A4A8B-0000-4000-8000-000000000001}"`) {
+		t.Fatal("the data binding was not written")
+	}
+}
+
+func TestTranslateDocxKeepsOLEObjects(t *testing.T) {
+	const (
+		sheet  = "PK\x03\x04 embedded workbook"
+		object = `<w:r><w:object w:dxaOrig="1531" w:dyaOrig="811"><v:shape id="_x0000_i1025" type="#_x0000_t75" style="width:76.5pt;height:40.5pt">` +
+			`<v:imagedata r:id="rId20" o:title=""/></v:shape><o:OLEObject Type="Embed" ProgID="Excel.Sheet.12" ShapeID="_x0000_i1025" DrawAspect="Icon" ObjectID="_1700000000" r:id="rId21"/></w:object></w:r>`
+	)
+	var buf bytes.Buffer
+	if _, err := newTestDoc("附件").WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		switch name {
+		case "[Content_Types].xml":
+			return bytes.Replace(content, []byte("</Types>"), []byte(
+				`<Default Extension="xlsx" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"/></Types>`), 1)
+		case "word/_rels/document.xml.rels":
+			return bytes.Replace(content, []byte("</Relationships>"), []byte(
+				`<Relationship Id="rId20" Type="`+REL_IMAGE+`" Target="media/image20.png"></Relationship>`+
+					`<Relationship Id="rId21" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/package" Target="embeddings/Sheet1.xlsx"></Relationship></Relationships>`), 1)
+		case "word/document.xml":
+			content = bytes.Replace(content, []byte("<w:document "), []byte(`<w:document xmlns:v="`+XMLNS_V+`" xmlns:o="`+XMLNS_O+`" `), 1)
+			return bytes.Replace(content, []byte("附件</w:t></w:r>"), []byte("附件</w:t></w:r>"+object), 1)
+		}
+		return nil
+	}, map[string]string{
+		"word/embeddings/Sheet1.xlsx": sheet,
+		"word/media/image20.png":      "\x89PNG preview",
+	})
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newDoc, err := newTestTranslator(t).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = newDoc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+	if files["word/embeddings/Sheet1.xlsx"] != sheet {
+		t.Fatal("the embedded workbook was not copied")
+	}
+	document := files["word/document.xml"]
+	if !strings.Contains(document, `ProgID="Excel.Sheet.12"`) || !strings.Contains(document, `w:dxaOrig="1531"`) {
+		t.Fatal("the object was not kept:", document)
+	}
+	reparsed, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var obj *Object
+	for _, child := range reparsed.Document.Body.Items[0].(*Paragraph).Children {
+		if r, ok := child.(*Run); ok {
+			for _, rc := range r.Children {
+				if o, ok := rc.(*Object); ok {
+					obj = o
+				}
+			}
+		}
+	}
+	if obj == nil {
+		t.Fatal("the object is missing from the translated paragraph")
+	}
+	for _, m := range objectRelRe.FindAllStringSubmatch(obj.RawXML, -1) {
+		target, err := reparsed.ReferTarget(m[2])
+		if err != nil {
+			t.Fatal("dangling relationship", m[2])
+		}
+		if target != "embeddings/Sheet1.xlsx" && !strings.HasPrefix(target, "media/") {
+			t.Fatal("unexpected target", target)
+		}
+	}
+}
+
+func TestTranslateDocxComments(t *testing.T) {
+	const (
+		body = `<w:p><w:commentRangeStart w:id="0"/><w:commentRangeStart w:id="1"/><w:r><w:t>hello</w:t></w:r>` +
+			`<w:commentRangeEnd w:id="0"/><w:r><w:commentReference w:id="0"/></w:r>` +
+			`<w:commentRangeEnd w:id="1"/><w:r><w:commentReference w:id="1"/></w:r></w:p>`
+		comments = `<w:comments xmlns:w="` + XMLNS_W + `" xmlns:r="` + XMLNS_R + `" xmlns:w14="` + XMLNS_W14 + `">` +
+			`<w:comment w:id="0" w:author="Alice" w:date="2024-01-02T03:04:05Z" w:initials="A">` +
+			`<w:p w14:paraId="1A2B3C4D"><w:r><w:annotationRef/></w:r><w:r><w:t xml:space="preserve">please check </w:t></w:r>` +
+			`<w:hyperlink r:id="rId1"><w:r><w:t>the spec</w:t></w:r></w:hyperlink></w:p></w:comment>` +
+			`<w:comment w:id="1" w:author="Bob"><w:p w14:paraId="5E6F7A8B"><w:r><w:annotationRef/></w:r><w:r><w:t>done</w:t></w:r></w:p></w:comment>` +
+			`</w:comments>`
+		extended = `<w15:commentsEx xmlns:w15="http://schemas.microsoft.com/office/word/2012/wordml">` +
+			`<w15:commentEx w15:paraId="1A2B3C4D" w15:done="0"/><w15:commentEx w15:paraId="5E6F7A8B" w15:paraIdParent="1A2B3C4D" w15:done="0"/></w15:commentsEx>`
+	)
+	var buf bytes.Buffer
+	if _, err := newTestDoc().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		switch name {
+		case "[Content_Types].xml":
+			return bytes.Replace(content, []byte("</Types>"), []byte(
+				`<Override PartName="/word/comments.xml" ContentType="`+contentTypeComments+`"/>`+
+					`<Override PartName="/word/commentsExtended.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.commentsExtended+xml"/></Types>`), 1)
+		case "word/_rels/document.xml.rels":
+			return bytes.Replace(content, []byte("</Relationships>"), []byte(
+				`<Relationship Id="rId30" Type="`+relComments+`" Target="comments.xml"></Relationship>`+
+					`<Relationship Id="rId31" Type="`+relCommentsExtended+`" Target="commentsExtended.xml"></Relationship></Relationships>`), 1)
+		case "word/document.xml":
+			return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+		}
+		return nil
+	}, map[string]string{
+		"word/comments.xml":         comments,
+		"word/commentsExtended.xml": extended,
+		"word/_rels/comments.xml.rels": `<Relationships xmlns="` + XMLNS_REL + `">` +
+			`<Relationship Id="rId1" Type="` + REL_HYPERLINK + `" Target="https://example.com/spec" TargetMode="External"/></Relationships>`,
+	})
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newDoc, err := newTestTranslator(t).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = newDoc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+	if files["word/commentsExtended.xml"] != extended {
+		t.Fatal("the reply threading was not copied")
+	}
+	document := files["word/document.xml"]
+	for _, want := range []string{`<w:commentRangeStart w:id="0">`, `<w:commentRangeEnd w:id="1">`, `<w:commentReference w:id="1">`, "HELLO"} {
+		if !strings.Contains(document, want) {
+			t.Fatalf("expected %s in %s", want, document)
+		}
+	}
+	reparsed, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, ok := partName(reparsed, relComments)
+	if !ok {
+		t.Fatal("the comments part is missing")
+	}
+	got := Comments{file: reparsed}
+	if err = xml.Unmarshal([]byte(files[name]), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Comments) != 2 {
+		t.Fatal("expected 2 comments, got", len(got.Comments))
+	}
+	first, reply := got.Comments[0], got.Comments[1]
+	if first.ID != "0" || first.Author != "Alice" || first.Date != "2024-01-02T03:04:05Z" || first.Initials != "A" {
+		t.Fatal("comment attributes were not kept:", first)
+	}
+	if s := first.Paragraphs[0].String(); !strings.Contains(s, "PLEASE CHECK") || !strings.Contains(files[name], "THE SPEC") {
+		t.Fatal("the comment was not translated:", s)
+	}
+	if reply.Paragraphs[0].String() != "DONE" || reply.paraIDs[0] != "5E6F7A8B" || first.paraIDs[0] != "1A2B3C4D" {
+		t.Fatal("the reply was not kept:", reply.Paragraphs[0].String(), reply.paraIDs, first.paraIDs)
+	}
+	if !strings.Contains(files[name], "<w:annotationRef>") {
+		t.Fatal("the annotation mark was not kept:", files[name])
+	}
+	if !strings.Contains(files["word/_rels/comments.xml.rels"], "https://example.com/spec") {
+		t.Fatal("the hyperlink relationship was not copied:", files["word/_rels/comments.xml.rels"])
+	}
+}
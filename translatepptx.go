@@ -0,0 +1,63 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+const (
+	pptxSlideHint = "这段文字是演示文稿幻灯片中的内容: 译文要简洁, 不要添加说明."
+	pptxNotesHint = "这段文字是演示文稿的演讲者备注."
+)
+
+// pptxParaRe 匹配幻灯片与备注页中的段落
+var pptxParaRe = regexp.MustCompile(`(?s)<a:p>(.*?)</a:p>`)
+
+// WithPptxNotesOnly 设置 TranslatePptx 只翻译演讲者备注, 幻灯片中的文字保持原文, 用于只需要本地化备注的演讲者
+func (t *Translator) WithPptxNotesOnly(notesOnly bool) *Translator {
+	t.pptxNotesOnly = notesOnly
+	return t
+}
+
+// TranslatePptx 将演示文稿 (.pptx) data 翻译为 targetLanguage 并写入 w, ctx 被取消时返回 ctx.Err()
+//
+// 翻译幻灯片 (ppt/slides) 中文本框、占位符与表格的段落以及演讲者备注 (ppt/notesSlides), WithPptxNotesOnly 时只翻译备注;
+// 母版、版式、图片与动画等原样复制. 带格式的段落译文使用第一段文字的格式, 同 TranslateXlsx.
+// 与 TranslateDocx 一样使用缓存、术语表与 WithErrorPolicy 等设置, ErrorPolicyCollect 下失败的段落保留原文并返回 SegmentErrors.
+func (t *Translator) TranslatePptx(ctx context.Context, data []byte, w io.Writer, targetLanguage string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	parts := make(map[string][]byte)
+	var texts []*xlsxText
+	for _, f := range zr.File {
+		var hint string
+		switch dir, base := path.Dir(f.Name), path.Base(f.Name); {
+		case dir == "ppt/slides" && strings.HasPrefix(base, "slide") && path.Ext(base) == ".xml" && !t.pptxNotesOnly:
+			hint = pptxSlideHint
+		case dir == "ppt/notesSlides" && strings.HasPrefix(base, "notesSlide") && path.Ext(base) == ".xml":
+			hint = pptxNotesHint
+		default:
+			continue
+		}
+		content, err := readZipFile(f)
+		if err != nil {
+			return err
+		}
+		parts[f.Name] = content
+		for _, m := range pptxParaRe.FindAllSubmatchIndex(content, -1) {
+			texts = t.addXlsxText(texts, f.Name, content, m[2], m[3], "a:", hint)
+		}
+	}
+	return t.writeTranslatedTexts(ctx, zr, parts, texts, w, targetLanguage)
+}
+
+func translatePptxFile(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error {
+	return t.TranslatePptx(ctx, data, w, targetLanguage)
+}
@@ -0,0 +1,57 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTranslatePptx(t *testing.T) {
+	const (
+		slide = `<p:sld><p:cSld><p:spTree><p:sp><p:txBody><a:bodyPr/><a:p><a:r><a:rPr lang="en-US" b="1"/><a:t>Quarterly </a:t></a:r>` +
+			`<a:r><a:t>results</a:t></a:r></a:p><a:p><a:r><a:t>2024</a:t></a:r></a:p></p:txBody></p:sp></p:spTree></p:cSld></p:sld>`
+		notes = `<p:notes><p:cSld><p:spTree><p:sp><p:nvSpPr><p:nvPr><p:ph type="body"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>Mention the growth</a:t></a:r></a:p></p:txBody></p:sp>` +
+			`<p:sp><p:txBody><a:p><a:fld id="{1}" type="slidenum"><a:t>1</a:t></a:fld></a:p></p:txBody></p:sp></p:spTree></p:cSld></p:notes>`
+	)
+	var in bytes.Buffer
+	zw := zip.NewWriter(&in)
+	for _, f := range [][2]string{{"ppt/presentation.xml", `<p:presentation/>`}, {"ppt/slides/slide1.xml", slide}, {"ppt/notesSlides/notesSlide1.xml", notes}} {
+		w, err := zw.Create(f[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(f[1]))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	var out bytes.Buffer
+	if err := tr.TranslateFile(context.Background(), bytes.NewReader(in.Bytes()), &out, TranslateFileOptions{TargetLanguage: "English"}); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, out.Bytes())
+	if want := `<a:p><a:r><a:rPr lang="en-US" b="1"/><a:t>QUARTERLY RESULTS</a:t></a:r></a:p><a:p><a:r><a:t>2024</a:t></a:r></a:p>`; !strings.Contains(files["ppt/slides/slide1.xml"], want) {
+		t.Errorf("slide: missing %q in %q", want, files["ppt/slides/slide1.xml"])
+	}
+	if want := `<a:t>MENTION THE GROWTH</a:t>`; !strings.Contains(files["ppt/notesSlides/notesSlide1.xml"], want) {
+		t.Errorf("notes: missing %q in %q", want, files["ppt/notesSlides/notesSlide1.xml"])
+	}
+
+	out.Reset()
+	if err := tr.WithPptxNotesOnly(true).TranslatePptx(context.Background(), in.Bytes(), &out, "English"); err != nil {
+		t.Fatal(err)
+	}
+	files = readZip(t, out.Bytes())
+	if files["ppt/slides/slide1.xml"] != slide {
+		t.Error("slide changed in notes-only mode:", files["ppt/slides/slide1.xml"])
+	}
+	if !strings.Contains(files["ppt/notesSlides/notesSlide1.xml"], `<a:t>MENTION THE GROWTH</a:t>`) {
+		t.Error("notes not translated:", files["ppt/notesSlides/notesSlide1.xml"])
+	}
+}
@@ -0,0 +1,164 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"regexp"
+	"strings"
+)
+
+const (
+	partCore   = "docProps/core.xml"
+	partApp    = "docProps/app.xml"
+	partCustom = "docProps/custom.xml"
+
+	relCustomProperties = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/custom-properties`
+
+	contentTypeCustomProperties = "application/vnd.openxmlformats-officedocument.custom-properties+xml"
+)
+
+const (
+	// propHint 是文档属性附加的翻译要求
+	propHint = "这段文字是文档的属性 (如标题、主题、摘要), 不是正文: 译文要简洁, 不要添加说明."
+	// keywordsHint 是文档关键词附加的翻译要求
+	keywordsHint = "这段文字是文档的关键词列表: 逐个翻译关键词, 保留原有的分隔符与顺序."
+	// authorHint 是作者、公司等属性附加的翻译要求
+	authorHint = "这段文字是人名或机构名: 按目标语言的习惯译写或音译, 已有通行译名时使用通行译名."
+)
+
+// WithCustomProperties 设置是否翻译文档的自定义属性 (docProps/custom.xml) 中的文本值, 默认不翻译; 属性名不翻译
+func (t *Translator) WithCustomProperties(translate bool) *Translator {
+	t.customProps = translate
+	return t
+}
+
+// WithKeepAuthors 设置是否保留作者、最后修改者、经理与公司属性的原文, 默认与标题等属性一起翻译
+func (t *Translator) WithKeepAuthors(keep bool) *Translator {
+	t.keepAuthors = keep
+	return t
+}
+
+// propRe 匹配属性部件中只有文本的元素, 第 2 组是不带前缀的元素名, 第 3 组是转义后的文本
+var propRe = regexp.MustCompile(`<(\w+:)?(\w+)>([^<]*)</`)
+
+// propHints 是各属性部件中翻译的元素及其翻译要求, authorHints 中的元素由 WithKeepAuthors 控制
+var (
+	propHints = map[string]map[string]string{
+		partCore: {"title": propHint, "subject": propHint, "description": propHint, "category": propHint, "keywords": keywordsHint},
+	}
+	authorHints = map[string]map[string]string{
+		partCore: {"creator": authorHint, "lastModifiedBy": authorHint},
+		partApp:  {"Manager": authorHint, "Company": authorHint},
+	}
+	// customValueHints 是自定义属性中翻译的值类型
+	customValueHints = map[string]string{"lpwstr": propHint, "bstr": propHint}
+)
+
+// copyProps 将原文档 src 的核心属性、扩展属性与自定义属性复制到新文档 dst, 原文档没有的部件沿用默认模板
+func copyProps(src, dst *Docx) {
+	for _, name := range []string{partCore, partApp} {
+		if data, err := src.readPart(name); err == nil {
+			dst.setPart(name, data)
+		}
+	}
+	if data, err := src.readPart(partCustom); err == nil {
+		setCustomProps(dst, data)
+	}
+}
+
+// setCustomProps 将 data 写为文档 doc 的自定义属性部件, 并登记其类型与关系, 返回是否成功
+func setCustomProps(doc *Docx, data []byte) bool {
+	if !addContentType(doc, "/"+partCustom, contentTypeCustomProperties) {
+		return false
+	}
+	rels, err := doc.readPart("_rels/.rels")
+	if err != nil {
+		return false
+	}
+	if !bytes.Contains(rels, []byte(relCustomProperties)) {
+		i := bytes.LastIndex(rels, []byte("</Relationships>"))
+		if i < 0 {
+			return false
+		}
+		rel := `<Relationship Id="rIdCustomProps" Type="` + relCustomProperties + `" Target="` + partCustom + `"/>`
+		patched := make([]byte, 0, len(rels)+len(rel))
+		patched = append(patched, rels[:i]...)
+		patched = append(patched, rel...)
+		patched = append(patched, rels[i:]...)
+		doc.setPart("_rels/.rels", patched)
+	}
+	doc.setPart(partCustom, data)
+	return true
+}
+
+// propSegments 返回文档 doc 的属性中需要翻译的文本对应的翻译单元, 译文写回 doc 中的属性部件,
+// 部件中的其他内容保持不变
+func (t *Translator) propSegments(doc *Docx) []*segment {
+	var segs []*segment
+	add := func(name string, hints func(local string) (string, bool)) {
+		data, err := doc.readPart(name)
+		if err != nil {
+			return
+		}
+		for k, m := range propRe.FindAllSubmatchIndex(data, -1) {
+			hint, ok := hints(string(data[m[4]:m[5]]))
+			if !ok {
+				continue
+			}
+			text, ok := unescapeXML(data[m[6]:m[7]])
+			if !ok || strings.TrimSpace(text) == "" {
+				continue
+			}
+			k := k
+			segs = append(segs, &segment{text: text, hint: hint, set: func(s string) {
+				// 之前的译文可能已经修改了部件, 但不会增减其中的元素, 按序号重新定位
+				cur, err := doc.readPart(name)
+				if err != nil {
+					return
+				}
+				ms := propRe.FindAllSubmatchIndex(cur, -1)
+				if k >= len(ms) {
+					return
+				}
+				var sb strings.Builder
+				_ = xml.EscapeText(&sb, []byte(s))
+				patched := make([]byte, 0, len(cur)+sb.Len())
+				patched = append(patched, cur[:ms[k][6]]...)
+				patched = append(patched, sb.String()...)
+				patched = append(patched, cur[ms[k][7]:]...)
+				doc.setPart(name, patched)
+			}})
+		}
+	}
+	for _, name := range []string{partCore, partApp} {
+		name := name
+		add(name, func(local string) (string, bool) {
+			if hint, ok := propHints[name][local]; ok {
+				return hint, true
+			}
+			if t.keepAuthors {
+				return "", false
+			}
+			hint, ok := authorHints[name][local]
+			return hint, ok
+		})
+	}
+	if t.customProps {
+		add(partCustom, func(local string) (string, bool) {
+			hint, ok := customValueHints[local]
+			return hint, ok
+		})
+	}
+	return segs
+}
+
+// unescapeXML 还原 XML 文本中的转义字符, 文本无效时返回 false
+func unescapeXML(data []byte) (string, bool) {
+	var v struct {
+		Text string `xml:",chardata"`
+	}
+	if err := xml.Unmarshal(append(append([]byte("<v>"), data...), "</v>"...), &v); err != nil {
+		return "", false
+	}
+	return v.Text, true
+}
@@ -0,0 +1,86 @@
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTranslateDocxProperties(t *testing.T) {
+	const (
+		core = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><cp:coreProperties xmlns:cp="http://schemas.openxmlformats.org/package/2006/metadata/core-properties" xmlns:dc="http://purl.org/dc/elements/1.1/">` +
+			`<dc:title>annual report</dc:title><dc:subject>sales &amp; growth</dc:subject><cp:keywords>sales; growth</cp:keywords>` +
+			`<dc:creator>li lei</dc:creator><cp:revision>3</cp:revision></cp:coreProperties>`
+		app = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/extended-properties">` +
+			`<Company>acme</Company><Pages>1</Pages></Properties>`
+		custom = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?><Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes">` +
+			`<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="2" name="client"><vt:lpwstr>big bank</vt:lpwstr></property></Properties>`
+	)
+	var buf bytes.Buffer
+	if _, err := newTestDoc("body").WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		switch name {
+		case partCore:
+			return []byte(core)
+		case partApp:
+			return []byte(app)
+		case "[Content_Types].xml":
+			return bytes.Replace(content, []byte("</Types>"), []byte(`<Override PartName="/docProps/custom.xml" ContentType="`+contentTypeCustomProperties+`"/></Types>`), 1)
+		}
+		return nil
+	}, map[string]string{partCustom: custom})
+	parse := func() *Docx {
+		doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+
+	h := hintRecorder{}
+	newDoc, err := NewTranslator("", "").WithProvider(h).WithCustomProperties(true).TranslateDocx(parse(), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"annual report|" + propHint, "sales & growth|" + propHint, "sales; growth|" + keywordsHint,
+		"li lei|" + authorHint, "acme|" + authorHint, "big bank|" + propHint} {
+		if _, ok := h[want]; !ok {
+			t.Errorf("missing %q in %q", want, h)
+		}
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+	for name, want := range map[string]string{
+		partCore:              `<dc:title>ANNUAL REPORT</dc:title><dc:subject>SALES &amp; GROWTH</dc:subject><cp:keywords>SALES; GROWTH</cp:keywords><dc:creator>LI LEI</dc:creator><cp:revision>3</cp:revision>`,
+		partApp:               `<Company>ACME</Company><Pages>1</Pages>`,
+		partCustom:            `name="client"><vt:lpwstr>BIG BANK</vt:lpwstr>`,
+		"_rels/.rels":         `Type="` + relCustomProperties + `" Target="docProps/custom.xml"`,
+		"[Content_Types].xml": `PartName="/docProps/custom.xml"`,
+	} {
+		if !strings.Contains(files[name], want) {
+			t.Errorf("missing %s in %s: %s", want, name, files[name])
+		}
+	}
+
+	// 保留作者与公司, 不翻译自定义属性; 原地翻译修改原文档的部件
+	doc := parse()
+	if err := NewTranslator("", "").WithProvider(h).WithKeepAuthors(true).TranslateDocxInPlace(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files = readZip(t, buf.Bytes())
+	if !strings.Contains(files[partCore], `<dc:title>ANNUAL REPORT</dc:title>`) || !strings.Contains(files[partCore], `<dc:creator>li lei</dc:creator>`) {
+		t.Error("unexpected core properties:", files[partCore])
+	}
+	if !strings.Contains(files[partApp], `<Company>acme</Company>`) || !strings.Contains(files[partCustom], `big bank`) {
+		t.Error("authors and custom properties must be kept:", files[partApp], files[partCustom])
+	}
+}
@@ -0,0 +1,168 @@
+package docx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	relFooter = `http://schemas.openxmlformats.org/officeDocument/2006/relationships/footer`
+
+	contentTypeFooter = "application/vnd.openxmlformats-officedocument.wordprocessingml.footer+xml"
+
+	// partProvenanceFooter 是来源说明的页脚部件
+	partProvenanceFooter = "word/footerProvenance.xml"
+)
+
+// 来源标记写入的自定义属性
+const (
+	PropMTProvider   = "MTProvider"   // PropMTProvider 是翻译服务的名称
+	PropMTModel      = "MTModel"      // PropMTModel 是翻译使用的模型
+	PropMTDate       = "MTDate"       // PropMTDate 是翻译完成的时间 (UTC, RFC 3339)
+	PropMTSourceHash = "MTSourceHash" // PropMTSourceHash 是原文的 SHA-256 (十六进制)
+)
+
+// ProvenanceOptions 是机器翻译来源标记的设置, 见 WithProvenance
+type ProvenanceOptions struct {
+	Property   bool   // Property 表示将来源写入自定义文档属性 (PropMTProvider 等)
+	Footer     bool   // Footer 表示在每一节的页脚中写入一行来源说明
+	Provider   string // Provider 是翻译服务的名称, 为空时为 "dashscope" 或 WithProvider 设置的 Provider 的类型名
+	SourceHash string // SourceHash 是原文件的 SHA-256 (十六进制), 为空时使用原文档正文的 SHA-256
+}
+
+// WithProvenance 设置在译文中标记机器翻译的来源: 翻译服务、模型、时间与原文的哈希, 默认不标记
+//
+// 自定义属性不随 WithCustomProperties 翻译. 页脚替换新文档中各节的默认页脚 (首页不同的节也替换首页页脚);
+// 原文档的页眉与页脚本来就不会复制到新文档中.
+func (t *Translator) WithProvenance(o ProvenanceOptions) *Translator {
+	t.provenance = &o
+	return t
+}
+
+// provenance 是一次翻译的来源
+type provenance struct {
+	provider, model, date, hash string
+}
+
+// newProvenance 返回翻译原文档 doc 的来源, 未设置 WithProvenance 时返回 nil; 需要在翻译 (可能修改 doc) 之前调用
+func (t *Translator) newProvenance(doc *Docx) *provenance {
+	if t.provenance == nil || (!t.provenance.Property && !t.provenance.Footer) {
+		return nil
+	}
+	pv := &provenance{provider: t.provenance.Provider, model: t.modelName(), hash: t.provenance.SourceHash}
+	if pv.provider == "" {
+		pv.provider = "dashscope"
+		if t.provider != nil {
+			pv.provider = fmt.Sprintf("%T", t.provider)
+		}
+	}
+	if pv.hash == "" {
+		data, _ := xml.Marshal(&doc.Document)
+		sum := sha256.Sum256(data)
+		pv.hash = hex.EncodeToString(sum[:])
+	}
+	return pv
+}
+
+// stamp 在翻译完成后将来源写入文档 doc
+func (t *Translator) stamp(pv *provenance, doc *Docx) {
+	if pv == nil {
+		return
+	}
+	pv.date = time.Now().UTC().Format(time.RFC3339)
+	if t.provenance.Property && !pv.stampProperties(doc) {
+		t.log().Log(LogLevelWarn, "无法写入来源的自定义属性")
+	}
+	if t.provenance.Footer && !pv.stampFooter(doc) {
+		t.log().Log(LogLevelWarn, "无法写入来源的页脚")
+	}
+}
+
+// emptyCustomProps 是没有任何属性的自定义属性部件
+const emptyCustomProps = xml.Header + `<Properties xmlns="http://schemas.openxmlformats.org/officeDocument/2006/custom-properties" ` +
+	`xmlns:vt="http://schemas.openxmlformats.org/officeDocument/2006/docPropsVTypes"></Properties>`
+
+var (
+	// pidRe 匹配自定义属性的编号
+	pidRe = regexp.MustCompile(`\bpid="(\d+)"`)
+	// mtPropRe 匹配之前写入的来源属性
+	mtPropRe = regexp.MustCompile(`<property [^>]*name="(?:` + PropMTProvider + `|` + PropMTModel + `|` + PropMTDate + `|` + PropMTSourceHash + `)"[^>]*>.*?</property>`)
+)
+
+// stampProperties 将来源写入文档的自定义属性, 替换之前写入的来源属性, 其他属性保持不变
+func (pv *provenance) stampProperties(doc *Docx) bool {
+	data, err := doc.readPart(partCustom)
+	if err != nil {
+		data = []byte(emptyCustomProps)
+	}
+	data = mtPropRe.ReplaceAll(data, nil)
+	i := bytes.LastIndex(data, []byte("</Properties>"))
+	if i < 0 {
+		return false
+	}
+	// 自定义属性的编号从 2 开始且不能重复
+	pid := 1
+	for _, m := range pidRe.FindAllSubmatch(data, -1) {
+		if n, err := strconv.Atoi(string(m[1])); err == nil && n > pid {
+			pid = n
+		}
+	}
+	var sb strings.Builder
+	for _, prop := range [...][2]string{
+		{PropMTProvider, pv.provider}, {PropMTModel, pv.model}, {PropMTDate, pv.date}, {PropMTSourceHash, pv.hash},
+	} {
+		pid++
+		sb.WriteString(`<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="` + strconv.Itoa(pid) + `" name="` + prop[0] + `"><vt:lpwstr>`)
+		_ = xml.EscapeText(&sb, []byte(prop[1]))
+		sb.WriteString(`</vt:lpwstr></property>`)
+	}
+	patched := make([]byte, 0, len(data)+sb.Len())
+	patched = append(patched, data[:i]...)
+	patched = append(patched, sb.String()...)
+	patched = append(patched, data[i:]...)
+	return setCustomProps(doc, patched)
+}
+
+// footerText 返回页脚中的来源说明
+func (pv *provenance) footerText() string {
+	return fmt.Sprintf("Machine translated by %s (%s) on %s. Source SHA-256: %s", pv.provider, pv.model, pv.date[:len("2006-01-02")], pv.hash)
+}
+
+// stampFooter 写入来源说明的页脚部件, 并设为文档中各节的页脚
+func (pv *provenance) stampFooter(doc *Docx) bool {
+	data, err := xml.Marshal(&Footer{XMLW: XMLNS_W, Paragraphs: []*Paragraph{{
+		Children: []interface{}{&Run{
+			RunProperties: &RunProperties{Color: &Color{Val: "808080"}, Size: &Size{Val: "16"}},
+			Children:      textChildren(pv.footerText()),
+		}},
+	}}})
+	if err != nil || !addContentType(doc, "/"+partProvenanceFooter, contentTypeFooter) {
+		return false
+	}
+	id, err := doc.ReferID(strings.TrimPrefix(partProvenanceFooter, "word/"))
+	if err != nil {
+		id = "rId" + strconv.Itoa(int(atomic.AddUintptr(&doc.rID, 1)))
+		doc.docRelation.Relationship = append(doc.docRelation.Relationship, Relationship{
+			ID:     id,
+			Type:   relFooter,
+			Target: strings.TrimPrefix(partProvenanceFooter, "word/"),
+		})
+	}
+	doc.setPart(partProvenanceFooter, append([]byte(xml.Header), data...))
+
+	editSections(doc, func(sect *SectPr) {
+		sect.Footers = []*FooterReference{{Type: "default", ID: id}}
+		if sect.TitlePg != nil {
+			sect.Footers = append(sect.Footers, &FooterReference{Type: "first", ID: id})
+		}
+	})
+	return true
+}
@@ -0,0 +1,55 @@
+package docx
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestTranslateDocxProvenance(t *testing.T) {
+	opts := ProvenanceOptions{Property: true, Footer: true, Provider: "acme", SourceHash: "abc"}
+	newDoc, err := newTestTranslator(t).WithModel("m1").WithProvenance(opts).TranslateDocx(newTestDoc("body"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dateRe := regexp.MustCompile(`\d{4}-\d\d-\d\d(T[\d:]+Z)?`)
+	files := readZip(t, buf.Bytes())
+	for name, want := range map[string]string{
+		partCustom: `<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="2" name="MTProvider"><vt:lpwstr>acme</vt:lpwstr></property>` +
+			`<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="3" name="MTModel"><vt:lpwstr>m1</vt:lpwstr></property>` +
+			`<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="4" name="MTDate"><vt:lpwstr>DATE</vt:lpwstr></property>` +
+			`<property fmtid="{D5CDD505-2E9C-101B-9397-08002B2CF9AE}" pid="5" name="MTSourceHash"><vt:lpwstr>abc</vt:lpwstr></property></Properties>`,
+		"_rels/.rels":                  `Type="` + relCustomProperties + `" Target="docProps/custom.xml"`,
+		partProvenanceFooter:           `<w:t>Machine translated by acme (m1) on DATE. Source SHA-256: abc</w:t>`,
+		"word/_rels/document.xml.rels": `Type="` + relFooter + `" Target="footerProvenance.xml"`,
+		"[Content_Types].xml":          `<Override PartName="/word/footerProvenance.xml" ContentType="` + contentTypeFooter + `"/>`,
+		"word/document.xml":            `<w:sectPr><w:footerReference w:type="default" r:id="`,
+	} {
+		if got := dateRe.ReplaceAllString(files[name], "DATE"); !strings.Contains(got, want) {
+			t.Errorf("%s: missing %s in %s", name, want, got)
+		}
+	}
+
+	// 再次标记时替换之前的来源属性
+	data := buf.Bytes()
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Footer = false
+	if err := newTestTranslator(t).WithProvenance(opts).TranslateDocxInPlace(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	custom, err := doc.readPart(partCustom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(custom), `name="MTProvider"`); n != 1 || !strings.Contains(string(custom), `pid="5" name="MTSourceHash"`) {
+		t.Errorf("stale provenance in %s", custom)
+	}
+}
@@ -0,0 +1,251 @@
+package docx
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Provider 是一个翻译服务
+type Provider interface {
+	// Translate 将 text 翻译为 targetLanguage
+	Translate(text, targetLanguage string) (string, error)
+}
+
+// ProviderFunc 将普通函数适配为 Provider
+type ProviderFunc func(text, targetLanguage string) (string, error)
+
+// Translate 实现 Provider
+func (f ProviderFunc) Translate(text, targetLanguage string) (string, error) {
+	return f(text, targetLanguage)
+}
+
+// HintedProvider 是可以接受额外翻译要求的 Provider, 如段落样式对应的语气提示 (见 WithStyleHints);
+// 未实现此接口的 Provider 会忽略这些要求
+type HintedProvider interface {
+	Provider
+	// TranslateWithHint 将 text 翻译为 targetLanguage, hint 为空时等同于 Translate
+	TranslateWithHint(text, targetLanguage, hint string) (string, error)
+}
+
+// WithProvider 设置 TranslateDocx 使用的翻译服务, 默认为 TranslateWithDashscope
+func (t *Translator) WithProvider(p Provider) *Translator {
+	t.provider = p
+	return t
+}
+
+// translateText 使用已配置的缓存与翻译服务翻译一段文本, hint 是附加的翻译要求
+//
+// 不翻译的内容先被替换为占位符, 缓存中保存的是含占位符的译文.
+func (t *Translator) translateText(text, hint, targetLanguage string) (string, error) {
+	hint = joinHints(t.tone.hint(), t.glossary.hint(text), hint)
+	masked, originals := t.mask(text)
+	if len(originals) > 0 && onlyPlaceholders(masked, len(originals)) {
+		return text, nil // 全部是不翻译的内容
+	}
+	var key CacheKey
+	if t.cache != nil {
+		key = t.cacheKey(masked, hint, targetLanguage)
+		if translated, ok := t.cache.Get(key); ok {
+			t.log().Log(LogLevelDebug, "命中缓存", "source", text)
+			t.metrics.countCache(true)
+			return unmask(translated, originals)
+		}
+		t.metrics.countCache(false)
+	}
+	translated, err := t.callProvider(masked, hint, targetLanguage)
+	if err != nil {
+		return "", err
+	}
+	translated, ok := t.sanitize(masked, translated)
+	if !ok {
+		// 回复明显异常时以更严格的提示词重试一次
+		t.log().Log(LogLevelWarn, "翻译服务的回复异常, 将重试", "source", text, "response", translated)
+		t.metrics.countRetry(t.providerName())
+		translated, err = t.callProvider(masked, joinHints(hint, strictHint), targetLanguage)
+		if err != nil {
+			return "", err
+		}
+		if translated, ok = t.sanitize(masked, translated); !ok {
+			return "", fmt.Errorf("%w: %q", ErrMalformedResponse, translated)
+		}
+	}
+	unmasked, err := unmask(translated, originals)
+	if err == nil && t.cache != nil {
+		t.cache.Set(key, translated)
+	}
+	return unmasked, err
+}
+
+// callProvider 将 text 交给已配置的翻译服务, 并记录请求的指标 (Dashscope 的请求由 dashscopeChat 记录)
+func (t *Translator) callProvider(text, hint, targetLanguage string) (translated string, err error) {
+	start := time.Now()
+	switch p := t.provider.(type) {
+	case nil:
+		return t.translateWithDashscope(text, targetLanguage, hint)
+	case HintedProvider:
+		translated, err = p.TranslateWithHint(text, targetLanguage, hint)
+	default:
+		translated, err = p.Translate(text, targetLanguage)
+	}
+	if t.metrics != nil {
+		name := t.providerName()
+		t.metrics.countRequest(name, time.Since(start), err)
+		if err == nil {
+			t.metrics.countTokens(name, EstimateTokens(text)+EstimateTokens(hint), EstimateTokens(translated))
+		}
+	}
+	return translated, err
+}
+
+// ErrProviderClosed 外部翻译服务已关闭
+var ErrProviderClosed = errors.New("external provider closed")
+
+// ExternalProvider 通过 JSON 行协议与外部程序通信, 使任意语言编写的翻译服务
+// 无需重新编译即可接入
+//
+// 每个请求与响应各占一行 (以 \n 结尾的 JSON 对象), 按顺序一问一答:
+//
+//	-> {"id":1,"text":"你好","target":"English"}
+//	<- {"id":1,"text":"Hello"}
+//	-> {"id":2,"text":"...","target":"English"}
+//	<- {"id":2,"error":"quota exceeded"}
+//	-> {"id":3,"text":"第一章","target":"English","hint":"..."}
+//	<- {"id":3,"text":"Chapter 1"}
+//
+// 响应中 error 非空表示该段翻译失败; 请求中的 hint 是可选的附加翻译要求, 见 HintedProvider.
+type ExternalProvider struct {
+	mu     sync.Mutex // mu 使请求按顺序一问一答, Close 不需要 mu, 因此可以打断正在等待的响应
+	nextID uint64
+	w      io.Writer
+	r      *bufio.Reader
+
+	closer    func() error
+	closed    atomic.Bool
+	closeOnce sync.Once
+	closeErr  error
+}
+
+type externalRequest struct {
+	ID     uint64 `json:"id"`
+	Text   string `json:"text"`
+	Target string `json:"target"`
+	Hint   string `json:"hint,omitempty"`
+}
+
+type externalResponse struct {
+	ID    uint64 `json:"id"`
+	Text  string `json:"text"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewExternalProvider 在已建立的双向连接上使用 JSON 行协议, closer 可为 nil
+func NewExternalProvider(r io.Reader, w io.Writer, closer func() error) *ExternalProvider {
+	return &ExternalProvider{w: w, r: bufio.NewReader(r), closer: closer}
+}
+
+// processExitTimeout 是 Close 关闭外部程序的 stdin 之后等待其退出的时间, 超时后结束外部程序
+var processExitTimeout = 5 * time.Second
+
+// StartProcessProvider 启动外部程序, 通过其 stdin/stdout 通信, stderr 保持继承;
+// Close 关闭外部程序的 stdin, 外部程序在 processExitTimeout 内没有退出时被结束
+func StartProcessProvider(name string, args ...string) (*ExternalProvider, error) {
+	cmd := exec.Command(name, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+	return NewExternalProvider(stdout, stdin, func() error {
+		_ = stdin.Close() // 外部程序读到 EOF 后应自行退出
+		done := make(chan error, 1)
+		go func() { done <- cmd.Wait() }()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(processExitTimeout):
+			_ = cmd.Process.Kill()
+			return <-done
+		}
+	}), nil
+}
+
+// DialProvider 连接一个监听在本地 socket (如 "unix", "/tmp/p.sock") 上的外部翻译服务
+func DialProvider(network, address string) (*ExternalProvider, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return NewExternalProvider(conn, conn, conn.Close), nil
+}
+
+// Translate 实现 Provider, 并发调用时按顺序排队
+func (p *ExternalProvider) Translate(text, targetLanguage string) (string, error) {
+	return p.TranslateWithHint(text, targetLanguage, "")
+}
+
+// TranslateWithHint 实现 HintedProvider
+func (p *ExternalProvider) TranslateWithHint(text, targetLanguage, hint string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed.Load() {
+		return "", ErrProviderClosed
+	}
+	p.nextID++
+	req := externalRequest{ID: p.nextID, Text: text, Target: targetLanguage, Hint: hint}
+	data, err := json.Marshal(&req)
+	if err != nil {
+		return "", err
+	}
+	_, err = p.w.Write(append(data, '\n'))
+	if err != nil {
+		if p.closed.Load() {
+			return "", ErrProviderClosed
+		}
+		return "", err
+	}
+	line, err := p.r.ReadBytes('\n')
+	if err != nil {
+		if p.closed.Load() {
+			return "", ErrProviderClosed
+		}
+		return "", err
+	}
+	var resp externalResponse
+	err = json.Unmarshal(line, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.ID != req.ID {
+		return "", errors.New("external provider: response id mismatch")
+	}
+	if resp.Error != "" {
+		return "", errors.New(resp.Error)
+	}
+	return resp.Text, nil
+}
+
+// Close 关闭连接或结束外部程序, 正在等待响应的请求返回 ErrProviderClosed; 多次调用返回第一次的结果
+func (p *ExternalProvider) Close() error {
+	p.closeOnce.Do(func() {
+		p.closed.Store(true)
+		if p.closer != nil {
+			p.closeErr = p.closer()
+		}
+	})
+	return p.closeErr
+}
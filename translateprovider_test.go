@@ -0,0 +1,122 @@
+package docx
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExternalProvider(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sc := bufio.NewScanner(conn)
+		enc := json.NewEncoder(conn)
+		for sc.Scan() {
+			var req externalRequest
+			if json.Unmarshal(sc.Bytes(), &req) != nil {
+				return
+			}
+			resp := externalResponse{ID: req.ID, Text: "[" + req.Target + "]" + req.Text}
+			if strings.Contains(req.Text, "FAIL") {
+				resp = externalResponse{ID: req.ID, Error: "mock failure"}
+			}
+			_ = enc.Encode(&resp)
+		}
+	}()
+
+	p, err := DialProvider("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	newDoc, err := NewTranslator("", "").WithProvider(p).WithErrorPolicy(ErrorPolicyCollect).
+		TranslateDocx(newTestDoc("hello", "FAIL"), "ja")
+	if ses, ok := err.(SegmentErrors); !ok || len(ses) != 1 || ses[0].Err.Error() != "mock failure" {
+		t.Fatal("unexpected error:", err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "[ja]hello" {
+		t.Fatal("unexpected translation:", s)
+	}
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = p.Translate("x", "ja"); err != ErrProviderClosed {
+		t.Fatal("expected ErrProviderClosed, got", err)
+	}
+}
+
+func TestExternalProviderCloseInFlight(t *testing.T) {
+	reqR, reqW := io.Pipe()
+	respR, respW := io.Pipe()
+	p := NewExternalProvider(respR, reqW, respW.Close)
+	errc := make(chan error, 1)
+	go func() {
+		_, err := p.Translate("hello", "ja")
+		errc <- err
+	}()
+	// 外部服务收到请求后不再响应
+	if _, err := bufio.NewReader(reqR).ReadBytes('\n'); err != nil {
+		t.Fatal(err)
+	}
+	closed := make(chan error, 1)
+	go func() { closed <- p.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked by the request in flight")
+	}
+	if err := <-errc; !errors.Is(err, ErrProviderClosed) {
+		t.Fatal("expected ErrProviderClosed, got", err)
+	}
+}
+
+func TestProcessProviderCloseHung(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+	defer func(d time.Duration) { processExitTimeout = d }(processExitTimeout)
+	processExitTimeout = 100 * time.Millisecond
+	// 外部程序读取请求后不响应, 也不因 stdin 关闭而退出
+	p, err := StartProcessProvider(sh, "-c", "read line; exec sleep 60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errc := make(chan error, 1)
+	go func() {
+		_, err := p.Translate("hello", "ja")
+		errc <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	closed := make(chan struct{})
+	go func() {
+		_ = p.Close() // 外部程序被结束, Wait 返回 signal: killed
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Close did not stop the hung process")
+	}
+	if err := <-errc; !errors.Is(err, ErrProviderClosed) {
+		t.Fatal("expected ErrProviderClosed, got", err)
+	}
+}
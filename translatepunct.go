@@ -0,0 +1,199 @@
+package docx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PunctuationRule 是目标语言的一条标点规则: 译文中的 From 替换为 To
+type PunctuationRule struct {
+	From, To string
+	// CJK 表示只替换两侧 (忽略空格、超链接等的标记与占位符) 至少一侧是中日韩文字或全角标点的 From,
+	// 并删除其两侧的空格, 如 "1,000" 与英文句子中的逗号保持不变; 否则总是替换.
+	// To 以空格开头或结尾时, 若该侧已经是空格、文本的边界或标点 (如 "。”" 中的 "”", 但左括号与左引号除外), 省略这个空格.
+	CJK bool
+	// Paired 表示 From 成对出现 (如直引号), To 的两个字符依次用作左右标点; 落单的 From 不替换.
+	// 与 CJK 同时使用时, 一对标点的外侧或其中的文字含有中日韩文字即替换.
+	Paired bool
+}
+
+// cjkToHalf 是全角标点转为半角的规则, 用于中日韩以外的目标语言
+var cjkToHalf = []PunctuationRule{
+	{From: "……", To: "..."}, {From: "，", To: ", "}, {From: "、", To: ", "}, {From: "。", To: ". "},
+	{From: "；", To: "; "}, {From: "：", To: ": "}, {From: "？", To: "? "}, {From: "！", To: "! "},
+	{From: "（", To: " ("}, {From: "）", To: ") "}, {From: "“", To: `"`}, {From: "”", To: `"`},
+	{From: "‘", To: "'"}, {From: "’", To: "'"}, {From: "「", To: `"`}, {From: "」", To: `"`},
+}
+
+// DefaultPunctuationRules 是常见目标语言 (ISO 639-1 代码) 的标点规则: 中文与日文将与中日韩文字相邻的半角标点转为全角,
+// 西文将全角标点转为半角. 括号与引号先于省略号与句点转换, 使 "(注)..." 中的省略号能看到相邻的全角括号.
+// 扩展时复制其中的切片再追加, 不要修改这个变量
+var DefaultPunctuationRules = map[string][]PunctuationRule{
+	"zh": {
+		{From: "(", To: "（", CJK: true}, {From: ")", To: "）", CJK: true}, {From: `"`, To: "“”", CJK: true, Paired: true},
+		{From: "...", To: "……", CJK: true}, {From: ",", To: "，", CJK: true}, {From: ".", To: "。", CJK: true},
+		{From: ";", To: "；", CJK: true}, {From: ":", To: "：", CJK: true}, {From: "?", To: "？", CJK: true},
+		{From: "!", To: "！", CJK: true},
+	},
+	"ja": {
+		{From: "(", To: "（", CJK: true}, {From: ")", To: "）", CJK: true}, {From: `"`, To: "「」", CJK: true, Paired: true},
+		{From: "...", To: "……", CJK: true}, {From: ",", To: "、", CJK: true}, {From: ".", To: "。", CJK: true},
+		{From: "?", To: "？", CJK: true}, {From: "!", To: "！", CJK: true},
+	},
+	"en": cjkToHalf, "fr": cjkToHalf, "de": cjkToHalf, "es": cjkToHalf, "it": cjkToHalf,
+	"pt": cjkToHalf, "ru": cjkToHalf, "nl": cjkToHalf,
+}
+
+// WithPunctuation 在翻译完成后按目标语言规范译文的标点, rules 的键是 ISO 639-1 代码,
+// 可以直接使用 DefaultPunctuationRules; 默认不做处理
+//
+// 规则按顺序逐条应用, 不翻译列表中的内容保持原样.
+func (t *Translator) WithPunctuation(rules map[string][]PunctuationRule) *Translator {
+	t.punctuation = rules
+	return t
+}
+
+// applyPunctuation 对译文应用目标语言的标点规则
+func (t *Translator) applyPunctuation(translated, targetLanguage string) string {
+	rules := t.punctuation[LanguageCode(targetLanguage)]
+	if len(rules) == 0 {
+		return translated
+	}
+	masked, originals := t.mask(translated)
+	masked = ApplyPunctuation(masked, rules)
+	s, err := unmask(masked, originals)
+	if err != nil { // 不会发生: 规则不会匹配占位符中的字符
+		return translated
+	}
+	return s
+}
+
+// ApplyPunctuation 对 s 按顺序应用标点规则
+func ApplyPunctuation(s string, rules []PunctuationRule) string {
+	for _, r := range rules {
+		s = applyPunctuationRule(s, r)
+	}
+	return s
+}
+
+// applyPunctuationRule 对 s 应用一条标点规则
+func applyPunctuationRule(s string, r PunctuationRule) string {
+	if r.From == "" || !strings.Contains(s, r.From) {
+		return s
+	}
+	var at []int
+	for i := 0; ; {
+		j := strings.Index(s[i:], r.From)
+		if j < 0 {
+			break
+		}
+		at = append(at, i+j)
+		i += j + len(r.From)
+	}
+	to := func(k int) string { return r.To }
+	replace := make([]bool, len(at))
+	if r.Paired {
+		left, right := r.To, r.To
+		if first, size := utf8.DecodeRuneInString(r.To); size < len(r.To) {
+			left, right = string(first), r.To[size:]
+		}
+		to = func(k int) string {
+			if k%2 == 0 {
+				return left
+			}
+			return right
+		}
+		for k := 0; k+1 < len(at); k += 2 {
+			end := at[k+1] + len(r.From)
+			replace[k] = !r.CJK || isCJKRune(neighbor(s[:at[k]], false)) || isCJKRune(neighbor(s[end:], true)) ||
+				strings.IndexFunc(s[at[k]:end], isCJKRune) >= 0
+			replace[k+1] = replace[k]
+		}
+	} else {
+		for k, i := range at {
+			replace[k] = !r.CJK || isCJKRune(neighbor(s[:i], false)) || isCJKRune(neighbor(s[i+len(r.From):], true))
+		}
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s))
+	last, prev := 0, utf8.RuneError // prev 是已写入的最后一个字符, utf8.RuneError 表示文本的开头
+	for k, i := range at {
+		if !replace[k] {
+			continue
+		}
+		before, next := s[last:i], i+len(r.From)
+		if r.CJK {
+			before = strings.TrimRight(before, " ")
+			next = len(s) - len(strings.TrimLeft(s[next:], " "))
+		}
+		sb.WriteString(before)
+		if before != "" {
+			prev, _ = utf8.DecodeLastRuneInString(before)
+		}
+		repl := to(k)
+		if strings.HasPrefix(repl, " ") && (prev == utf8.RuneError || prev == ' ' || isOpening(prev)) {
+			repl = repl[1:]
+		}
+		if n, _ := utf8.DecodeRuneInString(s[next:]); strings.HasSuffix(repl, " ") && (next == len(s) || n == ' ' || unicode.IsPunct(n) && !isOpening(n)) {
+			repl = repl[:len(repl)-1]
+		}
+		sb.WriteString(repl)
+		if repl != "" {
+			prev, _ = utf8.DecodeLastRuneInString(repl)
+		}
+		last = next
+	}
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+// neighbor 返回 s 末尾 (forward 为 false) 或开头 (forward 为 true) 跳过空格、超链接等的标记与占位符后的第一个字符,
+// 没有时返回 utf8.RuneError
+func neighbor(s string, forward bool) rune {
+	for s != "" {
+		if forward {
+			s = strings.TrimLeft(s, " ")
+			if loc := inlineTagRe.FindStringIndex(s); loc != nil && loc[0] == 0 {
+				s = s[loc[1]:]
+				continue
+			}
+			if strings.HasPrefix(s, "⟦") {
+				if j := strings.Index(s, "⟧"); j > 0 {
+					s = s[j+len("⟧"):]
+					continue
+				}
+			}
+			r, _ := utf8.DecodeRuneInString(s)
+			return r
+		}
+		s = strings.TrimRight(s, " ")
+		if strings.HasSuffix(s, "⟫") {
+			if j := strings.LastIndex(s, "⟪"); j >= 0 {
+				s = s[:j]
+				continue
+			}
+		}
+		if strings.HasSuffix(s, "⟧") {
+			if j := strings.LastIndex(s, "⟦"); j >= 0 {
+				s = s[:j]
+				continue
+			}
+		}
+		r, _ := utf8.DecodeLastRuneInString(s)
+		return r
+	}
+	return utf8.RuneError
+}
+
+// isCJKRune 判断字符是否为中日韩文字或全角标点
+func isCJKRune(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) ||
+		r >= 0x3000 && r <= 0x303f || r >= 0xff00 && r <= 0xffef
+}
+
+// isOpening 判断标点是否为左括号或左引号
+func isOpening(r rune) bool {
+	return unicode.In(r, unicode.Ps, unicode.Pi)
+}
@@ -0,0 +1,45 @@
+package docx
+
+import "testing"
+
+func TestApplyPunctuation(t *testing.T) {
+	for _, c := range []struct {
+		lang, in, want string
+	}{
+		{"zh", "你好, 世界.", "你好，世界。"},
+		{"zh", "共 1,000 元, 约 3.5 万", "共 1,000 元，约 3.5 万"},
+		{"zh", "他说 \"你好\" 然后离开 (暂时)...", "他说“你好”然后离开（暂时）……"},
+		{"zh", "他用 Hello, world 打招呼", "他用 Hello, world 打招呼"},
+		{"zh", "见⟪1⟫附件⟪/1⟫: 详情", "见⟪1⟫附件⟪/1⟫：详情"},
+		{"ja", "東京, 大阪. \"京都\"", "東京、大阪。「京都」"},
+		{"en", "Hello，world。“Quote。”（note）", "Hello, world. \"Quote.\" (note)"},
+		{"en", "a、b；c：d？", "a, b; c: d?"},
+	} {
+		if got := ApplyPunctuation(c.in, DefaultPunctuationRules[c.lang]); got != c.want {
+			t.Errorf("%s %q: got %q, want %q", c.lang, c.in, got, c.want)
+		}
+	}
+}
+
+func TestTranslateDocxPunctuation(t *testing.T) {
+	doc := New().WithDefaultTheme()
+	doc.AddParagraph().AddText("hello")
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		return "见 a, b 与 你好, 世界. (注)", nil
+	})
+	tr := NewTranslator("", "").WithProvider(p).WithDNT(NewDNTList("a, b")).WithPunctuation(DefaultPunctuationRules)
+	newDoc, err := tr.TranslateDocx(doc, "Chinese")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "见 a, b 与 你好，世界。（注）" {
+		t.Fatal("unexpected punctuation:", s)
+	}
+	newDoc, err = tr.TranslateDocx(doc, "Korean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "见 a, b 与 你好, 世界. (注)" {
+		t.Fatal("languages without a rule must be kept:", s)
+	}
+}
@@ -0,0 +1,130 @@
+package docx
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// QAKind 是译文自动检查发现的问题种类
+type QAKind uint8
+
+const (
+	// QANumbers 原文中的数字没有出现在译文中
+	QANumbers QAKind = iota
+	// QAPlaceholders 原文中的格式占位符 (如 {0}, %s, {{name}}) 或超链接与域的标记 (如 ⟪1⟫) 没有出现在译文中,
+	// 或译文中残留了 ⟦1⟧ 这样的内部占位符
+	QAPlaceholders
+	// QAUntranslated 译文与原文相同, 或仍含有原文书写系统的文字
+	QAUntranslated
+	// QALengthRatio 译文与原文的长度相差过大, 可能有漏译或多余的内容
+	QALengthRatio
+	// QAPunctuation 原文与译文中只有一方以句末标点结尾
+	QAPunctuation
+)
+
+func (k QAKind) String() string {
+	switch k {
+	case QANumbers:
+		return "numbers"
+	case QAPlaceholders:
+		return "placeholders"
+	case QAUntranslated:
+		return "untranslated"
+	case QALengthRatio:
+		return "length-ratio"
+	case QAPunctuation:
+		return "punctuation"
+	}
+	return "unknown"
+}
+
+// QAWarning 是译文自动检查发现的一个问题
+type QAWarning struct {
+	Kind    QAKind
+	Message string
+}
+
+const (
+	// qaMinTokens 是检查长度比例所需的最少 token 数, 过短的文本比例没有意义
+	qaMinTokens = 8
+	// qaMaxRatio 是译文与原文 token 数之比允许的上限, 下限为其倒数
+	qaMaxRatio = 3.0
+)
+
+var (
+	qaFormatRe      = regexp.MustCompile(`\{\{\s*[\w.]+\s*\}\}|\{\d*\}|\{[A-Za-z_]\w*\}|%(?:\d+\$)?[-+# 0]*\d*(?:\.\d+)?[sdvfxq]`)
+	qaPlaceholderRe = regexp.MustCompile(`⟦\d+⟧`)
+)
+
+// CheckTranslation 检查 source 的译文 target, 返回发现的问题; 语言用于判断是否有未翻译的内容
+func CheckTranslation(source, target, sourceLanguage, targetLanguage string) []QAWarning {
+	var warnings []QAWarning
+	add := func(kind QAKind, msg string) {
+		warnings = append(warnings, QAWarning{Kind: kind, Message: msg})
+	}
+
+	// 超链接与域的标记只检查是否保留, 其余检查不考虑标记
+	src := strings.TrimSpace(inlineTagRe.ReplaceAllString(source, ""))
+	tgt := strings.TrimSpace(inlineTagRe.ReplaceAllString(target, ""))
+
+	// 数字: 忽略千位分隔符与小数点的写法差异, 如 1,234.5 与 1.234,5
+	tnums := make(map[string]int)
+	for _, n := range protectNumberRe.FindAllString(tgt, -1) {
+		tnums[qaDigits(n)]++
+	}
+	for _, n := range protectNumberRe.FindAllString(src, -1) {
+		d := qaDigits(n)
+		if tnums[d] == 0 {
+			add(QANumbers, "数字 "+n+" 没有出现在译文中")
+			continue
+		}
+		tnums[d]--
+	}
+
+	for _, ph := range qaFormatRe.FindAllString(source, -1) {
+		if !strings.Contains(target, ph) {
+			add(QAPlaceholders, "占位符 "+ph+" 没有出现在译文中")
+		}
+	}
+	for _, tag := range inlineTagRe.FindAllString(source, -1) {
+		if !strings.Contains(target, tag) {
+			add(QAPlaceholders, "标记 "+tag+" 没有出现在译文中")
+		}
+	}
+	if ph := qaPlaceholderRe.FindString(target); ph != "" {
+		add(QAPlaceholders, "译文中残留了占位符 "+ph)
+	}
+
+	if LanguageCode(sourceLanguage) != LanguageCode(targetLanguage) {
+		switch {
+		case src == tgt && strings.IndexFunc(src, unicode.IsLetter) >= 0:
+			add(QAUntranslated, "译文与原文相同")
+		case isCJKLanguage(sourceLanguage) && !isCJKLanguage(targetLanguage) && strings.IndexFunc(tgt, isCJK) >= 0:
+			add(QAUntranslated, "译文中仍含有中日韩文字")
+		}
+	}
+
+	st, tt := EstimateTokens(src), EstimateTokens(tgt)
+	if st >= qaMinTokens || tt >= qaMinTokens {
+		ratio := float64(tt) / float64(maxInt(st, 1))
+		if ratio > qaMaxRatio || ratio < 1/qaMaxRatio {
+			add(QALengthRatio, "译文与原文的长度之比异常")
+		}
+	}
+
+	if src != "" && tgt != "" && isSentenceEnd(lastRune(src)) != isSentenceEnd(lastRune(tgt)) {
+		add(QAPunctuation, "原文与译文的句末标点不一致")
+	}
+	return warnings
+}
+
+// qaDigits 返回数字中的各位数字
+func qaDigits(n string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, n)
+}
@@ -0,0 +1,49 @@
+package docx
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckTranslation(t *testing.T) {
+	kinds := func(ws []QAWarning) string {
+		s := ""
+		for _, w := range ws {
+			s += w.Kind.String() + ";"
+		}
+		return s
+	}
+	for _, c := range []struct {
+		source, target, want string
+	}{
+		{"共 1,234.5 元, 见第 3 页。", "Total 1.234,5 yuan, see page 3.", ""},
+		{"共 12 项。", "Twelve items.", "numbers;"},
+		{"你好 {name}, 还有 %d 条", "Hello {name}, ⟦1⟧ left", "placeholders;placeholders;"},
+		{"保持原样", "保持原样", "untranslated;"},
+		{"混合文本", "mixed 文本", "untranslated;"},
+		{"这是一段相当长的原文, 需要完整地翻译出来", "Short", "length-ratio;"},
+		{"句子。", "Sentence", "punctuation;"},
+	} {
+		if got := kinds(CheckTranslation(c.source, c.target, "中文", "English")); got != c.want {
+			t.Errorf("%q -> %q: got %q, want %q", c.source, c.target, got, c.want)
+		}
+	}
+}
+
+func TestTranslateDocxQAReport(t *testing.T) {
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		if text == "第 1 章" {
+			return "Chapter one", nil
+		}
+		return "Hello.", nil
+	})
+	_, report, err := NewTranslator("", "").WithProvider(p).
+		TranslateDocxWithReport(context.Background(), newTestDoc("你好。", "第 1 章"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	warned := report.Warned()
+	if len(warned) != 1 || warned[0].Index != 1 || warned[0].Warnings[0].Kind != QANumbers {
+		t.Fatalf("unexpected warnings: %+v", warned)
+	}
+}
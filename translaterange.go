@@ -0,0 +1,191 @@
+package docx
+
+// rangeKind 是 Range 选择段落的方式
+type rangeKind uint8
+
+const (
+	rangeAll rangeKind = iota
+	rangeParagraphs
+	rangeBookmark
+	rangePages
+)
+
+// Range 是正文中选择翻译的范围, 由 ParagraphRange、BookmarkRange 或 PageRange 创建, 零值表示全文; 见 WithRange
+type Range struct {
+	kind     rangeKind
+	from, to int
+	bookmark string
+}
+
+// ParagraphRange 选择第 from 到第 to-1 个段落 (从 0 开始), to <= 0 时到文档结尾;
+// 段落按阅读顺序计数, 包括表格与内容控件中的段落, 同 Body.RangeParagraphs
+func ParagraphRange(from, to int) Range {
+	return Range{kind: rangeParagraphs, from: from, to: to}
+}
+
+// BookmarkRange 选择书签 name 的开始与结束之间的段落, 包括书签两端所在的段落
+func BookmarkRange(name string) Range {
+	return Range{kind: rangeBookmark, bookmark: name}
+}
+
+// PageRange 选择第 from 到第 to 页 (从 1 开始, 包括第 to 页) 上的段落, to <= 0 时到文档结尾, 跨页的段落属于两页
+//
+// 页码是估算的: 按 Word 上次排版时记录的分页位置 (w:lastRenderedPageBreak) 计算;
+// 文档中没有这些记录时 (如程序生成的文档), 只按分页符、段前分页与另起一页的分节计算.
+func PageRange(from, to int) Range {
+	return Range{kind: rangePages, from: from, to: to}
+}
+
+// WithRange 设置只翻译正文中范围 r 内的段落, 用于抽查或分批交付, 默认翻译全文
+//
+// 范围外的段落与 WithStyleFilter 跳过的段落一样原样保留; 文档属性仍然翻译.
+func (t *Translator) WithRange(r Range) *Translator {
+	t.docRange = r
+	return t
+}
+
+// rangeParagraphs 返回 doc 中 WithRange 选择的段落, 选择全文时返回 nil
+func (t *Translator) rangeParagraphs(doc *Docx) map[*Paragraph]bool {
+	r := t.docRange
+	if r.kind == rangeAll {
+		return nil
+	}
+	selected := make(map[*Paragraph]bool)
+	switch r.kind {
+	case rangeParagraphs:
+		i := 0
+		_ = doc.Document.Body.RangeParagraphs(func(p *Paragraph) error {
+			if i >= r.from && (r.to <= 0 || i < r.to) {
+				selected[p] = true
+			}
+			i++
+			return nil
+		})
+	case rangeBookmark:
+		var (
+			id           string
+			active, done bool
+		)
+		mark := func(child interface{}) {
+			switch o := child.(type) {
+			case *BookmarkStart:
+				if !active && !done && o.Name == r.bookmark {
+					active, id = true, o.ID
+				}
+			case *BookmarkEnd:
+				if active && o.ID == id {
+					active, done = false, true
+				}
+			}
+		}
+		visit := func(p *Paragraph) error {
+			in := active
+			for _, child := range p.Children {
+				mark(child)
+				in = in || active
+			}
+			if in {
+				selected[p] = true
+			}
+			return nil
+		}
+		for _, item := range doc.Document.Body.Items {
+			switch o := item.(type) {
+			case *Paragraph:
+				_ = visit(o)
+			case *Table:
+				_ = o.RangeParagraphs(visit)
+			case *SDT:
+				_ = o.RangeParagraphs(visit)
+			default:
+				mark(item)
+			}
+		}
+	case rangePages:
+		for p, span := range pageSpans(doc) {
+			if span[1] >= r.from && (r.to <= 0 || span[0] <= r.to) {
+				selected[p] = true
+			}
+		}
+	}
+	return selected
+}
+
+// pageSpans 估算 doc 中每个段落的起止页码 (从 1 开始), 见 PageRange
+func pageSpans(doc *Docx) map[*Paragraph][2]int {
+	rendered := false
+	_ = doc.Document.Body.RangeParagraphs(func(p *Paragraph) error {
+		for _, r := range paragraphRuns(p) {
+			if len(r.renderedBreaks) > 0 {
+				rendered = true
+			}
+		}
+		return nil
+	})
+
+	spans := make(map[*Paragraph][2]int)
+	page, fresh := 1, true // fresh 表示当前页还没有内容
+	_ = doc.Document.Body.RangeParagraphs(func(p *Paragraph) error {
+		pp := p.Properties
+		if !rendered && pp != nil && pp.PageBreakBefore != nil && !fresh {
+			page++
+		}
+		start := page
+		content := false // content 表示段落中已经有内容
+		for _, r := range paragraphRuns(p) {
+			if r.InstrText != "" {
+				content, fresh = true, false
+			}
+			if rendered {
+				for _, at := range r.renderedBreaks {
+					page++
+					if at == 0 && !content {
+						start = page
+					}
+				}
+				if len(r.Children) > 0 {
+					content = true
+				}
+				continue
+			}
+			for _, child := range r.Children {
+				if br, ok := child.(*BarterRabbet); ok && br.Type == "page" {
+					page++
+					if !content {
+						start = page
+					}
+					fresh = true
+					continue
+				}
+				content, fresh = true, false
+			}
+		}
+		spans[p] = [2]int{start, page}
+		if !rendered && pp != nil && pp.SectPr != nil && (pp.SectPr.Type == nil || pp.SectPr.Type.Val != "continuous" && pp.SectPr.Type.Val != "nextColumn") {
+			page++
+			fresh = true
+		}
+		return nil
+	})
+	return spans
+}
+
+// paragraphRuns 按顺序返回段落中的 Run, 包括超链接与插入修订中的 Run
+func paragraphRuns(p *Paragraph) []*Run {
+	var runs []*Run
+	for _, child := range p.Children {
+		switch o := child.(type) {
+		case *Run:
+			runs = append(runs, o)
+		case *Hyperlink:
+			runs = append(runs, &o.Run)
+		case *Ins:
+			for _, c := range o.Children {
+				if r, ok := c.(*Run); ok {
+					runs = append(runs, r)
+				}
+			}
+		}
+	}
+	return runs
+}
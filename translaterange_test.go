@@ -0,0 +1,82 @@
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTranslateDocxRange(t *testing.T) {
+	const (
+		explicit = `<w:p><w:r><w:t>one</w:t></w:r></w:p>` +
+			`<w:p><w:bookmarkStart w:id="7" w:name="part"/><w:r><w:t>two</w:t></w:r></w:p>` +
+			`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>three</w:t></w:r></w:p></w:tc></w:tr></w:tbl>` +
+			`<w:p><w:r><w:t>four</w:t></w:r><w:bookmarkEnd w:id="7"/></w:p>` +
+			`<w:p><w:r><w:br w:type="page"/></w:r><w:r><w:t>five</w:t></w:r></w:p>` +
+			`<w:p><w:pPr><w:sectPr/></w:pPr><w:r><w:t>six</w:t></w:r></w:p>` +
+			`<w:p><w:r><w:t>seven</w:t></w:r></w:p>` +
+			`<w:p><w:pPr><w:pageBreakBefore/></w:pPr><w:r><w:t>eight</w:t></w:r></w:p>`
+		rendered = `<w:p><w:r><w:t>a</w:t></w:r></w:p>` +
+			`<w:p><w:r><w:lastRenderedPageBreak/><w:t>b</w:t></w:r></w:p>` +
+			`<w:p><w:r><w:t>c</w:t><w:lastRenderedPageBreak/><w:t>d</w:t></w:r></w:p>` +
+			`<w:p><w:pPr><w:pageBreakBefore/></w:pPr><w:r><w:t>e</w:t></w:r></w:p>`
+	)
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	parse := func(body string) *Docx {
+		data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+			if name != "word/document.xml" {
+				return nil
+			}
+			return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+		}, nil)
+		doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	text := func(doc *Docx) string {
+		var texts []string
+		_ = doc.Document.Body.RangeParagraphs(func(p *Paragraph) error {
+			texts = append(texts, paragraphText(p))
+			return nil
+		})
+		return strings.Join(texts, " ")
+	}
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	for _, c := range []struct {
+		body string
+		r    Range
+		want string
+	}{
+		{explicit, ParagraphRange(1, 3), "one TWO THREE four five six seven eight"},
+		{explicit, ParagraphRange(6, 0), "one two three four five six SEVEN EIGHT"},
+		{explicit, BookmarkRange("part"), "one TWO THREE FOUR five six seven eight"},
+		{explicit, BookmarkRange("missing"), "one two three four five six seven eight"},
+		{explicit, PageRange(2, 3), "one two three four FIVE SIX SEVEN eight"},
+		{explicit, PageRange(4, 0), "one two three four five six seven EIGHT"},
+		{rendered, PageRange(1, 1), "A b cd e"},
+		{rendered, PageRange(3, 3), "a b CD E"},
+		{explicit, Range{}, "ONE TWO THREE FOUR FIVE SIX SEVEN EIGHT"},
+	} {
+		newDoc, err := tr.WithRange(c.r).TranslateDocx(parse(c.body), "English")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := text(newDoc); got != c.want {
+			t.Errorf("%+v: got %s, want %s", c.r, got, c.want)
+		}
+		doc := parse(c.body)
+		if err := tr.TranslateDocxInPlace(doc, "English"); err != nil {
+			t.Fatal(err)
+		}
+		if got := text(doc); got != c.want {
+			t.Errorf("%+v in place: got %s, want %s", c.r, got, c.want)
+		}
+	}
+}
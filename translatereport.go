@@ -0,0 +1,124 @@
+package docx
+
+import (
+	"context"
+	"unicode"
+)
+
+// SegmentReport 是一个翻译单元的质量报告
+type SegmentReport struct {
+	Index  int    // Index 是翻译单元在文档遍历顺序中的序号
+	Source string // Source 是原文
+	Target string // Target 是译文, 翻译失败时为原文
+	Err    error  // Err 是翻译失败的原因, 成功时为 nil
+
+	// BackTranslation 是译文回译为原文语言的结果, 没有开启回译或翻译失败时为空
+	BackTranslation string
+	// Score 是回译与原文的相似度, 取值 0 到 1, 见 BackTranslationScore
+	Score float64
+	// BackErr 是回译失败的原因, 回译失败的翻译单元不会被标记
+	BackErr error
+	// Flagged 表示 Score 低于 WithBackTranslation 设置的阈值, 需要人工检查
+	Flagged bool
+	// Warnings 是译文自动检查发现的问题, 见 CheckTranslation; 翻译失败时为空
+	Warnings []QAWarning
+}
+
+// Report 是 TranslateDocxWithReport 返回的质量报告
+type Report struct {
+	Segments []SegmentReport // Segments 按文档遍历顺序排列
+}
+
+// Flagged 返回所有被标记的翻译单元
+func (r *Report) Flagged() []SegmentReport {
+	var flagged []SegmentReport
+	for _, sr := range r.Segments {
+		if sr.Flagged {
+			flagged = append(flagged, sr)
+		}
+	}
+	return flagged
+}
+
+// Warned 返回所有有自动检查问题的翻译单元
+func (r *Report) Warned() []SegmentReport {
+	var warned []SegmentReport
+	for _, sr := range r.Segments {
+		if len(sr.Warnings) > 0 {
+			warned = append(warned, sr)
+		}
+	}
+	return warned
+}
+
+// TranslateDocxWithReport 同 TranslateDocxContext, 同时返回每个翻译单元的质量报告;
+// 返回的文档为 nil 时报告也为 nil
+func (t *Translator) TranslateDocxWithReport(ctx context.Context, doc *Docx, targetLanguage string) (*Docx, *Report, error) {
+	return t.translateDocx(ctx, doc, targetLanguage)
+}
+
+// DefaultBackTranslationThreshold 是 WithBackTranslation 建议的阈值
+const DefaultBackTranslationThreshold = 0.5
+
+// WithBackTranslation 开启回译检查: 每个翻译成功的翻译单元都被译回原文语言,
+// 与原文的相似度低于 threshold 时在报告中标记; threshold 不大于 0 时关闭 (默认)
+//
+// 回译会使请求翻译服务的次数加倍.
+func (t *Translator) WithBackTranslation(threshold float64) *Translator {
+	t.backThreshold = threshold
+	return t
+}
+
+// backTranslate 将译文 translated 译回 sourceLanguage, 返回回译与其和原文 source 的相似度
+func (t *Translator) backTranslate(source, translated, sourceLanguage, targetLanguage string) (string, float64, error) {
+	back, err := t.withSourceLanguage(targetLanguage).translateChunks(translated, "", sourceLanguage)
+	if err != nil {
+		t.log().Log(LogLevelWarn, "回译失败", "err", err)
+		return "", 0, err
+	}
+	return back, BackTranslationScore(source, back), nil
+}
+
+// BackTranslationScore 返回原文与回译的相似度, 取值 0 到 1
+//
+// 忽略大小写、空白与标点后按词计算编辑距离: 西文以单词为单位, 中日韩文字以字为单位,
+// 因此语序的小幅调整与同义词替换只会适度降低得分.
+func BackTranslationScore(source, back string) float64 {
+	ids := make(map[string]rune)
+	return similarity(scoreTokens(source, ids), scoreTokens(back, ids))
+}
+
+// scoreTokens 将 s 切分为词, 每个不同的词映射为一个 rune, 以便复用按 rune 计算的编辑距离
+func scoreTokens(s string, ids map[string]rune) []rune {
+	var (
+		tokens []rune
+		word   []rune
+	)
+	add := func(w string) {
+		id, ok := ids[w]
+		if !ok {
+			id = rune(len(ids))
+			ids[w] = id
+		}
+		tokens = append(tokens, id)
+	}
+	flush := func() {
+		if len(word) > 0 {
+			add(string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range s {
+		switch {
+		case isCJK(r):
+			flush()
+			add(string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word = append(word, unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
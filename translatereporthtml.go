@@ -0,0 +1,68 @@
+package docx
+
+import (
+	"html/template"
+	"io"
+)
+
+// reportHTMLTemplate 是 WriteReportHTML 的页面, 样式内嵌, 不依赖外部资源
+var reportHTMLTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Translation review</title>
+<style>
+body{font-family:sans-serif;margin:1.5em;color:#222}
+table{border-collapse:collapse;width:100%;table-layout:fixed}
+th,td{border:1px solid #ccc;padding:.4em .6em;vertical-align:top;text-align:left;white-space:pre-wrap;word-wrap:break-word}
+th{background:#f4f4f4}
+col.id{width:4em}col.status{width:7em}
+tr.warning td{background:#fff8e1}
+tr.flagged td{background:#fdecea}
+tr.failed td{background:#f8d7da}
+.notes{margin:.3em 0 0;padding-left:1.2em;color:#8a4b00;font-size:.9em}
+.summary span{margin-right:1.5em}
+</style>
+</head>
+<body>
+<h1>Translation review</h1>
+<p class="summary"><span>Segments: {{.Total}}</span><span>Warnings: {{.Warning}}</span><span>Flagged: {{.Flagged}}</span><span>Failed: {{.Failed}}</span></p>
+<table>
+<colgroup><col class="id"><col><col><col class="status"></colgroup>
+<thead><tr><th>ID</th><th>Source</th><th>Target</th><th>Status</th></tr></thead>
+<tbody>
+{{- range .Rows}}
+<tr id="{{.ID}}" class="{{.Status}}"><td>{{.ID}}</td><td>{{.Source}}</td><td>{{.Target}}{{if .Notes}}<ul class="notes">{{range .Notes}}<li>{{.}}</li>{{end}}</ul>{{end}}</td><td>{{.Status}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+</body>
+</html>
+`))
+
+// WriteReportHTML 将 TranslateDocxWithReport 的报告写为 HTML 页面, 原文与译文逐段并排显示,
+// 有问题的翻译单元按状态 (SegmentStatusWarning 等) 标色并列出失败的原因与自动检查发现的问题, 供没有 Word 的人员审阅
+func WriteReportHTML(w io.Writer, r *Report) error {
+	type row struct {
+		ID, Source, Target, Status string
+		Notes                      []string
+	}
+	data := struct {
+		Total, Warning, Flagged, Failed int
+		Rows                            []row
+	}{Total: len(r.Segments)}
+	for i := range r.Segments {
+		sr := &r.Segments[i]
+		status, notes := segmentStatus(sr)
+		switch status {
+		case SegmentStatusWarning:
+			data.Warning++
+		case SegmentStatusFlagged:
+			data.Flagged++
+		case SegmentStatusFailed:
+			data.Failed++
+		}
+		data.Rows = append(data.Rows, row{ID: segmentID(sr.Index), Source: sr.Source, Target: sr.Target, Status: status, Notes: notes})
+	}
+	return reportHTMLTemplate.Execute(w, data)
+}
@@ -0,0 +1,32 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWriteReportHTML(t *testing.T) {
+	report := &Report{Segments: []SegmentReport{
+		{Index: 0, Source: "a < b", Target: "A < B"},
+		{Index: 1, Source: "Page 3", Target: "Seite", Warnings: []QAWarning{{Kind: QANumbers, Message: "missing number 3"}}},
+		{Index: 2, Source: "oops", Target: "oops", Err: errors.New("quota exceeded")},
+	}}
+	var buf bytes.Buffer
+	if err := WriteReportHTML(&buf, report); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"<td>a &lt; b</td><td>A &lt; B</td>",
+		`<tr id="u2" class="warning">`,
+		"<li>missing number 3</li>",
+		`<tr id="u3" class="failed">`,
+		"<span>Warnings: 1</span><span>Flagged: 0</span><span>Failed: 1</span>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
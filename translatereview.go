@@ -0,0 +1,97 @@
+package docx
+
+import "strings"
+
+// DefaultReviewPrompt 是审校的默认要求
+const DefaultReviewPrompt = "你是一名资深审校。请检查译文是否准确、通顺, 术语是否前后一致, 并直接修改其中的问题; " +
+	"没有问题时原样返回译文。"
+
+// Reviewer 是可以审校译文的翻译服务, WithProvider 设置的 Provider 实现此接口时用于审校
+type Reviewer interface {
+	// Review 按 prompt 的要求审校 source 的译文 translation, 返回修改后的译文
+	Review(source, translation, targetLanguage, prompt string) (string, error)
+}
+
+// WithReviewPass 在翻译之后增加一轮审校: 将原文与译文交给审校提示词修正通顺度与术语,
+// model 为空时使用翻译的模型, prompt 为空时使用 DefaultReviewPrompt
+//
+// 使用默认的 Dashscope 时以 model 调用 ReviewWithDashscope; 自定义的 Provider 需实现 Reviewer,
+// 否则跳过审校. 审校失败, 或审校后丢失了不翻译列表与自动保护的内容时保留第一轮的译文.
+// 来自翻译记忆的译文不再审校.
+func (t *Translator) WithReviewPass(model, prompt string) *Translator {
+	if prompt == "" {
+		prompt = DefaultReviewPrompt
+	}
+	t.review = &reviewPass{model: model, prompt: prompt}
+	return t
+}
+
+// reviewPass 是 WithReviewPass 的设置
+type reviewPass struct {
+	model  string
+	prompt string
+}
+
+// dashscopeReviewPrompt 生成审校的系统提示词
+func dashscopeReviewPrompt(prompt, sourceLang, targetLang string) string {
+	return prompt + "\n用户会给出一条" + sourceLang + "原文及其" + targetLang + "译文。注意 你只需要返回审校后的译文，不要返回任何多余内容"
+}
+
+// ReviewWithDashscope 使用 Dashscope API 按 prompt 的要求审校 text 的译文 translation
+func (t *Translator) ReviewWithDashscope(text, translation, targetLang, prompt string) (string, error) {
+	if translation == "" {
+		return "", nil
+	}
+	reviewed, err := t.dashscopeChat([]map[string]string{
+		{"role": "system", "content": dashscopeReviewPrompt(prompt, t.sourceLanguageName(), targetLang)},
+		{"role": "user", "content": "原文: " + text + "\n译文: " + translation},
+	})
+	if err != nil {
+		return "", err
+	}
+	t.log().Log(LogLevelDebug, "审校完成", "source", translation, "target", reviewed)
+	return reviewed, nil
+}
+
+// reviewTranslation 审校 text 的译文 translated, 无法审校时返回 translated
+func (t *Translator) reviewTranslation(text, translated, targetLanguage string) string {
+	if t.review == nil {
+		return translated
+	}
+	var (
+		reviewed string
+		err      error
+		prompt   = joinHints(t.review.prompt, t.tone.hint())
+	)
+	switch p := t.provider.(type) {
+	case nil:
+		rt := t
+		if t.review.model != "" {
+			c := *t
+			c.model = t.review.model
+			rt = &c
+		}
+		reviewed, err = rt.ReviewWithDashscope(text, translated, targetLanguage, prompt)
+	case Reviewer:
+		reviewed, err = p.Review(text, translated, targetLanguage, prompt)
+	default:
+		return translated
+	}
+	if err != nil {
+		t.log().Log(LogLevelWarn, "审校失败, 将保留第一轮的译文", "source", text, "err", err)
+		return translated
+	}
+	reviewed, ok := t.sanitize(translated, reviewed)
+	if !ok || reviewed == "" {
+		return translated
+	}
+	// 审校不能改动被保护的内容
+	_, originals := t.mask(translated)
+	for _, orig := range originals {
+		if !strings.Contains(reviewed, orig) {
+			t.log().Log(LogLevelWarn, "审校改动了被保护的内容, 将保留第一轮的译文", "source", text, "missing", orig)
+			return translated
+		}
+	}
+	return reviewed
+}
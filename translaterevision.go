@@ -0,0 +1,235 @@
+package docx
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+)
+
+// RevisionPolicy 决定如何处理原文中的修订 (w:ins 与 w:del, 以及移动 w:moveTo 与 w:moveFrom)
+type RevisionPolicy uint8
+
+const (
+	// RevisionPolicyAccept 翻译前接受所有修订: 插入的文字与其他文字一样翻译, 删除的文字丢弃 (默认)
+	RevisionPolicyAccept RevisionPolicy = iota
+	// RevisionPolicyPreserve 保留修订标记: 插入的文字翻译后仍放在原来的修订中, 删除的文字原样保留
+	RevisionPolicyPreserve
+)
+
+// WithRevisionPolicy 设置原文中修订的处理方式
+func (t *Translator) WithRevisionPolicy(p RevisionPolicy) *Translator {
+	t.revisionPolicy = p
+	return t
+}
+
+// hasRevisions 判断段落中是否有修订
+func hasRevisions(p *Paragraph) bool {
+	for _, child := range p.Children {
+		switch child.(type) {
+		case *Ins, *Del:
+			return true
+		}
+	}
+	return false
+}
+
+// acceptedChildren 返回接受所有修订后段落的内容: 插入的内容展开, 删除的内容丢弃
+func acceptedChildren(p *Paragraph) []interface{} {
+	children := make([]interface{}, 0, len(p.Children))
+	for _, child := range p.Children {
+		switch o := child.(type) {
+		case *Ins:
+			children = append(children, o.Children...)
+		case *Del:
+		default:
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// acceptRevisions 返回接受所有修订后的段落, 段落中没有修订时返回 p 本身; p 不会被修改
+func acceptRevisions(p *Paragraph) *Paragraph {
+	if !hasRevisions(p) {
+		return p
+	}
+	np := *p
+	np.Children = acceptedChildren(p)
+	return &np
+}
+
+// textRuns 返回段落中可以放入译文的 Run, 包括插入的 Run 与超链接的 Run, 不包括域与删除的 Run
+func textRuns(p *Paragraph) []*Run {
+	fields := fieldRuns(p)
+	var runs []*Run
+	for _, child := range p.Children {
+		switch o := child.(type) {
+		case *Run:
+			if !fields[o] {
+				runs = append(runs, o)
+			}
+		case *Hyperlink:
+			runs = append(runs, &o.Run)
+		case *Ins:
+			for _, c := range o.Children {
+				if run, ok := c.(*Run); ok {
+					runs = append(runs, run)
+				}
+			}
+		}
+	}
+	return runs
+}
+
+// WithTrackChanges 设置以修订的形式输出译文: 原文标记为删除, 译文标记为插入, 修订者为 author,
+// 审阅者可以在 Word 中逐段接受或拒绝译文; author 为空时关闭 (默认)
+//
+// 修订放在超链接之内: 原文中的超链接保留, 其文字标记为删除; 译文中的超链接的文字标记为插入, 因此接受或拒绝修订后超链接仍然有效.
+// 目录、按语言标记拆分的片段 (WithRunLanguageRouting) 与 TranslateDocxInPlace 不输出修订.
+func (t *Translator) WithTrackChanges(author string) *Translator {
+	t.trackAuthor = author
+	return t
+}
+
+// tracker 为 WithTrackChanges 输出的修订分配标识
+type tracker struct {
+	author, date string
+	next         int // next 是上一个分配的标识
+}
+
+// newTracker 返回 doc 的 tracker, 修订的标识从文档中已有的书签与修订的最大标识之后开始
+func (t *Translator) newTracker(doc *Docx) *tracker {
+	if t.trackAuthor == "" {
+		return nil
+	}
+	tk := &tracker{author: t.trackAuthor, date: time.Now().UTC().Format(time.RFC3339)}
+	see := func(id string) {
+		if n, err := strconv.Atoi(id); err == nil && n > tk.next {
+			tk.next = n
+		}
+	}
+	_ = doc.Document.Body.RangeParagraphs(func(p *Paragraph) error {
+		for _, child := range p.Children {
+			switch o := child.(type) {
+			case *BookmarkStart:
+				see(o.ID)
+			case *Ins:
+				see(o.ID)
+			case *Del:
+				see(o.ID)
+			case *Hyperlink:
+				switch rev := o.Revision.(type) {
+				case *Ins:
+					see(rev.ID)
+				case *Del:
+					see(rev.ID)
+				}
+			}
+		}
+		return nil
+	})
+	for _, item := range doc.Document.Body.Items {
+		if o, ok := item.(*BookmarkStart); ok {
+			see(o.ID)
+		}
+	}
+	return tk
+}
+
+// id 返回下一个修订的标识
+func (tk *tracker) id() string {
+	tk.next++
+	return strconv.Itoa(tk.next)
+}
+
+// ins 返回文档 file 中一个新的插入修订
+func (tk *tracker) ins(file *Docx) *Ins {
+	return &Ins{XMLName: xml.Name{Local: "w:ins"}, ID: tk.id(), Author: tk.author, Date: tk.date, file: file}
+}
+
+// del 返回文档 file 中一个新的删除修订
+func (tk *tracker) del(file *Docx) *Del {
+	return &Del{XMLName: xml.Name{Local: "w:del"}, ID: tk.id(), Author: tk.author, Date: tk.date, file: file}
+}
+
+// markRevisions 将 dst.Children[from:] 中的译文标记为插入, 并在其前面放入标记为删除的原文
+func (sg *segment) markRevisions(from int) {
+	translated := append([]interface{}(nil), sg.dst.Children[from:]...)
+	children := append(sg.dst.Children[:from], sg.deletedSource()...)
+	var ins *Ins
+	for _, child := range translated {
+		switch o := child.(type) {
+		case *Run, *SDT:
+			if ins == nil {
+				ins = sg.track.ins(sg.dst.file)
+				children = append(children, ins)
+			}
+			ins.Children = append(ins.Children, child)
+		case *Hyperlink:
+			// 超链接中的文字标记为插入
+			ins = nil
+			o.Revision = sg.track.ins(sg.dst.file)
+			children = append(children, o)
+		default:
+			ins = nil
+			children = append(children, child)
+		}
+	}
+	sg.dst.Children = children
+}
+
+// deletedSource 返回标记为删除的原文: 原文中的 Run、插入修订中的文字放入删除修订, 超链接保留, 其中的文字标记为删除;
+// 书签、删除修订与公式等随译文重建的内容不重复
+func (sg *segment) deletedSource() []interface{} {
+	copied := sg.src.copymedia(sg.dst.file)
+	var (
+		children []interface{}
+		del      *Del
+	)
+	add := func(r *Run) {
+		if del == nil {
+			del = sg.track.del(sg.dst.file)
+			children = append(children, del)
+		}
+		del.Children = append(del.Children, deletedRun(r))
+	}
+	for _, child := range copied.Children {
+		switch o := child.(type) {
+		case *Run:
+			add(o)
+		case *Hyperlink:
+			del = nil
+			o.Run = *deletedRun(&o.Run)
+			o.Revision = sg.track.del(sg.dst.file)
+			children = append(children, o)
+		case *Ins:
+			for _, c := range o.Children {
+				if r, ok := c.(*Run); ok {
+					add(r)
+				}
+			}
+		}
+	}
+	return children
+}
+
+// deletedRun 将 Run 中的文字与域指令换为删除的形式
+func deletedRun(r *Run) *Run {
+	nr := *r
+	nr.Children = make([]interface{}, 0, len(r.Children)+1)
+	if r.InstrText != "" {
+		nr.InstrText = ""
+		nr.Children = append(nr.Children, &DelInstrText{XMLSpace: "preserve", Text: r.InstrText})
+	}
+	for _, child := range r.Children {
+		switch o := child.(type) {
+		case *Text:
+			nr.Children = append(nr.Children, &DelText{XMLSpace: o.XMLSpace, Text: o.Text})
+		case *InstrText:
+			nr.Children = append(nr.Children, &DelInstrText{XMLSpace: o.XMLSpace, Text: o.Text})
+		default:
+			nr.Children = append(nr.Children, child)
+		}
+	}
+	return &nr
+}
@@ -0,0 +1,187 @@
+package docx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// WithRunLanguageRouting 按 Run 上的语言标记 (w:lang) 将段落拆分为多个翻译单元, 默认关闭
+//
+// 开启后, 中文文档中标记为英文的 Run 会以英文为原文单独翻译, 已经是目标语言的 Run 原样保留,
+// 而不是与周围的中文拼接为一段混合语言的文本. 少于 routeMinWords 个词的外文片段 (如夹在句中的
+// 产品名或数字) 并入相邻的片段, 避免句子被切得过碎.
+func (t *Translator) WithRunLanguageRouting(enable bool) *Translator {
+	t.routeRuns = enable
+	return t
+}
+
+// routeMinWords 是单独翻译一个外文片段所需的最少词数, 中日韩文字每个字计为一个词
+const routeMinWords = 3
+
+// runGroup 是段落中语言相同的一组相邻 Run
+type runGroup struct {
+	runs []*Run
+	lang string // lang 是 ISO 639-1 代码, 为空表示没有标记
+	text string
+}
+
+// withSourceLanguage 返回原文语言为 lang 的浅拷贝, 缓存、翻译记忆等设置与 t 共享
+func (t *Translator) withSourceLanguage(lang string) *Translator {
+	c := *t
+	c.sourceLang = lang
+	return &c
+}
+
+// runLanguage 返回 Run 的语言标记: 含中日韩文字时取 eastAsia, 否则取 val
+func runLanguage(r *Run, text string) string {
+	if r.RunProperties == nil || r.RunProperties.Lang == nil {
+		return ""
+	}
+	lang := r.RunProperties.Lang.Val
+	for _, c := range text {
+		if isCJK(c) {
+			lang = r.RunProperties.Lang.EastAsia
+			break
+		}
+	}
+	if lang == "" {
+		return ""
+	}
+	return LanguageCode(lang)
+}
+
+// routeWords 统计 text 中的词数, 中日韩文字每个字计为一个词
+func routeWords(text string) int {
+	n := 0
+	for _, f := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) || isCJK(r)
+	}) {
+		if f != "" {
+			n++
+		}
+	}
+	for _, r := range text {
+		if isCJK(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// groupRuns 将段落 p 的 Run 按语言分组; 段落中含有 Run 以外的内容 (如超链接),
+// 或 Run 中含有文本与制表符以外的内容 (如图片) 时返回 nil
+func groupRuns(p *Paragraph, sourceLanguage string) []*runGroup {
+	var groups []*runGroup
+	for _, child := range p.Children {
+		run, ok := child.(*Run)
+		if !ok {
+			return nil
+		}
+		var sb strings.Builder
+		for _, grandChild := range run.Children {
+			if text, ok := grandChild.(*Text); ok {
+				sb.WriteString(text.Text)
+			} else if special, ok := specialText(grandChild); ok {
+				sb.WriteString(special)
+			} else {
+				return nil
+			}
+		}
+		text := sb.String()
+		lang := runLanguage(run, text)
+		if lang == LanguageCode(sourceLanguage) {
+			lang = ""
+		}
+		last := len(groups) - 1
+		// 没有标记或只有空白的 Run 跟随前一组
+		if last >= 0 && (lang == groups[last].lang || strings.TrimSpace(text) == "" || run.RunProperties == nil || run.RunProperties.Lang == nil) {
+			groups[last].runs = append(groups[last].runs, run)
+			groups[last].text += text
+			continue
+		}
+		groups = append(groups, &runGroup{runs: []*Run{run}, lang: lang, text: text})
+	}
+	// 过短的外文片段并入前一组 (第一组并入后一组), 之后合并语言相同的相邻组
+	merged := groups[:0]
+	for i, g := range groups {
+		if g.lang != "" && routeWords(g.text) < routeMinWords && len(groups) > 1 {
+			if len(merged) > 0 {
+				prev := merged[len(merged)-1]
+				prev.runs = append(prev.runs, g.runs...)
+				prev.text += g.text
+				continue
+			}
+			next := groups[i+1]
+			next.runs = append(g.runs, next.runs...)
+			next.text = g.text + next.text
+			continue
+		}
+		if len(merged) > 0 && merged[len(merged)-1].lang == g.lang {
+			prev := merged[len(merged)-1]
+			prev.runs = append(prev.runs, g.runs...)
+			prev.text += g.text
+			continue
+		}
+		merged = append(merged, g)
+	}
+	return merged
+}
+
+// route 按语言标记拆分段落 p, 返回新段落; 段落中只有原文语言时返回 nil, 由调用方整段翻译
+func (t *Translator) route(p *Paragraph, newDoc *Docx, targetLanguage string) (*Paragraph, []*segment) {
+	groups := groupRuns(p, t.sourceLanguageName())
+	if len(groups) == 0 || len(groups) == 1 && groups[0].lang == "" {
+		return nil, nil
+	}
+	target := LanguageCode(targetLanguage)
+	pad := targetLanguage != "" && !isScriptioContinua(targetLanguage)
+	np := &Paragraph{
+		Properties: p.Properties,
+		Children:   make([]interface{}, 0, len(p.Children)),
+		file:       newDoc,
+	}
+	var segs []*segment
+	for i, g := range groups {
+		if g.lang == target && target != "" || strings.TrimSpace(g.text) == "" {
+			for _, run := range g.runs {
+				np.Children = append(np.Children, run)
+			}
+			continue
+		}
+		sg := &segment{
+			src:    p,
+			dst:    np,
+			text:   strings.TrimSpace(g.text),
+			hint:   t.styleHint(p),
+			lang:   g.lang,
+			run:    g.runs[0],
+			routed: true,
+			slot:   len(np.Children),
+		}
+		if pad {
+			// 译文两侧补上与相邻片段之间的空格, 相邻片段自带空格时不重复添加
+			if i > 0 && (groups[i-1].lang != target || endsWithWord(groups[i-1].text)) {
+				sg.lead = " "
+			}
+			if i < len(groups)-1 && groups[i+1].lang == target && startsWithWord(groups[i+1].text) {
+				sg.trail = " "
+			}
+		}
+		np.Children = append(np.Children, nil) // 占位, 由 fill 填充
+		segs = append(segs, sg)
+	}
+	return np, segs
+}
+
+// startsWithWord 判断 text 是否以字母或数字开头
+func startsWithWord(text string) bool {
+	r, _ := utf8.DecodeRuneInString(text)
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// endsWithWord 判断 text 是否以字母或数字结尾
+func endsWithWord(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(text)
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,73 @@
+package docx
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestRunLang(t *testing.T) {
+	r := &Run{RunProperties: &RunProperties{}}
+	r.Language("en-US", "zh-CN")
+	data, err := xml.Marshal(r.RunProperties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `<w:lang w:val="en-US" w:eastAsia="zh-CN"></w:lang>`) {
+		t.Fatal("unexpected xml:", string(data))
+	}
+	var rp RunProperties
+	if err = xml.Unmarshal(data, &rp); err != nil {
+		t.Fatal(err)
+	}
+	if rp.Lang == nil || rp.Lang.Val != "en-US" || rp.Lang.EastAsia != "zh-CN" {
+		t.Fatalf("unexpected lang: %+v", rp.Lang)
+	}
+}
+
+func TestTranslateDocxRunRouting(t *testing.T) {
+	doc := New().WithDefaultTheme()
+	p := doc.AddParagraph()
+	p.AddText("请参考").Language("en-US", "zh-CN")
+	p.AddText(" the user manual ").Language("en-US", "zh-CN")
+	p.AddText("获取帮助").Language("en-US", "zh-CN")
+	// 少于 routeMinWords 个词的英文并入中文
+	doc.AddParagraph().AddText("使用 Go 开发").Language("en-US", "zh-CN")
+
+	var seen []string
+	prov := ProviderFunc(func(text, _ string) (string, error) {
+		seen = append(seen, text)
+		return "<" + text + ">", nil
+	})
+	tm := NewTranslationMemory()
+	newDoc, err := NewTranslator("", "").WithProvider(prov).WithTM(tm).WithRunLanguageRouting(true).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 目标语言的片段原样保留, 不请求翻译服务
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "<请参考> the user manual <获取帮助>" {
+		t.Fatalf("unexpected paragraph: %q", s)
+	}
+	if strings.Join(seen, "|") != "请参考|获取帮助|使用 Go 开发" {
+		t.Fatalf("unexpected provider input: %q", seen)
+	}
+
+	seen = seen[:0]
+	newDoc, err = NewTranslator("", "").WithProvider(prov).WithTM(tm).WithRunLanguageRouting(true).TranslateDocx(doc, "ja")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "<请参考><the user manual><获取帮助>" {
+		t.Fatalf("unexpected paragraph: %q", s)
+	}
+	// 英文片段以英文为原文单独翻译
+	var langs []string
+	for _, u := range tm.Units() {
+		if u.Source == "the user manual" {
+			langs = append(langs, u.SourceLang)
+		}
+	}
+	if len(langs) != 1 || langs[0] != "en" {
+		t.Fatalf("unexpected source languages: %q", langs)
+	}
+}
@@ -0,0 +1,445 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode/utf16"
+)
+
+// ErrInvalidRTF 不是 RTF 文档
+var ErrInvalidRTF = errors.New("invalid RTF document")
+
+// WithRTFConverter 设置 TranslateFile 将 RTF 转换为 docx 的函数, 如调用 LibreOffice 转换以保留图片与复杂的版式;
+// 默认使用 ParseRTF. 无论如何转换, 译文都以 docx 格式输出
+func (t *Translator) WithRTFConverter(convert func(data []byte) (*Docx, error)) *Translator {
+	t.rtfConverter = convert
+	return t
+}
+
+func translateRTFFile(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error {
+	convert := t.rtfConverter
+	if convert == nil {
+		convert = ParseRTF
+	}
+	doc, err := convert(data)
+	if err != nil {
+		return err
+	}
+	return t.writeTranslatedDocx(ctx, doc, w, targetLanguage)
+}
+
+// sniffRTF 判断是否为 RTF 文档
+func sniffRTF(data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte(`{\rtf`))
+}
+
+// ParseRTF 将 RTF 文档 data 转换为 docx 文档, 供 TranslateDocx 翻译
+//
+// 转换页面大小与页边距、段落与其对齐方式、粗体、斜体、下划线、字号、制表符、换行、分页符与简单的表格, 域只保留其显示的结果;
+// 字体表、样式表、图片、页眉页脚与脚注等不转换. 字符按 \uN 或文档的代码页 (\ansicpg) 解码,
+// 代码页只支持 1252 (西欧), 其他代码页中没有用 \uN 表示的字符返回错误, 此时可以用 WithRTFConverter 设置其他转换方式.
+func ParseRTF(data []byte) (*Docx, error) {
+	if !sniffRTF(data) {
+		return nil, ErrInvalidRTF
+	}
+	r := &rtfReader{
+		doc:      New().WithDefaultTheme(),
+		codePage: 1252,
+		state:    rtfState{uc: 1},
+		// 没有设置页面时使用 RTF 规定的默认值
+		sect: &SectPr{
+			PgSz:  &PgSz{W: 12240, H: 15840},
+			PgMar: &PgMar{Top: 1440, Left: 1800, Bottom: 1440, Right: 1800, Header: 720, Footer: 720},
+		},
+	}
+	if err := r.read(data); err != nil {
+		return nil, err
+	}
+	// 页面设置必须位于 body 的末尾
+	r.doc.Document.Body.Items = append(r.doc.Document.Body.Items, r.sect)
+	return r.doc, nil
+}
+
+// rtfState 是 RTF 组中的状态, 进入组时复制, 离开组时恢复
+type rtfState struct {
+	skip                    bool // skip 表示组中的内容不是正文, 如字体表与图片
+	bold, italic, underline bool
+	size                    int // size 是字号, 单位为半磅, 0 表示默认
+	uc                      int // uc 是 \uN 之后跳过的替代字符数
+}
+
+// rtfPara 是段落的格式
+type rtfPara struct {
+	align   string
+	inTable bool
+}
+
+// rtfReader 将 RTF 转换为 docx
+type rtfReader struct {
+	doc      *Docx
+	codePage int
+	sect     *SectPr // sect 是文档的页面设置
+
+	state rtfState
+	stack []rtfState
+	para  rtfPara
+
+	p       *Paragraph    // p 是正在写入的段落
+	text    []rune        // text 是尚未写入段落的文字, 格式为 textFmt
+	textFmt rtfState      // textFmt 是 text 的格式
+	pending []uint16      // pending 是 \uN 中尚未组成字符的代理对
+	skipN   int           // skipN 是还要跳过的替代字符数
+	table   *Table        // table 是正在写入的表格
+	cells   []*WTableCell // cells 是当前行中已经结束的单元格
+	cell    []*Paragraph  // cell 是当前单元格中已经结束的段落
+}
+
+// rtfDestinations 是内容不是正文的组
+var rtfDestinations = map[string]bool{
+	"fonttbl": true, "colortbl": true, "stylesheet": true, "info": true, "pict": true, "object": true,
+	"header": true, "headerl": true, "headerr": true, "headerf": true, "footer": true, "footerl": true, "footerr": true, "footerf": true,
+	"footnote": true, "annotation": true, "fldinst": true, "listtable": true, "listoverridetable": true, "rsidtbl": true,
+	"generator": true, "themedata": true, "colorschememapping": true, "datastore": true, "latentstyles": true,
+	"xmlnstbl": true, "filetbl": true, "revtbl": true, "bkmkstart": true, "bkmkend": true, "shp": true, "nonshppict": true,
+}
+
+// rtfSymbols 是表示字符的控制字
+var rtfSymbols = map[string]string{
+	"tab": "\t", "line": "\n", "emdash": "—", "endash": "–", "bullet": "•",
+	"lquote": "‘", "rquote": "’", "ldblquote": "“", "rdblquote": "”",
+	"emspace": "\u2003", "enspace": "\u2002", "qmspace": "\u2005",
+}
+
+func (r *rtfReader) read(data []byte) error {
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch c {
+		case '{':
+			r.stack = append(r.stack, r.state)
+			i++
+		case '}':
+			if len(r.stack) > 0 {
+				r.state = r.stack[len(r.stack)-1]
+				r.stack = r.stack[:len(r.stack)-1]
+			}
+			i++
+		case '\\':
+			n, err := r.control(data[i:])
+			if err != nil {
+				return err
+			}
+			i += n
+		case '\r', '\n':
+			i++
+		default:
+			if err := r.byteChar(c); err != nil {
+				return err
+			}
+			i++
+		}
+	}
+	if r.p != nil || len(r.text) > 0 {
+		r.endPara()
+	}
+	r.endTable()
+	return nil
+}
+
+// control 处理 data 开头的控制字或控制符号, 返回其长度
+func (r *rtfReader) control(data []byte) (int, error) {
+	if len(data) < 2 {
+		return len(data), nil
+	}
+	c := data[1]
+	switch {
+	case c == '\'':
+		if len(data) < 4 {
+			return len(data), nil
+		}
+		b, err := strconv.ParseUint(string(data[2:4]), 16, 8)
+		if err != nil {
+			return 4, nil
+		}
+		return 4, r.byteChar(byte(b))
+	case c == '*':
+		// 无法识别时可以忽略的组
+		r.state.skip = true
+		return 2, nil
+	case c == '\\' || c == '{' || c == '}':
+		r.char(rune(c))
+		return 2, nil
+	case c == '~':
+		r.char(' ')
+		return 2, nil
+	case c == '_':
+		r.char('\u2011')
+		return 2, nil
+	case c == '-':
+		r.char('\u00ad')
+		return 2, nil
+	case c == '\r' || c == '\n':
+		r.word("par", 0, false)
+		return 2, nil
+	case !isASCIILetter(c):
+		return 2, nil
+	}
+	n := 1
+	for n < len(data) && isASCIILetter(data[n]) {
+		n++
+	}
+	name := string(data[1:n])
+	start := n
+	if n < len(data) && data[n] == '-' {
+		n++
+	}
+	for n < len(data) && data[n] >= '0' && data[n] <= '9' {
+		n++
+	}
+	param, hasParam := 0, n > start
+	if hasParam {
+		param, _ = strconv.Atoi(string(data[start:n]))
+	}
+	if n < len(data) && data[n] == ' ' {
+		n++ // 分隔符
+	}
+	r.word(name, param, hasParam)
+	return n, nil
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// word 处理控制字 name 与其参数, 非正文中的控制字被忽略
+func (r *rtfReader) word(name string, param int, hasParam bool) {
+	if r.state.skip {
+		return
+	}
+	if rtfDestinations[name] {
+		r.state.skip = true
+		return
+	}
+	on := !hasParam || param != 0
+	switch name {
+	case "ansicpg":
+		r.codePage = param
+	case "uc":
+		r.state.uc = param
+	case "u":
+		if param < 0 {
+			param += 65536
+		}
+		r.unicode(uint16(param))
+		r.skipN = r.state.uc
+		if !hasParam {
+			r.skipN = 0
+		}
+	case "par":
+		r.endPara()
+	case "page":
+		r.flush()
+		r.paragraph().AddPageBreaks()
+	case "pard":
+		r.para = rtfPara{}
+	case "intbl":
+		r.para.inTable = true
+	case "ql":
+		r.para.align = "start"
+	case "qc":
+		r.para.align = "center"
+	case "qr":
+		r.para.align = "end"
+	case "qj":
+		r.para.align = "both"
+	case "cell":
+		r.endCell()
+	case "row":
+		r.endRow()
+	case "plain":
+		r.state.bold, r.state.italic, r.state.underline, r.state.size = false, false, false, 0
+	case "b":
+		r.state.bold = on
+	case "i":
+		r.state.italic = on
+	case "ul":
+		r.state.underline = on
+	case "ulnone":
+		r.state.underline = false
+	case "fs":
+		r.state.size = param
+	case "paperw":
+		r.sect.PgSz.W = param
+	case "paperh":
+		r.sect.PgSz.H = param
+	case "landscape":
+		r.sect.PgSz.Orient = "landscape"
+	case "margl":
+		r.sect.PgMar.Left = param
+	case "margr":
+		r.sect.PgMar.Right = param
+	case "margt":
+		r.sect.PgMar.Top = param
+	case "margb":
+		r.sect.PgMar.Bottom = param
+	default:
+		if s, ok := rtfSymbols[name]; ok {
+			for _, c := range s {
+				r.char(c)
+			}
+		}
+	}
+}
+
+// byteChar 处理代码页中的字符 c
+func (r *rtfReader) byteChar(c byte) error {
+	if c < 0x80 {
+		r.char(rune(c))
+		return nil
+	}
+	if r.state.skip || r.skipN > 0 {
+		r.char(0) // 只用于消耗跳过的字符
+		return nil
+	}
+	switch r.codePage {
+	case 1252, 0:
+		r.char(cp1252(c))
+		return nil
+	case 28591:
+		r.char(rune(c))
+		return nil
+	}
+	return fmt.Errorf("%w: RTF code page %d", ErrUnsupportedFormat, r.codePage)
+}
+
+// cp1252 将代码页 1252 中的字符 c 转为 Unicode
+func cp1252(c byte) rune {
+	const high = "€�‚ƒ„…†‡ˆ‰Š‹Œ�Ž��‘’“”•–—˜™š›œ�žŸ"
+	if c >= 0x80 && c < 0xa0 {
+		return []rune(high)[c-0x80]
+	}
+	return rune(c)
+}
+
+// unicode 处理 \uN 中的 UTF-16 编码单元
+func (r *rtfReader) unicode(u uint16) {
+	if utf16.IsSurrogate(rune(u)) {
+		r.pending = append(r.pending, u)
+		if len(r.pending) < 2 {
+			return
+		}
+		for _, c := range utf16.Decode(r.pending) {
+			r.char(c)
+		}
+		r.pending = r.pending[:0]
+		return
+	}
+	r.pending = r.pending[:0]
+	r.char(rune(u))
+}
+
+// char 将字符 c 写入当前段落, 跳过非正文与 \uN 之后的替代字符
+func (r *rtfReader) char(c rune) {
+	if r.skipN > 0 {
+		r.skipN--
+		return
+	}
+	if r.state.skip || c == 0 {
+		return
+	}
+	format := r.state
+	format.uc = 0
+	if len(r.text) > 0 && format != r.textFmt {
+		r.flush()
+	}
+	r.textFmt = format
+	r.text = append(r.text, c)
+}
+
+// paragraph 返回当前段落, 没有时新建
+func (r *rtfReader) paragraph() *Paragraph {
+	if r.p == nil {
+		r.p = &Paragraph{Children: make([]interface{}, 0, 8), file: r.doc}
+	}
+	return r.p
+}
+
+// flush 将尚未写入的文字写入当前段落
+func (r *rtfReader) flush() {
+	if len(r.text) == 0 {
+		return
+	}
+	run := r.paragraph().AddText(string(r.text))
+	if r.textFmt.bold {
+		run.Bold()
+	}
+	if r.textFmt.italic {
+		run.Italic()
+	}
+	if r.textFmt.underline {
+		run.Underline("single")
+	}
+	if r.textFmt.size > 0 {
+		run.Size(strconv.Itoa(r.textFmt.size))
+	}
+	r.text = r.text[:0]
+}
+
+// endPara 结束当前段落, 表格中的段落放入当前单元格; 没有内容的段落同样保留
+func (r *rtfReader) endPara() {
+	r.flush()
+	p := r.paragraph()
+	r.p = nil
+	if r.para.align != "" {
+		p.Justification(r.para.align)
+	}
+	if r.para.inTable {
+		r.cell = append(r.cell, p)
+		return
+	}
+	r.endTable()
+	r.doc.Document.Body.Items = append(r.doc.Document.Body.Items, p)
+}
+
+// endCell 结束当前单元格
+func (r *rtfReader) endCell() {
+	if r.p != nil || len(r.text) > 0 || len(r.cell) == 0 {
+		r.para.inTable = true
+		r.endPara()
+	}
+	r.cells = append(r.cells, &WTableCell{
+		TableCellProperties: &WTableCellProperties{TableCellWidth: &WTableCellWidth{Type: "auto"}},
+		Paragraphs:          r.cell,
+		file:                r.doc,
+	})
+	r.cell = nil
+}
+
+// endRow 结束当前行
+func (r *rtfReader) endRow() {
+	if len(r.cells) == 0 {
+		return
+	}
+	if r.table == nil {
+		r.table = r.doc.AddTable(0, 0, 0, nil)
+		r.table.file = r.doc
+	}
+	r.table.TableRows = append(r.table.TableRows, &WTableRow{
+		TableRowProperties: &WTableRowProperties{},
+		TableCells:         r.cells,
+		file:               r.doc,
+	})
+	r.cells = nil
+	r.para.inTable = false
+}
+
+// endTable 结束当前表格, 之后的段落位于表格之后
+func (r *rtfReader) endTable() {
+	if len(r.cells) > 0 {
+		r.endRow()
+	}
+	r.table = nil
+}
@@ -0,0 +1,84 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseRTF(t *testing.T) {
+	const src = `{\rtf1\ansi\ansicpg1252\deff0{\fonttbl{\f0\fswiss Arial;}}{\colortbl;\red0\green0\blue0;}` +
+		`{\info{\title Hidden title}}\paperw11906\paperh16838\margl1134` + "\r\n" +
+		`\pard\qc{\b Caf\'e9 menu}\par` + "\r\n" +
+		`\pard Price:\tab 5 {\i euros}\line \uc1\u20320?\u22909?{\*\bkmkstart b1}{\field{\*\fldinst HYPERLINK "https://example.com"}{\fldrslt link}}\par` +
+		`\trowd\cellx2000\cellx4000\pard\intbl one\cell\pard\intbl two\cell\row` +
+		`\pard after\page\par}`
+	doc, err := ParseRTF([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := doc.Document.Body.Items
+	if len(items) != 5 {
+		t.Fatal("expected 5 body items, got", len(items))
+	}
+	title := items[0].(*Paragraph)
+	if s := title.String(); s != "Café menu" {
+		t.Fatal("unexpected title:", s)
+	}
+	if title.Properties.Justification.Val != "center" || title.Children[0].(*Run).RunProperties.Bold == nil {
+		t.Fatal("the title format was not converted")
+	}
+	if s := items[1].(*Paragraph).String(); s != "Price:\t5 euros\n你好link" {
+		t.Fatalf("unexpected paragraph: %q", s)
+	}
+	tbl := items[2].(*Table)
+	if len(tbl.TableRows) != 1 || len(tbl.TableRows[0].TableCells) != 2 || tbl.TableRows[0].TableCells[1].Paragraphs[0].String() != "two" {
+		t.Fatal("the table was not converted")
+	}
+	if s := items[3].(*Paragraph).String(); !strings.HasPrefix(s, "after") {
+		t.Fatal("unexpected paragraph after the table:", s)
+	}
+	sect := items[4].(*SectPr)
+	if sect.PgSz.W != 11906 || sect.PgSz.H != 16838 || sect.PgMar.Left != 1134 {
+		t.Fatal("the page setup was not converted:", sect.PgSz, sect.PgMar)
+	}
+
+	if _, err = ParseRTF([]byte(`{\rtf1\ansi\ansicpg936 \'c4\'e3}`)); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatal("expected ErrUnsupportedFormat, got", err)
+	}
+	if _, err = ParseRTF([]byte("hello")); !errors.Is(err, ErrInvalidRTF) {
+		t.Fatal("expected ErrInvalidRTF, got", err)
+	}
+}
+
+func TestTranslateFileRTF(t *testing.T) {
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	var out bytes.Buffer
+	if err := tr.TranslateFile(context.Background(), strings.NewReader(`{\rtf1\ansi hello\par}`), &out, TranslateFileOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(out.Bytes()), int64(out.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := doc.Document.Body.Items[0].(*Paragraph).String(); s != "HELLO" {
+		t.Fatal("unexpected translation:", s)
+	}
+
+	converted := false
+	tr.WithRTFConverter(func(data []byte) (*Docx, error) {
+		converted = true
+		return newTestDoc("converted"), nil
+	})
+	out.Reset()
+	if err = tr.TranslateFile(context.Background(), strings.NewReader(`{\rtf1 ignored}`), &out, TranslateFileOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !converted {
+		t.Fatal("the converter was not used")
+	}
+}
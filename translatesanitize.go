@@ -0,0 +1,92 @@
+package docx
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// ErrMalformedResponse 翻译服务的回复明显异常 (如为空、拒绝翻译或只有解释), 以更严格的提示词重试后仍然如此
+var ErrMalformedResponse = errors.New("malformed response from provider")
+
+// Sanitizer 清理翻译服务回复 response 中的多余内容, source 是请求翻译的原文;
+// ok 为 false 表示回复明显异常, 需要以更严格的提示词重试
+type Sanitizer func(source, response string) (cleaned string, ok bool)
+
+// WithSanitizer 设置清理翻译服务回复的函数, 默认为 DefaultSanitizer; 传入 nil 以关闭
+func (t *Translator) WithSanitizer(s Sanitizer) *Translator {
+	t.sanitizer = s
+	t.noSanitizer = s == nil
+	return t
+}
+
+// strictHint 是回复异常时重试附加的翻译要求
+const strictHint = "只输出译文本身: 不要解释, 不要回答问题, 不要添加引号、代码块或 \"以下是翻译\" 这样的前言与注释."
+
+var (
+	// sanitizePreambleRe 匹配 "Here is the translation:", "译文如下：" 这样的前言
+	sanitizePreambleRe = regexp.MustCompile(`(?i)^(?:(?:sure|certainly|okay|ok)[,!.]?\s*)?(?:here(?:'s| is| are)\s+(?:the|your|my)\s+(?:\w+\s+)?translat\w*(?:\s+\w+){0,4}|(?:the\s+)?translat(?:ion|ed text)(?:\s+\w+){0,3}|以下是[^\n:：]{0,12}(?:翻译|译文)[^\n:：]{0,6}|(?:翻译|译文)(?:结果|如下)?)\s*[:：]\s*`)
+	// sanitizeNoteRe 匹配译文之后的注释行
+	sanitizeNoteRe = regexp.MustCompile(`(?i)^[(（]?\s*(?:note|notes|explanation|注|注释|说明|备注)\s*[:：]`)
+	// sanitizeRefusalRe 匹配拒绝翻译或自我介绍的回复
+	sanitizeRefusalRe = regexp.MustCompile(`(?i)^(?:i'?m sorry|i am sorry|sorry, i|i cannot|i can't|as an ai|抱歉|对不起|很抱歉|我无法|作为一个?(?:ai|人工智能))`)
+)
+
+// sanitizeQuotes 是可能包住整个回复的引号
+var sanitizeQuotes = [...][2]string{{`"`, `"`}, {"“", "”"}, {"'", "'"}, {"‘", "’"}, {"「", "」"}, {"『", "』"}, {"«", "»"}}
+
+// DefaultSanitizer 去除回复中的代码块标记、"Here is the translation:" 这样的前言、
+// 译文之后的 "Note: ..." 注释, 以及包住整个译文的引号; 原文本身具有的同类内容不会被去除.
+// 回复为空或以 "I'm sorry", "作为 AI" 这样的内容开头时视为异常.
+func DefaultSanitizer(source, response string) (string, bool) {
+	s := strings.TrimSpace(response)
+	src := strings.TrimSpace(source)
+	if strings.HasPrefix(s, "```") && strings.HasSuffix(s, "```") && len(s) >= 6 && !strings.Contains(src, "```") {
+		s = strings.TrimSuffix(s, "```")
+		if i := strings.IndexByte(s, '\n'); i >= 0 {
+			s = s[i+1:] // 去掉 ```text 这样的首行
+		} else {
+			s = strings.TrimPrefix(s, "```")
+		}
+		s = strings.TrimSpace(s)
+	}
+	if loc := sanitizePreambleRe.FindStringIndex(s); loc != nil && !sanitizePreambleRe.MatchString(src) {
+		s = strings.TrimSpace(s[loc[1]:])
+	}
+	if srcLines := strings.Count(src, "\n") + 1; strings.Count(s, "\n")+1 > srcLines {
+		lines := strings.Split(s, "\n")
+		for i := srcLines; i < len(lines); i++ {
+			if sanitizeNoteRe.MatchString(strings.TrimSpace(lines[i])) {
+				s = strings.TrimSpace(strings.Join(lines[:i], "\n"))
+				break
+			}
+		}
+	}
+	for _, q := range sanitizeQuotes {
+		if len(s) <= len(q[0])+len(q[1]) || !strings.HasPrefix(s, q[0]) || !strings.HasSuffix(s, q[1]) ||
+			strings.HasPrefix(src, q[0]) && strings.HasSuffix(src, q[1]) {
+			continue
+		}
+		// "a" and "b" 这样两端恰好是引号的译文不能去除
+		if inner := s[len(q[0]) : len(s)-len(q[1])]; !strings.Contains(inner, q[0]) && !strings.Contains(inner, q[1]) {
+			s = strings.TrimSpace(inner)
+		}
+		break
+	}
+	if s == "" && src != "" || sanitizeRefusalRe.MatchString(s) && !sanitizeRefusalRe.MatchString(src) {
+		return s, false
+	}
+	return s, true
+}
+
+// sanitize 使用已配置的 Sanitizer 清理回复
+func (t *Translator) sanitize(source, response string) (string, bool) {
+	if t.noSanitizer {
+		return response, true
+	}
+	s := t.sanitizer
+	if s == nil {
+		s = DefaultSanitizer
+	}
+	return s(source, response)
+}
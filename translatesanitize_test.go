@@ -0,0 +1,77 @@
+package docx
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDefaultSanitizer(t *testing.T) {
+	for _, c := range []struct {
+		source, response, want string
+		ok                     bool
+	}{
+		{"你好", "Here is the translation:\nHello", "Hello", true},
+		{"你好", "Sure! Here's the English translation: Hello", "Hello", true},
+		{"Hello", "译文：你好", "你好", true},
+		{"你好", "```text\nHello\n```", "Hello", true},
+		{"你好", "“Hello”", "Hello", true},
+		{"你好", `"Hello" and "bye"`, `"Hello" and "bye"`, true},
+		{"“你好”", "“Hello”", "“Hello”", true},
+		{"你好", "Hello\nNote: this is a greeting.", "Hello", true},
+		{"翻译: 你好", "Translation: hello", "Translation: hello", true},
+		{"你好", "I'm sorry, but I can't help with that.", "I'm sorry, but I can't help with that.", false},
+		{"你好", "  ", "", false},
+	} {
+		got, ok := DefaultSanitizer(c.source, c.response)
+		if got != c.want || ok != c.ok {
+			t.Errorf("%q: got %q %v, want %q %v", c.response, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestSanitizeRetry(t *testing.T) {
+	var hints []string
+	p := hintRecorderFunc(func(text, hint string) (string, error) {
+		hints = append(hints, hint)
+		switch {
+		case text == "拒绝":
+			return "As an AI, I cannot.", nil
+		case strings.Contains(hint, strictHint):
+			return "Hello", nil
+		default:
+			return "抱歉, 我无法翻译", nil
+		}
+	})
+	tr := NewTranslator("", "").WithProvider(p).WithErrorPolicy(ErrorPolicyCollect)
+	newDoc, err := tr.TranslateDocx(newTestDoc("你好", "拒绝"), "English")
+	var ses SegmentErrors
+	if !errors.As(err, &ses) || len(ses) != 1 || !errors.Is(ses[0], ErrMalformedResponse) {
+		t.Fatal("expected one ErrMalformedResponse, got", err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "Hello" {
+		t.Fatal("unexpected retried translation:", s)
+	}
+	if len(hints) != 4 || !strings.Contains(hints[1], strictHint) {
+		t.Fatalf("unexpected hints: %q", hints)
+	}
+
+	// 关闭后原样使用回复
+	newDoc, err = NewTranslator("", "").WithProvider(p).WithSanitizer(nil).TranslateDocx(newTestDoc("你好"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "抱歉, 我无法翻译" {
+		t.Fatal("unexpected raw translation:", s)
+	}
+}
+
+type hintRecorderFunc func(text, hint string) (string, error)
+
+func (f hintRecorderFunc) Translate(text, _ string) (string, error) {
+	return f(text, "")
+}
+
+func (f hintRecorderFunc) TranslateWithHint(text, _, hint string) (string, error) {
+	return f(text, hint)
+}
@@ -0,0 +1,51 @@
+package docx
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Segment 是文档中的一个翻译单元, 可以编码为 JSON, 供调用方搭建自己的审校界面或翻译流程
+//
+// Source 与 Target 中的 ⟪1⟫ 与 ⟪/1⟫ 这样成对的标记包住超链接、域等的文字, ⟪2/⟫ 这样单独的标记代表公式、书签等不翻译的内容,
+// 译文中保留这些标记才能重建超链接与域, 规则同 TranslateDocx.
+type Segment struct {
+	// ID 是翻译单元的编号, 依次为 u1, u2..., 与 ExportXLIFF 和 WriteReportCSV 相同
+	ID string `json:"id"`
+	// Source 是原文
+	Source string `json:"source"`
+	// Target 是译文, 为空表示保留原文
+	Target string `json:"target,omitempty"`
+	// Hint 是 TranslateDocx 交给翻译服务的附加要求, 如段落样式与标记的说明
+	Hint string `json:"hint,omitempty"`
+	// SourceLanguage 是按语言标记拆分出的片段的原文语言, 为空表示与文档相同, 见 WithRunLanguageRouting
+	SourceLanguage string `json:"sourceLanguage,omitempty"`
+}
+
+// segmentID 返回第 i 个 (从 0 开始) 翻译单元的编号
+func segmentID(i int) string {
+	return "u" + strconv.Itoa(i+1)
+}
+
+// ExtractSegments 返回 doc 译为 targetLanguage 时的翻译单元, 顺序与划分同 TranslateDocx (同样受 WithStyleFilter, WithRange 等设置影响);
+// 不请求翻译服务, 返回的 Target 为空
+func (t *Translator) ExtractSegments(doc *Docx, targetLanguage string) []Segment {
+	_, segs := t.prepare(doc, targetLanguage)
+	out := make([]Segment, len(segs))
+	for i, sg := range segs {
+		out[i] = Segment{ID: segmentID(i), Source: sg.text, Hint: sg.hint, SourceLanguage: sg.lang}
+	}
+	return out
+}
+
+// ApplySegments 将 segs 中的译文按 ID 填入 doc 的副本, 返回译为 targetLanguage 的新文档
+//
+// segs 通常来自 ExtractSegments 并由调用方填入 Target. Source 与文档不一致时返回包装了 ErrSegmentsMismatch 的错误;
+// segs 中没有的翻译单元与 Target 为空的翻译单元保留原文. 译文按原样写入, 不请求翻译服务, 也不套用标点、数字格式等后处理.
+func (t *Translator) ApplySegments(doc *Docx, targetLanguage string, segs []Segment) (*Docx, error) {
+	units := make(map[string]*importUnit, len(segs))
+	for _, s := range segs {
+		units[s.ID] = &importUnit{source: s.Source, target: s.Target, translated: strings.TrimSpace(s.Target) != ""}
+	}
+	return t.fillImported(doc, targetLanguage, units, false, ErrSegmentsMismatch)
+}
@@ -0,0 +1,38 @@
+package docx
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestExtractApplySegments(t *testing.T) {
+	doc := newTestDoc("hello", "world")
+	tr := NewTranslator("", "")
+	segs := tr.ExtractSegments(doc, "English")
+	data, err := json.Marshal(segs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `[{"id":"u1","source":"hello"},{"id":"u2","source":"world"}]` {
+		t.Fatal("unexpected segments:", string(data))
+	}
+
+	var edited []Segment
+	if err = json.Unmarshal([]byte(`[{"id":"u2","source":"world","target":"WORLD"}]`), &edited); err != nil {
+		t.Fatal(err)
+	}
+	newDoc, err := tr.ApplySegments(doc, "English", edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"hello", "WORLD"} {
+		if s := newDoc.Document.Body.Items[i].(*Paragraph).String(); s != want {
+			t.Errorf("paragraph %d: got %q, want %q", i, s, want)
+		}
+	}
+	edited[0].Source = "changed"
+	if _, err = tr.ApplySegments(doc, "English", edited); !errors.Is(err, ErrSegmentsMismatch) {
+		t.Fatal("expected ErrSegmentsMismatch, got", err)
+	}
+}
@@ -0,0 +1,227 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// ServerOptions 是 JobManager.Handler 与 JobQueue.Handler 的设置
+type ServerOptions struct {
+	// MaxUploadSize 是上传文件的最大字节数, 为 0 时为 DefaultMaxUploadSize
+	MaxUploadSize int64
+	// SyncLimit 是同步翻译的最大字节数: 不超过它的文件等待译文后直接返回, 更大的文件返回任务 ID 供轮询;
+	// 为 0 时为 DefaultSyncLimit, 小于 0 时总是返回任务 ID
+	SyncLimit int
+	// SyncTimeout 是同步翻译的最长等待时间, 超时后改为返回任务 ID, 为 0 时为 DefaultSyncTimeout
+	SyncTimeout time.Duration
+}
+
+const (
+	// DefaultMaxUploadSize 是默认的上传文件最大字节数
+	DefaultMaxUploadSize = 50 << 20
+	// DefaultSyncLimit 是默认的同步翻译最大字节数
+	DefaultSyncLimit = 256 << 10
+	// DefaultSyncTimeout 是默认的同步翻译最长等待时间
+	DefaultSyncTimeout = 60 * time.Second
+)
+
+// formatFiles 是各格式的译文的扩展名与 MIME 类型
+var formatFiles = map[string][2]string{
+	"docx": {".docx", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
+	"pptx": {".pptx", "application/vnd.openxmlformats-officedocument.presentationml.presentation"},
+	"xlsx": {".xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+	"odt":  {".odt", "application/vnd.oasis.opendocument.text"},
+	"epub": {".epub", "application/epub+zip"},
+	"html": {".html", "text/html; charset=utf-8"},
+	"vtt":  {".vtt", "text/vtt; charset=utf-8"},
+	"srt":  {".srt", "application/x-subrip; charset=utf-8"},
+	"md":   {".md", "text/markdown; charset=utf-8"},
+	"txt":  {".txt", "text/plain; charset=utf-8"},
+}
+
+// FormatFile 返回格式 format (见 SniffFormat) 的文件扩展名与 MIME 类型, 供在 Handler 之外提供译文下载的接口使用;
+// 未知的格式返回 false
+func FormatFile(format string) (ext, contentType string, ok bool) {
+	f, ok := formatFiles[format]
+	return f[0], f[1], ok
+}
+
+// Handler 返回翻译文件的 HTTP 接口, 供团队将翻译部署为内部服务:
+//
+//	POST /translate?to=...  上传文件并翻译, 返回译文或任务
+//	GET  /jobs/{id}         查看任务的进度与用量
+//	GET  /jobs/{id}/result  下载已完成任务的译文
+//
+// 上传的文件可以是请求体本身, 也可以是 multipart/form-data 中名为 file 的字段; 目标语言取参数或表单字段 to,
+// 格式取 format (为空时自动识别). 不超过 SyncLimit 的文件在 SyncTimeout 内完成时直接返回译文 (200),
+// 否则返回 202 与任务的 JSON (JobInfo), Location 指向任务. 失败的任务返回 422 与错误的 JSON.
+// 接口不做身份验证, 应挂载在只对内开放的地址上, 挂载在子路径下时使用 http.StripPrefix. 管理接口见 AdminHandler.
+func (m *JobManager) Handler(opts ServerOptions) http.Handler {
+	return jobHandler(m.Service(), opts)
+}
+
+// jobHandler 返回 s 的翻译接口
+func jobHandler(s JobService, opts ServerOptions) http.Handler {
+	if opts.MaxUploadSize == 0 {
+		opts.MaxUploadSize = DefaultMaxUploadSize
+	}
+	if opts.SyncLimit == 0 {
+		opts.SyncLimit = DefaultSyncLimit
+	}
+	if opts.SyncTimeout == 0 {
+		opts.SyncTimeout = DefaultSyncTimeout
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case len(parts) == 1 && parts[0] == "translate" && r.Method == http.MethodPost:
+			serveTranslate(s, w, r, opts)
+		case len(parts) == 2 && parts[0] == "jobs" && r.Method == http.MethodGet:
+			info, err := s.Job(parts[1])
+			if err != nil {
+				writeAdminError(w, adminStatus(err), err)
+				return
+			}
+			writeAdminJSON(w, http.StatusOK, info)
+		case len(parts) == 3 && parts[0] == "jobs" && parts[2] == "result" && r.Method == http.MethodGet:
+			serveResult(s, w, parts[1])
+		case len(parts) >= 1 && (parts[0] == "translate" || parts[0] == "jobs") && len(parts) <= 3:
+			writeAdminError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		default:
+			writeAdminError(w, http.StatusNotFound, errors.New("not found"))
+		}
+	})
+}
+
+func serveTranslate(s JobService, w http.ResponseWriter, r *http.Request, opts ServerOptions) {
+	r.Body = http.MaxBytesReader(w, r.Body, opts.MaxUploadSize)
+	var (
+		data []byte
+		name string
+		err  error
+	)
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "multipart/form-data" {
+		data, name, err = readUpload(r)
+	} else {
+		data, err = io.ReadAll(r.Body)
+	}
+	if err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeAdminError(w, status, err)
+		return
+	}
+	to := r.FormValue("to")
+	if to == "" {
+		writeAdminError(w, http.StatusBadRequest, errors.New("missing target language (to)"))
+		return
+	}
+	if len(data) == 0 {
+		writeAdminError(w, http.StatusBadRequest, errors.New("empty file"))
+		return
+	}
+	id, err := s.Submit(data, TranslateFileOptions{TargetLanguage: to, Format: r.FormValue("format")})
+	if err != nil {
+		writeAdminError(w, adminStatus(err), err)
+		return
+	}
+	if opts.SyncLimit > 0 && len(data) <= opts.SyncLimit {
+		ctx, cancel := context.WithTimeout(r.Context(), opts.SyncTimeout)
+		info, err := s.Wait(ctx, id)
+		cancel()
+		if err == nil {
+			writeFinished(s, w, info, name)
+			return
+		}
+	}
+	info, err := s.Job(id)
+	if err != nil {
+		writeAdminError(w, adminStatus(err), err)
+		return
+	}
+	w.Header().Set("Location", "jobs/"+id)
+	writeAdminJSON(w, http.StatusAccepted, info)
+}
+
+// readUpload 读取 multipart 表单中名为 file 的文件与文件名, 表单的其他字段可以通过 r.FormValue 取得
+func readUpload(r *http.Request) ([]byte, string, error) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return nil, "", err
+	}
+	f, header, err := r.FormFile("file")
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	return data, header.Filename, err
+}
+
+// writeFinished 返回已结束的同步任务的译文或错误, name 是上传的文件名
+func writeFinished(s JobService, w http.ResponseWriter, info JobInfo, name string) {
+	if info.State != JobSucceeded {
+		writeAdminJSON(w, http.StatusUnprocessableEntity, info)
+		return
+	}
+	data, err := s.Artifact(info.ID)
+	if err != nil {
+		writeAdminError(w, adminStatus(err), err)
+		return
+	}
+	writeArtifact(w, data, name, info.TargetLanguage)
+}
+
+func serveResult(s JobService, w http.ResponseWriter, id string) {
+	info, err := s.Job(id)
+	if err != nil {
+		writeAdminError(w, adminStatus(err), err)
+		return
+	}
+	switch {
+	case !info.State.finished():
+		writeAdminJSON(w, http.StatusConflict, info)
+		return
+	case info.State != JobSucceeded:
+		writeAdminJSON(w, http.StatusUnprocessableEntity, info)
+		return
+	}
+	data, err := s.Artifact(id)
+	if errors.Is(err, ErrArtifactExpired) {
+		writeAdminError(w, http.StatusGone, err)
+		return
+	}
+	if err != nil {
+		writeAdminError(w, adminStatus(err), err)
+		return
+	}
+	writeArtifact(w, data, "", info.TargetLanguage)
+}
+
+// writeArtifact 写出译文, 文件名为在上传的文件名 (没有时为 translated) 的扩展名之前插入语言代码, 扩展名按译文的格式确定
+func writeArtifact(w http.ResponseWriter, data []byte, name, targetLanguage string) {
+	ext, contentType := path.Ext(name), "application/octet-stream"
+	if e, ct, ok := FormatFile(SniffFormat(data)); ok {
+		ext, contentType = e, ct
+	}
+	base := strings.TrimSuffix(path.Base(strings.ReplaceAll(name, `\`, "/")), path.Ext(name))
+	if base == "" || base == "." || base == "/" {
+		base = "translated"
+	}
+	if code := languageFileCode(targetLanguage); code != "" {
+		base += "." + code
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": base + ext}))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, bytes.NewReader(data))
+}
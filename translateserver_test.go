@@ -0,0 +1,117 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobManagerHandler(t *testing.T) {
+	var doc bytes.Buffer
+	if _, err := newTestDoc("hello", "world").WriteTo(&doc); err != nil {
+		t.Fatal(err)
+	}
+	release := make(chan struct{})
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		if text == "slow" {
+			<-release
+		}
+		return strings.ToUpper(text), nil
+	})
+	m := NewJobManager(NewTranslator("", "").WithProvider(p))
+	srv := httptest.NewServer(m.Handler(ServerOptions{SyncLimit: 1 << 20, SyncTimeout: 200 * time.Millisecond, MaxUploadSize: 1 << 20}))
+	defer srv.Close()
+
+	// 小文件直接返回译文, 文件名取上传的文件名
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("to", "English")
+	fw, _ := mw.CreateFormFile("file", "report.docx")
+	_, _ = fw.Write(doc.Bytes())
+	_ = mw.Close()
+	resp, err := http.Post(srv.URL+"/translate", mw.FormDataContentType(), &body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || SniffFormat(data) != "docx" {
+		t.Fatalf("unexpected response: %s %s", resp.Status, data)
+	}
+	if cd := resp.Header.Get("Content-Disposition"); cd != `attachment; filename=report.en.docx` {
+		t.Fatal("unexpected Content-Disposition:", cd)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.Contains(ct, "wordprocessingml") {
+		t.Fatal("unexpected Content-Type:", ct)
+	}
+
+	// 超时的任务返回任务 ID, 轮询到完成后下载
+	resp, err = http.Post(srv.URL+"/translate?to=English&format=txt", "text/plain", strings.NewReader("slow"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info JobInfo
+	err = json.NewDecoder(resp.Body).Decode(&info)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusAccepted || resp.Header.Get("Location") != "jobs/"+info.ID {
+		t.Fatalf("unexpected response: %s %+v %v", resp.Status, info, err)
+	}
+	resp, err = http.Get(srv.URL + "/jobs/" + info.ID + "/result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatal("unexpected status:", resp.Status)
+	}
+	close(release)
+	for info.State != JobSucceeded {
+		time.Sleep(10 * time.Millisecond)
+		resp, err = http.Get(srv.URL + "/jobs/" + info.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&info)
+		resp.Body.Close()
+		if err != nil || info.State == JobFailed {
+			t.Fatalf("unexpected job: %+v %v", info, err)
+		}
+	}
+	resp, err = http.Get(srv.URL + "/jobs/" + info.ID + "/result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(data)) != "SLOW" || resp.Header.Get("Content-Disposition") != "attachment; filename=translated.en.txt" {
+		t.Fatalf("unexpected result: %s %q %s", resp.Status, data, resp.Header.Get("Content-Disposition"))
+	}
+
+	// 缺少目标语言, 文件过大与不存在的任务
+	for _, c := range []struct {
+		method, url string
+		body        string
+		status      int
+	}{
+		{http.MethodPost, "/translate", "hello", http.StatusBadRequest},
+		{http.MethodPost, "/translate?to=ja", strings.Repeat("a", 2<<20), http.StatusRequestEntityTooLarge},
+		{http.MethodGet, "/jobs/nope", "", http.StatusNotFound},
+		{http.MethodGet, "/translate", "", http.StatusMethodNotAllowed},
+	} {
+		req, _ := http.NewRequest(c.method, srv.URL+c.url, strings.NewReader(c.body))
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != c.status {
+			t.Errorf("%s %s: unexpected status %s", c.method, c.url, resp.Status)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrSegmentsMismatch 导入的表格或翻译单元中的原文与文档不一致, 通常是导出之后文档或翻译设置有变化
+var ErrSegmentsMismatch = errors.New("imported segments do not match the document")
+
+// 导出的表格中的状态
+const (
+	SegmentStatusTranslated = "translated" // SegmentStatusTranslated 翻译成功且自动检查没有发现问题
+	SegmentStatusWarning    = "warning"    // SegmentStatusWarning 自动检查发现了问题, 见 SegmentReport.Warnings
+	SegmentStatusFlagged    = "flagged"    // SegmentStatusFlagged 回译的相似度低于阈值, 见 WithBackTranslation
+	SegmentStatusFailed     = "failed"     // SegmentStatusFailed 翻译失败, 译文为原文
+)
+
+// segmentSheetHeader 是导出的表格的表头, ImportSegments 按表头中的 id, source 与 target 查找列
+var segmentSheetHeader = []string{"id", "source", "target", "status", "notes"}
+
+// segmentStatus 返回翻译单元 sr 的状态 (SegmentStatusTranslated 等) 与说明: 失败的原因与自动检查发现的问题
+func segmentStatus(sr *SegmentReport) (string, []string) {
+	status := SegmentStatusTranslated
+	var notes []string
+	switch {
+	case sr.Err != nil:
+		status = SegmentStatusFailed
+		notes = append(notes, sr.Err.Error())
+	case sr.Flagged:
+		status = SegmentStatusFlagged
+		notes = append(notes, "back translation: "+sr.BackTranslation)
+	case len(sr.Warnings) > 0:
+		status = SegmentStatusWarning
+	}
+	for _, w := range sr.Warnings {
+		notes = append(notes, w.Message)
+	}
+	return status, notes
+}
+
+// segmentRows 返回报告 r 的表格行 (不含表头), id 与 ExportXLIFF 相同
+func segmentRows(r *Report) [][]string {
+	rows := make([][]string, 0, len(r.Segments))
+	for i := range r.Segments {
+		sr := &r.Segments[i]
+		status, notes := segmentStatus(sr)
+		rows = append(rows, []string{segmentID(sr.Index), sr.Source, sr.Target, status, strings.Join(notes, "; ")})
+	}
+	return rows
+}
+
+// WriteReportCSV 以 id,source,target,status,notes 的格式写出 TranslateDocxWithReport 的报告, 供在电子表格中人工检查;
+// 修改 target 列后用 ImportSegments 导回. status 是 SegmentStatusTranslated 等, notes 是失败的原因与自动检查发现的问题
+func WriteReportCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(segmentSheetHeader)
+	if err != nil {
+		return err
+	}
+	for _, row := range segmentRows(r) {
+		if err = cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteReportXLSX 同 WriteReportCSV, 但写为只有一个工作表的 xlsx 工作簿
+func WriteReportXLSX(w io.Writer, r *Report) error {
+	var sheet bytes.Buffer
+	sheet.WriteString(xml.Header)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<cols><col min="1" max="1" width="8" customWidth="1"/><col min="2" max="3" width="60" customWidth="1"/>` +
+		`<col min="4" max="5" width="16" customWidth="1"/></cols><sheetData>`)
+	for i, row := range append([][]string{segmentSheetHeader}, segmentRows(r)...) {
+		n := strconv.Itoa(i + 1)
+		sheet.WriteString(`<row r="` + n + `">`)
+		for j, cell := range row {
+			sheet.WriteString(`<c r="` + string(rune('A'+j)) + n + `" t="inlineStr"><is><t xml:space="preserve">`)
+			sheet.Write(escapeXMLText(cell))
+			sheet.WriteString(`</t></is></c>`)
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	zw := zip.NewWriter(w)
+	for _, f := range []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="` + XMLNS_REL + `">` +
+			`<Relationship Id="rId1" Type="` + XMLNS_R + `/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="` + XMLNS_R + `">` +
+			`<sheets><sheet name="Segments" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="` + XMLNS_REL + `">` +
+			`<Relationship Id="rId1" Type="` + XMLNS_R + `/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	} {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// ImportSegments 读取 WriteReportCSV 或 WriteReportXLSX 写出并经过人工修改的表格 (按内容识别格式),
+// 将 target 列的译文按 id 填入 doc 的副本, 返回译为 targetLanguage 的新文档
+//
+// 按表头查找 id, source 与 target 列, 其他列与列的顺序不影响导入. source 与文档不一致时返回包装了 ErrSegmentsMismatch 的错误;
+// 表格中没有的翻译单元与 target 为空的翻译单元保留原文. 与 ImportXLIFF 一样, 译文按原样写入, 不请求翻译服务.
+func (t *Translator) ImportSegments(doc *Docx, r io.Reader, targetLanguage string) (*Docx, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]string
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		rows, err = readXLSXRows(data)
+	} else {
+		cr := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\ufeff"))))
+		cr.FieldsPerRecord = -1
+		rows, err = cr.ReadAll()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: empty sheet", ErrSegmentsMismatch)
+	}
+	col := map[string]int{"id": -1, "source": -1, "target": -1}
+	for i, name := range rows[0] {
+		if _, ok := col[strings.ToLower(strings.TrimSpace(name))]; ok {
+			col[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+	}
+	for name, i := range col {
+		if i < 0 {
+			return nil, fmt.Errorf("%w: no %s column", ErrSegmentsMismatch, name)
+		}
+	}
+	cell := func(row []string, name string) string {
+		if i := col[name]; i < len(row) {
+			return strings.ReplaceAll(row[i], "\r\n", "\n")
+		}
+		return ""
+	}
+	units := make(map[string]*importUnit, len(rows)-1)
+	for _, row := range rows[1:] {
+		id := strings.TrimSpace(cell(row, "id"))
+		if id == "" {
+			continue
+		}
+		u := &importUnit{source: cell(row, "source"), target: cell(row, "target")}
+		u.translated = strings.TrimSpace(u.target) != ""
+		units[id] = u
+	}
+	return t.fillImported(doc, targetLanguage, units, false, ErrSegmentsMismatch)
+}
+
+// sheetCell 是工作表中的一个单元格, 只读取文字
+type sheetCell struct {
+	Ref    string        `xml:"r,attr"`
+	Type   string        `xml:"t,attr"`
+	Value  string        `xml:"v"`
+	Inline sheetRichText `xml:"is"`
+}
+
+// sheetRichText 是共享字符串或内联字符串, 带格式的字符串由多段文字组成
+type sheetRichText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s *sheetRichText) String() string {
+	text := s.Text
+	for _, r := range s.Runs {
+		text += r.Text
+	}
+	return text
+}
+
+// readXLSXRows 读取 xlsx 工作簿 data 中第一个工作表的文字, 空单元格为空串
+func readXLSXRows(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	var sheetFile, sharedFile *zip.File
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "xl/sharedStrings.xml":
+			sharedFile = f
+		case path.Dir(f.Name) == "xl/worksheets" && path.Ext(f.Name) == ".xml" && (sheetFile == nil || f.Name < sheetFile.Name):
+			sheetFile = f
+		}
+	}
+	if sheetFile == nil {
+		return nil, fmt.Errorf("%w: no worksheet", ErrSegmentsMismatch)
+	}
+	var shared []string
+	if sharedFile != nil {
+		content, err := readZipFile(sharedFile)
+		if err != nil {
+			return nil, err
+		}
+		var sst struct {
+			Items []sheetRichText `xml:"si"`
+		}
+		if err = xml.Unmarshal(content, &sst); err != nil {
+			return nil, err
+		}
+		for i := range sst.Items {
+			shared = append(shared, sst.Items[i].String())
+		}
+	}
+	content, err := readZipFile(sheetFile)
+	if err != nil {
+		return nil, err
+	}
+	var sheet struct {
+		Rows []struct {
+			Cells []sheetCell `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err = xml.Unmarshal(content, &sheet); err != nil {
+		return nil, err
+	}
+	rows := make([][]string, len(sheet.Rows))
+	for i, row := range sheet.Rows {
+		for j, c := range row.Cells {
+			col := j
+			if letters := strings.TrimRight(c.Ref, "0123456789"); letters != "" {
+				col = 0
+				for _, l := range strings.ToUpper(letters) {
+					col = col*26 + int(l-'A'+1)
+				}
+				col--
+			}
+			text := c.Value
+			switch c.Type {
+			case "s":
+				if n, err := strconv.Atoi(c.Value); err == nil && n >= 0 && n < len(shared) {
+					text = shared[n]
+				}
+			case "inlineStr":
+				text = c.Inline.String()
+			}
+			for len(rows[i]) <= col {
+				rows[i] = append(rows[i], "")
+			}
+			rows[i][col] = text
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,68 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReportSheetRoundTrip(t *testing.T) {
+	doc := newTestDoc("hello", "FAIL here", "world")
+	tr := NewTranslator("", "").WithErrorPolicy(ErrorPolicyKeepOriginal).WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		if strings.HasPrefix(text, "FAIL") {
+			return "", errors.New("quota exceeded")
+		}
+		return strings.ToUpper(text), nil
+	}))
+	_, report, err := tr.TranslateDocxWithReport(context.Background(), doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var csvBuf bytes.Buffer
+	if err = WriteReportCSV(&csvBuf, report); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(csvBuf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || strings.Join(rows[1][:4], ",") != "u1,hello,HELLO,translated" || rows[2][3] != SegmentStatusFailed || rows[2][4] != "quota exceeded" {
+		t.Fatalf("unexpected rows: %q", rows)
+	}
+
+	// 审校者修改失败的译文, 删除最后一行并调换列的顺序
+	edited := "target,id,source\nHI,u1,hello\nFIXED here,u2,FAIL here\n"
+	newDoc, err := tr.ImportSegments(doc, strings.NewReader(edited), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"HI", "FIXED here", "world"} {
+		if s := newDoc.Document.Body.Items[i].(*Paragraph).String(); s != want {
+			t.Errorf("paragraph %d: got %q, want %q", i, s, want)
+		}
+	}
+	if _, err = tr.ImportSegments(doc, strings.NewReader("id,source,target\nu1,changed,HI\n"), "English"); !errors.Is(err, ErrSegmentsMismatch) {
+		t.Fatal("expected ErrSegmentsMismatch, got", err)
+	}
+
+	var xlsx bytes.Buffer
+	if err = WriteReportXLSX(&xlsx, report); err != nil {
+		t.Fatal(err)
+	}
+	if SniffFormat(xlsx.Bytes()) != "xlsx" {
+		t.Fatal("the workbook is not recognized as xlsx")
+	}
+	newDoc, err = tr.ImportSegments(doc, bytes.NewReader(xlsx.Bytes()), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"HELLO", "FAIL here", "WORLD"} {
+		if s := newDoc.Document.Body.Items[i].(*Paragraph).String(); s != want {
+			t.Errorf("paragraph %d: got %q, want %q", i, s, want)
+		}
+	}
+}
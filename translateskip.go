@@ -0,0 +1,61 @@
+package docx
+
+import (
+	"regexp"
+	"strings"
+)
+
+// DefaultSkipPatterns 匹配不需要翻译的段落: 只有数字与符号的段落 (如 "1.2.3"、"2024-03-05"、"(12)"),
+// 以及只有一个编号或代码的段落 (如 "ISO-9001"、"A1/B2"、"SKU_2024")
+var DefaultSkipPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`^[\d\s.,:;/\\\-–—()\[\]%+#*=<>]+$`),
+	regexp.MustCompile(`^[A-Z0-9][A-Z0-9_\-./]*\d[A-Z0-9_\-./]*$`),
+}
+
+// WithSkipPatterns 设置不翻译的段落: 段落的文字 (不含首尾空白) 匹配其中任意一个正则表达式时原样复制到新文档,
+// 如 regexp.MustCompile(`^Figure \d+`); 可以与 DefaultSkipPatterns 拼接使用. 默认翻译所有段落, 不传参数以关闭
+//
+// 被跳过的段落中图片的替代文字同样不翻译, 双语输出中不重复原文.
+func (t *Translator) WithSkipPatterns(patterns ...*regexp.Regexp) *Translator {
+	t.skipPatterns = patterns
+	return t
+}
+
+// skipText 判断段落的文字 text 是否匹配 WithSkipPatterns 设置的正则表达式
+func (t *Translator) skipText(text string) bool {
+	if len(t.skipPatterns) == 0 {
+		return false
+	}
+	text = strings.TrimSpace(inlineTagRe.ReplaceAllString(text, ""))
+	for _, re := range t.skipPatterns {
+		if re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithSkipHidden 设置不翻译隐藏的文字 (带 w:vanish 格式的 Run): 隐藏的 Run 原样保留在原来的位置, 不发送给翻译服务,
+// 只有隐藏文字的段落原样复制; TranslateDocxInPlace 中译文只放入可见的 Run. 默认与其他文字一样翻译
+//
+// 删除修订 (w:del) 中的文字本来就不会翻译: RevisionPolicyAccept 下丢弃, RevisionPolicyPreserve 下原样保留.
+// 含有隐藏文字的段落不按 WithRunLanguageRouting 拆分.
+func (t *Translator) WithSkipHidden(skip bool) *Translator {
+	t.skipHidden = skip
+	return t
+}
+
+// isHidden 判断 Run 是否带有隐藏格式
+func isHidden(r *Run) bool {
+	return r.RunProperties != nil && r.RunProperties.Vanish != nil
+}
+
+// hasHidden 判断段落中是否有隐藏的 Run
+func hasHidden(p *Paragraph) bool {
+	for _, r := range paragraphRuns(p) {
+		if isHidden(r) {
+			return true
+		}
+	}
+	return false
+}
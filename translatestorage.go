@@ -0,0 +1,221 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ObjectStore 是对象存储, 供 OpenURI, CreateURI 与 TranslateURI 读写 scheme://bucket/key 形式的地址, 实现必须可以并发调用
+type ObjectStore interface {
+	// Get 读取对象, 不存在时返回包装了 fs.ErrNotExist 的错误
+	Get(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	// Put 写入 size 字节的对象 r; size 小于 0 时长度未知, 读取 r 直到 EOF, r 返回其他错误时放弃写入
+	Put(ctx context.Context, bucket, key string, r io.Reader, size int64) error
+}
+
+var (
+	objectStoresMu sync.RWMutex
+	// objectStores 按地址的 scheme 索引, 内置的实现在使用时从环境变量读取凭证
+	objectStores = map[string]ObjectStore{
+		"s3":  &S3Store{},
+		"oss": &OSSStore{},
+		"gs":  &GCSStore{},
+	}
+)
+
+// RegisterObjectStore 设置 scheme (如 "s3") 的地址使用的对象存储, 替换内置的实现, 如改用官方 SDK 或自定义凭证
+func RegisterObjectStore(scheme string, s ObjectStore) {
+	objectStoresMu.Lock()
+	defer objectStoresMu.Unlock()
+	objectStores[scheme] = s
+}
+
+// objectURI 解析 s3://bucket/key 形式的地址; 不是对象存储的地址 (本地路径) 时返回 nil
+func objectURI(uri string) (ObjectStore, string, string, error) {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		return nil, "", "", nil
+	}
+	objectStoresMu.RLock()
+	s := objectStores[scheme]
+	objectStoresMu.RUnlock()
+	if s == nil {
+		return nil, "", "", fmt.Errorf("unsupported storage scheme %q", scheme)
+	}
+	bucket, key, _ := strings.Cut(rest, "/")
+	if bucket == "" || key == "" {
+		return nil, "", "", fmt.Errorf("invalid object URI %q, expected %s://bucket/key", uri, scheme)
+	}
+	return s, bucket, key, nil
+}
+
+// OpenURI 打开本地文件或 s3://, oss://, gs:// 等对象存储中的对象, 对象直接从存储流式读取
+func OpenURI(ctx context.Context, uri string) (io.ReadCloser, error) {
+	s, bucket, key, err := objectURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return os.Open(uri)
+	}
+	return s.Get(ctx, bucket, key)
+}
+
+// CreateURI 创建本地文件或对象存储中的对象; 对象的内容边写边上传 (ObjectStore.Put 的长度未知),
+// Close 等待上传完成, 上传失败时 Write 或 Close 返回错误
+func CreateURI(ctx context.Context, uri string) (io.WriteCloser, error) {
+	s, bucket, key, err := objectURI(uri)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return os.Create(uri)
+	}
+	return &objectWriter{ctx: ctx, s: s, bucket: bucket, key: key}, nil
+}
+
+// objectWriter 在第一次写入时开始上传对象, 写入的内容经管道交给 ObjectStore.Put
+type objectWriter struct {
+	ctx         context.Context
+	s           ObjectStore
+	bucket, key string
+	pw          *io.PipeWriter
+	done        chan error
+}
+
+func (w *objectWriter) Write(p []byte) (int, error) {
+	if w.pw == nil {
+		pr, pw := io.Pipe()
+		w.pw, w.done = pw, make(chan error, 1)
+		go func() {
+			err := w.s.Put(w.ctx, w.bucket, w.key, pr, -1)
+			// Put 提前返回时使之后的 Write 出错, 而不是阻塞
+			pr.CloseWithError(err)
+			w.done <- err
+		}()
+	}
+	return w.pw.Write(p)
+}
+
+func (w *objectWriter) Close() error {
+	if w.pw == nil {
+		return w.s.Put(w.ctx, w.bucket, w.key, bytes.NewReader(nil), 0)
+	}
+	_ = w.pw.Close()
+	return <-w.done
+}
+
+// TranslateURI 同 TranslateFile, 但读取 in 并将译文写入 out, 两者都可以是本地路径或对象存储的地址 (见 OpenURI), 不需要本地磁盘;
+// 译文直接写入 out, 不在内存中另存一份. 翻译失败且没有译文时不创建 out, 翻译出错但有译文时 (ErrorPolicyCollect) 仍写入 out 并返回该错误
+func (t *Translator) TranslateURI(ctx context.Context, in, out string, opts TranslateFileOptions) error {
+	r, err := OpenURI(ctx, in)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return t.TranslateToURI(ctx, r, out, opts)
+}
+
+// TranslateToURI 同 TranslateURI, 但原文从 r 读取
+func (t *Translator) TranslateToURI(ctx context.Context, r io.Reader, out string, opts TranslateFileOptions) error {
+	w := &lazyURIWriter{ctx: ctx, uri: out}
+	err := t.TranslateFile(ctx, r, w, opts)
+	if w.w == nil && err == nil {
+		// 译文为空时也创建 out
+		_, err = w.Write(nil)
+	}
+	switch {
+	case w.err != nil:
+		return w.err
+	case w.w == nil:
+		return err
+	}
+	if cerr := w.w.Close(); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// lazyURIWriter 在第一次写入时才以 CreateURI 创建 uri, 使没有译文的翻译不留下空文件
+type lazyURIWriter struct {
+	ctx context.Context
+	uri string
+	w   io.WriteCloser
+	err error // err 是创建 uri 的错误
+}
+
+func (l *lazyURIWriter) Write(p []byte) (int, error) {
+	if l.w == nil {
+		if l.err == nil {
+			l.w, l.err = CreateURI(l.ctx, l.uri)
+		}
+		if l.err != nil {
+			return 0, l.err
+		}
+	}
+	return l.w.Write(p)
+}
+
+// doObject 发送对象存储的请求, 非 2xx 的响应转为错误, 404 包装 fs.ErrNotExist; 返回的响应由调用方关闭
+func doObject(client *http.Client, req *http.Request) (*http.Response, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("%s %s: %s: %s", req.Method, redactURL(req.URL), resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode == http.StatusNotFound {
+		err = fmt.Errorf("%w: %v", fs.ErrNotExist, err)
+	}
+	return nil, err
+}
+
+// redactURL 返回去掉查询参数的 u, 避免在错误中泄露签名
+func redactURL(u *url.URL) string {
+	c := *u
+	c.RawQuery = ""
+	return c.String()
+}
+
+// escapeObjectKey 按 RFC 3986 转义对象的键, 保留 /
+func escapeObjectKey(key string) string {
+	var sb strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// firstEnv 返回 names 中第一个非空的环境变量
+func firstEnv(names ...string) string {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// errNoObjectCredentials 没有找到对象存储的凭证
+var errNoObjectCredentials = errors.New("no object storage credentials")
@@ -0,0 +1,113 @@
+package docx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GCSStore 是 Google Cloud Storage 的 ObjectStore, 通过 XML API 以 OAuth 2.0 访问令牌读写, 用于 gs:// 地址
+//
+// 令牌依次取自 Token, 环境变量 GOOGLE_OAUTH_ACCESS_TOKEN (如 gcloud auth print-access-token 的输出)
+// 与 GCE, GKE, Cloud Run 等环境的元数据服务器. 服务账号密钥文件需由调用方换取令牌后通过 Token 提供.
+type GCSStore struct {
+	// Endpoint 为空时为 https://storage.googleapis.com
+	Endpoint string
+	// Token 返回访问令牌, 为 nil 时使用环境变量或元数据服务器
+	Token func(ctx context.Context) (string, error)
+	// Client 为 nil 时使用 http.DefaultClient
+	Client *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// Get 实现 ObjectStore
+func (s *GCSStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, bucket, key, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doObject(s.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Put 实现 ObjectStore
+func (s *GCSStore) Put(ctx context.Context, bucket, key string, r io.Reader, size int64) error {
+	req, err := s.request(ctx, http.MethodPut, bucket, key, r, size)
+	if err != nil {
+		return err
+	}
+	resp, err := doObject(s.Client, req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (s *GCSStore) request(ctx context.Context, method, bucket, key string, body io.Reader, size int64) (*http.Request, error) {
+	token, err := s.accessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = "https://storage.googleapis.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(endpoint, "/")+"/"+bucket+"/"+escapeObjectKey(key), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req, nil
+}
+
+// gcsMetadataTokenURL 是元数据服务器中默认服务账号的令牌地址
+const gcsMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// accessToken 返回访问令牌, 元数据服务器的令牌在过期前一分钟内重新获取
+func (s *GCSStore) accessToken(ctx context.Context) (string, error) {
+	if s.Token != nil {
+		return s.Token(ctx)
+	}
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Until(s.expires) > time.Minute {
+		return s.token, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcsMetadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := (&http.Client{Timeout: 5 * time.Second}).Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w for gs:// (set GOOGLE_OAUTH_ACCESS_TOKEN): %v", errNoObjectCredentials, err)
+	}
+	defer resp.Body.Close()
+	var t struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil || t.AccessToken == "" {
+		return "", fmt.Errorf("%w for gs:// from the metadata server: %s", errNoObjectCredentials, resp.Status)
+	}
+	s.token, s.expires = t.AccessToken, time.Now().Add(time.Duration(t.ExpiresIn)*time.Second)
+	return s.token, nil
+}
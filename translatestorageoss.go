@@ -0,0 +1,108 @@
+package docx
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OSSStore 是阿里云对象存储 OSS 的 ObjectStore, 以 OSS 的 V1 签名访问, 用于 oss:// 地址
+//
+// 为空的字段在每次请求时从环境变量读取: OSS_ACCESS_KEY_ID, OSS_ACCESS_KEY_SECRET, OSS_SESSION_TOKEN
+// (或对应的 ALIBABA_CLOUD_ACCESS_KEY_ID, ALIBABA_CLOUD_ACCESS_KEY_SECRET, ALIBABA_CLOUD_SECURITY_TOKEN) 与 OSS_ENDPOINT.
+// 没有凭证时发送匿名请求, 只能访问公共读写的存储空间.
+type OSSStore struct {
+	// Endpoint 是地域的访问域名, 如 oss-cn-hangzhou.aliyuncs.com (默认), 可以带 http:// 或 https:// (默认)
+	Endpoint        string
+	AccessKeyID     string
+	AccessKeySecret string
+	SecurityToken   string
+	// Client 为 nil 时使用 http.DefaultClient
+	Client *http.Client
+}
+
+// Get 实现 ObjectStore
+func (s *OSSStore) Get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, bucket, key, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doObject(s.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Put 实现 ObjectStore
+func (s *OSSStore) Put(ctx context.Context, bucket, key string, r io.Reader, size int64) error {
+	req, err := s.request(ctx, http.MethodPut, bucket, key, r, size)
+	if err != nil {
+		return err
+	}
+	resp, err := doObject(s.Client, req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func (s *OSSStore) request(ctx context.Context, method, bucket, key string, body io.Reader, size int64) (*http.Request, error) {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("OSS_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = "oss-cn-hangzhou.aliyuncs.com"
+	}
+	scheme, host, ok := strings.Cut(endpoint, "://")
+	if !ok {
+		scheme, host = "https", endpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, method, scheme+"://"+bucket+"."+strings.TrimSuffix(host, "/")+"/"+escapeObjectKey(key), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	accessKey, secret, token := s.AccessKeyID, s.AccessKeySecret, s.SecurityToken
+	if accessKey == "" {
+		accessKey = firstEnv("OSS_ACCESS_KEY_ID", "ALIBABA_CLOUD_ACCESS_KEY_ID")
+		secret = firstEnv("OSS_ACCESS_KEY_SECRET", "ALIBABA_CLOUD_ACCESS_KEY_SECRET")
+		token = firstEnv("OSS_SESSION_TOKEN", "ALIBABA_CLOUD_SECURITY_TOKEN")
+	}
+	if accessKey == "" {
+		return req, nil
+	}
+	if token != "" {
+		req.Header.Set("X-Oss-Security-Token", token)
+	}
+	req.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	signOSS(req, accessKey, secret, "/"+bucket+"/"+key)
+	return req, nil
+}
+
+// signOSS 以 OSS 的 V1 签名 req, resource 是 /bucket/key 形式的 CanonicalizedResource (键不转义)
+func signOSS(req *http.Request, accessKey, secret, resource string) {
+	var ossHeaders []string
+	for name, values := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-oss-") {
+			ossHeaders = append(ossHeaders, lower+":"+strings.TrimSpace(strings.Join(values, ","))+"\n")
+		}
+	}
+	sort.Strings(ossHeaders)
+	stringToSign := req.Method + "\n" + req.Header.Get("Content-MD5") + "\n" + req.Header.Get("Content-Type") + "\n" +
+		req.Header.Get("Date") + "\n" + strings.Join(ossHeaders, "") + resource
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write([]byte(stringToSign))
+	req.Header.Set("Authorization", "OSS "+accessKey+":"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+}
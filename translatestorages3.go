@@ -0,0 +1,266 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Store 是 Amazon S3 (以及 MinIO 等兼容 S3 的服务) 的 ObjectStore, 以 AWS Signature Version 4 签名, 用于 s3:// 地址
+//
+// 为空的字段在每次请求时从环境变量读取: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN,
+// AWS_REGION (或 AWS_DEFAULT_REGION, 默认 us-east-1) 与 AWS_ENDPOINT_URL_S3 (或 AWS_ENDPOINT_URL).
+// 没有凭证时发送匿名请求, 只能访问公开的存储桶.
+type S3Store struct {
+	// Endpoint 是兼容 S3 的服务的地址 (如 http://localhost:9000), 设置后使用路径形式的地址; 为空时为 AWS 的地址
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Client 为 nil 时使用 http.DefaultClient
+	Client *http.Client
+}
+
+// s3PartSize 是长度未知的对象分片上传时每个分片的字节数, S3 要求除最后一个分片外不小于 5 MiB
+var s3PartSize = 8 << 20
+
+// Get 实现 ObjectStore
+func (s *S3Store) Get(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, bucket, key, "", nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doObject(s.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Put 实现 ObjectStore; 长度未知时按 s3PartSize 分片上传 (不超过一个分片时直接上传), 内存中最多保存一个分片
+func (s *S3Store) Put(ctx context.Context, bucket, key string, r io.Reader, size int64) error {
+	if size >= 0 {
+		_, err := s.do(ctx, http.MethodPut, bucket, key, "", r, size, nil)
+		return err
+	}
+	part := make([]byte, s3PartSize)
+	n, err := io.ReadFull(r, part)
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		_, err = s.do(ctx, http.MethodPut, bucket, key, "", bytes.NewReader(part[:n]), int64(n), nil)
+		return err
+	case err != nil:
+		return err
+	}
+	return s.putMultipart(ctx, bucket, key, part, r)
+}
+
+// s3CompleteUpload 是完成分片上传的请求体
+type s3CompleteUpload struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []s3Part `xml:"Part"`
+}
+
+type s3Part struct {
+	PartNumber int
+	ETag       string
+}
+
+// putMultipart 以分片上传写入对象, part 是已读出的第一个分片, 之后的分片从 r 读取; 失败时放弃上传, 删除已上传的分片
+func (s *S3Store) putMultipart(ctx context.Context, bucket, key string, part []byte, r io.Reader) (err error) {
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if _, err = s.do(ctx, http.MethodPost, bucket, key, "uploads", nil, 0, &initiated); err != nil {
+		return err
+	}
+	upload := "uploadId=" + url.QueryEscape(initiated.UploadID)
+	defer func() {
+		if err != nil {
+			_, _ = s.do(context.Background(), http.MethodDelete, bucket, key, upload, nil, 0, nil)
+		}
+	}()
+	var complete s3CompleteUpload
+	for n, number := len(part), 1; n > 0; number++ {
+		header, err := s.do(ctx, http.MethodPut, bucket, key, fmt.Sprintf("partNumber=%d&%s", number, upload), bytes.NewReader(part[:n]), int64(n), nil)
+		if err != nil {
+			return err
+		}
+		complete.Parts = append(complete.Parts, s3Part{PartNumber: number, ETag: header.Get("ETag")})
+		if n, err = io.ReadFull(r, part); err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+	}
+	body, err := xml.Marshal(complete)
+	if err != nil {
+		return err
+	}
+	// 完成分片上传的请求出错时也可能返回 200, 错误在响应体中
+	var result struct {
+		XMLName xml.Name
+		Code    string
+		Message string
+	}
+	if _, err = s.do(ctx, http.MethodPost, bucket, key, upload, bytes.NewReader(body), int64(len(body)), &result); err != nil {
+		return err
+	}
+	if result.XMLName.Local == "Error" {
+		return fmt.Errorf("complete multipart upload of %s/%s: %s: %s", bucket, key, result.Code, result.Message)
+	}
+	return nil
+}
+
+// do 发送请求并关闭响应, result 不为 nil 时将 XML 响应体解析到 result, 返回响应头
+func (s *S3Store) do(ctx context.Context, method, bucket, key, query string, body io.Reader, size int64, result interface{}) (http.Header, error) {
+	req, err := s.request(ctx, method, bucket, key, query, body, size)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doObject(s.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if result != nil {
+		if err = xml.NewDecoder(resp.Body).Decode(result); err != nil {
+			return nil, fmt.Errorf("%s %s: %w", method, redactURL(req.URL), err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// request 创建已签名的请求, query 是已转义的查询参数
+func (s *S3Store) request(ctx context.Context, method, bucket, key, query string, body io.Reader, size int64) (*http.Request, error) {
+	endpoint := s.Endpoint
+	if endpoint == "" {
+		endpoint = firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL")
+	}
+	region := s.Region
+	if region == "" {
+		region = firstEnv("AWS_REGION", "AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	var u string
+	switch {
+	case endpoint != "":
+		u = strings.TrimSuffix(endpoint, "/") + "/" + bucket + "/" + escapeObjectKey(key)
+	case strings.Contains(bucket, "."):
+		// 含 . 的存储桶名与通配符证书不匹配, 使用路径形式
+		u = "https://s3." + region + ".amazonaws.com/" + bucket + "/" + escapeObjectKey(key)
+	default:
+		u = "https://" + bucket + ".s3." + region + ".amazonaws.com/" + escapeObjectKey(key)
+	}
+	if query != "" {
+		u += "?" + query
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	accessKey, secret := s.AccessKeyID, s.SecretAccessKey
+	if accessKey == "" {
+		accessKey, secret = os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if accessKey == "" {
+		return req, nil
+	}
+	token := s.SessionToken
+	if token == "" && s.AccessKeyID == "" {
+		token = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	if token != "" {
+		req.Header.Set("X-Amz-Security-Token", token)
+	}
+	signV4(req, accessKey, secret, region, "s3", time.Now())
+	return req, nil
+}
+
+// signV4 以 AWS Signature Version 4 签名 req: 签名查询参数, Host, Range, Content-Type, Content-MD5 与所有 X-Amz- 开头的头,
+// 请求体的摘要取自 X-Amz-Content-Sha256 头
+func signV4(req *http.Request, accessKey, secret, region, service string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "range" || lower == "content-type" || lower == "content-md5" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonical strings.Builder
+	canonical.WriteString(req.Method + "\n" + req.URL.EscapedPath() + "\n" + canonicalQuery(req.URL.Query()) + "\n")
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonical.WriteString("\n" + signedHeaders + "\n" + req.Header.Get("X-Amz-Content-Sha256"))
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	digest := sha256.Sum256([]byte(canonical.String()))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(digest[:])
+	key := []byte("AWS4" + secret)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+hex.EncodeToString(hmacSHA256(key, stringToSign)))
+}
+
+// canonicalQuery 返回签名用的查询参数: 名称与值按 RFC 3986 转义, 按名称再按值排序
+func canonicalQuery(values url.Values) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, k int) bool { return escapeQueryValue(names[i]) < escapeQueryValue(names[k]) })
+	var pairs []string
+	for _, name := range names {
+		vs := make([]string, len(values[name]))
+		for i, v := range values[name] {
+			vs[i] = escapeQueryValue(v)
+		}
+		sort.Strings(vs)
+		for _, v := range vs {
+			pairs = append(pairs, escapeQueryValue(name)+"="+v)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escapeQueryValue 按 RFC 3986 转义查询参数的名称或值
+func escapeQueryValue(v string) string {
+	return strings.ReplaceAll(escapeObjectKey(v), "/", "%2F")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
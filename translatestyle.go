@@ -0,0 +1,99 @@
+package docx
+
+import "strings"
+
+// StyleHinter 根据段落样式 ID (如 "Heading1", "Quote") 返回注入提示词的翻译要求,
+// 返回空串表示没有额外要求
+type StyleHinter func(styleID string) string
+
+const (
+	styleHintHeading = "这段文字是文档中的标题: 译文要简洁, 遵循目标语言标题的大小写习惯 (如英文标题每个实词首字母大写), 不要添加句末标点."
+	styleHintQuote   = "这段文字是一段引文: 保留原文的语体与语气, 直接翻译引文本身, 不要改写为转述."
+	styleHintCaption = "这段文字是图片或表格的题注: 保留编号标记的位置, 按目标语言的习惯翻译题注的标签 (如英文的 Figure 与 Table)."
+)
+
+// DefaultStyleHints 为标题 (Title, Subtitle, Heading1-9 以及中文 Word 中的样式 1-9)、
+// 引文 (Quote, IntenseQuote) 与题注 (Caption) 样式返回对应的翻译要求
+func DefaultStyleHints(styleID string) string {
+	switch {
+	case isHeadingStyle(styleID):
+		return styleHintHeading
+	case strings.HasSuffix(strings.ToLower(styleID), "quote"):
+		return styleHintQuote
+	case strings.EqualFold(styleID, "Caption"):
+		return styleHintCaption
+	default:
+		return ""
+	}
+}
+
+// WithStyleHints 设置段落样式到翻译要求的映射, 默认为 DefaultStyleHints;
+// 传入 nil 以关闭
+//
+// 翻译要求会附加在 Dashscope 的系统提示词之后, 或交给实现了 HintedProvider 的 Provider.
+func (t *Translator) WithStyleHints(h StyleHinter) *Translator {
+	t.styleHints = h
+	t.noStyleHints = h == nil
+	return t
+}
+
+// styleHint 返回段落 p 的样式对应的翻译要求
+func (t *Translator) styleHint(p *Paragraph) string {
+	if t.noStyleHints || p.Properties == nil || p.Properties.Style == nil {
+		return ""
+	}
+	h := t.styleHints
+	if h == nil {
+		h = DefaultStyleHints
+	}
+	return h(p.Properties.Style.Val)
+}
+
+// StyleFilter 根据段落样式 ID 判断是否翻译段落, 没有设置样式的段落 (使用默认段落样式) 传入空串
+type StyleFilter func(styleID string) bool
+
+// IncludeStyles 返回只翻译 styleIDs 中样式的 StyleFilter, 样式 ID 不区分大小写;
+// 空串表示没有设置样式的段落, 如 IncludeStyles("", "BodyText") 只翻译正文
+func IncludeStyles(styleIDs ...string) StyleFilter {
+	return func(styleID string) bool {
+		return containsFold(styleIDs, styleID)
+	}
+}
+
+// ExcludeStyles 返回不翻译 styleIDs 中样式的 StyleFilter, 如 ExcludeStyles("Code", "Quote"), 样式 ID 不区分大小写
+func ExcludeStyles(styleIDs ...string) StyleFilter {
+	return func(styleID string) bool {
+		return !containsFold(styleIDs, styleID)
+	}
+}
+
+// containsFold 判断 ids 中是否有不区分大小写时与 id 相同的字符串
+func containsFold(ids []string, id string) bool {
+	for _, s := range ids {
+		if strings.EqualFold(s, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithStyleFilter 设置按段落样式选择翻译的段落, 默认翻译所有段落; 传入 nil 以关闭
+//
+// 不翻译的段落连同其中的图片原样保留 (图片的替代文字也不翻译), 双语输出中不重复原文;
+// 表格与内容控件中的段落同样按样式选择, 目录段落的样式 (如 TOC1) 也会被检查.
+func (t *Translator) WithStyleFilter(f StyleFilter) *Translator {
+	t.styleFilter = f
+	return t
+}
+
+// skipStyle 判断是否因 WithStyleFilter 不翻译段落 p
+func (t *Translator) skipStyle(p *Paragraph) bool {
+	if t.styleFilter == nil {
+		return false
+	}
+	var styleID string
+	if p.Properties != nil && p.Properties.Style != nil {
+		styleID = p.Properties.Style.Val
+	}
+	return !t.styleFilter(styleID)
+}
@@ -0,0 +1,295 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// subtitleHint 是字幕附加的翻译要求
+	subtitleHint = "这段文字是视频字幕: 译文要简洁口语化, 长度尽量不超过原文, 不要换行."
+	// subtitleDialogueHint 是多人对白的字幕附加的翻译要求
+	subtitleDialogueHint = "这段文字是多人对白的视频字幕, 每行以 - 开头的是一个人说的话: 译文要简洁口语化, 保留每行开头的 -."
+	// subtitleTagHint 是字幕中含有格式标签时附加的翻译要求
+	subtitleTagHint = "⟪1⟫ 与 ⟪/1⟫ 这样成对的标记包住的是斜体等格式的文字: 译文中原样保留每一对标记, 并用它包住对应的译文; " +
+		"⟪2/⟫ 这样单独的标记代表说话人、位置等不翻译的内容, 原样保留在译文中对应的位置."
+
+	// DefaultSubtitleLineLength 是字幕每行最多的字符数, 中日韩语为 DefaultSubtitleLineLengthCJK
+	DefaultSubtitleLineLength = 42
+	// DefaultSubtitleLineLengthCJK 是中日韩语字幕每行最多的字符数
+	DefaultSubtitleLineLengthCJK = 16
+)
+
+// subtitleTagRe 匹配字幕中的格式标签: HTML 风格的标签 (第 1 组为 / 时是结束标签, 第 2 组是标签名)、WebVTT 的时间标签与 ASS 风格的 {\an8} 等
+var subtitleTagRe = regexp.MustCompile(`<(/?)([A-Za-z][A-Za-z0-9]*)[^<>]*>|<\d{1,2}:[\d:.]+>|\{\\[^{}]*\}`)
+
+// WithSubtitleLineLength 设置 TranslateSubtitles 译文每行最多的字符数 (不计格式标签), 超出时折为长度相近的多行;
+// n 为 0 时使用默认值 (DefaultSubtitleLineLength, 中日韩语为 DefaultSubtitleLineLengthCJK), 小于 0 时不折行
+func (t *Translator) WithSubtitleLineLength(n int) *Translator {
+	t.subtitleLineLength = n
+	return t
+}
+
+// TranslateSubtitles 将 SRT 或 WebVTT 字幕 r 翻译为 targetLanguage 并写入 w, ctx 被取消时返回 ctx.Err()
+//
+// 每条字幕是一个翻译单元, 序号、时间轴与 WebVTT 的文件头、NOTE、STYLE 与 REGION 块原样保留. 一条字幕中的多行合并后翻译,
+// 译文按 WithSubtitleLineLength 的限制重新折行; 每行以 - 开头的多人对白保持分行. <i>、<font>、<v 说话人> 与 {\an8} 等格式标签
+// 不交给翻译服务. 与 TranslateText 一样使用翻译服务、缓存、翻译记忆与 WithErrorPolicy 等设置, 行尾与 BOM 保持不变.
+func (t *Translator) TranslateSubtitles(ctx context.Context, r io.Reader, w io.Writer, targetLanguage string) error {
+	width := t.subtitleLineLength
+	if width == 0 {
+		width = DefaultSubtitleLineLength
+		switch LanguageCode(targetLanguage) {
+		case "zh", "ja", "ko":
+			width = DefaultSubtitleLineLengthCJK
+		}
+	}
+	return t.translatePlain(ctx, r, w, targetLanguage, func(s string) *textDoc {
+		return splitSubtitles(s, width)
+	})
+}
+
+func translateSubtitleFile(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error {
+	return t.TranslateSubtitles(ctx, bytes.NewReader(data), w, targetLanguage)
+}
+
+// sniffVTT 判断是否为 WebVTT 字幕: 以 WEBVTT 开头
+func sniffVTT(data []byte) bool {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	if !utf8.Valid(data) || !bytes.HasPrefix(data, []byte("WEBVTT")) {
+		return false
+	}
+	return len(data) == 6 || strings.ContainsRune(" \t\r\n", rune(data[6]))
+}
+
+// splitSubtitles 将字幕 s 拆分为翻译单元, 每条字幕时间轴之后的文字是一个翻译单元, width 见 textBlock.wrap
+func splitSubtitles(s string, width int) *textDoc {
+	d := &textDoc{}
+	lines := strings.Split(s, "\n")
+	for i := 0; i < len(lines); {
+		// lines[i:j] 是以空行分隔的一块
+		j := i
+		for j < len(lines) && strings.TrimSpace(lines[j]) != "" {
+			j++
+		}
+		if j == i {
+			j++
+		}
+		text := i
+		if first := strings.TrimSpace(lines[i]); first != "" && !strings.HasPrefix(first, "WEBVTT") &&
+			!strings.HasPrefix(first, "NOTE") && !strings.HasPrefix(first, "STYLE") && !strings.HasPrefix(first, "REGION") {
+			for k := i; k < j; k++ {
+				if strings.Contains(lines[k], "-->") {
+					text = k + 1
+					break
+				}
+			}
+		}
+		if text == i || text == j {
+			text = j
+		}
+		for k := i; k < text; k++ {
+			if k > 0 {
+				d.lit("\n")
+			}
+			d.lit(lines[k])
+		}
+		if text < j {
+			d.lit("\n")
+			d.subtitleCue(lines[text:j], width)
+		}
+		i = j
+	}
+	return d
+}
+
+// subtitleCue 在 d 中加入一条字幕的文字 lines
+func (d *textDoc) subtitleCue(lines []string, width int) {
+	dialogue := len(lines) > 1
+	for _, line := range lines {
+		dialogue = dialogue && strings.HasPrefix(strings.TrimSpace(line), "-")
+	}
+	hint := subtitleHint
+	if dialogue {
+		hint = subtitleDialogueHint
+	}
+	b := d.block(lines[0], "", hint)
+	b.lines = nil
+	for k, line := range lines {
+		if k > 0 {
+			b.src += "\n" + line
+		}
+		b.lines = append(b.lines, strings.TrimSpace(line))
+	}
+	sep := " "
+	if dialogue {
+		sep = "\n"
+	} else {
+		b.oneLine = true
+	}
+	if width > 0 {
+		b.wrap = width
+	}
+	b.text, b.inlines = subtitleInlines(strings.Join(b.lines, sep))
+	if len(b.inlines) > 0 {
+		b.hint = joinHints(b.hint, subtitleTagHint)
+	}
+	if dialogue {
+		b.hint = joinHints(breakHintFor(b.text), b.hint)
+	}
+}
+
+// subtitleInlines 用标记替换字幕 text 中的格式标签: 中间只有文字的一对开始与结束标签用成对的标记包住, 其余的标签用单独的标记表示
+func subtitleInlines(text string) (string, []mdInline) {
+	var (
+		sb      strings.Builder
+		inlines []mdInline
+		last    int
+	)
+	ms := subtitleTagRe.FindAllStringSubmatchIndex(text, -1)
+	for i := 0; i < len(ms); i++ {
+		m := ms[i]
+		sb.WriteString(text[last:m[0]])
+		last = m[1]
+		if m[2] >= 0 && m[3] == m[2] && i+1 < len(ms) {
+			next := ms[i+1]
+			inner := text[m[1]:next[0]]
+			if next[2] >= 0 && next[3] > next[2] && strings.EqualFold(text[next[4]:next[5]], text[m[4]:m[5]]) && hasLetter(inner) {
+				inlines = append(inlines, mdInline{open: text[m[0]:m[1]], close: text[next[0]:next[1]]})
+				open, end := inlineTags(len(inlines))
+				sb.WriteString(open + inner + end)
+				last = next[1]
+				i++
+				continue
+			}
+		}
+		inlines = append(inlines, mdInline{open: text[m[0]:m[1]]})
+		sb.WriteString(inlineTag(len(inlines)))
+	}
+	sb.WriteString(text[last:])
+	return sb.String(), inlines
+}
+
+// wrapToken 是折行时不可分割的一段文字
+type wrapToken struct {
+	text  string
+	width int  // width 是不计格式标签的字符数
+	space bool // space 表示 text 之前有空白
+}
+
+// wrapLines 将 s 中超过 width 个字符 (不计格式标签) 的行在空白处或中日韩文字之间折为长度相近的多行
+func wrapLines(s string, width int) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		tokens := wrapTokens(line)
+		n := len(wrapGreedy(tokens, width))
+		if n <= 1 {
+			continue
+		}
+		// 在行数不变的前提下尽量缩短每行的长度, 使各行长度相近
+		total := 0
+		for k, tk := range tokens {
+			total += tk.width
+			if tk.space && k > 0 {
+				total++
+			}
+		}
+		best := width
+		for w := (total + n - 1) / n; w < width; w++ {
+			if len(wrapGreedy(tokens, w)) == n {
+				best = w
+				break
+			}
+		}
+		lines[i] = strings.Join(wrapGreedy(tokens, best), "\n")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapGreedy 依次将 tokens 放入长度不超过 width 的行中, 过长的一段单独成行
+func wrapGreedy(tokens []wrapToken, width int) []string {
+	var (
+		lines []string
+		sb    strings.Builder
+		cur   int
+	)
+	for _, tk := range tokens {
+		add := tk.width
+		if tk.space && cur > 0 {
+			add++
+		}
+		if cur > 0 && cur+add > width {
+			lines = append(lines, sb.String())
+			sb.Reset()
+			cur, add = 0, tk.width
+		}
+		if tk.space && cur > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(tk.text)
+		cur += add
+	}
+	if sb.Len() > 0 || len(lines) == 0 {
+		lines = append(lines, sb.String())
+	}
+	return lines
+}
+
+// wrapTokens 将一行文字 s 拆分为折行的单位: 以空白分隔的词与单个中日韩文字, 格式标签与标点附在相邻的文字上
+func wrapTokens(s string) []wrapToken {
+	var (
+		tokens []wrapToken
+		space  bool // space 表示之前有尚未计入的空白
+		glue   bool // glue 表示后面的文字接在最后一段之后
+	)
+	add := func(text string, width int, join bool) {
+		if join && len(tokens) > 0 {
+			tokens[len(tokens)-1].text += text
+			tokens[len(tokens)-1].width += width
+			return
+		}
+		tokens = append(tokens, wrapToken{text: text, width: width, space: space})
+		space = false
+	}
+	tags := subtitleTagRe.FindAllStringIndex(s, -1)
+	for i := 0; i < len(s); {
+		if len(tags) > 0 && tags[0][0] == i {
+			tag := s[i:tags[0][1]]
+			if strings.HasPrefix(tag, "</") {
+				// 结束标签附在前面的文字上
+				add(tag, 0, !space)
+				glue = false
+			} else {
+				// 其余标签附在后面的文字上
+				add(tag, 0, glue && tokens[len(tokens)-1].width == 0)
+				glue = true
+			}
+			i = tags[0][1]
+			tags = tags[1:]
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case unicode.IsSpace(r):
+			space, glue = true, false
+		case unicode.In(r, unicode.Ps, unicode.Pi):
+			add(s[i:i+size], 1, glue && tokens[len(tokens)-1].width == 0)
+			glue = true
+		case unicode.IsPunct(r) && !space && len(tokens) > 0:
+			add(s[i:i+size], 1, true)
+		case isCJKRune(r):
+			add(s[i:i+size], 1, glue && tokens[len(tokens)-1].width == 0)
+			glue = false
+		default:
+			add(s[i:i+size], 1, glue)
+			glue = true
+		}
+		i += size
+	}
+	return tokens
+}
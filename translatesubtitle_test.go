@@ -0,0 +1,61 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTranslateSubtitles(t *testing.T) {
+	srt := "1\r\n00:00:01,000 --> 00:00:02,500\r\n<i>hello there</i>\r\n\r\n" +
+		"2\r\n00:00:03,000 --> 00:00:05,000\r\n{\\an8}this line is long\r\nand wraps again\r\n\r\n" +
+		"3\r\n00:00:06,000 --> 00:00:07,000\r\n- yes\r\n- no\r\n\r\n" +
+		"4\r\n00:00:08,000 --> 00:00:09,000\r\n♪ ♪\r\n"
+	tr := NewTranslator("", "").WithSubtitleLineLength(20).WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	if SniffFormat([]byte(srt)) != "srt" {
+		t.Fatal("not sniffed as srt")
+	}
+	var buf bytes.Buffer
+	if err := tr.TranslateFile(context.Background(), strings.NewReader(srt), &buf, TranslateFileOptions{TargetLanguage: "English"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "1\r\n00:00:01,000 --> 00:00:02,500\r\n<i>HELLO THERE</i>\r\n\r\n" +
+		"2\r\n00:00:03,000 --> 00:00:05,000\r\n{\\an8}THIS LINE IS LONG\r\nAND WRAPS AGAIN\r\n\r\n" +
+		"3\r\n00:00:06,000 --> 00:00:07,000\r\n- YES\r\n- NO\r\n\r\n" +
+		"4\r\n00:00:08,000 --> 00:00:09,000\r\n♪ ♪\r\n"
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+
+	vtt := "WEBVTT\n\nNOTE keep me\n\nintro\n00:01.000 --> 00:02.000 align:start\n<v Anna>good morning\n"
+	if SniffFormat([]byte(vtt)) != "vtt" {
+		t.Fatal("not sniffed as vtt")
+	}
+	buf.Reset()
+	if err := tr.TranslateSubtitles(context.Background(), strings.NewReader(vtt), &buf, "English"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "WEBVTT\n\nNOTE keep me\n\nintro\n00:01.000 --> 00:02.000 align:start\n<v Anna>GOOD MORNING\n"; buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWrapLines(t *testing.T) {
+	for _, c := range []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"one two three four five", 16, "one two three\nfour five"},
+		{"这是一条很长的中文字幕，需要折行。", 10, "这是一条很长的中文\n字幕，需要折行。"},
+		{"<i>aaa bbb</i> ccc", 5, "<i>aaa\nbbb</i>\nccc"},
+	} {
+		if got := wrapLines(c.s, c.width); got != c.want {
+			t.Errorf("wrapLines(%q, %d) = %q, want %q", c.s, c.width, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package docx
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TermOptions 控制 ExtractTerms 的行为, 零值字段使用默认值
+type TermOptions struct {
+	MinFrequency int // MinFrequency 是候选术语的最少出现次数, 默认 2
+	MaxWords     int // MaxWords 是西文术语最多包含的单词数, 默认 3
+	MaxCJKRunes  int // MaxCJKRunes 是中日韩术语最多包含的字数, 默认 4
+	MaxContexts  int // MaxContexts 是每个术语保留的上下文数, 默认 3
+}
+
+// TermCandidate 是一个候选术语
+type TermCandidate struct {
+	Term      string   // Term 是术语首次出现时的写法
+	Frequency int      // Frequency 是在所有文档中出现的总次数
+	Documents int      // Documents 是包含该术语的文档数
+	Contexts  []string // Contexts 是包含该术语的段落片段
+}
+
+// termStopWords 不能作为西文术语开头或结尾的常见词
+var termStopWords = map[string]struct{}{}
+
+func init() {
+	for _, w := range strings.Fields(`a an and are as at be by for from has have in is it its of on or
+		that the this to was were will with not no can may shall should must which who all any each
+		than then there these those such into out over under between per via`) {
+		termStopWords[w] = struct{}{}
+	}
+}
+
+type termStat struct {
+	TermCandidate
+	key  string
+	docs map[int]struct{}
+}
+
+// ExtractTerms 扫描一个或多个文档, 按出现频率从高到低返回候选术语,
+// 供术语专家在翻译前整理为术语表
+//
+// 西文按单词切分并统计 1 至 MaxWords 元组, 中日韩文字统计 2 至 MaxCJKRunes 字的片段;
+// 被一个更长且出现次数相同的候选完全包含的候选会被舍弃.
+func ExtractTerms(docs []*Docx, opts TermOptions) []TermCandidate {
+	if opts.MinFrequency <= 0 {
+		opts.MinFrequency = 2
+	}
+	if opts.MaxWords <= 0 {
+		opts.MaxWords = 3
+	}
+	if opts.MaxCJKRunes <= 0 {
+		opts.MaxCJKRunes = 4
+	}
+	if opts.MaxContexts <= 0 {
+		opts.MaxContexts = 3
+	}
+	stats := make(map[string]*termStat, 1024)
+	for di, doc := range docs {
+		_ = doc.Document.Body.RangeParagraphs(func(p *Paragraph) error {
+			text := paragraphText(p)
+			for _, gram := range termGrams(text, &opts) {
+				st, ok := stats[gram.key]
+				if !ok {
+					st = &termStat{key: gram.key, docs: make(map[int]struct{}, 4)}
+					st.Term = gram.surface
+					stats[gram.key] = st
+				}
+				st.Frequency++
+				st.docs[di] = struct{}{}
+				if len(st.Contexts) < opts.MaxContexts {
+					ctx := termContext(text, gram.surface)
+					if len(st.Contexts) == 0 || st.Contexts[len(st.Contexts)-1] != ctx {
+						st.Contexts = append(st.Contexts, ctx)
+					}
+				}
+			}
+			return nil
+		})
+	}
+
+	cands := make([]*termStat, 0, len(stats))
+	for _, st := range stats {
+		if st.Frequency >= opts.MinFrequency {
+			st.Documents = len(st.docs)
+			cands = append(cands, st)
+		}
+	}
+	// 长的在前, 便于判断包含关系
+	sort.Slice(cands, func(i, j int) bool {
+		return len(cands[i].key) > len(cands[j].key)
+	})
+	terms := make([]TermCandidate, 0, len(cands))
+	kept := make([]*termStat, 0, len(cands))
+nextcand:
+	for _, c := range cands {
+		for _, k := range kept {
+			if len(k.key) > len(c.key) && k.Frequency >= c.Frequency && strings.Contains(k.key, c.key) {
+				continue nextcand
+			}
+		}
+		kept = append(kept, c)
+		terms = append(terms, c.TermCandidate)
+	}
+	sort.SliceStable(terms, func(i, j int) bool {
+		if terms[i].Frequency != terms[j].Frequency {
+			return terms[i].Frequency > terms[j].Frequency
+		}
+		return terms[i].Term < terms[j].Term
+	})
+	return terms
+}
+
+// WriteTermsCSV 以 term,frequency,documents,contexts 的格式写出候选术语,
+// 多个上下文以 " | " 分隔
+func WriteTermsCSV(w io.Writer, terms []TermCandidate) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{"term", "frequency", "documents", "contexts"})
+	if err != nil {
+		return err
+	}
+	for _, t := range terms {
+		err = cw.Write([]string{
+			t.Term, strconv.Itoa(t.Frequency), strconv.Itoa(t.Documents), strings.Join(t.Contexts, " | "),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type termGram struct {
+	key     string // key 是统计用的归一化写法
+	surface string // surface 是原文中的写法
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// termGrams 将一段文本切分为候选元组
+func termGrams(text string, opts *TermOptions) []termGram {
+	grams := make([]termGram, 0, 64)
+	var words []string
+	var cjk []rune
+	flushWords := func() {
+		for i := range words {
+			for n := 1; n <= opts.MaxWords && i+n <= len(words); n++ {
+				g := words[i : i+n]
+				first, last := strings.ToLower(g[0]), strings.ToLower(g[n-1])
+				if _, ok := termStopWords[first]; ok {
+					break
+				}
+				if _, ok := termStopWords[last]; ok {
+					continue
+				}
+				if n == 1 && utf8.RuneCountInString(first) < 3 {
+					continue
+				}
+				if strings.IndexFunc(strings.Join(g, ""), unicode.IsLetter) < 0 {
+					continue
+				}
+				surface := strings.Join(g, " ")
+				grams = append(grams, termGram{key: strings.ToLower(surface), surface: surface})
+			}
+		}
+		words = words[:0]
+	}
+	flushCJK := func() {
+		for i := range cjk {
+			for n := 2; n <= opts.MaxCJKRunes && i+n <= len(cjk); n++ {
+				s := string(cjk[i : i+n])
+				grams = append(grams, termGram{key: s, surface: s})
+			}
+		}
+		cjk = cjk[:0]
+	}
+	var word strings.Builder
+	for _, r := range text + " " {
+		switch {
+		case isCJK(r):
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			flushWords()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || ((r == '-' || r == '\'') && word.Len() > 0):
+			flushCJK()
+			word.WriteRune(r)
+		default:
+			flushCJK()
+			if word.Len() > 0 {
+				words = append(words, strings.TrimRight(word.String(), "-'"))
+				word.Reset()
+			}
+			// 标点处断开词组, 空白不断开
+			if !unicode.IsSpace(r) {
+				flushWords()
+			}
+		}
+	}
+	flushWords()
+	return grams
+}
+
+// termContext 截取术语附近最多 80 个字的片段
+func termContext(text, term string) string {
+	const width = 80
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= width {
+		return string(runes)
+	}
+	start := 0
+	if i := strings.Index(string(runes), term); i >= 0 {
+		start = utf8.RuneCountInString(string(runes)[:i]) - (width-utf8.RuneCountInString(term))/2
+	}
+	if start < 0 {
+		start = 0
+	}
+	if start+width > len(runes) {
+		start = len(runes) - width
+	}
+	ctx := string(runes[start : start+width])
+	if start > 0 {
+		ctx = "…" + ctx
+	}
+	if start+width < len(runes) {
+		ctx += "…"
+	}
+	return ctx
+}
@@ -0,0 +1,39 @@
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestExtractTerms(t *testing.T) {
+	d1 := newTestDoc("The machine translation engine is fast.", "合同条款适用于本合同。")
+	d2 := newTestDoc("A machine translation engine, again!", "合同条款")
+	terms := ExtractTerms([]*Docx{d1, d2}, TermOptions{})
+	got := make(map[string]TermCandidate, len(terms))
+	for _, tc := range terms {
+		got[tc.Term] = tc
+	}
+	if tc, ok := got["machine translation engine"]; !ok || tc.Frequency != 2 || tc.Documents != 2 || len(tc.Contexts) != 2 {
+		t.Fatalf("missing or wrong trigram: %+v", terms)
+	}
+	if _, ok := got["machine"]; ok {
+		t.Fatal("subsumed unigram should be dropped")
+	}
+	if tc, ok := got["合同"]; !ok || tc.Frequency != 3 {
+		t.Fatalf("missing CJK term: %+v", terms)
+	}
+	if _, ok := got["合同条款"]; !ok {
+		t.Fatalf("missing CJK term: %+v", terms)
+	}
+	if terms[0].Term != "合同" {
+		t.Fatal("terms not ranked by frequency:", terms[0])
+	}
+	var buf bytes.Buffer
+	if err := WriteTermsCSV(&buf, terms); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(buf.String(), "term,frequency,documents,contexts\n合同,3,2,") {
+		t.Fatal("unexpected csv:", buf.String())
+	}
+}
@@ -0,0 +1,455 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// mdHint 是 Markdown 文档中的文字附加的翻译要求
+	mdHint = "这段文字取自 Markdown 文档: 原样保留 **、*、_、~~ 等强调标记并包住对应的译文, 不要添加原文没有的 Markdown 标记."
+	// mdInlineHint 是 Markdown 段落中含有链接、代码或网址时附加的翻译要求
+	mdInlineHint = "⟪1⟫ 与 ⟪/1⟫ 这样成对的标记包住的是链接或图片的文字: 译文中原样保留每一对标记, 并用它包住对应的译文; " +
+		"⟪2/⟫ 这样单独的标记代表代码、网址与 HTML 标签等不翻译的内容, 原样保留在译文中对应的位置."
+	// mdHeadingHint 是 Markdown 标题附加的翻译要求
+	mdHeadingHint = "这段文字是标题: 译文要简洁, 不要添加句末标点."
+	// mdCellHint 是 Markdown 表格单元格附加的翻译要求
+	mdCellHint = "这段文字是表格单元格中的内容: 译文要简洁, 不要换行."
+)
+
+var (
+	// mdInlineRe 匹配 Markdown 段落中不翻译的行内内容: 代码、脚注引用、链接与图片 (第 1 组是其文字)、自动链接、HTML 标签与网址
+	mdInlineRe = regexp.MustCompile("``[^`]+(?:`[^`]+)*``|`[^`]+`" +
+		`|\[\^[^\]\s]+\]` +
+		`|!?\[((?:[^\[\]]|\[[^\[\]]*\])*)\](?:\([^()\s]*(?:\([^()\s]*\)[^()\s]*)*(?:\s+"[^"]*")?\)|\[[^\]]*\])` +
+		`|<(?:https?|ftp|mailto):[^<>\s]+>` +
+		`|<!--.*?-->|</?[A-Za-z][A-Za-z0-9-]*(?:\s[^<>]*)?/?>` +
+		`|(?:https?|ftp)://[^\s<>()\[\]]+`)
+	// mdContainerRe 匹配行首的引用与列表标记 (包括任务列表的复选框)
+	mdContainerRe = regexp.MustCompile(`^(?:[ \t]*(?:>[ \t]?|(?:[-*+]|\d{1,9}[.)])(?:[ \t]+|$)(?:\[[ xX]\][ \t]+)?))*`)
+	// mdHeadingRe 匹配 ATX 标题, 第 1 组是标题的文字
+	mdHeadingRe = regexp.MustCompile(`^#{1,6}(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	// mdLinkDefRe 匹配链接引用定义
+	mdLinkDefRe = regexp.MustCompile(`^ {0,3}\[[^\]]+\]:\s`)
+	// mdHTMLRe 匹配以 HTML 标签或注释开头的行
+	mdHTMLRe = regexp.MustCompile(`^ {0,3}(?:<!--|</?[A-Za-z][A-Za-z0-9-]*(?:[\s/>]|$))`)
+	// mdDelimiterRe 匹配表格的分隔行
+	mdDelimiterRe = regexp.MustCompile(`^[ \t]*\|?[ \t]*:?-+:?[ \t]*(?:\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+	// mdSetextRe 匹配 Setext 标题的下划线
+	mdSetextRe = regexp.MustCompile(`^ {0,3}(?:=+|-+)[ \t]*$`)
+)
+
+// TranslateText 将纯文本 r 翻译为 targetLanguage 并写入 w, ctx 被取消时返回 ctx.Err()
+//
+// 以空行分隔的每一段是一个翻译单元, 段内的换行、段落之间的空行、缩进、行尾 (\n 或 \r\n) 与 BOM 保持不变.
+// 与 TranslateDocx 一样使用翻译服务、缓存、翻译记忆、不翻译列表与 WithErrorPolicy 等设置,
+// ErrorPolicyCollect 下失败的段落保留原文并返回 SegmentErrors.
+func (t *Translator) TranslateText(ctx context.Context, r io.Reader, w io.Writer, targetLanguage string) error {
+	return t.translatePlain(ctx, r, w, targetLanguage, splitText)
+}
+
+// TranslateMarkdown 同 TranslateText, 但按 Markdown 的结构翻译 r 中的文字
+//
+// 代码块、front matter、HTML 块、链接引用定义与分隔线原样保留; 标题、段落、列表项、引用与表格单元格分别翻译,
+// 其中的标题与列表标记、引用符号与表格的竖线保持不变. 行内代码、网址、链接的地址、图片的路径与 HTML 标签不交给翻译服务,
+// 链接与图片的文字随所在的段落一起翻译.
+func (t *Translator) TranslateMarkdown(ctx context.Context, r io.Reader, w io.Writer, targetLanguage string) error {
+	return t.translatePlain(ctx, r, w, targetLanguage, splitMarkdown)
+}
+
+func translateTextFile(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error {
+	return t.TranslateText(ctx, bytes.NewReader(data), w, targetLanguage)
+}
+
+func translateMarkdownFile(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error {
+	return t.TranslateMarkdown(ctx, bytes.NewReader(data), w, targetLanguage)
+}
+
+// translatePlain 用 split 将 r 中的文本拆分为翻译单元, 翻译后写入 w
+func (t *Translator) translatePlain(ctx context.Context, r io.Reader, w io.Writer, targetLanguage string, split func(s string) *textDoc) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s := string(data)
+	bom := strings.HasPrefix(s, "\ufeff")
+	s = strings.TrimPrefix(s, "\ufeff")
+	crlf := strings.Contains(s, "\r\n")
+	if crlf {
+		s = strings.ReplaceAll(s, "\r\n", "\n")
+	}
+	d := split(s)
+	segs := make([]*segment, 0, len(d.blocks))
+	for _, b := range d.blocks {
+		if !hasLetter(inlineTagRe.ReplaceAllString(b.text, "")) || t.skipText(b.text) {
+			continue
+		}
+		b := b
+		segs = append(segs, &segment{text: b.text, hint: b.hint, set: func(s string) { b.translated = &s }})
+	}
+	report, err := t.translateSegments(ctx, segs, targetLanguage)
+	if report == nil {
+		return err
+	}
+
+	out := d.render()
+	if crlf {
+		out = strings.ReplaceAll(out, "\n", "\r\n")
+	}
+	if bom {
+		out = "\ufeff" + out
+	}
+	if _, werr := io.WriteString(w, out); werr != nil {
+		return werr
+	}
+	return err // ErrorPolicyCollect 下的 SegmentErrors
+}
+
+// textDoc 是拆分为翻译单元的文本, 依次输出 pieces 中的原文与译文
+type textDoc struct {
+	pieces []interface{} // pieces 的元素是原样输出的 string 或 *textBlock
+	blocks []*textBlock
+}
+
+// textBlock 是文本中的一个翻译单元
+type textBlock struct {
+	src        string     // src 是原文, 未翻译时原样输出
+	lines      []string   // lines 是各行去掉行首标记后的文字, 见 finish
+	text, hint string     // text 是交给翻译服务的文字, 行内不翻译的内容用标记表示
+	inlines    []mdInline // inlines 是 text 中第 i 个标记对应的内容
+	indent     string     // indent 是译文中换行之后补上的续行前缀, 如列表与引用的缩进
+	oneLine    bool       // oneLine 表示译文中的换行改为空格, 如表格单元格
+	wrap       int        // wrap 大于 0 时译文的每行超过 wrap 个字符则折行, 如字幕, 见 wrapLines
+	translated *string
+}
+
+// mdInline 是 Markdown 段落中不翻译的行内内容
+type mdInline struct {
+	open, close string // open 与 close 是链接与图片的文字之前与之后的部分; close 为空时 open 是不翻译的整体, 如代码与网址
+}
+
+func (d *textDoc) lit(s string) {
+	d.pieces = append(d.pieces, s)
+}
+
+// block 在 d 中加入以 text 开头的翻译单元, text 两侧的空白原样输出
+func (d *textDoc) block(text, indent, hint string) *textBlock {
+	trimmed := strings.TrimLeft(text, " \t")
+	d.lit(text[:len(text)-len(trimmed)])
+	b := &textBlock{src: trimmed, lines: []string{trimmed}, indent: indent, hint: hint}
+	d.pieces = append(d.pieces, b)
+	d.blocks = append(d.blocks, b)
+	return b
+}
+
+// finish 拼接 b 的各行, md 为 true 时用标记替换行内不翻译的内容
+func (b *textBlock) finish(md bool) {
+	text := strings.TrimSpace(strings.Join(b.lines, "\n"))
+	if md {
+		text, b.inlines = mdInlines(text)
+		b.hint = joinHints(b.hint, mdHint)
+		if len(b.inlines) > 0 {
+			b.hint = joinHints(b.hint, mdInlineHint)
+		}
+	}
+	b.text = text
+	if !b.oneLine {
+		b.hint = joinHints(breakHintFor(text), b.hint)
+	}
+}
+
+func (d *textDoc) render() string {
+	var sb strings.Builder
+	for _, p := range d.pieces {
+		switch p := p.(type) {
+		case string:
+			sb.WriteString(p)
+		case *textBlock:
+			sb.WriteString(p.render())
+		}
+	}
+	return sb.String()
+}
+
+func (b *textBlock) render() string {
+	if b.translated == nil {
+		return b.src
+	}
+	translated := strings.TrimSpace(*b.translated)
+	s, ok := b.fillInlines(translated)
+	if !ok {
+		s = inlineTagRe.ReplaceAllString(translated, "")
+		for _, in := range b.inlines {
+			if in.close == "" {
+				s += in.open
+			}
+		}
+	}
+	if b.oneLine {
+		s = strings.Join(strings.Fields(s), " ")
+	} else {
+		s = strings.ReplaceAll(s, "\n", "\n"+b.indent)
+	}
+	if b.wrap > 0 {
+		s = wrapLines(s, b.wrap)
+	}
+	return s + b.src[len(strings.TrimRight(b.src, " \t")):]
+}
+
+// fillInlines 按译文 translated 中的标记还原行内不翻译的内容, 规则同 segment.fillInlines
+func (b *textBlock) fillInlines(translated string) (string, bool) {
+	var (
+		sb   strings.Builder
+		seen = make([]bool, len(b.inlines))
+		open int
+		last int
+	)
+	for _, m := range inlineTagRe.FindAllStringSubmatchIndex(translated, -1) {
+		n, err := strconv.Atoi(translated[m[4]:m[5]])
+		if err != nil || n < 1 || n > len(b.inlines) {
+			return "", false
+		}
+		closing, single := m[3] > m[2], m[7] > m[6]
+		in := b.inlines[n-1]
+		atomic := in.close == ""
+		sb.WriteString(translated[last:m[0]])
+		switch {
+		case single && !closing && open == 0 && atomic && !seen[n-1]:
+			sb.WriteString(in.open)
+			seen[n-1] = true
+		case !single && !closing && open == 0 && !atomic && !seen[n-1]:
+			sb.WriteString(in.open)
+			open = n
+			seen[n-1] = true
+		case !single && closing && open != 0 && n == open:
+			sb.WriteString(in.close)
+			open = 0
+		default:
+			return "", false
+		}
+		last = m[1]
+	}
+	if open != 0 {
+		return "", false
+	}
+	for _, ok := range seen {
+		if !ok {
+			return "", false
+		}
+	}
+	sb.WriteString(translated[last:])
+	return sb.String(), true
+}
+
+// splitText 将纯文本 s 按空行拆分为翻译单元
+func splitText(s string) *textDoc {
+	d := &textDoc{}
+	lines := strings.Split(s, "\n")
+	for i := 0; i < len(lines); i++ {
+		if i > 0 {
+			d.lit("\n")
+		}
+		if strings.TrimSpace(lines[i]) == "" {
+			d.lit(lines[i])
+			continue
+		}
+		b := d.block(lines[i], "", "")
+		for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			i++
+			b.src += "\n" + lines[i]
+			b.lines = append(b.lines, lines[i])
+		}
+		b.finish(false)
+	}
+	return d
+}
+
+// splitMarkdown 将 Markdown 文档 s 拆分为翻译单元, 见 TranslateMarkdown
+func splitMarkdown(s string) *textDoc {
+	d := &textDoc{}
+	lines := strings.Split(s, "\n")
+	var (
+		para    *textBlock // para 是可以接续下一行的段落
+		fence   string     // fence 非空时位于代码块中, 是代码块的开始标记
+		html    bool       // html 表示位于 HTML 块中, 直到空行
+		inList  bool       // inList 表示位于列表中, 缩进的行是列表项的内容而不是代码块
+		inTable bool
+	)
+	end := func() {
+		if para != nil {
+			para.finish(true)
+			para = nil
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		lead := mdContainerRe.FindString(line)
+		rest := line[len(lead):]
+		marker := strings.Trim(lead, " \t>") != ""
+		unquoted := strings.TrimLeft(line, " \t>")
+		// 段落的续行; Setext 标题的下划线不是续行, 作为没有文字的段落原样输出
+		if para != nil && unquoted != "" && !marker && !inTable && !mdBlockStart(rest) && !mdSetextRe.MatchString(rest) {
+			para.src += "\n" + line
+			para.lines = append(para.lines, strings.TrimSpace(rest))
+			continue
+		}
+		end()
+		if i > 0 {
+			d.lit("\n")
+		}
+		// front matter
+		if i == 0 && line == "---" {
+			for k := 1; k < len(lines); k++ {
+				if lines[k] == "---" || lines[k] == "..." {
+					d.lit(strings.Join(lines[:k+1], "\n"))
+					i = k
+					break
+				}
+			}
+			if i > 0 {
+				continue
+			}
+		}
+		if fence != "" {
+			if strings.HasPrefix(unquoted, fence) && strings.Trim(unquoted, fence[:1]+" \t") == "" {
+				fence = ""
+			}
+			d.lit(line)
+			continue
+		}
+		if unquoted == "" {
+			html, inTable = false, false
+			d.lit(line)
+			continue
+		}
+		if html {
+			d.lit(line)
+			continue
+		}
+		switch {
+		case mdThematicBreak(unquoted):
+			d.lit(line)
+			continue
+		case strings.HasPrefix(strings.TrimLeft(rest, " "), "```"), strings.HasPrefix(strings.TrimLeft(rest, " "), "~~~"):
+			trimmed := strings.TrimLeft(rest, " ")
+			fence = trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, trimmed[:1]))]
+			d.lit(line)
+			continue
+		case lead == "" && !inList && (strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t")):
+			d.lit(line) // 缩进的代码块
+			continue
+		case mdHTMLRe.MatchString(rest):
+			html = true
+			d.lit(line)
+			continue
+		case mdLinkDefRe.MatchString(rest), mdDelimiterRe.MatchString(rest) && strings.Contains(rest, "-"):
+			d.lit(line)
+			continue
+		}
+		if marker {
+			inList = true
+		} else if lead == "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			inList = false
+		}
+		d.lit(lead)
+		indent := strings.Map(func(r rune) rune {
+			if r == '>' || r == '\t' {
+				return r
+			}
+			return ' '
+		}, lead)
+		if inTable || strings.Contains(rest, "|") && i+1 < len(lines) && mdDelimiterRe.MatchString(lines[i+1][len(mdContainerRe.FindString(lines[i+1])):]) {
+			inTable = true
+			d.tableRow(rest)
+			continue
+		}
+		if m := mdHeadingRe.FindStringSubmatchIndex(rest); m != nil {
+			if m[2] < 0 || strings.TrimSpace(rest[m[2]:m[3]]) == "" {
+				d.lit(rest)
+				continue
+			}
+			d.lit(rest[:m[2]])
+			b := d.block(rest[m[2]:m[3]], indent, mdHeadingHint)
+			b.finish(true)
+			d.lit(rest[m[3]:])
+			continue
+		}
+		para = d.block(rest, indent, "")
+	}
+	end()
+	return d
+}
+
+// tableRow 将表格的一行 row 按单元格拆分为翻译单元
+func (d *textDoc) tableRow(row string) {
+	start, code := 0, false
+	cell := func(end int) {
+		text := row[start:end]
+		if strings.TrimSpace(text) == "" {
+			d.lit(text)
+			return
+		}
+		trimmed := strings.TrimRight(text, " \t")
+		b := d.block(trimmed, "", mdCellHint)
+		b.oneLine = true
+		b.finish(true)
+		d.lit(text[len(trimmed):])
+	}
+	for i := 0; i < len(row); i++ {
+		switch row[i] {
+		case '\\':
+			i++
+		case '`':
+			code = !code
+		case '|':
+			if !code {
+				cell(i)
+				d.lit("|")
+				start = i + 1
+			}
+		}
+	}
+	cell(len(row))
+}
+
+// mdBlockStart 判断去掉行首标记后的 rest 是否开始一个新的块, 不能作为上一段落的续行
+func mdBlockStart(rest string) bool {
+	trimmed := strings.TrimLeft(rest, " ")
+	return strings.HasPrefix(trimmed, "#") && mdHeadingRe.MatchString(trimmed) ||
+		strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") ||
+		mdHTMLRe.MatchString(rest) || mdThematicBreak(trimmed) && !mdSetextRe.MatchString(rest)
+}
+
+// mdThematicBreak 判断 line 是否为分隔线: 三个以上相同的 -、* 或 _, 其间可以有空格
+func mdThematicBreak(line string) bool {
+	s := strings.NewReplacer(" ", "", "\t", "").Replace(line)
+	return len(s) >= 3 && strings.Contains("-*_", s[:1]) && strings.Trim(s, s[:1]) == ""
+}
+
+// mdInlines 用标记替换 Markdown 段落 text 中不翻译的行内内容, 见 mdInlineHint:
+// 链接与图片的文字用成对的标记包住, 其余的内容与文字中含有代码或嵌套链接的链接用单独的标记表示
+func mdInlines(text string) (string, []mdInline) {
+	var (
+		sb      strings.Builder
+		inlines []mdInline
+		last    int
+	)
+	for _, m := range mdInlineRe.FindAllStringSubmatchIndex(text, -1) {
+		sb.WriteString(text[last:m[0]])
+		last = m[1]
+		if m[2] >= 0 && hasLetter(text[m[2]:m[3]]) && !strings.ContainsAny(text[m[2]:m[3]], "`[]<") {
+			inlines = append(inlines, mdInline{open: text[m[0]:m[2]], close: text[m[3]:m[1]]})
+			open, end := inlineTags(len(inlines))
+			sb.WriteString(open + text[m[2]:m[3]] + end)
+			continue
+		}
+		inlines = append(inlines, mdInline{open: text[m[0]:m[1]]})
+		sb.WriteString(inlineTag(len(inlines)))
+	}
+	sb.WriteString(text[last:])
+	return sb.String(), inlines
+}
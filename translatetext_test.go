@@ -0,0 +1,64 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTranslateText(t *testing.T) {
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	var out bytes.Buffer
+	in := "\ufeffFirst line\r\n  second line\r\n\r\n\r\n  indented para  \r\n2024\r\n"
+	if err := tr.TranslateText(context.Background(), strings.NewReader(in), &out, "English"); err != nil {
+		t.Fatal(err)
+	}
+	if want := "\ufeffFIRST LINE\r\n  SECOND LINE\r\n\r\n\r\n  INDENTED PARA  \r\n2024\r\n"; out.String() != want {
+		t.Fatalf("got %q, want %q", out.String(), want)
+	}
+}
+
+func TestTranslateMarkdown(t *testing.T) {
+	var sources []string
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		sources = append(sources, text)
+		return strings.ToUpper(text), nil
+	}))
+	const in = "---\ntitle: keep me\n---\n" +
+		"# Getting started #\n\n" +
+		"Run `go build` and read [the guide](https://example.com/guide \"Guide\") or https://example.com/raw.\n" +
+		"See ![a diagram](img/diagram.png) below.\n\n" +
+		"```go\nfmt.Println(\"hello\")\n```\n\n" +
+		"- first item\n  continued here\n- [ ] task item\n\n" +
+		"> quoted text\n> more quoted\n\n" +
+		"| Name | Value |\n| ---- | :---: |\n| speed | `fast` |\n\n" +
+		"    indented code\n\n" +
+		"<div align=\"center\">html block</div>\n\n" +
+		"***\n\n[ref]: https://example.com/ref\n"
+	var out bytes.Buffer
+	if err := tr.TranslateMarkdown(context.Background(), strings.NewReader(in), &out, "English"); err != nil {
+		t.Fatal(err)
+	}
+	const want = "---\ntitle: keep me\n---\n" +
+		"# GETTING STARTED #\n\n" +
+		"RUN `go build` AND READ [THE GUIDE](https://example.com/guide \"Guide\") OR https://example.com/raw.\n" +
+		"SEE ![A DIAGRAM](img/diagram.png) BELOW.\n\n" +
+		"```go\nfmt.Println(\"hello\")\n```\n\n" +
+		"- FIRST ITEM\n  CONTINUED HERE\n- [ ] TASK ITEM\n\n" +
+		"> QUOTED TEXT\n> MORE QUOTED\n\n" +
+		"| NAME | VALUE |\n| ---- | :---: |\n| SPEED | `fast` |\n\n" +
+		"    indented code\n\n" +
+		"<div align=\"center\">html block</div>\n\n" +
+		"***\n\n[ref]: https://example.com/ref\n"
+	if out.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+	for _, s := range sources {
+		if strings.Contains(s, "example.com") || strings.Contains(s, "go build") || strings.Contains(s, "diagram.png") {
+			t.Error("protected content was sent for translation:", s)
+		}
+	}
+}
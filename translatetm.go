@@ -0,0 +1,395 @@
+package docx
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"sync"
+)
+
+// TMUnit 是翻译记忆中的一个句对
+type TMUnit struct {
+	SourceLang string // SourceLang 是原文语言, 保存为 ISO 639-1 代码
+	TargetLang string // TargetLang 是译文语言, 保存为 ISO 639-1 代码
+	Source     string
+	Target     string
+}
+
+type tmKey struct {
+	source, target, text string
+}
+
+// TranslationMemory 是内存中的翻译记忆库, 可以从 TMX 文件导入并导出为 TMX,
+// 与 Trados, memoQ 等 CAT 工具交换句对. 可以并发调用.
+//
+// 语言统一按 LanguageCode 归一化, 因此 "English", "en-US" 与 "en" 视为同一语言.
+type TranslationMemory struct {
+	mu    sync.RWMutex
+	units []TMUnit
+	index map[tmKey]int
+	added int // units[added:] 是最后一次导入之后新增的句对
+}
+
+// NewTranslationMemory 创建一个空的翻译记忆库
+func NewTranslationMemory() *TranslationMemory {
+	return &TranslationMemory{
+		units: make([]TMUnit, 0, 256),
+		index: make(map[tmKey]int, 256),
+	}
+}
+
+// Len 返回记忆库中的句对数
+func (tm *TranslationMemory) Len() int {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return len(tm.units)
+}
+
+// Units 返回记忆库中所有句对的副本
+func (tm *TranslationMemory) Units() []TMUnit {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return append([]TMUnit(nil), tm.units...)
+}
+
+// NewUnits 返回最后一次导入之后新增的句对, 通常是本次翻译产生的译文
+func (tm *TranslationMemory) NewUnits() []TMUnit {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return append([]TMUnit(nil), tm.units[tm.added:]...)
+}
+
+// Add 添加一个句对, 相同语言对下相同原文的旧译文会被替换
+func (tm *TranslationMemory) Add(sourceLang, targetLang, source, target string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.add(TMUnit{SourceLang: sourceLang, TargetLang: targetLang, Source: source, Target: target})
+}
+
+// add 添加一个句对, 调用时必须持有锁
+func (tm *TranslationMemory) add(u TMUnit) {
+	u.SourceLang, u.TargetLang = LanguageCode(u.SourceLang), LanguageCode(u.TargetLang)
+	k := tmKey{u.SourceLang, u.TargetLang, strings.TrimSpace(u.Source)}
+	if i, ok := tm.index[k]; ok && i >= tm.added {
+		tm.units[i] = u
+		return
+	}
+	// 导入的句对保持不变, 新译文追加到末尾, 以便 NewUnits 导出
+	tm.index[k] = len(tm.units)
+	tm.units = append(tm.units, u)
+}
+
+// Lookup 返回与 source 完全相同 (忽略首尾空白) 的原文的译文
+func (tm *TranslationMemory) Lookup(sourceLang, targetLang, source string) (string, bool) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	i, ok := tm.index[tmKey{LanguageCode(sourceLang), LanguageCode(targetLang), strings.TrimSpace(source)}]
+	if !ok {
+		return "", false
+	}
+	return tm.units[i].Target, true
+}
+
+// Fuzzy 返回给定语言对下与 source 最相似的句对及其相似度, 取值范围 [0, 1];
+// 记忆库中没有该语言对时返回零值与 0
+//
+// 相似度为 1 - 编辑距离 / 较长一方的字数, 比较前忽略首尾空白.
+func (tm *TranslationMemory) Fuzzy(sourceLang, targetLang, source string) (TMUnit, float64) {
+	sl, tl := LanguageCode(sourceLang), LanguageCode(targetLang)
+	src := []rune(strings.TrimSpace(source))
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	var (
+		best  TMUnit
+		score float64
+	)
+	for _, u := range tm.units {
+		if u.SourceLang != sl || u.TargetLang != tl {
+			continue
+		}
+		cand := []rune(strings.TrimSpace(u.Source))
+		// 长度之差是编辑距离的下界, 据此跳过不可能更好的候选
+		if similarityBound(len(src), len(cand)) <= score {
+			continue
+		}
+		if s := similarity(src, cand); s > score {
+			best, score = u, s
+			if s >= 1 {
+				break
+			}
+		}
+	}
+	return best, score
+}
+
+// Matcher 返回给定语言对下的 MatchSource, 用于 AnalyzeDocx
+func (tm *TranslationMemory) Matcher(sourceLang, targetLang string) MatchSource {
+	return tmMatcher{tm: tm, source: sourceLang, target: targetLang}
+}
+
+type tmMatcher struct {
+	tm             *TranslationMemory
+	source, target string
+}
+
+// BestMatch 实现 MatchSource
+func (m tmMatcher) BestMatch(source string) float64 {
+	_, score := m.tm.Fuzzy(m.source, m.target, source)
+	return score
+}
+
+// similarityBound 返回长度分别为 a, b 的两段文本相似度的上界
+func similarityBound(a, b int) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	if a == 0 {
+		return 1
+	}
+	return float64(b) / float64(a)
+}
+
+// similarity 返回 1 - 编辑距离 / 较长一方的长度
+func similarity(a, b []rune) float64 {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(n)
+}
+
+// levenshtein 计算两段文本的编辑距离, 只使用两行的空间
+func levenshtein(a, b []rune) int {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(a int, bs ...int) int {
+	for _, b := range bs {
+		if b < a {
+			a = b
+		}
+	}
+	return a
+}
+
+// WithTM 设置翻译记忆库: 翻译前先查找完全匹配 (以及 WithTMThreshold 允许的模糊匹配) 的句段,
+// 命中时不再调用翻译服务;
+// 翻译成功的句段作为新句对加入记忆库, 可以用 NewUnits 与 WriteTMX 导出
+func (t *Translator) WithTM(tm *TranslationMemory) *Translator {
+	t.tm = tm
+	return t
+}
+
+// MatchRepairer 是可以修改模糊匹配译文的翻译服务,
+// WithProvider 设置的 Provider 实现此接口时用于匹配修复
+type MatchRepairer interface {
+	// Repair 修改 matchSource 的译文 matchTarget, 使其成为 text 的译文
+	Repair(text, matchSource, matchTarget, targetLanguage string) (string, error)
+}
+
+// WithTMThreshold 设置使用模糊匹配的最低相似度, 取值范围 (0, 1];
+// 默认为 0, 即只使用完全匹配
+func (t *Translator) WithTMThreshold(threshold float64) *Translator {
+	t.tmThreshold = threshold
+	return t
+}
+
+// WithMatchRepair 设置是否修复模糊匹配: 启用时将新原文与模糊匹配一起交给翻译服务改写,
+// 否则直接使用模糊匹配的译文
+//
+// 使用默认的 Dashscope 时调用 RepairWithDashscope; 自定义的 Provider 需实现
+// MatchRepairer, 否则重新翻译. 修复失败时同样重新翻译.
+func (t *Translator) WithMatchRepair(enable bool) *Translator {
+	t.matchRepair = enable
+	return t
+}
+
+// translateFuzzy 使用不低于阈值的模糊匹配翻译 text, ok 为 false 时需要重新翻译
+func (t *Translator) translateFuzzy(text, targetLanguage string) (translated string, ok bool) {
+	if t.tmThreshold <= 0 {
+		return "", false
+	}
+	u, score := t.tm.Fuzzy(t.sourceLanguageName(), targetLanguage, text)
+	if score < t.tmThreshold {
+		return "", false
+	}
+	if !t.matchRepair {
+		t.log().Log(LogLevelDebug, "使用模糊匹配", "source", text, "match", u.Source, "score", score)
+		return u.Target, true
+	}
+	var err error
+	switch p := t.provider.(type) {
+	case nil:
+		translated, err = t.RepairWithDashscope(text, u.Source, u.Target, targetLanguage)
+	case MatchRepairer:
+		translated, err = p.Repair(text, u.Source, u.Target, targetLanguage)
+	default:
+		return "", false
+	}
+	if err != nil {
+		t.log().Log(LogLevelWarn, "修复模糊匹配失败, 将重新翻译", "source", text, "err", err)
+		return "", false
+	}
+	t.log().Log(LogLevelDebug, "修复模糊匹配", "source", text, "match", u.Source, "score", score)
+	t.tm.Add(t.sourceLanguageName(), targetLanguage, text, translated)
+	return translated, true
+}
+
+// tmx 是 TMX 1.4 文档, 只处理纯文本的句段
+type tmx struct {
+	XMLName xml.Name  `xml:"tmx"`
+	Version string    `xml:"version,attr"`
+	Header  tmxHeader `xml:"header"`
+	Units   []tmxTU   `xml:"body>tu"`
+}
+
+type tmxHeader struct {
+	CreationTool        string `xml:"creationtool,attr"`
+	CreationToolVersion string `xml:"creationtoolversion,attr"`
+	SegType             string `xml:"segtype,attr"`
+	OTmf                string `xml:"o-tmf,attr"`
+	AdminLang           string `xml:"adminlang,attr"`
+	SrcLang             string `xml:"srclang,attr"`
+	DataType            string `xml:"datatype,attr"`
+}
+
+type tmxTU struct {
+	Variants []tmxTUV `xml:"tuv"`
+}
+
+type tmxTUV struct {
+	Lang string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	// LegacyLang 是 TMX 1.1 使用的 lang 属性
+	LegacyLang string `xml:"lang,attr,omitempty"`
+	Seg        tmxSeg `xml:"seg"`
+}
+
+// tmxSeg 只保留句段中的文本, 忽略 <bpt>, <ph> 等内联标记
+type tmxSeg struct {
+	Text string `xml:",chardata"`
+}
+
+// ReadTMX 读取 TMX 文件中的句对
+//
+// 每个 <tu> 中的第一个 <tuv> 视为原文 (或以 header 中的 srclang 为原文),
+// 其余每个 <tuv> 各生成一个句对.
+func ReadTMX(r io.Reader) ([]TMUnit, error) {
+	var doc tmx
+	err := xml.NewDecoder(r).Decode(&doc)
+	if err != nil {
+		return nil, err
+	}
+	srclang := LanguageCode(doc.Header.SrcLang)
+	units := make([]TMUnit, 0, len(doc.Units))
+	for _, tu := range doc.Units {
+		if len(tu.Variants) < 2 {
+			continue
+		}
+		src := 0
+		for i, v := range tu.Variants {
+			if LanguageCode(v.lang()) == srclang {
+				src = i
+				break
+			}
+		}
+		for i, v := range tu.Variants {
+			if i == src {
+				continue
+			}
+			units = append(units, TMUnit{
+				SourceLang: LanguageCode(tu.Variants[src].lang()),
+				TargetLang: LanguageCode(v.lang()),
+				Source:     tu.Variants[src].Seg.Text,
+				Target:     v.Seg.Text,
+			})
+		}
+	}
+	return units, nil
+}
+
+func (v *tmxTUV) lang() string {
+	if v.Lang != "" {
+		return v.Lang
+	}
+	return v.LegacyLang
+}
+
+// WriteTMX 将句对写为 TMX 1.4 文件, header 中的 srclang 取第一个句对的原文语言
+func WriteTMX(w io.Writer, units []TMUnit) error {
+	doc := tmx{
+		Version: "1.4",
+		Header: tmxHeader{
+			CreationTool:        "go-docx-translate",
+			CreationToolVersion: "1",
+			SegType:             "paragraph",
+			OTmf:                "go-docx-translate",
+			AdminLang:           "en",
+			SrcLang:             "*all*",
+			DataType:            "plaintext",
+		},
+		Units: make([]tmxTU, len(units)),
+	}
+	if len(units) > 0 {
+		doc.Header.SrcLang = units[0].SourceLang
+	}
+	for i, u := range units {
+		doc.Units[i].Variants = []tmxTUV{
+			{Lang: u.SourceLang, Seg: tmxSeg{Text: u.Source}},
+			{Lang: u.TargetLang, Seg: tmxSeg{Text: u.Target}},
+		}
+	}
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(&doc)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// LoadTMX 读取 TMX 文件并将其中的句对加入记忆库, 导入的句对不计入 NewUnits
+func (tm *TranslationMemory) LoadTMX(r io.Reader) error {
+	units, err := ReadTMX(r)
+	if err != nil {
+		return err
+	}
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	for _, u := range units {
+		tm.add(u)
+	}
+	tm.added = len(tm.units)
+	return nil
+}
+
+// WriteTMX 将记忆库中所有的句对写为 TMX 文件
+func (tm *TranslationMemory) WriteTMX(w io.Writer) error {
+	return WriteTMX(w, tm.Units())
+}
@@ -0,0 +1,161 @@
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testTMX = `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="test" segtype="sentence" o-tmf="test" adminlang="en-US" srclang="zh-CN" datatype="plaintext"/>
+  <body>
+    <tu>
+      <tuv xml:lang="en-US"><seg>Terms of the contract</seg></tuv>
+      <tuv xml:lang="zh-CN"><seg>合同条款</seg></tuv>
+    </tu>
+    <tu>
+      <tuv lang="ZH-CN"><seg>付款方式</seg></tuv>
+      <tuv lang="EN-US"><seg>Payment <ph>&lt;b/&gt;</ph>method</seg></tuv>
+    </tu>
+  </body>
+</tmx>`
+
+func TestReadTMX(t *testing.T) {
+	units, err := ReadTMX(strings.NewReader(testTMX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []TMUnit{
+		{SourceLang: "zh", TargetLang: "en", Source: "合同条款", Target: "Terms of the contract"},
+		{SourceLang: "zh", TargetLang: "en", Source: "付款方式", Target: "Payment method"},
+	}
+	if len(units) != len(want) {
+		t.Fatalf("unexpected units: %+v", units)
+	}
+	for i := range want {
+		if units[i] != want[i] {
+			t.Fatalf("unit %d: got %+v, want %+v", i, units[i], want[i])
+		}
+	}
+}
+
+func TestTranslationMemory(t *testing.T) {
+	tm := NewTranslationMemory()
+	if err := tm.LoadTMX(strings.NewReader(testTMX)); err != nil {
+		t.Fatal(err)
+	}
+	if s, ok := tm.Lookup("中文", "English", " 合同条款 "); !ok || s != "Terms of the contract" {
+		t.Fatal("unexpected lookup:", s, ok)
+	}
+	if _, ok := tm.Lookup("中文", "Japanese", "合同条款"); ok {
+		t.Fatal("language pair must be part of the lookup")
+	}
+	u, score := tm.Fuzzy("zh", "en", "合同条款一")
+	if u.Source != "合同条款" || score != 0.8 {
+		t.Fatal("unexpected fuzzy match:", u, score)
+	}
+	if a := NewTranslator("", "").AnalyzeDocx(newTestDoc("合同条款", "付款方式一"), tm.Matcher("中文", "English")); a.Bands[MatchBandExact].Segments != 1 || a.Bands[MatchBand75].Segments != 1 {
+		t.Fatalf("unexpected analysis: %+v", a.Bands)
+	}
+
+	calls := 0
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		calls++
+		return strings.ToUpper(text), nil
+	})
+	newDoc, err := NewTranslator("", "").WithProvider(p).WithTM(tm).
+		TranslateDocx(newTestDoc("合同条款", "new text"), "en-US")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Fatal("expected the TM hit to skip the provider, calls:", calls)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "Terms of the contract" {
+		t.Fatal("unexpected translation:", s)
+	}
+	if nu := tm.NewUnits(); len(nu) != 1 || nu[0] != (TMUnit{SourceLang: "zh", TargetLang: "en", Source: "new text", Target: "NEW TEXT"}) {
+		t.Fatalf("unexpected new units: %+v", nu)
+	}
+
+	var buf bytes.Buffer
+	if err = tm.WriteTMX(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), `<tuv xml:lang="zh">`) {
+		t.Fatal("expected xml:lang attributes:", buf.String())
+	}
+	units, err := ReadTMX(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(units) != 3 || units[2].Target != "NEW TEXT" {
+		t.Fatalf("unexpected round trip: %+v", units)
+	}
+}
+
+type repairProvider struct {
+	ProviderFunc
+	repairs int
+}
+
+func (p *repairProvider) Repair(text, matchSource, matchTarget, _ string) (string, error) {
+	p.repairs++
+	return matchTarget + " (" + text + ")", nil
+}
+
+func TestTranslationMemoryFuzzy(t *testing.T) {
+	tm := NewTranslationMemory()
+	if err := tm.LoadTMX(strings.NewReader(testTMX)); err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	upper := ProviderFunc(func(text, _ string) (string, error) {
+		calls++
+		return strings.ToUpper(text), nil
+	})
+	doc := newTestDoc("合同条款一", "付款")
+
+	// 付款 与 付款方式 的相似度只有 0.5, 低于阈值
+	newDoc, err := NewTranslator("", "").WithProvider(upper).WithTM(tm).WithTMThreshold(0.75).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "Terms of the contract" || calls != 1 {
+		t.Fatal("expected the fuzzy match to be used as is:", s, calls)
+	}
+
+	p := &repairProvider{ProviderFunc: upper}
+	newDoc, err = NewTranslator("", "").WithProvider(p).WithTM(tm).WithTMThreshold(0.75).WithMatchRepair(true).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "Terms of the contract (合同条款一)" || p.repairs != 1 {
+		t.Fatal("unexpected repair:", s, p.repairs)
+	}
+	if s, ok := tm.Lookup("zh", "en", "合同条款一"); !ok || s != "Terms of the contract (合同条款一)" {
+		t.Fatal("expected the repaired match to be added to the TM, got", s, ok)
+	}
+
+	// 不支持修复的 Provider 退回到重新翻译
+	calls = 0
+	newDoc, err = NewTranslator("", "").WithProvider(upper).WithTM(NewTranslationMemory()).WithTMThreshold(0.5).WithMatchRepair(true).
+		TranslateDocx(newTestDoc("abc", "abd"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[1].(*Paragraph).String(); s != "ABD" || calls != 2 {
+		t.Fatal("expected a fresh translation:", s, calls)
+	}
+
+	// 默认的 Dashscope 使用 RepairWithDashscope
+	newDoc, err = newTestTranslator(t).WithTM(tm).WithTMThreshold(0.75).WithMatchRepair(true).
+		TranslateDocx(newTestDoc("付款方式二"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); !strings.Contains(s, "旧译文: PAYMENT METHOD") {
+		t.Fatal("unexpected dashscope repair request:", s)
+	}
+}
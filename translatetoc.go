@@ -0,0 +1,149 @@
+package docx
+
+import "strings"
+
+// WithTOCEntries 设置是否翻译目录域中缓存的条目文字, 默认不翻译
+//
+// 无论是否翻译, 目录域的指令都原样保留, 并被标记为需要更新 (w:dirty),
+// Word 打开文档时会按译文的标题重新生成目录; 条目中的页码等嵌套的域不翻译.
+func (t *Translator) WithTOCEntries(translate bool) *Translator {
+	t.tocEntries = translate
+	return t
+}
+
+// fieldFrame 是遍历段落时尚未结束的域
+type fieldFrame struct {
+	begin *FldChar
+	instr strings.Builder
+	sep   bool // sep 表示已经过了 separate, 之后是域结果
+}
+
+// toc 判断域是否为目录
+func (f *fieldFrame) toc() bool {
+	return fieldKind(f.instr.String()) == "TOC"
+}
+
+// fieldStack 是遍历段落时尚未结束的域, 可以跨越段落
+type fieldStack []*fieldFrame
+
+// step 处理段落中的一项内容, 见 walkRunContent
+func (s *fieldStack) step(child interface{}) {
+	n := len(*s)
+	switch o := child.(type) {
+	case *FldChar:
+		switch o.FldCharType {
+		case "begin":
+			*s = append(*s, &fieldFrame{begin: o})
+		case "separate":
+			if n > 0 {
+				(*s)[n-1].sep = true
+			}
+		case "end":
+			if n > 0 {
+				*s = (*s)[:n-1]
+			}
+		}
+	case *InstrText:
+		if n > 0 && !(*s)[n-1].sep {
+			(*s)[n-1].instr.WriteString(o.Text)
+		}
+	}
+}
+
+// inTOC 判断当前是否位于目录域中
+func (s fieldStack) inTOC() bool {
+	for _, f := range s {
+		if f.toc() {
+			return true
+		}
+	}
+	return false
+}
+
+// walkRunContent 按文档顺序遍历段落中 Run 与超链接的内容, Run.InstrText 作为 *InstrText 传给 fn
+func walkRunContent(p *Paragraph, fn func(child interface{})) {
+	walk := func(r *Run) {
+		if r.InstrText != "" {
+			fn(&InstrText{Text: r.InstrText})
+		}
+		for _, child := range r.Children {
+			fn(child)
+		}
+	}
+	for _, child := range p.Children {
+		switch o := child.(type) {
+		case *Run:
+			walk(o)
+		case *Hyperlink:
+			walk(&o.Run)
+		}
+	}
+}
+
+// tocParagraphs 返回文档中位于目录域中的段落, 包括目录域开始与结束的段落
+func tocParagraphs(doc *Docx) map[*Paragraph]bool {
+	var (
+		stack fieldStack
+		paras map[*Paragraph]bool
+	)
+	visit := func(p *Paragraph) error {
+		in := stack.inTOC()
+		walkRunContent(p, func(child interface{}) {
+			stack.step(child)
+			in = in || stack.inTOC()
+		})
+		if in {
+			if paras == nil {
+				paras = make(map[*Paragraph]bool)
+			}
+			paras[p] = true
+		}
+		return nil
+	}
+	_ = doc.Document.Body.RangeParagraphs(visit)
+	return paras
+}
+
+// tocParagraph 返回目录段落中条目的文本节点 (不包括嵌套的域中的页码等), 以及在段落中开始的目录域的 begin 标记
+func tocParagraph(p *Paragraph) (texts []*Text, begins []*FldChar) {
+	var stack fieldStack
+	walkRunContent(p, func(child interface{}) {
+		switch o := child.(type) {
+		case *Text:
+			for _, f := range stack {
+				if !f.sep || !f.toc() {
+					return
+				}
+			}
+			texts = append(texts, o)
+		case *FldChar:
+			if n := len(stack); n > 0 && o.FldCharType == "separate" && stack[n-1].toc() {
+				begins = append(begins, stack[n-1].begin)
+			}
+		}
+		stack.step(child)
+	})
+	return texts, begins
+}
+
+// tocSegment 返回目录段落 p 中翻译条目文字的翻译单元, 未开启 WithTOCEntries 或没有文字时返回 nil;
+// dst 是 p 在新文档中的副本, 原地翻译时就是 p. mark 将 dst 中开始的目录域标记为需要更新
+func (t *Translator) tocSegment(p, dst *Paragraph) (sg *segment, mark func()) {
+	texts, begins := tocParagraph(dst)
+	mark = func() {
+		for _, begin := range begins {
+			begin.Dirty = "true"
+		}
+	}
+	if !t.tocEntries {
+		return nil, mark
+	}
+	var sb strings.Builder
+	for _, text := range texts {
+		sb.WriteString(text.Text)
+	}
+	if strings.TrimSpace(sb.String()) == "" {
+		return nil, mark
+	}
+	return &segment{src: p, dst: dst, text: sb.String(), hint: t.styleHint(p), texts: texts, inPlace: true}, mark
+}
@@ -0,0 +1,112 @@
+package docx
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// tocEntryXML 返回一个目录条目的超链接
+func tocEntryXML(anchor, title, page string) string {
+	return `<w:hyperlink w:anchor="` + anchor + `"><w:r><w:t>` + title + `</w:t></w:r><w:r><w:tab/></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> PAGEREF ` + anchor + ` \h </w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r><w:r><w:t>` + page + `</w:t></w:r><w:r><w:fldChar w:fldCharType="end"/></w:r></w:hyperlink>`
+}
+
+// newTOCDocx 返回一个含有两项目录的文档
+func newTOCDocx(t *testing.T) []byte {
+	t.Helper()
+	body := `<w:p><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText xml:space="preserve"> TOC \o "1-3" \h </w:instrText></w:r>` +
+		`<w:r><w:fldChar w:fldCharType="separate"/></w:r>` + tocEntryXML("_Toc1", "第一章 总则", "1") + `</w:p>` +
+		`<w:p>` + tocEntryXML("_Toc2", "第二章 定义", "2") + `<w:r><w:fldChar w:fldCharType="end"/></w:r></w:p>` +
+		`<w:p><w:r><w:t>第一章 总则</w:t></w:r></w:p>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+}
+
+func TestTranslateDocxTOC(t *testing.T) {
+	data := newTOCDocx(t)
+	words := strings.NewReplacer("第一章 总则", "Chapter 1 General", "第二章 定义", "Chapter 2 Definitions")
+	var sources []string
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		sources = append(sources, text)
+		return words.Replace(text), nil
+	}))
+	translate := func(tr *Translator) string {
+		t.Helper()
+		doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		newDoc, err := tr.TranslateDocx(doc, "English")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if _, err := newDoc.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return readZip(t, buf.Bytes())["word/document.xml"]
+	}
+	checkFields := func(xml string) {
+		t.Helper()
+		if !strings.Contains(xml, `<w:fldChar w:fldCharType="begin" w:dirty="true"></w:fldChar></w:r><w:r><w:instrText> TOC \o &#34;1-3&#34; \h </w:instrText>`) {
+			t.Error("TOC field was not kept or marked dirty:", xml)
+		}
+		if strings.Count(xml, `w:dirty="true"`) != 1 {
+			t.Error("only the TOC field should be marked dirty:", xml)
+		}
+		for _, anchor := range []string{"_Toc1", "_Toc2"} {
+			if !strings.Contains(xml, `<w:tab></w:tab><w:fldChar w:fldCharType="begin"></w:fldChar><w:instrText xml:space="preserve"> PAGEREF `+anchor+` \h </w:instrText><w:fldChar w:fldCharType="separate"></w:fldChar>`) {
+				t.Errorf("PAGEREF %s was not kept in order: %s", anchor, xml)
+			}
+		}
+	}
+
+	// 默认只翻译正文, 条目原样保留
+	xml := translate(tr)
+	checkFields(xml)
+	if len(sources) != 1 || sources[0] != "第一章 总则" {
+		t.Fatalf("unexpected sources: %q", sources)
+	}
+	if strings.Count(xml, "第一章 总则") != 1 || !strings.Contains(xml, "第二章 定义") {
+		t.Fatal("TOC entries should be kept:", xml)
+	}
+
+	// 开启 WithTOCEntries 后翻译条目文字, 页码不变
+	sources = nil
+	xml = translate(tr.WithTOCEntries(true))
+	checkFields(xml)
+	if len(sources) != 2 || sources[1] != "第二章 定义" {
+		t.Fatalf("unexpected sources: %q", sources)
+	}
+	if strings.Contains(xml, "第") || strings.Count(xml, "Chapter 1 General") != 2 || !strings.Contains(xml, "<w:t>2</w:t>") {
+		t.Fatal("TOC entries were not translated:", xml)
+	}
+
+	// 原地翻译同样标记目录需要更新
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.TranslateDocxInPlace(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml = readZip(t, buf.Bytes())["word/document.xml"]
+	checkFields(xml)
+	if strings.Contains(xml, "第") {
+		t.Fatal("TOC entries were not translated in place:", xml)
+	}
+}
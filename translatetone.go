@@ -0,0 +1,57 @@
+package docx
+
+// Tone 是译文的语体
+type Tone uint8
+
+const (
+	// ToneDefault 不对语体提出要求 (默认)
+	ToneDefault Tone = iota
+	// ToneFormal 正式、礼貌的书面语, 适用于商务往来与公文
+	ToneFormal
+	// ToneInformal 轻松、口语化的表达
+	ToneInformal
+	// ToneMarketing 生动、有感染力的宣传文案
+	ToneMarketing
+	// ToneLegal 严谨、准确的法律文书用语
+	ToneLegal
+)
+
+func (t Tone) String() string {
+	switch t {
+	case ToneDefault:
+		return "default"
+	case ToneFormal:
+		return "formal"
+	case ToneInformal:
+		return "informal"
+	case ToneMarketing:
+		return "marketing"
+	case ToneLegal:
+		return "legal"
+	}
+	return "unknown"
+}
+
+// hint 返回语体对应的翻译要求
+func (t Tone) hint() string {
+	switch t {
+	case ToneFormal:
+		return "译文使用正式、礼貌的书面语, 在目标语言区分尊称时使用尊称 (如德语的 Sie, 日语的敬语)."
+	case ToneInformal:
+		return "译文使用轻松、自然的口语化表达, 在目标语言区分尊称时使用非正式的称呼 (如德语的 du)."
+	case ToneMarketing:
+		return "这是一份宣传材料: 译文要生动、有感染力, 符合目标语言的广告文案习惯, 可以适当意译, 但不能改变产品信息与数字."
+	case ToneLegal:
+		return "这是一份法律文书: 译文要严谨、准确, 使用目标语言规范的法律术语, 逐句忠实翻译, 不要意译或省略, 保持条款编号与定义词前后一致."
+	}
+	return ""
+}
+
+// WithTone 设置译文的语体, 如合同使用 ToneLegal, 宣传册使用 ToneMarketing; 默认为 ToneDefault
+//
+// 语体要求会附加在 Dashscope 的系统提示词与审校要求之后, 或交给实现了 HintedProvider 的 Provider.
+// 语体不同的译文在缓存中互不影响.
+func (t *Translator) WithTone(tone Tone) *Translator {
+	t.tone = tone
+	return t
+}
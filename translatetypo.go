@@ -0,0 +1,135 @@
+package docx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Typography 是目标语言的引号与排版习惯, 见 WithTypography
+type Typography struct {
+	// Quotes 是左右双引号, 替换译文中的直双引号与弯双引号 (“ ”), 为空时不替换;
+	// 直双引号与 “ 在没有未闭合的左引号 (包括 „ 与 «) 时视为左引号, 否则视为右引号, 因此德文的 „…“ 保持不变.
+	// 左引号以空白 (如不换行空格) 结尾、右引号以空白开头时, 删除引号内侧原有的空格
+	Quotes [2]string
+	// Apostrophe 替换两个字母之间的直撇号, 如 "l'homme" 中的 "'", 为空时不替换
+	Apostrophe string
+	// SpaceBefore 中的标点 (如法文的 ";:!?") 之前放入不换行空格, 原有的空格换为不换行空格;
+	// 只处理之后是空格、文本结尾或标点的情况, 避免改动 "12:30" 与网址
+	SpaceBefore string
+}
+
+// DefaultTypography 是常见目标语言 (ISO 639-1 代码) 的引号与排版习惯
+var DefaultTypography = map[string]Typography{
+	"en": {Quotes: [2]string{"“", "”"}, Apostrophe: "’"},
+	"fr": {Quotes: [2]string{"«\u00a0", "\u00a0»"}, Apostrophe: "’", SpaceBefore: ";:!?"},
+	"de": {Quotes: [2]string{"„", "“"}, Apostrophe: "’"},
+	"es": {Quotes: [2]string{"«", "»"}, Apostrophe: "’"},
+	"it": {Quotes: [2]string{"«", "»"}, Apostrophe: "’"},
+	"pt": {Quotes: [2]string{"“", "”"}, Apostrophe: "’"},
+	"nl": {Quotes: [2]string{"“", "”"}, Apostrophe: "’"},
+	"pl": {Quotes: [2]string{"„", "”"}, Apostrophe: "’"},
+	"ru": {Quotes: [2]string{"«", "»"}, Apostrophe: "’"},
+}
+
+// WithTypography 在翻译完成后 (WithPunctuation 之后) 按目标语言的习惯替换译文中的引号、撇号与标点前的空格,
+// rules 的键是 ISO 639-1 代码, 可以直接使用 DefaultTypography; 默认不做处理
+//
+// 不翻译列表中的内容保持原样.
+func (t *Translator) WithTypography(rules map[string]Typography) *Translator {
+	t.typography = rules
+	return t
+}
+
+// applyTypography 对译文应用目标语言的排版习惯
+func (t *Translator) applyTypography(translated, targetLanguage string) string {
+	ty, ok := t.typography[LanguageCode(targetLanguage)]
+	if !ok {
+		return translated
+	}
+	masked, originals := t.mask(translated)
+	s, err := unmask(ApplyTypography(masked, ty), originals)
+	if err != nil { // 不会发生: 替换不会改动占位符
+		return translated
+	}
+	return s
+}
+
+// ApplyTypography 对 s 应用排版习惯 ty
+func ApplyTypography(s string, ty Typography) string {
+	if ty.Quotes == [2]string{} && ty.Apostrophe == "" && ty.SpaceBefore == "" {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	quoted := false // quoted 表示已有未闭合的左引号
+	for i, w := 0, 0; i < len(s); i += w {
+		var r rune
+		r, w = utf8.DecodeRuneInString(s[i:])
+		out := sb.String()
+		switch {
+		case ty.Quotes != [2]string{} && (r == '"' || r == '“' || r == '”'):
+			left := r != '”' && !quoted
+			quoted = left
+			if left {
+				sb.WriteString(ty.Quotes[0])
+				if q, _ := utf8.DecodeLastRuneInString(ty.Quotes[0]); unicode.IsSpace(q) {
+					w += len(s[i+w:]) - len(strings.TrimLeft(s[i+w:], " "))
+				}
+				continue
+			}
+			if q, _ := utf8.DecodeRuneInString(ty.Quotes[1]); unicode.IsSpace(q) {
+				trimmed := strings.TrimRight(out, " ")
+				if trimmed != out {
+					sb.Reset()
+					sb.WriteString(trimmed)
+				}
+			}
+			sb.WriteString(ty.Quotes[1])
+		case ty.Apostrophe != "" && r == '\'':
+			next, _ := utf8.DecodeRuneInString(s[i+w:])
+			if unicode.IsLetter(precedingRune(out)) && unicode.IsLetter(next) {
+				sb.WriteString(ty.Apostrophe)
+			} else {
+				sb.WriteRune(r)
+			}
+		case strings.ContainsRune(ty.SpaceBefore, r):
+			next, _ := utf8.DecodeRuneInString(s[i+w:])
+			if i+w < len(s) && !unicode.IsSpace(next) && !strings.ContainsRune(ty.SpaceBefore, next) && !unicode.In(next, unicode.Pe, unicode.Pf) {
+				sb.WriteRune(r)
+				continue
+			}
+			trimmed := strings.TrimRightFunc(out, unicode.IsSpace)
+			if prev := precedingRune(trimmed); prev != utf8.RuneError && !strings.ContainsRune(ty.SpaceBefore, prev) && !isOpening(prev) {
+				trimmed += "\u00a0"
+			}
+			if trimmed != out {
+				sb.Reset()
+				sb.WriteString(trimmed)
+			}
+			sb.WriteRune(r)
+		default:
+			switch r {
+			case '„', '«':
+				quoted = true
+			case '»':
+				quoted = false
+			}
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// precedingRune 返回 s 中跳过末尾超链接等的标记 (⟪n⟫) 后的最后一个字符, 没有时返回 utf8.RuneError
+func precedingRune(s string) rune {
+	for strings.HasSuffix(s, "⟫") {
+		j := strings.LastIndex(s, "⟪")
+		if j < 0 {
+			break
+		}
+		s = s[:j]
+	}
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
@@ -0,0 +1,43 @@
+package docx
+
+import "testing"
+
+func TestApplyTypography(t *testing.T) {
+	for _, c := range []struct {
+		lang, in, want string
+	}{
+		{"fr", `Il a dit "bonjour" : c'est l'heure !`, "Il a dit «\u00a0bonjour\u00a0»\u00a0: c’est l’heure\u00a0!"},
+		{"fr", `Vraiment?! Voir "  note ". À 12:30, https://example.com`, "Vraiment\u00a0?! Voir «\u00a0note\u00a0». À 12:30, https://example.com"},
+		{"fr", "⟪1⟫Quoi⟪/1⟫?", "⟪1⟫Quoi⟪/1⟫\u00a0?"},
+		{"de", `Er sagte "Hallo" und „ging“. Peter's Buch`, "Er sagte „Hallo“ und „ging“. Peter’s Buch"},
+		{"de", "Er sagte “Hallo”.", "Er sagte „Hallo“."},
+		{"en", `"It's" 'quoted' (“fine”)`, "“It’s” 'quoted' (“fine”)"},
+	} {
+		if got := ApplyTypography(c.in, DefaultTypography[c.lang]); got != c.want {
+			t.Errorf("%s %q: got %q, want %q", c.lang, c.in, got, c.want)
+		}
+	}
+}
+
+func TestTranslateDocxTypography(t *testing.T) {
+	doc := New().WithDefaultTheme()
+	doc.AddParagraph().AddText("你好")
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		return `Voir "Go-Docx" : c'est "simple"!`, nil
+	})
+	tr := NewTranslator("", "").WithProvider(p).WithDNT(NewDNTList(`"Go-Docx"`)).WithTypography(DefaultTypography)
+	newDoc, err := tr.TranslateDocx(doc, "French")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "Voir \"Go-Docx\"\u00a0: c’est «\u00a0simple\u00a0»\u00a0!" {
+		t.Fatalf("unexpected typography: %q", s)
+	}
+	newDoc, err = tr.TranslateDocx(doc, "Korean")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != `Voir "Go-Docx" : c'est "simple"!` {
+		t.Fatal("languages without a rule must be kept:", s)
+	}
+}
@@ -0,0 +1,137 @@
+package docx
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// ErrStructureMismatch 旧译文的结构与旧原文不一致, 通常是译文中增删了段落或表格
+var ErrStructureMismatch = errors.New("translated document does not match its source")
+
+// UpdateDocx 增量翻译修改过的文档: 原文没有变化的段落直接沿用旧译文 oldTranslated
+// 中对应的段落 (包括其中人工修改过的内容与格式), 只翻译新增或修改过的段落, 并拼接为新的译文
+//
+// oldTranslated 必须是由 oldSource 翻译得到的, 否则返回 ErrStructureMismatch;
+// 两者都为 nil 时只依靠 WithTM 设置的翻译记忆复用旧译文. 其余行为同 TranslateDocxContext.
+func (t *Translator) UpdateDocx(ctx context.Context, oldSource, oldTranslated, newSource *Docx, targetLanguage string) (*Docx, error) {
+	if oldSource == nil || oldTranslated == nil {
+		return t.TranslateDocxContext(ctx, newSource, targetLanguage)
+	}
+	previous, err := pairParagraphs(oldSource, oldTranslated)
+	if err != nil {
+		return nil, err
+	}
+	c := *t
+	c.previous = previous
+	return c.TranslateDocxContext(ctx, newSource, targetLanguage)
+}
+
+// pairParagraphs 按 prepare 的遍历顺序同时遍历原文与译文, 返回原文段落的文本到译文段落的映射,
+// 相同的原文取第一个
+func pairParagraphs(source, translated *Docx) (map[string]*Paragraph, error) {
+	previous := make(map[string]*Paragraph)
+	pair := func(src, dst *Paragraph) {
+		text := paragraphText(src)
+		if strings.TrimSpace(text) == "" {
+			return
+		}
+		if _, ok := previous[text]; !ok {
+			previous[text] = dst
+		}
+	}
+	var pairTable func(src, dst *Table) error
+	pairTable = func(src, dst *Table) error {
+		if len(src.TableRows) != len(dst.TableRows) {
+			return ErrStructureMismatch
+		}
+		for i, row := range src.TableRows {
+			if len(row.TableCells) != len(dst.TableRows[i].TableCells) {
+				return ErrStructureMismatch
+			}
+			for k, cell := range row.TableCells {
+				dcell := dst.TableRows[i].TableCells[k]
+				if len(cell.Paragraphs) > len(dcell.Paragraphs) {
+					return ErrStructureMismatch
+				}
+				for n, p := range cell.Paragraphs {
+					pair(p, dcell.Paragraphs[n])
+				}
+				var tables []*Table
+				for _, tbl := range cell.Tables {
+					if len(tbl.TableRows) > 0 {
+						tables = append(tables, tbl)
+					}
+				}
+				if len(tables) != len(dcell.Tables) {
+					return ErrStructureMismatch
+				}
+				for n, tbl := range tables {
+					if err := pairTable(tbl, dcell.Tables[n]); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	}
+
+	// 与 prepare 一样只保留段落与有行的表格
+	items := func(doc *Docx) []interface{} {
+		var its []interface{}
+		for _, item := range doc.Document.Body.Items {
+			switch o := item.(type) {
+			case *Paragraph:
+				its = append(its, o)
+			case *Table:
+				if len(o.TableRows) > 0 {
+					its = append(its, o)
+				}
+			}
+		}
+		return its
+	}
+	srcItems, dstItems := items(source), items(translated)
+	if len(srcItems) != len(dstItems) {
+		return nil, ErrStructureMismatch
+	}
+	for i, item := range srcItems {
+		switch o := item.(type) {
+		case *Paragraph:
+			p, ok := dstItems[i].(*Paragraph)
+			if !ok {
+				return nil, ErrStructureMismatch
+			}
+			pair(o, p)
+		case *Table:
+			tbl, ok := dstItems[i].(*Table)
+			if !ok {
+				return nil, ErrStructureMismatch
+			}
+			if err := pairTable(o, tbl); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return previous, nil
+}
+
+// previousParagraph 返回 UpdateDocx 为翻译单元找到的旧译文段落
+func (t *Translator) previousParagraph(sg *segment) (*Paragraph, bool) {
+	if t.previous == nil || sg.routed || sg.set != nil {
+		return nil, false
+	}
+	prev, ok := t.previous[paragraphText(sg.src)]
+	return prev, ok
+}
+
+// reusePrevious 将 UpdateDocx 找到的旧译文放入翻译单元的新段落, 返回旧译文的文本
+func (t *Translator) reusePrevious(sg *segment) (string, bool) {
+	prev, ok := t.previousParagraph(sg)
+	if !ok {
+		return "", false
+	}
+	np := prev.copymedia(sg.dst.file)
+	sg.dst.Children = np.Children
+	return prev.String(), true
+}
@@ -0,0 +1,53 @@
+package docx
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUpdateDocx(t *testing.T) {
+	var seen []string
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		seen = append(seen, text)
+		return strings.ToUpper(text), nil
+	})
+	tr := NewTranslator("", "").WithProvider(p)
+	oldSource := newTestDoc("one", "two", "three")
+	tbl := oldSource.AddTable(1, 1, 0, nil)
+	tbl.TableRows[0].TableCells[0].AddParagraph().AddText("cell")
+	oldTranslated, err := tr.TranslateDocx(oldSource, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 人工修改过的旧译文应被沿用
+	oldTranslated.Document.Body.Items[0].(*Paragraph).Children[0].(*Run).Children[0].(*Text).Text = "One (edited)"
+
+	newSource := newTestDoc("one", "two changed", "three", "four")
+	tbl = newSource.AddTable(1, 1, 0, nil)
+	tbl.TableRows[0].TableCells[0].AddParagraph().AddText("cell")
+	seen = nil
+	newDoc, err := tr.UpdateDocx(context.Background(), oldSource, oldTranslated, newSource, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(seen, "|") != "two changed|four" {
+		t.Fatalf("unexpected provider input: %q", seen)
+	}
+	var got []string
+	for _, item := range newDoc.Document.Body.Items[:4] {
+		got = append(got, item.(*Paragraph).String())
+	}
+	if strings.Join(got, "|") != "One (edited)|TWO CHANGED|THREE|FOUR" {
+		t.Fatalf("unexpected paragraphs: %q", got)
+	}
+	if s := newDoc.Document.Body.Items[4].(*Table).TableRows[0].TableCells[0].Paragraphs[0].String(); s != "CELL" {
+		t.Fatal("unexpected cell:", s)
+	}
+
+	oldTranslated.Document.Body.Items = oldTranslated.Document.Body.Items[1:]
+	if _, err = tr.UpdateDocx(context.Background(), oldSource, oldTranslated, newSource, "English"); !errors.Is(err, ErrStructureMismatch) {
+		t.Fatal("expected ErrStructureMismatch, got", err)
+	}
+}
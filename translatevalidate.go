@@ -0,0 +1,254 @@
+package docx
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidationIssue 是 Validate 发现的一个问题
+type ValidationIssue struct {
+	Part    string // Part 是出问题的文件, 如 word/_rels/document.xml.rels
+	Message string // Message 描述问题
+}
+
+func (e *ValidationIssue) Error() string {
+	return e.Part + ": " + e.Message
+}
+
+// ValidationErrors 是 Validate 发现的所有问题
+type ValidationErrors []*ValidationIssue
+
+func (e ValidationErrors) Error() string {
+	sb := strings.Builder{}
+	sb.WriteString(strconv.Itoa(len(e)))
+	sb.WriteString(" 个文档结构问题")
+	for _, issue := range e {
+		sb.WriteString("\n\t")
+		sb.WriteString(issue.Error())
+	}
+	return sb.String()
+}
+
+// Validate 检查即将写出的 docx 包是否符合 WordprocessingML 的基本要求, 没有问题时返回 nil, 否则返回 ValidationErrors:
+//
+//  1. 所有 XML 部件与关系文件都是格式正确的 XML
+//  2. 关系的 ID 不重复, 内部关系的目标文件存在于包中
+//  3. 部件中引用的关系 ID (如 r:id、r:embed) 在该部件的关系中有定义
+//  4. 除 [Content_Types].xml 外的每个文件都在 [Content_Types].xml 中登记了类型
+//
+// 这些问题会使 Word 打开文档时提示修复; Validate 需要序列化整个文档, 开销与 WriteTo 相当.
+func (f *Docx) Validate() error {
+	files, err := f.packageFiles()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var issues ValidationErrors
+	report := func(part, msg string) {
+		issues = append(issues, &ValidationIssue{Part: part, Message: msg})
+	}
+
+	// 格式正确的 XML, 同时收集每个部件引用的关系 ID
+	refs := make(map[string][]string, len(names))
+	wellFormed := make(map[string]bool, len(names))
+	for _, name := range names {
+		if !isXMLPart(name) {
+			continue
+		}
+		ids, err := scanXML(files[name])
+		if err != nil {
+			report(name, "malformed XML: "+err.Error())
+			continue
+		}
+		refs[name] = ids
+		wellFormed[name] = true
+	}
+
+	// 关系
+	for _, name := range names {
+		if path.Ext(name) != ".rels" || !wellFormed[name] {
+			continue
+		}
+		source := relsSource(name)
+		var rels Relationships
+		if err := xml.Unmarshal(files[name], &rels); err != nil {
+			report(name, "invalid relationships: "+err.Error())
+			continue
+		}
+		defined := make(map[string]struct{}, len(rels.Relationship))
+		for _, r := range rels.Relationship {
+			if _, ok := defined[r.ID]; ok {
+				report(name, "duplicate relationship ID "+r.ID)
+			}
+			defined[r.ID] = struct{}{}
+			if r.TargetMode == REL_TARGETMODE {
+				continue
+			}
+			target := resolveTarget(path.Dir(source), r.Target)
+			if _, ok := files[target]; !ok {
+				report(name, "relationship "+r.ID+" points to missing part "+target)
+			}
+		}
+		for _, id := range refs[source] {
+			if _, ok := defined[id]; !ok {
+				report(source, "reference to undefined relationship "+id)
+			}
+		}
+		delete(refs, source)
+	}
+	for _, name := range names {
+		if ids := refs[name]; len(ids) > 0 && path.Ext(name) != ".rels" {
+			report(name, "reference to undefined relationship "+ids[0]+": part has no relationships")
+		}
+	}
+
+	// 内容类型
+	var types contentTypes
+	if data, ok := files["[Content_Types].xml"]; !ok {
+		report("[Content_Types].xml", "missing")
+	} else if err := xml.Unmarshal(data, &types); err != nil {
+		report("[Content_Types].xml", "invalid content types: "+err.Error())
+	} else {
+		overrides := make(map[string]struct{}, len(types.Overrides))
+		for _, o := range types.Overrides {
+			overrides[strings.ToLower(o.PartName)] = struct{}{}
+		}
+		defaults := make(map[string]struct{}, len(types.Defaults))
+		for _, d := range types.Defaults {
+			defaults[strings.ToLower(d.Extension)] = struct{}{}
+		}
+		for _, name := range names {
+			if name == "[Content_Types].xml" {
+				continue
+			}
+			if _, ok := overrides[strings.ToLower("/"+name)]; ok {
+				continue
+			}
+			if _, ok := defaults[strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))]; ok {
+				continue
+			}
+			report(name, "no content type")
+		}
+	}
+
+	if len(issues) == 0 {
+		return nil
+	}
+	return issues
+}
+
+// WithValidation 设置 TranslateFile 在写出 docx 译文之前调用 Validate, 有问题时不写出并返回 ValidationErrors;
+// 默认不检查
+func (t *Translator) WithValidation(enabled bool) *Translator {
+	t.validate = enabled
+	return t
+}
+
+// packageFiles 返回 WriteTo 写入包中的所有文件, 键为文件名
+func (f *Docx) packageFiles() (map[string][]byte, error) {
+	files := make(map[string][]byte, len(f.tmpfslst)+len(f.parts)+len(f.media)+2)
+	for _, name := range f.tmpfslst {
+		if strings.HasSuffix(name, "/") {
+			continue // 目录
+		}
+		data, err := f.readPart(name)
+		if err != nil {
+			return nil, err
+		}
+		files[name] = data
+	}
+	for name, data := range f.parts {
+		files[name] = data
+	}
+	for name, v := range map[string]interface{}{
+		"word/_rels/document.xml.rels": &f.docRelation,
+		"word/document.xml":            &f.Document,
+	} {
+		var buf bytes.Buffer
+		if _, err := (marshaller{data: v}).WriteTo(&buf); err != nil {
+			return nil, err
+		}
+		files[name] = buf.Bytes()
+	}
+	f.mediaMu.RLock()
+	for i := range f.media {
+		files[f.media[i].String()] = f.media[i].Data
+	}
+	f.mediaMu.RUnlock()
+	return files, nil
+}
+
+// isXMLPart 判断文件 name 是否为 XML
+func isXMLPart(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".xml", ".rels", ".vml":
+		return true
+	}
+	return false
+}
+
+// scanXML 检查 data 是否为格式正确的 XML, 返回其中引用的关系 ID (关系命名空间中的属性值)
+func scanXML(data []byte) ([]string, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var ids []string
+	seen := make(map[string]struct{})
+	root := false
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		root = true
+		for _, a := range se.Attr {
+			if a.Name.Space != XMLNS_R || a.Value == "" {
+				continue
+			}
+			if _, ok := seen[a.Value]; !ok {
+				seen[a.Value] = struct{}{}
+				ids = append(ids, a.Value)
+			}
+		}
+	}
+	if !root {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return ids, nil
+}
+
+// relsSource 返回关系文件 name 所属的部件, 包的关系 _rels/.rels 属于根目录
+func relsSource(name string) string {
+	dir, base := path.Split(name)
+	return path.Join(path.Dir(strings.TrimSuffix(dir, "/")), strings.TrimSuffix(base, ".rels"))
+}
+
+// resolveTarget 返回目录 dir 中的部件的关系目标 target 在包中的文件名
+func resolveTarget(dir, target string) string {
+	if t, err := url.PathUnescape(target); err == nil {
+		target = t
+	}
+	if i := strings.IndexByte(target, '#'); i >= 0 {
+		target = target[:i]
+	}
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(path.Clean(target), "/")
+	}
+	return strings.TrimPrefix(path.Join(dir, target), "/")
+}
@@ -0,0 +1,55 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	doc := newTestDoc("hello")
+	if _, err := doc.AddParagraph().AddInlineDrawingFrom("testdata/fumiama.JPG"); err != nil {
+		t.Fatal(err)
+	}
+	doc.AddParagraph().AddLink("link", "https://example.com")
+	if err := doc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	doc.docRelation.Relationship = append(doc.docRelation.Relationship, Relationship{
+		ID: "rId1", Type: relStyles, Target: "missing.xml",
+	})
+	doc.setPart("word/extra.bin", []byte{0})
+	doc.setPart("word/broken.xml", []byte("<a><b></a>"))
+	err := doc.Validate()
+	var issues ValidationErrors
+	if !errors.As(err, &issues) {
+		t.Fatal("expected ValidationErrors, got", err)
+	}
+	for _, want := range []string{
+		"duplicate relationship ID rId1",
+		"points to missing part word/missing.xml",
+		"word/extra.bin: no content type",
+		"word/broken.xml: malformed XML",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestTranslateFileValidation(t *testing.T) {
+	var in bytes.Buffer
+	if _, err := newTestDoc("hello").WriteTo(&in); err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTranslator("", "").WithValidation(true).WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	if err := tr.TranslateFile(context.Background(), bytes.NewReader(in.Bytes()), io.Discard, TranslateFileOptions{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,147 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultWatchInterval 是 WatchFolder 默认的扫描间隔
+const DefaultWatchInterval = 2 * time.Second
+
+// WatchOptions 是 WatchFolder 的设置
+type WatchOptions struct {
+	// InputDir 是监视的目录, 只处理其中 (不含子目录) 扩展名为 .docx 的文件
+	InputDir string
+	// OutputDir 是译文的目录, 译文的文件名为在扩展名之前插入语言代码, 如 a.docx 译为日语时为 a.ja.docx
+	OutputDir string
+	// ErrorDir 是翻译失败的文件移入的目录, 同时写入记录错误的 <文件名>.error.txt; 为空时为 OutputDir 下的 errors
+	ErrorDir string
+	// DoneDir 不为空时, 翻译成功的文件移入该目录; 为空时保留在 InputDir, 译文比它新时不再翻译
+	DoneDir string
+	// TargetLanguage 是目标语言
+	TargetLanguage string
+	// Interval 是扫描间隔, 为 0 时为 DefaultWatchInterval. 修改时间在一个间隔之内的文件视为仍在写入, 留待下次扫描
+	Interval time.Duration
+}
+
+// WatchFolder 定期扫描 opts.InputDir, 将新放入的 docx 文件译为 opts.TargetLanguage 写入 opts.OutputDir,
+// 翻译失败的文件移入 opts.ErrorDir, 直到 ctx 结束, 返回 ctx.Err(); 目录无法创建或读取时立即返回错误
+//
+// 文件依次翻译, 译文先写入临时文件再改名, 不会留下不完整的译文. ErrorPolicyCollect 下部分翻译单元失败的文件仍视为成功,
+// 失败的翻译单元保留原文并记录日志. 以 . 或 ~$ 开头的文件 (隐藏文件与 Word 的锁文件) 被忽略.
+func (t *Translator) WatchFolder(ctx context.Context, opts WatchOptions) error {
+	if opts.ErrorDir == "" {
+		opts.ErrorDir = filepath.Join(opts.OutputDir, "errors")
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultWatchInterval
+	}
+	for _, dir := range []string{opts.OutputDir, opts.ErrorDir, opts.DoneDir} {
+		if dir == "" {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	for {
+		entries, err := os.ReadDir(opts.InputDir)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			name := e.Name()
+			if !e.Type().IsRegular() || !strings.EqualFold(filepath.Ext(name), ".docx") ||
+				strings.HasPrefix(name, ".") || strings.HasPrefix(name, "~$") {
+				continue
+			}
+			info, err := e.Info()
+			if err != nil || time.Since(info.ModTime()) < opts.Interval {
+				continue
+			}
+			out := filepath.Join(opts.OutputDir, LanguageFileName(name, opts.TargetLanguage))
+			if o, err := os.Stat(out); err == nil && !o.ModTime().Before(info.ModTime()) {
+				continue
+			}
+			t.watchFile(ctx, opts, name, out)
+		}
+		select {
+		case <-time.After(opts.Interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// watchFile 翻译 InputDir 中的文件 name 并写入 out, 失败时将其移入 ErrorDir
+func (t *Translator) watchFile(ctx context.Context, opts WatchOptions, name, out string) {
+	in := filepath.Join(opts.InputDir, name)
+	t.log().Log(LogLevelInfo, "开始翻译", "file", in)
+	err := t.translateFileTo(ctx, in, out, TranslateFileOptions{TargetLanguage: opts.TargetLanguage, Format: "docx"})
+	var segErrs SegmentErrors
+	if errors.As(err, &segErrs) {
+		t.log().Log(LogLevelWarn, "部分翻译单元保留了原文", "file", in, "failed", len(segErrs))
+		err = nil
+	}
+	if ctx.Err() != nil {
+		// 被中止的文件留待下次启动时重新翻译
+		return
+	}
+	if err != nil {
+		t.log().Log(LogLevelError, "翻译失败", "file", in, "error", err)
+		if merr := os.Rename(in, filepath.Join(opts.ErrorDir, name)); merr != nil {
+			t.log().Log(LogLevelError, "无法移走翻译失败的文件", "file", in, "error", merr)
+			return
+		}
+		_ = os.WriteFile(filepath.Join(opts.ErrorDir, name+".error.txt"), []byte(err.Error()+"\n"), 0o644)
+		return
+	}
+	t.log().Log(LogLevelInfo, "翻译完成", "file", in, "output", out)
+	if opts.DoneDir != "" {
+		if err = os.Rename(in, filepath.Join(opts.DoneDir, name)); err != nil {
+			t.log().Log(LogLevelError, "无法移走翻译完成的文件", "file", in, "error", err)
+		}
+	}
+}
+
+// translateFileTo 翻译文件 in 并写入 out; 译文先写入同一目录中的临时文件, 成功后改名为 out.
+// 翻译出错但有译文时 (ErrorPolicyCollect) 仍写入并返回该错误
+func (t *Translator) translateFileTo(ctx context.Context, in, out string, opts TranslateFileOptions) error {
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	var buf bytes.Buffer
+	err = t.TranslateFile(ctx, f, &buf, opts)
+	if buf.Len() == 0 || ctx.Err() != nil {
+		if err == nil {
+			err = ctx.Err()
+		}
+		return err
+	}
+	tmp, werr := os.CreateTemp(filepath.Dir(out), "."+filepath.Base(out)+".*")
+	if werr != nil {
+		return werr
+	}
+	_, werr = tmp.Write(buf.Bytes())
+	if cerr := tmp.Close(); werr == nil {
+		werr = cerr
+	}
+	if werr == nil {
+		werr = os.Rename(tmp.Name(), out)
+	}
+	if werr != nil {
+		_ = os.Remove(tmp.Name())
+		return werr
+	}
+	return err
+}
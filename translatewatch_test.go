@@ -0,0 +1,85 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWatchFolder(t *testing.T) {
+	dir := t.TempDir()
+	in, out, done := filepath.Join(dir, "in"), filepath.Join(dir, "out"), filepath.Join(dir, "done")
+	if err := os.Mkdir(in, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := newTestDoc("hello").WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-time.Minute)
+	for name, data := range map[string][]byte{
+		"a.docx":    buf.Bytes(),
+		"bad.docx":  []byte("not a document"),
+		"~$a.docx":  buf.Bytes(),
+		"notes.txt": []byte("hello"),
+	} {
+		p := filepath.Join(in, name)
+		if err := os.WriteFile(p, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		_ = os.Chtimes(p, old, old)
+	}
+	// 刚写入的文件留待下次扫描
+	if err := os.WriteFile(filepath.Join(in, "new.docx"), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- tr.WatchFolder(ctx, WatchOptions{InputDir: in, OutputDir: out, DoneDir: done, TargetLanguage: "Japanese", Interval: 200 * time.Millisecond})
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(filepath.Join(out, "new.ja.docx")); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	cancel()
+	if err := <-errc; !errors.Is(err, context.Canceled) {
+		t.Fatal("unexpected WatchFolder result:", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "a.ja.docx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil || !strings.Contains(doc.Document.Body.Items[0].(*Paragraph).String(), "HELLO") {
+		t.Fatal("unexpected translation:", err)
+	}
+	for _, p := range []string{
+		filepath.Join(done, "a.docx"),
+		filepath.Join(done, "new.docx"),
+		filepath.Join(out, "errors", "bad.docx"),
+		filepath.Join(out, "errors", "bad.docx.error.txt"),
+		filepath.Join(in, "~$a.docx"),
+		filepath.Join(in, "notes.txt"),
+	} {
+		if _, err = os.Stat(p); err != nil {
+			t.Error(err)
+		}
+	}
+	if entries, _ := os.ReadDir(out); len(entries) != 3 {
+		t.Errorf("unexpected output files: %v", entries)
+	}
+}
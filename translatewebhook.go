@@ -0,0 +1,178 @@
+package docx
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 任务通知请求的头
+const (
+	// WebhookSignatureHeader 是签名的头, 值为 "sha256=" 加上以 Secret 对 "<时间戳>.<请求体>" 计算的 HMAC-SHA256 的十六进制
+	WebhookSignatureHeader = "X-Docx-Translate-Signature"
+	// WebhookTimestampHeader 是发送时间的头, 值为 Unix 秒数, 供接收方拒绝重放的旧请求
+	WebhookTimestampHeader = "X-Docx-Translate-Timestamp"
+)
+
+// ErrWebhookSignature 任务通知的签名无效或已过期, 见 VerifyWebhook
+var ErrWebhookSignature = errors.New("invalid webhook signature")
+
+// WebhookPayload 是任务通知的 JSON 请求体
+type WebhookPayload struct {
+	Event          string     `json:"event"` // Event 为 "job.succeeded", "job.failed" 或 "job.canceled"
+	ID             string     `json:"id"`
+	State          JobState   `json:"state"`
+	Format         string     `json:"format,omitempty"`
+	TargetLanguage string     `json:"target_language"`
+	Usage          JobUsage   `json:"usage"`
+	Error          string     `json:"error,omitempty"`
+	Finished       *time.Time `json:"finished,omitempty"`
+	// DownloadURL 是译文的下载地址, 只有成功的任务且设置了 Webhook.ResultURL 或 Webhook.BaseURL 时才有
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+// Webhook 在任务结束 (成功、失败或取消) 时向 URL 发送签名的 JSON 通知, 见 JobManager.WithWebhook 与 JobQueue.WithWebhook
+//
+// 请求带有 WebhookTimestampHeader 与 WebhookSignatureHeader, 接收方用 VerifyWebhook 验证.
+// 通知在后台发送, 失败 (网络错误或非 2xx 响应) 时按 1, 2, 4... 秒的间隔重试, 最终失败只记录日志, 不影响任务.
+type Webhook struct {
+	// URL 是接收通知的地址
+	URL string
+	// Secret 是签名的密钥, 为空时不签名
+	Secret []byte
+	// BaseURL 是 JobManager.Handler 或 JobQueue.Handler 对外的地址 (如 https://translate.example.com/api),
+	// DownloadURL 为 BaseURL + "/jobs/{id}/result"; 没有挂载 Handler 时不要设置, 改用 ResultURL
+	BaseURL string
+	// ResultURL 返回任务 id 的译文的下载地址, 供译文由调用方自己的接口提供的情况; 设置后取代 BaseURL, 返回空串时没有 DownloadURL
+	ResultURL func(id string) string
+	// Retries 是失败后的重试次数, 为 0 时为 3, 小于 0 时不重试
+	Retries int
+	// Client 是发送请求的 HTTP 客户端, 为 nil 时使用超时为 10 秒的客户端
+	Client *http.Client
+	// Logger 记录最终发送失败的通知, 为 nil 时不记录
+	Logger Logger
+}
+
+// payload 返回任务 info 的通知
+func (h *Webhook) payload(info JobInfo) WebhookPayload {
+	p := WebhookPayload{
+		Event:          "job." + info.State.String(),
+		ID:             info.ID,
+		State:          info.State,
+		Format:         info.Format,
+		TargetLanguage: info.TargetLanguage,
+		Usage:          info.Usage,
+		Error:          info.Error,
+		Finished:       info.Finished,
+	}
+	switch {
+	case info.State != JobSucceeded:
+	case h.ResultURL != nil:
+		p.DownloadURL = h.ResultURL(info.ID)
+	case h.BaseURL != "":
+		p.DownloadURL = strings.TrimSuffix(h.BaseURL, "/") + "/jobs/" + info.ID + "/result"
+	}
+	return p
+}
+
+// notify 在后台发送任务 info 的通知
+func (h *Webhook) notify(info JobInfo) {
+	if h == nil {
+		return
+	}
+	go func() {
+		if err := h.send(h.payload(info)); err != nil && h.Logger != nil {
+			h.Logger.Log(LogLevelError, "任务通知发送失败", "id", info.ID, "url", h.URL, "error", err)
+		}
+	}()
+}
+
+// send 发送通知 p, 失败时重试
+func (h *Webhook) send(p WebhookPayload) error {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	client := h.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	retries := h.Retries
+	if retries == 0 {
+		retries = 3
+	}
+	for attempt := 0; ; attempt++ {
+		if err = h.post(client, body); err == nil || attempt >= retries {
+			return err
+		}
+		time.Sleep(time.Second << attempt)
+	}
+}
+
+func (h *Webhook) post(client *http.Client, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, ts)
+	if len(h.Secret) > 0 {
+		req.Header.Set(WebhookSignatureHeader, webhookSignature(h.Secret, ts, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+// webhookSignature 返回时间戳 ts 与请求体 body 的签名
+func webhookSignature(secret []byte, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook 验证收到的任务通知的签名, header 与 body 是请求的头与请求体;
+// 时间戳与当前时间相差超过 maxAge (大于 0 时) 也视为无效. 无效时返回 ErrWebhookSignature
+func VerifyWebhook(secret []byte, header http.Header, body []byte, maxAge time.Duration) error {
+	ts := header.Get(WebhookTimestampHeader)
+	sent, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: bad timestamp", ErrWebhookSignature)
+	}
+	if age := time.Since(time.Unix(sent, 0)); maxAge > 0 && (age > maxAge || age < -maxAge) {
+		return fmt.Errorf("%w: expired", ErrWebhookSignature)
+	}
+	if !hmac.Equal([]byte(header.Get(WebhookSignatureHeader)), []byte(webhookSignature(secret, ts, body))) {
+		return ErrWebhookSignature
+	}
+	return nil
+}
+
+// WithWebhook 设置任务结束时发送的通知
+func (m *JobManager) WithWebhook(h *Webhook) *JobManager {
+	m.webhook = h
+	return m
+}
+
+// WithWebhook 设置任务结束时发送的通知, 由完成任务的执行者发送
+func (q *JobQueue) WithWebhook(h *Webhook) *JobQueue {
+	q.webhook = h
+	return q
+}
@@ -0,0 +1,103 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	secret := []byte("s3cret")
+	received := make(chan WebhookPayload, 4)
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := VerifyWebhook(secret, r.Header, body, time.Minute); err != nil {
+			t.Error(err)
+		}
+		if attempts++; attempts == 1 {
+			// 第一次失败, 应当重试
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var p WebhookPayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Error(err)
+		}
+		received <- p
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	if _, err := newTestDoc("hello").WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	}))
+	m := NewJobManager(tr).WithWebhook(&Webhook{URL: srv.URL, Secret: secret, BaseURL: "https://translate.example.com/api/", Retries: 1})
+	id := m.Submit(buf.Bytes(), TranslateFileOptions{TargetLanguage: "English"})
+	select {
+	case p := <-received:
+		if p.Event != "job.succeeded" || p.ID != id || p.State != JobSucceeded || p.Usage.Segments != 1 ||
+			p.DownloadURL != "https://translate.example.com/api/jobs/"+id+"/result" {
+			t.Fatalf("unexpected payload: %+v", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook received")
+	}
+
+	// 排队中被取消的任务也发送通知, 失败的任务没有下载地址
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := NewJobQueue(NewMemoryJobStore()).WithPollInterval(10 * time.Millisecond).
+		WithWebhook(&Webhook{URL: srv.URL, Secret: secret, BaseURL: "https://x"})
+	id, _ = q.Submit([]byte("hello"), TranslateFileOptions{TargetLanguage: "English"})
+	if err := q.Cancel(id); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case p := <-received:
+		if p.Event != "job.canceled" || p.ID != id || p.DownloadURL != "" {
+			t.Fatalf("unexpected payload: %+v", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook received")
+	}
+
+	// 译文由调用方的接口提供
+	q.webhook.ResultURL = func(id string) string { return "https://api.example.com/documents/" + id }
+	go func() { _ = q.Work(ctx, tr) }()
+	id, _ = q.Submit([]byte("hello"), TranslateFileOptions{TargetLanguage: "English", Format: "txt"})
+	select {
+	case p := <-received:
+		if p.Event != "job.succeeded" || p.ID != id || p.DownloadURL != "https://api.example.com/documents/"+id {
+			t.Fatalf("unexpected payload: %+v", p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook received")
+	}
+
+	h := http.Header{}
+	body := []byte(`{"id":"x"}`)
+	old := time.Now().Add(-time.Hour).Unix()
+	h.Set(WebhookTimestampHeader, strconv.FormatInt(old, 10))
+	h.Set(WebhookSignatureHeader, webhookSignature(secret, strconv.FormatInt(old, 10), body))
+	if err := VerifyWebhook(secret, h, body, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyWebhook(secret, h, body, time.Minute); !errors.Is(err, ErrWebhookSignature) {
+		t.Fatal("expected an expired signature, got", err)
+	}
+	if err := VerifyWebhook([]byte("other"), h, body, 0); !errors.Is(err, ErrWebhookSignature) {
+		t.Fatal("expected an invalid signature, got", err)
+	}
+}
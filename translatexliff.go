@@ -0,0 +1,205 @@
+package docx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// XMLNS_XLIFF2 是 XLIFF 2.x 的命名空间
+const XMLNS_XLIFF2 = "urn:oasis:names:tc:xliff:document:2.0"
+
+// ErrXLIFFMismatch XLIFF 文件中的翻译单元与文档不一致, 通常是导出之后文档或翻译设置 (如 WithStyleFilter) 有变化
+var ErrXLIFFMismatch = errors.New("XLIFF does not match the document")
+
+// ExportXLIFF 将 doc 中的翻译单元写为 XLIFF 2.1 文件, 供 CAT 工具翻译, 译好的文件用 ImportXLIFF 导回
+//
+// 翻译单元与 TranslateDocx 相同 (同样受 WithStyleFilter, WithRange 等设置影响), 每个段落是一个 <unit>, id 依次为 u1, u2...;
+// 超链接、域等成对的标记写为 <pc>, 不翻译的内容写为 <ph>, 其 id 与 TranslateDocx 使用的标记编号相同.
+func (t *Translator) ExportXLIFF(w io.Writer, doc *Docx, targetLanguage string) error {
+	_, segs := t.prepare(doc, targetLanguage)
+	var sb strings.Builder
+	sb.WriteString(xml.Header)
+	sb.WriteString(`<xliff xmlns="` + XMLNS_XLIFF2 + `" version="2.1" srcLang="`)
+	sb.Write(escapeXMLText(xliffLang(t.sourceLanguageName())))
+	sb.WriteString(`" trgLang="`)
+	sb.Write(escapeXMLText(xliffLang(targetLanguage)))
+	sb.WriteString("\">\n  <file id=\"f1\">\n")
+	for i, sg := range segs {
+		sb.WriteString(`    <unit id="` + segmentID(i) + `"><segment state="initial"><source xml:space="preserve">`)
+		sb.WriteString(xliffInline(sg.text))
+		sb.WriteString("</source></segment></unit>\n")
+	}
+	sb.WriteString("  </file>\n</xliff>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// ImportXLIFF 读取 ExportXLIFF 导出并已翻译的 XLIFF 文件, 将其中的译文填入 doc 的副本, 返回新文档
+//
+// 译文语言取 XLIFF 的 trgLang. 翻译单元按 id 对应, 原文与文档不一致时返回包装了 ErrXLIFFMismatch 的错误;
+// 没有译文的翻译单元保留原文. 译文中的 <pc> 与 <ph> 按 TranslateDocx 的规则重建超链接与域等, <mrk> 只保留其中的文字.
+// 导入不请求翻译服务, 也不套用标点、数字格式等后处理, 译文按人工编辑的原样写入.
+func (t *Translator) ImportXLIFF(doc *Docx, r io.Reader) (*Docx, error) {
+	units, trgLang, err := readXLIFF(r)
+	if err != nil {
+		return nil, err
+	}
+	return t.fillImported(doc, trgLang, units, true, ErrXLIFFMismatch)
+}
+
+// fillImported 按 id (u1, u2...) 将 units 中的译文填入 doc 的副本, 返回新文档; 原文与文档不一致时返回包装了 mismatch 的错误,
+// all 为 true 时每个翻译单元都要有对应的 unit, 否则没有 unit 或没有译文的翻译单元保留原文. ImportXLIFF 与 ImportSegments 共用
+func (t *Translator) fillImported(doc *Docx, targetLanguage string, units map[string]*importUnit, all bool, mismatch error) (*Docx, error) {
+	newDoc, segs := t.prepare(doc, targetLanguage)
+	if all && len(units) != len(segs) {
+		return nil, fmt.Errorf("%w: %d units for %d segments", mismatch, len(units), len(segs))
+	}
+	targets := make([]string, len(segs))
+	for i, sg := range segs {
+		id := segmentID(i)
+		u, ok := units[id]
+		if ok && u.source != sg.text || !ok && all {
+			return nil, fmt.Errorf("%w: unit %s", mismatch, id)
+		}
+		targets[i] = sg.text
+		if ok && u.translated {
+			targets[i] = u.target
+		} else {
+			t.log().Log(LogLevelDebug, "翻译单元没有译文, 将保留原文", "unit", id)
+		}
+	}
+	for i, sg := range segs {
+		sg.fill(targets[i])
+	}
+	return newDoc, nil
+}
+
+// xliffLang 返回语言 lang 的 BCP 47 标记
+func xliffLang(lang string) string {
+	if tag := languageTag(lang); tag != "" {
+		return tag
+	}
+	return LanguageCode(lang)
+}
+
+// xliffInline 将带有标记的文字 text 转为 XLIFF 的内容: ⟪n⟫ 与 ⟪/n⟫ 转为 <pc id="n">, ⟪n/⟫ 转为 <ph id="n"/>
+func xliffInline(text string) string {
+	var sb strings.Builder
+	last := 0
+	for _, m := range inlineTagRe.FindAllStringSubmatchIndex(text, -1) {
+		sb.Write(escapeXMLText(text[last:m[0]]))
+		last = m[1]
+		id := text[m[4]:m[5]]
+		switch {
+		case m[3] > m[2]:
+			sb.WriteString("</pc>")
+		case m[7] > m[6]:
+			sb.WriteString(`<ph id="` + id + `"/>`)
+		default:
+			sb.WriteString(`<pc id="` + id + `">`)
+		}
+	}
+	sb.Write(escapeXMLText(text[last:]))
+	return sb.String()
+}
+
+// importUnit 是导入的文件中一个翻译单元的原文与译文, 行内元素已转为标记
+type importUnit struct {
+	source, target string
+	translated     bool // translated 表示有非空的译文
+}
+
+// readXLIFF 读取 XLIFF 2.x 文件中的翻译单元与译文语言, 一个 <unit> 中的多个 <segment> 依次拼接
+func readXLIFF(r io.Reader) (map[string]*importUnit, string, error) {
+	var (
+		units   = make(map[string]*importUnit)
+		trgLang string
+		unit    *importUnit
+		text    *strings.Builder // text 是正在读取的 <source> 或 <target>
+		source  strings.Builder
+		target  strings.Builder
+		pcs     []string // pcs 是未结束的 <pc> 的 id
+	)
+	d := xml.NewDecoder(r)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			attr := func(name string) string {
+				for _, a := range tok.Attr {
+					if a.Name.Local == name {
+						return a.Value
+					}
+				}
+				return ""
+			}
+			switch tok.Name.Local {
+			case "xliff":
+				if tok.Name.Space != XMLNS_XLIFF2 {
+					return nil, "", fmt.Errorf("%w: not an XLIFF 2 document", ErrXLIFFMismatch)
+				}
+				trgLang = attr("trgLang")
+			case "unit":
+				unit = &importUnit{}
+				units[attr("id")] = unit
+				source.Reset()
+				target.Reset()
+			case "source":
+				text = &source
+			case "target":
+				if unit != nil {
+					unit.translated = true
+				}
+				text = &target
+			case "pc":
+				if text != nil {
+					pcs = append(pcs, attr("id"))
+					open, _ := inlineTags(atoiOr(attr("id")))
+					text.WriteString(open)
+				}
+			case "ph":
+				if text != nil {
+					text.WriteString(inlineTag(atoiOr(attr("id"))))
+				}
+			}
+		case xml.EndElement:
+			switch tok.Name.Local {
+			case "source", "target":
+				text = nil
+			case "pc":
+				if text != nil && len(pcs) > 0 {
+					_, end := inlineTags(atoiOr(pcs[len(pcs)-1]))
+					pcs = pcs[:len(pcs)-1]
+					text.WriteString(end)
+				}
+			case "unit":
+				if unit != nil {
+					unit.source, unit.target = source.String(), target.String()
+					unit.translated = unit.translated && strings.TrimSpace(unit.target) != ""
+				}
+				unit = nil
+			}
+		case xml.CharData:
+			if text != nil {
+				text.Write(tok)
+			}
+		}
+	}
+	return units, trgLang, nil
+}
+
+// atoiOr 返回 s 表示的整数, 无效时返回 0, 对应的标记在填充时视为无效
+func atoiOr(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
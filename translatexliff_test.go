@@ -0,0 +1,66 @@
+package docx
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestXLIFFRoundTrip(t *testing.T) {
+	doc := New().WithDefaultTheme()
+	p := doc.AddParagraph()
+	p.AddText("请参阅")
+	h := p.AddLink("", "https://example.com/docs")
+	h.Run.Children = []interface{}{&Text{Text: "文档"}}
+	p.AddText("了解 <详情>")
+	doc.AddParagraph().AddText("未翻译")
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := NewTranslator("", "")
+	var xliff bytes.Buffer
+	if err = tr.ExportXLIFF(&xliff, doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	exported := xliff.String()
+	for _, want := range []string{
+		`<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.1" srcLang="zh-CN" trgLang="en-US">`,
+		`<unit id="u1"><segment state="initial"><source xml:space="preserve">请参阅<pc id="1">文档</pc>了解 &lt;详情&gt;</source></segment></unit>`,
+		`<unit id="u2">`,
+	} {
+		if !strings.Contains(exported, want) {
+			t.Fatalf("expected %s in\n%s", want, exported)
+		}
+	}
+
+	// CAT 工具填入译文
+	translated := strings.Replace(exported, `了解 &lt;详情&gt;</source>`,
+		`了解 &lt;详情&gt;</source><target>See <pc id="1">the <mrk id="m1" translate="yes">docs</mrk></pc> for &lt;details&gt;</target>`, 1)
+	newDoc, err := tr.ImportXLIFF(doc, strings.NewReader(translated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	np := newDoc.Document.Body.Items[0].(*Paragraph)
+	link, ok := np.Children[1].(*Hyperlink)
+	if !ok || runText(&link.Run) != "the docs" {
+		t.Fatalf("the hyperlink was not rebuilt: %#v", np.Children)
+	}
+	if s := np.String(); !strings.HasPrefix(s, "See ") || !strings.HasSuffix(s, " for <details>") {
+		t.Fatal("unexpected translation:", s)
+	}
+	if s := newDoc.Document.Body.Items[1].(*Paragraph).String(); s != "未翻译" {
+		t.Fatal("the untranslated unit was not kept:", s)
+	}
+
+	changed := strings.Replace(exported, "未翻译", "已修改", 1)
+	if _, err = tr.ImportXLIFF(doc, strings.NewReader(changed)); !errors.Is(err, ErrXLIFFMismatch) {
+		t.Fatal("expected ErrXLIFFMismatch, got", err)
+	}
+}
@@ -0,0 +1,229 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	xlsxCellHint    = "这段文字是电子表格单元格中的内容: 译文要简洁, 保留原有的换行, 不要添加说明."
+	xlsxCommentHint = "这段文字是电子表格单元格的批注."
+	xlsxChartHint   = "这段文字是图表或坐标轴的标题: 译文要简洁, 不要添加句末标点."
+)
+
+var (
+	// xlsxSharedRe 匹配共享字符串表中的字符串
+	xlsxSharedRe = regexp.MustCompile(`(?s)<si>(.*?)</si>`)
+	// xlsxInlineRe 匹配工作表中的内联字符串, 公式的结果 (t="str") 不是内联字符串
+	xlsxInlineRe = regexp.MustCompile(`(?s)<is>(.*?)</is>`)
+	// xlsxCommentRe 匹配批注与会话式批注的内容
+	xlsxCommentRe = regexp.MustCompile(`(?s)<text>(.*?)</text>`)
+	// xlsxTitleRe 匹配图表与坐标轴的标题, 引用单元格的标题 (c:strRef) 中没有段落, 随单元格翻译
+	xlsxTitleRe = regexp.MustCompile(`(?s)<c:title>(.*?)</c:title>`)
+	// xlsxChartParaRe 匹配图表标题中的段落
+	xlsxChartParaRe = regexp.MustCompile(`(?s)<a:p>(.*?)</a:p>`)
+)
+
+// TranslateXlsx 将 xlsx 工作簿 data 翻译为 targetLanguage 并写入 w, ctx 被取消时返回 ctx.Err()
+//
+// 翻译共享字符串表 (xl/sharedStrings.xml)、工作表中的内联字符串、单元格的批注 (包括会话式批注) 与图表和坐标轴的标题;
+// 公式、数值、日期、定义的名称与工作表名原样保留, 没有文字的字符串 (如以文本存储的数字) 不翻译.
+// 带格式的字符串译文使用第一段文字的格式. 与 TranslateDocx 一样使用缓存、术语表与 WithErrorPolicy 等设置,
+// ErrorPolicyCollect 下有失败的字符串时仍然写出工作簿, 失败的字符串保留原文, 并返回 SegmentErrors.
+func (t *Translator) TranslateXlsx(ctx context.Context, data []byte, w io.Writer, targetLanguage string) error {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	parts := make(map[string][]byte)
+	var texts []*xlsxText
+	for _, f := range zr.File {
+		var (
+			re   *regexp.Regexp
+			hint string
+		)
+		switch dir := path.Dir(f.Name); {
+		case f.Name == "xl/sharedStrings.xml":
+			re, hint = xlsxSharedRe, xlsxCellHint
+		case dir == "xl/worksheets" && path.Ext(f.Name) == ".xml":
+			re, hint = xlsxInlineRe, xlsxCellHint
+		case dir == "xl" && strings.HasPrefix(path.Base(f.Name), "comments"), dir == "xl/threadedComments":
+			re, hint = xlsxCommentRe, xlsxCommentHint
+		case dir == "xl/charts" && strings.HasPrefix(path.Base(f.Name), "chart"):
+			re, hint = xlsxTitleRe, xlsxChartHint
+		default:
+			continue
+		}
+		content, err := readZipFile(f)
+		if err != nil {
+			return err
+		}
+		parts[f.Name] = content
+		for _, m := range re.FindAllSubmatchIndex(content, -1) {
+			if re == xlsxTitleRe {
+				for _, p := range xlsxChartParaRe.FindAllSubmatchIndex(content[m[2]:m[3]], -1) {
+					texts = t.addXlsxText(texts, f.Name, content, m[2]+p[2], m[2]+p[3], "a:", hint)
+				}
+				continue
+			}
+			texts = t.addXlsxText(texts, f.Name, content, m[2], m[3], "", hint)
+		}
+	}
+
+	return t.writeTranslatedTexts(ctx, zr, parts, texts, w, targetLanguage)
+}
+
+// writeTranslatedTexts 翻译 texts, 将译文放入 parts 中对应的部件后写出 zip 包 zr; parts 中是 texts 所在部件的原始内容.
+// TranslateXlsx 与 TranslatePptx 共用
+func (t *Translator) writeTranslatedTexts(ctx context.Context, zr *zip.Reader, parts map[string][]byte, texts []*xlsxText, w io.Writer, targetLanguage string) error {
+	segs := make([]*segment, len(texts))
+	for i, x := range texts {
+		x := x
+		segs[i] = &segment{text: x.text, hint: x.hint, set: func(s string) { x.translated = &s }}
+	}
+	report, err := t.translateSegments(ctx, segs, targetLanguage)
+	if report == nil {
+		return err
+	}
+
+	// 从后向前替换, 前面的位置不受影响
+	sort.SliceStable(texts, func(i, j int) bool { return texts[i].start > texts[j].start })
+	for _, x := range texts {
+		if x.translated == nil {
+			continue
+		}
+		content := parts[x.part]
+		patched := make([]byte, 0, len(content)+len(*x.translated))
+		patched = append(patched, content[:x.start]...)
+		patched = append(patched, x.rebuild(*x.translated)...)
+		patched = append(patched, content[x.end:]...)
+		parts[x.part] = patched
+	}
+
+	if werr := writeZipParts(w, zr, parts); werr != nil {
+		return werr
+	}
+	return err // ErrorPolicyCollect 下的 SegmentErrors
+}
+
+func translateXlsxFile(ctx context.Context, t *Translator, data []byte, w io.Writer, targetLanguage string) error {
+	return t.TranslateXlsx(ctx, data, w, targetLanguage)
+}
+
+// xlsxText 是 xlsx 部件中需要翻译的一段文字, 译文替换部件中 [start, end) 的内容
+type xlsxText struct {
+	part       string
+	start, end int
+	text, hint string
+	rebuild    func(translated string) []byte // rebuild 返回放入译文后的内容
+	translated *string
+}
+
+// addXlsxText 将部件 part 中 [start, end) 的文字加入 texts; ns 是文字所在元素的命名空间前缀,
+// 电子表格的字符串与批注为空, 图表标题为 "a:". 没有文字的内容不加入
+func (t *Translator) addXlsxText(texts []*xlsxText, part string, content []byte, start, end int, ns, hint string) []*xlsxText {
+	text, rebuild, ok := richText(content[start:end], ns)
+	if !ok || !hasLetter(text) || t.skipText(text) {
+		return texts
+	}
+	return append(texts, &xlsxText{part: part, start: start, end: end, text: text, hint: hint, rebuild: rebuild})
+}
+
+// richTextRes 是 richText 按命名空间前缀使用的正则表达式
+var richTextRes = map[string][3]*regexp.Regexp{}
+
+func init() {
+	for _, ns := range []string{"", "a:"} {
+		richTextRes[ns] = [3]*regexp.Regexp{
+			// 一段文字
+			regexp.MustCompile(`(?s)<` + ns + `r>(.*?)</` + ns + `r>`),
+			// 文字的格式
+			regexp.MustCompile(`(?s)^\s*(<` + ns + `rPr(?:\s[^>]*)?/>|<` + ns + `rPr(?:\s[^>]*)?>.*?</` + ns + `rPr>)`),
+			// 文字
+			regexp.MustCompile(`(?s)<` + ns + `t(?:\s[^>]*)?>(.*?)</` + ns + `t>`),
+		}
+	}
+}
+
+// richText 返回字符串 (<si>、<is>、批注的 <text>、图表标题或幻灯片的 <a:p>) 的内容 inner 中的文字,
+// rebuild 将译文放入第一段文字并去掉其余各段, 格式、注音 (rPh) 等其他内容保持不变;
+// 会话式批注的 <text> 中直接是文字. 无法解析时 ok 为 false
+func richText(inner []byte, ns string) (text string, rebuild func(string) []byte, ok bool) {
+	res := richTextRes[ns]
+	runs := res[0].FindAllSubmatchIndex(inner, -1)
+	if len(runs) == 0 {
+		if ns == "" && !bytes.Contains(inner, []byte("<")) {
+			text, ok = unescapeXML(inner)
+			return text, func(s string) []byte { return escapeXMLText(s) }, ok
+		}
+		// 不分段的字符串的文字位于开头, 注音中的文字在其后
+		m := res[2].FindSubmatchIndex(inner)
+		if m == nil || strings.TrimSpace(string(inner[:m[0]])) != "" {
+			return "", nil, false
+		}
+		if text, ok = unescapeXML(inner[m[2]:m[3]]); !ok {
+			return "", nil, false
+		}
+		return text, func(s string) []byte {
+			out := append([]byte(nil), inner[:m[0]]...)
+			out = append(out, xlsxT(ns, s)...)
+			return append(out, inner[m[1]:]...)
+		}, true
+	}
+	var sb strings.Builder
+	for _, r := range runs {
+		for _, m := range res[2].FindAllSubmatchIndex(inner[r[2]:r[3]], -1) {
+			s, ok := unescapeXML(inner[r[2]+m[2] : r[2]+m[3]])
+			if !ok {
+				return "", nil, false
+			}
+			sb.WriteString(s)
+		}
+	}
+	first := inner[runs[0][2]:runs[0][3]]
+	var props []byte
+	if m := res[1].FindSubmatchIndex(first); m != nil {
+		props = first[m[2]:m[3]]
+	}
+	return sb.String(), func(s string) []byte {
+		out := append([]byte(nil), inner[:runs[0][0]]...)
+		out = append(out, "<"+ns+"r>"...)
+		out = append(out, props...)
+		out = append(out, xlsxT(ns, s)...)
+		out = append(out, "</"+ns+"r>"...)
+		for i := 1; i < len(runs); i++ {
+			out = append(out, inner[runs[i-1][1]:runs[i][0]]...)
+		}
+		return append(out, inner[runs[len(runs)-1][1]:]...)
+	}, true
+}
+
+// xlsxT 返回内容为 s 的文字元素, 两端的空格与换行保留
+func xlsxT(ns, s string) []byte {
+	open := "<" + ns + "t>"
+	if ns == "" && strings.TrimSpace(s) != s {
+		open = `<t xml:space="preserve">`
+	}
+	out := append([]byte(open), escapeXMLText(s)...)
+	return append(out, "</"+ns+"t>"...)
+}
+
+// escapeXMLText 转义 XML 文本中的特殊字符
+func escapeXMLText(s string) []byte {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.Bytes()
+}
+
+// hasLetter 判断 s 中是否有文字, 数字、日期与符号不需要翻译
+func hasLetter(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
@@ -0,0 +1,74 @@
+package docx
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestTranslateXlsx(t *testing.T) {
+	files := map[string]string{
+		"[Content_Types].xml": `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"></Types>`,
+		"xl/workbook.xml": `<workbook><sheets><sheet name="Sales" sheetId="1" r:id="rId1"/></sheets>` +
+			`<definedNames><definedName name="Total">Sales!$B$1</definedName></definedNames></workbook>`,
+		"xl/sharedStrings.xml": `<sst count="4" uniqueCount="4">` +
+			`<si><t>hello</t></si>` +
+			`<si><r><rPr><b/></rPr><t xml:space="preserve">bold </t></r><r><t>plain</t></r><rPh sb="0" eb="1"><t>ph</t></rPh></si>` +
+			`<si><t>12.5</t></si>` +
+			`<si><t>a &amp; b</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1">` +
+			`<c r="A1" t="s"><v>0</v></c><c r="B1"><f>SUM(C1:C9)</f><v>42</v></c>` +
+			`<c r="C1" t="inlineStr"><is><t>inline</t></is></c><c r="D1" t="str"><f>"text"</f><v>text</v></c></row></sheetData></worksheet>`,
+		"xl/comments1.xml": `<comments><authors><author>Alice</author></authors><commentList>` +
+			`<comment ref="A1" authorId="0"><text><r><rPr><b/></rPr><t>Alice:</t></r><r><t xml:space="preserve"> check this</t></r></text></comment></commentList></comments>`,
+		"xl/threadedComments/threadedComment1.xml": `<ThreadedComments><threadedComment ref="A1" id="{1}"><text>looks good</text></threadedComment></ThreadedComments>`,
+		"xl/charts/chart1.xml": `<c:chartSpace><c:chart><c:title><c:tx><c:rich><a:bodyPr/><a:p><a:pPr/><a:r><a:rPr lang="en-US"/><a:t>Revenue</a:t></a:r></a:p></c:rich></c:tx></c:title>` +
+			`<c:plotArea><c:valAx><c:title><c:tx><c:strRef><c:f>Sales!$A$1</c:f></c:strRef></c:tx></c:title></c:valAx></c:plotArea></c:chart></c:chartSpace>`,
+	}
+	var in bytes.Buffer
+	zw := zip.NewWriter(&in)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var sources []string
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		sources = append(sources, text)
+		return strings.ToUpper(text), nil
+	}))
+	var out bytes.Buffer
+	if err := tr.TranslateFile(context.Background(), bytes.NewReader(in.Bytes()), &out, TranslateFileOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	got := readZip(t, out.Bytes())
+	for name, want := range map[string]string{
+		"xl/workbook.xml": files["xl/workbook.xml"],
+		"xl/sharedStrings.xml": `<sst count="4" uniqueCount="4">` +
+			`<si><t>HELLO</t></si>` +
+			`<si><r><rPr><b/></rPr><t>BOLD PLAIN</t></r><rPh sb="0" eb="1"><t>ph</t></rPh></si>` +
+			`<si><t>12.5</t></si>` +
+			`<si><t>A &amp; B</t></si></sst>`,
+		"xl/worksheets/sheet1.xml":                 strings.Replace(files["xl/worksheets/sheet1.xml"], "<t>inline</t>", "<t>INLINE</t>", 1),
+		"xl/comments1.xml":                         strings.Replace(files["xl/comments1.xml"], `<t>Alice:</t></r><r><t xml:space="preserve"> check this</t></r>`, `<t>ALICE: CHECK THIS</t></r>`, 1),
+		"xl/threadedComments/threadedComment1.xml": strings.Replace(files["xl/threadedComments/threadedComment1.xml"], "looks good", "LOOKS GOOD", 1),
+		"xl/charts/chart1.xml":                     strings.Replace(files["xl/charts/chart1.xml"], "Revenue", "REVENUE", 1),
+	} {
+		if got[name] != want {
+			t.Errorf("%s:\n got %s\nwant %s", name, got[name], want)
+		}
+	}
+	for _, s := range sources {
+		if s == "12.5" || strings.Contains(s, "SUM") || s == "text" {
+			t.Error("translated a number or formula:", s)
+		}
+	}
+}
@@ -6,7 +6,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"strings"
+	"regexp"
+	"time"
 )
 
 // Translator 结构体，用于配置翻译 API
@@ -14,6 +15,68 @@ type Translator struct {
 	APIKey string
 	APIURL string
 	Client *http.Client
+
+	errorPolicy ErrorPolicy
+	logger      Logger
+	metrics     *Metrics // metrics 非 nil 时记录运行指标, 见 WithMetrics
+	progress    ProgressFunc
+	pricings    []ProviderPricing
+	provider    Provider
+	model       string
+	sourceLang  string
+	cache       Cache
+	tm          *TranslationMemory
+	tmThreshold float64
+	matchRepair bool
+
+	concurrency    int // concurrency 是同时请求翻译服务的翻译单元数, 见 WithConcurrency
+	docConcurrency int // docConcurrency 是 TranslateDir 同时翻译的文档数, 见 WithDocumentConcurrency
+	maxChunkRunes  int
+	joiner         Joiner
+	styleHints     StyleHinter
+	noStyleHints   bool
+	styleFilter    StyleFilter
+	docRange       Range
+	skipPatterns   []*regexp.Regexp
+	skipHidden     bool
+	dnt            *DNTList
+	glossary       *Glossary
+	protect        ProtectKind
+	headingCases   map[string]HeadingCase
+	punctuation    map[string][]PunctuationRule
+	typography     map[string]Typography
+	numberFormats  map[string]NumberFormat
+
+	bilingualPolicy BilingualPolicy
+	routeRuns       bool
+	limits          Limits
+	password        string // password 是 TranslateFile 打开加密文档的密码, 见 WithPassword
+	outputPassword  string // outputPassword 非空时 TranslateFile 加密译文, 见 WithOutputPassword
+	lenient         bool   // lenient 为 true 时 TranslateFile 宽松地解析 docx, 见 WithLenientParsing
+	validate        bool   // validate 为 true 时 TranslateFile 写出译文前检查, 见 WithValidation
+	docContext      ContextOptions
+	backThreshold   float64
+	review          *reviewPass
+	sanitizer       Sanitizer
+	noSanitizer     bool
+	tone            Tone
+	tocEntries      bool
+	customProps     bool
+	keepAuthors     bool
+	revisionPolicy  RevisionPolicy
+	trackAuthor     string
+	outputMode      OutputMode
+	interleave      *InterleaveOptions // interleave 为 nil 时使用 DefaultInterleaveOptions
+	commentAuthor   string
+	provenance      *ProvenanceOptions
+	fontMaps        map[string]FontMap    // fontMaps 的键是 ISO 639-1 代码, 见 WithFontMap
+	previous        map[string]*Paragraph // previous 是 UpdateDocx 沿用的旧译文
+
+	subtitleLineLength int  // subtitleLineLength 是字幕译文每行最多的字符数, 见 WithSubtitleLineLength
+	pptxNotesOnly      bool // pptxNotesOnly 为 true 时 TranslatePptx 只翻译演讲者备注, 见 WithPptxNotesOnly
+
+	rtfConverter func(data []byte) (*Docx, error)  // rtfConverter 非 nil 时 TranslateFile 用它转换 RTF, 见 WithRTFConverter
+	docConverter func(data []byte) ([]byte, error) // docConverter 非 nil 时 TranslateFile 用它将 .doc 转换为 docx, 见 WithDocConverter
 }
 
 // NewTranslator 创建一个新的 Translator 实例
@@ -25,6 +88,34 @@ func NewTranslator(apiKey, apiURL string) *Translator {
 	}
 }
 
+// WithModel 设置 TranslateWithDashscope 使用的模型, 默认为 qwen-plus
+//
+// 使用自定义 Provider 时, 模型名仅作为缓存键的一部分.
+func (t *Translator) WithModel(model string) *Translator {
+	t.model = model
+	return t
+}
+
+func (t *Translator) modelName() string {
+	if t.model == "" {
+		return "qwen-plus"
+	}
+	return t.model
+}
+
+// WithSourceLanguage 设置原文的语言, 默认为中文
+func (t *Translator) WithSourceLanguage(lang string) *Translator {
+	t.sourceLang = lang
+	return t
+}
+
+func (t *Translator) sourceLanguageName() string {
+	if t.sourceLang == "" {
+		return "中文"
+	}
+	return t.sourceLang
+}
+
 // Translate 使用 OpenAI 兼容的 API 翻译文本
 func (t *Translator) Translate(text, targetLanguage string) (string, error) {
 	if text == "" {
@@ -97,97 +188,6 @@ func (t *Translator) Translate(text, targetLanguage string) (string, error) {
 	return translatedText, nil
 }
 
-// TranslateDocx 翻译一个 docx 对象，并返回一个新的翻译后的 docx 对象
-// TranslateDocx 翻译一个 docx 对象，并返回一个新的翻译后的 docx 对象 (优化版)
-func (t *Translator) TranslateDocx(doc *Docx, targetLanguage string) (*Docx, error) {
-	newDoc := New().WithDefaultTheme().WithA4Page()
-	newDoc.media = doc.media
-	newDoc.mediaNameIdx = doc.mediaNameIdx
-
-	// 辅助函数，用于翻译段落内容
-	translateParagraphContent := func(p *Paragraph) (*Paragraph, error) {
-		// 1. 拼接整个段落的文本
-		var textToTranslateBuilder strings.Builder
-		for _, child := range p.Children {
-			if run, ok := child.(*Run); ok {
-				for _, grandChild := range run.Children {
-					if text, ok := grandChild.(*Text); ok {
-						textToTranslateBuilder.WriteString(text.Text)
-					}
-				}
-			}
-		}
-		textToTranslate := textToTranslateBuilder.String()
-
-		// 2. 如果段落有实际内容，则进行翻译
-		if strings.TrimSpace(textToTranslate) == "" {
-			// 对于空段落或只有空格的段落，直接复制
-			return p, nil
-		}
-
-		translatedText, err := t.TranslateWithDashscope(textToTranslate, targetLanguage)
-		if err != nil {
-			// 如果翻译出错，则保留原文并打印错误
-			fmt.Printf("翻译段落时出错: %v. 将保留原文.\n", err)
-			translatedText = textToTranslate
-		}
-
-		// 3. 将翻译结果放入新段落，并尽量保留格式
-		newPara := &Paragraph{
-			Properties: p.Properties,
-			Children:   make([]interface{}, 0),
-			file:       newDoc,
-		}
-
-		if len(p.Children) > 0 {
-			// 创建一个新的 Run 来存放完整的翻译文本
-			// 并继承原段落第一个 Run 的格式
-			newRun := &Run{
-				RunProperties: &RunProperties{},
-				Children:      []interface{}{&Text{Text: translatedText}},
-			}
-
-			if firstRun, ok := p.Children[0].(*Run); ok {
-				newRun.RunProperties = firstRun.RunProperties
-			}
-			newPara.Children = append(newPara.Children, newRun)
-		}
-
-		return newPara, nil
-	}
-
-	// --- 遍历文档主体 ---
-	for _, item := range doc.Document.Body.Items {
-		switch o := item.(type) {
-		case *Paragraph:
-			translatedPara, _ := translateParagraphContent(o)
-			newDoc.Document.Body.Items = append(newDoc.Document.Body.Items, translatedPara)
-
-		case *Table:
-			// 创建结构相同的新表格
-			newTable := newDoc.AddTable(len(o.TableRows), len(o.TableRows[0].TableCells), 0, nil)
-			newTable.TableProperties = o.TableProperties
-			newTable.TableGrid = o.TableGrid
-
-			// 遍历并翻译表格中的每一个单元格
-			for i, row := range o.TableRows {
-				for j, cell := range row.TableCells {
-					newCell := newTable.TableRows[i].TableCells[j]
-					newCell.TableCellProperties = cell.TableCellProperties
-					newCell.Paragraphs = make([]*Paragraph, 0) // 清空默认段落
-
-					for _, para := range cell.Paragraphs {
-						translatedPara, _ := translateParagraphContent(para)
-						newCell.Paragraphs = append(newCell.Paragraphs, translatedPara)
-					}
-				}
-			}
-			newDoc.Document.Body.Items = append(newDoc.Document.Body.Items, newTable)
-		}
-	}
-	return newDoc, nil
-}
-
 // --- 在 translator.go 文件中添加以下代码 ---
 
 // Dashscope API 请求体结构
@@ -197,21 +197,45 @@ type DashscopeRequest struct {
 	TranslationOptions map[string]string   `json:"translation_options"`
 }
 
+// dashscopeSystemPrompt 生成 Dashscope 翻译请求的系统提示词
+func dashscopeSystemPrompt(sourceLang, targetLang string) string {
+	return "你是一个翻译大师，你需要将" + sourceLang + "的用户输入内容翻译为:" + targetLang + ".注意 你只需要返回翻译后的内容，不要返回任何多余内容"
+}
+
 // TranslateWithDashscope 使用阿里云 Dashscope API 翻译文本
 // sourceLang: 源语言代码 (例如 "auto", "zh", "en")
 // targetLang: 目标语言代码 (例如 "English", "Chinese", "Japanese")
 func (t *Translator) TranslateWithDashscope(text, targetLang string) (string, error) {
+	return t.translateWithDashscope(text, targetLang, "")
+}
+
+// translateWithDashscope 同 TranslateWithDashscope, hint 非空时附加在系统提示词之后
+func (t *Translator) translateWithDashscope(text, targetLang, hint string) (string, error) {
 	if text == "" {
 		return "", nil
 	}
 
+	prompt := dashscopeSystemPrompt(t.sourceLanguageName(), targetLang)
+	if hint != "" {
+		prompt += "\n" + hint
+	}
+	translatedText, err := t.dashscopeChat([]map[string]string{
+		{"role": "system", "content": prompt},
+		{"role": "user", "content": text},
+	})
+	if err != nil {
+		return "", err
+	}
+	t.log().Log(LogLevelDebug, "翻译完成", "source", text, "target", translatedText)
+	return translatedText, nil
+}
+
+// dashscopeChat 向 Dashscope 发送一次对话请求并返回模型的回复
+func (t *Translator) dashscopeChat(messages []map[string]string) (translatedText string, err error) {
 	// 构造符合 Dashscope API 格式的请求体
 	reqBody := DashscopeRequest{
-		Model: "qwen-plus",
-		Messages: []map[string]string{
-			{"role": "system", "content": "你是一个翻译大师，你需要将" + "中文" + "的用户输入内容翻译为:" + targetLang + ".注意 你只需要返回翻译后的内容，不要返回任何多余内容"},
-			{"role": "user", "content": text},
-		},
+		Model:    t.modelName(),
+		Messages: messages,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -230,6 +254,10 @@ func (t *Translator) TranslateWithDashscope(text, targetLang string) (string, er
 	req.Header.Set("Authorization", "Bearer "+t.APIKey)
 
 	// 发送请求
+	start := time.Now()
+	defer func() {
+		t.metrics.countRequest("dashscope/"+t.modelName(), time.Since(start), err)
+	}()
 	resp, err := t.Client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("发送 API 请求失败: %w", err)
@@ -263,10 +291,52 @@ func (t *Translator) TranslateWithDashscope(text, targetLang string) (string, er
 		return "", fmt.Errorf("无效的 API 响应格式: message 格式错误")
 	}
 
-	translatedText, ok := message["content"].(string)
+	translatedText, ok = message["content"].(string)
 	if !ok {
 		return "", fmt.Errorf("无效的 API 响应格式: 未在 message 中找到 content")
 	}
-	fmt.Println(translatedText)
+	if t.metrics != nil {
+		input, output := dashscopeUsage(result, messages, translatedText)
+		t.metrics.countTokens("dashscope/"+t.modelName(), input, output)
+	}
+	return translatedText, nil
+}
+
+// dashscopeUsage 返回响应 result 中 usage 记录的输入与输出 token 数, 没有 usage 时按 EstimateTokens 估算
+func dashscopeUsage(result map[string]interface{}, messages []map[string]string, reply string) (int, int) {
+	if usage, ok := result["usage"].(map[string]interface{}); ok {
+		input, iok := usage["prompt_tokens"].(float64)
+		output, ook := usage["completion_tokens"].(float64)
+		if iok && ook {
+			return int(input), int(output)
+		}
+	}
+	input := 0
+	for _, m := range messages {
+		input += EstimateTokens(m["content"])
+	}
+	return input, EstimateTokens(reply)
+}
+
+// dashscopeRepairPrompt 生成请求模型修改模糊匹配译文的系统提示词
+func dashscopeRepairPrompt(sourceLang, targetLang string) string {
+	return "你是一个翻译大师。用户会给出一条" + sourceLang + "原文、一条相近的旧原文及其" + targetLang +
+		"旧译文，你需要在尽量保留旧译文措辞的前提下修改旧译文，使其成为新原文的准确译文。注意 你只需要返回修改后的译文，不要返回任何多余内容"
+}
+
+// RepairWithDashscope 使用 Dashscope API 将翻译记忆中的模糊匹配改写为 text 的译文,
+// 通常比重新翻译更省 token, 术语与措辞也更一致
+func (t *Translator) RepairWithDashscope(text, matchSource, matchTarget, targetLang string) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	translatedText, err := t.dashscopeChat([]map[string]string{
+		{"role": "system", "content": dashscopeRepairPrompt(t.sourceLanguageName(), targetLang)},
+		{"role": "user", "content": "新原文: " + text + "\n旧原文: " + matchSource + "\n旧译文: " + matchTarget},
+	})
+	if err != nil {
+		return "", err
+	}
+	t.log().Log(LogLevelDebug, "修改模糊匹配完成", "source", text, "target", translatedText)
 	return translatedText, nil
 }
@@ -0,0 +1,1567 @@
+package docx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newTestTranslator 启动一个模拟的 Dashscope 服务, 将用户输入转为大写返回,
+// 输入中含有 "FAIL" 时返回 500
+func newTestTranslator(t *testing.T) *Translator {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req DashscopeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		text := req.Messages[len(req.Messages)-1]["content"]
+		if strings.Contains(text, "FAIL") {
+			http.Error(w, "mock failure", http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []interface{}{
+				map[string]interface{}{
+					"message": map[string]interface{}{"content": strings.ToUpper(text)},
+				},
+			},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return NewTranslator("test-key", srv.URL)
+}
+
+func newTestDoc(texts ...string) *Docx {
+	doc := New().WithDefaultTheme()
+	for _, s := range texts {
+		doc.AddParagraph().AddText(s)
+	}
+	return doc
+}
+
+func TestTranslateDocxErrorPolicy(t *testing.T) {
+	doc := newTestDoc("hello", "FAIL here", "world")
+
+	var logbuf bytes.Buffer
+	newDoc, err := newTestTranslator(t).WithLogger(NewStdLogger(&logbuf, LogLevelWarn)).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[1].(*Paragraph).String(); s != "FAIL here" {
+		t.Fatal("expected original text to be kept, got", s)
+	}
+	if n := strings.Count(logbuf.String(), "\n"); n != 1 || !strings.Contains(logbuf.String(), "WARN") {
+		t.Fatal("expected exactly one warning, got", logbuf.String())
+	}
+
+	_, err = newTestTranslator(t).WithErrorPolicy(ErrorPolicyFailFast).TranslateDocx(doc, "English")
+	var se *SegmentError
+	if !errors.As(err, &se) || se.Index != 1 || se.Source != "FAIL here" {
+		t.Fatal("unexpected fail-fast error:", err)
+	}
+
+	newDoc, err = newTestTranslator(t).WithErrorPolicy(ErrorPolicyCollect).TranslateDocx(doc, "English")
+	var ses SegmentErrors
+	if !errors.As(err, &ses) || len(ses) != 1 || ses[0].Index != 1 {
+		t.Fatal("unexpected collected errors:", err)
+	}
+	if newDoc == nil {
+		t.Fatal("expected a document alongside collected errors")
+	}
+	if s := newDoc.Document.Body.Items[2].(*Paragraph).String(); s != "WORLD" {
+		t.Fatal("expected translation to continue after failure, got", s)
+	}
+}
+
+func TestTranslateDocxProgress(t *testing.T) {
+	doc := newTestDoc("a", " ", "FAIL", "c")
+	var dones []int
+	_, err := newTestTranslator(t).WithProgress(func(done, total int, sg SegmentInfo) {
+		if total != 3 {
+			t.Fatal("expected 3 segments, got", total)
+		}
+		if sg.Index == 1 && (sg.Err == nil || sg.Target != "FAIL") {
+			t.Fatal("unexpected failed segment info:", sg)
+		}
+		dones = append(dones, done)
+	}).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dones) != 3 || dones[2] != 3 {
+		t.Fatal("unexpected progress calls:", dones)
+	}
+}
+
+func TestEstimateDocx(t *testing.T) {
+	doc := newTestDoc("你好世界", "", "hello world!")
+	est := NewTranslator("", "").EstimateDocx(doc, "English")
+	if est.Segments != 2 || est.Characters != 16 || est.Tokens != 4+3 {
+		t.Fatalf("unexpected estimate: %+v", est)
+	}
+	if len(est.Costs) != len(DefaultPricings) || est.Costs[0].Cost <= 0 {
+		t.Fatalf("unexpected costs: %+v", est.Costs)
+	}
+}
+
+func TestTranslateDocxDedup(t *testing.T) {
+	calls := 0
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		calls++
+		return strings.ToUpper(text), nil
+	})
+	newDoc, err := NewTranslator("", "").WithProvider(p).TranslateDocx(newTestDoc("header", "body", "header"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Fatal("expected 2 provider calls, got", calls)
+	}
+	if s := newDoc.Document.Body.Items[2].(*Paragraph).String(); s != "HEADER" {
+		t.Fatal("unexpected fan out:", s)
+	}
+}
+
+func TestTranslateDocxChunkJoin(t *testing.T) {
+	var chunks []string
+	p := ProviderFunc(func(text, target string) (string, error) {
+		chunks = append(chunks, text)
+		if target == "English" {
+			return "S" + strconv.Itoa(len(chunks)) + ".", nil
+		}
+		return "句" + strconv.Itoa(len(chunks)) + "。", nil
+	})
+	tr := NewTranslator("", "").WithProvider(p).WithMaxChunkRunes(8)
+	newDoc, err := tr.TranslateDocx(newTestDoc("第一句话。第二句话！Pi is 3.14 ok. End"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 4 || chunks[2] != "Pi is 3.14 ok." {
+		t.Fatalf("unexpected chunks: %q", chunks)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "S1. S2. S3. S4." {
+		t.Fatal("unexpected latin join:", s)
+	}
+	chunks = nil
+	newDoc, err = tr.TranslateDocx(newTestDoc("One sentence. Two sentence."), "Japanese")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "句1。句2。" {
+		t.Fatal("unexpected CJK join:", s)
+	}
+}
+
+func TestTranslateDocxEmpty(t *testing.T) {
+	blank := newTestDoc("", "  ")
+	blank.Document.Body.Items = append(blank.Document.Body.Items, &Table{}, &SectPr{})
+	docs := map[string]*Docx{
+		"empty":      New().WithDefaultTheme(),
+		"sectPrOnly": New().WithDefaultTheme().WithA4Page(),
+		"blank":      blank,
+	}
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		t.Fatal("unexpected provider call:", text)
+		return "", nil
+	})
+	tr := NewTranslator("", "").WithProvider(p).WithErrorPolicy(ErrorPolicyCollect)
+	for name, doc := range docs {
+		t.Run(name, func(t *testing.T) {
+			newDoc, err := tr.TranslateDocx(doc, "English")
+			if err != nil {
+				t.Fatal(err)
+			}
+			items := newDoc.Document.Body.Items
+			if _, ok := items[len(items)-1].(*SectPr); !ok {
+				t.Fatalf("expected sectPr as last item, got %T", items[len(items)-1])
+			}
+			for _, it := range items {
+				if tbl, ok := it.(*Table); ok {
+					t.Fatal("unexpected table:", tbl)
+				}
+			}
+			var buf bytes.Buffer
+			if _, err := newDoc.WriteTo(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+				t.Fatal(err)
+			}
+
+			est := tr.EstimateDocx(doc, "English")
+			if est.Segments != 0 || est.Characters != 0 || est.Tokens != 0 {
+				t.Fatalf("unexpected estimate: %+v", est)
+			}
+			for _, c := range est.Costs {
+				if c.Cost != 0 {
+					t.Fatalf("unexpected cost: %+v", c)
+				}
+			}
+			a := tr.AnalyzeDocx(doc, nil)
+			if a.Total.Segments != 0 || a.Total.Words != 0 {
+				t.Fatalf("unexpected analysis: %+v", a.Total)
+			}
+			if err := a.WriteCSV(&buf); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestTranslateDocxMedia(t *testing.T) {
+	doc := newTestDoc("hello")
+	if _, err := doc.AddParagraph().AddInlineDrawingFrom("testdata/fumiamayoko.png"); err != nil {
+		t.Fatal(err)
+	}
+	p := ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(text), nil
+	})
+	newDoc, err := NewTranslator("", "").WithProvider(p).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := newDoc.Document.Body.Items[1].(*Paragraph).Children[0].(*Run).Children[0].(*Drawing)
+	tgt, err := newDoc.ReferTarget(d.Inline.Graphic.GraphicData.Pic.BlipFill.Blip.Embed)
+	if err != nil || newDoc.Media(tgt[6:]) == nil {
+		t.Fatal("image not copied into the translated doc:", tgt, err)
+	}
+
+	data, err := os.ReadFile("testdata/fumiama.JPG")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = newDoc.AddImage(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = doc.AddParagraph().AddInlineDrawing(data); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.media) != 2 || len(newDoc.media) != 2 {
+		t.Fatal("media lists must be independent:", len(doc.media), len(newDoc.media))
+	}
+	for _, m := range newDoc.media {
+		if newDoc.Media(m.Name) != &newDoc.media[newDoc.mediaNameIdx[m.Name]] {
+			t.Fatal("broken media index for", m.Name)
+		}
+	}
+	var buf bytes.Buffer
+	if _, err = newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type hintRecorder map[string]string
+
+func (h hintRecorder) Translate(text, target string) (string, error) {
+	return h.TranslateWithHint(text, target, "")
+}
+
+func (h hintRecorder) TranslateWithHint(text, _, hint string) (string, error) {
+	h[text+"|"+hint] = hint
+	return strings.ToUpper(text), nil
+}
+
+func TestTranslateDocxStyleHints(t *testing.T) {
+	doc := newTestDoc("概述")
+	doc.AddParagraph().Style("Heading1").AddText("概述")
+	doc.AddParagraph().Style("Quote").AddText("引文")
+
+	h := hintRecorder{}
+	if _, err := NewTranslator("", "").WithProvider(h).TranslateDocx(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 3 || h["概述|"+styleHintHeading] == "" || h["引文|"+styleHintQuote] == "" {
+		t.Fatalf("unexpected hints: %q", h)
+	}
+	if _, ok := h["概述|"]; !ok {
+		t.Fatal("body paragraph must not get a hint")
+	}
+
+	h = hintRecorder{}
+	if _, err := NewTranslator("", "").WithProvider(h).WithStyleHints(nil).TranslateDocx(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 2 {
+		t.Fatalf("expected hints to be disabled: %q", h)
+	}
+	if DefaultStyleHints("2") != styleHintHeading || DefaultStyleHints("IntenseQuote") != styleHintQuote || DefaultStyleHints("Normal") != "" {
+		t.Fatal("unexpected default style hints")
+	}
+}
+
+func TestTranslateDocxStyleFilter(t *testing.T) {
+	source := func() *Docx {
+		doc := newTestDoc("正文")
+		doc.AddParagraph().Style("Code").AddText("fmt.Println")
+		doc.AddParagraph().Style("Quote").AddText("引文")
+		return doc
+	}
+	text := func(doc *Docx) string {
+		var texts []string
+		for _, item := range doc.Document.Body.Items {
+			if p, ok := item.(*Paragraph); ok {
+				texts = append(texts, paragraphText(p))
+			}
+		}
+		return strings.Join(texts, " ")
+	}
+	for _, c := range []struct {
+		filter StyleFilter
+		want   string
+	}{
+		{ExcludeStyles("code", "Quote"), "ZHENGWEN fmt.Println 引文"},
+		{IncludeStyles("", "Quote"), "ZHENGWEN fmt.Println YINWEN"},
+		{IncludeStyles("Code"), "正文 FMT.PRINTLN 引文"},
+		{nil, "ZHENGWEN FMT.PRINTLN YINWEN"},
+	} {
+		tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+			return strings.NewReplacer("正文", "ZHENGWEN", "引文", "YINWEN").Replace(strings.ToUpper(text)), nil
+		})).WithStyleFilter(c.filter)
+		newDoc, err := tr.TranslateDocx(source(), "English")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := text(newDoc); got != c.want {
+			t.Errorf("got %s, want %s", got, c.want)
+		}
+		doc := source()
+		if err := tr.TranslateDocxInPlace(doc, "English"); err != nil {
+			t.Fatal(err)
+		}
+		if got := text(doc); got != c.want {
+			t.Errorf("in place: got %s, want %s", got, c.want)
+		}
+	}
+}
+
+func TestTranslateDocxSkipPatterns(t *testing.T) {
+	source := func() *Docx {
+		return newTestDoc("Figure 3 示意图", "正文", "1.2.3", "ISO-9001", "ISO 标准")
+	}
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		return strings.ToUpper(strings.NewReplacer("示意图", "diagram", "正文", "body", "标准", "standard").Replace(text)), nil
+	})).WithSkipPatterns(append(DefaultSkipPatterns, regexp.MustCompile(`^Figure \d+`))...)
+	text := func(doc *Docx) string {
+		var texts []string
+		for _, item := range doc.Document.Body.Items {
+			if p, ok := item.(*Paragraph); ok {
+				texts = append(texts, paragraphText(p))
+			}
+		}
+		return strings.Join(texts, "|")
+	}
+	const want = "Figure 3 示意图|BODY|1.2.3|ISO-9001|ISO STANDARD"
+	newDoc, err := tr.TranslateDocx(source(), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := text(newDoc); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	doc := source()
+	if err := tr.TranslateDocxInPlace(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	if got := text(doc); got != want {
+		t.Errorf("in place: got %s, want %s", got, want)
+	}
+}
+
+func TestTranslateDocxSkipHidden(t *testing.T) {
+	source := func() *Docx {
+		doc := newTestDoc("可见", "隐藏")
+		hide := func(r *Run) *Run {
+			r.RunProperties = &RunProperties{Vanish: &Vanish{}}
+			return r
+		}
+		p := doc.Document.Body.Items[0].(*Paragraph)
+		p.Children = append(p.Children, hide(&Run{Children: textChildren("注释")}), &Run{Children: textChildren("文字")})
+		hide(doc.Document.Body.Items[1].(*Paragraph).Children[0].(*Run))
+		return doc
+	}
+	var (
+		mu   sync.Mutex
+		sent []string
+	)
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		mu.Lock()
+		sent = append(sent, text)
+		mu.Unlock()
+		return strings.NewReplacer("可见", "visible ", "文字", "text").Replace(text), nil
+	})).WithSkipHidden(true)
+	check := func(name, want string, doc *Docx) {
+		t.Helper()
+		var texts []string
+		for _, item := range doc.Document.Body.Items {
+			if p, ok := item.(*Paragraph); ok {
+				texts = append(texts, paragraphText(p))
+			}
+		}
+		if got := strings.Join(texts, "|"); got != want {
+			t.Errorf("%s: got %s, want %s", name, got, want)
+		}
+		for _, s := range sent {
+			if strings.Contains(s, "注释") || strings.Contains(s, "隐藏") {
+				t.Errorf("%s: hidden text sent for translation: %q", name, s)
+			}
+		}
+		sent = nil
+	}
+	newDoc, err := tr.TranslateDocx(source(), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("new document", "visible 注释text|隐藏", newDoc)
+	doc := source()
+	if err := tr.TranslateDocxInPlace(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	// 原地翻译时译文放入第一个可见的 Run, 隐藏的 Run 不变
+	check("in place", "visible text注释|隐藏", doc)
+}
+
+func TestTranslateDocxDocumentContext(t *testing.T) {
+	doc := newTestDoc("张三是工程师", "他很忙", "结束")
+	h := hintRecorder{}
+	opts := ContextOptions{Before: 1, After: 1, MaxRunes: 2, Summary: "人物介绍"}
+	if _, err := NewTranslator("", "").WithProvider(h).WithDocumentContext(opts).TranslateDocx(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 3 {
+		t.Fatalf("unexpected hints: %q", h)
+	}
+	var hint string
+	for k, v := range h {
+		if strings.HasPrefix(k, "他很忙|") {
+			hint = v
+		}
+	}
+	if !strings.Contains(hint, "[文档摘要] 人物介绍\n[上文] 张三…\n[下文] 结束") {
+		t.Fatalf("unexpected context: %q", hint)
+	}
+	for k := range h {
+		if strings.HasPrefix(k, "张三是工程师|") && strings.Contains(k, "[上文]") {
+			t.Fatal("the first paragraph has no preceding context:", k)
+		}
+	}
+}
+
+func TestBackTranslation(t *testing.T) {
+	if s := BackTranslationScore("The quick brown fox.", "the quick, brown fox"); s != 1 {
+		t.Fatal("case and punctuation must be ignored, got", s)
+	}
+	if s := BackTranslationScore("你好世界", "你好地球"); s != 0.5 {
+		t.Fatal("unexpected CJK score:", s)
+	}
+
+	dict := map[string]string{
+		"你好世界": "Hello world", "Hello world": "你好世界",
+		"苹果": "Banana", "Banana": "香蕉",
+	}
+	var targets []string
+	p := ProviderFunc(func(text, target string) (string, error) {
+		targets = append(targets, target)
+		return dict[text], nil
+	})
+	newDoc, report, err := NewTranslator("", "").WithProvider(p).WithBackTranslation(DefaultBackTranslationThreshold).
+		TranslateDocxWithReport(context.Background(), newTestDoc("你好世界", "苹果", "你好世界"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[1].(*Paragraph).String(); s != "Banana" {
+		t.Fatal("unexpected translation:", s)
+	}
+	if len(report.Segments) != 3 || report.Segments[0].Score != 1 || report.Segments[2].BackTranslation != "你好世界" {
+		t.Fatalf("unexpected report: %+v", report.Segments)
+	}
+	flagged := report.Flagged()
+	if len(flagged) != 1 || flagged[0].Index != 1 || flagged[0].BackTranslation != "香蕉" {
+		t.Fatalf("unexpected flagged segments: %+v", flagged)
+	}
+	// 相同的原文只翻译与回译一次
+	if strings.Join(targets, ",") != "English,中文,English,中文" {
+		t.Fatal("unexpected provider calls:", targets)
+	}
+}
+
+type reviewProvider struct{ prompts []string }
+
+func (p *reviewProvider) Translate(text, _ string) (string, error) {
+	return strings.ToUpper(text), nil
+}
+
+func (p *reviewProvider) Review(_, translation, _, prompt string) (string, error) {
+	p.prompts = append(p.prompts, prompt)
+	return strings.ReplaceAll(translation, "DOCX", "Docx") + "!", nil
+}
+
+func TestReviewPass(t *testing.T) {
+	var models []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req DashscopeRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		models = append(models, req.Model)
+		text := req.Messages[len(req.Messages)-1]["content"]
+		if strings.Contains(req.Messages[0]["content"], "审校") {
+			text = "reviewed " + text[strings.Index(text, "译文: ")+len("译文: "):]
+		} else {
+			text = strings.ToUpper(text)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []interface{}{map[string]interface{}{"message": map[string]interface{}{"content": text}}},
+		})
+	}))
+	defer srv.Close()
+	newDoc, err := NewTranslator("", srv.URL).WithReviewPass("qwen-max", "").TranslateDocx(newTestDoc("hello"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "reviewed HELLO" {
+		t.Fatal("unexpected reviewed translation:", s)
+	}
+	if strings.Join(models, ",") != "qwen-plus,qwen-max" {
+		t.Fatal("unexpected models:", models)
+	}
+
+	// 审校丢失了不翻译的内容时保留第一轮的译文
+	p := &reviewProvider{}
+	tr := NewTranslator("", "").WithProvider(p).WithDNT(NewDNTList("DOCX")).WithReviewPass("", "统一术语")
+	newDoc, err = tr.TranslateDocx(newTestDoc("go", "docx 与 DOCX"), "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := newDoc.Document.Body.Items[0].(*Paragraph).String(); s != "GO!" {
+		t.Fatal("unexpected reviewed translation:", s)
+	}
+	if s := newDoc.Document.Body.Items[1].(*Paragraph).String(); s != "DOCX 与 DOCX" {
+		t.Fatal("expected the first pass to be kept, got", s)
+	}
+	if len(p.prompts) != 2 || p.prompts[0] != "统一术语" {
+		t.Fatal("unexpected prompts:", p.prompts)
+	}
+}
+
+func TestTranslateDocxMulti(t *testing.T) {
+	calls := map[string]int{}
+	p := ProviderFunc(func(text, target string) (string, error) {
+		calls[target]++
+		if target == "de" && text == "b" {
+			return "", errors.New("boom")
+		}
+		return target + ":" + text, nil
+	})
+	docs, err := NewTranslator("", "").WithProvider(p).WithErrorPolicy(ErrorPolicyFailFast).
+		TranslateDocxMulti(newTestDoc("a", "b", "a"), []string{"en", "ja", "de", "en"})
+	var te TargetErrors
+	if !errors.As(err, &te) || len(te) != 1 || te["de"] == nil {
+		t.Fatal("expected a de error, got", err)
+	}
+	if len(docs) != 2 || calls["en"] != 2 || calls["ja"] != 2 {
+		t.Fatalf("unexpected result: %v %v", docs, calls)
+	}
+	if s := docs["ja"].Document.Body.Items[2].(*Paragraph).String(); s != "ja:a" {
+		t.Fatal("unexpected translation:", s)
+	}
+	if s := docs["en"].Document.Body.Items[2].(*Paragraph).String(); s != "en:a" {
+		t.Fatal("unexpected translation:", s)
+	}
+
+	// 原文档只分段一次, 各目标语言的翻译单元复制自同一个模板
+	tr := NewTranslator("", "")
+	plan := tr.newSegmentPlan(newTestDoc("a", "b"))
+	_, en := tr.preparePlan(plan, "English")
+	units := len(plan.units)
+	_, ja := tr.preparePlan(plan, "Japanese")
+	if len(plan.units) != units || len(en) != 2 || len(ja) != 2 ||
+		en[0].dst == ja[0].dst || en[0].text != ja[0].text || en[0].langTag == ja[0].langTag {
+		t.Fatalf("unexpected segments: %d units, %+v %+v", units, en, ja)
+	}
+}
+
+func TestTranslateDocxTone(t *testing.T) {
+	doc := newTestDoc("正文")
+	doc.AddParagraph().Style("Heading1").AddText("概述")
+
+	h := hintRecorder{}
+	cache := NewLRUCache(10)
+	tr := NewTranslator("", "").WithProvider(h).WithCache(cache).WithTone(ToneLegal)
+	if _, err := tr.TranslateDocx(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	legal := ToneLegal.hint()
+	if len(h) != 2 || h["正文|"+legal] == "" || h["概述|"+legal+"\n"+styleHintHeading] == "" {
+		t.Fatalf("unexpected hints: %q", h)
+	}
+
+	// 语体不同的译文不能命中缓存
+	if _, err := tr.WithTone(ToneMarketing).TranslateDocx(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 4 || h["正文|"+ToneMarketing.hint()] == "" {
+		t.Fatalf("unexpected hints: %q", h)
+	}
+}
+
+func TestTranslateDocxSections(t *testing.T) {
+	doc := newTestDoc("第一节", "第二节")
+	first := doc.Document.Body.Items[0].(*Paragraph)
+	first.Properties = &ParagraphProperties{SectPr: &SectPr{
+		PgSz:  &PgSz{W: 11906, H: 16838},
+		PgMar: &PgMar{Top: 1440, Left: 1800, Bottom: 1440, Right: 1800, Header: 851, Footer: 992},
+	}}
+	doc.Document.Body.Items = append(doc.Document.Body.Items, &SectPr{
+		Type:  &SectType{Val: "nextPage"},
+		PgSz:  &PgSz{W: 16838, H: 11906, Orient: "landscape"},
+		PgMar: &PgMar{Top: 720, Left: 720, Bottom: 720, Right: 720},
+		Cols:  &Cols{Num: 2, Space: 425},
+	})
+	// 经过一次读写, 确认页面设置能被解析
+	var buf bytes.Buffer
+	if _, err := doc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newDoc, err := newTestTranslator(t).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if newDoc, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Fatal(err)
+	}
+	items := newDoc.Document.Body.Items
+	sect, ok := items[len(items)-1].(*SectPr)
+	if !ok {
+		t.Fatalf("expected sectPr as last item, got %T", items[len(items)-1])
+	}
+	if sect.Type == nil || sect.Type.Val != "nextPage" || sect.PgSz.Orient != "landscape" || sect.PgSz.W != 16838 ||
+		sect.PgMar.Left != 720 || sect.Cols.Num != 2 || sect.Cols.Space != 425 {
+		t.Fatalf("unexpected section properties: %+v", sect)
+	}
+	p := items[0].(*Paragraph)
+	if p.Properties == nil || p.Properties.SectPr == nil || p.Properties.SectPr.PgMar.Left != 1800 {
+		t.Fatal("the first section was lost")
+	}
+}
+
+func TestTranslateDocxContentControls(t *testing.T) {
+	const body = `<w:sdt><w:sdtPr><w:alias w:val="Name"/><w:tag w:val="customer-name"/><w:id w:val="1001"/>` +
+		`<w:placeholder><w:docPart w:val="DefaultPlaceholder_1"/></w:placeholder><w:showingPlcHdr/><w:text/></w:sdtPr>` +
+		`<w:sdtContent><w:p><w:r><w:t>enter the customer name</w:t></w:r></w:p></w:sdtContent></w:sdt>` +
+		`<w:sdt><w:sdtPr><w:tag w:val="terms"/><w:id w:val="1002"/></w:sdtPr><w:sdtContent>` +
+		`<w:sdt><w:sdtPr><w:id w:val="1003"/></w:sdtPr><w:sdtContent><w:p><w:r><w:t>payment terms</w:t></w:r></w:p></w:sdtContent></w:sdt>` +
+		`</w:sdtContent></w:sdt>` +
+		`<w:p><w:r><w:t xml:space="preserve">status: </w:t></w:r><w:sdt><w:sdtPr><w:tag w:val="status"/><w:id w:val="1004"/>` +
+		`<w:dropDownList w:lastValue="d"><w:listItem w:displayText="draft" w:value="d"/><w:listItem w:displayText="final" w:value="f"/></w:dropDownList>` +
+		`</w:sdtPr><w:sdtContent><w:r><w:rPr><w:b/></w:rPr><w:t>draft</w:t></w:r></w:sdtContent></w:sdt></w:p>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	_ = doc.Document.Body.RangeParagraphs(func(p *Paragraph) error {
+		texts = append(texts, p.String())
+		return nil
+	})
+	if len(texts) != 3 {
+		t.Fatalf("content controls were not walked: %q", texts)
+	}
+
+	newDoc, err := newTestTranslator(t).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := readZip(t, buf.Bytes())["word/document.xml"]
+	for _, want := range []string{
+		`<w:sdtPr><w:alias w:val="Name"></w:alias><w:tag w:val="customer-name"></w:tag><w:id w:val="1001"></w:id>` +
+			`<w:placeholder><w:docPart w:val="DefaultPlaceholder_1"></w:docPart></w:placeholder><w:showingPlcHdr></w:showingPlcHdr><w:text></w:text></w:sdtPr>`,
+		`<w:t>ENTER THE CUSTOMER NAME</w:t>`,
+		`<w:sdtContent><w:sdt><w:sdtPr><w:id w:val="1003"></w:id></w:sdtPr><w:sdtContent><w:p><w:r><w:t>PAYMENT TERMS</w:t>`,
+		`<w:dropDownList w:lastValue="d"><w:listItem w:displayText="draft" w:value="d"></w:listItem>`,
+		`<w:t xml:space="preserve">STATUS: </w:t></w:r><w:sdt>`,
+		`<w:sdtContent><w:r><w:rPr><w:b></w:b></w:rPr><w:t>DRAFT</w:t></w:r></w:sdtContent></w:sdt></w:p>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("missing %s in %s", want, xml)
+		}
+	}
+
+	// 原地翻译同样进入内容控件
+	doc, err = Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := newTestTranslator(t).TranslateDocxInPlace(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	texts = texts[:0]
+	_ = doc.Document.Body.RangeParagraphs(func(p *Paragraph) error {
+		texts = append(texts, p.String())
+		return nil
+	})
+	if texts[0] != "ENTER THE CUSTOMER NAME" || texts[1] != "PAYMENT TERMS" {
+		t.Fatalf("content controls were not translated in place: %q", texts)
+	}
+}
+
+func TestTranslateDocxMath(t *testing.T) {
+	const (
+		inline  = `<m:r><m:t>E</m:t></m:r><m:r><m:t>=</m:t></m:r><m:sSup><m:e><m:r><m:t>mc</m:t></m:r></m:e><m:sup><m:r><m:t>2</m:t></m:r></m:sup></m:sSup>`
+		display = `<m:oMath><m:f><m:num><m:r><m:t>a</m:t></m:r></m:num><m:den><m:r><m:t>b</m:t></m:r></m:den></m:f></m:oMath>`
+		body    = `<w:p><w:r><w:t xml:space="preserve">energy is </w:t></w:r><m:oMath>` + inline + `</m:oMath><w:r><w:t xml:space="preserve"> in theory</w:t></w:r></w:p>` +
+			`<w:p><m:oMathPara>` + display + `</m:oMathPara></w:p>`
+	)
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sources []string
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		sources = append(sources, text)
+		return strings.ToUpper(text), nil
+	}))
+	newDoc, err := tr.TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sources) != 1 || sources[0] != "energy is ⟪1/⟫ in theory" {
+		t.Fatalf("unexpected sources: %q", sources)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := readZip(t, buf.Bytes())["word/document.xml"]
+	for _, want := range []string{
+		`xmlns:m="` + XMLNS_M + `"`,
+		`<w:t xml:space="preserve">ENERGY IS </w:t></w:r><m:oMath>` + inline + `</m:oMath><w:r><w:t xml:space="preserve"> IN THEORY</w:t></w:r>`,
+		`<w:p><m:oMathPara>` + display + `</m:oMathPara></w:p>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("missing %s in %s", want, xml)
+		}
+	}
+	if _, err := Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTranslateDocxBookmarks(t *testing.T) {
+	const body = `<w:p><w:bookmarkStart w:id="0" w:name="_Toc1"/><w:r><w:t>chapter one</w:t></w:r><w:bookmarkEnd w:id="0"/></w:p>` +
+		`<w:p><w:r><w:t xml:space="preserve">see </w:t></w:r><w:bookmarkStart w:id="1" w:name="term"/><w:r><w:t>the term</w:t></w:r>` +
+		`<w:bookmarkEnd w:id="1"/><w:r><w:t xml:space="preserve"> below</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>summary</w:t></w:r><w:bookmarkStart w:id="2" w:name="span"/></w:p><w:bookmarkEnd w:id="2"/>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sources []string
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		sources = append(sources, text)
+		return strings.ToUpper(text), nil
+	}))
+	newDoc, err := tr.TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(sources, "|") != "chapter one|see ⟪1/⟫the term⟪2/⟫ below|summary" {
+		t.Fatalf("unexpected sources: %q", sources)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := readZip(t, buf.Bytes())["word/document.xml"]
+	for _, want := range []string{
+		`<w:p><w:bookmarkStart w:id="0" w:name="_Toc1"></w:bookmarkStart><w:r><w:t>CHAPTER ONE</w:t></w:r><w:bookmarkEnd w:id="0"></w:bookmarkEnd></w:p>`,
+		`<w:t xml:space="preserve">SEE </w:t></w:r><w:bookmarkStart w:id="1" w:name="term"></w:bookmarkStart><w:r><w:t>THE TERM</w:t></w:r>` +
+			`<w:bookmarkEnd w:id="1"></w:bookmarkEnd><w:r><w:t xml:space="preserve"> BELOW</w:t>`,
+		`<w:t>SUMMARY</w:t></w:r><w:bookmarkStart w:id="2" w:name="span"></w:bookmarkStart></w:p><w:bookmarkEnd w:id="2"></w:bookmarkEnd>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("missing %s in %s", want, xml)
+		}
+	}
+}
+
+func TestTranslateDocxNestedTables(t *testing.T) {
+	const (
+		inner = `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>inner</w:t></w:r></w:p>` +
+			`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>deepest</w:t></w:r></w:p></w:tc></w:tr></w:tbl></w:tc></w:tr></w:tbl>`
+		body = `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>before</w:t></w:r></w:p>` + inner +
+			`<w:p><w:r><w:t>after</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`
+	)
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sources []string
+	tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+		sources = append(sources, text)
+		return strings.ToUpper(text), nil
+	}))
+	newDoc, err := tr.TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(sources, "|") != "before|inner|deepest|after" {
+		t.Fatalf("unexpected sources: %q", sources)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := readZip(t, buf.Bytes())["word/document.xml"]
+	// 嵌套的表格保持在原来的位置, 以表格结尾的单元格补上空段落
+	want := `<w:tbl><w:tr><w:tc><w:p><w:r><w:t>BEFORE</w:t></w:r></w:p><w:tbl>` +
+		`<w:tr><w:tc><w:p><w:r><w:t>INNER</w:t></w:r></w:p><w:tbl><w:tr><w:tc><w:p><w:r><w:t>DEEPEST</w:t></w:r></w:p></w:tc></w:tr></w:tbl><w:p></w:p></w:tc></w:tr></w:tbl>` +
+		`<w:p><w:r><w:t>AFTER</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`
+	if !strings.Contains(regexp.MustCompile(`<w:tblPr>.*?</w:tblPr>|<w:tblGrid>.*?</w:tblGrid>|<w:trPr>.*?</w:trPr>`).ReplaceAllString(xml, ""), want) {
+		t.Fatal("nested tables are out of order:", xml)
+	}
+}
+
+func TestTranslateDocxMergedCells(t *testing.T) {
+	const body = `<w:tbl><w:tblGrid><w:gridCol w:w="2000"/><w:gridCol w:w="2000"/><w:gridCol w:w="2000"/></w:tblGrid>` +
+		`<w:tr><w:trPr><w:tblHeader/></w:trPr><w:tc><w:tcPr><w:tcW w:w="4000" w:type="dxa"/><w:gridSpan w:val="2"/><w:vMerge w:val="restart"/></w:tcPr>` +
+		`<w:p><w:r><w:t>merged</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>right</w:t></w:r></w:p></w:tc></w:tr>` +
+		`<w:tr><w:tc><w:tcPr><w:gridSpan w:val="2"/><w:vMerge/></w:tcPr><w:p/></w:tc><w:tc><w:tcPr><w:hMerge w:val="restart"/></w:tcPr><w:p><w:r><w:t>legacy</w:t></w:r></w:p></w:tc></w:tr>` +
+		`<w:tr><w:trPr><w:gridBefore w:val="1"/><w:wBefore w:w="2000" w:type="dxa"/></w:trPr><w:tc><w:p><w:r><w:t>offset</w:t></w:r></w:p></w:tc>` +
+		`<w:tc><w:p><w:r><w:t>last</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newDoc, err := newTestTranslator(t).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := readZip(t, buf.Bytes())["word/document.xml"]
+	for _, want := range []string{
+		`<w:trPr><w:tblHeader></w:tblHeader></w:trPr>`,
+		`<w:tcPr><w:tcW w:w="4000" w:type="dxa"></w:tcW><w:gridSpan w:val="2"></w:gridSpan><w:vMerge w:val="restart"></w:vMerge></w:tcPr><w:p><w:r><w:t>MERGED</w:t>`,
+		`<w:tcPr><w:gridSpan w:val="2"></w:gridSpan><w:vMerge></w:vMerge></w:tcPr>`,
+		`<w:tcPr><w:hMerge w:val="restart"></w:hMerge></w:tcPr><w:p><w:r><w:t>LEGACY</w:t>`,
+		`<w:trPr><w:gridBefore w:val="1"></w:gridBefore><w:wBefore w:w="2000" w:type="dxa"></w:wBefore></w:trPr><w:tc><w:p><w:r><w:t>OFFSET</w:t>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("missing %s in %s", want, xml)
+		}
+	}
+}
+
+func TestTranslateDocxRunBreaks(t *testing.T) {
+	const body = `<w:p><w:r><w:t>name</w:t><w:tab/><w:t>value</w:t><w:br/><w:t>e</w:t><w:noBreakHyphen/><w:t>mail</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:t>end of page</w:t><w:br w:type="page"/><w:t>next page</w:t></w:r></w:p>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+
+	for _, inPlace := range []bool{false, true} {
+		doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sources []string
+		tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+			sources = append(sources, text)
+			return strings.ToUpper(text), nil
+		}))
+		newDoc := doc
+		if inPlace {
+			err = tr.TranslateDocxInPlace(doc, "English")
+		} else {
+			newDoc, err = tr.TranslateDocx(doc, "English")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"name\tvalue\ne‑mail", "end of page⟪1/⟫next page"}
+		if inPlace {
+			want[1] = "end of pagenext page"
+		}
+		if strings.Join(sources, "|") != strings.Join(want, "|") {
+			t.Fatalf("unexpected sources: %q", sources)
+		}
+		buf.Reset()
+		if _, err := newDoc.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		xml := readZip(t, buf.Bytes())["word/document.xml"]
+		if want := `<w:t>NAME</w:t><w:tab></w:tab><w:t>VALUE</w:t><w:br></w:br><w:t>E</w:t><w:noBreakHyphen></w:noBreakHyphen><w:t>MAIL</w:t>`; !strings.Contains(xml, want) {
+			t.Errorf("inPlace=%v: missing %s in %s", inPlace, want, xml)
+		}
+		if !inPlace && !strings.Contains(xml, `<w:t>END OF PAGE</w:t></w:r><w:r><w:br w:type="page"></w:br></w:r><w:r><w:t>NEXT PAGE</w:t>`) {
+			t.Errorf("page break not kept in %s", xml)
+		}
+	}
+}
+
+func TestTranslateDocxAltTextAndCaptions(t *testing.T) {
+	build := func() *Docx {
+		doc := newTestDoc()
+		r, err := doc.AddParagraph().AddInlineDrawingFrom("testdata/fumiamayoko.png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		docPr := r.Children[0].(*Drawing).Inline.DocPr
+		docPr.Descr, docPr.Title = "a smiling cat", "cat"
+		p := doc.AddParagraph().Style("Caption")
+		p.Children = append(p.Children,
+			&Run{Children: []interface{}{&Text{Text: "Figure ", XMLSpace: "preserve"}}},
+			&Run{Children: []interface{}{&FldChar{FldCharType: "begin"}}},
+			&Run{InstrText: ` SEQ Figure \* ARABIC `},
+			&Run{Children: []interface{}{&FldChar{FldCharType: "separate"}}},
+			&Run{Children: []interface{}{&Text{Text: "1"}}},
+			&Run{Children: []interface{}{&FldChar{FldCharType: "end"}}},
+			&Run{Children: []interface{}{&Text{Text: " cat", XMLSpace: "preserve"}}},
+		)
+		return doc
+	}
+	altOf := func(doc *Docx) *WPDocPr {
+		return doc.Document.Body.Items[0].(*Paragraph).Children[0].(*Run).Children[0].(*Drawing).Inline.DocPr
+	}
+
+	doc := build()
+	h := hintRecorder{}
+	newDoc, err := NewTranslator("", "").WithProvider(h).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"a smiling cat|" + altHint, "cat|" + altTitleHint, "Figure ⟪1/⟫ cat|" + joinHints(styleHintCaption, inlineHint)} {
+		if _, ok := h[want]; !ok {
+			t.Fatalf("missing %q in %q", want, h)
+		}
+	}
+	if alt := altOf(newDoc); alt.Descr != "A SMILING CAT" || alt.Title != "CAT" {
+		t.Fatal("alt text not translated:", alt.Descr, alt.Title)
+	}
+	if alt := altOf(doc); alt.Descr != "a smiling cat" || alt.Title != "cat" {
+		t.Fatal("the source document was modified:", alt.Descr, alt.Title)
+	}
+	var buf bytes.Buffer
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := readZip(t, buf.Bytes())["word/document.xml"]
+	for _, want := range []string{`descr="A SMILING CAT" title="CAT"`, `<w:instrText> SEQ Figure \* ARABIC </w:instrText>`, `<w:t>1</w:t>`, `<w:t xml:space="preserve"> CAT</w:t>`} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("missing %s in %s", want, xml)
+		}
+	}
+
+	doc = build()
+	if err := NewTranslator("", "").WithProvider(h).TranslateDocxInPlace(doc, "English"); err != nil {
+		t.Fatal(err)
+	}
+	if alt := altOf(doc); alt.Descr != "A SMILING CAT" || alt.Title != "CAT" {
+		t.Fatal("alt text not translated in place:", alt.Descr, alt.Title)
+	}
+}
+
+func TestTranslateDocxRevisions(t *testing.T) {
+	const body = `<w:p><w:r><w:t xml:space="preserve">the </w:t></w:r>` +
+		`<w:ins w:id="1" w:author="ann" w:date="2024-01-02T00:00:00Z"><w:r><w:rPr><w:b/></w:rPr><w:t>new</w:t></w:r></w:ins>` +
+		`<w:del w:id="2" w:author="ann"><w:r><w:delText>old</w:delText></w:r></w:del>` +
+		`<w:r><w:t xml:space="preserve"> plan</w:t></w:r></w:p>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	parse := func() *Docx {
+		doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	translate := func(policy RevisionPolicy, inPlace bool) (string, string) {
+		var sources []string
+		tr := NewTranslator("", "").WithRevisionPolicy(policy).WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+			sources = append(sources, text)
+			return strings.ToUpper(text), nil
+		}))
+		doc := parse()
+		newDoc := doc
+		var err error
+		if inPlace {
+			err = tr.TranslateDocxInPlace(doc, "English")
+		} else {
+			newDoc, err = tr.TranslateDocx(doc, "English")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Reset()
+		if _, err := newDoc.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return strings.Join(sources, "|"), readZip(t, buf.Bytes())["word/document.xml"]
+	}
+
+	sources, xml := translate(RevisionPolicyAccept, false)
+	if sources != "the new plan" {
+		t.Fatalf("unexpected sources: %q", sources)
+	}
+	if strings.Contains(xml, "w:ins") || strings.Contains(xml, "w:del") || !strings.Contains(xml, "THE NEW PLAN") {
+		t.Error("revisions not accepted:", xml)
+	}
+
+	sources, xml = translate(RevisionPolicyPreserve, false)
+	if sources != "the ⟪1⟫new⟪/1⟫⟪2/⟫ plan" {
+		t.Fatalf("unexpected sources: %q", sources)
+	}
+	for _, want := range []string{
+		`<w:ins w:id="1" w:author="ann" w:date="2024-01-02T00:00:00Z"><w:r><w:rPr><w:b></w:b></w:rPr><w:t>NEW</w:t></w:r></w:ins>`,
+		`<w:del w:id="2" w:author="ann"><w:r><w:delText>old</w:delText></w:r></w:del>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("missing %s in %s", want, xml)
+		}
+	}
+
+	sources, xml = translate(RevisionPolicyAccept, true)
+	if sources != "the new plan" || strings.Contains(xml, "w:ins") || strings.Contains(xml, "w:del") || !strings.Contains(xml, "THE NEW PLAN") {
+		t.Errorf("revisions not accepted in place: %q %s", sources, xml)
+	}
+	sources, xml = translate(RevisionPolicyPreserve, true)
+	if sources != "the new plan" || !strings.Contains(xml, `<w:ins w:id="1"`) || !strings.Contains(xml, `<w:delText>old</w:delText>`) {
+		t.Errorf("revisions not preserved in place: %q %s", sources, xml)
+	}
+}
+
+func TestTranslateDocxTrackChanges(t *testing.T) {
+	const body = `<w:p><w:bookmarkStart w:id="7" w:name="intro"/><w:r><w:rPr><w:b/></w:rPr><w:t>the plan</w:t></w:r><w:bookmarkEnd w:id="7"/></w:p>` +
+		`<w:p><w:r><w:t xml:space="preserve">see </w:t></w:r><w:hyperlink w:anchor="intro"><w:r><w:t>intro</w:t></w:r></w:hyperlink></w:p>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newDoc, err := newTestTranslator(t).WithTrackChanges("MT").TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := regexp.MustCompile(` w:date="[^"]+"`).ReplaceAllString(readZip(t, buf.Bytes())["word/document.xml"], "")
+	for _, want := range []string{
+		`<w:p><w:bookmarkStart w:id="7" w:name="intro"></w:bookmarkStart>` +
+			`<w:del w:id="8" w:author="MT"><w:r><w:rPr><w:b></w:b></w:rPr><w:delText>the plan</w:delText></w:r></w:del>` +
+			`<w:ins w:id="9" w:author="MT"><w:r><w:rPr><w:b></w:b></w:rPr><w:t>THE PLAN</w:t></w:r></w:ins>` +
+			`<w:bookmarkEnd w:id="7"></w:bookmarkEnd></w:p>`,
+		// 超链接中的文字在超链接之内标记为删除与插入
+		`<w:del w:id="10" w:author="MT"><w:r><w:delText xml:space="preserve">see </w:delText></w:r></w:del>` +
+			`<w:hyperlink w:anchor="intro"><w:del w:id="11" w:author="MT"><w:r><w:delText>intro</w:delText></w:r></w:del></w:hyperlink>` +
+			`<w:ins w:id="12" w:author="MT"><w:r><w:t xml:space="preserve">SEE </w:t></w:r></w:ins>` +
+			`<w:hyperlink w:anchor="intro"><w:ins w:id="13" w:author="MT"><w:r><w:t>INTRO</w:t></w:r></w:ins></w:hyperlink>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("missing %s in %s", want, xml)
+		}
+	}
+
+	// 读取时超链接中的修订保留
+	doc, err = Parse(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := doc.Document.Body.Items[1].(*Paragraph)
+	var revisions []interface{}
+	for _, child := range p.Children {
+		if link, ok := child.(*Hyperlink); ok {
+			revisions = append(revisions, link.Revision)
+		}
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("unexpected hyperlinks: %+v", p.Children)
+	}
+	if del, ok := revisions[0].(*Del); !ok || del.ID != "11" {
+		t.Errorf("unexpected deleted link: %+v", revisions[0])
+	}
+	if ins, ok := revisions[1].(*Ins); !ok || ins.ID != "13" {
+		t.Errorf("unexpected inserted link: %+v", revisions[1])
+	}
+}
+
+func TestTranslateDocxInterleaved(t *testing.T) {
+	const body = `<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:bookmarkStart w:id="1" w:name="term"/><w:r><w:rPr><w:b/></w:rPr><w:t>the term</w:t></w:r><w:bookmarkEnd w:id="1"/></w:p>` +
+		`<w:tbl><w:tr><w:tc><w:p><w:r><w:t>cell</w:t></w:r></w:p></w:tc></w:tr></w:tbl>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	translate := func(tr *Translator) string {
+		doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		newDoc, err := tr.WithOutputMode(OutputModeBilingualInterleaved).TranslateDocx(doc, "English")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if _, err := newDoc.WriteTo(&out); err != nil {
+			t.Fatal(err)
+		}
+		return readZip(t, out.Bytes())["word/document.xml"]
+	}
+
+	xml := translate(newTestTranslator(t))
+	for _, want := range []string{
+		`<w:p><w:pPr><w:numPr><w:ilvl w:val="0"></w:ilvl><w:numId w:val="1"></w:numId></w:numPr></w:pPr><w:bookmarkStart w:id="1" w:name="term"></w:bookmarkStart>` +
+			`<w:r><w:rPr><w:b></w:b></w:rPr><w:t>THE TERM</w:t></w:r><w:bookmarkEnd w:id="1"></w:bookmarkEnd></w:p>` +
+			`<w:p><w:pPr></w:pPr><w:r><w:rPr><w:b></w:b><w:i></w:i><w:color w:val="808080"></w:color></w:rPr><w:t>the term</w:t></w:r></w:p>`,
+		`<w:tc><w:p><w:r><w:t>CELL</w:t></w:r></w:p><w:p><w:r><w:rPr><w:i></w:i><w:color w:val="808080"></w:color></w:rPr><w:t>cell</w:t></w:r></w:p></w:tc>`,
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("missing %s in %s", want, xml)
+		}
+	}
+
+	xml = translate(newTestTranslator(t).WithInterleaveOptions(InterleaveOptions{SourceFirst: true, StyleID: "Quote"}))
+	want := `<w:tc><w:p><w:pPr><w:pStyle w:val="Quote"></w:pStyle></w:pPr><w:r><w:t>cell</w:t></w:r></w:p><w:p><w:r><w:t>CELL</w:t></w:r></w:p></w:tc>`
+	if !strings.Contains(xml, want) {
+		t.Errorf("missing %s in %s", want, xml)
+	}
+}
+
+func TestTranslateDocxBilingualTable(t *testing.T) {
+	const body = `<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>title</w:t></w:r></w:p>` +
+		`<w:p><w:pPr><w:sectPr><w:pgSz w:w="11906" w:h="16838"/></w:sectPr></w:pPr><w:r><w:t>end of part</w:t></w:r></w:p>` +
+		`<w:p/><w:p><w:r><w:t>body</w:t></w:r></w:p>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newDoc, err := newTestTranslator(t).WithOutputMode(OutputModeBilingualTable).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := readZip(t, buf.Bytes())["word/document.xml"]
+	cell := func(p string) string {
+		return `<w:tc><w:tcPr><w:tcW w:w="4513" w:type="dxa"></w:tcW></w:tcPr>` + p + `</w:tc>`
+	}
+	for _, want := range []string{
+		`<w:tblGrid><w:gridCol w:w="4513"></w:gridCol><w:gridCol w:w="4513"></w:gridCol></w:tblGrid>`,
+		`<w:tr><w:trPr></w:trPr>` +
+			cell(`<w:p><w:pPr><w:pStyle w:val="Heading1"></w:pStyle></w:pPr><w:r><w:t>title</w:t></w:r></w:p>`) +
+			cell(`<w:p><w:pPr><w:pStyle w:val="Heading1"></w:pStyle></w:pPr><w:r><w:t>TITLE</w:t></w:r></w:p>`) + `</w:tr>`,
+		cell(`<w:p><w:pPr></w:pPr><w:r><w:t>END OF PART</w:t></w:r></w:p>`) + `</w:tr></w:tbl>` +
+			`<w:p><w:pPr><w:sectPr><w:pgSz w:w="11906" w:h="16838"></w:pgSz></w:sectPr></w:pPr></w:p><w:tbl>`,
+		cell(`<w:p></w:p>`) + cell(`<w:p></w:p>`),
+		cell(`<w:p><w:r><w:t>body</w:t></w:r></w:p>`) + cell(`<w:p><w:r><w:t>BODY</w:t></w:r></w:p>`),
+	} {
+		if !strings.Contains(xml, want) {
+			t.Errorf("missing %s in %s", want, xml)
+		}
+	}
+}
+
+func TestTranslateDocxSourceComments(t *testing.T) {
+	doc := newTestDoc("first\tline", "")
+	newDoc, err := newTestTranslator(t).WithOutputMode(OutputModeSourceComments).WithCommentAuthor("Review").TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	files := readZip(t, buf.Bytes())
+	dateRe := regexp.MustCompile(` w:date="[^"]+"`)
+	for name, want := range map[string]string{
+		"word/document.xml": `<w:p><w:commentRangeStart w:id="0"></w:commentRangeStart><w:r><w:rPr></w:rPr><w:t>FIRST</w:t><w:tab></w:tab><w:t>LINE</w:t></w:r>` +
+			`<w:commentRangeEnd w:id="0"></w:commentRangeEnd><w:r><w:commentReference w:id="0"></w:commentReference></w:r></w:p>`,
+		"word/comments.xml":            `<w:comment w:id="0" w:author="Review"><w:p><w:r><w:t>first</w:t><w:tab></w:tab><w:t>line</w:t></w:r></w:p></w:comment></w:comments>`,
+		"word/_rels/document.xml.rels": `Type="` + relComments + `" Target="comments.xml"`,
+		"[Content_Types].xml":          `<Override PartName="/word/comments.xml" ContentType="` + contentTypeComments + `"/>`,
+	} {
+		if got := dateRe.ReplaceAllString(files[name], ""); !strings.Contains(got, want) {
+			t.Errorf("%s: missing %s in %s", name, want, got)
+		}
+	}
+	if strings.Count(files["word/document.xml"], "<w:commentRangeStart") != 1 {
+		t.Errorf("empty paragraphs must not get comments: %s", files["word/document.xml"])
+	}
+}
+
+func TestTranslateDocxHiddenSource(t *testing.T) {
+	const body = `<w:p><w:bookmarkStart w:id="1" w:name="plan"/><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">the </w:t></w:r><w:r><w:t>plan</w:t></w:r><w:bookmarkEnd w:id="1"/></w:p>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newDoc, err := newTestTranslator(t).WithOutputMode(OutputModeHiddenSource).TranslateDocx(doc, "English")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if _, err := newDoc.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	xml := readZip(t, buf.Bytes())["word/document.xml"]
+	want := `<w:p><w:bookmarkStart w:id="1" w:name="plan"></w:bookmarkStart>` +
+		`<w:r><w:rPr><w:b></w:b><w:vanish></w:vanish></w:rPr><w:t xml:space="preserve">the </w:t></w:r>` +
+		`<w:r><w:rPr><w:vanish></w:vanish></w:rPr><w:t>plan</w:t></w:r>` +
+		`<w:r><w:rPr><w:vanish></w:vanish></w:rPr><w:t xml:space="preserve"> </w:t></w:r>` +
+		`<w:r><w:rPr><w:b></w:b></w:rPr><w:t>THE PLAN</w:t></w:r><w:bookmarkEnd w:id="1"></w:bookmarkEnd></w:p>`
+	if !strings.Contains(xml, want) {
+		t.Errorf("missing %s in %s", want, xml)
+	}
+}
+
+func TestTranslateDocxLanguageTags(t *testing.T) {
+	const body = `<w:p><w:r><w:rPr><w:lang w:val="zh-CN" w:eastAsia="zh-CN"/></w:rPr><w:t>text</w:t></w:r></w:p>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	for _, c := range []struct {
+		target, run, style string
+	}{
+		{"French", `<w:lang w:val="fr-FR" w:eastAsia="zh-CN"></w:lang>`, `<w:lang w:val="fr-FR" w:eastAsia="zh-CN" w:bidi="ar-SA"/>`},
+		{"ja_jp", `<w:lang w:val="zh-CN" w:eastAsia="ja-JP"></w:lang>`, `<w:lang w:val="en-US" w:eastAsia="ja-JP" w:bidi="ar-SA"/>`},
+		{"Hebrew", `<w:lang w:val="zh-CN" w:eastAsia="zh-CN" w:bidi="he-IL"></w:lang>`, `<w:lang w:val="en-US" w:eastAsia="zh-CN" w:bidi="he-IL"/>`},
+	} {
+		doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		newDoc, err := newTestTranslator(t).TranslateDocx(doc, c.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Reset()
+		if _, err := newDoc.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		files := readZip(t, buf.Bytes())
+		if !strings.Contains(files["word/document.xml"], c.run) {
+			t.Errorf("%s: missing %s in %s", c.target, c.run, files["word/document.xml"])
+		}
+		if !strings.Contains(files["word/styles.xml"], c.style) {
+			t.Errorf("%s: missing %s in styles", c.target, c.style)
+		}
+		if !strings.Contains(paragraphText(doc.Document.Body.Items[0].(*Paragraph)), "text") ||
+			doc.Document.Body.Items[0].(*Paragraph).Children[0].(*Run).RunProperties.Lang.Val != "zh-CN" {
+			t.Errorf("%s: source document modified", c.target)
+		}
+	}
+}
+
+func TestLanguageTag(t *testing.T) {
+	for lang, want := range map[string]string{
+		"English":       "en-US",
+		"繁體中文":          "zh-TW",
+		"EN-gb":         "en-GB",
+		"zh_tw":         "zh-TW",
+		"zh-Hans":       "zh-Hans",
+		"ZH-HANS-cn":    "zh-Hans-CN",
+		"sr-Latn-RS":    "sr-Latn-RS",
+		"sr_latn_rs":    "sr-Latn-RS",
+		"es-419":        "es-419",
+		"de-CH-1996":    "de-CH-1996",
+		"en-US-x-Twain": "en-US-x-twain",
+		"Klingon":       "",
+	} {
+		if got := languageTag(lang); got != want {
+			t.Errorf("languageTag(%q) = %q, want %q", lang, got, want)
+		}
+	}
+}
+
+func TestLanguageFileName(t *testing.T) {
+	for _, c := range []struct{ name, lang, want string }{
+		{"a.docx", "Japanese", "a.ja.docx"},
+		{"dir/a.b.docx", "zh-TW", "dir/a.b.zh.docx"},
+		{"a.docx", "Klingon (pIqaD)", "a.klingon-piqad.docx"},
+		{"a.docx", "../../etc/passwd", "a.etc-passwd.docx"},
+		{"a.docx", "克林贡语", "a.克林贡语.docx"},
+		{"a", "?", "a.translated"},
+	} {
+		if got := LanguageFileName(c.name, c.lang); got != c.want {
+			t.Errorf("LanguageFileName(%q, %q) = %q, want %q", c.name, c.lang, got, c.want)
+		}
+	}
+}
+
+func TestTranslateDocxRightToLeft(t *testing.T) {
+	translate := func(body, target string) string {
+		var buf bytes.Buffer
+		if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+			if name != "word/document.xml" {
+				return nil
+			}
+			return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+		}, nil)
+		doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		newDoc, err := newTestTranslator(t).TranslateDocx(doc, target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Reset()
+		if _, err := newDoc.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		return readZip(t, buf.Bytes())["word/document.xml"]
+	}
+
+	xml := translate(`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>text</w:t></w:r></w:p><w:sectPr><w:pgSz w:w="11906" w:h="16838"/></w:sectPr>`, "Arabic")
+	want := `<w:p><w:pPr><w:bidi></w:bidi></w:pPr><w:r><w:rPr><w:b></w:b><w:rtl></w:rtl></w:rPr><w:t>TEXT</w:t></w:r></w:p>` +
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"></w:pgSz><w:bidi></w:bidi><w:rtlGutter></w:rtlGutter></w:sectPr>`
+	if !strings.Contains(xml, want) {
+		t.Errorf("missing %s in %s", want, xml)
+	}
+
+	xml = translate(`<w:p><w:pPr><w:bidi/></w:pPr><w:r><w:rPr><w:rtl/></w:rPr><w:t>text</w:t></w:r></w:p>`+
+		`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/><w:bidi/><w:rtlGutter/></w:sectPr>`, "English")
+	want = `<w:p><w:pPr></w:pPr><w:r><w:rPr></w:rPr><w:t>TEXT</w:t></w:r></w:p><w:sectPr><w:pgSz w:w="11906" w:h="16838"></w:pgSz></w:sectPr>`
+	if !strings.Contains(xml, want) {
+		t.Errorf("missing %s in %s", want, xml)
+	}
+}
+
+func TestTranslateDocxFontMap(t *testing.T) {
+	const body = `<w:p><w:r><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri"/></w:rPr><w:t>one</w:t></w:r></w:p><w:p><w:r><w:t>two</w:t></w:r></w:p>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	for _, c := range []struct {
+		target string
+		fonts  FontMap
+		want   string
+	}{
+		{"Chinese", DefaultFontMaps["zh"], `<w:r><w:rPr><w:rFonts w:ascii="Calibri" w:eastAsia="等线" w:hAnsi="Calibri"></w:rFonts></w:rPr><w:t>ONE</w:t></w:r></w:p>` +
+			`<w:p><w:r><w:rPr><w:rFonts w:eastAsia="宋体"></w:rFonts></w:rPr><w:t>TWO</w:t></w:r></w:p>`},
+		{"Japanese", FontMap{"CALIBRI": "Meiryo"}, `<w:rFonts w:ascii="Calibri" w:eastAsia="Meiryo" w:hAnsi="Calibri"></w:rFonts></w:rPr><w:t>ONE</w:t></w:r></w:p><w:p><w:r><w:t>TWO</w:t></w:r></w:p>`},
+		{"English", DefaultFontMaps["zh"], `<w:rFonts w:ascii="Calibri" w:hAnsi="Calibri"></w:rFonts></w:rPr><w:t>ONE</w:t>`},
+	} {
+		doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tr := newTestTranslator(t).WithFontMap(c.target, c.fonts)
+		if c.target == "English" {
+			tr.WithFontMap("Chinese", c.fonts)
+		}
+		newDoc, err := tr.TranslateDocx(doc, c.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf.Reset()
+		if _, err := newDoc.WriteTo(&buf); err != nil {
+			t.Fatal(err)
+		}
+		if xml := readZip(t, buf.Bytes())["word/document.xml"]; !strings.Contains(xml, c.want) {
+			t.Errorf("%s: missing %s in %s", c.target, c.want, xml)
+		}
+	}
+}
+
+func TestTranslateDocxPageBreaks(t *testing.T) {
+	const body = `<w:p><w:pPr><w:pageBreakBefore/></w:pPr><w:r><w:t>one</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:br w:type="page"/></w:r><w:r><w:t>two</w:t></w:r><w:r><w:br w:type="column"/></w:r></w:p>` +
+		`<w:p><w:pPr><w:sectPr><w:type w:val="nextPage"/></w:sectPr></w:pPr><w:r><w:t>three</w:t></w:r></w:p>`
+	var buf bytes.Buffer
+	if _, err := New().WithDefaultTheme().WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := rewriteZip(t, buf.Bytes(), func(name string, content []byte) []byte {
+		if name != "word/document.xml" {
+			return nil
+		}
+		return bytes.Replace(content, []byte("<w:body>"), []byte("<w:body>"+body), 1)
+	}, nil)
+	translate := func(mode OutputMode) string {
+		doc, err := Parse(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 译文丢失了分页符的标记, 段落两端的分页符仍留在原来的位置
+		tr := NewTranslator("", "").WithProvider(ProviderFunc(func(text, _ string) (string, error) {
+			return strings.ToUpper(inlineTagRe.ReplaceAllString(text, "")), nil
+		}))
+		newDoc, err := tr.WithOutputMode(mode).TranslateDocx(doc, "English")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if _, err := newDoc.WriteTo(&out); err != nil {
+			t.Fatal(err)
+		}
+		return readZip(t, out.Bytes())["word/document.xml"]
+	}
+
+	xml := translate(OutputModeTranslation)
+	want := `<w:p><w:pPr><w:pageBreakBefore></w:pageBreakBefore></w:pPr><w:r><w:t>ONE</w:t></w:r></w:p>` +
+		`<w:p><w:r><w:br w:type="page"></w:br></w:r><w:r><w:t>TWO</w:t></w:r><w:r><w:br w:type="column"></w:br></w:r></w:p>` +
+		`<w:p><w:pPr><w:sectPr><w:type w:val="nextPage"></w:type></w:sectPr></w:pPr><w:r><w:t>THREE</w:t></w:r></w:p>`
+	if !strings.Contains(xml, want) {
+		t.Errorf("missing %s in %s", want, xml)
+	}
+
+	// 双语对照中分页只出现一次
+	xml = translate(OutputModeBilingualInterleaved)
+	if n := strings.Count(xml, "<w:pageBreakBefore>"); n != 1 {
+		t.Errorf("%d paragraphs break the page in %s", n, xml)
+	}
+	if n := strings.Count(xml, `<w:br w:type="page">`); n != 1 {
+		t.Errorf("%d page breaks in %s", n, xml)
+	}
+}
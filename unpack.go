@@ -37,8 +37,10 @@ import (
 //  3. Media
 //
 // Then it stores all other files into tmpfslist for packing.
-func unpack(zipReader *zip.Reader) (docx *Docx, err error) {
+// In lenient mode errors that leave the document usable are recorded as warnings, see ParseLenient.
+func unpack(zipReader *zip.Reader, lenient bool) (docx *Docx, err error) {
 	docx = new(Docx)
+	docx.lenient = lenient
 	docx.mediaNameIdx = make(map[string]int, 64)
 	docx.slowIDs = make(map[string]uintptr, 64)
 	docx.tmplfs = zipReader
@@ -47,12 +49,16 @@ func unpack(zipReader *zip.Reader) (docx *Docx, err error) {
 		if f.Name == "word/_rels/document.xml.rels" {
 			err = docx.parseDocRelation(f)
 			if err != nil {
-				return
+				if !lenient {
+					return
+				}
+				docx.warn(f.Name, "", err)
+				err = nil
 			}
 			continue
 		}
 		if f.Name == "word/document.xml" {
-			err = docx.parseDocument(f)
+			err = docx.lenientDocumentError(docx.parseDocument(f))
 			if err != nil {
 				return
 			}
@@ -61,7 +67,11 @@ func unpack(zipReader *zip.Reader) (docx *Docx, err error) {
 		if strings.HasPrefix(f.Name, MEDIA_FOLDER) {
 			err = docx.parseMedia(f)
 			if err != nil {
-				return
+				if !lenient {
+					return
+				}
+				docx.warn(f.Name, "", err)
+				err = nil
 			}
 			continue
 		}
@@ -90,6 +100,7 @@ func (f *Docx) parseDocument(file *zip.File) error {
 	f.Document.XMLWPS = XMLNS_WPS
 	f.Document.XMLWPC = XMLNS_WPC
 	f.Document.XMLWPG = XMLNS_WPG
+	f.Document.XMLM = XMLNS_M
 	// f.Document.XMLWP14 = XMLNS_WP14
 	f.Document.XMLName.Space = XMLNS_W
 	f.Document.XMLName.Local = "document"
@@ -97,7 +108,9 @@ func (f *Docx) parseDocument(file *zip.File) error {
 	f.Document.Body.file = f
 	//TODO: find last docID
 	f.docID = 100000
-	err = xml.NewDecoder(zf).Decode(&f.Document)
+	d := xml.NewDecoder(zf)
+	d.Strict = !f.lenient
+	err = d.Decode(&f.Document)
 	return err
 }
 
@@ -116,10 +129,16 @@ func (f *Docx) parseDocRelation(file *zip.File) error {
 	}
 	for _, r := range f.docRelation.Relationship {
 		if !strings.HasPrefix(r.ID, "rId") {
+			if f.tolerate(file.Name, "Relationship", errors.New("invalid rel ID: "+r.ID)) {
+				continue
+			}
 			return errors.New("invalid rel ID: " + r.ID)
 		}
 		id, err := strconv.ParseUint(r.ID[3:], 10, 64)
 		if err != nil {
+			if f.tolerate(file.Name, "Relationship", err) {
+				continue
+			}
 			return err
 		}
 		if f.rID < uintptr(id) {